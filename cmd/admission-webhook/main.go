@@ -38,6 +38,7 @@ var (
 	healthProbeBindAddress  string
 	gracefulShutdownTimeout time.Duration
 	cacheSyncTimeout        time.Duration
+	emergencyOverrideGroup  string
 )
 
 func main() {
@@ -46,6 +47,7 @@ func main() {
 	flag.StringVar(&healthProbeBindAddress, "health-probe-bind-addr", fmt.Sprintf(":%d", configuration.HealthProbePort), "The address the healthz & readyz probes bind to.")
 	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", configuration.GracefulShutdownTimeout, "The duration of time to wait while shutting down for all controllers to stop.")
 	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", configuration.CacheSyncTimeout, "The duration of time to wait while informers synchronize.")
+	flag.StringVar(&emergencyOverrideGroup, "emergency-override-group", "", "The group whose members may set emergency overrides on managed objects. Emergency overrides are disabled if empty.")
 
 	log.Setup()
 
@@ -107,7 +109,7 @@ func main() {
 		<-certDone
 
 		setupLog.Info("registering validator for webhook")
-		if err := webhook.AddValidator(mgr); err != nil {
+		if err := webhook.AddValidator(mgr, emergencyOverrideGroup); err != nil {
 			setupLog.Error(err, "unable to register validator for webhook")
 			os.Exit(1)
 		}
@@ -0,0 +1,84 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/klogr"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/gitwebhook"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	utillog "kpt.dev/configsync/pkg/util/log"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	addr = flag.String("addr", fmt.Sprintf(":%d", reconcilermanager.GitWebhookReceiverPort),
+		"The address the git webhook receiver binds to.")
+	minInterval = flag.Duration("min-interval", 30*time.Second,
+		"The minimum time between two sync requests for the same RootSync or RepoSync. Pushes received in between are coalesced.")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second,
+		"The duration of time to wait for the in-flight events while shutting down.")
+)
+
+func main() {
+	utillog.Setup()
+	ctrl.SetLogger(klogr.New())
+
+	secret := os.Getenv(reconcilermanager.GitWebhookSecret)
+	if secret == "" {
+		klog.Fatalf("%s must be set", reconcilermanager.GitWebhookSecret)
+	}
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: core.Scheme})
+	if err != nil {
+		klog.Fatalf("Failed to create the client: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gitwebhook.NewHandler(c, []byte(secret), *minInterval))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("Failed to shut down the git webhook receiver: %v", err)
+		}
+	}()
+
+	klog.Infof("Starting the git webhook receiver on %s", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Fatalf("Git webhook receiver failed: %v", err)
+	}
+}
@@ -24,14 +24,21 @@ import (
 	"k8s.io/klog/v2/klogr"
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/hydrate"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/kmetrics"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/profiler"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/util"
 	"kpt.dev/configsync/pkg/util/log"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
@@ -65,11 +72,21 @@ var (
 	// Other ways to trigger the hydration process are:
 	// - push a new commit
 	// - delete the done file from the hydration-controller.
+	// - change the reset-cache annotation of the RootSync or RepoSync.
 	rehydratePeriod = flag.Duration("rehydrate-period", configsync.DefaultHydrationRetryPeriod,
 		"Period of time between rehydrating on errors.")
 
 	reconcilerName = flag.String("reconciler-name", os.Getenv(reconcilermanager.ReconcilerNameKey),
 		"Name of the reconciler Deployment.")
+
+	scope = flag.String("scope", os.Getenv(reconcilermanager.ScopeKey),
+		"Scope of the reconciler, either a namespace or ':root'.")
+
+	syncName = flag.String("sync-name", os.Getenv(reconcilermanager.SyncNameKey),
+		"Name of the RootSync or RepoSync object, whose reset-cache annotation purges the hydrated configs. Empty disables the purge.")
+
+	oneShot = flag.Bool("one-shot", util.EnvBool(reconcilermanager.OneShot, false),
+		"Render the fetched commit once and exit, instead of rendering new commits continuously. Used when the reconciler runs in a Job.")
 )
 
 func main() {
@@ -101,6 +118,7 @@ func main() {
 	absSourceRootDir := absRepoRootDir.Join(cmpath.RelativeSlash(*sourceRootDir))
 	absHydratedRootDir := absRepoRootDir.Join(cmpath.RelativeSlash(*hydratedRootDir))
 	absDonePath := absRepoRootDir.Join(cmpath.RelativeSlash(hydrate.DoneFile))
+	absProgressPath := absRepoRootDir.Join(cmpath.RelativeSlash(hydrate.ProgressFile))
 
 	// Normalize syncDirRelative.
 	// Some users specify the directory as if the root of the repository is "/".
@@ -111,6 +129,7 @@ func main() {
 
 	hydrator := &hydrate.Hydrator{
 		DonePath:        absDonePath,
+		ProgressPath:    absProgressPath,
 		SourceType:      v1beta1.SourceType(*sourceType),
 		SourceRoot:      absSourceRootDir,
 		HydratedRoot:    absHydratedRootDir,
@@ -120,7 +139,39 @@ func main() {
 		PollingPeriod:   *pollingPeriod,
 		RehydratePeriod: *rehydratePeriod,
 		ReconcilerName:  *reconcilerName,
+		SigningKey:      []byte(os.Getenv(reconcilermanager.HydrationSigningKey)),
 	}
 
+	if *oneShot {
+		if err := hydrator.RunOnce(); err != nil {
+			klog.Fatalf("Failed to render the fetched commit: %v", err)
+		}
+		return
+	}
+	if *syncName != "" {
+		c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: core.Scheme})
+		if err != nil {
+			klog.Fatalf("failed to create client: %v", err)
+		}
+		hydrator.ResetCacheToken = resetCacheToken(c, declared.Scope(*scope), *syncName)
+	}
 	hydrator.Run(context.Background())
 }
+
+// resetCacheToken returns a function which reads the reset-cache annotation
+// of the RootSync or RepoSync.
+func resetCacheToken(c client.Reader, scope declared.Scope, syncName string) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		var rs client.Object
+		var key client.ObjectKey
+		if scope == declared.RootReconciler {
+			rs, key = &v1beta1.RootSync{}, rootsync.ObjectKey(syncName)
+		} else {
+			rs, key = &v1beta1.RepoSync{}, reposync.ObjectKey(scope, syncName)
+		}
+		if err := c.Get(ctx, key, rs); err != nil {
+			return "", err
+		}
+		return core.GetAnnotation(rs, metadata.ResetCacheKey), nil
+	}
+}
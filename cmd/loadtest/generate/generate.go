@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/loadtest"
+)
+
+var (
+	config    loadtest.Config
+	iteration int
+	outputDir string
+	repo      string
+	branch    string
+	auth      string
+	secretRef string
+)
+
+func init() {
+	Cmd.Flags().StringVar(&config.Name, "name", "",
+		"The name of the fleet, set as the value of the "+loadtest.LabelKey+" label of its objects")
+	Cmd.Flags().IntVar(&config.RepoSyncs, "repo-syncs", 10,
+		"The number of RepoSyncs, each in its own Namespace")
+	Cmd.Flags().IntVar(&config.ObjectsPerRepoSync, "objects", 100,
+		"The number of ConfigMaps synced by every RepoSync")
+	Cmd.Flags().IntVar(&config.ChurnPercent, "churn-percent", 10,
+		"The percentage of the ConfigMaps of every RepoSync changed by every iteration")
+	Cmd.Flags().Int64Var(&config.Seed, "seed", 0,
+		"The seed of the churn. Runs with the same seed change the same objects")
+	Cmd.Flags().StringVar(&config.RootDir, "root-dir", loadtest.DefaultRootDir,
+		"The directory of the Namespaces, RoleBindings and RepoSyncs, to be synced by a RootSync")
+	Cmd.Flags().StringVar(&config.NamespacePrefix, "namespace-prefix", loadtest.DefaultNamespacePrefix,
+		"The prefix of the Namespaces of the RepoSyncs")
+	Cmd.Flags().StringVar(&config.ClusterRole, "cluster-role", loadtest.DefaultClusterRole,
+		"The ClusterRole bound to the reconcilers of the RepoSyncs")
+	Cmd.Flags().IntVar(&iteration, "iteration", 0,
+		"The iteration to generate. Iteration 0 creates the fleet, and every later one churns it")
+	Cmd.Flags().StringVar(&outputDir, "output", ".",
+		"The directory of the repository to write the fleet to")
+	Cmd.Flags().StringVar(&repo, "repo", "",
+		"The URL of the repository, synced by the RepoSyncs")
+	Cmd.Flags().StringVar(&branch, "branch", "main",
+		"The branch of the repository, synced by the RepoSyncs")
+	Cmd.Flags().StringVar(&auth, "auth", string(configsync.AuthNone),
+		"The authentication type of the RepoSyncs")
+	Cmd.Flags().StringVar(&secretRef, "secret-ref", "",
+		"The Secret of the credentials of the RepoSyncs, in their Namespaces")
+}
+
+// Cmd is the Cobra object representing the loadtest generate command.
+var Cmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Write an iteration of a synthetic fleet of RepoSyncs to a repository",
+	Long: `Write an iteration of a synthetic fleet of RepoSyncs to a repository.
+The RootSync syncing the root directory creates the Namespaces, RoleBindings
+and RepoSyncs, and every RepoSync syncs the ConfigMaps of the directory named
+after its Namespace. Commit and push every iteration, and record how long the
+RepoSyncs take to sync it.`,
+	Example: `  loadtest generate --name=nightly --repo=https://github.com/example/fleet --repo-syncs=100 --objects=500 --iteration=0 --output=fleet-repo`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		config.Default()
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		if repo == "" {
+			return fmt.Errorf("--repo must be set")
+		}
+		if err := config.WriteRepository(outputDir, template(), iteration); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote iteration %d of fleet %q (%d changed objects) to %s\n",
+			iteration, config.Name, config.ChurnedObjects(iteration), outputDir)
+		return nil
+	},
+}
+
+func template() *v1beta1.RepoSync {
+	rs := &v1beta1.RepoSync{}
+	rs.Spec.SourceType = string(v1beta1.GitSource)
+	rs.Spec.Git = &v1beta1.Git{
+		Repo:   repo,
+		Branch: branch,
+		Auth:   configsync.AuthType(auth),
+	}
+	if secretRef != "" {
+		rs.Spec.Git.SecretRef = &v1beta1.SecretReference{Name: secretRef}
+	}
+	return rs
+}
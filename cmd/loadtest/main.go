@@ -0,0 +1,46 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/cmd/loadtest/generate"
+	"kpt.dev/configsync/cmd/loadtest/report"
+)
+
+var (
+	rootCmd = &cobra.Command{
+		Use:   "loadtest",
+		Short: "Generate synthetic fleets of RepoSyncs and report how Config Sync syncs them",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(generate.Cmd)
+	rootCmd.AddCommand(report.Cmd)
+}
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
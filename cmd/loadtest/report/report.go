@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	prometheusapi "github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/spf13/cobra"
+	"kpt.dev/configsync/pkg/loadtest"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	resultsPath  string
+	prometheus   string
+	window       time.Duration
+	outputFormat string
+	outputPath   string
+)
+
+func init() {
+	Cmd.Flags().StringVar(&resultsPath, "results", "",
+		"The JSON report of the run, with the sync durations of its iterations")
+	Cmd.Flags().StringVar(&prometheus, "prometheus", "",
+		"The address of the Prometheus server scraping the Config Sync metrics. If unset, no metrics are collected")
+	Cmd.Flags().DurationVar(&window, "window", time.Hour,
+		"The duration of the run, over which the metrics are aggregated")
+	Cmd.Flags().StringVar(&outputFormat, "format", outputText,
+		fmt.Sprintf("The format of the report, %q or %q", outputText, outputJSON))
+	Cmd.Flags().StringVar(&outputPath, "output", "",
+		"The file to write the report to. If unset, the report is printed")
+}
+
+// Cmd is the Cobra object representing the loadtest report command.
+var Cmd = &cobra.Command{
+	Use:     "report",
+	Short:   "Collect the pipeline metrics of a load test run into its report",
+	Example: `  loadtest report --results=results.json --prometheus=http://localhost:9090 --window=2h`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if resultsPath == "" {
+			return fmt.Errorf("--results must be set")
+		}
+		if outputFormat != outputText && outputFormat != outputJSON {
+			return fmt.Errorf("--format must be %q or %q", outputText, outputJSON)
+		}
+		r, err := loadtest.ReadReport(resultsPath)
+		if err != nil {
+			return err
+		}
+		if prometheus != "" {
+			if err := collectMetrics(cmd.Context(), r); err != nil {
+				return err
+			}
+		}
+
+		out := os.Stdout
+		if outputPath != "" {
+			f, err := os.Create(outputPath)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			out = f
+		}
+		if outputFormat == outputJSON {
+			return r.WriteJSON(out)
+		}
+		return r.WriteText(out)
+	},
+}
+
+func collectMetrics(ctx context.Context, r *loadtest.Report) error {
+	client, err := prometheusapi.NewClient(prometheusapi.Config{Address: prometheus})
+	if err != nil {
+		return err
+	}
+	return r.CollectMetrics(ctx, prometheusv1.NewAPI(client), loadtest.DefaultQueries(window))
+}
@@ -40,6 +40,9 @@ const (
 	// SkipAPIServerFlag is the flag name for SkipAPIServer below.
 	SkipAPIServerFlag = "no-api-server-check"
 
+	// clusterStateFlag is the flag name for ClusterState below.
+	clusterStateFlag = "cluster-state"
+
 	// OutputYAML specifies exporting the output in YAML format.
 	OutputYAML = "yaml"
 
@@ -67,6 +70,10 @@ var (
 	// SkipAPIServer directs whether to try to contact the API Server for checks.
 	SkipAPIServer bool
 
+	// ClusterState is the path to the manifests of a fake cluster state, used
+	// instead of the API Server.
+	ClusterState string
+
 	// SourceFormat indicates the format of the Git repository.
 	SourceFormat string
 
@@ -109,6 +116,13 @@ func AllClusters() bool {
 	return Clusters == nil
 }
 
+// AddClusterState adds the --cluster-state flag.
+func AddClusterState(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&ClusterState, clusterStateFlag, "",
+		"If set, the path to a file or directory of manifests of the namespaces, CRDs and "+
+			"other objects on a cluster, used instead of talking to the API Server.")
+}
+
 // AddSourceFormat adds the --source-format flag.
 func AddSourceFormat(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&SourceFormat, reconcilermanager.SourceFormat, "",
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handoff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/handoff"
+	"kpt.dev/configsync/pkg/resync"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	fromName      string
+	fromNamespace string
+	toName        string
+	toNamespace   string
+	objects       []string
+	dryRun        bool
+)
+
+func init() {
+	Cmd.Flags().StringVar(&fromName, "from-name", configsync.RootSyncName,
+		"Name of the RootSync or RepoSync managing the objects.")
+	Cmd.Flags().StringVar(&fromNamespace, "from-namespace", configsync.ControllerNamespace,
+		fmt.Sprintf("Namespace of the RepoSync managing the objects. Defaults to the RootSync namespace %s.", configsync.ControllerNamespace))
+	Cmd.Flags().StringVar(&toName, "to-name", configsync.RepoSyncName,
+		"Name of the RootSync or RepoSync taking over the objects.")
+	Cmd.Flags().StringVar(&toNamespace, "to-namespace", "",
+		fmt.Sprintf("Namespace of the RepoSync taking over the objects, or the RootSync namespace %s.", configsync.ControllerNamespace))
+	Cmd.Flags().StringArrayVar(&objects, "object", nil,
+		"Object to hand off, as <kind>[.<group>]/<namespace>/<name>, or <kind>[.<group>]/<name> for cluster-scoped objects. May be repeated.")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Only check that the objects can be handed off.")
+	Cmd.Flags().DurationVar(&flags.ClientTimeout, "timeout", flags.DefaultClusterClientTimeout, "Timeout for connecting to the cluster")
+}
+
+// Cmd moves the management of objects from a RootSync or RepoSync to another.
+var Cmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "Moves the management of objects from a RootSync or RepoSync to another, without deleting them.",
+	Long: `Moves the management of objects from a RootSync or RepoSync to another, without deleting or recreating them.
+First declare the objects in the source of truth of the RootSync or RepoSync taking them over, and wait for it to report management conflicts for them.
+The handoff then moves the objects from the inventory of the current manager to the inventory of the new one, and updates their management annotations, while neither is syncing.
+Finally remove the objects from the source of truth of the previous manager, which no longer prunes them.`,
+	Example: `  nomos handoff --from-name root-sync --to-name repo-sync --to-namespace bookstore --object configmap/bookstore/settings --dry-run
+  nomos handoff --from-name root-sync --to-name repo-sync --to-namespace bookstore --object configmap/bookstore/settings`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toNamespace == "" {
+			return errors.New("--to-namespace must be set")
+		}
+		if len(objects) == 0 {
+			return errors.New("at least one --object must be set")
+		}
+		targets, err := resync.ParseTargets(strings.Join(objects, ","))
+		if err != nil {
+			return err
+		}
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		cfg, err := restconfig.NewRestConfig(flags.ClientTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create rest config")
+		}
+		c, err := client.New(cfg, client.Options{Scheme: core.Scheme})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create client")
+		}
+
+		opts := handoff.Options{
+			From:    handoff.Sync{Name: fromName, Namespace: fromNamespace},
+			To:      handoff.Sync{Name: toName, Namespace: toNamespace},
+			Targets: targets,
+			DryRun:  dryRun,
+		}
+		ids, err := handoff.Handoff(cmd.Context(), c, opts)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Printf("%s can hand off to %s: %s\n", opts.From, opts.To, handoff.FormatIDs(ids))
+			return nil
+		}
+		fmt.Printf("Handed off from %s to %s: %s\n", opts.From, opts.To, handoff.FormatIDs(ids))
+		fmt.Println(handoff.NextSteps(opts))
+		return nil
+	},
+}
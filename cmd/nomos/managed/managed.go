@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managed
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/cmd/nomos/util"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/managed"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	syncName      string
+	syncNamespace string
+	kindFlags     []string
+	chunkSize     int64
+)
+
+func init() {
+	Cmd.Flags().StringVar(&syncName, "name", configsync.RootSyncName,
+		"Name of the RootSync or RepoSync whose managed objects are listed.")
+	Cmd.Flags().StringVar(&syncNamespace, "namespace", configsync.ControllerNamespace,
+		fmt.Sprintf("Namespace of the RepoSync whose managed objects are listed. Defaults to the RootSync namespace %s.", configsync.ControllerNamespace))
+	Cmd.Flags().StringArrayVar(&kindFlags, "kind", nil,
+		"Kind of the objects to list, as <kind>[.<group>]. May be repeated. Defaults to all the kinds served by the cluster.")
+	Cmd.Flags().Int64Var(&chunkSize, "chunk-size", managed.DefaultChunkSize,
+		"Maximum number of objects fetched from the cluster per request.")
+	Cmd.Flags().DurationVar(&flags.ClientTimeout, "timeout", flags.DefaultClusterClientTimeout, "Timeout for connecting to the cluster")
+}
+
+// Cmd lists the objects managed by a RootSync or RepoSync.
+var Cmd = &cobra.Command{
+	Use:   "managed",
+	Short: "Lists the objects managed by a RootSync or RepoSync, with their health and commit.",
+	Long: `Lists the objects managed by a RootSync or RepoSync, with their health and the commit they were last applied from.
+The objects are selected by the owning inventory label set by the reconciler, and fetched in chunks, one kind at a time, without reading the ResourceGroup of the RootSync or RepoSync.
+Listing all the kinds served by the cluster issues at least one request per kind, so restrict the kinds with --kind when possible.`,
+	Example: `  nomos managed
+  nomos managed --name repo-sync --namespace bookstore --kind Deployment.apps --kind ConfigMap`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var kinds []schema.GroupKind
+		for _, k := range kindFlags {
+			kinds = append(kinds, schema.ParseGroupKind(k))
+		}
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		cfg, err := restconfig.NewRestConfig(flags.ClientTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create rest config")
+		}
+		c, err := client.New(cfg, client.Options{Scheme: core.Scheme})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create client")
+		}
+		dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create discovery client")
+		}
+		resourceLists, err := dc.ServerPreferredResources()
+		if err != nil {
+			// The kinds of the API groups which failed discovery are skipped.
+			if !discovery.IsGroupDiscoveryFailedError(err) {
+				return errors.Wrapf(err, "failed to discover the kinds served by the cluster")
+			}
+			klog.Warning(err)
+		}
+		gvks, err := managed.ListableGVKs(resourceLists, kinds)
+		if err != nil {
+			return err
+		}
+
+		writer := util.NewWriter(os.Stdout)
+		count := 0
+		opts := managed.Options{
+			SyncName:      syncName,
+			SyncNamespace: syncNamespace,
+			GVKs:          gvks,
+			ChunkSize:     chunkSize,
+		}
+		err = managed.List(cmd.Context(), c, opts, func(page []managed.Object) error {
+			if count == 0 {
+				fmt.Fprintln(writer, "KIND\tNAMESPACE\tNAME\tSTATUS\tCOMMIT")
+			}
+			for _, obj := range page {
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+					obj.ID.GroupKind, obj.ID.Namespace, obj.ID.Name, obj.Status, obj.Commit)
+			}
+			count += len(page)
+			// Print each page as it is fetched.
+			return writer.Flush()
+		})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			fmt.Printf("No managed objects found for %s/%s\n", syncNamespace, syncName)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,257 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"kpt.dev/configsync/cmd/nomos/status"
+	"kpt.dev/configsync/cmd/nomos/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// helmManagedByLabel is the label Helm sets on the objects of a release.
+	helmManagedByLabel = "app.kubernetes.io/managed-by"
+	// helmManagedByValue is the value of helmManagedByLabel for Helm releases.
+	helmManagedByValue = "Helm"
+	// helmReleaseNameAnnotation is the annotation Helm uses to record the
+	// release that owns an object.
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	// helmReleaseNamespaceAnnotation is the annotation Helm uses to record the
+	// namespace of the release that owns an object.
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	// helmFieldManager is the field manager Helm uses when updating objects.
+	helmFieldManager = "helm"
+
+	helmAdoptionReportFile = "helm-adoption-report.txt"
+)
+
+var helmRelease string
+var helmReleaseNamespace string
+
+func init() {
+	Cmd.Flags().StringVar(&helmRelease, "helm-release", "",
+		`If set, adopts the objects of the Helm release into Config Sync instead of enabling the multi-repo mode. `+
+			`The Helm ownership labels, annotations and field manager are removed from the objects of the release, `+
+			`so that they can be taken over by Config Sync after being declared in the source of truth.`)
+	Cmd.Flags().StringVar(&helmReleaseNamespace, "helm-release-namespace", "default",
+		`The namespace of the Helm release to adopt. Only used with --helm-release.`)
+}
+
+// adoptHelmRelease strips the Helm release ownership from the objects of the
+// release on the cluster, one object at a time, and saves a report of the
+// adopted objects. It stops at the first object that fails to be adopted.
+func adoptHelmRelease(ctx context.Context, sc *status.ClusterClient, context string) error {
+	printInfo("Looking up the objects of the Helm release %s/%s", helmReleaseNamespace, helmRelease)
+	objs, err := listHelmReleaseObjects(ctx, sc.Client, sc.K8sClient.Discovery(), helmReleaseNamespace, helmRelease)
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		printNotice("No objects found for the Helm release %s/%s", helmReleaseNamespace, helmRelease)
+		return nil
+	}
+	printInfo("Found %d object(s) to adopt", len(objs))
+
+	var adopted []string
+	var adoptErr error
+	for _, obj := range objs {
+		ref := helmObjectRef(obj)
+		if dryRun {
+			printInfo("%sWould adopt %s", util.Indent, ref)
+			continue
+		}
+		if err := adoptHelmObject(ctx, sc.Client, obj); err != nil {
+			adoptErr = errors.Wrapf(err, "failed to adopt %s", ref)
+			break
+		}
+		adopted = append(adopted, ref)
+		printInfo("%sAdopted %s (%d/%d)", util.Indent, ref, len(adopted), len(objs))
+	}
+	if dryRun {
+		return nil
+	}
+
+	reportFile, err := saveHelmAdoptionReport(context, adopted)
+	if err != nil {
+		printError(err)
+	} else {
+		printHint("The list of adopted objects is saved in %q", reportFile)
+	}
+	if adoptErr != nil {
+		return adoptErr
+	}
+	printSuccess("The Helm release %s/%s has been adopted. Please make sure all its objects are declared in the source of truth", helmReleaseNamespace, helmRelease)
+	printHint("Do not run `helm uninstall`, which deletes the adopted objects. Delete the release history Secrets `sh.helm.release.v1.%s.*` in the %q namespace instead", helmRelease, helmReleaseNamespace)
+	return nil
+}
+
+// listHelmReleaseObjects returns the objects labeled as managed by Helm that
+// belong to the given release, sorted for a stable adoption order.
+func listHelmReleaseObjects(ctx context.Context, c client.Client, dc discovery.DiscoveryInterface, releaseNamespace, releaseName string) ([]*unstructured.Unstructured, error) {
+	_, resourceLists, err := dc.ServerGroupsAndResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, errors.Wrap(err, "failed to discover the API resources")
+		}
+		// Continue with the groups that were discovered successfully.
+		printNotice("Some API groups could not be discovered: %v", err)
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, gvk := range listablePatchableGVKs(resourceLists) {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.MatchingLabels{helmManagedByLabel: helmManagedByValue}); err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s", gvk.GroupKind())
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			annotations := obj.GetAnnotations()
+			if annotations[helmReleaseNameAnnotation] == releaseName && annotations[helmReleaseNamespaceAnnotation] == releaseNamespace {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		return helmObjectRef(objs[i]) < helmObjectRef(objs[j])
+	})
+	return objs, nil
+}
+
+// listablePatchableGVKs returns the GroupVersionKinds of the resources which
+// support the list and patch verbs.
+func listablePatchableGVKs(resourceLists []*metav1.APIResourceList) []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+	seen := make(map[schema.GroupKind]bool)
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				// Skip subresources.
+				continue
+			}
+			gk := schema.GroupKind{Group: gv.Group, Kind: resource.Kind}
+			if seen[gk] || !hasVerbs(resource.Verbs, "list", "patch") {
+				continue
+			}
+			seen[gk] = true
+			gvks = append(gvks, gv.WithKind(resource.Kind))
+		}
+	}
+	return gvks
+}
+
+func hasVerbs(verbs metav1.Verbs, required ...string) bool {
+	for _, r := range required {
+		found := false
+		for _, v := range verbs {
+			if v == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// adoptHelmObject removes the Helm ownership from the object on the cluster.
+func adoptHelmObject(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+	original := obj.DeepCopy()
+	if !stripHelmOwnership(obj) {
+		return nil
+	}
+	return c.Patch(ctx, obj, client.MergeFrom(original))
+}
+
+// stripHelmOwnership removes the Helm release labels, annotations and
+// managed fields from the object. It returns true if the object was modified.
+func stripHelmOwnership(obj client.Object) bool {
+	modified := false
+
+	labels := obj.GetLabels()
+	if labels[helmManagedByLabel] == helmManagedByValue {
+		delete(labels, helmManagedByLabel)
+		obj.SetLabels(labels)
+		modified = true
+	}
+
+	annotations := obj.GetAnnotations()
+	for _, key := range []string{helmReleaseNameAnnotation, helmReleaseNamespaceAnnotation} {
+		if _, found := annotations[key]; found {
+			delete(annotations, key)
+			modified = true
+		}
+	}
+	obj.SetAnnotations(annotations)
+
+	var managedFields []metav1.ManagedFieldsEntry
+	helmManaged := false
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == helmFieldManager {
+			helmManaged = true
+			continue
+		}
+		managedFields = append(managedFields, entry)
+	}
+	if helmManaged {
+		if len(managedFields) == 0 {
+			// A single empty entry resets the managed fields.
+			managedFields = []metav1.ManagedFieldsEntry{{}}
+		}
+		obj.SetManagedFields(managedFields)
+		modified = true
+	}
+	return modified
+}
+
+func helmObjectRef(obj client.Object) string {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", gvk.GroupKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", gvk.GroupKind(), obj.GetNamespace(), obj.GetName())
+}
+
+func saveHelmAdoptionReport(context string, adopted []string) (string, error) {
+	dir := filepath.Join(os.TempDir(), migrateDir, context)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	reportFile := filepath.Join(dir, helmAdoptionReportFile)
+	content := strings.Join(adopted, "\n") + "\n"
+	if err := ioutil.WriteFile(reportFile, []byte(content), 0644); err != nil {
+		return reportFile, err
+	}
+	return reportFile, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestStripHelmOwnership(t *testing.T) {
+	testCases := []struct {
+		name              string
+		managedFields     []metav1.ManagedFieldsEntry
+		opts              []core.MetaMutator
+		wantModified      bool
+		wantLabels        map[string]string
+		wantAnnotations   map[string]string
+		wantManagedFields []metav1.ManagedFieldsEntry
+	}{
+		{
+			name: "not managed by Helm",
+			opts: []core.MetaMutator{
+				core.Label("app", "bookstore"),
+			},
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl"},
+			},
+			wantModified: false,
+			wantLabels: map[string]string{
+				"app": "bookstore",
+			},
+			wantManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl"},
+			},
+		},
+		{
+			name: "managed by Helm and kubectl",
+			opts: []core.MetaMutator{
+				core.Label("app", "bookstore"),
+				core.Label(helmManagedByLabel, helmManagedByValue),
+				core.Annotation(helmReleaseNameAnnotation, "bookstore"),
+				core.Annotation(helmReleaseNamespaceAnnotation, "default"),
+				core.Annotation("foo", "bar"),
+			},
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: helmFieldManager},
+				{Manager: "kubectl"},
+			},
+			wantModified: true,
+			wantLabels: map[string]string{
+				"app": "bookstore",
+			},
+			wantAnnotations: map[string]string{
+				"foo": "bar",
+			},
+			wantManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl"},
+			},
+		},
+		{
+			name: "managed by Helm only",
+			opts: []core.MetaMutator{
+				core.Label(helmManagedByLabel, helmManagedByValue),
+				core.Annotation(helmReleaseNameAnnotation, "bookstore"),
+				core.Annotation(helmReleaseNamespaceAnnotation, "default"),
+			},
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: helmFieldManager},
+			},
+			wantModified:      true,
+			wantLabels:        map[string]string{},
+			wantAnnotations:   map[string]string{},
+			wantManagedFields: []metav1.ManagedFieldsEntry{{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := fake.UnstructuredObject(kinds.Deployment(), append(tc.opts, core.Name("bookstore"), core.Namespace("default"))...)
+			obj.SetManagedFields(tc.managedFields)
+
+			if got := stripHelmOwnership(obj); got != tc.wantModified {
+				t.Errorf("stripHelmOwnership() = %v, want %v", got, tc.wantModified)
+			}
+			if diff := cmp.Diff(tc.wantLabels, obj.GetLabels(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, obj.GetAnnotations(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected annotations (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantManagedFields, obj.GetManagedFields()); diff != "" {
+				t.Errorf("unexpected managed fields (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListablePatchableGVKs(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Verbs: []string{"get", "list", "patch"}},
+				{Name: "deployments/status", Kind: "Deployment", Verbs: []string{"get", "patch"}},
+			},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "bindings", Kind: "Binding", Verbs: []string{"create"}},
+				{Name: "configmaps", Kind: "ConfigMap", Verbs: []string{"list", "patch", "delete"}},
+			},
+		},
+	}
+	want := []schema.GroupVersionKind{
+		kinds.Deployment(),
+		kinds.ConfigMap(),
+	}
+	if diff := cmp.Diff(want, listablePatchableGVKs(resourceLists)); diff != "" {
+		t.Errorf("unexpected GVKs (-want +got):\n%s", diff)
+	}
+}
@@ -77,7 +77,7 @@ func init() {
 var Cmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Migrates to the new Config Sync architecture by enabling the multi-repo mode.",
-	Long:  "Migrates to the new Config Sync architecture by enabling the multi-repo mode. It provides you with additional features and gives you the flexibility to sync to a single repository, or multiple repositories.",
+	Long:  "Migrates to the new Config Sync architecture by enabling the multi-repo mode. It provides you with additional features and gives you the flexibility to sync to a single repository, or multiple repositories. With --helm-release, migrates the objects of a Helm release to Config Sync instead, by removing their Helm ownership.",
 	Args:  cobra.ExactArgs(0),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Don't show usage on error, as argument validation passed.
@@ -105,6 +105,14 @@ var Cmd = &cobra.Command{
 			migrationContexts = append(migrationContexts, context)
 			fmt.Println()
 			fmt.Println(util.Separator)
+			if helmRelease != "" {
+				fmt.Printf("Adopting the Helm release %s/%s on cluster %q ...\n", helmReleaseNamespace, helmRelease, context)
+				if err := adoptHelmRelease(cmd.Context(), c, context); err != nil {
+					printError(err)
+					migrationError = true
+				}
+				continue
+			}
 			fmt.Printf("Enabling the multi-repo mode on cluster %q ...\n", context)
 			cs := &status.ClusterState{Ref: context}
 			if !c.IsInstalled(cmd.Context(), cs) || !c.IsConfigured(cmd.Context(), cs) {
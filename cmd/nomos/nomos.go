@@ -22,9 +22,13 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/cmd/nomos/bugreport"
+	"kpt.dev/configsync/cmd/nomos/handoff"
 	"kpt.dev/configsync/cmd/nomos/hydrate"
 	"kpt.dev/configsync/cmd/nomos/initialize"
+	"kpt.dev/configsync/cmd/nomos/managed"
 	"kpt.dev/configsync/cmd/nomos/migrate"
+	"kpt.dev/configsync/cmd/nomos/restore"
+	"kpt.dev/configsync/cmd/nomos/resync"
 	"kpt.dev/configsync/cmd/nomos/status"
 	"kpt.dev/configsync/cmd/nomos/version"
 	"kpt.dev/configsync/cmd/nomos/vet"
@@ -48,6 +52,10 @@ func init() {
 	rootCmd.AddCommand(status.Cmd)
 	rootCmd.AddCommand(bugreport.Cmd)
 	rootCmd.AddCommand(migrate.Cmd)
+	rootCmd.AddCommand(restore.Cmd)
+	rootCmd.AddCommand(resync.Cmd)
+	rootCmd.AddCommand(managed.Cmd)
+	rootCmd.AddCommand(handoff.Cmd)
 }
 
 func main() {
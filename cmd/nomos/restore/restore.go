@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/snapshot"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	syncName      string
+	syncNamespace string
+	commit        string
+	list          bool
+	clearRestore  bool
+)
+
+func init() {
+	Cmd.Flags().StringVar(&syncName, "name", configsync.RootSyncName,
+		"Name of the RootSync or RepoSync to restore.")
+	Cmd.Flags().StringVar(&syncNamespace, "namespace", configsync.ControllerNamespace,
+		fmt.Sprintf("Namespace of the RepoSync to restore. Defaults to the RootSync namespace %s.", configsync.ControllerNamespace))
+	Cmd.Flags().StringVar(&commit, "commit", "",
+		"Commit whose snapshot is re-applied, instead of syncing from the source of truth.")
+	Cmd.Flags().BoolVar(&list, "list", false,
+		"List the commits with a snapshot, from the newest to the oldest.")
+	Cmd.Flags().BoolVar(&clearRestore, "clear", false,
+		"Stop restoring the snapshot, and resume syncing from the source of truth.")
+	Cmd.Flags().DurationVar(&flags.ClientTimeout, "timeout", flags.DefaultClusterClientTimeout, "Timeout for connecting to the cluster")
+}
+
+// Cmd re-applies the snapshot of a previous commit of a RootSync or RepoSync.
+var Cmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Re-applies the objects synced for a previous commit, from the snapshots kept by the reconciler.",
+	Long: `Re-applies the objects synced for a previous commit, from the snapshots kept by the reconciler.
+The reconciler keeps re-applying the snapshot, even if the source of truth is broken or unreachable, until the restore is cleared with --clear.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if btoi(commit != "")+btoi(list)+btoi(clearRestore) != 1 {
+			return errors.New("exactly one of --commit, --list and --clear must be set")
+		}
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		cfg, err := restconfig.NewRestConfig(flags.ClientTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create rest config")
+		}
+		c, err := client.New(cfg, client.Options{Scheme: core.Scheme})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create client")
+		}
+
+		var rs client.Object
+		var reconcilerName string
+		if syncNamespace == configsync.ControllerNamespace {
+			rs = &v1beta1.RootSync{}
+			reconcilerName = core.RootReconcilerName(syncName)
+		} else {
+			rs = &v1beta1.RepoSync{}
+			reconcilerName = core.NsReconcilerName(syncNamespace, syncName)
+		}
+		key := types.NamespacedName{Namespace: syncNamespace, Name: syncName}
+		if err := c.Get(cmd.Context(), key, rs); err != nil {
+			return errors.Wrapf(err, "failed to get %s", key)
+		}
+
+		switch {
+		case list:
+			commits, err := snapshot.List(cmd.Context(), c, syncNamespace, reconcilerName)
+			if err != nil {
+				return err
+			}
+			if len(commits) == 0 {
+				fmt.Printf("No snapshots found for %s\n", key)
+			}
+			for _, commit := range commits {
+				fmt.Println(commit)
+			}
+			return nil
+		case clearRestore:
+			if err := setRestoreCommit(cmd, c, rs, ""); err != nil {
+				return err
+			}
+			fmt.Printf("Resuming syncing %s from the source of truth\n", key)
+			return nil
+		default:
+			if _, err := snapshot.Load(cmd.Context(), c, syncNamespace, reconcilerName, commit); err != nil {
+				if apierrors.IsNotFound(err) {
+					return errors.Errorf("no snapshot of commit %s found for %s, list the available snapshots with --list", commit, key)
+				}
+				return err
+			}
+			if err := setRestoreCommit(cmd, c, rs, commit); err != nil {
+				return err
+			}
+			fmt.Printf("Restoring the snapshot of commit %s for %s. Check the sync status with `nomos status`, and resume syncing from the source of truth with --clear.\n", commit, key)
+			return nil
+		}
+	},
+}
+
+// setRestoreCommit sets the restore annotation of the RSync to the commit, or
+// removes it if the commit is empty.
+func setRestoreCommit(cmd *cobra.Command, c client.Client, rs client.Object, commit string) error {
+	var patch []byte
+	if commit == "" {
+		patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, metadata.RestoreCommitAnnotationKey))
+	} else {
+		patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, metadata.RestoreCommitAnnotationKey, commit))
+	}
+	if err := c.Patch(cmd.Context(), rs, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return errors.Wrapf(err, "failed to annotate %s/%s", rs.GetNamespace(), rs.GetName())
+	}
+	return nil
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/resync"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	syncName      string
+	syncNamespace string
+	objects       []string
+	dirs          []string
+)
+
+func init() {
+	Cmd.Flags().StringVar(&syncName, "name", configsync.RootSyncName,
+		"Name of the RootSync or RepoSync to resync.")
+	Cmd.Flags().StringVar(&syncNamespace, "namespace", configsync.ControllerNamespace,
+		fmt.Sprintf("Namespace of the RepoSync to resync. Defaults to the RootSync namespace %s.", configsync.ControllerNamespace))
+	Cmd.Flags().StringArrayVar(&objects, "object", nil,
+		"Object to re-apply, as <kind>[.<group>]/<namespace>/<name>, or <kind>[.<group>]/<name> for cluster-scoped objects. May be repeated.")
+	Cmd.Flags().StringArrayVar(&dirs, "dir", nil,
+		"Directory of the source of truth, relative to the sync directory, whose objects are re-applied. May be repeated.")
+	Cmd.Flags().DurationVar(&flags.ClientTimeout, "timeout", flags.DefaultClusterClientTimeout, "Timeout for connecting to the cluster")
+}
+
+// Cmd re-applies some of the declared objects of a RootSync or RepoSync.
+var Cmd = &cobra.Command{
+	Use:   "resync",
+	Short: "Re-applies some of the declared objects of a RootSync or RepoSync from the current commit.",
+	Long: `Re-applies some of the declared objects of a RootSync or RepoSync from the current commit.
+Unlike a full resync, the other declared objects are neither re-parsed nor re-applied. The reconciler records the outcome as an event of the RootSync or RepoSync.`,
+	Example: `  nomos resync --object deployment/bookstore/app
+  nomos resync --name repo-sync --namespace bookstore --dir deployments`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets := append([]string{}, objects...)
+		for _, dir := range dirs {
+			targets = append(targets, "dir:"+dir)
+		}
+		if len(targets) == 0 {
+			return errors.New("at least one --object or --dir must be set")
+		}
+		value := strings.Join(targets, ",")
+		if _, err := resync.ParseTargets(value); err != nil {
+			return err
+		}
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		cfg, err := restconfig.NewRestConfig(flags.ClientTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create rest config")
+		}
+		c, err := client.New(cfg, client.Options{Scheme: core.Scheme})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create client")
+		}
+
+		var rs client.Object
+		if syncNamespace == configsync.ControllerNamespace {
+			rs = &v1beta1.RootSync{}
+		} else {
+			rs = &v1beta1.RepoSync{}
+		}
+		key := types.NamespacedName{Namespace: syncNamespace, Name: syncName}
+		if err := c.Get(cmd.Context(), key, rs); err != nil {
+			return errors.Wrapf(err, "failed to get %s", key)
+		}
+
+		// The reconciler resyncs the objects every time the timestamp changes.
+		requestedAt := time.Now().UTC().Format(time.RFC3339Nano)
+		patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q,%q:%q}}}`,
+			metadata.ResyncObjectsKey, value, metadata.ResyncRequestedAtKey, requestedAt))
+		if err := c.Patch(cmd.Context(), rs, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			return errors.Wrapf(err, "failed to annotate %s", key)
+		}
+		fmt.Printf("Requested the resync of %s for %s. Check the ObjectsResynced events of %s for the outcome.\n",
+			value, key, key)
+		return nil
+	},
+}
@@ -19,11 +19,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 )
 
 var (
 	namespaceValue string
+	syncNameValue  string
 	keepOutput     bool
 	outPath        string
 )
@@ -32,6 +34,7 @@ func init() {
 	flags.AddClusters(Cmd)
 	flags.AddPath(Cmd)
 	flags.AddSkipAPIServerCheck(Cmd)
+	flags.AddClusterState(Cmd)
 	flags.AddSourceFormat(Cmd)
 	flags.AddOutputFormat(Cmd)
 	flags.AddAPIServerTimeout(Cmd)
@@ -40,6 +43,11 @@ func init() {
 			"If set, validate the repository as a Namespace Repo with the provided name. Automatically sets --source-format=%s",
 			filesystem.SourceFormatUnstructured))
 
+	Cmd.Flags().StringVar(&syncNameValue, "sync-name", "",
+		fmt.Sprintf(
+			"The name of the RootSync or RepoSync syncing the repository, used with --cluster-state to check management conflicts. Defaults to %q, or %q if --namespace is provided",
+			configsync.RootSyncName, configsync.RepoSyncName))
+
 	Cmd.Flags().BoolVar(&keepOutput, "keep-output", false,
 		`If enabled, keep the hydrated output`)
 
@@ -64,6 +72,6 @@ returns a non-zero error code if any issues are found.
 		// Don't show usage on error, as argument validation passed.
 		cmd.SilenceUsage = true
 
-		return runVet(cmd.Context(), namespaceValue, filesystem.SourceFormat(flags.SourceFormat), flags.APIServerTimeout)
+		return runVet(cmd.Context(), namespaceValue, syncNameValue, filesystem.SourceFormat(flags.SourceFormat), flags.APIServerTimeout)
 	},
 }
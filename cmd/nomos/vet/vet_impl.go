@@ -25,6 +25,8 @@ import (
 	"kpt.dev/configsync/cmd/nomos/flags"
 	nomosparse "kpt.dev/configsync/cmd/nomos/parse"
 	"kpt.dev/configsync/cmd/nomos/util"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/hydrate"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
@@ -34,6 +36,7 @@ import (
 	"kpt.dev/configsync/pkg/parse"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/vet"
 )
 
 // vet runs nomos vet with the specified options.
@@ -42,6 +45,8 @@ import (
 // If relative, it is assumed to be relative to the working directory.
 // namespace, if non-emptystring, validates the repo as a CSMR Namespace
 // repository.
+// syncName is the name of the RootSync or RepoSync syncing the repository,
+// used to check the declared objects against the --cluster-state.
 //
 // sourceFormat is whether the repository is in the hierarchy or unstructured
 // format.
@@ -51,7 +56,7 @@ import (
 // clusters is the set of clusters we are checking.
 //
 // Only used if allClusters is false.
-func runVet(ctx context.Context, namespace string, sourceFormat filesystem.SourceFormat, apiServerTimeout time.Duration) error {
+func runVet(ctx context.Context, namespace, syncName string, sourceFormat filesystem.SourceFormat, apiServerTimeout time.Duration) error {
 	if sourceFormat == "" {
 		if namespace == "" {
 			// Default to hierarchical if --namespace is not provided.
@@ -110,6 +115,24 @@ func runVet(ctx context.Context, namespace string, sourceFormat filesystem.Sourc
 		return fmt.Errorf("unknown %s value %q", reconcilermanager.SourceFormat, sourceFormat)
 	}
 
+	scope := declared.RootReconciler
+	if namespace != "" {
+		scope = declared.Scope(namespace)
+	}
+	if syncName == "" {
+		if scope == declared.RootReconciler {
+			syncName = configsync.RootSyncName
+		} else {
+			syncName = configsync.RepoSyncName
+		}
+	}
+	var state *vet.ClusterState
+	if flags.ClusterState != "" {
+		if state, err = hydrate.ReadClusterState(); err != nil {
+			return err
+		}
+	}
+
 	filePaths := reader.FilePaths{
 		RootDir:   rootDir,
 		PolicyDir: cmpath.RelativeOS(rootDir.OSPath()),
@@ -132,6 +155,11 @@ func runVet(ctx context.Context, namespace string, sourceFormat filesystem.Sourc
 		}
 		numClusters++
 
+		if state != nil {
+			err = status.Append(err, state.ManagementConflicts(fileObjects, scope, syncName))
+			printPrunePreview(clusterName, state.PrunePreview(fileObjects, scope, syncName))
+		}
+
 		if err != nil {
 			if clusterName == "" {
 				clusterName = nomosparse.UnregisteredCluster
@@ -161,6 +189,22 @@ func runVet(ctx context.Context, namespace string, sourceFormat filesystem.Sourc
 	return nil
 }
 
+// printPrunePreview prints the objects of the --cluster-state which would be
+// pruned by syncing the repository.
+func printPrunePreview(clusterName string, ids []core.ID) {
+	if len(ids) == 0 {
+		return
+	}
+	if clusterName == "" || clusterName == nomosparse.UnregisteredCluster {
+		fmt.Println("Objects which would be pruned from the cluster:")
+	} else {
+		fmt.Printf("Objects which would be pruned from cluster %q:\n", clusterName)
+	}
+	for _, id := range ids {
+		fmt.Printf("  %s\n", id)
+	}
+}
+
 // clusterErrors is the set of vet errors for a specific Cluster.
 type clusterErrors struct {
 	name string
@@ -18,6 +18,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/google"
 	"k8s.io/klog/v2/klogr"
 	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/execcredential"
 	"kpt.dev/configsync/pkg/oci"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/util"
@@ -35,8 +37,10 @@ import (
 var flImage = flag.String("image", util.EnvString(reconcilermanager.OciSyncImage, ""),
 	"the OCI image repository for the package")
 var flAuth = flag.String("auth", util.EnvString(reconcilermanager.OciSyncAuth, string(configsync.AuthNone)),
-	fmt.Sprintf("the authentication type for access to the OCI package. Must be one of %s, %s, or %s. Defaults to %s",
-		configsync.AuthGCPServiceAccount, configsync.AuthGCENode, configsync.AuthNone, configsync.AuthNone))
+	fmt.Sprintf("the authentication type for access to the OCI package. Must be one of %s, %s, %s, or %s. Defaults to %s",
+		configsync.AuthGCPServiceAccount, configsync.AuthGCENode, configsync.AuthExec, configsync.AuthNone, configsync.AuthNone))
+var flExecCredential = flag.String("exec-credential", util.EnvString(reconcilermanager.ExecCredential, ""),
+	"the JSON encoded exec credential plugin, which vends the token when --auth is exec")
 var flRoot = flag.String("root", util.EnvString("OCI_SYNC_ROOT", util.EnvString("HOME", "")+"/oci"),
 	"the root directory for oci-sync operations, under which --dest will be created")
 var flDest = flag.String("dest", util.EnvString("OCI_SYNC_DEST", ""),
@@ -49,6 +53,8 @@ var flSyncTimeout = flag.Int("timeout", util.EnvInt("OCI_SYNC_TIMEOUT", 120),
 	"the max number of seconds allowed for a complete sync")
 var flOneTime = flag.Bool("one-time", util.EnvBool("OCI_SYNC_ONE_TIME", false),
 	"exit after the first sync")
+var flWebhookAddr = flag.String("webhook-addr", util.EnvString(reconcilermanager.OciSyncWebhookAddr, ""),
+	"the address of the registry webhook receiver, a notification sent to which triggers an immediate sync (defaults to \"\", disabling the receiver)")
 var flMaxSyncFailures = flag.Int("max-sync-failures", util.EnvInt("OCI_SYNC_MAX_SYNC_FAILURES", 0),
 	"the number of consecutive failures allowed before aborting (the first sync must succeed, -1 will retry forever after the initial sync)")
 
@@ -59,7 +65,8 @@ func main() {
 	log.Info("pulling OCI image with arguments", "--image", *flImage,
 		"--auth", *flAuth, "--root", *flRoot, "--dest", *flDest, "--wait", *flWait,
 		"--error-file", *flErrorFile, "--timeout", *flSyncTimeout,
-		"--one-time", *flOneTime, "--max-sync-failures", *flMaxSyncFailures)
+		"--one-time", *flOneTime, "--max-sync-failures", *flMaxSyncFailures,
+		"--webhook-addr", *flWebhookAddr)
 
 	if *flImage == "" {
 		utillog.HandleError(log, true, "ERROR: --image must be specified")
@@ -92,10 +99,30 @@ func main() {
 			utillog.HandleError(log, true, "ERROR: failed to get the authentication with type %q: %v", *flAuth, err)
 		}
 		auth = a
+	case configsync.AuthExec:
+		plugin, err := execcredential.Parse(*flExecCredential)
+		if err != nil {
+			utillog.HandleError(log, true, "ERROR: failed to get the authentication with type %q: %v", *flAuth, err)
+		}
+		auth = execcredential.NewProvider(plugin, execcredential.DefaultPluginDir)
 	default:
 		utillog.HandleError(log, true, "ERROR: unsupported authentication type %q", *flAuth)
 	}
 
+	// A nil channel never receives, so the loop only waits for the timer when
+	// the receiver is disabled.
+	var notifications <-chan struct{}
+	if *flWebhookAddr != "" {
+		receiver := oci.NewWebhookReceiver()
+		notifications = receiver.Notifications()
+		go func() {
+			log.Info("starting the registry webhook receiver", "address", *flWebhookAddr)
+			if err := http.ListenAndServe(*flWebhookAddr, receiver); err != nil {
+				utillog.HandleError(log, false, "ERROR: registry webhook receiver failed: %v", err)
+			}
+		}()
+	}
+
 	initialSync := true
 	failCount := 0
 	for {
@@ -111,7 +138,7 @@ func main() {
 			log.Error(err, "unexpected error fetching package, will retry")
 			log.Info("waiting before retrying", "waitTime", util.WaitTime(*flWait))
 			cancel()
-			time.Sleep(util.WaitTime(*flWait))
+			waitForNextSync(notifications, util.WaitTime(*flWait))
 			continue
 		}
 
@@ -127,7 +154,18 @@ func main() {
 		log.DeleteErrorFile()
 		log.Info("next sync", "wait_time", util.WaitTime(*flWait))
 		cancel()
-		time.Sleep(util.WaitTime(*flWait))
+		waitForNextSync(notifications, util.WaitTime(*flWait))
 	}
 
 }
+
+// waitForNextSync waits for the wait period to elapse, or for a registry
+// notification, whichever comes first.
+func waitForNextSync(notifications <-chan struct{}, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-notifications:
+	}
+}
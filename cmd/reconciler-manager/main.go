@@ -28,11 +28,14 @@ import (
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/profiler"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
+	"kpt.dev/configsync/pkg/util"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -48,6 +51,46 @@ var (
 		controllers.PollingPeriod(reconcilermanager.HydrationPollingPeriod, configsync.DefaultHydrationPollingPeriod),
 		"Period of time between checking the filesystem for source updates to render.")
 
+	publishSyncStatus = flag.Bool("publish-sync-status", false,
+		"Mirror the summarized status of all the RootSyncs and RepoSyncs into the "+controllers.SyncStatusSummaryName+" ConfigMap.")
+
+	notifications = flag.Bool("notifications", false,
+		"Notify the failures of the RootSyncs and RepoSyncs, as routed by the ConfigMaps labeled with "+metadata.NotificationRouteLabel+"=true in the "+configsync.ControllerNamespace+" namespace.")
+
+	badgeAddr = flag.String("badge-addr", "",
+		"The address the sync status badge endpoint binds to, like \":8090\". Empty disables the endpoint.")
+
+	dashboardAddr = flag.String("dashboard-addr", "",
+		"The address the read-only dashboard of the RootSyncs and RepoSyncs binds to, like \":8091\". Empty disables the dashboard. "+
+			"The dashboard has no authentication of its own, so it also requires --dashboard-allow-unauthenticated.")
+
+	dashboardAllowUnauthenticated = flag.Bool("dashboard-allow-unauthenticated", false,
+		"Acknowledge that the dashboard serves the status of all the RootSyncs and RepoSyncs to anyone who can reach --dashboard-addr, "+
+			"so it must be exposed only through the authenticating proxy of the cluster, or with a port-forward.")
+
+	gitWebhookReceiver = flag.Bool("git-webhook-receiver", util.EnvBool(reconcilermanager.GitWebhookReceiverEnabled, false),
+		"Run the git webhook receiver, which requests an immediate sync of the RootSyncs and RepoSyncs when their Git repository receives a push.")
+
+	gitWebhookReceiverImage = flag.String("git-webhook-receiver-image", os.Getenv(reconcilermanager.GitWebhookReceiverImage),
+		"The image of the git webhook receiver.")
+
+	tenantNamespaces = flag.String("tenant-namespaces", os.Getenv(reconcilermanager.TenantNamespaces),
+		"Comma-separated list of the namespaces whose RepoSyncs are reconciled, to run with only namespaced permissions. "+
+			"RootSyncs are not reconciled in this mode. Empty reconciles all the RootSyncs and RepoSyncs, which requires cluster-admin.")
+
+	oneShotJobs = flag.Bool("one-shot-jobs", false,
+		"Run the reconcilers of the RootSyncs and RepoSyncs with the one-shot mode as Jobs, which complete once the source commit is synced. "+
+			"Otherwise, the one-shot RSyncs keep syncing like the continuous ones.")
+
+	exportSink = flag.String("export-sink", os.Getenv(reconcilermanager.ExportSink),
+		"The URL of the sink the reconcilers export their sync attempts, applied objects and drift events to, "+
+			"either bigquery://<project>/<dataset> or a JSON-over-HTTP endpoint. Empty disables exporting.")
+
+	repoSyncPluginImages = flag.String("repo-sync-plugin-images", "",
+		"Comma-separated list of the images which the exec credential and converter plugins of the RepoSyncs may use. "+
+			"The plugins run in the reconciler Pods, so RepoSyncs cannot use plugins from other images. "+
+			"Empty disallows the plugins of the RepoSyncs. The plugins of the RootSyncs may use any image.")
+
 	setupLog = ctrl.Log.WithName("setup")
 )
 
@@ -69,9 +112,16 @@ func main() {
 	profiler.Service()
 	ctrl.SetLogger(klogr.New())
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgrOptions := ctrl.Options{
 		Scheme: core.Scheme,
-	})
+	}
+	tenants := controllers.ParseTenantNamespaces(*tenantNamespaces)
+	namespacedOnly := len(tenants) > 0
+	if namespacedOnly {
+		setupLog.Info("Running with only namespaced permissions", "tenantNamespaces", tenants)
+		mgrOptions.NewCache = cache.MultiNamespacedCacheBuilder(controllers.NamespacedOnlyCacheNamespaces(tenants))
+	}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), mgrOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -81,40 +131,191 @@ func main() {
 		setupLog.Error(err, "failed to build dynamic client")
 		os.Exit(1)
 	}
-	watchFleetMembership := fleetMembershipCRDExists(dynamicClient, mgr.GetRESTMapper())
+	// The Fleet membership is cluster-scoped, so it cannot be watched with
+	// only namespaced permissions.
+	watchFleetMembership := !namespacedOnly && fleetMembershipCRDExists(dynamicClient, mgr.GetRESTMapper())
 
 	repoSync := controllers.NewRepoSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, mgr.GetClient(), dynamicClient,
 		ctrl.Log.WithName("controllers").WithName(configsync.RepoSyncKind),
 		mgr.GetScheme())
+	if namespacedOnly {
+		repoSync.SetNamespacedOnly()
+	}
+	if *oneShotJobs {
+		repoSync.SetOneShotJobs()
+	}
+	if *exportSink != "" {
+		repoSync.SetExportSink(*exportSink)
+	}
+	repoSync.SetPluginImages(controllers.ParsePluginImages(*repoSyncPluginImages))
 	if err := repoSync.SetupWithManager(mgr, watchFleetMembership); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", configsync.RepoSyncKind)
 		os.Exit(1)
 	}
 
-	rootSync := controllers.NewRootSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, mgr.GetClient(), dynamicClient,
-		ctrl.Log.WithName("controllers").WithName(configsync.RootSyncKind),
-		mgr.GetScheme())
-	if err := rootSync.SetupWithManager(mgr, watchFleetMembership); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", configsync.RootSyncKind)
-		os.Exit(1)
+	// The root reconcilers need cluster-admin, so RootSyncs are not reconciled
+	// with only namespaced permissions.
+	if !namespacedOnly {
+		rootSync := controllers.NewRootSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, mgr.GetClient(), dynamicClient,
+			ctrl.Log.WithName("controllers").WithName(configsync.RootSyncKind),
+			mgr.GetScheme())
+		if *oneShotJobs {
+			rootSync.SetOneShotJobs()
+		}
+		if *exportSink != "" {
+			rootSync.SetExportSink(*exportSink)
+		}
+		if err := rootSync.SetupWithManager(mgr, watchFleetMembership); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", configsync.RootSyncKind)
+			os.Exit(1)
+		}
 	}
 
-	otel := controllers.NewOtelReconciler(*clusterName, mgr.GetClient(),
-		ctrl.Log.WithName("controllers").WithName("Otel"),
-		mgr.GetScheme())
-	if err := otel.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Otel")
-		os.Exit(1)
+	// The otel-collector runs in the config-management-monitoring namespace,
+	// which is not watched with only namespaced permissions.
+	if !namespacedOnly {
+		otel := controllers.NewOtelReconciler(*clusterName, mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName("Otel"),
+			mgr.GetScheme())
+		if err := otel.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Otel")
+			os.Exit(1)
+		}
+
+		otelTenant := controllers.NewOtelTenantReconciler(mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName(controllers.OtelTenantLoggerName),
+			mgr.GetScheme())
+		if err := otelTenant.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", controllers.OtelTenantLoggerName)
+			os.Exit(1)
+		}
+
+		otelSA := controllers.NewOtelSAReconciler(*clusterName, mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName(controllers.OtelSALoggerName),
+			mgr.GetScheme())
+		if err := otelSA.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "OtelSA")
+			os.Exit(1)
+		}
 	}
 
-	otelSA := controllers.NewOtelSAReconciler(*clusterName, mgr.GetClient(),
-		ctrl.Log.WithName("controllers").WithName(controllers.OtelSALoggerName),
-		mgr.GetScheme())
-	if err := otelSA.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "OtelSA")
+	// The ValidatingWebhookConfiguration is cluster-scoped, so the health of
+	// the admission webhook is not checked with only namespaced permissions.
+	if !namespacedOnly {
+		webhookHealth := controllers.NewWebhookHealthChecker(mgr.GetClient(),
+			ctrl.Log.WithName("webhook-health"))
+		if err := mgr.Add(webhookHealth); err != nil {
+			setupLog.Error(err, "unable to add the webhook health checker")
+			os.Exit(1)
+		}
+	}
+
+	// The inventories of all the RootSyncs and RepoSyncs are cross-referenced,
+	// which requires listing them in all the namespaces.
+	if !namespacedOnly {
+		overlapAnalyzer := controllers.NewOverlapAnalyzer(mgr.GetClient(),
+			ctrl.Log.WithName("overlap-analyzer"))
+		if err := mgr.Add(overlapAnalyzer); err != nil {
+			setupLog.Error(err, "unable to add the overlap analyzer")
+			os.Exit(1)
+		}
+	}
+
+	// The expiring annotations are also set on cluster-scoped objects, which
+	// are not watched with only namespaced permissions.
+	if !namespacedOnly {
+		annotationTTL := controllers.NewAnnotationTTLController(mgr.GetClient(),
+			ctrl.Log.WithName("annotation-ttl"))
+		if err := mgr.Add(annotationTTL); err != nil {
+			setupLog.Error(err, "unable to add the annotation TTL controller")
+			os.Exit(1)
+		}
+	}
+
+	// The ConfigSyncStatus is cluster-scoped, and aggregates the components in
+	// other namespaces, so it is not maintained with only namespaced
+	// permissions.
+	if !namespacedOnly {
+		installationStatus := controllers.NewInstallationStatusController(mgr.GetClient(),
+			ctrl.Log.WithName("installation-status"))
+		if err := mgr.Add(installationStatus); err != nil {
+			setupLog.Error(err, "unable to add the installation status controller")
+			os.Exit(1)
+		}
+	}
+
+	var publishers []controllers.StatusPublisher
+	if *publishSyncStatus {
+		publishers = append(publishers, controllers.NewConfigMapStatusPublisher(mgr.GetClient()))
+	}
+	if *notifications {
+		// The notifications are routed by the labels of the namespaces, which
+		// are cluster-scoped.
+		if namespacedOnly {
+			setupLog.Error(nil, "--notifications is not supported with --tenant-namespaces")
+			os.Exit(1)
+		}
+		publishers = append(publishers, controllers.NewNotificationPublisher(mgr.GetClient(),
+			ctrl.Log.WithName("notifications")))
+	}
+	if *badgeAddr != "" {
+		badgeServer := controllers.NewBadgeServer(*badgeAddr, ctrl.Log.WithName("badges"))
+		if err := mgr.Add(badgeServer); err != nil {
+			setupLog.Error(err, "unable to add the badge server")
+			os.Exit(1)
+		}
+		publishers = append(publishers, badgeServer)
+	}
+	if *dashboardAddr != "" {
+		// The dashboard lists the RSyncs and their events in all the
+		// namespaces.
+		if namespacedOnly {
+			setupLog.Error(nil, "--dashboard-addr is not supported with --tenant-namespaces")
+			os.Exit(1)
+		}
+		if !*dashboardAllowUnauthenticated {
+			setupLog.Error(nil, "--dashboard-addr requires --dashboard-allow-unauthenticated, since the dashboard has no authentication")
+			os.Exit(1)
+		}
+		// The dashboard reads through the API reader, to not cache all the
+		// events of the cluster.
+		dashboard := controllers.NewDashboard(*dashboardAddr, mgr.GetAPIReader(), ctrl.Log.WithName("dashboard"))
+		if err := mgr.Add(dashboard); err != nil {
+			setupLog.Error(err, "unable to add the dashboard")
+			os.Exit(1)
+		}
+	}
+	if len(publishers) > 0 {
+		statusPublisher := controllers.NewStatusPublisherReconciler(mgr.GetClient(),
+			controllers.NewMultiStatusPublisher(publishers...),
+			ctrl.Log.WithName("controllers").WithName(controllers.StatusPublisherLoggerName),
+			mgr.GetScheme())
+		if err := statusPublisher.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", controllers.StatusPublisherLoggerName)
+			os.Exit(1)
+		}
+	}
+
+	signingKey := controllers.NewHydrationSigningKey(mgr.GetClient(),
+		ctrl.Log.WithName("hydration-signing-key"))
+	if err := mgr.Add(signingKey); err != nil {
+		setupLog.Error(err, "unable to add the hydration signing key")
 		os.Exit(1)
 	}
 
+	if *gitWebhookReceiver {
+		if *gitWebhookReceiverImage == "" {
+			setupLog.Error(nil, "--git-webhook-receiver-image must be set to run the git webhook receiver")
+			os.Exit(1)
+		}
+		receiver := controllers.NewGitWebhookReceiver(mgr.GetClient(), *gitWebhookReceiverImage,
+			ctrl.Log.WithName(reconcilermanager.GitWebhookReceiver))
+		if err := mgr.Add(receiver); err != nil {
+			setupLog.Error(err, "unable to add the git webhook receiver")
+			os.Exit(1)
+		}
+	}
+
 	// Register the OpenCensus views
 	if err := metrics.RegisterReconcilerManagerMetricsViews(); err != nil {
 		setupLog.Error(err, "failed to register OpenCensus views")
@@ -15,23 +15,36 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/converter"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/execcredential"
+	"kpt.dev/configsync/pkg/export"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
 	ocmetrics "kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/profiler"
 	"kpt.dev/configsync/pkg/reconciler"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
+	"kpt.dev/configsync/pkg/rename"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncwindow"
+	"kpt.dev/configsync/pkg/util"
 	"kpt.dev/configsync/pkg/util/log"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -78,6 +91,17 @@ var (
 	pollingPeriod = flag.Duration("filesystem-polling-period",
 		controllers.PollingPeriod(reconcilermanager.ReconcilerPollingPeriod, configsync.DefaultReconcilerPollingPeriod),
 		"Period of time between checking the filesystem for source updates to sync.")
+	statusUpdatePeriod = flag.Duration("status-update-period",
+		controllers.PollingPeriod(reconcilermanager.StatusUpdatePeriod, configsync.DefaultReconcilerSyncStatusUpdatePeriod),
+		"Period of time between the updates of the sync status, while syncing and to report the management conflicts.")
+	watchSource = flag.Bool("watch-source", util.EnvBool(reconcilermanager.WatchSource, false),
+		"Reimport the source as soon as it is fetched or rendered, instead of waiting for the next filesystem polling period.")
+	supersedeInFlightApply = flag.Bool("supersede-in-flight-apply", util.EnvBool(reconcilermanager.SupersedeInFlightApply, false),
+		"Stop applying a commit as soon as a newer commit is fetched, rendered and validated, and apply the newer commit instead.")
+	partialApply = flag.Bool("partial-apply", util.EnvBool(reconcilermanager.PartialApply, false),
+		"Only apply the objects declared in the source files changed since the last successful apply, along with their dependents. All the objects are applied on every resync.")
+	metricsLevel = flag.String("metrics-level", util.EnvString(reconcilermanager.MetricsLevel, ocmetrics.LevelFull),
+		"The metrics emitted by the reconciler: disabled, basic or full.")
 
 	// Root-Repo-only flags. If set for a Namespace-scoped Reconciler, causes the Reconciler to fail immediately.
 	sourceFormat = flag.String(flags.sourceFormat, os.Getenv(filesystem.SourceFormatKey),
@@ -90,27 +114,120 @@ var (
 
 	apiServerTimeout = flag.String("api-server-timeout", os.Getenv(reconcilermanager.APIServerTimeout), "The client-side timeout for requests to the API server")
 
+	upgradeSettlePeriod = flag.Duration("upgrade-settle-period", controllers.PollingPeriod(reconcilermanager.UpgradeSettlePeriod, 0),
+		"How long to hold off applying new commits after detecting a cluster control plane upgrade. Zero disables the upgrade detection.")
+
+	selfUpdateTimeout = flag.Duration("self-update-timeout", controllers.PollingPeriod(reconcilermanager.SelfUpdateTimeout, 0),
+		"How long to wait for the Config Sync components declared in the source to become healthy after changing them, before reverting them. Zero applies them without a health check. Only applicable to the root reconciler.")
+
+	retryBudget = flag.Int("retry-budget", util.EnvInt(reconcilermanager.RetryBudget, 0),
+		"How many consecutive failed retries of a commit to attempt before giving up until the source changes. Zero retries forever.")
+
+	pruneObsoleteMetadata = flag.Bool("prune-obsolete-metadata", util.EnvBool(reconcilermanager.PruneObsoleteMetadata, false),
+		"Remove the annotations and labels set by previous versions of Config Sync, which are no longer used, from the managed objects during apply.")
+
+	migrateClientSideApply = flag.Bool("migrate-client-side-apply", util.EnvBool(reconcilermanager.MigrateClientSideApply, false),
+		"Migrate the objects previously managed with kubectl client-side apply to Config Sync server-side apply, by transferring the fields owned by client-side apply to Config Sync and removing the last-applied-configuration annotation.")
+
+	normalizeDeclarations = flag.Bool("normalize-declarations", util.EnvBool(reconcilermanager.NormalizeDeclarations, false),
+		"Normalize the declarations of objects with their OpenAPI schemas, dropping the fields set to their defaults and formatting quantities and durations canonically, before comparing them with their previous declarations. Pure formatting or defaulting changes are then not reported as changes, e.g. to frozen namespaces.")
+
+	commonLabels = flag.String(flags.commonLabels, os.Getenv(reconcilermanager.CommonLabels),
+		"JSON object of the labels to add to every applied object. The labels declared on an object take precedence.")
+
+	commonAnnotations = flag.String(flags.commonAnnotations, os.Getenv(reconcilermanager.CommonAnnotations),
+		"JSON object of the annotations to add to every applied object. The annotations declared on an object take precedence.")
+
+	pruneDelay = flag.Duration("prune-delay", controllers.PollingPeriod(reconcilermanager.PruneDelay, 0),
+		"How long to hold off deleting the objects removed from the source of truth. Zero prunes them immediately.")
+
+	approvalTimeout = flag.Duration("approval-timeout", controllers.PollingPeriod(reconcilermanager.ApprovalTimeout, 0),
+		"How long to wait for the approval of a new commit before applying it. Zero applies new commits without approval.")
+
+	oneShot = flag.Bool("one-shot", util.EnvBool(reconcilermanager.OneShot, false),
+		"Exit once the source of truth is synced, instead of syncing continuously. Used when the reconciler runs in a Job.")
+
+	pruneAllowedKinds = flag.String("prune-allowed-kinds", os.Getenv(reconcilermanager.PruneAllowedKinds),
+		"Comma-separated list of the kinds of the objects to delete when they are removed from the source of truth, in the Kind.group format. Empty allows all kinds.")
+
+	pruneDeniedKinds = flag.String("prune-denied-kinds", os.Getenv(reconcilermanager.PruneDeniedKinds),
+		"Comma-separated list of the kinds of the objects to never delete, in the Kind.group format. The objects of these kinds are unmanaged instead.")
+
+	pruneMaxPercentage = flag.Int("prune-max-percentage", util.EnvInt(reconcilermanager.PruneMaxPercentage, 0),
+		"Largest percentage of the managed objects to prune at once without confirmation. Zero disables the limit.")
+
+	pruneMaxCount = flag.Int("prune-max-count", util.EnvInt(reconcilermanager.PruneMaxCount, 0),
+		"Largest number of the managed objects to prune at once without confirmation. Zero disables the limit.")
+
+	maxWorkloadRollouts = flag.Int("rollout-max-concurrent-updates", util.EnvInt(reconcilermanager.RolloutMaxConcurrentUpdates, 0),
+		"Largest number of the managed Deployments, StatefulSets and DaemonSets to roll out at once. Zero disables the limit.")
+
+	maxNamespaceRollouts = flag.Int("rollout-max-concurrent-updates-per-namespace", util.EnvInt(reconcilermanager.RolloutMaxConcurrentUpdatesPerNamespace, 0),
+		"Largest number of the managed Deployments, StatefulSets and DaemonSets of a namespace to roll out at once. Zero disables the limit.")
+
+	apiPriorityGroup = flag.String("api-priority-group", os.Getenv(reconcilermanager.APIPriorityGroup),
+		"Group to add to the identity of the reconciler on its API requests, so that a FlowSchema can match them. Requires the permission to impersonate the reconciler service account and the group.")
+
+	targetKubeconfig = flag.String("target-kubeconfig", os.Getenv(reconcilermanager.TargetKubeconfig),
+		"Path to the kubeconfig of the cluster to sync resources to, if it is not the current cluster. Only applicable to the root reconciler.")
+
+	targets = flag.String("targets", os.Getenv(reconcilermanager.Targets),
+		"JSON list of the clusters to fan the resources out to, each with a name, a kubeconfig path and variables. Only applicable to the root reconciler.")
+
+	execCredential = flag.String("exec-credential", os.Getenv(reconcilermanager.ExecCredential),
+		"JSON encoded exec credential plugin, whose token the reconciler serves to git-sync from the git askpass endpoint.")
+
+	hierarchicalDirs = flag.String("hierarchical-dirs", os.Getenv(reconcilermanager.HierarchicalDirs),
+		"Comma-separated list of the directories of an unstructured repository, relative to the sync directory, which are in the hierarchy format. "+
+			"Only applicable to the root reconciler.")
+
+	syncWindows = flag.String("sync-windows", os.Getenv(reconcilermanager.SyncWindows),
+		"JSON-encoded list of the sync windows, which restrict when new commits are applied. Empty applies new commits at any time.")
+
+	dryRun = flag.Bool("dry-run", util.EnvBool(reconcilermanager.DryRun, false),
+		"Preview the commits with server-side dry-run applies, and report the objects they would add, change or prune, "+
+			"instead of syncing them.")
+
+	renames = flag.String("renames", os.Getenv(reconcilermanager.Renames),
+		"JSON-encoded list of the rules renaming the declared objects after rendering. Empty keeps the declared names.")
+
+	parseConcurrency = flag.Int("parse-concurrency", util.EnvInt(reconcilermanager.ParseConcurrency, 1),
+		"Largest number of the source files to parse at once, which speeds up parsing the sources with many files. "+
+			"Values below 2 parse the files one at a time.")
+
+	converterPlugin = flag.String("converter", os.Getenv(reconcilermanager.Converter),
+		"JSON encoded converter plugin, which converts the source files with its extensions into Kubernetes manifests. "+
+			"Empty parses the files as manifests.")
+
+	exportSink = flag.String("export-sink", os.Getenv(reconcilermanager.ExportSink),
+		"The URL of the sink the sync attempts, applied objects and drift events are exported to, "+
+			"either bigquery://<project>/<dataset> or a JSON-over-HTTP endpoint. Empty disables exporting.")
+
 	debug = flag.Bool("debug", false,
 		"Enable debug mode, panicking in many scenarios where normally an InternalError would be logged. "+
 			"Do not use in production.")
 )
 
 var flags = struct {
-	sourceDir        string
-	repoRootDir      string
-	hydratedRootDir  string
-	clusterName      string
-	sourceFormat     string
-	statusMode       string
-	reconcileTimeout string
+	sourceDir         string
+	repoRootDir       string
+	hydratedRootDir   string
+	clusterName       string
+	sourceFormat      string
+	statusMode        string
+	reconcileTimeout  string
+	commonLabels      string
+	commonAnnotations string
 }{
-	repoRootDir:      "repo-root",
-	sourceDir:        "source-dir",
-	hydratedRootDir:  "hydrated-root",
-	clusterName:      "cluster-name",
-	sourceFormat:     reconcilermanager.SourceFormat,
-	statusMode:       "status-mode",
-	reconcileTimeout: "reconcile-timeout",
+	repoRootDir:       "repo-root",
+	sourceDir:         "source-dir",
+	hydratedRootDir:   "hydrated-root",
+	clusterName:       "cluster-name",
+	sourceFormat:      reconcilermanager.SourceFormat,
+	statusMode:        "status-mode",
+	reconcileTimeout:  "reconcile-timeout",
+	commonLabels:      "common-labels",
+	commonAnnotations: "common-annotations",
 }
 
 func main() {
@@ -123,21 +240,29 @@ func main() {
 	}
 
 	// Register the OpenCensus views
-	if err := ocmetrics.RegisterReconcilerMetricsViews(); err != nil {
+	if err := ocmetrics.RegisterReconcilerMetricsViews(*metricsLevel); err != nil {
 		klog.Fatalf("Failed to register OpenCensus views: %v", err)
 	}
 
-	// Register the OC Agent exporter
-	oce, err := ocmetrics.RegisterOCAgentExporter(reconcilermanager.Reconciler)
-	if err != nil {
-		klog.Fatalf("Failed to register the OC Agent exporter: %v", err)
-	}
-
-	defer func() {
-		if err := oce.Stop(); err != nil {
-			klog.Fatalf("Unable to stop the OC Agent exporter: %v", err)
+	if *metricsLevel == ocmetrics.LevelDisabled {
+		klog.Info("Metrics are disabled")
+	} else {
+		// Register the OC Agent exporter
+		oce, err := ocmetrics.RegisterOCAgentExporter(reconcilermanager.Reconciler)
+		if err != nil {
+			klog.Fatalf("Failed to register the OC Agent exporter: %v", err)
 		}
-	}()
+		// Export the spans of every run too, so that a sync attempt can be
+		// traced end to end through the otel-collector.
+		trace.RegisterExporter(oce)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+
+		defer func() {
+			if err := oce.Stop(); err != nil {
+				klog.Fatalf("Unable to stop the OC Agent exporter: %v", err)
+			}
+		}()
+	}
 
 	absRepoRoot, err := cmpath.AbsoluteOS(*repoRootDir)
 	if err != nil {
@@ -160,6 +285,35 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	if *exportSink != "" {
+		exporter, err := export.Start(context.Background(), *exportSink, exportedSync())
+		if err != nil {
+			klog.Fatalf("Failed to start the exporter: %v", err)
+		}
+		defer exporter.Stop()
+	}
+
+	if *execCredential != "" {
+		serveExecCredentialAskpass(*execCredential)
+	}
+
+	windows, err := parseSyncWindows(*syncWindows)
+	if err != nil {
+		klog.Fatalf("Invalid sync windows: %v", err)
+	}
+	renameRules, err := parseRenames(*renames)
+	if err != nil {
+		klog.Fatalf("Invalid rename rules: %v", err)
+	}
+	var fileConverter reader.Converter
+	if *converterPlugin != "" {
+		plugin, err := converter.Parse(*converterPlugin)
+		if err != nil {
+			klog.Fatalf("Invalid converter plugin: %v", err)
+		}
+		fileConverter = converter.NewExec(plugin, converter.DefaultPluginDir)
+	}
+
 	opts := reconciler.Options{
 		ClusterName:             *clusterName,
 		FightDetectionThreshold: *fightDetectionThreshold,
@@ -167,12 +321,16 @@ func main() {
 		ReconcilerScope:         declared.Scope(*scope),
 		ResyncPeriod:            *resyncPeriod,
 		PollingPeriod:           *pollingPeriod,
+		WatchSource:             *watchSource,
+		SupersedeInFlightApply:  *supersedeInFlightApply,
+		PartialApply:            *partialApply,
 		RetryPeriod:             configsync.DefaultReconcilerRetryPeriod,
-		StatusUpdatePeriod:      configsync.DefaultReconcilerSyncStatusUpdatePeriod,
+		StatusUpdatePeriod:      *statusUpdatePeriod,
 		SourceRoot:              absSourceDir,
 		RepoRoot:                absRepoRoot,
 		HydratedRoot:            *hydratedRootDir,
 		HydratedLink:            *hydratedLinkDir,
+		HydrationSigningKey:     []byte(os.Getenv(reconcilermanager.HydrationSigningKey)),
 		SourceRev:               *sourceRev,
 		SourceBranch:            *sourceBranch,
 		SourceType:              v1beta1.SourceType(*sourceType),
@@ -183,6 +341,29 @@ func main() {
 		StatusMode:              *statusMode,
 		ReconcileTimeout:        *reconcileTimeout,
 		APIServerTimeout:        *apiServerTimeout,
+		UpgradeSettlePeriod:     *upgradeSettlePeriod,
+		SelfUpdateTimeout:       *selfUpdateTimeout,
+		RetryBudget:             *retryBudget,
+		PruneObsoleteMetadata:   *pruneObsoleteMetadata,
+		MigrateClientSideApply:  *migrateClientSideApply,
+		NormalizeDeclarations:   *normalizeDeclarations,
+		CommonLabels:            parseStringMap(flags.commonLabels, *commonLabels),
+		CommonAnnotations:       parseStringMap(flags.commonAnnotations, *commonAnnotations),
+		PruneDelay:              *pruneDelay,
+		ApprovalTimeout:         *approvalTimeout,
+		OneShot:                 *oneShot,
+		SyncWindows:             windows,
+		DryRun:                  *dryRun,
+		Renames:                 renameRules,
+		APIPriorityGroup:        *apiPriorityGroup,
+		PruneAllowedKinds:       parseGroupKinds(*pruneAllowedKinds),
+		PruneDeniedKinds:        parseGroupKinds(*pruneDeniedKinds),
+		PruneMaxPercentage:      *pruneMaxPercentage,
+		PruneMaxCount:           *pruneMaxCount,
+		MaxWorkloadRollouts:     *maxWorkloadRollouts,
+		MaxNamespaceRollouts:    *maxNamespaceRollouts,
+		ParseConcurrency:        *parseConcurrency,
+		Converter:               fileConverter,
 	}
 
 	if declared.Scope(*scope) == declared.RootReconciler {
@@ -192,9 +373,24 @@ func main() {
 			format = filesystem.SourceFormatHierarchy
 		}
 
+		var syncTargets []reconcilermanager.TargetConfig
+		if *targets != "" {
+			if err := json.Unmarshal([]byte(*targets), &syncTargets); err != nil {
+				klog.Fatalf("Error parsing the sync targets: %v", err)
+			}
+		}
+
 		klog.Info("Starting reconciler for: root")
+		dirs := parseRelativePaths(*hierarchicalDirs)
+		if len(dirs) > 0 && format != filesystem.SourceFormatUnstructured {
+			klog.Fatalf("--hierarchical-dirs is only supported for the %s source format", filesystem.SourceFormatUnstructured)
+		}
+
 		opts.RootOptions = &reconciler.RootOptions{
-			SourceFormat: format,
+			SourceFormat:     format,
+			HierarchicalDirs: dirs,
+			TargetKubeconfig: *targetKubeconfig,
+			Targets:          syncTargets,
 		}
 	} else {
 		klog.Infof("Starting reconciler for: %s", *scope)
@@ -203,6 +399,112 @@ func main() {
 			klog.Fatalf("Flag %s and Environment variable%q must not be passed to a Namespace reconciler",
 				flags.sourceFormat, filesystem.SourceFormatKey)
 		}
+		if *hierarchicalDirs != "" {
+			klog.Fatalf("Flag hierarchical-dirs and Environment variable %q must not be passed to a Namespace reconciler",
+				reconcilermanager.HierarchicalDirs)
+		}
 	}
 	reconciler.Run(opts)
 }
+
+// parseSyncWindows parses the JSON-encoded sync windows.
+func parseSyncWindows(value string) ([]syncwindow.Window, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var windows []v1beta1.SyncWindow
+	if err := json.Unmarshal([]byte(value), &windows); err != nil {
+		return nil, err
+	}
+	return syncwindow.Parse(windows)
+}
+
+// parseRenames parses the JSON-encoded rename rules.
+func parseRenames(value string) ([]rename.Rule, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var rules []v1beta1.RenameRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, err
+	}
+	return rename.Parse(rules)
+}
+
+// exportedSync returns the identity of the RootSync or RepoSync of the
+// reconciler in the exported records.
+func exportedSync() export.Sync {
+	if declared.Scope(*scope) == declared.RootReconciler {
+		return export.Sync{
+			Cluster:       *clusterName,
+			SyncKind:      configsync.RootSyncKind,
+			SyncNamespace: configsync.ControllerNamespace,
+			SyncName:      *syncName,
+		}
+	}
+	return export.Sync{
+		Cluster:       *clusterName,
+		SyncKind:      configsync.RepoSyncKind,
+		SyncNamespace: *scope,
+		SyncName:      *syncName,
+	}
+}
+
+// parseGroupKinds parses a comma-separated list of GroupKinds in the
+// `Kind.group` format.
+func parseGroupKinds(value string) []schema.GroupKind {
+	var gks []schema.GroupKind
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		gks = append(gks, schema.ParseGroupKind(item))
+	}
+	return gks
+}
+
+// serveExecCredentialAskpass serves the git askpass endpoint, from which
+// git-sync fetches the token vended by the exec credential plugin.
+func serveExecCredentialAskpass(encoded string) {
+	plugin, err := execcredential.Parse(encoded)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(execcredential.AskpassPath, execcredential.NewProvider(plugin, execcredential.DefaultPluginDir))
+	addr := fmt.Sprintf("localhost:%d", reconcilermanager.CredentialAskpassPort)
+	go func() {
+		klog.Infof("Serving the git askpass endpoint at %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			klog.Fatalf("Git askpass endpoint failed: %v", err)
+		}
+	}()
+}
+
+// parseRelativePaths parses a comma-separated list of slash-separated
+// relative paths.
+func parseRelativePaths(value string) []cmpath.Relative {
+	var paths []cmpath.Relative
+	for _, item := range strings.Split(value, ",") {
+		item = strings.Trim(strings.TrimSpace(item), "/")
+		if item == "" {
+			continue
+		}
+		paths = append(paths, cmpath.RelativeSlash(item))
+	}
+	return paths
+}
+
+// parseStringMap parses the JSON object of strings set by the flag with the
+// given name.
+func parseStringMap(flagName, value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		klog.Fatalf("Invalid --%s: %v", flagName, err)
+	}
+	return result
+}
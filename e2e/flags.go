@@ -33,6 +33,24 @@ var E2E = flag.Bool("e2e", false,
 var Load = flag.Bool("load", false,
 	"If true, run load tests.")
 
+// LoadRepoSyncs is the number of RepoSyncs the fleet load test creates.
+var LoadRepoSyncs = flag.Int("load-repo-syncs", 10,
+	"The number of RepoSyncs created by the fleet load test.")
+
+// LoadObjects is the number of objects declared by each RepoSync in the fleet
+// load test.
+var LoadObjects = flag.Int("load-objects", 50,
+	"The number of objects declared by each RepoSync in the fleet load test.")
+
+// LoadChurnPercent is the percentage of objects changed by each iteration of
+// the fleet load test.
+var LoadChurnPercent = flag.Int("load-churn-percent", 10,
+	"The percentage of objects changed by each iteration of the fleet load test.")
+
+// LoadIterations is the number of churn iterations of the fleet load test.
+var LoadIterations = flag.Int("load-iterations", 5,
+	"The number of churn iterations of the fleet load test.")
+
 // Stress enables running of stress tests.
 var Stress = flag.Bool("stress", false,
 	"If true, run stress tests.")
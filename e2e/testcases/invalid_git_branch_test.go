@@ -36,7 +36,7 @@ func TestInvalidRootSyncBranchStatus(t *testing.T) {
 	// Update RootSync to invalid branch name
 	nomostest.SetGitBranch(nt, configsync.RootSyncName, "invalid-branch")
 
-	nt.WaitForRootSyncSourceError(configsync.RootSyncName, status.SourceErrorCode, "")
+	nt.WaitForRootSyncSourceError(configsync.RootSyncName, status.SourceNotFoundErrorCode, "")
 
 	rootReconcilerPod, err := nt.KubeClient.GetDeploymentPod(
 		nomostest.DefaultRootReconcilerName, configmanagement.ControllerNamespace,
@@ -75,7 +75,7 @@ func TestInvalidRepoSyncBranchStatus(t *testing.T) {
 	nt.Must(nt.RootRepos[configsync.RootSyncName].Add(nomostest.StructuredNSPath(namespaceRepo, rs.Name), rs))
 	nt.Must(nt.RootRepos[configsync.RootSyncName].CommitAndPush("Update RepoSync to invalid branch name"))
 
-	nt.WaitForRepoSyncSourceError(namespaceRepo, configsync.RepoSyncName, status.SourceErrorCode, "")
+	nt.WaitForRepoSyncSourceError(namespaceRepo, configsync.RepoSyncName, status.SourceNotFoundErrorCode, "")
 
 	err := nomostest.ValidateStandardMetricsForRootSync(nt, metrics.Summary{
 		Sync: nomostest.RootSyncNN(configsync.RootSyncName),
@@ -169,7 +169,7 @@ func TestSyncFailureAfterSuccessfulSyncs(t *testing.T) {
 
 	// Make the sync fail by invalidating the source repo.
 	nt.Must(nt.RootRepos[configsync.RootSyncName].RenameBranch(devBranch, "invalid-branch"))
-	nt.WaitForRootSyncSourceError(configsync.RootSyncName, status.SourceErrorCode, "")
+	nt.WaitForRootSyncSourceError(configsync.RootSyncName, status.SourceNotFoundErrorCode, "")
 
 	// Change the remote branch name back to the original name.
 	nt.Must(nt.RootRepos[configsync.RootSyncName].RenameBranch("invalid-branch", devBranch))
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/e2e"
+	"kpt.dev/configsync/e2e/nomostest"
+	"kpt.dev/configsync/e2e/nomostest/ntopts"
+	"kpt.dev/configsync/e2e/nomostest/policy"
+	nomostesting "kpt.dev/configsync/e2e/nomostest/testing"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/loadtest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestLoadRepoSyncFleet syncs a fleet of RepoSyncs which all read from the
+// default root repository, then repeatedly changes a percentage of their
+// objects and measures how long the whole fleet takes to sync each commit.
+// The results and the pipeline metrics are written to a report in the test's
+// temporary directory.
+func TestLoadRepoSyncFleet(t *testing.T) {
+	nt := nomostest.New(t, nomostesting.Reconciliation1, ntopts.Unstructured, ntopts.LoadTest,
+		ntopts.WithDelegatedControl,
+		ntopts.RepoSyncPermissions(policy.CoreAdmin()),
+		ntopts.WithReconcileTimeout(configsync.DefaultReconcileTimeout))
+	rootRepo := nt.RootRepos[configsync.RootSyncName]
+	sha1Func := commitForRepo(rootRepo)
+
+	config := loadtest.Config{
+		Name:               t.Name(),
+		RepoSyncs:          *e2e.LoadRepoSyncs,
+		ObjectsPerRepoSync: *e2e.LoadObjects,
+		ChurnPercent:       *e2e.LoadChurnPercent,
+		Seed:               time.Now().UnixNano(),
+		RootDir:            "acme/load-test",
+		ClusterRole:        nt.RepoSyncClusterRole().Name,
+	}
+	config.Default()
+	if err := config.Validate(); err != nil {
+		nt.T.Fatal(err)
+	}
+	nt.T.Logf("Load test config: %+v", config)
+
+	template := nomostest.RepoSyncObjectV1Beta1(types.NamespacedName{Name: configsync.RepoSyncName},
+		nt.GitProvider.SyncURL(rootRepo.RemoteRepoName), filesystem.SourceFormatUnstructured)
+
+	// The Namespaces and RoleBindings are synced first, so the git Secrets can
+	// be created before the RepoSyncs start to fetch.
+	var repoSyncs []client.Object
+	rootObjects := config.RootObjects(template)
+	for path, obj := range rootObjects {
+		switch o := obj.(type) {
+		case *corev1.Namespace, *rbacv1.RoleBinding:
+			nt.Must(rootRepo.Add(path, o))
+		case *v1beta1.RepoSync:
+			repoSyncs = append(repoSyncs, o)
+		}
+	}
+	nt.Must(rootRepo.CommitAndPush("Adding load test namespaces"))
+	if err := nt.WatchForAllSyncs(nomostest.RootSyncOnly()); err != nil {
+		nt.T.Fatal(err)
+	}
+	for _, ns := range config.Namespaces() {
+		nomostest.CreateNamespaceSecret(nt, ns)
+	}
+
+	report := loadtest.NewReport(config)
+	syncFleet := func(iteration int, msg string) {
+		nt.T.Helper()
+		nt.Must(rootRepo.CommitAndPush(msg))
+		start := time.Now()
+		rootSync := nomostest.RootSyncObjectV1Beta1FromRootRepo(nt, configsync.RootSyncName)
+		waitForSync(nt, sha1Func, append([]client.Object{rootSync}, repoSyncs...)...)
+		commit, err := rootRepo.Hash()
+		if err != nil {
+			nt.T.Fatal(err)
+		}
+		it := loadtest.Iteration{
+			Number:         iteration,
+			Commit:         commit,
+			ChangedObjects: config.ChurnedObjects(iteration),
+		}
+		it.SyncDuration.Duration = time.Since(start)
+		nt.T.Logf("Iteration %d synced %d changed objects in %v", iteration, it.ChangedObjects, it.SyncDuration.Duration)
+		report.AddIteration(it)
+	}
+
+	for path, obj := range rootObjects {
+		if _, ok := obj.(*v1beta1.RepoSync); ok {
+			nt.Must(rootRepo.Add(path, obj))
+		}
+	}
+	for _, ns := range config.Namespaces() {
+		for path, obj := range config.Objects(ns, 0) {
+			nt.Must(rootRepo.Add(path, obj))
+		}
+	}
+	loadStart := time.Now()
+	syncFleet(0, "Adding load test RepoSyncs")
+
+	for it := 1; it <= *e2e.LoadIterations; it++ {
+		for _, ns := range config.Namespaces() {
+			for path, obj := range config.Objects(ns, it) {
+				nt.Must(rootRepo.Add(path, obj))
+			}
+		}
+		syncFleet(it, fmt.Sprintf("Load test iteration %d", it))
+	}
+
+	err := nomostest.ValidateMetrics(nt, func(ctx context.Context, api prometheusv1.API) error {
+		return report.CollectMetrics(ctx, api, loadtest.DefaultQueries(time.Since(loadStart)))
+	})
+	if err != nil {
+		nt.T.Error(err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		nt.T.Fatal(err)
+	}
+	nt.T.Log(buf.String())
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		nt.T.Fatal(err)
+	}
+	path := filepath.Join(nt.TmpDir, "load-report.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		nt.T.Fatal(err)
+	}
+	nt.T.Logf("Load test report written to %s", path)
+}
@@ -40,6 +40,20 @@ const (
 	RepoSyncKind = "RepoSync"
 	// RootSyncKind is the kind of the RepoSync resource.
 	RootSyncKind = "RootSync"
+	// RepoSyncQuotaKind is the kind of the RepoSyncQuota resource.
+	RepoSyncQuotaKind = "RepoSyncQuota"
+	// ConfigSyncStatusKind is the kind of the ConfigSyncStatus resource.
+	ConfigSyncStatusKind = "ConfigSyncStatus"
+	// ConfigSyncUpgradePolicyKind is the kind of the ConfigSyncUpgradePolicy resource.
+	ConfigSyncUpgradePolicyKind = "ConfigSyncUpgradePolicy"
+	// ApprovalRequestKind is the kind of the ApprovalRequest resource.
+	ApprovalRequestKind = "ApprovalRequest"
+	// ReconcilerDebugKind is the kind of the ReconcilerDebug resource.
+	ReconcilerDebugKind = "ReconcilerDebug"
+	// NamespaceRequestKind is the kind of the NamespaceRequest resource.
+	NamespaceRequestKind = "NamespaceRequest"
+	// DeclaredObjectMutatorKind is the kind of the DeclaredObjectMutator resource.
+	DeclaredObjectMutatorKind = "DeclaredObjectMutator"
 )
 
 const (
@@ -102,4 +116,7 @@ const (
 	// AuthGCPServiceAccount indicates using a GCP service account to authenticate to
 	// Git or OCI or Helm, when GKE Workload Identity or Fleet Workload Identity is enabled.
 	AuthGCPServiceAccount AuthType = "gcpserviceaccount"
+	// AuthExec indicates using the token vended by an exec credential plugin to
+	// authenticate to Git or OCI.
+	AuthExec AuthType = "exec"
 )
@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// Converter configures a converter plugin, which converts the files of the
+// source of truth that are not Kubernetes manifests, e.g. files written in an
+// in-house DSL, into Kubernetes objects when the source is read. The plugin is
+// executed once per file, in the directory of the file, with the path of the
+// file appended to its arguments, and must print the converted objects as
+// YAML to stdout. A plugin that exits with a non-zero status fails the read,
+// and its stderr is reported in the source status.
+type Converter struct {
+	// image is the container image which provides the plugin. The image runs
+	// as an init container of the reconciler Pod, and must copy the plugin
+	// executable into the directory given by the CONVERTER_PLUGIN_DIR
+	// environment variable. The image of a RepoSync plugin must be one of the
+	// images allowed by the cluster admin. Required.
+	Image string `json:"image"`
+
+	// command is the plugin executable, as a path relative to the plugin
+	// directory. Absolute paths and paths containing `..` are rejected.
+	// Required.
+	Command string `json:"command"`
+
+	// args are the arguments passed to the plugin, before the path of the
+	// file to convert.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// env are the environment variables set when executing the plugin.
+	// +optional
+	Env []ExecEnvVar `json:"env,omitempty"`
+
+	// extensions are the extensions of the files converted by the plugin,
+	// e.g. ".dsl". Required.
+	Extensions []string `json:"extensions"`
+}
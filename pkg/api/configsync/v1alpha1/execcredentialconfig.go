@@ -0,0 +1,57 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// ExecCredential configures an exec credential plugin, which vends the token
+// used to authenticate to the source. Like the kubectl exec credential
+// plugins, the plugin prints an ExecCredential (client.authentication.k8s.io)
+// to stdout. The token is cached until the expirationTimestamp of the
+// ExecCredential, and the plugin is executed again when it expires.
+type ExecCredential struct {
+	// image is the container image which provides the plugin. The image runs
+	// as an init container of the reconciler Pod, and must copy the plugin
+	// executable into the directory given by the CREDENTIAL_PLUGIN_DIR
+	// environment variable. The image of a RepoSync plugin must be one of the
+	// images allowed by the cluster admin. Required.
+	Image string `json:"image"`
+
+	// command is the plugin executable, as a path relative to the plugin
+	// directory. Absolute paths and paths containing `..` are rejected.
+	// Required.
+	Command string `json:"command"`
+
+	// args are the arguments passed to the plugin.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// env are the environment variables set when executing the plugin.
+	// +optional
+	Env []ExecEnvVar `json:"env,omitempty"`
+
+	// username is the username presented with the token.
+	// Default: "oauth2accesstoken".
+	// +optional
+	Username string `json:"username,omitempty"`
+}
+
+// ExecEnvVar is an environment variable set when executing an exec credential
+// plugin.
+type ExecEnvVar struct {
+	// name of the environment variable.
+	Name string `json:"name"`
+
+	// value of the environment variable.
+	Value string `json:"value"`
+}
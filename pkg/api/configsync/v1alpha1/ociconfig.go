@@ -45,14 +45,28 @@ type Oci struct {
 	Period metav1.Duration `json:"period,omitempty"`
 
 	// auth is the type of secret configured for access to the OCI package.
-	// Must be one of gcenode, gcpserviceaccount, or none.
+	// Must be one of gcenode, gcpserviceaccount, exec, or none.
 	// The validation of this is case-sensitive. Required.
 	//
-	// +kubebuilder:validation:Enum=gcenode;gcpserviceaccount;none
+	// +kubebuilder:validation:Enum=gcenode;gcpserviceaccount;exec;none
 	Auth configsync.AuthType `json:"auth"`
 
 	// gcpServiceAccountEmail specifies the GCP service account used to annotate
 	// the RootSync/RepoSync controller Kubernetes Service Account.
 	// Note: The field is used when secretType: gcpServiceAccount.
 	GCPServiceAccountEmail string `json:"gcpServiceAccountEmail,omitempty"`
+
+	// execCredential configures the exec credential plugin which vends the
+	// token used to access the OCI package.
+	// Note: The field is used when secretType: exec.
+	// +nullable
+	// +optional
+	ExecCredential *ExecCredential `json:"execCredential,omitempty"`
+
+	// webhook enables a registry webhook receiver for the package. When true,
+	// a notification sent to the receiver triggers an immediate fetch of the
+	// image, and the reconciler reimports the package as soon as it is
+	// fetched, instead of waiting for the next polling period.
+	// +optional
+	Webhook bool `json:"webhook,omitempty"`
 }
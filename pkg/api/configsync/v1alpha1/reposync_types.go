@@ -76,6 +76,58 @@ type RepoSyncSpec struct {
 	// +nullable
 	// +optional
 	Override *OverrideSpec `json:"override,omitempty"`
+
+	// prune restricts the kinds of the objects that the reconciler deletes,
+	// when they are removed from the source of truth. The objects of the other
+	// kinds are only ever created and updated: they are unmanaged instead of
+	// deleted.
+	// +optional
+	Prune *PruneSpec `json:"prune,omitempty"`
+
+	// rollout paces the updates of the Deployments, StatefulSets and
+	// DaemonSets managed by the reconciler, which restart their pods, so that
+	// a change to many workloads at once, e.g. a base image bump, does not
+	// restart all of them simultaneously.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// syncWindows restrict when the reconciler applies the changes of the
+	// source of truth. Outside the allowed windows, the source is still
+	// fetched and rendered, and the remediator still reverts drift, but new
+	// commits are only applied once a window allows it.
+	// If unset, changes are applied at any time.
+	// +optional
+	SyncWindows []SyncWindow `json:"syncWindows,omitempty"`
+
+	// dryRun previews the commits of the source of truth instead of syncing
+	// them. The reconciler runs server-side dry-run applies of the declared
+	// resources, and reports the objects that syncing the commit would add,
+	// change or prune in .status.dryRun, without mutating the cluster.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// renames rename the declared objects after rendering, so that the same
+	// package can be synced multiple times in a cluster without forking it
+	// only to rename its objects. The rules are applied in order. References
+	// to the renamed objects in the fields of other objects are not updated.
+	// +optional
+	Renames []RenameRule `json:"renames,omitempty"`
+
+	// converter configures a plugin which converts the files of the source
+	// of truth that are not Kubernetes manifests into Kubernetes objects.
+	// +optional
+	Converter *Converter `json:"converter,omitempty"`
+
+	// mode specifies whether the reconciler keeps syncing the source of
+	// truth, or stops once it synced it, e.g. for bootstrap-only
+	// repositories. One-shot reconcilers run as Jobs if the
+	// reconciler-manager enables it, and keep syncing otherwise.
+	//
+	// Must be one of continuous, one-shot. Optional. Set to continuous if
+	// not specified.
+	// +kubebuilder:validation:Pattern=^(continuous|one-shot|)$
+	// +optional
+	Mode string `json:"mode,omitempty"`
 }
 
 // RepoSyncStatus defines the observed state of a RepoSync.
@@ -127,6 +179,11 @@ type RepoSyncCondition struct {
 	// A human readable message indicating details about the transition.
 	// +optional
 	Message string `json:"message,omitempty"`
+	// observedGeneration is the generation of the sync resource this
+	// condition was set for. A condition with an observedGeneration lower than
+	// the generation of the sync resource is out of date with its spec.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// hash of the source of truth. It can be a git commit hash, or an OCI image digest.
 	// +optional
 	Commit string `json:"commit,omitempty"`
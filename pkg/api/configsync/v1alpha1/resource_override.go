@@ -66,6 +66,166 @@ type OverrideSpec struct {
 	// support pulling remote bases from public repositories.
 	// +optional
 	EnableShellInRendering *bool `json:"enableShellInRendering,omitempty"`
+
+	// upgradeSettlePeriod allows one to hold off applying new commits after
+	// the reconciler detects an upgrade of the cluster control plane, such as
+	// a change of the API server version or of the served API resources.
+	// Drift correction continues during the settle period.
+	// Default: 0, which disables the settle period.
+	// Use string to specify this field value, like "5m", "10m".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	UpgradeSettlePeriod *metav1.Duration `json:"upgradeSettlePeriod,omitempty"`
+
+	// pruneDelay holds off deleting the objects removed from the source of
+	// truth. The removed objects are annotated with
+	// `configsync.gke.io/pending-prune-since` and kept for the delay, giving
+	// one a window to notice accidental removals and restore them.
+	// Default: 0, which prunes the removed objects immediately.
+	// Use string to specify this field value, like "30m", "1h".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	PruneDelay *metav1.Duration `json:"pruneDelay,omitempty"`
+
+	// approvalTimeout requires the approval of every new commit before the
+	// reconciler applies it. The reconciler creates or updates an
+	// ApprovalRequest with the commit, and waits for an external approval
+	// system to approve or deny it, for up to the timeout. A commit which is
+	// not approved in time is reported as denied.
+	// Default: 0, which applies new commits without approval.
+	// Use string to specify this field value, like "30m", "24h".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	ApprovalTimeout *metav1.Duration `json:"approvalTimeout,omitempty"`
+
+	// pollingPeriod is how often the reconciler checks the source of truth
+	// for new commits, when it does not watch it.
+	// Default: the polling period of the reconciler-manager, 15s unless set.
+	// Use string to specify this field value, like "15s", "1m".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	PollingPeriod *metav1.Duration `json:"pollingPeriod,omitempty"`
+
+	// statusUpdatePeriod is how often the reconciler updates the sync status
+	// of the RootSync or RepoSync while it is syncing, and reports the
+	// management conflicts while it is not. A longer period reduces the
+	// writes to the API server of noisy repositories, and a shorter one
+	// reports the status of quiet repositories sooner.
+	// Default: 5s.
+	// Use string to specify this field value, like "5s", "1m".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	StatusUpdatePeriod *metav1.Duration `json:"statusUpdatePeriod,omitempty"`
+
+	// apiPriorityGroup tags all the API requests of the reconciler with the
+	// specified group, by impersonating the reconciler service account with
+	// the group added. Cluster admins can then match the group in a
+	// FlowSchema to assign the reconciler traffic to a lower priority level
+	// than the interactive traffic.
+	// The reconciler service account must be allowed to impersonate itself
+	// and the group.
+	// Default: "", which sends the requests as the service account.
+	// +optional
+	APIPriorityGroup string `json:"apiPriorityGroup,omitempty"`
+
+	// supersedeInFlightApply allows one to stop applying a commit as soon as
+	// a newer commit is fetched, rendered and validated, and to apply the
+	// newer commit instead, rather than waiting for the older commit to finish
+	// applying. A newer commit that fails to validate does not stop the apply.
+	// Default: false.
+	// +optional
+	SupersedeInFlightApply *bool `json:"supersedeInFlightApply,omitempty"`
+
+	// partialApply allows one to only apply the objects declared in the
+	// source files changed by a new commit, along with the objects depending
+	// on them, instead of all the declared objects. All the objects are still
+	// applied, and the removed objects pruned, on every resync, after a failed
+	// apply, and when objects are removed from the source of truth.
+	// Default: false.
+	// +optional
+	PartialApply *bool `json:"partialApply,omitempty"`
+
+	// commonLabels are added to every object applied by the reconciler, for
+	// organization-wide requirements such as owner or cost-center labels.
+	// A label declared on an object takes precedence over a common label
+	// with the same key. Config Sync labels are ignored.
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// commonAnnotations are added to every object applied by the reconciler.
+	// An annotation declared on an object takes precedence over a common
+	// annotation with the same key. Config Sync annotations are ignored.
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// metrics controls the metrics emitted by the reconciler.
+	// Must be "disabled", "basic" or "full".
+	// If set to "disabled", no metrics are emitted. If set to "basic", only
+	// the metrics of the sync health, such as the errors and the last sync
+	// timestamp, are emitted, which is useful to reduce the load on the
+	// metrics pipeline from short-lived or test RSyncs.
+	// Default: "full".
+	//
+	// +kubebuilder:validation:Pattern=^(disabled|basic|full|)$
+	// +optional
+	Metrics string `json:"metrics,omitempty"`
+
+	// selfUpdateTimeout enables the staged updates of the Config Sync
+	// components declared in the source of truth of a RootSync: the objects
+	// labeled with `configmanagement.gke.io/system: "true"` and the Config Sync
+	// CRDs. Their changes are applied after every other change, and reverted if
+	// their Deployments do not become available within the timeout.
+	// Default: 0, which applies the components along with the other objects.
+	// Ignored for RepoSyncs.
+	// Use string to specify this field value, like "5m", "10m".
+	// More details about valid inputs: https://pkg.go.dev/time#ParseDuration.
+	// +optional
+	SelfUpdateTimeout *metav1.Duration `json:"selfUpdateTimeout,omitempty"`
+
+	// pruneObsoleteMetadata allows one to remove the annotations and labels set
+	// by previous versions of Config Sync, which are no longer used, from the
+	// managed objects when they are applied.
+	// Default: false.
+	// +optional
+	PruneObsoleteMetadata *bool `json:"pruneObsoleteMetadata,omitempty"`
+
+	// migrateClientSideApply allows one to migrate the objects previously
+	// managed with kubectl client-side apply to Config Sync, by transferring the
+	// fields owned by client-side apply to Config Sync and removing the
+	// `kubectl.kubernetes.io/last-applied-configuration` annotation when the
+	// objects are applied.
+	// Default: false.
+	// +optional
+	MigrateClientSideApply *bool `json:"migrateClientSideApply,omitempty"`
+
+	// normalizeDeclarations allows one to normalize the declarations of objects
+	// with their OpenAPI schemas, dropping the fields set to their defaults and
+	// formatting quantities and durations canonically, before comparing them
+	// with their previous declarations. Pure formatting or defaulting changes are
+	// then not reported as changes, e.g. to frozen namespaces.
+	// Default: false.
+	// +optional
+	NormalizeDeclarations *bool `json:"normalizeDeclarations,omitempty"`
+
+	// retryBudget allows one to limit how many times a failing commit is
+	// retried. After the first attempt and this many consecutive failed retries,
+	// the reconciler stops retrying the commit and sets the RetriesExhausted
+	// condition, until the source changes, a sync is requested, or the next
+	// force-resync.
+	// Default: 0, which retries forever.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetryBudget *int64 `json:"retryBudget,omitempty"`
+
+	// parseConcurrency allows one to parse up to this many files of the source
+	// of truth at once, which speeds up parsing the sources with many files, at
+	// the cost of more CPU for the reconciler container.
+	// Default: 1, which parses the files one at a time.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ParseConcurrency *int64 `json:"parseConcurrency,omitempty"`
 }
 
 // ContainerResourcesSpec allows to override the resource requirements for a container
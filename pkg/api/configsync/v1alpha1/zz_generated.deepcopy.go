@@ -11,6 +11,43 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AbandonedResource) DeepCopyInto(out *AbandonedResource) {
+	*out = *in
+	out.Resource = in.Resource
+	in.AbandonedAt.DeepCopyInto(&out.AbandonedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AbandonedResource.
+func (in *AbandonedResource) DeepCopy() *AbandonedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AbandonedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPrerequisite) DeepCopyInto(out *ClusterPrerequisite) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPrerequisite.
+func (in *ClusterPrerequisite) DeepCopy() *ClusterPrerequisite {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPrerequisite)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigSyncError) DeepCopyInto(out *ConfigSyncError) {
 	*out = *in
@@ -50,6 +87,64 @@ func (in *ContainerResourcesSpec) DeepCopy() *ContainerResourcesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Converter) DeepCopyInto(out *Converter) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ExecEnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Converter.
+func (in *Converter) DeepCopy() *Converter {
+	if in == nil {
+		return nil
+	}
+	out := new(Converter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
+	*out = *in
+	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ObjectDiff, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]ConfigSyncError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ErrorSummary) DeepCopyInto(out *ErrorSummary) {
 	*out = *in
@@ -65,10 +160,55 @@ func (in *ErrorSummary) DeepCopy() *ErrorSummary {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecCredential) DeepCopyInto(out *ExecCredential) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ExecEnvVar, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecCredential.
+func (in *ExecCredential) DeepCopy() *ExecCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecEnvVar) DeepCopyInto(out *ExecEnvVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecEnvVar.
+func (in *ExecEnvVar) DeepCopy() *ExecEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Git) DeepCopyInto(out *Git) {
 	*out = *in
 	out.Period = in.Period
+	if in.ExecCredential != nil {
+		in, out := &in.ExecCredential, &out.ExecCredential
+		*out = new(ExecCredential)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
 		*out = new(SecretReference)
@@ -79,6 +219,11 @@ func (in *Git) DeepCopyInto(out *Git) {
 		*out = new(SecretReference)
 		**out = **in
 	}
+	if in.KnownHostsSecretRef != nil {
+		in, out := &in.KnownHostsSecretRef, &out.KnownHostsSecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Git.
@@ -179,10 +324,31 @@ func (in *HelmStatus) DeepCopy() *HelmStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectDiff) DeepCopyInto(out *ObjectDiff) {
+	*out = *in
+	out.Resource = in.Resource
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectDiff.
+func (in *ObjectDiff) DeepCopy() *ObjectDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Oci) DeepCopyInto(out *Oci) {
 	*out = *in
 	out.Period = in.Period
+	if in.ExecCredential != nil {
+		in, out := &in.ExecCredential, &out.ExecCredential
+		*out = new(ExecCredential)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Oci.
@@ -240,6 +406,85 @@ func (in *OverrideSpec) DeepCopyInto(out *OverrideSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.UpgradeSettlePeriod != nil {
+		in, out := &in.UpgradeSettlePeriod, &out.UpgradeSettlePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PruneDelay != nil {
+		in, out := &in.PruneDelay, &out.PruneDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ApprovalTimeout != nil {
+		in, out := &in.ApprovalTimeout, &out.ApprovalTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PollingPeriod != nil {
+		in, out := &in.PollingPeriod, &out.PollingPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StatusUpdatePeriod != nil {
+		in, out := &in.StatusUpdatePeriod, &out.StatusUpdatePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SupersedeInFlightApply != nil {
+		in, out := &in.SupersedeInFlightApply, &out.SupersedeInFlightApply
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PartialApply != nil {
+		in, out := &in.PartialApply, &out.PartialApply
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SelfUpdateTimeout != nil {
+		in, out := &in.SelfUpdateTimeout, &out.SelfUpdateTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PruneObsoleteMetadata != nil {
+		in, out := &in.PruneObsoleteMetadata, &out.PruneObsoleteMetadata
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MigrateClientSideApply != nil {
+		in, out := &in.MigrateClientSideApply, &out.MigrateClientSideApply
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NormalizeDeclarations != nil {
+		in, out := &in.NormalizeDeclarations, &out.NormalizeDeclarations
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RetryBudget != nil {
+		in, out := &in.RetryBudget, &out.RetryBudget
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ParseConcurrency != nil {
+		in, out := &in.ParseConcurrency, &out.ParseConcurrency
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideSpec.
@@ -252,6 +497,61 @@ func (in *OverrideSpec) DeepCopy() *OverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneSpec) DeepCopyInto(out *PruneSpec) {
+	*out = *in
+	if in.AllowedKinds != nil {
+		in, out := &in.AllowedKinds, &out.AllowedKinds
+		*out = make([]metav1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedKinds != nil {
+		in, out := &in.DeniedKinds, &out.DeniedKinds
+		*out = make([]metav1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxPercentage != nil {
+		in, out := &in.MaxPercentage, &out.MaxPercentage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneSpec.
+func (in *PruneSpec) DeepCopy() *PruneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenameRule) DeepCopyInto(out *RenameRule) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]metav1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenameRule.
+func (in *RenameRule) DeepCopy() *RenameRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RenameRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RenderingStatus) DeepCopyInto(out *RenderingStatus) {
 	*out = *in
@@ -411,6 +711,33 @@ func (in *RepoSyncSpec) DeepCopyInto(out *RepoSyncSpec) {
 		*out = new(OverrideSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(PruneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncWindows != nil {
+		in, out := &in.SyncWindows, &out.SyncWindows
+		*out = make([]SyncWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Renames != nil {
+		in, out := &in.Renames, &out.Renames
+		*out = make([]RenameRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Converter != nil {
+		in, out := &in.Converter, &out.Converter
+		*out = new(Converter)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoSyncSpec.
@@ -446,6 +773,22 @@ func (in *RepoSyncStatus) DeepCopy() *RepoSyncStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceConsumption) DeepCopyInto(out *ResourceConsumption) {
+	*out = *in
+	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceConsumption.
+func (in *ResourceConsumption) DeepCopy() *ResourceConsumption {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceConsumption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRef) DeepCopyInto(out *ResourceRef) {
 	*out = *in
@@ -462,6 +805,47 @@ func (in *ResourceRef) DeepCopy() *ResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryStatus) DeepCopyInto(out *RetryStatus) {
+	*out = *in
+	in.NextRetryTime.DeepCopyInto(&out.NextRetryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryStatus.
+func (in *RetryStatus) DeepCopy() *RetryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+	if in.MaxConcurrentUpdates != nil {
+		in, out := &in.MaxConcurrentUpdates, &out.MaxConcurrentUpdates
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxConcurrentUpdatesPerNamespace != nil {
+		in, out := &in.MaxConcurrentUpdatesPerNamespace, &out.MaxConcurrentUpdatesPerNamespace
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RootSync) DeepCopyInto(out *RootSync) {
 	*out = *in
@@ -558,6 +942,11 @@ func (in *RootSyncList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RootSyncSpec) DeepCopyInto(out *RootSyncSpec) {
 	*out = *in
+	if in.HierarchicalDirs != nil {
+		in, out := &in.HierarchicalDirs, &out.HierarchicalDirs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Git != nil {
 		in, out := &in.Git, &out.Git
 		*out = new(Git)
@@ -578,6 +967,45 @@ func (in *RootSyncSpec) DeepCopyInto(out *RootSyncSpec) {
 		*out = new(OverrideSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(Target)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]SyncTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(PruneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncWindows != nil {
+		in, out := &in.SyncWindows, &out.SyncWindows
+		*out = make([]SyncWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Renames != nil {
+		in, out := &in.Renames, &out.Renames
+		*out = make([]RenameRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Converter != nil {
+		in, out := &in.Converter, &out.Converter
+		*out = new(Converter)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RootSyncSpec.
@@ -601,6 +1029,13 @@ func (in *RootSyncStatus) DeepCopyInto(out *RootSyncStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RootSyncStatus.
@@ -628,6 +1063,26 @@ func (in *SecretReference) DeepCopy() *SecretReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedObjects) DeepCopyInto(out *SkippedObjects) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ResourceRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedObjects.
+func (in *SkippedObjects) DeepCopy() *SkippedObjects {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedObjects)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SourceStatus) DeepCopyInto(out *SourceStatus) {
 	*out = *in
@@ -677,6 +1132,16 @@ func (in *Status) DeepCopyInto(out *Status) {
 	in.Source.DeepCopyInto(&out.Source)
 	in.Rendering.DeepCopyInto(&out.Rendering)
 	in.Sync.DeepCopyInto(&out.Sync)
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
@@ -720,6 +1185,56 @@ func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
 		*out = new(ErrorSummary)
 		**out = **in
 	}
+	if in.ImmutableFieldChanges != nil {
+		in, out := &in.ImmutableFieldChanges, &out.ImmutableFieldChanges
+		*out = make([]ResourceRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.AbandonedResources != nil {
+		in, out := &in.AbandonedResources, &out.AbandonedResources
+		*out = make([]AbandonedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]ResourceRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceConsumption != nil {
+		in, out := &in.ResourceConsumption, &out.ResourceConsumption
+		*out = new(ResourceConsumption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterPrerequisites != nil {
+		in, out := &in.ClusterPrerequisites, &out.ClusterPrerequisites
+		*out = make([]ClusterPrerequisite, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SelectorSkipped != nil {
+		in, out := &in.SelectorSkipped, &out.SelectorSkipped
+		*out = new(SkippedObjects)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]SyncWarning, len(*in))
+		copy(*out, *in)
+	}
+	if in.WatchHealth != nil {
+		in, out := &in.WatchHealth, &out.WatchHealth
+		*out = make([]WatchFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastFullResync != nil {
+		in, out := &in.LastFullResync, &out.LastFullResync
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStatus.
@@ -731,3 +1246,118 @@ func (in *SyncStatus) DeepCopy() *SyncStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncTarget) DeepCopyInto(out *SyncTarget) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncTarget.
+func (in *SyncTarget) DeepCopy() *SyncTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncWarning) DeepCopyInto(out *SyncWarning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncWarning.
+func (in *SyncWarning) DeepCopy() *SyncWarning {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncWarning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncWindow) DeepCopyInto(out *SyncWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncWindow.
+func (in *SyncWindow) DeepCopy() *SyncWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Target) DeepCopyInto(out *Target) {
+	*out = *in
+	if in.KubeconfigSecretRef != nil {
+		in, out := &in.KubeconfigSecretRef, &out.KubeconfigSecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
+func (in *Target) DeepCopy() *Target {
+	if in == nil {
+		return nil
+	}
+	out := new(Target)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetStatus) DeepCopyInto(out *TargetStatus) {
+	*out = *in
+	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]ConfigSyncError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetStatus.
+func (in *TargetStatus) DeepCopy() *TargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatchFailure) DeepCopyInto(out *WatchFailure) {
+	*out = *in
+	out.GVK = in.GVK
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatchFailure.
+func (in *WatchFailure) DeepCopy() *WatchFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(WatchFailure)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApprovalDecision is the decision of an external approval system on an
+// ApprovalRequest.
+type ApprovalDecision string
+
+const (
+	// ApprovalApproved allows the reconciler to apply the commit.
+	ApprovalApproved ApprovalDecision = "Approved"
+	// ApprovalDenied prevents the reconciler from applying the commit.
+	ApprovalDenied ApprovalDecision = "Denied"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ApprovalRequest requests the approval of a new commit before a reconciler
+// applies it, for the integration with change-management systems.
+//
+// The reconciler creates or updates the ApprovalRequest when it fetches a new
+// commit, if the approvalTimeout of its RootSync or RepoSync is set, and
+// waits for an external system to set the decision in the status. The
+// ApprovalRequest has the same name as the reconciler, in the namespace of
+// the RootSync or RepoSync, and is deleted with it.
+type ApprovalRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec ApprovalRequestSpec `json:"spec,omitempty"`
+	// +optional
+	Status ApprovalRequestStatus `json:"status,omitempty"`
+}
+
+// ApprovalRequestSpec is the commit waiting for approval. It is set by the
+// reconciler.
+type ApprovalRequestSpec struct {
+	// commit is the commit waiting for approval.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// lastSyncedCommit is the commit currently synced, which the commit
+	// waiting for approval replaces.
+	// +optional
+	LastSyncedCommit string `json:"lastSyncedCommit,omitempty"`
+
+	// requestedAt is when the reconciler requested the approval.
+	// +optional
+	RequestedAt metav1.Time `json:"requestedAt,omitempty"`
+
+	// expiresAt is when the reconciler stops waiting for a decision, and
+	// reports the commit as denied.
+	// +optional
+	ExpiresAt metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// ApprovalRequestStatus is the decision on the commit. It is set by the
+// external approval system.
+type ApprovalRequestStatus struct {
+	// commit is the commit which the decision applies to. A decision on
+	// another commit is ignored.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// decision is either Approved or Denied.
+	// +kubebuilder:validation:Enum=Approved;Denied
+	// +optional
+	Decision ApprovalDecision `json:"decision,omitempty"`
+
+	// decidedBy identifies who made the decision, e.g. a change ticket.
+	// +optional
+	DecidedBy string `json:"decidedBy,omitempty"`
+
+	// message explains the decision.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApprovalRequestList contains a list of ApprovalRequest
+type ApprovalRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApprovalRequest `json:"items"`
+}
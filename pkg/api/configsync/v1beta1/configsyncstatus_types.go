@@ -0,0 +1,118 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentState is the state of a component of the Config Sync installation.
+type ComponentState string
+
+const (
+	// ComponentAvailable means that all the replicas of the component are
+	// available.
+	ComponentAvailable = ComponentState("Available")
+	// ComponentUnavailable means that some replicas of the component are not
+	// available, or run an outdated generation.
+	ComponentUnavailable = ComponentState("Unavailable")
+	// ComponentNotInstalled means that the component is not installed.
+	ComponentNotInstalled = ComponentState("NotInstalled")
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Syncs",type="integer",JSONPath=".status.syncs.total"
+// +kubebuilder:printcolumn:name="Healthy",type="integer",JSONPath=".status.syncs.healthy"
+
+// ConfigSyncStatus reports the readiness of the whole Config Sync
+// installation, so that health checks and upgrade gates watch a single
+// object, instead of the Deployments of every component.
+//
+// The reconciler-manager maintains the ConfigSyncStatus named "config-sync".
+type ConfigSyncStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status ConfigSyncStatusStatus `json:"status,omitempty"`
+}
+
+// ConfigSyncStatusStatus aggregates the health of the components and of the
+// RootSyncs and RepoSyncs.
+type ConfigSyncStatusStatus struct {
+	// ready is true if all the installed components are available, and all the
+	// RootSyncs and RepoSyncs are healthy.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// components reports the health of each component of the installation.
+	// +optional
+	Components []ComponentStatus `json:"components,omitempty"`
+
+	// syncs summarizes the health of the RootSyncs and RepoSyncs.
+	// +optional
+	Syncs SyncsSummary `json:"syncs,omitempty"`
+
+	// lastUpdateTime is when the status last changed.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ComponentStatus reports the health of a component of the installation.
+type ComponentStatus struct {
+	// name is the name of the Deployment of the component.
+	Name string `json:"name"`
+
+	// namespace is the namespace of the Deployment of the component.
+	Namespace string `json:"namespace"`
+
+	// state is one of Available, Unavailable and NotInstalled.
+	State ComponentState `json:"state"`
+
+	// message describes why the component is not available.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SyncsSummary summarizes the health of the RootSyncs and RepoSyncs.
+type SyncsSummary struct {
+	// total is the number of RootSyncs and RepoSyncs on the cluster.
+	// +optional
+	Total int `json:"total,omitempty"`
+
+	// healthy is the number of RootSyncs and RepoSyncs that are not stalled and
+	// sync without errors.
+	// +optional
+	Healthy int `json:"healthy,omitempty"`
+
+	// unhealthy is the list of the RootSyncs and RepoSyncs that are not
+	// healthy, in the kind/namespace/name format.
+	// +optional
+	Unhealthy []string `json:"unhealthy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigSyncStatusList contains a list of ConfigSyncStatus
+type ConfigSyncStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConfigSyncStatus `json:"items"`
+}
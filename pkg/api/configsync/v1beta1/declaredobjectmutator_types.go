@@ -0,0 +1,105 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SetLabelsFunction is the built-in mutation function which sets the
+	// key-value pairs of its configMap as labels on the declared objects.
+	SetLabelsFunction = "set-labels"
+	// SetAnnotationsFunction is the built-in mutation function which sets the
+	// key-value pairs of its configMap as annotations on the declared objects.
+	SetAnnotationsFunction = "set-annotations"
+	// SetImagePullSecretsFunction is the built-in mutation function which adds
+	// the comma-separated Secret names of the `names` key of its configMap to
+	// the imagePullSecrets of the declared Pods and Pod templates.
+	SetImagePullSecretsFunction = "set-image-pull-secrets"
+	// IgnoreMutationFunction is the built-in mutation function which marks the
+	// declared objects to be applied once and never updated or remediated
+	// afterwards, as with the `client.lifecycle.config.k8s.io/mutation: ignore`
+	// annotation. If the `types` key of its configMap is set, only the objects
+	// with one of its comma-separated values in their `type` field are marked,
+	// e.g. `kubernetes.io/tls` Secrets.
+	IgnoreMutationFunction = "ignore-mutation"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// DeclaredObjectMutator configures a chain of mutation functions, which the
+// reconcilers evaluate on all the declared objects after parsing the source of
+// truth, and before validating and applying them.
+//
+// The DeclaredObjectMutators are evaluated in the order of their names.
+// A RootSync or RepoSync may opt out with the
+// `configsync.gke.io/declared-object-mutation: disabled` annotation.
+type DeclaredObjectMutator struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec DeclaredObjectMutatorSpec `json:"spec,omitempty"`
+}
+
+// DeclaredObjectMutatorSpec defines the mutation functions of a
+// DeclaredObjectMutator.
+type DeclaredObjectMutatorSpec struct {
+	// functions is the chain of mutation functions, evaluated in order.
+	// +optional
+	Functions []MutationFunction `json:"functions,omitempty"`
+}
+
+// MutationFunction is a single mutation function. Exactly one of builtin and
+// image must be set.
+type MutationFunction struct {
+	// builtin is the name of the built-in function to evaluate.
+	// Must be one of: set-labels, set-annotations, set-image-pull-secrets,
+	// ignore-mutation.
+	// +kubebuilder:validation:Enum=set-labels;set-annotations;set-image-pull-secrets;ignore-mutation
+	// +optional
+	Builtin string `json:"builtin,omitempty"`
+
+	// image is the container image of a KRM function to evaluate, e.g.
+	// `gcr.io/kpt-fn/set-labels:v0.2`. The function receives the declared
+	// objects in a ResourceList, with its configMap as a ConfigMap
+	// functionConfig, and may only change them, not add or remove any.
+	// The image is run with `docker run`, without network access, so the
+	// reconciler must be able to reach a Docker daemon.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// configMap is the configuration of the function.
+	// +optional
+	ConfigMap map[string]string `json:"configMap,omitempty"`
+
+	// kinds restricts the function to the declared objects of these kinds.
+	// If empty, the function is evaluated on all the declared objects.
+	// +optional
+	Kinds []metav1.GroupKind `json:"kinds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeclaredObjectMutatorList contains a list of DeclaredObjectMutator
+type DeclaredObjectMutatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeclaredObjectMutator `json:"items"`
+}
@@ -57,6 +57,16 @@ func (rs *RootSyncSpec) SafeOverride() *OverrideSpec {
 	return rs.Override
 }
 
+// TargetKubeconfigSecretName returns the name of the Secret that stores the
+// kubeconfig of the target cluster, or an empty string if the RootSync syncs
+// to the current cluster.
+func (rs *RootSyncSpec) TargetKubeconfigSecretName() string {
+	if rs.Target == nil {
+		return ""
+	}
+	return GetSecretName(rs.Target.KubeconfigSecretRef)
+}
+
 // GetReconcileTimeout returns reconcile timeout in string, defaulting to 5m if empty
 func GetReconcileTimeout(d *metav1.Duration) string {
 	if d == nil || d.Duration == 0 {
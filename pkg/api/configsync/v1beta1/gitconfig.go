@@ -48,10 +48,10 @@ type Git struct {
 	Period metav1.Duration `json:"period,omitempty"`
 
 	// auth is the type of secret configured for access to the Git repo.
-	// Must be one of ssh, cookiefile, gcenode, token, or none.
+	// Must be one of ssh, cookiefile, gcenode, token, exec, or none.
 	// The validation of this is case-sensitive. Required.
 	//
-	// +kubebuilder:validation:Enum=ssh;cookiefile;gcenode;gcpserviceaccount;token;none
+	// +kubebuilder:validation:Enum=ssh;cookiefile;gcenode;gcpserviceaccount;token;exec;none
 	Auth configsync.AuthType `json:"auth"`
 
 	// gcpServiceAccountEmail specifies the GCP service account used to annotate
@@ -59,6 +59,13 @@ type Git struct {
 	// Note: The field is used when secretType: gcpServiceAccount.
 	GCPServiceAccountEmail string `json:"gcpServiceAccountEmail,omitempty"`
 
+	// execCredential configures the exec credential plugin which vends the
+	// token used to access the Git repo.
+	// Note: The field is used when secretType: exec.
+	// +nullable
+	// +optional
+	ExecCredential *ExecCredential `json:"execCredential,omitempty"`
+
 	// proxy specifies an HTTPS proxy for accessing the Git repo.
 	// Only has an effect when secretType is one of ("cookiefile", "none", "token").
 	// When secretType is "cookiefile" or "token", if your HTTPS proxy URL contains sensitive information
@@ -87,8 +94,39 @@ type Git struct {
 	// +nullable
 	// +optional
 	CACertSecretRef *SecretReference `json:"caCertSecretRef,omitempty"`
+
+	// knownHostsSecretRef specifies the name of the secret holding the SSH known
+	// hosts of the Git server in a key named "known_hosts". When set, git-sync
+	// verifies the host key of the Git server instead of skipping the check.
+	// Only has an effect when secretType is "ssh". For RepoSync resources, the
+	// secret must be in the same namespace as the RepoSync. For RootSync
+	// resource, the secret must be in the config-management-system namespace.
+	// +nullable
+	// +optional
+	KnownHostsSecretRef *SecretReference `json:"knownHostsSecretRef,omitempty"`
+
+	// knownHostsPolicy specifies how the known hosts secret is populated.
+	// Must be "Manual" or "PinOnFirstUse". Default: "Manual".
+	// With "Manual", the secret must be created out of band.
+	// With "PinOnFirstUse", if the secret does not exist, Config Sync records
+	// the host keys presented by the Git server into it. To read them, the
+	// reconciler-manager connects to the host and port of the repo URL, and
+	// to no other host. To accept rotated host keys, delete the secret and it
+	// will be pinned again.
+	// +kubebuilder:validation:Enum=Manual;PinOnFirstUse
+	// +optional
+	KnownHostsPolicy string `json:"knownHostsPolicy,omitempty"`
 }
 
+const (
+	// KnownHostsPolicyManual requires the known hosts secret to be created
+	// out of band.
+	KnownHostsPolicyManual = "Manual"
+	// KnownHostsPolicyPinOnFirstUse records the host keys presented by the Git
+	// server into the known hosts secret, if it does not exist.
+	KnownHostsPolicyPinOnFirstUse = "PinOnFirstUse"
+)
+
 // SecretReference contains the reference to the secret used to connect to
 // Git source of truth.
 type SecretReference struct {
@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// NamespaceRequest requests a tenant Namespace. When namespace provisioning is
+// enabled on the RootSync which declares it, the root reconciler creates the
+// Namespace of the same name, along with the objects of the namespace
+// provisioning template, e.g. a ResourceQuota, RoleBindings and a RepoSync.
+type NamespaceRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec NamespaceRequestSpec `json:"spec,omitempty"`
+}
+
+// NamespaceRequestSpec defines the requested Namespace.
+type NamespaceRequestSpec struct {
+	// labels are set on the provisioned Namespace.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// annotations are set on the provisioned Namespace.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceRequestList contains a list of NamespaceRequest
+type NamespaceRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceRequest `json:"items"`
+}
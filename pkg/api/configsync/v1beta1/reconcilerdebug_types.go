@@ -0,0 +1,164 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ReconcilerDebug is a snapshot of the internal state of a reconciler, for
+// troubleshooting a RootSync or RepoSync which is stuck.
+//
+// The reconciler writes the snapshot when the
+// `configsync.gke.io/debug-snapshot-requested-at` annotation of its RootSync
+// or RepoSync changes. The ReconcilerDebug has the same name as the
+// reconciler, in the namespace of the RootSync or RepoSync, and is deleted
+// with it. The snapshot never includes the content of the declared objects.
+type ReconcilerDebug struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status ReconcilerDebugStatus `json:"status,omitempty"`
+}
+
+// ReconcilerDebugStatus is the state of the reconciler when the snapshot was
+// taken.
+type ReconcilerDebugStatus struct {
+	// requestedAt is the value of the annotation which requested the snapshot.
+	// +optional
+	RequestedAt string `json:"requestedAt,omitempty"`
+
+	// snapshotTime is when the snapshot was taken.
+	// +optional
+	SnapshotTime metav1.Time `json:"snapshotTime,omitempty"`
+
+	// lastRuns is when the reconciler last ran for each trigger, e.g.
+	// "resync", "reimport" or "retry". A trigger which has not run for longer
+	// than its period points to the stuck step.
+	// +optional
+	LastRuns map[string]metav1.Time `json:"lastRuns,omitempty"`
+
+	// commit is the cached commit, which the reconciler read from the source.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// syncDir is the cached sync directory of the commit.
+	// +optional
+	SyncDir string `json:"syncDir,omitempty"`
+
+	// lastApplied is the sync directory which was last applied successfully.
+	// +optional
+	LastApplied string `json:"lastApplied,omitempty"`
+
+	// sourceCommit, renderingCommit and syncCommit are the commits last
+	// reported in the source, rendering and sync status of the RootSync or
+	// RepoSync.
+	// +optional
+	SourceCommit string `json:"sourceCommit,omitempty"`
+	// +optional
+	RenderingCommit string `json:"renderingCommit,omitempty"`
+	// +optional
+	SyncCommit string `json:"syncCommit,omitempty"`
+
+	// retry is the retry state of the reconciler.
+	// +optional
+	Retry ReconcilerDebugRetry `json:"retry,omitempty"`
+
+	// cache is the validity of the cached steps of the reconciliation.
+	// +optional
+	Cache ReconcilerDebugCache `json:"cache,omitempty"`
+
+	// errors is the backlog of errors of the last reconciliation, without the
+	// resources of the errors.
+	// +optional
+	Errors []ConfigSyncError `json:"errors,omitempty"`
+
+	// errorSummary summarizes the errors of the last reconciliation.
+	// +optional
+	ErrorSummary *ErrorSummary `json:"errorSummary,omitempty"`
+}
+
+// ReconcilerDebugRetry is the retry state of a reconciler.
+type ReconcilerDebugRetry struct {
+	// needToRetry indicates whether the last reconciliation failed and is
+	// retried.
+	// +optional
+	NeedToRetry bool `json:"needToRetry,omitempty"`
+
+	// retries is the number of reconciliations which failed with the same
+	// errors.
+	// +optional
+	Retries int `json:"retries,omitempty"`
+
+	// nextRetryTime is the earliest time of the next retry.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// managementConflict indicates whether the remediator detected a
+	// management conflict, which triggers a retry.
+	// +optional
+	ManagementConflict bool `json:"managementConflict,omitempty"`
+
+	// needToUpdateWatch indicates whether the remediator watches need to be
+	// updated, which triggers a retry.
+	// +optional
+	NeedToUpdateWatch bool `json:"needToUpdateWatch,omitempty"`
+}
+
+// ReconcilerDebugCache is the validity of the cached steps of a
+// reconciliation. The reconciler skips the steps which are cached for the
+// current commit.
+type ReconcilerDebugCache struct {
+	// hasParserResult indicates whether the parser result is cached.
+	// +optional
+	HasParserResult bool `json:"hasParserResult,omitempty"`
+
+	// objectsToApply is the number of cached objects to apply.
+	// +optional
+	ObjectsToApply int `json:"objectsToApply,omitempty"`
+
+	// objectsSkipped is the number of cached objects which are not applied,
+	// e.g. because their scope is unknown.
+	// +optional
+	ObjectsSkipped int `json:"objectsSkipped,omitempty"`
+
+	// declaredResourcesUpdated indicates whether the declared resources were
+	// updated.
+	// +optional
+	DeclaredResourcesUpdated bool `json:"declaredResourcesUpdated,omitempty"`
+
+	// applied indicates whether the declared resources were applied.
+	// +optional
+	Applied bool `json:"applied,omitempty"`
+
+	// watchesUpdated indicates whether the remediator watches were updated.
+	// +optional
+	WatchesUpdated bool `json:"watchesUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReconcilerDebugList contains a list of ReconcilerDebug
+type ReconcilerDebugList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReconcilerDebug `json:"items"`
+}
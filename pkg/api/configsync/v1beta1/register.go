@@ -38,8 +38,22 @@ var (
 
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&ApprovalRequest{},
+		&ApprovalRequestList{},
+		&ConfigSyncStatus{},
+		&ConfigSyncStatusList{},
+		&ConfigSyncUpgradePolicy{},
+		&ConfigSyncUpgradePolicyList{},
+		&DeclaredObjectMutator{},
+		&DeclaredObjectMutatorList{},
+		&NamespaceRequest{},
+		&NamespaceRequestList{},
+		&ReconcilerDebug{},
+		&ReconcilerDebugList{},
 		&RepoSync{},
 		&RepoSyncList{},
+		&RepoSyncQuota{},
+		&RepoSyncQuotaList{},
 		&RootSync{},
 		&RootSyncList{},
 	)
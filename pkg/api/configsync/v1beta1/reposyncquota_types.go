@@ -0,0 +1,69 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// RepoSyncQuota limits the resources declared by the RepoSyncs in its
+// namespace. The limits are enforced by the namespace reconcilers when parsing
+// the source of truth, so that a single repository cannot bloat the etcd
+// usage of the whole cluster.
+//
+// Cluster admins should restrict who can create and update RepoSyncQuota
+// objects with RBAC, in the same way as ResourceQuota objects.
+type RepoSyncQuota struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec RepoSyncQuotaSpec `json:"spec,omitempty"`
+}
+
+// RepoSyncQuotaSpec defines the limits of a RepoSyncQuota.
+// Unset limits are not enforced.
+type RepoSyncQuotaSpec struct {
+	// maxObjects is the maximum number of objects that each RepoSync in the
+	// namespace may declare.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxObjects *int64 `json:"maxObjects,omitempty"`
+
+	// maxTotalSize is the maximum total size of the objects that each RepoSync
+	// in the namespace may declare, e.g. "10Mi". The size of an object is the
+	// size of its JSON encoding.
+	// +optional
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+
+	// forbiddenKinds is the list of kinds that the RepoSyncs in the namespace
+	// must not declare.
+	// +optional
+	ForbiddenKinds []metav1.GroupKind `json:"forbiddenKinds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepoSyncQuotaList contains a list of RepoSyncQuota
+type RepoSyncQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepoSyncQuota `json:"items"`
+}
@@ -53,6 +53,17 @@ type RootSyncSpec struct {
 	// +optional
 	SourceFormat string `json:"sourceFormat,omitempty"`
 
+	// hierarchicalDirs are the directories of an unstructured repository,
+	// relative to the sync directory, which are in the hierarchy format, so
+	// that a repository can be converted one directory at a time. Each
+	// directory holds the system, cluster, clusterregistry and namespaces
+	// directories of a hierarchical repository, and must not be nested in
+	// another one.
+	//
+	// Only applicable if sourceFormat is unstructured.
+	// +optional
+	HierarchicalDirs []string `json:"hierarchicalDirs,omitempty"`
+
 	// sourceType specifies the type of the source of truth.
 	//
 	// Must be one of git, oci, helm. Optional. Set to git if not specified.
@@ -77,12 +88,124 @@ type RootSyncSpec struct {
 	// +nullable
 	// +optional
 	Override *OverrideSpec `json:"override,omitempty"`
+
+	// target specifies the cluster that the resources are synced to.
+	// Optional. Set to the cluster where the RootSync object exists if not
+	// specified.
+	// +optional
+	Target *Target `json:"target,omitempty"`
+
+	// targets specifies multiple remote clusters that the same rendered
+	// resources are synced to, each with its own inventory and status.
+	// Mutually exclusive with target.
+	// +optional
+	Targets []SyncTarget `json:"targets,omitempty"`
+
+	// prune restricts the kinds of the objects that the reconciler deletes,
+	// when they are removed from the source of truth. The objects of the other
+	// kinds are only ever created and updated: they are unmanaged instead of
+	// deleted.
+	// +optional
+	Prune *PruneSpec `json:"prune,omitempty"`
+
+	// rollout paces the updates of the Deployments, StatefulSets and
+	// DaemonSets managed by the reconciler, which restart their pods, so that
+	// a change to many workloads at once, e.g. a base image bump, does not
+	// restart all of them simultaneously.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// syncWindows restrict when the reconciler applies the changes of the
+	// source of truth. Outside the allowed windows, the source is still
+	// fetched and rendered, and the remediator still reverts drift, but new
+	// commits are only applied once a window allows it.
+	// If unset, changes are applied at any time.
+	// +optional
+	SyncWindows []SyncWindow `json:"syncWindows,omitempty"`
+
+	// dryRun previews the commits of the source of truth instead of syncing
+	// them. The reconciler runs server-side dry-run applies of the declared
+	// resources, and reports the objects that syncing the commit would add,
+	// change or prune in .status.dryRun, without mutating the cluster.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// renames rename the declared objects after rendering, so that the same
+	// package can be synced multiple times in a cluster without forking it
+	// only to rename its objects. The rules are applied in order. References
+	// to the renamed objects in the fields of other objects are not updated.
+	// +optional
+	Renames []RenameRule `json:"renames,omitempty"`
+
+	// converter configures a plugin which converts the files of the source
+	// of truth that are not Kubernetes manifests into Kubernetes objects.
+	// +optional
+	Converter *Converter `json:"converter,omitempty"`
+
+	// mode specifies whether the reconciler keeps syncing the source of
+	// truth, or stops once it synced it, e.g. for bootstrap-only
+	// repositories. One-shot reconcilers run as Jobs if the
+	// reconciler-manager enables it, and keep syncing otherwise.
+	//
+	// Must be one of continuous, one-shot. Optional. Set to continuous if
+	// not specified.
+	// +kubebuilder:validation:Pattern=^(continuous|one-shot|)$
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// Target specifies a remote cluster to sync resources to, e.g. a workload
+// cluster or a virtual cluster that cannot run Config Sync itself.
+type Target struct {
+	// kubeconfigSecretRef is the reference of the Secret in the
+	// config-management-system namespace that stores the kubeconfig to access
+	// the target cluster under the `kubeconfig` key.
+	// +optional
+	KubeconfigSecretRef *SecretReference `json:"kubeconfigSecretRef,omitempty"`
+}
+
+// SyncTarget is one of the remote clusters that a RootSync fans out to.
+type SyncTarget struct {
+	// name identifies the target in the status. Must be unique within the
+	// RootSync, and a valid DNS-1123 label.
+	Name string `json:"name"`
+
+	Target `json:",inline"`
+
+	// variables are substituted for the `${NAME}` references in the string
+	// fields of the resources synced to this target.
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// TargetStatus is the sync status of one of the RootSync targets.
+type TargetStatus struct {
+	// name is the name of the target.
+	Name string `json:"name"`
+
+	// commit is the hash of the most recent commit synced to the target.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// lastUpdate is the timestamp of when the status of the target was last
+	// updated.
+	// +optional
+	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+
+	// errors is a list of errors that occurred while applying the resources
+	// to the target.
+	// +optional
+	Errors []ConfigSyncError `json:"errors,omitempty"`
 }
 
 // RootSyncStatus defines the observed state of RootSync
 type RootSyncStatus struct {
 	Status `json:",inline"`
 
+	// targets reports the sync status of each of spec.targets.
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
 	// conditions represents the latest available observations of the RootSync's
 	// current state.
 	// +optional
@@ -110,6 +233,18 @@ const (
 	RootSyncReconcilerFinalizing RootSyncConditionType = "ReconcilerFinalizing"
 	// RootSyncReconcilerFinalizerFailure means that the root reconciler finalizer has errored, blocking deletion.
 	RootSyncReconcilerFinalizerFailure RootSyncConditionType = "ReconcilerFinalizerFailure"
+	// RootSyncRetriesExhausted means that the root reconciler stopped retrying the current commit after too many failed attempts.
+	RootSyncRetriesExhausted RootSyncConditionType = "RetriesExhausted"
+	// RootSyncFlapping means that the root reconciler alternated between failing and succeeding to sync too often recently.
+	RootSyncFlapping RootSyncConditionType = "Flapping"
+	// RootSyncWebhookDegraded means that the Config Sync admission webhook
+	// cannot prevent drift, because its serving certificate or CA is expired
+	// or rotating, or because it is unreachable.
+	RootSyncWebhookDegraded RootSyncConditionType = "WebhookDegraded"
+	// RootSyncOverlapping means that some of the objects in the inventory of
+	// the RootSync are also in the inventory of another RootSync or RepoSync,
+	// so that their management is going to conflict.
+	RootSyncOverlapping RootSyncConditionType = "Overlapping"
 )
 
 // RootSyncCondition describes the state of a RootSync at a certain point.
@@ -132,6 +267,11 @@ type RootSyncCondition struct {
 	// A human readable message indicating details about the transition.
 	// +optional
 	Message string `json:"message,omitempty"`
+	// observedGeneration is the generation of the sync resource this
+	// condition was set for. A condition with an observedGeneration lower than
+	// the generation of the sync resource is out of date with its spec.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// hash of the source of truth. It can be a git commit hash, or an OCI image digest.
 	// +optional
 	Commit string `json:"commit,omitempty"`
@@ -49,6 +49,36 @@ type Status struct {
 	// source of truth to the cluster.
 	// +optional
 	Sync SyncStatus `json:"sync,omitempty"`
+
+	// retry describes when the reconciler retries, after the last attempt
+	// to sync the source of truth failed. Unset if the last attempt
+	// succeeded.
+	// +optional
+	Retry *RetryStatus `json:"retry,omitempty"`
+
+	// dryRun describes the changes that syncing the last previewed commit
+	// would make to the cluster. Only set while spec.dryRun is true.
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+}
+
+// RetryStatus describes the backoff of the retries of a reconciler after
+// errors. The retries back off exponentially, with jitter, up to a maximum
+// interval which depends on the class of the errors.
+type RetryStatus struct {
+	// errorClass is the class of the errors of the last attempt, which
+	// determines its backoff: transient, user or terminal.
+	// +optional
+	ErrorClass string `json:"errorClass,omitempty"`
+
+	// attempts is the number of consecutive attempts which failed with the
+	// same errors.
+	// +optional
+	Attempts int `json:"attempts,omitempty"`
+
+	// nextRetryTime is the earliest time of the next retry.
+	// +optional
+	NextRetryTime metav1.Time `json:"nextRetryTime,omitempty"`
 }
 
 // SourceStatus describes the source status of a source-of-truth.
@@ -104,6 +134,13 @@ type RenderingStatus struct {
 	// +optional
 	Commit string `json:"commit,omitempty"`
 
+	// digest of the rendered configs, computed from the paths and contents of
+	// the files read by the reconciler. The digest doesn't depend on the
+	// commit, so two commits, or two clusters, with the same digest run
+	// identical rendered configs.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
 	// lastUpdate is the timestamp of when this status was last updated by a
 	// reconciler.
 	// +nullable
@@ -155,6 +192,66 @@ type SyncStatus struct {
 	// errorSummary summarizes the errors encountered during the process of syncing the resources.
 	// +optional
 	ErrorSummary *ErrorSummary `json:"errorSummary,omitempty"`
+
+	// immutableFieldChanges is a list of the resources which could not be
+	// applied, because the change indicated by Commit modifies their immutable
+	// fields.
+	// +optional
+	ImmutableFieldChanges []ResourceRef `json:"immutableFieldChanges,omitempty"`
+
+	// abandonedResources is a list of the resources which Config Sync stopped
+	// managing, because their management was disabled in the source of truth,
+	// or because they were removed from the source of truth, but their kind
+	// cannot be pruned.
+	// +optional
+	AbandonedResources []AbandonedResource `json:"abandonedResources,omitempty"`
+
+	// orphanedResources is a list of the resources which carry the management
+	// metadata of this reconciler, but are missing from its inventory, e.g.
+	// because they were orphaned by an older version of Config Sync or edited
+	// manually. They are found by a periodic audit, and are neither updated
+	// nor pruned by the reconciler.
+	// +optional
+	OrphanedResources []ResourceRef `json:"orphanedResources,omitempty"`
+
+	// resourceConsumption is the approximate consumption of cluster resources
+	// attributable to this RootSync or RepoSync, reported periodically.
+	// +optional
+	ResourceConsumption *ResourceConsumption `json:"resourceConsumption,omitempty"`
+
+	// clusterPrerequisites is a list of the cluster-scoped objects which
+	// declared resources are waiting for, but which the reconciler cannot
+	// create, e.g. the CustomResourceDefinition of a custom resource declared
+	// in a RepoSync. The resources are synced as soon as the prerequisites
+	// are available.
+	// +optional
+	ClusterPrerequisites []ClusterPrerequisite `json:"clusterPrerequisites,omitempty"`
+
+	// selectorSkipped summarizes the declared objects which are not synced
+	// to this cluster, because their ClusterSelector does not select the
+	// cluster, or their NamespaceSelector does not select any namespace.
+	// +optional
+	SelectorSkipped *SkippedObjects `json:"selectorSkipped,omitempty"`
+
+	// warnings is a list of the problems of the last sync which do not block
+	// it, but leave the cluster only partially protected, e.g. a failure to
+	// update the admission webhook, which leaves the drift prevention of newly
+	// declared types out of date.
+	// +optional
+	Warnings []SyncWarning `json:"warnings,omitempty"`
+
+	// watchHealth is a list of the types whose remediator watches are failing
+	// to start or stalled, e.g. because the permission to watch them was denied,
+	// or their CRD was deleted. Drift of these types is not corrected until the
+	// watches recover.
+	// +optional
+	WatchHealth []WatchFailure `json:"watchHealth,omitempty"`
+
+	// lastFullResync is the time when the last periodic full resync, which
+	// re-applies all the resources even if the source of truth did not
+	// change, completed without errors.
+	// +optional
+	LastFullResync *metav1.Time `json:"lastFullResync,omitempty"`
 }
 
 // GitStatus describes the status of a Git source of truth.
@@ -249,6 +346,104 @@ type ResourceRef struct {
 	GVK metav1.GroupVersionKind `json:"gvk,omitempty"`
 }
 
+// AbandonedResource is a resource which Config Sync stopped managing, because
+// its management was disabled with the
+// `configmanagement.gke.io/managed: disabled` annotation, or because it was
+// removed from the source of truth, but its kind cannot be pruned.
+type AbandonedResource struct {
+	// resource identifies the abandoned K8S resource.
+	Resource ResourceRef `json:"resource"`
+
+	// abandonedAt is the timestamp of when the reconciler first stopped
+	// managing the resource.
+	AbandonedAt metav1.Time `json:"abandonedAt"`
+
+	// reason is why the reconciler stopped managing the resource: either
+	// `ManagementDisabled` or `PruneDenied`. Empty means `ManagementDisabled`.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+const (
+	// AbandonedManagementDisabled is the reason of the resources abandoned,
+	// because their management was disabled.
+	AbandonedManagementDisabled = "ManagementDisabled"
+	// AbandonedPruneDenied is the reason of the resources retained, because
+	// they were removed from the source of truth, but their kind cannot be
+	// pruned.
+	AbandonedPruneDenied = "PruneDenied"
+)
+
+// ResourceConsumption is the approximate consumption of cluster resources
+// attributable to a RootSync or RepoSync, e.g. for chargeback.
+type ResourceConsumption struct {
+	// objectCount is the number of declared objects.
+	ObjectCount int `json:"objectCount"`
+
+	// estimatedBytes is the approximate size in etcd of the declared objects,
+	// computed from their JSON encoding.
+	EstimatedBytes int64 `json:"estimatedBytes"`
+
+	// apiCallsPerMinute is the average rate of API server calls made by the
+	// reconciler since the previous report.
+	APICallsPerMinute int64 `json:"apiCallsPerMinute"`
+
+	// lastUpdate is the timestamp of when the consumption was last computed.
+	LastUpdate metav1.Time `json:"lastUpdate"`
+}
+
+// ClusterPrerequisite is a missing cluster-scoped object which declared
+// resources depend on.
+type ClusterPrerequisite struct {
+	// kind is the kind of the missing object. Only CustomResourceDefinition
+	// is reported.
+	Kind string `json:"kind"`
+
+	// groupKind is the type defined by the missing CustomResourceDefinition,
+	// e.g. `Database.operator.example.com`.
+	GroupKind string `json:"groupKind"`
+
+	// resources is a list of the declared resources waiting for the object.
+	// +optional
+	Resources []ResourceRef `json:"resources,omitempty"`
+}
+
+// SkippedObjects summarizes declared objects which are not synced.
+type SkippedObjects struct {
+	// totalCount is the number of skipped objects.
+	TotalCount int `json:"totalCount"`
+
+	// objects is a sample of the skipped objects.
+	// +optional
+	Objects []ResourceRef `json:"objects,omitempty"`
+
+	// truncated indicates whether objects is a sample of the skipped
+	// objects, rather than all of them.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// SyncWarning describes a problem of a sync which does not block it.
+type SyncWarning struct {
+	// code identifies the kind of the warning, e.g. WebhookUpdateFailed.
+	Code string `json:"code"`
+
+	// message describes the warning.
+	Message string `json:"message"`
+}
+
+// WatchFailure describes a failing remediator watch.
+type WatchFailure struct {
+	// gvk is the GroupVersionKind being watched.
+	GVK metav1.GroupVersionKind `json:"gvk"`
+
+	// message is the last error of the watch.
+	Message string `json:"message"`
+
+	// since is the timestamp of when the watch started failing.
+	Since metav1.Time `json:"since"`
+}
+
 // SourceType specifies the type of the source of truth.
 type SourceType string
 
@@ -262,3 +457,211 @@ const (
 	// HelmSource represents the source type is Helm repository.
 	HelmSource SourceType = "helm"
 )
+
+// SyncMode specifies whether a reconciler syncs continuously or once.
+type SyncMode string
+
+const (
+	// ContinuousSyncMode keeps syncing the source of truth.
+	ContinuousSyncMode SyncMode = "continuous"
+
+	// OneShotSyncMode stops syncing once the source of truth is synced.
+	OneShotSyncMode SyncMode = "one-shot"
+)
+
+// PruneSpec restricts the kinds of the objects that a reconciler prunes.
+type PruneSpec struct {
+	// allowedKinds is the list of the kinds of the objects that the
+	// reconciler deletes when they are removed from the source of truth.
+	// If empty, all the kinds are allowed, except for the deniedKinds.
+	// +optional
+	AllowedKinds []metav1.GroupKind `json:"allowedKinds,omitempty"`
+
+	// deniedKinds is the list of the kinds of the objects that the reconciler
+	// never deletes. The objects of these kinds are unmanaged instead, when
+	// they are removed from the source of truth. Takes precedence over
+	// allowedKinds.
+	// +optional
+	DeniedKinds []metav1.GroupKind `json:"deniedKinds,omitempty"`
+
+	// maxPercentage is the largest percentage of the managed objects that
+	// the reconciler prunes at once. Above it, the reconciler refuses to
+	// prune, and reports an error, until the prune is confirmed by annotating
+	// the RootSync or RepoSync with `configsync.gke.io/confirm-prune` set to
+	// the number of objects to prune. This protects against a bad change
+	// removing most of the objects from the source of truth.
+	// If unset, the percentage is not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxPercentage *int64 `json:"maxPercentage,omitempty"`
+
+	// maxCount is the largest number of the managed objects that the
+	// reconciler prunes at once. Above it, the reconciler refuses to prune,
+	// like above maxPercentage.
+	// If unset, the number is not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount *int64 `json:"maxCount,omitempty"`
+}
+
+// RolloutSpec limits the number of the managed workloads rolling out at once.
+// The update of a workload which would restart its pods is held back while
+// the limits are reached, or while a PodDisruptionBudget of its pods allows
+// no disruption, and is retried until the other workloads finished rolling
+// out. The workloads whose updates are held back are reported as sync errors
+// in the meantime.
+type RolloutSpec struct {
+	// maxConcurrentUpdates is the largest number of the managed workloads
+	// which roll out at once. If unset, the number is not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentUpdates *int64 `json:"maxConcurrentUpdates,omitempty"`
+
+	// maxConcurrentUpdatesPerNamespace is the largest number of the managed
+	// workloads of a namespace which roll out at once. If unset, the number
+	// is not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentUpdatesPerNamespace *int64 `json:"maxConcurrentUpdatesPerNamespace,omitempty"`
+}
+
+const (
+	// SyncWindowAllow is the kind of the windows in which changes are
+	// applied.
+	SyncWindowAllow = "allow"
+	// SyncWindowDeny is the kind of the windows in which changes are not
+	// applied.
+	SyncWindowDeny = "deny"
+)
+
+// SyncWindow is a recurring window of time in which the changes of the source
+// of truth are either allowed or denied. A deny window which is open takes
+// precedence over the allow windows. If there are allow windows, changes are
+// only applied while one of them is open.
+type SyncWindow struct {
+	// kind is whether changes are applied while the window is open.
+	// Must be one of allow, deny.
+	// +kubebuilder:validation:Enum=allow;deny
+	Kind string `json:"kind"`
+
+	// schedule is when the window opens, as a cron expression with five
+	// fields: minute, hour, day of month, month and day of week, e.g.
+	// "0 22 * * 1-5" for 10pm on weekdays.
+	Schedule string `json:"schedule"`
+
+	// duration is how long the window stays open after it opens, e.g. "2h".
+	Duration metav1.Duration `json:"duration"`
+
+	// timeZone is the IANA time zone of the schedule, e.g. "Europe/Paris".
+	// If unset, the schedule is in UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+const (
+	// DiffActionAdd is the action of the objects which syncing the commit
+	// would create.
+	DiffActionAdd = "Add"
+	// DiffActionChange is the action of the objects which syncing the commit
+	// would update.
+	DiffActionChange = "Change"
+	// DiffActionPrune is the action of the objects which syncing the commit
+	// would delete.
+	DiffActionPrune = "Prune"
+)
+
+// DryRunStatus describes the changes that syncing a commit would make to the
+// cluster, according to server-side dry-run applies of the declared resources.
+type DryRunStatus struct {
+	// commit is the hash of the source of truth that is previewed.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// lastUpdate is the timestamp of when this status was last updated by a
+	// reconciler.
+	// +nullable
+	// +optional
+	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+
+	// adds is the number of objects which syncing the commit would create.
+	// +optional
+	Adds int `json:"adds,omitempty"`
+
+	// changes is the number of objects which syncing the commit would update.
+	// +optional
+	Changes int `json:"changes,omitempty"`
+
+	// prunes is the number of objects which syncing the commit would delete.
+	// +optional
+	Prunes int `json:"prunes,omitempty"`
+
+	// unchanged is the number of declared objects which are already in sync.
+	// +optional
+	Unchanged int `json:"unchanged,omitempty"`
+
+	// objects is a list of the objects which syncing the commit would add,
+	// change or prune. The list is truncated to keep the RootSync/RepoSync
+	// object small, while the counts include all the objects.
+	// +optional
+	Objects []ObjectDiff `json:"objects,omitempty"`
+
+	// truncated indicates whether the `Objects` field omits some objects.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// errors is a list of any errors that occurred while previewing the
+	// change indicated by Commit.
+	// +optional
+	Errors []ConfigSyncError `json:"errors,omitempty"`
+}
+
+// ObjectDiff is a change that syncing a commit would make to an object.
+type ObjectDiff struct {
+	// action is the change to the object: Add, Change or Prune.
+	// +kubebuilder:validation:Enum=Add;Change;Prune
+	Action string `json:"action"`
+
+	// resource identifies the K8S resource.
+	Resource ResourceRef `json:"resource"`
+
+	// target is the name of the target cluster of the object, if the
+	// RootSync syncs to multiple targets.
+	// +optional
+	Target string `json:"target,omitempty"`
+}
+
+// RenameRule renames the declared objects of some kinds. The name of an
+// object is remapped with nameRegex first, then the namespace prefix and
+// suffix are added.
+type RenameRule struct {
+	// kinds restricts the rule to the objects of these kinds. Include the
+	// Namespace kind to rename the Namespace objects along with the objects
+	// in them.
+	// If empty, the rule applies to the objects of all kinds.
+	// +optional
+	Kinds []metav1.GroupKind `json:"kinds,omitempty"`
+
+	// namespacePrefix is prepended to the namespace of the namespaced
+	// objects, and to the name of the Namespace objects.
+	// Only supported by RootSyncs.
+	// +optional
+	NamespacePrefix string `json:"namespacePrefix,omitempty"`
+
+	// namespaceSuffix is appended to the namespace of the namespaced
+	// objects, and to the name of the Namespace objects.
+	// Only supported by RootSyncs.
+	// +optional
+	NamespaceSuffix string `json:"namespaceSuffix,omitempty"`
+
+	// nameRegex is a regular expression, in the RE2 syntax, matching the
+	// names of the objects to rename, e.g. "^(.*)-base$". The names which do
+	// not match are left as is.
+	// +optional
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// nameReplacement replaces the matches of nameRegex in the names, where
+	// `$1` is the text of the first capture group, e.g. "${1}-team-a".
+	// +optional
+	NameReplacement string `json:"nameReplacement,omitempty"`
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// ConfigSyncUpgradePolicy controls how the reconciler-manager rolls out new
+// reconciler images after Config Sync is upgraded. Without a policy, every
+// reconciler is restarted with the new images at once.
+//
+// With a policy, the reconcilers are upgraded in waves. The first wave is the
+// canary RootSyncs and RepoSyncs, and each following wave is only started once
+// all the reconcilers of the previous waves run the new images and are healthy.
+//
+// Only the ConfigSyncUpgradePolicy named "config-sync" is used.
+type ConfigSyncUpgradePolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec ConfigSyncUpgradePolicySpec `json:"spec,omitempty"`
+
+	// +optional
+	Status ConfigSyncUpgradePolicyStatus `json:"status,omitempty"`
+}
+
+// ConfigSyncUpgradePolicySpec defines the waves of a reconciler upgrade.
+type ConfigSyncUpgradePolicySpec struct {
+	// canarySelector selects the RootSyncs and RepoSyncs, by their labels, to
+	// upgrade in the first wave. If unset, the first wave is the same size as
+	// the other waves.
+	// +optional
+	CanarySelector *metav1.LabelSelector `json:"canarySelector,omitempty"`
+
+	// waveSize is the maximum number of RootSyncs and RepoSyncs to upgrade in
+	// each wave after the canaries. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WaveSize *int `json:"waveSize,omitempty"`
+}
+
+// ConfigSyncUpgradePolicyStatus reports the progress of the latest upgrade.
+type ConfigSyncUpgradePolicyStatus struct {
+	// totalSyncs is the number of RootSyncs and RepoSyncs on the cluster.
+	// +optional
+	TotalSyncs int `json:"totalSyncs,omitempty"`
+
+	// upgradedSyncs is the number of RootSyncs and RepoSyncs whose reconciler
+	// runs the latest images.
+	// +optional
+	UpgradedSyncs int `json:"upgradedSyncs,omitempty"`
+
+	// currentWave is the index of the wave being upgraded, starting at 0 for
+	// the canaries.
+	// +optional
+	CurrentWave int `json:"currentWave,omitempty"`
+
+	// unhealthySyncs is the list of the upgraded RootSyncs and RepoSyncs that
+	// are not healthy, in the kind/namespace/name format. The upgrade does not
+	// proceed to the next wave until they are healthy.
+	// +optional
+	UnhealthySyncs []string `json:"unhealthySyncs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigSyncUpgradePolicyList contains a list of ConfigSyncUpgradePolicy
+type ConfigSyncUpgradePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConfigSyncUpgradePolicy `json:"items"`
+}
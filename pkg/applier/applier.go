@@ -16,6 +16,7 @@ package applier
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"sync"
@@ -27,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/api/configmanagement"
 	"kpt.dev/configsync/pkg/api/configsync"
@@ -77,6 +79,26 @@ type Applier interface {
 	// This method may be called while Destroy is running, to get the set of
 	// errors encounted so far.
 	Errors() status.MultiError
+	// AbandonedObjects returns the objects which the last apply stopped
+	// managing, because their management was disabled.
+	AbandonedObjects() []client.Object
+	// RetainedObjects returns the objects which the last apply stopped
+	// managing, because they were removed from the source of truth, but
+	// their kind cannot be pruned.
+	RetainedObjects() []client.Object
+	// RequestFullApply makes the next Apply apply all the objects and prune
+	// the removed ones, even if partial apply is enabled.
+	// This is called by the reconciler on every resync.
+	RequestFullApply()
+	// PendingPruneExpiry returns when the prune delay of the first object
+	// pending prune expires, or the zero time if no object is pending prune.
+	// This is called by the reconciler to prune the object once it expires.
+	PendingPruneExpiry() time.Time
+	// DryRun returns the objects which applying the desired resources would
+	// add, change or prune, according to server-side dry-run applies,
+	// without mutating the cluster.
+	// This is called by the reconciler instead of Apply in dry-run mode.
+	DryRun(ctx context.Context, desiredResources []client.Object) (*DryRunResult, status.MultiError)
 }
 
 // Destroyer is a bulk client for deleting all the managed resource objects
@@ -127,10 +149,54 @@ type supervisor struct {
 	// execMux prevents concurrent Apply/Destroy calls
 	execMux sync.Mutex
 	// errorMux prevents concurrent modifications to the cached set of errors
+	// and abandoned objects
 	errorMux sync.RWMutex
 	// errs recieved from the current (if running) or previous Apply/Destroy.
 	// These errors is cleared at the start of the Apply/Destroy methods.
 	errs status.MultiError
+	// abandoned objects from the current (if running) or previous Apply.
+	// These objects are cleared at the start of the Apply/Destroy methods.
+	abandoned []client.Object
+	// retained objects from the current (if running) or previous Apply.
+	// These objects are cleared at the start of the Apply/Destroy methods.
+	retained []client.Object
+
+	// lastApplied are the objects applied by the previous Apply, used to keep
+	// applying the removed objects until their prune delay expires.
+	lastApplied map[core.ID]*unstructured.Unstructured
+	// pendingPruneExpiry is when the prune delay of the first object pending
+	// prune expires, or the zero time if no object is pending prune.
+	pendingPruneExpiry time.Time
+	// emergencyOverridesChecked is true once all the declared objects were
+	// checked for emergency overrides on the cluster. The overrides tracked by
+	// the remediator are lost on restart, and only found again once it watches
+	// the declared objects, after the first Apply.
+	emergencyOverridesChecked bool
+	// lastSucceeded are the objects applied by the previous Apply if it
+	// succeeded, used to only apply what changed when partial apply is
+	// enabled. Nil makes the next Apply apply all the objects.
+	lastSucceeded map[core.ID]*unstructured.Unstructured
+	// retryCommit is the commit of the failed applies whose applied objects
+	// are tracked in retrySucceeded.
+	retryCommit string
+	// retrySucceeded are the objects which were applied and reconciled by the
+	// failed applies of retryCommit, used to skip them when the commit is
+	// retried.
+	retrySucceeded map[core.ID]*unstructured.Unstructured
+	// terminatingNamespaces tracks when the applier first found each of the
+	// namespaces terminating, to tell how long their objects have been
+	// waiting for the namespace deletion.
+	terminatingNamespaces map[string]time.Time
+	// blockedCRDPrunes tracks the number of custom resources of each of the
+	// CustomResourceDefinitions which are not pruned, to only record an event
+	// when the number changes.
+	blockedCRDPrunes map[core.ID]int
+	// blockedPrunes is the number of objects which were last not pruned,
+	// because it exceeds the prune limits, to only record an event when the
+	// number changes.
+	blockedPrunes int
+	// now returns the current time. Overridden in tests.
+	now func() time.Time
 }
 
 var _ Applier = &supervisor{}
@@ -170,6 +236,7 @@ func NewNamespaceSupervisor(cs *ClientSet, namespace declared.Scope, syncName st
 		syncName:         syncName,
 		syncNamespace:    string(namespace),
 		reconcileTimeout: reconcileTimeout,
+		now:              time.Now,
 	}
 	klog.V(4).Infof("Namespace Supervisor %s/%s is initialized", namespace, syncName)
 	return a, nil
@@ -199,6 +266,7 @@ func NewRootSupervisor(cs *ClientSet, syncName string, reconcileTimeout time.Dur
 		syncName:         syncName,
 		syncNamespace:    string(configmanagement.ControllerNamespace),
 		reconcileTimeout: reconcileTimeout,
+		now:              time.Now,
 	}
 	klog.V(4).Infof("Root Supervisor %s is initialized and synced with the API server", syncName)
 	return a, nil
@@ -500,13 +568,76 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		}
 	}
 	klog.Infof("%v objects to be applied: %v", len(enabledObjs), core.GKNNs(enabledObjs))
+	commit := declaredCommit(enabledObjs)
 	resources, err := toUnstructured(enabledObjs)
 	if err != nil {
 		a.addError(err)
 		return nil, a.Errors()
 	}
+	// Pace the workload updates before the prunes are deferred, which records
+	// the applied objects, so that the held workloads are recorded with their
+	// held pod templates.
+	if err := a.paceWorkloadUpdates(ctx, resources); err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	if err := a.holdEmergencyOverrides(ctx, resources); err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	lastApplied := a.lastApplied
+	pendingPrune, err := a.deferPrunes(ctx, resources)
+	if err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	resources = append(resources, pendingPrune...)
+	heldCRDs, err := a.holdCRDPrunes(ctx, resources)
+	if err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	resources = append(resources, heldCRDs...)
+	if err := a.retainUnprunableObjects(ctx, resources, lastApplied); err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	declaredObjs := make(map[core.ID]*unstructured.Unstructured, len(resources))
+	for _, resource := range resources {
+		declaredObjs[core.IDOf(resource)] = resource
+	}
+	pruneBlocked, err := a.checkPruneLimits(ctx, resources)
+	if err != nil {
+		a.addError(err)
+		return nil, a.Errors()
+	}
+	applyObjs, partial := a.partialApplyObjects(resources)
+	retry := false
+	if !partial {
+		applyObjs, retry = a.retryApplyObjects(commit, resources)
+		partial = retry
+	}
+	if pruneBlocked {
+		if a.clientSet.PartialKptApplier == nil {
+			return nil, a.Errors()
+		}
+		// Apply all the declared objects, without pruning the removed ones,
+		// which stay in the inventory until the prune is confirmed.
+		applyObjs, partial, retry = resources, true, false
+	}
+	kptApplier := a.clientSet.KptApplier
+	if partial {
+		kptApplier = a.clientSet.PartialKptApplier
+	}
+	// Remember the resources before they are applied, since the apply
+	// mutators may modify them.
+	appliedObjs := make([]*unstructured.Unstructured, len(resources))
+	for i, resource := range resources {
+		appliedObjs[i] = resource.DeepCopy()
+	}
 
 	unknownTypeResources := make(map[core.ID]struct{})
+	terminatingNamespaces := make(map[string]bool)
 	options := apply.ApplierOptions{
 		ServerSideOptions: common.ServerSideOptions{
 			ServerSideApply: true,
@@ -528,13 +659,16 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		// to be garbage collected as owned resources.
 		// TODO: Switch to "Foreground" after the reconciler-manager finalizer is added.
 		PrunePropagationPolicy: metav1.DeletePropagationBackground,
+		// Partial applies only apply the changed objects, so they must not
+		// prune the others.
+		NoPrune: partial,
 	}
 
 	// Reset shared mapper before each apply to invalidate the discovery cache.
 	// This allows for picking up CRD changes.
 	meta.MaybeResetRESTMapper(a.clientSet.Mapper)
 
-	events := a.clientSet.KptApplier.Run(ctx, a.inventory, object.UnstructuredSet(resources), options)
+	events := kptApplier.Run(ctx, a.inventory, object.UnstructuredSet(applyObjs), options)
 	for e := range events {
 		switch e.Type {
 		case event.InitType:
@@ -567,7 +701,33 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 			} else {
 				klog.V(1).Info(e.ApplyEvent)
 			}
-			a.addError(processApplyEvent(ctx, e.ApplyEvent, s.ApplyEvent, objStatusMap, unknownTypeResources))
+			applyErr := processApplyEvent(ctx, e.ApplyEvent, s.ApplyEvent, objStatusMap, unknownTypeResources)
+			if e.ApplyEvent.Status == event.ApplyFailed && isImmutableFieldError(e.ApplyEvent.Error) {
+				if obj, found := declaredObjs[idFrom(e.ApplyEvent.Identifier)]; found {
+					applyErr = a.handleImmutableFieldChange(ctx, obj, e.ApplyEvent.Error)
+				}
+			}
+			if e.ApplyEvent.Status == event.ApplyFailed && isNamespaceTerminatingError(e.ApplyEvent.Error) {
+				if obj, found := declaredObjs[idFrom(e.ApplyEvent.Identifier)]; found {
+					applyErr = a.handleNamespaceTerminating(obj, terminatingNamespaces)
+				}
+			}
+			a.addError(applyErr)
+			if a.clientSet.PruneObsoleteMetadata && e.ApplyEvent.Status == event.ApplySuccessful {
+				if err := a.pruneObsoleteMetadata(ctx, e.ApplyEvent.Resource); err != nil {
+					a.addError(err)
+				}
+			}
+			if a.clientSet.MigrateClientSideApply && e.ApplyEvent.Status == event.ApplySuccessful {
+				migrated, err := a.migrateClientSideApply(ctx, e.ApplyEvent.Resource)
+				if err != nil {
+					klog.Warningf("Failed to migrate object from client-side apply: %v", err)
+					a.addError(err)
+				}
+				if migrated || err != nil {
+					s.MigrateObjs.Add(migrated)
+				}
+			}
 		case event.PruneType:
 			if e.PruneEvent.Error != nil {
 				klog.Info(e.PruneEvent)
@@ -580,6 +740,8 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		}
 	}
 
+	a.forgetTerminatedNamespaces(terminatingNamespaces)
+
 	gvks := make(map[schema.GroupVersionKind]struct{})
 	for _, resource := range objs {
 		id := core.IDOf(resource)
@@ -593,6 +755,11 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 	if errs == nil {
 		klog.V(4).Infof("Apply completed without error: all resources are up to date.")
 	}
+	// A retry does not prune, so the apply following a successful retry is a
+	// full apply, which prunes the objects removed by the commit.
+	a.recordApply(appliedObjs, errs == nil && !retry)
+	a.recordRetryResults(commit, appliedObjs, errs == nil, objStatusMap)
+	exportObjectStatuses(objs, objStatusMap)
 	if s.Empty() {
 		klog.V(4).Infof("Applier made no new progress")
 	} else {
@@ -630,6 +797,52 @@ func (a *supervisor) invalidateErrors() {
 	defer a.errorMux.Unlock()
 
 	a.errs = nil
+	a.abandoned = nil
+	a.retained = nil
+}
+
+// AbandonedObjects returns the objects which were abandoned during the last
+// apply or current apply if still running.
+// AbandonedObjects implements the Applier interface.
+func (a *supervisor) AbandonedObjects() []client.Object {
+	a.errorMux.RLock()
+	defer a.errorMux.RUnlock()
+
+	// Return a copy to avoid persisting caller modifications
+	return append([]client.Object(nil), a.abandoned...)
+}
+
+func (a *supervisor) addAbandoned(obj client.Object) {
+	a.errorMux.Lock()
+	defer a.errorMux.Unlock()
+
+	a.abandoned = append(a.abandoned, obj)
+}
+
+// RetainedObjects returns the objects which were retained during the last
+// apply or current apply if still running.
+// RetainedObjects implements the Applier interface.
+func (a *supervisor) RetainedObjects() []client.Object {
+	a.errorMux.RLock()
+	defer a.errorMux.RUnlock()
+
+	// Return a copy to avoid persisting caller modifications
+	return append([]client.Object(nil), a.retained...)
+}
+
+// PendingPruneExpiry implements the Applier interface.
+func (a *supervisor) PendingPruneExpiry() time.Time {
+	a.errorMux.RLock()
+	defer a.errorMux.RUnlock()
+
+	return a.pendingPruneExpiry
+}
+
+func (a *supervisor) addRetained(obj client.Object) {
+	a.errorMux.Lock()
+	defer a.errorMux.Unlock()
+
+	a.retained = append(a.retained, obj)
 }
 
 // destroyInner triggers a kpt live destroy library call to destroy a set of resources.
@@ -752,6 +965,18 @@ func InventoryID(name, namespace string) string {
 	return namespace + "_" + name
 }
 
+// InventoryLabelValue returns the value of the owning inventory label of the
+// objects managed by the RootSync or RepoSync. The inventory ID is hashed if
+// it is not a valid label value, e.g. longer than 63 characters.
+func InventoryLabelValue(name, namespace string) string {
+	id := InventoryID(name, namespace)
+	if len(validation.IsValidLabelValue(id)) == 0 {
+		return id
+	}
+	hash := sha256.Sum256([]byte(id))
+	return fmt.Sprintf("%x", hash[:16])
+}
+
 // handleDisabledObjects removes the specified objects from the inventory, and
 // then disables them, one by one, by removing the ConfigSync metadata.
 // Returns the number of objects which are disabled successfully, and any errors
@@ -779,6 +1004,7 @@ func (a *supervisor) handleDisabledObjects(ctx context.Context, rg *live.Invento
 		} else {
 			klog.V(4).Infof("removed the Config Sync metadata from %v (%s: %s)",
 				id, metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)
+			a.addAbandoned(obj)
 			disabledCount++
 		}
 	}
@@ -812,6 +1038,73 @@ func (a *supervisor) removeFromInventory(rg *live.InventoryResourceGroup, objs [
 	return a.clientSet.InvClient.Replace(rg, newObjs, nil, common.DryRunNone)
 }
 
+// handleImmutableFieldChange handles an object which failed to apply, because
+// the declared changes modify its immutable fields. The object is deleted, so
+// that the next apply recreates it, if it opted in with the
+// `configsync.gke.io/recreate-on-immutable-change` annotation or the replace
+// run-once strategy. Run-once objects using the ignore-if-succeeded strategy
+// are left as they are once they succeeded. Otherwise, the object is skipped
+// and reported.
+func (a *supervisor) handleImmutableFieldChange(ctx context.Context, obj *unstructured.Unstructured, applyErr error) status.Error {
+	if ignoreIfSucceeded(obj) {
+		liveObj := &unstructured.Unstructured{}
+		liveObj.SetGroupVersionKind(obj.GroupVersionKind())
+		err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(obj), liveObj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return status.APIServerError(err, "failed to get run-once object", obj)
+		}
+		if err == nil && hasSucceeded(liveObj) {
+			klog.Infof("Skipping changes to immutable fields of succeeded run-once object: %s", core.IDOf(obj))
+			return nil
+		}
+	}
+	if !recreateOnImmutableChange(obj) {
+		return ImmutableFieldError(applyErr, obj)
+	}
+	klog.Infof("Deleting object to recreate it with changes to immutable fields: %s", core.IDOf(obj))
+	uObj := &unstructured.Unstructured{}
+	uObj.SetGroupVersionKind(obj.GroupVersionKind())
+	uObj.SetNamespace(obj.GetNamespace())
+	uObj.SetName(obj.GetName())
+	err := a.clientSet.Client.Delete(ctx, uObj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return DeleteErrorForResource(err, core.IDOf(obj))
+	}
+	return recreatingImmutableFieldError(applyErr, obj)
+}
+
+// pruneObsoleteMetadata removes the annotations and labels that are no longer
+// used by Config Sync from an applied object, so that long-lived clusters
+// don't accumulate metadata set by previous versions.
+func (a *supervisor) pruneObsoleteMetadata(ctx context.Context, obj *unstructured.Unstructured) error {
+	if obj == nil || !metadata.HasObsoleteMetadata(obj) {
+		return nil
+	}
+	// Use minimal before & after objects to simplify DeepCopy and building
+	// the merge patch.
+	fromObj := &unstructured.Unstructured{}
+	fromObj.SetGroupVersionKind(obj.GroupVersionKind())
+	fromObj.SetNamespace(obj.GetNamespace())
+	fromObj.SetName(obj.GetName())
+	fromObj.SetAnnotations(obj.GetAnnotations())
+	fromObj.SetLabels(obj.GetLabels())
+
+	toObj := fromObj.DeepCopy()
+	if !metadata.RemoveObsoleteMetadata(toObj) {
+		return nil
+	}
+	klog.Infof("Removing obsolete Config Sync metadata from object: %s", core.IDOf(obj))
+	// Use merge-patch instead of server-side-apply, because the obsolete
+	// metadata may be owned by other field managers, e.g. when it was set
+	// with client-side apply by previous versions.
+	err := a.clientSet.Client.Patch(ctx, toObj, client.MergeFrom(fromObj),
+		client.FieldOwner(configsync.FieldManager))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ErrorForResource(err, core.IDOf(obj))
+	}
+	return nil
+}
+
 // abandonObject removes ConfigSync labels and annotations from an object,
 // disabling management.
 func (a *supervisor) abandonObject(ctx context.Context, obj client.Object) error {
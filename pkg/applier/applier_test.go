@@ -317,6 +317,7 @@ func TestApply(t *testing.T) {
 			fakeClient := testingfake.NewClient(t, core.Scheme, tc.serverObjs...)
 			cs := &ClientSet{
 				KptApplier: newFakeKptApplier(tc.events),
+				InvClient:  inventory.NewFakeClient(nil),
 				Client:     fakeClient,
 				Mapper:     fakeClient.RESTMapper(),
 				// TODO: Add tests to cover status mode
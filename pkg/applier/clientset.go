@@ -16,15 +16,19 @@ package applier
 
 import (
 	"context"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/pkg/live"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/cmd/util"
+	"kpt.dev/configsync/pkg/core"
 	"sigs.k8s.io/cli-utils/pkg/apply"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -50,6 +54,46 @@ type ClientSet struct {
 	Client       client.Client
 	Mapper       meta.RESTMapper
 	StatusMode   string
+	// PruneObsoleteMetadata enables removing the annotations and labels that
+	// are no longer used by Config Sync from the applied objects.
+	PruneObsoleteMetadata bool
+	// MigrateClientSideApply enables transferring the fields owned by kubectl
+	// client-side apply to Config Sync, and removing the
+	// last-applied-configuration annotation, from the applied objects.
+	MigrateClientSideApply bool
+	// PruneDelay is how long the objects removed from the declared resources
+	// are kept before they are pruned. Zero prunes them immediately.
+	PruneDelay time.Duration
+	// PruneAllowedKinds are the kinds of the objects which are pruned when
+	// they are removed from the declared resources. Empty allows all kinds.
+	PruneAllowedKinds []schema.GroupKind
+	// PruneDeniedKinds are the kinds of the objects which are never pruned.
+	// The objects of these kinds are unmanaged instead.
+	PruneDeniedKinds []schema.GroupKind
+	// PruneMaxPercentage is the largest percentage of the managed objects
+	// which are pruned at once without confirmation. Zero is unlimited.
+	PruneMaxPercentage int
+	// PruneMaxCount is the largest number of the managed objects which are
+	// pruned at once without confirmation. Zero is unlimited.
+	PruneMaxCount int
+	// MaxWorkloadRollouts is the largest number of the managed workloads
+	// which roll out at once. Zero is unlimited.
+	MaxWorkloadRollouts int
+	// MaxNamespaceRollouts is the largest number of the managed workloads of
+	// a namespace which roll out at once. Zero is unlimited.
+	MaxNamespaceRollouts int
+	// PartialApply enables applying only the objects declared in the source
+	// files which changed since the last successful apply, along with their
+	// dependents, instead of all the declared objects.
+	PartialApply bool
+	// PartialKptApplier applies the objects of partial applies. It adds the
+	// objects to the inventory instead of replacing the inventory.
+	PartialKptApplier KptApplier
+	// EmergencyOverrides returns the IDs of the managed objects which the
+	// remediator found under an emergency override active at the given time.
+	// Their manual changes are not reverted by the applies either. Nil if
+	// emergency overrides are not tracked.
+	EmergencyOverrides func(now time.Time) []core.ID
 }
 
 // NewClientSet constructs a new ClientSet.
@@ -71,33 +115,52 @@ func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, s
 		return nil, err
 	}
 
+	// Wait for the kinds with a readiness mapping, e.g. Config Connector
+	// resources, to be Ready.
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	statusWatcher := watcher.NewDefaultStatusWatcher(dynamicClient, mapper)
+	statusWatcher.StatusReader = newStatusReader(mapper)
+
 	applier, err := apply.NewApplierBuilder().
 		WithInventoryClient(invClient).
 		WithFactory(f).
+		WithStatusWatcher(statusWatcher).
 		Build()
 	if err != nil {
 		return nil, err
 	}
 
-	destroyer, err := apply.NewDestroyerBuilder().
-		WithInventoryClient(invClient).
+	partialApplier, err := apply.NewApplierBuilder().
+		WithInventoryClient(&mergingInventoryClient{Client: invClient}).
 		WithFactory(f).
+		WithStatusWatcher(statusWatcher).
 		Build()
 	if err != nil {
 		return nil, err
 	}
 
-	mapper, err := f.ToRESTMapper()
+	destroyer, err := apply.NewDestroyerBuilder().
+		WithInventoryClient(invClient).
+		WithFactory(f).
+		Build()
 	if err != nil {
 		return nil, err
 	}
 
 	return &ClientSet{
-		KptApplier:   applier,
-		KptDestroyer: destroyer,
-		InvClient:    invClient,
-		Client:       c,
-		Mapper:       mapper,
-		StatusMode:   statusMode,
+		KptApplier:        applier,
+		PartialKptApplier: partialApplier,
+		KptDestroyer:      destroyer,
+		InvClient:         invClient,
+		Client:            c,
+		Mapper:            mapper,
+		StatusMode:        statusMode,
 	}, nil
 }
@@ -0,0 +1,172 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CRDPruneBlockedErrorCode is the error code for CustomResourceDefinitions
+// removed from the source of truth, which are not pruned because custom
+// resources of them exist.
+const CRDPruneBlockedErrorCode = "2027"
+
+// CRDPruneBlockedReason is the reason of the event recorded on a
+// CustomResourceDefinition which is not pruned because custom resources of it
+// exist.
+const CRDPruneBlockedReason = "CRDPruneBlocked"
+
+var crdPruneBlockedErrorBuilder = status.NewErrorBuilder(CRDPruneBlockedErrorCode)
+
+// CRDPruneBlockedError indicates that the given CustomResourceDefinition was
+// removed from the source of truth, but is not pruned, because pruning it
+// would delete the existing custom resources of it.
+func CRDPruneBlockedError(crd client.Object, count int) status.Error {
+	return crdPruneBlockedErrorBuilder.
+		Sprintf("refusing to prune the CustomResourceDefinition %q removed from the source of truth, "+
+			"because %d custom resources of it exist and would be deleted with it. "+
+			"Delete the custom resources, declare the CustomResourceDefinition again, "+
+			"or annotate the CustomResourceDefinition with `%s: %s` to prune it anyway",
+			crd.GetName(), count, metadata.AllowCRDPruneKey, metadata.AllowCRDPruneEnabled).
+		BuildWithResources(crd)
+}
+
+// holdCRDPrunes returns the CustomResourceDefinitions in the inventory which
+// are missing from the declared resources, but which still have custom
+// resources. They are applied along with the declared resources, so that they
+// are not pruned, which would delete the custom resources too, e.g. when a
+// CustomResourceDefinition is moved to a directory which is not synced.
+// Each of them is reported with an error and an event, unless it is annotated
+// to allow the prune.
+func (a *supervisor) holdCRDPrunes(ctx context.Context, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, status.MultiError) {
+	declared := make(map[core.ID]struct{}, len(resources))
+	for _, resource := range resources {
+		declared[core.IDOf(resource)] = struct{}{}
+	}
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, Error(err)
+	}
+	var held []*unstructured.Unstructured
+	blocked := make(map[core.ID]int)
+	blockedResources := 0
+	for _, invObj := range invObjs {
+		id := idFrom(invObj)
+		if id.GroupKind != kinds.CustomResourceDefinition() {
+			continue
+		}
+		if _, found := declared[id]; found {
+			continue
+		}
+		liveCRD := &unstructured.Unstructured{}
+		liveCRD.SetGroupVersionKind(kinds.CustomResourceDefinitionV1())
+		if err := a.clientSet.Client.Get(ctx, id.ObjectKey, liveCRD); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, status.APIServerError(err, "failed to get CustomResourceDefinition pending prune", liveCRD)
+		}
+		if liveCRD.GetDeletionTimestamp() != nil ||
+			core.GetAnnotation(liveCRD, metadata.AllowCRDPruneKey) == metadata.AllowCRDPruneEnabled {
+			continue
+		}
+		count, err := a.countCustomResources(ctx, liveCRD)
+		if err != nil {
+			return nil, status.APIServerError(err, "failed to list the custom resources of CustomResourceDefinition pending prune", liveCRD)
+		}
+		if count == 0 {
+			continue
+		}
+		klog.Warningf("Refusing to prune CustomResourceDefinition %s, because %d custom resources of it exist", id, count)
+		a.addError(CRDPruneBlockedError(liveCRD, count))
+		if a.blockedCRDPrunes[id] != count {
+			a.recordCRDPruneBlockedEvent(ctx, liveCRD, count)
+		}
+		blocked[id] = count
+		blockedResources += count
+		held = append(held, sanitizeLiveObject(liveCRD))
+	}
+	a.blockedCRDPrunes = blocked
+	metrics.RecordCRDPruneBlockedResources(ctx, blockedResources)
+	return held, nil
+}
+
+// countCustomResources returns the number of custom resources of the
+// CustomResourceDefinition, in all the namespaces.
+func (a *supervisor) countCustomResources(ctx context.Context, crd *unstructured.Unstructured) (int, error) {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	version := storageVersion(crd)
+	if group == "" || kind == "" || version == "" {
+		return 0, nil
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: group, Version: version, Kind: kind + "List"})
+	if err := a.clientSet.Client.List(ctx, list); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// storageVersion returns the version in which the custom resources of the
+// CustomResourceDefinition are stored.
+func storageVersion(crd *unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if storage, _, _ := unstructured.NestedBool(version, "storage"); storage {
+			name, _, _ := unstructured.NestedString(version, "name")
+			return name
+		}
+	}
+	// CustomResourceDefinitions served as v1beta1 may only set spec.version.
+	name, _, _ := unstructured.NestedString(crd.Object, "spec", "version")
+	return name
+}
+
+// recordCRDPruneBlockedEvent emits a warning event on the
+// CustomResourceDefinition which is not pruned, or whose number of custom
+// resources changed since it was last found not pruned.
+// Failures are logged, but otherwise ignored, because events are best effort.
+func (a *supervisor) recordCRDPruneBlockedEvent(ctx context.Context, crd *unstructured.Unstructured, count int) {
+	message := fmt.Sprintf("Not pruned after removal from the source of truth, because %d custom resources of it exist. "+
+		"Annotate with `%s: %s` to prune it anyway", count, metadata.AllowCRDPruneKey, metadata.AllowCRDPruneEnabled)
+	e := event.New(event.Reference(crd, crd.GroupVersionKind()), corev1.EventTypeWarning, CRDPruneBlockedReason, message,
+		a.syncName, metav1.NewTime(a.now()))
+	if err := a.clientSet.Client.Create(ctx, e); err != nil {
+		klog.Warningf("Failed to record the %s event for %s: %v", CRDPruneBlockedReason, crd.GetName(), err)
+	}
+}
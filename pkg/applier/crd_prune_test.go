@@ -0,0 +1,107 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func anvilCRD(name, kind string, opts ...core.MetaMutator) *unstructured.Unstructured {
+	crd := fake.UnstructuredObject(kinds.CustomResourceDefinitionV1(), append(opts, core.Name(name))...)
+	crd.Object["spec"] = map[string]interface{}{
+		"group": "acme.com",
+		"names": map[string]interface{}{"kind": kind},
+		"versions": []interface{}{
+			map[string]interface{}{"name": "v1beta1", "storage": false},
+			map[string]interface{}{"name": "v1", "storage": true},
+		},
+	}
+	return crd
+}
+
+func TestHoldCRDPrunes(t *testing.T) {
+	anvilGVK := schema.GroupVersionKind{Group: "acme.com", Version: "v1", Kind: "Anvil"}
+	declared := anvilCRD("declareds.acme.com", "Declared")
+	inUse := anvilCRD("anvils.acme.com", "Anvil")
+	unused := anvilCRD("hammers.acme.com", "Hammer")
+	allowed := anvilCRD("anvils2.acme.com", "Anvil", core.Annotation(metadata.AllowCRDPruneKey, metadata.AllowCRDPruneEnabled))
+	anvil1 := fake.UnstructuredObject(anvilGVK, core.Name("anvil-1"), core.Namespace("foo"))
+	anvil2 := fake.UnstructuredObject(anvilGVK, core.Name("anvil-2"), core.Namespace("bar"))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(anvilGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(anvilGVK.GroupVersion().WithKind("AnvilList"), &unstructured.UnstructuredList{})
+	fakeClient := testingfake.NewClient(t, scheme, declared, inUse, unused, allowed, anvil1, anvil2)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{
+			object.UnstructuredToObjMetadata(declared),
+			object.UnstructuredToObjMetadata(inUse),
+			object.UnstructuredToObjMetadata(unused),
+			object.UnstructuredToObjMetadata(allowed),
+		}),
+		Client: fakeClient,
+		Mapper: fakeClient.RESTMapper(),
+	}
+	s, err := NewRootSupervisor(cs, "root-sync", 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+	ctx := context.Background()
+
+	held, errs := a.holdCRDPrunes(ctx, []*unstructured.Unstructured{declared})
+	require.Nil(t, errs)
+	require.Len(t, held, 1)
+	assert.Equal(t, inUse.GetName(), held[0].GetName())
+	assert.Empty(t, held[0].GetResourceVersion())
+
+	// The CustomResourceDefinition is reported with the number of custom
+	// resources which would be deleted.
+	require.Len(t, a.Errors().Errors(), 1)
+	assert.Equal(t, CRDPruneBlockedErrorCode, a.Errors().Errors()[0].(status.Error).Code())
+	assert.Contains(t, a.Errors().Error(), "2 custom resources")
+
+	events := &corev1.EventList{}
+	require.NoError(t, fakeClient.List(ctx, events))
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, CRDPruneBlockedReason, events.Items[0].Reason)
+	assert.Equal(t, inUse.GetName(), events.Items[0].InvolvedObject.Name)
+
+	// The event is not recorded again while the number of custom resources
+	// does not change.
+	_, errs = a.holdCRDPrunes(ctx, []*unstructured.Unstructured{declared})
+	require.Nil(t, errs)
+	require.NoError(t, fakeClient.List(ctx, events))
+	assert.Len(t, events.Items, 1)
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"bytes"
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// lastAppliedConfigAnnotation is set by kubectl client-side apply to compute
+// the fields to remove on the next client-side apply.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// csaFieldManagers are the field managers used by kubectl client-side apply.
+var csaFieldManagers = map[string]bool{
+	"kubectl-client-side-apply": true,
+	"kubectl-last-applied":      true,
+}
+
+// migrateClientSideApply converts an object previously managed with kubectl
+// client-side apply into an object managed by Config Sync with server-side
+// apply. The fields owned by client-side apply are transferred to Config
+// Sync, so that the fields which are no longer declared are removed by the
+// next apply, and the stale last-applied-configuration annotation is removed,
+// so that a later client-side apply does not fight with Config Sync.
+// Returns true if the object was migrated.
+func (a *supervisor) migrateClientSideApply(ctx context.Context, obj *unstructured.Unstructured) (bool, status.Error) {
+	if obj == nil {
+		return false, nil
+	}
+	liveObj := &unstructured.Unstructured{}
+	liveObj.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(obj), liveObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, status.APIServerError(err, "failed to get object to migrate from client-side apply", obj)
+	}
+
+	// Use minimal before & after objects to simplify DeepCopy and building
+	// the merge patch.
+	fromObj := &unstructured.Unstructured{}
+	fromObj.SetGroupVersionKind(liveObj.GroupVersionKind())
+	fromObj.SetNamespace(liveObj.GetNamespace())
+	fromObj.SetName(liveObj.GetName())
+	fromObj.SetResourceVersion(liveObj.GetResourceVersion())
+	fromObj.SetAnnotations(liveObj.GetAnnotations())
+	fromObj.SetManagedFields(liveObj.GetManagedFields())
+
+	toObj := fromObj.DeepCopy()
+	migrated, err := migrateManagedFields(toObj)
+	if err != nil {
+		return false, ErrorForResource(err, core.IDOf(obj))
+	}
+	if !migrated {
+		return false, nil
+	}
+	klog.Infof("Migrating object from client-side apply to Config Sync server-side apply: %s", core.IDOf(obj))
+	// The resource version makes the patch fail if the object changed since
+	// it was read, instead of overwriting the field ownership.
+	err = a.clientSet.Client.Patch(ctx, toObj, client.MergeFromWithOptions(fromObj, client.MergeFromWithOptimisticLock{}),
+		client.FieldOwner(configsync.FieldManager))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, ErrorForResource(err, core.IDOf(obj))
+	}
+	return true, nil
+}
+
+// migrateManagedFields removes the last-applied-configuration annotation
+// from the object, and merges the fields owned by the client-side apply
+// field managers into the fields owned by Config Sync. Returns true if the
+// object was modified.
+func migrateManagedFields(obj *unstructured.Unstructured) (bool, error) {
+	migrated := false
+	annotations := obj.GetAnnotations()
+	if _, found := annotations[lastAppliedConfigAnnotation]; found {
+		delete(annotations, lastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+		migrated = true
+	}
+
+	managedFields := obj.GetManagedFields()
+	ssaIndex := -1
+	for i, entry := range managedFields {
+		if entry.Manager == configsync.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			ssaIndex = i
+			break
+		}
+	}
+	if ssaIndex < 0 {
+		// The object was not applied by Config Sync yet, so there is no owner
+		// to transfer the fields to.
+		return migrated, nil
+	}
+	ssaFields, err := fieldSet(managedFields[ssaIndex].FieldsV1)
+	if err != nil {
+		return false, err
+	}
+
+	var result []metav1.ManagedFieldsEntry
+	csaFound := false
+	for _, entry := range managedFields {
+		if entry.Operation != metav1.ManagedFieldsOperationUpdate || !csaFieldManagers[entry.Manager] {
+			result = append(result, entry)
+			continue
+		}
+		csaFound = true
+		// Fields owned with a different API version have different paths.
+		// They are released instead of transferred.
+		if entry.APIVersion != managedFields[ssaIndex].APIVersion {
+			continue
+		}
+		csaFields, err := fieldSet(entry.FieldsV1)
+		if err != nil {
+			return false, err
+		}
+		ssaFields = ssaFields.Union(csaFields)
+	}
+	if !csaFound {
+		return migrated, nil
+	}
+
+	raw, err := ssaFields.ToJSON()
+	if err != nil {
+		return false, err
+	}
+	for i := range result {
+		if result[i].Manager == configsync.FieldManager && result[i].Operation == metav1.ManagedFieldsOperationApply {
+			result[i].FieldsV1 = &metav1.FieldsV1{Raw: raw}
+		}
+	}
+	obj.SetManagedFields(result)
+	return true, nil
+}
+
+// fieldSet parses the fields of a managed fields entry.
+func fieldSet(fields *metav1.FieldsV1) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if fields == nil || len(fields.Raw) == 0 {
+		return set, nil
+	}
+	if err := set.FromJSON(bytes.NewReader(fields.Raw)); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestMigrateManagedFields(t *testing.T) {
+	ssaEntry := metav1.ManagedFieldsEntry{
+		Manager:    configsync.FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:declared":{}}}`)},
+	}
+	csaEntry := metav1.ManagedFieldsEntry{
+		Manager:    "kubectl-client-side-apply",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:removed":{}}}`)},
+	}
+	otherEntry := metav1.ManagedFieldsEntry{
+		Manager:    "kube-controller-manager",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:other":{}}}`)},
+	}
+
+	testCases := []struct {
+		name              string
+		annotations       map[string]string
+		managedFields     []metav1.ManagedFieldsEntry
+		wantMigrated      bool
+		wantManagedFields []metav1.ManagedFieldsEntry
+	}{
+		{
+			name:        "client-side applied object",
+			annotations: map[string]string{lastAppliedConfigAnnotation: "{}", "foo": "bar"},
+			managedFields: []metav1.ManagedFieldsEntry{
+				csaEntry, otherEntry, ssaEntry,
+			},
+			wantMigrated: true,
+			wantManagedFields: []metav1.ManagedFieldsEntry{
+				otherEntry,
+				{
+					Manager:    configsync.FieldManager,
+					Operation:  metav1.ManagedFieldsOperationApply,
+					APIVersion: "v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:declared":{},"f:removed":{}}}`)},
+				},
+			},
+		},
+		{
+			name:        "stale annotation only",
+			annotations: map[string]string{lastAppliedConfigAnnotation: "{}"},
+			managedFields: []metav1.ManagedFieldsEntry{
+				ssaEntry,
+			},
+			wantMigrated:      true,
+			wantManagedFields: []metav1.ManagedFieldsEntry{ssaEntry},
+		},
+		{
+			name:              "not applied by Config Sync yet",
+			managedFields:     []metav1.ManagedFieldsEntry{csaEntry},
+			wantManagedFields: []metav1.ManagedFieldsEntry{csaEntry},
+		},
+		{
+			name:              "already migrated",
+			managedFields:     []metav1.ManagedFieldsEntry{otherEntry, ssaEntry},
+			wantManagedFields: []metav1.ManagedFieldsEntry{otherEntry, ssaEntry},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("cm"), core.Namespace("default"))
+			obj.SetAnnotations(tc.annotations)
+			obj.SetManagedFields(tc.managedFields)
+
+			migrated, err := migrateManagedFields(obj)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMigrated, migrated)
+			assert.NotContains(t, obj.GetAnnotations(), lastAppliedConfigAnnotation)
+			assert.Equal(t, tc.wantManagedFields, obj.GetManagedFields())
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectDiff is a change that applying the declared resources would make to
+// an object.
+type ObjectDiff struct {
+	// Action is the change to the object: v1beta1.DiffActionAdd,
+	// v1beta1.DiffActionChange or v1beta1.DiffActionPrune.
+	Action string
+	// GVK is the GroupVersionKind of the object. The version of a pruned
+	// object is empty if its type is no longer served.
+	GVK schema.GroupVersionKind
+	// Namespace is the namespace of the object.
+	Namespace string
+	// Name is the name of the object.
+	Name string
+	// SourcePath is the path of the declared object in the source of truth.
+	// Empty for pruned objects.
+	SourcePath string
+	// Target is the name of the target cluster of the object, if the
+	// resources are applied by a MultiTargetSupervisor.
+	Target string
+}
+
+// DryRunResult is the outcome of a dry-run of an apply.
+type DryRunResult struct {
+	// Diffs are the objects which the apply would add, change or prune,
+	// sorted by action and object.
+	Diffs []ObjectDiff
+	// Unchanged is the number of declared objects which the apply would not
+	// change.
+	Unchanged int
+}
+
+// dryRunIgnoredAnnotations are the annotations which differ between the
+// commits even if the declared fields of an object do not, so a difference in
+// them alone is not reported as a change.
+var dryRunIgnoredAnnotations = []string{
+	metadata.SyncTokenAnnotationKey,
+	metadata.DeclaredFieldsKey,
+}
+
+// DryRun implements Applier. The declared objects which exist are applied
+// with server-side dry-run applies, and compared with the live objects. The
+// objects in the inventory which are no longer declared are reported as
+// prunes, unless their kind cannot be pruned. Nothing is mutated, including
+// the inventory.
+func (a *supervisor) DryRun(ctx context.Context, desiredResources []client.Object) (*DryRunResult, status.MultiError) {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+
+	// The objects whose management is disabled are unmanaged instead of
+	// pruned, so they are neither applied nor pruned.
+	enabledObjs, disabledObjs := partitionObjs(desiredResources)
+	resources, errs := toUnstructured(enabledObjs)
+	if errs != nil {
+		return nil, errs
+	}
+	result := &DryRunResult{}
+	declared := make(map[core.ID]struct{}, len(desiredResources))
+	for _, obj := range disabledObjs {
+		declared[core.IDOf(obj)] = struct{}{}
+	}
+	for _, resource := range resources {
+		declared[core.IDOf(resource)] = struct{}{}
+		action, err := a.dryRunApply(ctx, resource)
+		if err != nil {
+			errs = status.Append(errs, err)
+			continue
+		}
+		if action == "" {
+			result.Unchanged++
+			continue
+		}
+		result.Diffs = append(result.Diffs, ObjectDiff{
+			Action:     action,
+			GVK:        resource.GroupVersionKind(),
+			Namespace:  resource.GetNamespace(),
+			Name:       resource.GetName(),
+			SourcePath: core.GetAnnotation(resource, metadata.SourcePathAnnotationKey),
+		})
+	}
+
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, status.Append(errs, Error(err))
+	}
+	for _, invObj := range invObjs {
+		id := idFrom(invObj)
+		if _, found := declared[id]; found || !a.clientSet.canPrune(id.GroupKind) {
+			continue
+		}
+		gvk := id.GroupKind.WithVersion("")
+		if mapping, err := a.clientSet.Mapper.RESTMapping(id.GroupKind); err == nil {
+			gvk = mapping.GroupVersionKind
+		}
+		result.Diffs = append(result.Diffs, ObjectDiff{
+			Action:    v1beta1.DiffActionPrune,
+			GVK:       gvk,
+			Namespace: id.Namespace,
+			Name:      id.Name,
+		})
+	}
+	sortObjectDiffs(result.Diffs)
+	klog.Infof("Dry-run of %d declared objects: %d objects to add, change or prune, %d unchanged",
+		len(resources), len(result.Diffs), result.Unchanged)
+	return result, errs
+}
+
+// dryRunApply returns the change that applying the object would make:
+// v1beta1.DiffActionAdd if it does not exist, v1beta1.DiffActionChange if the
+// server-side dry-run apply changes it, or "" if it is unchanged.
+func (a *supervisor) dryRunApply(ctx context.Context, obj *unstructured.Unstructured) (string, status.Error) {
+	liveObj := &unstructured.Unstructured{}
+	liveObj.SetGroupVersionKind(obj.GroupVersionKind())
+	err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(obj), liveObj)
+	switch {
+	case apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+		// The type may not exist yet, e.g. if the commit declares its CRD.
+		return v1beta1.DiffActionAdd, nil
+	case err != nil:
+		return "", ErrorForResource(err, core.IDOf(obj))
+	}
+	appliedObj := obj.DeepCopy()
+	err = a.clientSet.Client.Patch(ctx, appliedObj, client.Apply, client.DryRunAll,
+		client.FieldOwner(configsync.FieldManager), client.ForceOwnership)
+	if err != nil {
+		return "", ErrorForResource(err, core.IDOf(obj))
+	}
+	if equality.Semantic.DeepEqual(comparableObject(liveObj), comparableObject(appliedObj)) {
+		return "", nil
+	}
+	return v1beta1.DiffActionChange, nil
+}
+
+// comparableObject returns a copy of the object without the fields which the
+// server updates on every apply, or which differ between the commits.
+func comparableObject(obj *unstructured.Unstructured) map[string]interface{} {
+	u := obj.DeepCopy()
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+	u.SetGeneration(0)
+	annotations := u.GetAnnotations()
+	for _, key := range dryRunIgnoredAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	u.SetAnnotations(annotations)
+	return u.Object
+}
+
+// sortObjectDiffs sorts the diffs by action, target and object.
+func sortObjectDiffs(diffs []ObjectDiff) {
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Action != diffs[j].Action {
+			return diffs[i].Action < diffs[j].Action
+		}
+		if diffs[i].Target != diffs[j].Target {
+			return diffs[i].Target < diffs[j].Target
+		}
+		return diffKey(diffs[i]) < diffKey(diffs[j])
+	})
+}
+
+func diffKey(diff ObjectDiff) string {
+	return core.ID{
+		GroupKind: diff.GVK.GroupKind(),
+		ObjectKey: client.ObjectKey{Namespace: diff.Namespace, Name: diff.Name},
+	}.String()
+}
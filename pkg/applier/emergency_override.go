@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// holdEmergencyOverrides replaces the declared objects under an active
+// emergency override with their live values, so that applying them does not
+// revert the manual changes before the override expires. The remediator
+// reverts them once it expires.
+//
+// Only the objects under an emergency override tracked by the remediator are
+// read from the cluster, except on the first Apply, which reads all the
+// declared objects, so that the overrides are not reverted after a restart.
+func (a *supervisor) holdEmergencyOverrides(ctx context.Context, resources []*unstructured.Unstructured) status.MultiError {
+	if a.clientSet.EmergencyOverrides == nil {
+		return nil
+	}
+	now := a.now()
+	checkAll := !a.emergencyOverridesChecked
+	ids := a.clientSet.EmergencyOverrides(now)
+	if len(ids) == 0 && !checkAll {
+		return nil
+	}
+	overridden := make(map[core.ID]bool, len(ids))
+	for _, id := range ids {
+		overridden[id] = true
+	}
+	for i, resource := range resources {
+		if !checkAll && !overridden[core.IDOf(resource)] {
+			continue
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(resource.GroupVersionKind())
+		if err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(resource), live); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return status.APIServerError(err, "failed to get the object under an emergency override", resource)
+		}
+		if !metadata.EmergencyOverrideActive(live, now) {
+			continue
+		}
+		klog.Infof("Holding back the update of %s under an emergency override until %s",
+			core.IDOf(resource), live.GetAnnotations()[metadata.EmergencyOverrideUntilKey])
+		resources[i] = overriddenObject(resource, live)
+	}
+	a.emergencyOverridesChecked = true
+	return nil
+}
+
+// overriddenObject returns the declared object with the live values of its
+// declared fields. The declared metadata is kept, since the Config Sync
+// metadata must still be updated.
+func overriddenObject(resource, live *unstructured.Unstructured) *unstructured.Unstructured {
+	held := resource.DeepCopy()
+	for field, value := range resource.Object {
+		switch field {
+		case "apiVersion", "kind", "metadata":
+			continue
+		}
+		liveValue, found := live.Object[field]
+		if !found {
+			delete(held.Object, field)
+			continue
+		}
+		held.Object[field] = liveValues(value, liveValue)
+	}
+	return held
+}
+
+// liveValues returns the live values of the fields of the declared value.
+// The declared fields which were removed from the live value are omitted.
+func liveValues(declared, live interface{}) interface{} {
+	declaredMap, ok := declared.(map[string]interface{})
+	liveMap, liveOK := live.(map[string]interface{})
+	if !ok || !liveOK {
+		return runtime.DeepCopyJSONValue(live)
+	}
+	result := make(map[string]interface{}, len(declaredMap))
+	for key, value := range declaredMap {
+		if liveValue, found := liveMap[key]; found {
+			result[key] = liveValues(value, liveValue)
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHoldEmergencyOverrides(t *testing.T) {
+	now := time.Now()
+	configMap := func(name, value string, opts ...core.MetaMutator) *unstructured.Unstructured {
+		u := fake.UnstructuredObject(kinds.ConfigMap(), append(opts, core.Name(name), core.Namespace("foo"))...)
+		require.NoError(t, unstructured.SetNestedField(u.Object, value, "data", "key"))
+		return u
+	}
+	active := core.Annotation(metadata.EmergencyOverrideUntilKey, now.Add(time.Hour).Format(time.RFC3339))
+	expired := core.Annotation(metadata.EmergencyOverrideUntilKey, now.Add(-time.Hour).Format(time.RFC3339))
+
+	live := []client.Object{
+		configMap("overridden", "manual", active),
+		configMap("expired", "manual", expired),
+		configMap("other", "manual"),
+	}
+	fakeClient := testingfake.NewClient(t, core.Scheme, live...)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient:  inventory.NewFakeClient(object.ObjMetadataSet{}),
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+		EmergencyOverrides: func(time.Time) []core.ID {
+			// The remediator has not yet observed that the override of the
+			// expired object expired.
+			return []core.ID{core.IDOf(live[0]), core.IDOf(live[1])}
+		},
+	}
+	s, err := NewRootSupervisor(cs, configsync.RootSyncName, 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+	a.now = func() time.Time { return now }
+
+	resources := []*unstructured.Unstructured{
+		configMap("overridden", "declared", core.Label("declared", "true")),
+		configMap("expired", "declared"),
+		configMap("other", "declared"),
+	}
+	require.Nil(t, a.holdEmergencyOverrides(context.Background(), resources))
+
+	want := map[string]string{"overridden": "manual", "expired": "declared", "other": "declared"}
+	for _, resource := range resources {
+		value, _, err := unstructured.NestedString(resource.Object, "data", "key")
+		require.NoError(t, err)
+		assert.Equal(t, want[resource.GetName()], value, resource.GetName())
+	}
+	assert.Equal(t, "true", resources[0].GetLabels()["declared"], "the declared metadata is kept")
+	_, found := resources[0].GetAnnotations()[metadata.EmergencyOverrideUntilKey]
+	assert.False(t, found, "the emergency override annotation is not applied")
+}
+
+func TestHoldEmergencyOverrides_Restart(t *testing.T) {
+	now := time.Now()
+	configMap := func(name, value string, opts ...core.MetaMutator) *unstructured.Unstructured {
+		u := fake.UnstructuredObject(kinds.ConfigMap(), append(opts, core.Name(name), core.Namespace("foo"))...)
+		require.NoError(t, unstructured.SetNestedField(u.Object, value, "data", "key"))
+		return u
+	}
+	active := core.Annotation(metadata.EmergencyOverrideUntilKey, now.Add(time.Hour).Format(time.RFC3339))
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, configMap("overridden", "manual", active))
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient:  inventory.NewFakeClient(object.ObjMetadataSet{}),
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+		EmergencyOverrides: func(time.Time) []core.ID {
+			// The remediator has not yet watched the objects since the restart.
+			return nil
+		},
+	}
+	s, err := NewRootSupervisor(cs, configsync.RootSyncName, 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+	a.now = func() time.Time { return now }
+
+	// The first Apply finds the override on the cluster.
+	resources := []*unstructured.Unstructured{configMap("overridden", "declared")}
+	require.Nil(t, a.holdEmergencyOverrides(context.Background(), resources))
+	value, _, err := unstructured.NestedString(resources[0].Object, "data", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "manual", value)
+
+	// The next Applies rely on the overrides tracked by the remediator.
+	resources = []*unstructured.Unstructured{configMap("overridden", "declared")}
+	require.Nil(t, a.holdEmergencyOverrides(context.Background(), resources))
+	value, _, err = unstructured.NestedString(resources[0].Object, "data", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "declared", value)
+}
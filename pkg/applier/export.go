@@ -0,0 +1,66 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"sort"
+	"time"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/export"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// exportObjectStatuses exports the records of the objects actuated by an
+// apply, with the commit of the applied objects.
+func exportObjectStatuses(objs []client.Object, m ObjectStatusMap) {
+	if !export.Enabled() || len(m) == 0 {
+		return
+	}
+	commit := ""
+	for _, obj := range objs {
+		if commit = core.GetAnnotation(obj, metadata.SyncTokenAnnotationKey); commit != "" {
+			break
+		}
+	}
+	ids := make([]core.ID, 0, len(m))
+	for id, objStatus := range m {
+		if objStatus != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	now := time.Now()
+	records := make([]export.AppliedObject, 0, len(ids))
+	for _, id := range ids {
+		objStatus := m[id]
+		records = append(records, export.AppliedObject{
+			Time:      now,
+			Commit:    commit,
+			Group:     id.Group,
+			Kind:      id.Kind,
+			Namespace: id.Namespace,
+			Name:      id.Name,
+			Strategy:  objStatus.Strategy.String(),
+			Actuation: objStatus.Actuation.String(),
+			Reconcile: objStatus.Reconcile.String(),
+		})
+	}
+	export.RecordAppliedObjects(records)
+}
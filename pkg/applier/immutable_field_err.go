@@ -0,0 +1,113 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImmutableFieldErrorCode is the error code for apply failures caused by
+// changes to immutable fields.
+const ImmutableFieldErrorCode = "2018"
+
+var immutableFieldErrorBuilder = status.NewErrorBuilder(ImmutableFieldErrorCode)
+
+// ImmutableFieldError indicates that the applier failed to apply the given
+// resource, because the declared changes modify its immutable fields.
+func ImmutableFieldError(err error, resource client.Object) status.Error {
+	return immutableFieldErrorBuilder.
+		Wrap(fmt.Errorf("failed to apply %v, because the declared changes modify immutable fields. "+
+			"To apply the changes, delete the object, or annotate it with `%s: %q` to let Config Sync recreate it: %w",
+			core.IDOf(resource), metadata.RecreateOnImmutableChangeKey, metadata.RecreateOnImmutableChangeEnabled, err)).
+		BuildWithResources(resource)
+}
+
+// recreatingImmutableFieldError indicates that the given resource was deleted,
+// because the declared changes modify its immutable fields, and that it will be
+// recreated by the next apply.
+func recreatingImmutableFieldError(err error, resource client.Object) status.Error {
+	return immutableFieldErrorBuilder.
+		Wrap(fmt.Errorf("failed to apply %v, because the declared changes modify immutable fields. "+
+			"The object was deleted, and will be recreated by the next apply: %w", core.IDOf(resource), err)).
+		BuildWithResources(resource)
+}
+
+// isImmutableFieldError returns true if the error is returned by the API
+// server for a change which modifies an immutable field.
+func isImmutableFieldError(err error) bool {
+	if err == nil || !apierrors.IsInvalid(err) {
+		return false
+	}
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return false
+	}
+	for _, cause := range details.Causes {
+		if strings.Contains(cause.Message, "immutable") || strings.Contains(cause.Message, "may not change once set") {
+			return true
+		}
+	}
+	return false
+}
+
+// recreateOnImmutableChange returns true if the declared object opted in to
+// being recreated when the declared changes modify its immutable fields,
+// either directly or with the replace run-once strategy.
+func recreateOnImmutableChange(obj client.Object) bool {
+	return core.GetAnnotation(obj, metadata.RecreateOnImmutableChangeKey) == metadata.RecreateOnImmutableChangeEnabled ||
+		core.GetAnnotation(obj, metadata.RunOnceStrategyKey) == metadata.RunOnceStrategyReplace
+}
+
+// ignoreIfSucceeded returns true if the declared object uses the
+// ignore-if-succeeded run-once strategy.
+func ignoreIfSucceeded(obj client.Object) bool {
+	return core.GetAnnotation(obj, metadata.RunOnceStrategyKey) == metadata.RunOnceStrategyIgnoreIfSucceeded
+}
+
+// hasSucceeded returns true if the live run-once object reports that it ran to
+// completion, e.g. a Job with succeeded Pods or a Complete condition, or a Pod
+// in the Succeeded phase.
+func hasSucceeded(obj *unstructured.Unstructured) bool {
+	if succeeded, found, _ := unstructured.NestedInt64(obj.Object, "status", "succeeded"); found && succeeded > 0 {
+		return true
+	}
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase == "Succeeded" {
+		return true
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Complete" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func immutableClusterIPError() error {
+	return apierrors.NewInvalid(kinds.Service().GroupKind(), "my-svc", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "clusterIP"), "10.0.0.2", "field is immutable"),
+	})
+}
+
+func TestIsImmutableFieldError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "immutable field",
+			err:  immutableClusterIPError(),
+			want: true,
+		},
+		{
+			name: "wrapped immutable field",
+			err:  fmt.Errorf("failed to apply: %w", immutableClusterIPError()),
+			want: true,
+		},
+		{
+			name: "other invalid field",
+			err: apierrors.NewInvalid(kinds.Service().GroupKind(), "my-svc", field.ErrorList{
+				field.Required(field.NewPath("spec", "ports"), ""),
+			}),
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("field is immutable"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, isImmutableFieldError(tc.err))
+		})
+	}
+}
+
+func TestHandleImmutableFieldChange(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations []core.MetaMutator
+		wantDeleted bool
+	}{
+		{
+			name: "skip and report by default",
+		},
+		{
+			name:        "recreate if annotated",
+			annotations: []core.MetaMutator{core.Annotation(metadata.RecreateOnImmutableChangeKey, metadata.RecreateOnImmutableChangeEnabled)},
+			wantDeleted: true,
+		},
+		{
+			name:        "recreate with replace run-once strategy",
+			annotations: []core.MetaMutator{core.Annotation(metadata.RunOnceStrategyKey, metadata.RunOnceStrategyReplace)},
+			wantDeleted: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]core.MetaMutator{core.Name("my-svc"), core.Namespace("bookstore")}, tc.annotations...)
+			obj := fake.UnstructuredObject(kinds.Service(), opts...)
+			fakeClient := testingfake.NewClient(t, core.Scheme, obj.DeepCopy())
+			a := &supervisor{clientSet: &ClientSet{Client: fakeClient}}
+
+			err := a.handleImmutableFieldChange(context.Background(), obj, immutableClusterIPError())
+			require.Error(t, err)
+			require.Equal(t, ImmutableFieldErrorCode, err.Code())
+
+			live := &unstructured.Unstructured{}
+			live.SetGroupVersionKind(kinds.Service())
+			getErr := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), live)
+			require.Equal(t, tc.wantDeleted, apierrors.IsNotFound(getErr))
+		})
+	}
+}
+
+func TestHandleImmutableFieldChangeIgnoreIfSucceeded(t *testing.T) {
+	testCases := []struct {
+		name      string
+		succeeded int64
+		wantErr   bool
+	}{
+		{
+			name:      "ignore succeeded Job",
+			succeeded: 1,
+		},
+		{
+			name:    "report Job which has not succeeded",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := fake.UnstructuredObject(kinds.Job(), core.Name("migrate"), core.Namespace("bookstore"),
+				core.Annotation(metadata.RunOnceStrategyKey, metadata.RunOnceStrategyIgnoreIfSucceeded))
+			live := obj.DeepCopy()
+			require.NoError(t, unstructured.SetNestedField(live.Object, tc.succeeded, "status", "succeeded"))
+			fakeClient := testingfake.NewClient(t, core.Scheme, live)
+			a := &supervisor{clientSet: &ClientSet{Client: fakeClient}}
+
+			err := a.handleImmutableFieldChange(context.Background(), obj, immutableClusterIPError())
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, ImmutableFieldErrorCode, err.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Target is one of the clusters that a MultiTargetSupervisor fans the
+// resources out to.
+type Target struct {
+	// Name identifies the target in the status.
+	Name string
+	// Supervisor applies and destroys the resources on the target cluster,
+	// tracking them in an inventory on that cluster.
+	Supervisor Supervisor
+	// Variables are substituted for the `${NAME}` references in the string
+	// fields of the resources applied to the target.
+	Variables map[string]string
+}
+
+// TargetResult is the outcome of the last Apply, DryRun or Destroy on a target.
+type TargetResult struct {
+	// Name is the name of the target.
+	Name string
+	// Errors are the errors of the last Apply, DryRun or Destroy on the target.
+	Errors status.MultiError
+	// LastUpdate is when the last Apply, DryRun or Destroy on the target finished.
+	LastUpdate time.Time
+}
+
+// MultiTargetSupervisor is a Supervisor that applies the same resources to
+// multiple target clusters, with per-target variable substitution.
+type MultiTargetSupervisor struct {
+	targets []Target
+	// now returns the current time. Overridden in tests.
+	now func() time.Time
+
+	mux     sync.RWMutex
+	results map[string]TargetResult
+}
+
+var _ Supervisor = &MultiTargetSupervisor{}
+
+// NewMultiTargetSupervisor returns a Supervisor that fans out to the targets.
+func NewMultiTargetSupervisor(targets []Target) *MultiTargetSupervisor {
+	return &MultiTargetSupervisor{
+		targets: targets,
+		now:     time.Now,
+		results: make(map[string]TargetResult),
+	}
+}
+
+// Apply implements Applier. The targets are applied concurrently. The
+// returned GVKs are the union of the GVKs applied to each target.
+func (m *MultiTargetSupervisor) Apply(ctx context.Context, desiredResources []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+	gvks := make(map[schema.GroupVersionKind]struct{})
+	var errs status.MultiError
+	var resultMux sync.Mutex
+	m.forEachTarget(func(target Target) status.MultiError {
+		objs, err := substituteVariables(desiredResources, target.Variables)
+		if err != nil {
+			return err
+		}
+		targetGVKs, err := target.Supervisor.Apply(ctx, objs)
+		resultMux.Lock()
+		defer resultMux.Unlock()
+		for gvk := range targetGVKs {
+			gvks[gvk] = struct{}{}
+		}
+		return err
+	}, &errs)
+	return gvks, errs
+}
+
+// Destroy implements Destroyer. The targets are destroyed concurrently.
+func (m *MultiTargetSupervisor) Destroy(ctx context.Context) status.MultiError {
+	var errs status.MultiError
+	m.forEachTarget(func(target Target) status.MultiError {
+		return target.Supervisor.Destroy(ctx)
+	}, &errs)
+	return errs
+}
+
+// Errors implements Applier and Destroyer.
+func (m *MultiTargetSupervisor) Errors() status.MultiError {
+	var errs status.MultiError
+	for _, target := range m.targets {
+		errs = status.Append(errs, target.Supervisor.Errors())
+	}
+	return errs
+}
+
+// RequestFullApply implements Applier.
+func (m *MultiTargetSupervisor) RequestFullApply() {
+	for _, target := range m.targets {
+		target.Supervisor.RequestFullApply()
+	}
+}
+
+// DryRun implements Applier. The targets are previewed concurrently, and the
+// diffs of each target are reported with its name.
+func (m *MultiTargetSupervisor) DryRun(ctx context.Context, desiredResources []client.Object) (*DryRunResult, status.MultiError) {
+	result := &DryRunResult{}
+	var errs status.MultiError
+	var resultMux sync.Mutex
+	m.forEachTarget(func(target Target) status.MultiError {
+		objs, err := substituteVariables(desiredResources, target.Variables)
+		if err != nil {
+			return err
+		}
+		targetResult, err := target.Supervisor.DryRun(ctx, objs)
+		if targetResult == nil {
+			return err
+		}
+		resultMux.Lock()
+		defer resultMux.Unlock()
+		for _, diff := range targetResult.Diffs {
+			diff.Target = target.Name
+			result.Diffs = append(result.Diffs, diff)
+		}
+		result.Unchanged += targetResult.Unchanged
+		return err
+	}, &errs)
+	sortObjectDiffs(result.Diffs)
+	return result, errs
+}
+
+// AbandonedObjects implements Applier. An object abandoned on several
+// targets is only returned once.
+func (m *MultiTargetSupervisor) AbandonedObjects() []client.Object {
+	return m.uniqueObjects(Supervisor.AbandonedObjects)
+}
+
+// RetainedObjects implements Applier. An object retained on several targets
+// is only returned once.
+func (m *MultiTargetSupervisor) RetainedObjects() []client.Object {
+	return m.uniqueObjects(Supervisor.RetainedObjects)
+}
+
+// PendingPruneExpiry implements Applier. It returns the first expiry of all
+// the targets.
+func (m *MultiTargetSupervisor) PendingPruneExpiry() time.Time {
+	var expiry time.Time
+	for _, target := range m.targets {
+		targetExpiry := target.Supervisor.PendingPruneExpiry()
+		if !targetExpiry.IsZero() && (expiry.IsZero() || targetExpiry.Before(expiry)) {
+			expiry = targetExpiry
+		}
+	}
+	return expiry
+}
+
+// uniqueObjects returns the objects returned by objects for every target,
+// without duplicates.
+func (m *MultiTargetSupervisor) uniqueObjects(objects func(Supervisor) []client.Object) []client.Object {
+	var result []client.Object
+	seen := make(map[core.ID]struct{})
+	for _, target := range m.targets {
+		for _, obj := range objects(target.Supervisor) {
+			id := core.IDOf(obj)
+			if _, found := seen[id]; found {
+				continue
+			}
+			seen[id] = struct{}{}
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// TargetResults returns the outcome of the last Apply or Destroy on each
+// target, in the order of the targets. Targets which have not been applied
+// yet are omitted.
+func (m *MultiTargetSupervisor) TargetResults() []TargetResult {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	var results []TargetResult
+	for _, target := range m.targets {
+		if result, found := m.results[target.Name]; found {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// forEachTarget runs fn concurrently for every target, records the result of
+// each target, and appends all the errors to errs.
+func (m *MultiTargetSupervisor) forEachTarget(fn func(Target) status.MultiError, errs *status.MultiError) {
+	targetErrs := make([]status.MultiError, len(m.targets))
+	var wg sync.WaitGroup
+	for i, target := range m.targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			targetErrs[i] = fn(target)
+			m.mux.Lock()
+			defer m.mux.Unlock()
+			m.results[target.Name] = TargetResult{
+				Name:       target.Name,
+				Errors:     targetErrs[i],
+				LastUpdate: m.now(),
+			}
+		}(i, target)
+	}
+	wg.Wait()
+	for _, err := range targetErrs {
+		*errs = status.Append(*errs, err)
+	}
+}
+
+// variableRegexp matches the `${NAME}` variable references.
+var variableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substituteVariables returns copies of the objects with the `${NAME}`
+// references in their string fields replaced by the variable values.
+// References to undefined variables are left as is.
+//
+// The objects are shared by the targets, which are applied concurrently, so
+// every target gets its own copies even when it has no variables.
+func substituteVariables(objs []client.Object, variables map[string]string) ([]client.Object, status.MultiError) {
+	result := make([]client.Object, 0, len(objs))
+	if len(variables) == 0 {
+		for _, obj := range objs {
+			result = append(result, obj.DeepCopyObject().(client.Object))
+		}
+		return result, nil
+	}
+	var errs status.MultiError
+	for _, obj := range objs {
+		// ToUnstructured sets the GVK of the object it converts, so convert a
+		// copy.
+		u, err := kinds.ToUnstructured(obj.DeepCopyObject(), core.Scheme)
+		if err != nil {
+			errs = status.Append(errs, status.InternalErrorBuilder.Wrap(err).BuildWithResources(obj))
+			continue
+		}
+		u.Object = substituteValue(u.Object, variables).(map[string]interface{})
+		result = append(result, u)
+	}
+	return result, errs
+}
+
+// substituteValue replaces the variable references in the strings of an
+// unstructured value.
+func substituteValue(value interface{}, variables map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return variableRegexp.ReplaceAllStringFunc(v, func(ref string) string {
+			if val, found := variables[ref[2:len(ref)-1]]; found {
+				return val
+			}
+			return ref
+		})
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = substituteValue(val, variables)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = substituteValue(val, variables)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeSupervisor struct {
+	applied   []client.Object
+	destroyed bool
+	errs      status.MultiError
+	abandoned []client.Object
+	retained  []client.Object
+	previewed []client.Object
+	dryRun    *DryRunResult
+}
+
+var _ Supervisor = &fakeSupervisor{}
+
+func (s *fakeSupervisor) Apply(_ context.Context, objs []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+	s.applied = objs
+	gvks := make(map[schema.GroupVersionKind]struct{})
+	for _, obj := range objs {
+		// The applier modifies the objects it applies.
+		core.SetAnnotation(obj, "applied", "true")
+		gvks[obj.GetObjectKind().GroupVersionKind()] = struct{}{}
+	}
+	return gvks, s.errs
+}
+
+func (s *fakeSupervisor) Destroy(_ context.Context) status.MultiError {
+	s.destroyed = true
+	return s.errs
+}
+
+func (s *fakeSupervisor) Errors() status.MultiError {
+	return s.errs
+}
+
+func (s *fakeSupervisor) AbandonedObjects() []client.Object {
+	return s.abandoned
+}
+
+func (s *fakeSupervisor) RetainedObjects() []client.Object {
+	return s.retained
+}
+
+func (s *fakeSupervisor) RequestFullApply() {}
+
+func (s *fakeSupervisor) PendingPruneExpiry() time.Time {
+	return time.Time{}
+}
+
+func (s *fakeSupervisor) DryRun(_ context.Context, objs []client.Object) (*DryRunResult, status.MultiError) {
+	s.previewed = objs
+	return s.dryRun, s.errs
+}
+
+func TestMultiTargetSupervisorApply(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Name("cluster-info"), core.Namespace("default"),
+		core.Label("region", "${REGION}"))
+	cm.Data = map[string]string{
+		"cluster": "${CLUSTER_NAME}",
+		"url":     "https://${CLUSTER_NAME}.${DOMAIN}/${UNDEFINED}",
+	}
+
+	east := &fakeSupervisor{}
+	west := &fakeSupervisor{
+		errs: status.APIServerError(errors.New("connection refused"), "failed to apply"),
+	}
+	central := &fakeSupervisor{}
+	m := NewMultiTargetSupervisor([]Target{
+		{Name: "east", Supervisor: east, Variables: map[string]string{"CLUSTER_NAME": "east", "REGION": "us-east1", "DOMAIN": "example.com"}},
+		{Name: "west", Supervisor: west, Variables: map[string]string{"CLUSTER_NAME": "west", "REGION": "us-west1", "DOMAIN": "example.com"}},
+		{Name: "central", Supervisor: central},
+	})
+	now := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	assert.Empty(t, m.TargetResults())
+
+	gvks, errs := m.Apply(context.Background(), []client.Object{cm})
+	assert.Equal(t, map[schema.GroupVersionKind]struct{}{kinds.ConfigMap(): {}}, gvks)
+	require.Len(t, errs.Errors(), 1)
+
+	for name, tc := range map[string]struct {
+		supervisor *fakeSupervisor
+		region     string
+	}{
+		"east": {supervisor: east, region: "us-east1"},
+		"west": {supervisor: west, region: "us-west1"},
+	} {
+		require.Len(t, tc.supervisor.applied, 1, name)
+		u := tc.supervisor.applied[0].(*unstructured.Unstructured)
+		assert.Equal(t, tc.region, u.GetLabels()["region"], name)
+		data, _, err := unstructured.NestedStringMap(u.Object, "data")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"cluster": name,
+			"url":     "https://" + name + ".example.com/${UNDEFINED}",
+		}, data, name)
+	}
+	// A target without variables gets copies of the declared objects too.
+	require.Len(t, central.applied, 1)
+	assert.NotSame(t, cm, central.applied[0])
+	assert.Equal(t, "${CLUSTER_NAME}", central.applied[0].(*corev1.ConfigMap).Data["cluster"])
+	// The declared object is not modified.
+	assert.Equal(t, "${CLUSTER_NAME}", cm.Data["cluster"])
+	assert.Equal(t, "${REGION}", cm.Labels["region"])
+	assert.NotContains(t, cm.Annotations, "applied")
+
+	results := m.TargetResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, TargetResult{Name: "east", LastUpdate: now}, results[0])
+	assert.Equal(t, "west", results[1].Name)
+	assert.Equal(t, west.errs, results[1].Errors)
+}
+
+func TestMultiTargetSupervisorAbandonedObjects(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Name("cm"), core.Namespace("default"))
+	ns := fake.NamespaceObject("bookstore")
+	m := NewMultiTargetSupervisor([]Target{
+		{Name: "east", Supervisor: &fakeSupervisor{abandoned: []client.Object{cm}}},
+		{Name: "west", Supervisor: &fakeSupervisor{abandoned: []client.Object{cm.DeepCopy(), ns}, retained: []client.Object{cm}}},
+	})
+	assert.Equal(t, []client.Object{cm, ns}, m.AbandonedObjects())
+	assert.Equal(t, []client.Object{cm}, m.RetainedObjects())
+}
+
+func TestMultiTargetSupervisorDestroy(t *testing.T) {
+	east := &fakeSupervisor{}
+	west := &fakeSupervisor{}
+	m := NewMultiTargetSupervisor([]Target{
+		{Name: "east", Supervisor: east},
+		{Name: "west", Supervisor: west},
+	})
+	assert.Nil(t, m.Destroy(context.Background()))
+	assert.True(t, east.destroyed)
+	assert.True(t, west.destroyed)
+}
+
+func TestMultiTargetSupervisorDryRun(t *testing.T) {
+	cmGVK := kinds.ConfigMap()
+	east := &fakeSupervisor{dryRun: &DryRunResult{
+		Diffs: []ObjectDiff{
+			{Action: v1beta1.DiffActionPrune, GVK: cmGVK, Namespace: "default", Name: "old"},
+			{Action: v1beta1.DiffActionAdd, GVK: cmGVK, Namespace: "default", Name: "new"},
+		},
+		Unchanged: 2,
+	}}
+	west := &fakeSupervisor{dryRun: &DryRunResult{
+		Diffs:     []ObjectDiff{{Action: v1beta1.DiffActionAdd, GVK: cmGVK, Namespace: "default", Name: "new"}},
+		Unchanged: 3,
+	}}
+	m := NewMultiTargetSupervisor([]Target{
+		{Name: "west", Supervisor: west},
+		{Name: "east", Supervisor: east},
+	})
+	cm := fake.ConfigMapObject(core.Name("new"), core.Namespace("default"))
+	result, errs := m.DryRun(context.Background(), []client.Object{cm})
+	require.Nil(t, errs)
+	assert.Equal(t, &DryRunResult{
+		Diffs: []ObjectDiff{
+			{Action: v1beta1.DiffActionAdd, GVK: cmGVK, Namespace: "default", Name: "new", Target: "east"},
+			{Action: v1beta1.DiffActionAdd, GVK: cmGVK, Namespace: "default", Name: "new", Target: "west"},
+			{Action: v1beta1.DiffActionPrune, GVK: cmGVK, Namespace: "default", Name: "old", Target: "east"},
+		},
+		Unchanged: 5,
+	}, result)
+	assert.Equal(t, []client.Object{cm}, east.previewed)
+	assert.Equal(t, []client.Object{cm}, west.previewed)
+}
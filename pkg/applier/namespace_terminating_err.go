@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceTerminatingErrorCode is the error code for objects which are
+// waiting for the deletion of their namespace to complete, before they can be
+// applied again.
+const NamespaceTerminatingErrorCode = "2025"
+
+// NamespaceTerminationTimeoutErrorCode is the error code for objects whose
+// namespace has been terminating for longer than namespaceTerminationTimeout.
+const NamespaceTerminationTimeoutErrorCode = "2026"
+
+// namespaceTerminationTimeout is how long the applier waits for a terminating
+// namespace to be deleted, before it reports that the deletion is stuck.
+const namespaceTerminationTimeout = 10 * time.Minute
+
+var namespaceTerminatingErrorBuilder = status.NewErrorBuilder(NamespaceTerminatingErrorCode)
+
+var namespaceTerminationTimeoutErrorBuilder = status.NewErrorBuilder(NamespaceTerminationTimeoutErrorCode)
+
+// NamespaceTerminatingError indicates that the given resource is waiting for
+// its namespace to finish terminating. It is retried after the deletion of
+// the namespace completes, which recreates the resource in the new namespace.
+func NamespaceTerminatingError(resource client.Object) status.Error {
+	return namespaceTerminatingErrorBuilder.
+		Sprintf("waiting for namespace %q to finish terminating before applying %v",
+			resource.GetNamespace(), core.IDOf(resource)).
+		BuildWithResources(resource)
+}
+
+// NamespaceTerminationTimeoutError indicates that the namespace of the given
+// resource has been terminating for too long, e.g. because of a finalizer
+// which is never removed.
+func NamespaceTerminationTimeoutError(resource client.Object, waited time.Duration) status.Error {
+	return namespaceTerminationTimeoutErrorBuilder.
+		Sprintf("namespace %q has been terminating for more than %v, so %v cannot be applied. "+
+			"Check the finalizers of the namespace and of the objects in it, which may block its deletion",
+			resource.GetNamespace(), waited.Round(time.Second), core.IDOf(resource)).
+		BuildWithResources(resource)
+}
+
+// isNamespaceTerminatingError returns true if the error is returned by the API
+// server for a change in a namespace which is being terminated.
+func isNamespaceTerminatingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return false
+	}
+	for _, cause := range details.Causes {
+		if cause.Type == corev1.NamespaceTerminatingCause {
+			return true
+		}
+	}
+	return false
+}
+
+// handleNamespaceTerminating returns the error for an object which failed to
+// apply, because its namespace is terminating. The object is reported as
+// waiting for the namespace deletion, until the namespace has been seen
+// terminating for longer than namespaceTerminationTimeout.
+// seen records the namespaces found terminating during the current apply.
+func (a *supervisor) handleNamespaceTerminating(obj client.Object, seen map[string]bool) status.Error {
+	namespace := obj.GetNamespace()
+	seen[namespace] = true
+	if a.terminatingNamespaces == nil {
+		a.terminatingNamespaces = make(map[string]time.Time)
+	}
+	now := a.now()
+	since, found := a.terminatingNamespaces[namespace]
+	if !found {
+		since = now
+		a.terminatingNamespaces[namespace] = since
+	}
+	if waited := now.Sub(since); waited > namespaceTerminationTimeout {
+		return NamespaceTerminationTimeoutError(obj, waited)
+	}
+	return NamespaceTerminatingError(obj)
+}
+
+// forgetTerminatedNamespaces stops tracking the namespaces which were not found
+// terminating during the last apply, because their deletion completed.
+func (a *supervisor) forgetTerminatedNamespaces(seen map[string]bool) {
+	for namespace := range a.terminatingNamespaces {
+		if !seen[namespace] {
+			delete(a.terminatingNamespaces, namespace)
+		}
+	}
+}
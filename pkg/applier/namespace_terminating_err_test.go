@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func namespaceTerminatingError(namespace string) error {
+	err := apierrors.NewForbidden(kinds.ConfigMap().GroupVersion().WithResource("configmaps").GroupResource(), "cm",
+		fmt.Errorf("unable to create new content in namespace %s because it is being terminated", namespace))
+	err.ErrStatus.Details.Causes = []metav1.StatusCause{{
+		Type:    corev1.NamespaceTerminatingCause,
+		Message: fmt.Sprintf("namespace %s is being terminated", namespace),
+		Field:   "metadata.namespace",
+	}}
+	return err
+}
+
+func TestIsNamespaceTerminatingError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "namespace terminating",
+			err:  namespaceTerminatingError("foo"),
+			want: true,
+		},
+		{
+			name: "wrapped namespace terminating",
+			err:  fmt.Errorf("failed to apply: %w", namespaceTerminatingError("foo")),
+			want: true,
+		},
+		{
+			name: "other forbidden error",
+			err:  apierrors.NewForbidden(kinds.ConfigMap().GroupVersion().WithResource("configmaps").GroupResource(), "cm", errors.New("denied")),
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("namespace foo is being terminated"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, isNamespaceTerminatingError(tc.err))
+		})
+	}
+}
+
+func TestHandleNamespaceTerminating(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := &supervisor{now: func() time.Time { return now }}
+	cm := fake.ConfigMapObject(core.Name("cm"), core.Namespace("foo"))
+
+	// The first failures report that the object is waiting for the namespace.
+	seen := make(map[string]bool)
+	err := a.handleNamespaceTerminating(cm, seen)
+	require.Equal(t, NamespaceTerminatingErrorCode, err.Code())
+	require.Equal(t, status.TransientErrorClass, status.ClassOf(err))
+	a.forgetTerminatedNamespaces(seen)
+
+	now = now.Add(namespaceTerminationTimeout)
+	seen = make(map[string]bool)
+	err = a.handleNamespaceTerminating(cm, seen)
+	require.Equal(t, NamespaceTerminatingErrorCode, err.Code())
+	a.forgetTerminatedNamespaces(seen)
+
+	// After the timeout, the stuck deletion is reported.
+	now = now.Add(time.Minute)
+	seen = make(map[string]bool)
+	err = a.handleNamespaceTerminating(cm, seen)
+	require.Equal(t, NamespaceTerminationTimeoutErrorCode, err.Code())
+	require.Equal(t, status.UserErrorClass, status.ClassOf(err))
+	require.Contains(t, err.Error(), `namespace "foo" has been terminating for more than 11m0s`)
+	a.forgetTerminatedNamespaces(seen)
+
+	// Once the deletion completes, the namespace is no longer tracked, so a
+	// later termination starts waiting again.
+	a.forgetTerminatedNamespaces(make(map[string]bool))
+	require.Empty(t, a.terminatingNamespaces)
+	err = a.handleNamespaceTerminating(cm, make(map[string]bool))
+	require.Equal(t, NamespaceTerminatingErrorCode, err.Code())
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+// partialApplyMaxRatio is the largest share of the declared objects which is
+// applied partially. Applying more objects than that does not save much
+// time, so all the objects are applied instead.
+const partialApplyMaxRatio = 0.5
+
+// mergingInventoryClient is an inventory.Client which adds the objects to the
+// inventory instead of replacing the inventory. It is used by partial applies,
+// which do not prune, so that the objects which are not part of the partial
+// apply stay in the inventory and are still pruned by the next full apply.
+type mergingInventoryClient struct {
+	inventory.Client
+}
+
+// Replace adds the objects to the ones already in the inventory.
+func (c *mergingInventoryClient) Replace(inv inventory.Info, objs object.ObjMetadataSet, status []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
+	clusterObjs, err := c.Client.GetClusterObjs(inv)
+	if err != nil {
+		return err
+	}
+	return c.Client.Replace(inv, clusterObjs.Union(objs), status, dryRun)
+}
+
+// partialApplyObjects returns the subset of the resources to apply, and
+// whether the apply is partial. Only the objects declared in the source files
+// which changed since the last successful apply are applied, along with the
+// objects which depend on them. All the resources are applied if partial
+// apply is disabled, if the last apply did not succeed, if objects were
+// removed and need to be pruned, if too many objects changed, or if a full
+// apply was requested.
+func (a *supervisor) partialApplyObjects(resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, bool) {
+	if !a.clientSet.PartialApply || a.clientSet.PartialKptApplier == nil || a.lastSucceeded == nil {
+		return resources, false
+	}
+
+	declared := make(map[core.ID]*unstructured.Unstructured, len(resources))
+	for _, resource := range resources {
+		declared[core.IDOf(resource)] = resource
+	}
+	for id := range a.lastSucceeded {
+		if _, found := declared[id]; !found {
+			klog.Infof("Applying all the objects, since %s was removed and needs to be pruned", id)
+			return resources, false
+		}
+	}
+
+	// Find the source files which changed since the last successful apply.
+	changedFiles := make(map[string]bool)
+	affected := make(map[core.ID]bool)
+	for id, resource := range declared {
+		if last, found := a.lastSucceeded[id]; found && equality.Semantic.DeepEqual(last.Object, resource.Object) {
+			continue
+		}
+		affected[id] = true
+		if path := core.GetAnnotation(resource, metadata.SourcePathAnnotationKey); path != "" {
+			changedFiles[path] = true
+		}
+	}
+	for id, resource := range declared {
+		if changedFiles[core.GetAnnotation(resource, metadata.SourcePathAnnotationKey)] {
+			affected[id] = true
+		}
+	}
+	addDependents(declared, affected)
+
+	if float64(len(affected)) > partialApplyMaxRatio*float64(len(resources)) {
+		klog.Infof("Applying all the objects, since %d of %d objects changed", len(affected), len(resources))
+		return resources, false
+	}
+	var partial []*unstructured.Unstructured
+	for _, resource := range resources {
+		if affected[core.IDOf(resource)] {
+			partial = append(partial, resource)
+		}
+	}
+	klog.Infof("Applying %d of %d objects, declared in the %d changed source files", len(partial), len(resources), len(changedFiles))
+	return partial, true
+}
+
+// addDependents adds the declared objects which depend on the affected
+// objects, directly or transitively, to the affected objects.
+func addDependents(declared map[core.ID]*unstructured.Unstructured, affected map[core.ID]bool) {
+	for added := true; added; {
+		added = false
+		for id, resource := range declared {
+			if affected[id] {
+				continue
+			}
+			deps, err := dependson.ReadAnnotation(resource)
+			if err != nil {
+				// Invalid annotations are reported by the applier.
+				continue
+			}
+			for _, dep := range deps {
+				if affected[idFrom(dep)] {
+					affected[id] = true
+					added = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// recordApply remembers the resources of a successful apply, so that the next
+// apply only applies what changed. A failed apply makes the next apply a full
+// apply.
+func (a *supervisor) recordApply(resources []*unstructured.Unstructured, succeeded bool) {
+	if !succeeded {
+		a.lastSucceeded = nil
+		return
+	}
+	a.lastSucceeded = make(map[core.ID]*unstructured.Unstructured, len(resources))
+	for _, resource := range resources {
+		a.lastSucceeded[core.IDOf(resource)] = resource
+	}
+}
+
+// RequestFullApply makes the next Apply apply all the objects and prune the
+// removed ones.
+// RequestFullApply implements the Applier interface.
+func (a *supervisor) RequestFullApply() {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+
+	a.lastSucceeded = nil
+	a.retryCommit, a.retrySucceeded = "", nil
+}
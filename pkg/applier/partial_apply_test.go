@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+func TestPartialApplyObjects(t *testing.T) {
+	cm := func(name, path, value string, opts ...core.MetaMutator) *unstructured.Unstructured {
+		opts = append(opts, core.Name(name), core.Namespace("default"),
+			core.Annotation(metadata.SourcePathAnnotationKey, path))
+		obj := fake.UnstructuredObject(kinds.ConfigMap(), opts...)
+		obj.Object["data"] = map[string]interface{}{"key": value}
+		return obj
+	}
+	dependsOnA := core.Annotation(dependson.Annotation, "/namespaces/default/ConfigMap/a")
+	last := []*unstructured.Unstructured{
+		cm("a", "a.yaml", "1"),
+		cm("b", "ab.yaml", "1"),
+		cm("c", "c.yaml", "1", dependsOnA),
+		cm("d", "d.yaml", "1"),
+		cm("e", "e.yaml", "1"),
+		cm("f", "f.yaml", "1"),
+	}
+
+	testCases := []struct {
+		name        string
+		disabled    bool
+		lastFailed  bool
+		resources   []*unstructured.Unstructured
+		wantPartial bool
+		wantNames   []string
+	}{
+		{
+			name:        "changed object with its dependents",
+			resources:   []*unstructured.Unstructured{cm("a", "a.yaml", "2"), last[1], last[2], last[3], last[4], last[5]},
+			wantPartial: true,
+			wantNames:   []string{"a", "c"},
+		},
+		{
+			name:        "added object with the objects of the same file",
+			resources:   append([]*unstructured.Unstructured{cm("g", "ab.yaml", "1")}, last...),
+			wantPartial: true,
+			wantNames:   []string{"g", "b"},
+		},
+		{
+			name:        "no change",
+			resources:   last,
+			wantPartial: true,
+		},
+		{
+			name:      "removed object",
+			resources: last[1:],
+			wantNames: []string{"b", "c", "d", "e", "f"},
+		},
+		{
+			name:      "too many changes",
+			resources: []*unstructured.Unstructured{cm("a", "a.yaml", "2"), last[1], last[2], cm("d", "d.yaml", "2"), cm("e", "e.yaml", "2"), last[5]},
+			wantNames: []string{"a", "b", "c", "d", "e", "f"},
+		},
+		{
+			name:       "last apply failed",
+			lastFailed: true,
+			resources:  []*unstructured.Unstructured{cm("a", "a.yaml", "2"), last[1], last[2], last[3], last[4], last[5]},
+			wantNames:  []string{"a", "b", "c", "d", "e", "f"},
+		},
+		{
+			name:      "disabled",
+			disabled:  true,
+			resources: []*unstructured.Unstructured{cm("a", "a.yaml", "2"), last[1], last[2], last[3], last[4], last[5]},
+			wantNames: []string{"a", "b", "c", "d", "e", "f"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newPartialApplySupervisor(t, !tc.disabled)
+			a.recordApply(last, !tc.lastFailed)
+
+			got, partial := a.partialApplyObjects(tc.resources)
+			assert.Equal(t, tc.wantPartial, partial)
+			var gotNames []string
+			for _, obj := range got {
+				gotNames = append(gotNames, obj.GetName())
+			}
+			assert.Equal(t, tc.wantNames, gotNames)
+		})
+	}
+}
+
+func TestRequestFullApply(t *testing.T) {
+	obj := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("a"), core.Namespace("default"))
+	a := newPartialApplySupervisor(t, true)
+	a.recordApply([]*unstructured.Unstructured{obj}, true)
+
+	a.RequestFullApply()
+	_, partial := a.partialApplyObjects([]*unstructured.Unstructured{obj})
+	assert.False(t, partial)
+}
+
+func newPartialApplySupervisor(t *testing.T, enabled bool) *supervisor {
+	t.Helper()
+	fakeClient := testingfake.NewClient(t, core.Scheme)
+	cs := &ClientSet{
+		KptApplier:        newFakeKptApplier(nil),
+		PartialKptApplier: newFakeKptApplier(nil),
+		InvClient:         inventory.NewFakeClient(nil),
+		Client:            fakeClient,
+		Mapper:            fakeClient.RESTMapper(),
+		PartialApply:      enabled,
+	}
+	s, err := NewRootSupervisor(cs, "root-sync", 5*time.Minute)
+	require.NoError(t, err)
+	return s.(*supervisor)
+}
+
+func TestMergingInventoryClientReplace(t *testing.T) {
+	a := object.ObjMetadata{GroupKind: kinds.ConfigMap().GroupKind(), Namespace: "default", Name: "a"}
+	b := object.ObjMetadata{GroupKind: kinds.ConfigMap().GroupKind(), Namespace: "default", Name: "b"}
+	fakeClient := inventory.NewFakeClient(object.ObjMetadataSet{a})
+	c := &mergingInventoryClient{Client: fakeClient}
+
+	require.NoError(t, c.Replace(nil, object.ObjMetadataSet{b}, nil, common.DryRunNone))
+	assert.ElementsMatch(t, object.ObjMetadataSet{a, b}, fakeClient.Objs)
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// deferPrunes returns the objects in the inventory which are missing from the
+// declared resources, but whose prune delay has not expired yet. They are
+// applied along with the declared resources, so that they are not pruned,
+// annotated with when they were found removed. Removed objects are applied
+// with their last applied configuration, or with their live configuration if
+// the reconciler restarted since they were removed. The remediator leaves the
+// objects annotated as pending prune alone, and the reconciler runs again when
+// the first prune delay expires.
+func (a *supervisor) deferPrunes(ctx context.Context, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, status.MultiError) {
+	applied := make(map[core.ID]*unstructured.Unstructured, len(resources))
+	for _, resource := range resources {
+		applied[core.IDOf(resource)] = resource
+	}
+	lastApplied := a.lastApplied
+	a.lastApplied = applied
+	a.setPendingPruneExpiry(time.Time{})
+	if a.clientSet.PruneDelay <= 0 {
+		return nil, nil
+	}
+
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, Error(err)
+	}
+	now := a.now()
+	var pending []*unstructured.Unstructured
+	var expiry time.Time
+	for _, invObj := range invObjs {
+		id := idFrom(invObj)
+		if _, found := applied[id]; found {
+			continue
+		}
+		mapping, err := a.clientSet.Mapper.RESTMapping(id.GroupKind)
+		if err != nil {
+			// The type is no longer served, so the object cannot be pruned.
+			klog.V(3).Infof("Skipping prune delay of %s: %v", id, err)
+			continue
+		}
+		liveObj := &unstructured.Unstructured{}
+		liveObj.SetGroupVersionKind(mapping.GroupVersionKind)
+		if err := a.clientSet.Client.Get(ctx, id.ObjectKey, liveObj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, status.APIServerError(err, "failed to get object pending prune", liveObj)
+		}
+
+		since := now
+		if value, found := liveObj.GetAnnotations()[metadata.PendingPruneSinceKey]; found {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				since = t
+			}
+		}
+		if now.Sub(since) >= a.clientSet.PruneDelay {
+			klog.Infof("Prune delay of %s expired, pruning the object", id)
+			continue
+		}
+
+		obj, found := lastApplied[id]
+		if found {
+			obj = obj.DeepCopy()
+		} else {
+			obj = sanitizeLiveObject(liveObj)
+		}
+		core.SetAnnotation(obj, metadata.PendingPruneSinceKey, since.UTC().Format(time.RFC3339))
+		objExpiry := since.Add(a.clientSet.PruneDelay)
+		klog.Infof("Object %s was removed from the source, pruning it after %v",
+			id, objExpiry.UTC().Format(time.RFC3339))
+		if expiry.IsZero() || objExpiry.Before(expiry) {
+			expiry = objExpiry
+		}
+		applied[id] = obj
+		pending = append(pending, obj)
+	}
+	a.setPendingPruneExpiry(expiry)
+	return pending, nil
+}
+
+func (a *supervisor) setPendingPruneExpiry(expiry time.Time) {
+	a.errorMux.Lock()
+	defer a.errorMux.Unlock()
+
+	a.pendingPruneExpiry = expiry
+}
+
+// sanitizeLiveObject returns a copy of the live object without the fields set
+// by the server, so that it can be applied.
+func sanitizeLiveObject(liveObj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj := liveObj.DeepCopy()
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetSelfLink("")
+	obj.SetCreationTimestamp(metav1.Time{})
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return obj
+}
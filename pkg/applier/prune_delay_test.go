@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestDeferPrunes(t *testing.T) {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	kept := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("kept"), core.Namespace("default"))
+	removed := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("removed"), core.Namespace("default"))
+	removed.Object["data"] = map[string]interface{}{"key": "declared"}
+	pending := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("pending"), core.Namespace("default"),
+		core.Annotation(metadata.PendingPruneSinceKey, now.Add(-20*time.Minute).Format(time.RFC3339)))
+	expired := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("expired"), core.Namespace("default"),
+		core.Annotation(metadata.PendingPruneSinceKey, now.Add(-2*time.Hour).Format(time.RFC3339)))
+	deleted := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("deleted"), core.Namespace("default"))
+
+	liveRemoved := removed.DeepCopy()
+	liveRemoved.Object["data"] = map[string]interface{}{"key": "live"}
+	fakeClient := testingfake.NewClient(t, core.Scheme, kept, liveRemoved, pending, expired)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{
+			object.UnstructuredToObjMetadata(kept),
+			object.UnstructuredToObjMetadata(removed),
+			object.UnstructuredToObjMetadata(pending),
+			object.UnstructuredToObjMetadata(expired),
+			object.UnstructuredToObjMetadata(deleted),
+		}),
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+		PruneDelay: time.Hour,
+	}
+	s, err := NewRootSupervisor(cs, "root-sync", 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+	a.now = func() time.Time { return now }
+	a.lastApplied = map[core.ID]*unstructured.Unstructured{core.IDOf(removed): removed}
+
+	got, errs := a.deferPrunes(context.Background(), []*unstructured.Unstructured{kept})
+	require.Nil(t, errs)
+
+	gotByName := make(map[string]*unstructured.Unstructured)
+	for _, obj := range got {
+		gotByName[obj.GetName()] = obj
+	}
+	require.Len(t, gotByName, 2)
+
+	// The removed object is applied with its last applied configuration.
+	assert.Equal(t, now.Format(time.RFC3339), gotByName["removed"].GetAnnotations()[metadata.PendingPruneSinceKey])
+	assert.Equal(t, "declared", gotByName["removed"].Object["data"].(map[string]interface{})["key"])
+	// The pending object is applied with its live configuration after a
+	// restart, and keeps the time since when it is pending prune.
+	assert.Equal(t, now.Add(-20*time.Minute).Format(time.RFC3339), gotByName["pending"].GetAnnotations()[metadata.PendingPruneSinceKey])
+	assert.Empty(t, gotByName["pending"].GetResourceVersion())
+	assert.Empty(t, gotByName["pending"].GetUID())
+
+	// The pending objects are remembered for the next apply.
+	assert.Contains(t, a.lastApplied, core.IDOf(removed))
+	assert.Contains(t, a.lastApplied, core.IDOf(kept))
+	// The reconciler runs again when the first prune delay expires.
+	assert.Equal(t, now.Add(40*time.Minute), a.PendingPruneExpiry())
+}
+
+func TestDeferPrunesDisabled(t *testing.T) {
+	removed := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("removed"), core.Namespace("default"))
+	fakeClient := testingfake.NewClient(t, core.Scheme, removed)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient:  inventory.NewFakeClient(object.ObjMetadataSet{object.UnstructuredToObjMetadata(removed)}),
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+	}
+	s, err := NewRootSupervisor(cs, "root-sync", 5*time.Minute)
+	require.NoError(t, err)
+
+	got, errs := s.(*supervisor).deferPrunes(context.Background(), nil)
+	assert.Nil(t, errs)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	nomosutil "kpt.dev/configsync/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// canPrune returns true if the objects of the kind can be pruned, according
+// to the allowed and denied kinds of the ClientSet.
+func (cs *ClientSet) canPrune(gk schema.GroupKind) bool {
+	for _, denied := range cs.PruneDeniedKinds {
+		if denied == gk {
+			return false
+		}
+	}
+	if len(cs.PruneAllowedKinds) == 0 {
+		return true
+	}
+	for _, allowed := range cs.PruneAllowedKinds {
+		if allowed == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// retainUnprunableObjects unmanages the objects in the inventory which are
+// missing from the declared resources, but whose kind cannot be pruned. They
+// are removed from the inventory, so that the applier does not prune them,
+// and their Config Sync metadata is removed, like for the objects whose
+// management is disabled. They are tracked as retained, rather than abandoned.
+// The objects are looked up with the version of their last applied
+// configuration, if the reconciler did not restart since they were applied.
+func (a *supervisor) retainUnprunableObjects(ctx context.Context, resources []*unstructured.Unstructured, lastApplied map[core.ID]*unstructured.Unstructured) status.MultiError {
+	if len(a.clientSet.PruneAllowedKinds) == 0 && len(a.clientSet.PruneDeniedKinds) == 0 {
+		return nil
+	}
+	declared := make(map[core.ID]struct{}, len(resources))
+	for _, resource := range resources {
+		declared[core.IDOf(resource)] = struct{}{}
+	}
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return Error(err)
+	}
+	var retained []client.Object
+	for _, invObj := range invObjs {
+		id := idFrom(invObj)
+		if _, found := declared[id]; found || a.clientSet.canPrune(id.GroupKind) {
+			continue
+		}
+		var versions []string
+		if obj, found := lastApplied[id]; found {
+			versions = append(versions, obj.GroupVersionKind().Version)
+		}
+		mapping, err := a.clientSet.Mapper.RESTMapping(id.GroupKind, versions...)
+		if err != nil {
+			// The type is no longer served, so the object cannot be unmanaged.
+			klog.V(3).Infof("Skipping retaining %s: %v", id, err)
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(mapping.GroupVersionKind)
+		obj.SetNamespace(id.Namespace)
+		obj.SetName(id.Name)
+		retained = append(retained, obj)
+	}
+	if len(retained) == 0 {
+		return nil
+	}
+	klog.Infof("%v objects removed from the source are retained, because their kinds cannot be pruned: %v",
+		len(retained), core.GKNNs(retained))
+
+	if err := a.removeFromInventory(a.inventory, retained); err != nil {
+		if nomosutil.IsRequestTooLargeError(err) {
+			return largeResourceGroupError(err, idFromInventory(a.inventory))
+		}
+		return Error(err)
+	}
+	var errs status.MultiError
+	for _, obj := range retained {
+		id := core.IDOf(obj)
+		err := a.abandonObject(ctx, obj)
+		handleMetrics(ctx, "unmanage", err, id.Kind)
+		if err != nil && !apierrors.IsNotFound(err) {
+			err = fmt.Errorf("failed to remove the Config Sync metadata from %v (kind cannot be pruned): %v", id, err)
+			klog.Warning(err)
+			errs = status.Append(errs, Error(err))
+			continue
+		}
+		a.addRetained(obj)
+	}
+	return errs
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestClientSetCanPrune(t *testing.T) {
+	testCases := []struct {
+		name    string
+		allowed []schema.GroupKind
+		denied  []schema.GroupKind
+		gk      schema.GroupKind
+		want    bool
+	}{
+		{
+			name: "all kinds allowed by default",
+			gk:   kinds.Deployment().GroupKind(),
+			want: true,
+		},
+		{
+			name:    "allowed kind",
+			allowed: []schema.GroupKind{kinds.ConfigMap().GroupKind()},
+			gk:      kinds.ConfigMap().GroupKind(),
+			want:    true,
+		},
+		{
+			name:    "kind not allowed",
+			allowed: []schema.GroupKind{kinds.ConfigMap().GroupKind()},
+			gk:      kinds.Deployment().GroupKind(),
+			want:    false,
+		},
+		{
+			name:    "denied kind takes precedence",
+			allowed: []schema.GroupKind{kinds.ConfigMap().GroupKind()},
+			denied:  []schema.GroupKind{kinds.ConfigMap().GroupKind()},
+			gk:      kinds.ConfigMap().GroupKind(),
+			want:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &ClientSet{PruneAllowedKinds: tc.allowed, PruneDeniedKinds: tc.denied}
+			assert.Equal(t, tc.want, cs.canPrune(tc.gk))
+		})
+	}
+}
+
+func TestRetainUnprunableObjects(t *testing.T) {
+	managed := []core.MetaMutator{
+		core.Namespace("default"),
+		core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
+		core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+	}
+	declared := fake.UnstructuredObject(kinds.Deployment(), append(managed, core.Name("declared"))...)
+	removedDeployment := fake.UnstructuredObject(kinds.Deployment(), append(managed, core.Name("removed"))...)
+	removedConfigMap := fake.UnstructuredObject(kinds.ConfigMap(), append(managed, core.Name("removed"))...)
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, declared, removedDeployment, removedConfigMap)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{
+			object.UnstructuredToObjMetadata(declared),
+			object.UnstructuredToObjMetadata(removedDeployment),
+			object.UnstructuredToObjMetadata(removedConfigMap),
+		}),
+		Client:           fakeClient,
+		Mapper:           fakeClient.RESTMapper(),
+		PruneDeniedKinds: []schema.GroupKind{kinds.Deployment().GroupKind()},
+	}
+	s, err := NewRootSupervisor(cs, "root-sync", 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+
+	// Deployments are served in multiple versions, so the removed Deployment
+	// is looked up with the version it was last applied with.
+	lastApplied := map[core.ID]*unstructured.Unstructured{
+		core.IDOf(declared):          declared,
+		core.IDOf(removedDeployment): removedDeployment,
+		core.IDOf(removedConfigMap):  removedConfigMap,
+	}
+	errs := a.retainUnprunableObjects(context.Background(), []*unstructured.Unstructured{declared}, lastApplied)
+	require.Nil(t, errs)
+
+	// Only the removed Deployment is unmanaged. The removed ConfigMap is left
+	// to the applier to prune.
+	retained := a.RetainedObjects()
+	require.Len(t, retained, 1)
+	assert.Equal(t, core.IDOf(removedDeployment), core.IDOf(retained[0]))
+	assert.Empty(t, a.AbandonedObjects())
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(kinds.Deployment())
+	require.NoError(t, fakeClient.Get(context.Background(), core.IDOf(removedDeployment).ObjectKey, live))
+	assert.False(t, metadata.HasConfigSyncMetadata(live))
+}
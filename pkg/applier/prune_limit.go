@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PruneLimitExceededErrorCode is the error code for prunes which are blocked,
+// because they exceed the prune limits of the RootSync or RepoSync.
+const PruneLimitExceededErrorCode = "2028"
+
+// PruneLimitExceededReason is the reason of the event recorded on a RootSync
+// or RepoSync whose prune is blocked, because it exceeds the prune limits.
+const PruneLimitExceededReason = "PruneLimitExceeded"
+
+var pruneLimitExceededErrorBuilder = status.NewErrorBuilder(PruneLimitExceededErrorCode)
+
+// PruneLimitExceededError indicates that the objects removed from the source
+// of truth are not pruned, because there are more of them than allowed by
+// the spec.prune maxPercentage or maxCount of the RootSync or RepoSync.
+func PruneLimitExceededError(syncKind string, pruned, total int) status.Error {
+	return pruneLimitExceededErrorBuilder.
+		Sprintf("refusing to prune %d of the %d managed objects, because it exceeds the spec.prune.maxPercentage "+
+			"or spec.prune.maxCount of the %s. Restore the objects in the source of truth, "+
+			"or annotate the %s with `%s: \"%d\"` to prune them anyway",
+			pruned, total, syncKind, syncKind, metadata.ConfirmPruneKey, pruned).
+		Build()
+}
+
+// exceedsPruneLimits returns true if pruning the number of objects, out of
+// the total number of managed objects, exceeds the prune limits of the
+// ClientSet.
+func (cs *ClientSet) exceedsPruneLimits(pruned, total int) bool {
+	if cs.PruneMaxCount > 0 && pruned > cs.PruneMaxCount {
+		return true
+	}
+	return cs.PruneMaxPercentage > 0 && pruned*100 > cs.PruneMaxPercentage*total
+}
+
+// checkPruneLimits returns true if the objects in the inventory which are
+// missing from the declared resources must not be pruned, because there are
+// more of them than allowed by the prune limits, and the prune is not
+// confirmed by the annotation of the RootSync or RepoSync. Blocked prunes are
+// reported with an error and an event, so that a bad change removing most of
+// the objects from the source of truth does not delete them.
+func (a *supervisor) checkPruneLimits(ctx context.Context, resources []*unstructured.Unstructured) (bool, status.MultiError) {
+	if a.clientSet.PruneMaxCount <= 0 && a.clientSet.PruneMaxPercentage <= 0 {
+		return false, nil
+	}
+	declared := make(map[core.ID]struct{}, len(resources))
+	for _, resource := range resources {
+		declared[core.IDOf(resource)] = struct{}{}
+	}
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return false, Error(err)
+	}
+	pruned := 0
+	for _, invObj := range invObjs {
+		if _, found := declared[idFrom(invObj)]; !found {
+			pruned++
+		}
+	}
+	if pruned == 0 || !a.clientSet.exceedsPruneLimits(pruned, len(invObjs)) {
+		a.blockedPrunes = 0
+		return false, nil
+	}
+
+	rsync, err := a.getRSync(ctx)
+	if err != nil {
+		// Without the confirmation, the prune stays blocked.
+		klog.Warningf("Failed to get the %s to check the prune confirmation: %v", a.syncKind, err)
+	} else if core.GetAnnotation(rsync, metadata.ConfirmPruneKey) == strconv.Itoa(pruned) {
+		klog.Infof("Pruning %d of the %d managed objects, as confirmed by the %s annotation",
+			pruned, len(invObjs), metadata.ConfirmPruneKey)
+		a.blockedPrunes = 0
+		return false, nil
+	}
+
+	klog.Warningf("Refusing to prune %d of the %d managed objects, because it exceeds the prune limits", pruned, len(invObjs))
+	a.addError(PruneLimitExceededError(a.syncKind, pruned, len(invObjs)))
+	if rsync != nil && a.blockedPrunes != pruned {
+		a.recordPruneLimitExceededEvent(ctx, rsync, pruned, len(invObjs))
+	}
+	a.blockedPrunes = pruned
+	return true, nil
+}
+
+// getRSync returns the RootSync or RepoSync of the supervisor.
+func (a *supervisor) getRSync(ctx context.Context) (*unstructured.Unstructured, error) {
+	rsync := &unstructured.Unstructured{}
+	if a.syncKind == configsync.RootSyncKind {
+		rsync.SetGroupVersionKind(kinds.RootSyncV1Beta1())
+	} else {
+		rsync.SetGroupVersionKind(kinds.RepoSyncV1Beta1())
+	}
+	key := client.ObjectKey{Namespace: a.syncNamespace, Name: a.syncName}
+	if err := a.clientSet.Client.Get(ctx, key, rsync); err != nil {
+		return nil, err
+	}
+	return rsync, nil
+}
+
+// recordPruneLimitExceededEvent emits a warning event on the RootSync or
+// RepoSync whose prune is blocked, or whose number of objects to prune changed
+// since the prune was last blocked.
+// Failures are logged, but otherwise ignored, because events are best effort.
+func (a *supervisor) recordPruneLimitExceededEvent(ctx context.Context, rsync *unstructured.Unstructured, pruned, total int) {
+	message := fmt.Sprintf("Not pruning %d of the %d managed objects removed from the source of truth, because it exceeds the prune limits. "+
+		"Annotate with `%s: \"%d\"` to prune them anyway", pruned, total, metadata.ConfirmPruneKey, pruned)
+	e := event.New(event.Reference(rsync, rsync.GroupVersionKind()), corev1.EventTypeWarning, PruneLimitExceededReason, message,
+		a.syncName, metav1.NewTime(a.now()))
+	if err := a.clientSet.Client.Create(ctx, e); err != nil {
+		klog.Warningf("Failed to record the %s event: %v", PruneLimitExceededReason, err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestExceedsPruneLimits(t *testing.T) {
+	testCases := []struct {
+		name          string
+		maxPercentage int
+		maxCount      int
+		pruned        int
+		total         int
+		want          bool
+	}{
+		{
+			name:   "no limits",
+			pruned: 10,
+			total:  10,
+			want:   false,
+		},
+		{
+			name:     "below the count",
+			maxCount: 5,
+			pruned:   5,
+			total:    100,
+			want:     false,
+		},
+		{
+			name:     "above the count",
+			maxCount: 5,
+			pruned:   6,
+			total:    100,
+			want:     true,
+		},
+		{
+			name:          "below the percentage",
+			maxPercentage: 50,
+			pruned:        5,
+			total:         10,
+			want:          false,
+		},
+		{
+			name:          "above the percentage",
+			maxPercentage: 50,
+			pruned:        6,
+			total:         10,
+			want:          true,
+		},
+		{
+			name:          "above the count, below the percentage",
+			maxPercentage: 50,
+			maxCount:      2,
+			pruned:        3,
+			total:         10,
+			want:          true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &ClientSet{PruneMaxPercentage: tc.maxPercentage, PruneMaxCount: tc.maxCount}
+			assert.Equal(t, tc.want, cs.exceedsPruneLimits(tc.pruned, tc.total))
+		})
+	}
+}
+
+func TestCheckPruneLimits(t *testing.T) {
+	declared := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("declared"), core.Namespace("foo"))
+	removed1 := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("removed-1"), core.Namespace("foo"))
+	removed2 := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("removed-2"), core.Namespace("foo"))
+	rootSync := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, rootSync)
+	cs := &ClientSet{
+		KptApplier: newFakeKptApplier(nil),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{
+			object.UnstructuredToObjMetadata(declared),
+			object.UnstructuredToObjMetadata(removed1),
+			object.UnstructuredToObjMetadata(removed2),
+		}),
+		Client:             fakeClient,
+		Mapper:             fakeClient.RESTMapper(),
+		PruneMaxPercentage: 50,
+	}
+	s, err := NewRootSupervisor(cs, configsync.RootSyncName, 5*time.Minute)
+	require.NoError(t, err)
+	a := s.(*supervisor)
+	ctx := context.Background()
+	resources := []*unstructured.Unstructured{declared}
+
+	// Pruning 2 of the 3 objects exceeds 50%.
+	blocked, errs := a.checkPruneLimits(ctx, resources)
+	require.Nil(t, errs)
+	assert.True(t, blocked)
+	require.Len(t, a.Errors().Errors(), 1)
+	assert.Equal(t, PruneLimitExceededErrorCode, a.Errors().Errors()[0].(status.Error).Code())
+	assert.Contains(t, a.Errors().Error(), "refusing to prune 2 of the 3 managed objects")
+
+	events := &corev1.EventList{}
+	require.NoError(t, fakeClient.List(ctx, events))
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, PruneLimitExceededReason, events.Items[0].Reason)
+	assert.Equal(t, configsync.RootSyncName, events.Items[0].InvolvedObject.Name)
+
+	// The event is not recorded again while the number of objects to prune
+	// does not change.
+	a.invalidateErrors()
+	blocked, errs = a.checkPruneLimits(ctx, resources)
+	require.Nil(t, errs)
+	assert.True(t, blocked)
+	require.NoError(t, fakeClient.List(ctx, events))
+	assert.Len(t, events.Items, 1)
+
+	// Confirming a different number of objects does not unblock the prune.
+	a.invalidateErrors()
+	core.SetAnnotation(rootSync, metadata.ConfirmPruneKey, "1")
+	require.NoError(t, fakeClient.Update(ctx, rootSync))
+	blocked, errs = a.checkPruneLimits(ctx, resources)
+	require.Nil(t, errs)
+	assert.True(t, blocked)
+
+	// Confirming the number of objects to prune unblocks the prune.
+	a.invalidateErrors()
+	core.SetAnnotation(rootSync, metadata.ConfirmPruneKey, "2")
+	require.NoError(t, fakeClient.Update(ctx, rootSync))
+	blocked, errs = a.checkPruneLimits(ctx, resources)
+	require.Nil(t, errs)
+	assert.False(t, blocked)
+	assert.Nil(t, a.Errors())
+
+	// Pruning 1 of the 3 objects is within the limits.
+	core.RemoveAnnotations(rootSync, metadata.ConfirmPruneKey)
+	require.NoError(t, fakeClient.Update(ctx, rootSync))
+	blocked, errs = a.checkPruneLimits(ctx, append(resources, removed1))
+	require.Nil(t, errs)
+	assert.False(t, blocked)
+}
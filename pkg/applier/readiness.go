@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/statusreaders"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// readinessMapping maps the Ready condition of the kinds of an API group to
+// their kstatus status. The controllers of these kinds report their health
+// with a Ready condition instead of the kstatus Reconciling and Stalled
+// conditions, so kstatus considers them Current as soon as they are created,
+// before their controllers report anything, and InProgress when they failed
+// permanently.
+type readinessMapping struct {
+	// group is the API group of the kinds, if they are in a single group.
+	group string
+	// groupSuffix is the suffix of the API groups of the kinds, if they are in
+	// several groups.
+	groupSuffix string
+	// failedReasons are the reasons of a False Ready condition which are
+	// terminal failures, instead of progress.
+	failedReasons map[string]bool
+}
+
+// readinessMappings are the built-in readiness mappings.
+var readinessMappings = []readinessMapping{
+	{
+		// Config Connector
+		groupSuffix: ".cnrm.cloud.google.com",
+		failedReasons: map[string]bool{
+			"UpdateFailed":       true,
+			"DeleteFailed":       true,
+			"DependencyInvalid":  true,
+			"ManagementConflict": true,
+		},
+	},
+	{
+		// cert-manager Certificates, CertificateRequests, Issuers and
+		// ClusterIssuers
+		group: "cert-manager.io",
+		failedReasons: map[string]bool{
+			"Failed":  true,
+			"Denied":  true,
+			"Invalid": true,
+		},
+	},
+}
+
+// findReadinessMapping returns the readiness mapping of the GroupKind, or nil
+// if there is none.
+func findReadinessMapping(gk schema.GroupKind) *readinessMapping {
+	for i, m := range readinessMappings {
+		if (m.group != "" && gk.Group == m.group) || (m.groupSuffix != "" && strings.HasSuffix(gk.Group, m.groupSuffix)) {
+			return &readinessMappings[i]
+		}
+	}
+	return nil
+}
+
+// computeReadiness computes the status of an object with a readiness mapping.
+// The kstatus status of the objects being deleted, or whose controller has not
+// observed their latest generation, takes precedence over the Ready condition.
+func computeReadiness(u *unstructured.Unstructured) (*kstatus.Result, error) {
+	result, err := kstatus.Compute(u)
+	if err != nil || result.Status == kstatus.TerminatingStatus {
+		return result, err
+	}
+	observedGeneration := kstatus.GetIntField(u.Object, ".status.observedGeneration", -1)
+	if observedGeneration != -1 && int64(observedGeneration) != u.GetGeneration() {
+		return result, nil
+	}
+	mapping := findReadinessMapping(u.GroupVersionKind().GroupKind())
+	if mapping == nil {
+		return result, nil
+	}
+	obj, err := kstatus.GetObjectWithConditions(u.Object)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range obj.Status.Conditions {
+		if c.Type != "Ready" {
+			continue
+		}
+		switch c.Status {
+		case corev1.ConditionTrue:
+			return result, nil
+		case corev1.ConditionFalse:
+			message := fmt.Sprintf("Ready: %s: %s", c.Reason, c.Message)
+			if mapping.failedReasons[c.Reason] {
+				return &kstatus.Result{Status: kstatus.FailedStatus, Message: message}, nil
+			}
+			return &kstatus.Result{Status: kstatus.InProgressStatus, Message: message}, nil
+		default:
+			return &kstatus.Result{Status: kstatus.InProgressStatus, Message: fmt.Sprintf("Ready: %s", c.Status)}, nil
+		}
+	}
+	return &kstatus.Result{Status: kstatus.InProgressStatus, Message: "Ready condition not yet reported"}, nil
+}
+
+// readinessStatusReader is an engine.StatusReader for the kinds with a
+// readiness mapping.
+type readinessStatusReader struct {
+	engine.StatusReader
+}
+
+// Supports returns true for the kinds with a readiness mapping.
+func (r *readinessStatusReader) Supports(gk schema.GroupKind) bool {
+	return findReadinessMapping(gk) != nil
+}
+
+// newStatusReader returns the status reader of the applier, which uses the
+// readiness mappings for the kinds with one, and the kstatus status readers
+// for the other kinds.
+func newStatusReader(mapper meta.RESTMapper) engine.StatusReader {
+	return statusreaders.NewStatusReader(mapper, &readinessStatusReader{
+		StatusReader: statusreaders.NewGenericStatusReader(mapper, computeReadiness),
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+func TestComputeReadiness(t *testing.T) {
+	newObj := func(apiVersion, kind string, conditions ...interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":       "obj",
+				"namespace":  "default",
+				"generation": int64(2),
+			},
+		}}
+		if conditions != nil {
+			u.Object["status"] = map[string]interface{}{
+				"observedGeneration": int64(2),
+				"conditions":         conditions,
+			}
+		}
+		return u
+	}
+	ready := func(status, reason string) interface{} {
+		return map[string]interface{}{"type": "Ready", "status": status, "reason": reason, "message": "msg"}
+	}
+
+	testCases := []struct {
+		name string
+		obj  *unstructured.Unstructured
+		want kstatus.Status
+	}{
+		{
+			name: "Config Connector resource ready",
+			obj:  newObj("storage.cnrm.cloud.google.com/v1beta1", "StorageBucket", ready("True", "UpToDate")),
+			want: kstatus.CurrentStatus,
+		},
+		{
+			name: "Config Connector resource updating",
+			obj:  newObj("storage.cnrm.cloud.google.com/v1beta1", "StorageBucket", ready("False", "Updating")),
+			want: kstatus.InProgressStatus,
+		},
+		{
+			name: "Config Connector resource failed",
+			obj:  newObj("storage.cnrm.cloud.google.com/v1beta1", "StorageBucket", ready("False", "UpdateFailed")),
+			want: kstatus.FailedStatus,
+		},
+		{
+			name: "Config Connector resource without status",
+			obj:  newObj("storage.cnrm.cloud.google.com/v1beta1", "StorageBucket"),
+			want: kstatus.InProgressStatus,
+		},
+		{
+			name: "cert-manager Certificate issuing",
+			obj:  newObj("cert-manager.io/v1", "Certificate", ready("False", "DoesNotExist")),
+			want: kstatus.InProgressStatus,
+		},
+		{
+			name: "cert-manager Certificate ready",
+			obj:  newObj("cert-manager.io/v1", "Certificate", ready("True", "Ready")),
+			want: kstatus.CurrentStatus,
+		},
+		{
+			name: "observed generation takes precedence",
+			obj: func() *unstructured.Unstructured {
+				u := newObj("cert-manager.io/v1", "Certificate", ready("True", "Ready"))
+				u.SetGeneration(3)
+				return u
+			}(),
+			want: kstatus.InProgressStatus,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := computeReadiness(tc.obj)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, result.Status)
+		})
+	}
+}
+
+func TestReadinessStatusReaderSupports(t *testing.T) {
+	r := &readinessStatusReader{}
+	assert.True(t, r.Supports(schema.GroupKind{Group: "iam.cnrm.cloud.google.com", Kind: "IAMPolicyMember"}))
+	assert.True(t, r.Supports(schema.GroupKind{Group: "cert-manager.io", Kind: "Issuer"}))
+	assert.False(t, r.Supports(schema.GroupKind{Group: "acme.cert-manager.io", Kind: "Order"}))
+	assert.False(t, r.Supports(schema.GroupKind{Group: "apps", Kind: "Deployment"}))
+}
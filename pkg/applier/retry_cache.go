@@ -0,0 +1,122 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// declaredCommit returns the commit the objects are declared in, as set in
+// their token annotation by the parser, or empty if it is unknown.
+func declaredCommit(objs []client.Object) string {
+	for _, obj := range objs {
+		if commit := core.GetAnnotation(obj, metadata.SyncTokenAnnotationKey); commit != "" {
+			return commit
+		}
+	}
+	return ""
+}
+
+// retryApplyObjects returns the subset of the resources to apply when
+// retrying the commit of a failed apply, and whether the apply is partial.
+// The objects which were already applied and reconciled for the commit are
+// skipped, unless they changed or depend on an object which is retried. All
+// the resources are applied if the commit was not applied before, or if
+// nothing can be skipped.
+func (a *supervisor) retryApplyObjects(commit string, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, bool) {
+	if a.clientSet.PartialKptApplier == nil || commit == "" || commit != a.retryCommit || len(a.retrySucceeded) == 0 {
+		return resources, false
+	}
+
+	declared := make(map[core.ID]*unstructured.Unstructured, len(resources))
+	affected := make(map[core.ID]bool)
+	for _, resource := range resources {
+		id := core.IDOf(resource)
+		declared[id] = resource
+		if last, found := a.retrySucceeded[id]; found && equality.Semantic.DeepEqual(last.Object, resource.Object) {
+			continue
+		}
+		affected[id] = true
+	}
+	addDependents(declared, affected)
+
+	if len(affected) == len(resources) {
+		return resources, false
+	}
+	var retry []*unstructured.Unstructured
+	for _, resource := range resources {
+		if affected[core.IDOf(resource)] {
+			retry = append(retry, resource)
+		}
+	}
+	klog.Infof("Retrying %d of %d objects of commit %s, skipping the ones already applied", len(retry), len(resources), commit)
+	return retry, true
+}
+
+// recordRetryResults remembers the objects which were applied and reconciled
+// by a failed apply of the commit, so that retrying the commit skips them.
+// The results of the earlier failed applies of the same commit are kept for
+// the objects which were not applied again. A successful apply, or a prune
+// which did not succeed, forgets the results, since the prunes are only
+// retried by full applies.
+func (a *supervisor) recordRetryResults(commit string, resources []*unstructured.Unstructured, succeeded bool, objStatusMap ObjectStatusMap) {
+	if succeeded || commit == "" {
+		a.retryCommit, a.retrySucceeded = "", nil
+		return
+	}
+	for id, objStatus := range objStatusMap {
+		if objStatus != nil && objStatus.Strategy == actuation.ActuationStrategyDelete && objStatus.Actuation != actuation.ActuationSucceeded {
+			klog.V(3).Infof("Retrying all the objects of commit %s, since %s was not pruned", commit, id)
+			a.retryCommit, a.retrySucceeded = "", nil
+			return
+		}
+	}
+	if commit != a.retryCommit || a.retrySucceeded == nil {
+		a.retryCommit = commit
+		a.retrySucceeded = make(map[core.ID]*unstructured.Unstructured)
+	}
+	for _, resource := range resources {
+		id := core.IDOf(resource)
+		objStatus, found := objStatusMap[id]
+		if !found || objStatus == nil {
+			continue
+		}
+		if appliedAndReconciled(objStatus) {
+			a.retrySucceeded[id] = resource
+		} else {
+			delete(a.retrySucceeded, id)
+		}
+	}
+}
+
+// appliedAndReconciled returns true if the object was applied, and did not
+// fail to reconcile.
+func appliedAndReconciled(objStatus *ObjectStatus) bool {
+	if objStatus.Strategy != actuation.ActuationStrategyApply || objStatus.Actuation != actuation.ActuationSucceeded {
+		return false
+	}
+	switch objStatus.Reconcile {
+	case actuation.ReconcileFailed, actuation.ReconcileTimeout, actuation.ReconcilePending:
+		return false
+	default:
+		return true
+	}
+}
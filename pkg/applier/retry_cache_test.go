@@ -0,0 +1,185 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRetryApplyObjects(t *testing.T) {
+	cm := func(name, commit, value string, opts ...core.MetaMutator) *unstructured.Unstructured {
+		opts = append(opts, core.Name(name), core.Namespace("default"),
+			core.Annotation(metadata.SyncTokenAnnotationKey, commit))
+		obj := fake.UnstructuredObject(kinds.ConfigMap(), opts...)
+		obj.Object["data"] = map[string]interface{}{"key": value}
+		return obj
+	}
+	dependsOnB := core.Annotation(dependson.Annotation, "/namespaces/default/ConfigMap/b")
+	resources := []*unstructured.Unstructured{
+		cm("a", "abc123", "1"),
+		cm("b", "abc123", "1"),
+		cm("c", "abc123", "1", dependsOnB),
+		cm("d", "abc123", "1"),
+	}
+	applied := func(reconcile actuation.ReconcileStatus) *ObjectStatus {
+		return &ObjectStatus{Strategy: actuation.ActuationStrategyApply, Actuation: actuation.ActuationSucceeded, Reconcile: reconcile}
+	}
+	failed := &ObjectStatus{Strategy: actuation.ActuationStrategyApply, Actuation: actuation.ActuationFailed}
+	skipped := &ObjectStatus{Strategy: actuation.ActuationStrategyApply, Actuation: actuation.ActuationSkipped}
+	// b failed, so c, which depends on it, was skipped.
+	lastStatus := ObjectStatusMap{
+		core.IDOf(resources[0]): applied(actuation.ReconcileSucceeded),
+		core.IDOf(resources[1]): failed,
+		core.IDOf(resources[2]): skipped,
+		core.IDOf(resources[3]): applied(actuation.ReconcileSucceeded),
+	}
+
+	testCases := []struct {
+		name        string
+		lastStatus  ObjectStatusMap
+		lastFailed  bool
+		commit      string
+		resources   []*unstructured.Unstructured
+		wantPartial bool
+		wantNames   []string
+	}{
+		{
+			name:        "retry of the same commit",
+			lastStatus:  lastStatus,
+			lastFailed:  true,
+			commit:      "abc123",
+			resources:   resources,
+			wantPartial: true,
+			wantNames:   []string{"b", "c"},
+		},
+		{
+			name:       "retry of the same commit with a changed object",
+			lastStatus: lastStatus,
+			lastFailed: true,
+			commit:     "abc123",
+			resources: []*unstructured.Unstructured{
+				resources[0], resources[1], resources[2], cm("d", "abc123", "2"),
+			},
+			wantPartial: true,
+			wantNames:   []string{"b", "c", "d"},
+		},
+		{
+			name: "object not reconciled",
+			lastStatus: ObjectStatusMap{
+				core.IDOf(resources[0]): applied(actuation.ReconcileSucceeded),
+				core.IDOf(resources[1]): applied(actuation.ReconcileTimeout),
+				core.IDOf(resources[2]): applied(actuation.ReconcileSucceeded),
+				core.IDOf(resources[3]): failed,
+			},
+			lastFailed:  true,
+			commit:      "abc123",
+			resources:   resources,
+			wantPartial: true,
+			wantNames:   []string{"b", "c", "d"},
+		},
+		{
+			name: "prune failed",
+			lastStatus: ObjectStatusMap{
+				core.IDOf(resources[0]): applied(actuation.ReconcileSucceeded),
+				core.IDOf(cm("e", "abc123", "1")): {
+					Strategy:  actuation.ActuationStrategyDelete,
+					Actuation: actuation.ActuationFailed,
+				},
+			},
+			lastFailed: true,
+			commit:     "abc123",
+			resources:  resources,
+			wantNames:  []string{"a", "b", "c", "d"},
+		},
+		{
+			name:       "new commit",
+			lastStatus: lastStatus,
+			lastFailed: true,
+			commit:     "def456",
+			resources:  resources,
+			wantNames:  []string{"a", "b", "c", "d"},
+		},
+		{
+			name:       "last apply succeeded",
+			lastStatus: lastStatus,
+			commit:     "abc123",
+			resources:  resources,
+			wantNames:  []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newPartialApplySupervisor(t, false)
+			a.recordRetryResults("abc123", resources, !tc.lastFailed, tc.lastStatus)
+
+			got, partial := a.retryApplyObjects(tc.commit, tc.resources)
+			assert.Equal(t, tc.wantPartial, partial)
+			var gotNames []string
+			for _, obj := range got {
+				gotNames = append(gotNames, obj.GetName())
+			}
+			assert.Equal(t, tc.wantNames, gotNames)
+		})
+	}
+}
+
+func TestRetryResultsAccumulate(t *testing.T) {
+	a := newPartialApplySupervisor(t, false)
+	obj := func(name string) *unstructured.Unstructured {
+		return fake.UnstructuredObject(kinds.ConfigMap(), core.Name(name), core.Namespace("default"),
+			core.Annotation(metadata.SyncTokenAnnotationKey, "abc123"))
+	}
+	resources := []*unstructured.Unstructured{obj("a"), obj("b"), obj("c")}
+	applied := &ObjectStatus{Strategy: actuation.ActuationStrategyApply, Actuation: actuation.ActuationSucceeded, Reconcile: actuation.ReconcileSucceeded}
+	failed := &ObjectStatus{Strategy: actuation.ActuationStrategyApply, Actuation: actuation.ActuationFailed}
+
+	// The first apply only applies a, and the retry of b and c only applies b.
+	a.recordRetryResults("abc123", resources, false, ObjectStatusMap{
+		core.IDOf(resources[0]): applied,
+		core.IDOf(resources[1]): failed,
+		core.IDOf(resources[2]): failed,
+	})
+	a.recordRetryResults("abc123", resources, false, ObjectStatusMap{
+		core.IDOf(resources[1]): applied,
+		core.IDOf(resources[2]): failed,
+	})
+	got, partial := a.retryApplyObjects("abc123", resources)
+	assert.True(t, partial)
+	assert.Equal(t, []*unstructured.Unstructured{resources[2]}, got)
+
+	// A full apply forgets the results.
+	a.RequestFullApply()
+	_, partial = a.retryApplyObjects("abc123", resources)
+	assert.False(t, partial)
+}
+
+func TestDeclaredCommit(t *testing.T) {
+	assert.Equal(t, "", declaredCommit(nil))
+	assert.Equal(t, "abc123", declaredCommit([]client.Object{
+		fake.ConfigMapObject(core.Name("a")),
+		fake.ConfigMapObject(core.Name("b"), core.Annotation(metadata.SyncTokenAnnotationKey, "abc123")),
+	}))
+}
@@ -209,6 +209,36 @@ func (s *DisabledObjStats) Empty() bool {
 	return s == nil || s.Total == 0
 }
 
+// MigratedObjStats tracks the stats for objects migrated from client-side
+// apply
+type MigratedObjStats struct {
+	// Total tracks the number of objects to be migrated
+	Total uint64
+	// Succeeded tracks how many objects were migrated successfully
+	Succeeded uint64
+}
+
+// Add records the result of a migration.
+func (s *MigratedObjStats) Add(succeeded bool) {
+	s.Total++
+	if succeeded {
+		s.Succeeded++
+	}
+}
+
+// String returns the stats as a human readable String.
+func (s MigratedObjStats) String() string {
+	if s.Empty() {
+		return ""
+	}
+	return fmt.Sprintf("migrated %d out of %d objects from client-side apply", s.Succeeded, s.Total)
+}
+
+// Empty returns true if no events were recorded.
+func (s *MigratedObjStats) Empty() bool {
+	return s == nil || s.Total == 0
+}
+
 // SyncStats tracks the stats for all the events
 type SyncStats struct {
 	ApplyEvent  *ApplyEventStats
@@ -216,6 +246,7 @@ type SyncStats struct {
 	DeleteEvent *DeleteEventStats
 	WaitEvent   *WaitEventStats
 	DisableObjs *DisabledObjStats
+	MigrateObjs *MigratedObjStats
 	// ErrorTypeEvents tracks the number of ErrorType events
 	ErrorTypeEvents uint64
 }
@@ -238,6 +269,9 @@ func (s SyncStats) String() string {
 	if !s.DisableObjs.Empty() {
 		strs = append(strs, s.DisableObjs.String())
 	}
+	if !s.MigrateObjs.Empty() {
+		strs = append(strs, s.MigrateObjs.String())
+	}
 	if s.ErrorTypeEvents > 0 {
 		strs = append(strs, fmt.Sprintf("ErrorEvents: %d", s.ErrorTypeEvents))
 	}
@@ -246,7 +280,7 @@ func (s SyncStats) String() string {
 
 // Empty returns true if no events were recorded.
 func (s *SyncStats) Empty() bool {
-	return s == nil || s.ErrorTypeEvents == 0 && s.PruneEvent.Empty() && s.DeleteEvent.Empty() && s.ApplyEvent.Empty() && s.WaitEvent.Empty() && s.DisableObjs.Empty()
+	return s == nil || s.ErrorTypeEvents == 0 && s.PruneEvent.Empty() && s.DeleteEvent.Empty() && s.ApplyEvent.Empty() && s.WaitEvent.Empty() && s.DisableObjs.Empty() && s.MigrateObjs.Empty()
 }
 
 // NewSyncStats constructs a SyncStats with empty event maps.
@@ -257,5 +291,6 @@ func NewSyncStats() *SyncStats {
 		DeleteEvent: &DeleteEventStats{},
 		WaitEvent:   &WaitEventStats{},
 		DisableObjs: &DisabledObjStats{},
+		MigrateObjs: &MigratedObjStats{},
 	}
 }
@@ -0,0 +1,257 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadUpdateHeldErrorCode is the error code for the updates of managed
+// workloads which are held back, because they would restart the pods of the
+// workload while too many workloads are rolling out.
+const WorkloadUpdateHeldErrorCode = "2030"
+
+var workloadUpdateHeldErrorBuilder = status.NewErrorBuilder(WorkloadUpdateHeldErrorCode)
+
+// WorkloadUpdateHeldError indicates that the update of the given workload is
+// held back, and retried once the other workloads finished rolling out.
+func WorkloadUpdateHeldError(workload client.Object, reason string) status.Error {
+	return workloadUpdateHeldErrorBuilder.
+		Sprintf("holding back the update of %v, because %s. It is applied once the other workloads finished rolling out",
+			core.IDOf(workload), reason).
+		BuildWithResources(workload)
+}
+
+// workloadKinds are the kinds of the workloads whose pods are restarted when
+// their pod template changes.
+var workloadKinds = map[schema.GroupKind]struct{}{
+	kinds.Deployment().GroupKind():  {},
+	kinds.StatefulSet().GroupKind(): {},
+	kinds.DaemonSet().GroupKind():   {},
+}
+
+// pacesRollouts returns true if the rollouts of the managed workloads are
+// limited.
+func (cs *ClientSet) pacesRollouts() bool {
+	return cs.MaxWorkloadRollouts > 0 || cs.MaxNamespaceRollouts > 0
+}
+
+// paceWorkloadUpdates holds back the updates of the declared workloads which
+// would restart their pods, while the number of the managed workloads rolling
+// out reaches the rollout limits, or while a PodDisruptionBudget of the pods
+// allows no disruption. The pod templates of the held workloads are replaced
+// with the ones they were last applied with, so that their other fields, e.g.
+// their replicas, are still updated, and an error is recorded for each of
+// them, so that the reconciler retries until all of them are applied.
+//
+// A workload which never finishes rolling out, e.g. because of a bad image,
+// keeps holding back the others, so that a bad change does not break all the
+// workloads.
+func (a *supervisor) paceWorkloadUpdates(ctx context.Context, resources []*unstructured.Unstructured) status.MultiError {
+	if !a.clientSet.pacesRollouts() {
+		return nil
+	}
+	type workload struct {
+		index int
+		live  *unstructured.Unstructured
+	}
+	var updated []workload
+	rollouts := 0
+	namespaceRollouts := make(map[string]int)
+	for i, resource := range resources {
+		if _, found := workloadKinds[resource.GroupVersionKind().GroupKind()]; !found {
+			continue
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(resource.GroupVersionKind())
+		if err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(resource), live); err != nil {
+			if apierrors.IsNotFound(err) {
+				// Creating a workload does not restart any pod.
+				continue
+			}
+			return status.APIServerError(err, "failed to get the workload to pace its rollout", resource)
+		}
+		if isRollingOut(live) {
+			rollouts++
+			namespaceRollouts[live.GetNamespace()]++
+		}
+		if podTemplateChanged(resource, live) {
+			updated = append(updated, workload{index: i, live: live})
+		}
+	}
+
+	for _, w := range updated {
+		resource := resources[w.index]
+		namespace := resource.GetNamespace()
+		reason, err := a.holdReason(ctx, w.live, rollouts, namespaceRollouts[namespace])
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			if !isRollingOut(w.live) {
+				rollouts++
+				namespaceRollouts[namespace]++
+			}
+			continue
+		}
+		klog.Infof("Holding back the update of %s, because %s", core.IDOf(resource), reason)
+		resources[w.index] = a.heldWorkload(resource, w.live)
+		a.addError(WorkloadUpdateHeldError(resource, reason))
+	}
+	return nil
+}
+
+// holdReason returns why the update of the live workload must be held back,
+// or an empty string if it can be rolled out.
+func (a *supervisor) holdReason(ctx context.Context, live *unstructured.Unstructured, rollouts, namespaceRollouts int) (string, status.Error) {
+	if isRollingOut(live) {
+		// Updating a workload which is already rolling out does not start
+		// another rollout.
+		return "", nil
+	}
+	if limit := a.clientSet.MaxWorkloadRollouts; limit > 0 && rollouts >= limit {
+		return fmt.Sprintf("%d managed workloads are rolling out, the maximum of spec.rollout.maxConcurrentUpdates", rollouts), nil
+	}
+	if limit := a.clientSet.MaxNamespaceRollouts; limit > 0 && namespaceRollouts >= limit {
+		return fmt.Sprintf("%d managed workloads of the namespace %q are rolling out, the maximum of spec.rollout.maxConcurrentUpdatesPerNamespace",
+			namespaceRollouts, live.GetNamespace()), nil
+	}
+	pdb, err := a.blockingDisruptionBudget(ctx, live)
+	if err != nil {
+		return "", err
+	}
+	if pdb != "" {
+		return fmt.Sprintf("the PodDisruptionBudget %q allows no disruption of its pods", pdb), nil
+	}
+	return "", nil
+}
+
+// blockingDisruptionBudget returns the name of a PodDisruptionBudget selecting
+// the pods of the workload which allows no disruption, or an empty string if
+// there is none. Unhealthy workloads are never blocked, since their rollout
+// may be what fixes them.
+func (a *supervisor) blockingDisruptionBudget(ctx context.Context, live *unstructured.Unstructured) (string, status.Error) {
+	if result, err := kstatus.Compute(live); err != nil || result.Status != kstatus.CurrentStatus {
+		return "", nil
+	}
+	podLabels, _, err := unstructured.NestedStringMap(live.Object, "spec", "template", "metadata", "labels")
+	if err != nil || len(podLabels) == 0 {
+		return "", nil
+	}
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := a.clientSet.Client.List(ctx, pdbs, client.InNamespace(live.GetNamespace())); err != nil {
+		if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) {
+			// Namespace reconcilers may not be allowed to list the
+			// PodDisruptionBudgets.
+			klog.V(3).Infof("Skipping the PodDisruptionBudgets of %s: %v", core.IDOf(live), err)
+			return "", nil
+		}
+		return "", status.APIServerError(err, "failed to list the PodDisruptionBudgets to pace the rollout", live)
+	}
+	for _, pdb := range pdbs.Items {
+		if pdb.Spec.Selector == nil || pdb.Status.DisruptionsAllowed > 0 {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+		return pdb.Name, nil
+	}
+	return "", nil
+}
+
+// heldWorkload returns a copy of the declared workload whose pod template is
+// replaced with the last applied one, or with the live one if the reconciler
+// restarted since, so that applying it does not restart the pods.
+func (a *supervisor) heldWorkload(resource, live *unstructured.Unstructured) *unstructured.Unstructured {
+	template, _, _ := unstructured.NestedFieldCopy(live.Object, "spec", "template")
+	if lastApplied, found := a.lastApplied[core.IDOf(resource)]; found && !podTemplateChanged(lastApplied, live) {
+		template, _, _ = unstructured.NestedFieldCopy(lastApplied.Object, "spec", "template")
+	}
+	held := resource.DeepCopy()
+	if err := unstructured.SetNestedField(held.Object, template, "spec", "template"); err != nil {
+		klog.Warningf("Failed to hold back the pod template of %s: %v", core.IDOf(resource), err)
+		return resource
+	}
+	return held
+}
+
+// isRollingOut returns true if the live workload has not finished rolling out.
+func isRollingOut(live *unstructured.Unstructured) bool {
+	result, err := kstatus.Compute(live)
+	return err == nil && result.Status == kstatus.InProgressStatus
+}
+
+// podTemplateChanged returns true if applying the declared workload changes
+// the pod template of the live workload, which restarts its pods. The fields
+// of the live pod template which are not declared, e.g. the defaulted ones,
+// are ignored.
+func podTemplateChanged(declared, live *unstructured.Unstructured) bool {
+	declaredTemplate, _, _ := unstructured.NestedFieldNoCopy(declared.Object, "spec", "template")
+	liveTemplate, _, _ := unstructured.NestedFieldNoCopy(live.Object, "spec", "template")
+	return !isSubset(declaredTemplate, liveTemplate)
+}
+
+// isSubset returns true if all the fields of the declared value are set to the
+// same values in the live value.
+func isSubset(declared, live interface{}) bool {
+	switch d := declared.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		for k, v := range d {
+			if !isSubset(v, l[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		if len(d) != len(l) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return true
+	default:
+		return reflect.DeepEqual(declared, live) || fmt.Sprint(declared) == fmt.Sprint(live)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deploymentObject returns a Deployment running the image, which is rolled
+// out unless rollingOut is true.
+func deploymentObject(t *testing.T, name, image string, rollingOut bool) *unstructured.Unstructured {
+	t.Helper()
+	u := fake.UnstructuredObject(kinds.Deployment(), core.Name(name), core.Namespace("foo"))
+	require.NoError(t, unstructured.SetNestedField(u.Object, int64(1), "spec", "replicas"))
+	require.NoError(t, unstructured.SetNestedField(u.Object, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": name},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": image},
+			},
+		},
+	}, "spec", "template"))
+	updated := int64(1)
+	if rollingOut {
+		updated = 0
+	}
+	require.NoError(t, unstructured.SetNestedField(u.Object, map[string]interface{}{
+		"observedGeneration": int64(1),
+		"replicas":           int64(1),
+		"updatedReplicas":    updated,
+		"readyReplicas":      int64(1),
+		"availableReplicas":  int64(1),
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+			map[string]interface{}{"type": "Available", "status": "True"},
+		},
+	}, "status"))
+	return u
+}
+
+func podImage(t *testing.T, u *unstructured.Unstructured) string {
+	t.Helper()
+	containers, _, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	return containers[0].(map[string]interface{})["image"].(string)
+}
+
+func TestPodTemplateChanged(t *testing.T) {
+	live := deploymentObject(t, "app", "app:v1", false)
+	// Defaulted fields of the live pod template are ignored.
+	require.NoError(t, unstructured.SetNestedField(live.Object, "Always", "spec", "template", "spec", "restartPolicy"))
+
+	assert.False(t, podTemplateChanged(deploymentObject(t, "app", "app:v1", false), live))
+	assert.True(t, podTemplateChanged(deploymentObject(t, "app", "app:v2", false), live))
+}
+
+func TestPaceWorkloadUpdates(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		maxWorkloadRollouts  int
+		maxNamespaceRollouts int
+		live                 []client.Object
+		wantImages           map[string]string
+		wantHeld             int
+	}{
+		{
+			name:                "updates within the limit are applied",
+			maxWorkloadRollouts: 2,
+			live: []client.Object{
+				deploymentObject(t, "a", "app:v1", false),
+				deploymentObject(t, "b", "app:v1", false),
+			},
+			wantImages: map[string]string{"a": "app:v2", "b": "app:v2"},
+		},
+		{
+			name:                "updates above the limit are held",
+			maxWorkloadRollouts: 1,
+			live: []client.Object{
+				deploymentObject(t, "a", "app:v1", false),
+				deploymentObject(t, "b", "app:v1", false),
+			},
+			wantImages: map[string]string{"a": "app:v2", "b": "app:v1"},
+			wantHeld:   1,
+		},
+		{
+			name:                 "workloads rolling out count against the namespace limit",
+			maxNamespaceRollouts: 1,
+			live: []client.Object{
+				deploymentObject(t, "a", "app:v1", true),
+				deploymentObject(t, "b", "app:v1", false),
+			},
+			wantImages: map[string]string{"a": "app:v2", "b": "app:v1"},
+			wantHeld:   1,
+		},
+		{
+			name:                "PodDisruptionBudgets allowing no disruption hold the update",
+			maxWorkloadRollouts: 2,
+			live: []client.Object{
+				deploymentObject(t, "a", "app:v1", false),
+				deploymentObject(t, "b", "app:v1", false),
+				&policyv1.PodDisruptionBudget{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "policy/v1", Kind: "PodDisruptionBudget"},
+					ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "foo"},
+					Spec: policyv1.PodDisruptionBudgetSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "b"}},
+					},
+				},
+			},
+			wantImages: map[string]string{"a": "app:v2", "b": "app:v1"},
+			wantHeld:   1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := testingfake.NewClient(t, core.Scheme, tc.live...)
+			cs := &ClientSet{
+				KptApplier:           newFakeKptApplier(nil),
+				InvClient:            inventory.NewFakeClient(object.ObjMetadataSet{}),
+				Client:               fakeClient,
+				Mapper:               fakeClient.RESTMapper(),
+				MaxWorkloadRollouts:  tc.maxWorkloadRollouts,
+				MaxNamespaceRollouts: tc.maxNamespaceRollouts,
+			}
+			s, err := NewRootSupervisor(cs, configsync.RootSyncName, 5*time.Minute)
+			require.NoError(t, err)
+			a := s.(*supervisor)
+
+			resources := []*unstructured.Unstructured{
+				deploymentObject(t, "a", "app:v2", false),
+				deploymentObject(t, "b", "app:v2", false),
+			}
+			require.Nil(t, a.paceWorkloadUpdates(context.Background(), resources))
+			for _, resource := range resources {
+				assert.Equal(t, tc.wantImages[resource.GetName()], podImage(t, resource), resource.GetName())
+			}
+			if tc.wantHeld == 0 {
+				assert.Nil(t, a.Errors())
+				return
+			}
+			require.Len(t, a.Errors().Errors(), tc.wantHeld)
+			assert.Equal(t, WorkloadUpdateHeldErrorCode, a.Errors().Errors()[0].(status.Error).Code())
+			assert.Equal(t, status.TransientErrorClass, status.Classify(a.Errors()))
+		})
+	}
+}
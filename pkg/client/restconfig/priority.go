@@ -0,0 +1,43 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restconfig
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+)
+
+// SetPriorityGroup modifies a rest.Config to impersonate the specified
+// service account with the group added, so that a FlowSchema can match the
+// requests by the group and assign them to a priority level.
+//
+// The groups of a service account are replaced when it is impersonated, so
+// the groups the API server assigns to service accounts are impersonated as
+// well.
+func SetPriorityGroup(config *rest.Config, namespace, serviceAccount, group string) {
+	if group == "" {
+		return
+	}
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount),
+		Groups: []string{
+			"system:serviceaccounts",
+			"system:serviceaccounts:" + namespace,
+			"system:authenticated",
+			group,
+		},
+	}
+}
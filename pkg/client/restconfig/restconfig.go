@@ -71,6 +71,24 @@ func NewRestConfig(timeout time.Duration) (*rest.Config, error) {
 	return config, nil
 }
 
+// NewTargetRestConfig returns a REST config to talk to the cluster that
+// resources are synced to. If kubeconfigPath is empty, the target is the
+// current cluster, and the config is built by NewRestConfig. Otherwise, the
+// config is built from the kube config file at kubeconfigPath.
+func NewTargetRestConfig(kubeconfigPath string, timeout time.Duration) (*rest.Config, error) {
+	if kubeconfigPath == "" {
+		return NewRestConfig(timeout)
+	}
+	config, err := NewFromConfigFile(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if timeout != 0 {
+		config.Timeout = timeout
+	}
+	return config, nil
+}
+
 // NewFromConfigFile returns a REST config built from the kube config file at
 // the specified path.
 func NewFromConfigFile(path string) (*rest.Config, error) {
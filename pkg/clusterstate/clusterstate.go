@@ -0,0 +1,220 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterstate captures a lightweight state of the managed objects on
+// the cluster, i.e. their hashes and health, before and after an apply, and
+// publishes the difference as an immutable ConfigMap, as an audit record of
+// what each commit changed on the cluster.
+package clusterstate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DiffKey is the key of the JSON diff in the data of a diff ConfigMap.
+	DiffKey = "diff.json"
+
+	// Retention is the number of diffs kept for each reconciler. The oldest
+	// diffs are deleted when new ones are published.
+	Retention = 20
+)
+
+// ChangeType is the type of the change of an object between two states.
+type ChangeType string
+
+const (
+	// Created means the object did not exist before the apply.
+	Created = ChangeType("Created")
+	// Updated means the content of the object changed.
+	Updated = ChangeType("Updated")
+	// Deleted means the object no longer exists after the apply.
+	Deleted = ChangeType("Deleted")
+	// HealthChanged means only the health of the object changed.
+	HealthChanged = ChangeType("HealthChanged")
+)
+
+// Entry is the state of an object on the cluster.
+type Entry struct {
+	// Hash is the hash of the content of the object, without its status and
+	// the metadata set by the API server.
+	Hash string `json:"hash"`
+	// Status is the health of the object, as computed by kstatus.
+	Status kstatus.Status `json:"status"`
+}
+
+// State is the state of a set of objects on the cluster. Objects which do not
+// exist are omitted.
+type State map[core.ID]Entry
+
+// Change is the change of an object between two states.
+type Change struct {
+	// ID identifies the object, as `<group>/<kind>/<namespace>/<name>`.
+	ID string `json:"id"`
+	// Type is the type of the change.
+	Type ChangeType `json:"type"`
+	// Before is the state of the object before the apply, if it existed.
+	Before *Entry `json:"before,omitempty"`
+	// After is the state of the object after the apply, if it exists.
+	After *Entry `json:"after,omitempty"`
+}
+
+// Diff is the published record of the changes made by an apply.
+type Diff struct {
+	// Commit is the commit which was applied.
+	Commit string `json:"commit"`
+	// AppliedAt is when the apply finished.
+	AppliedAt time.Time `json:"appliedAt"`
+	// Changes are the changes of the objects, sorted by ID.
+	Changes []Change `json:"changes"`
+}
+
+// Capture returns the state of the objects on the cluster. Objects of kinds
+// which are not served are treated as missing.
+func Capture(ctx context.Context, c client.Reader, objs []*unstructured.Unstructured) (State, error) {
+	state := make(State, len(objs))
+	for _, obj := range objs {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), u); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get %s", core.IDOf(obj))
+		}
+		entry, err := entryOf(u)
+		if err != nil {
+			return nil, err
+		}
+		state[core.IDOf(u)] = entry
+	}
+	return state, nil
+}
+
+// entryOf returns the state of the object.
+func entryOf(u *unstructured.Unstructured) (Entry, error) {
+	content := u.DeepCopy()
+	unstructured.RemoveNestedField(content.Object, "status")
+	for _, field := range []string{"resourceVersion", "generation", "uid", "creationTimestamp", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(content.Object, "metadata", field)
+	}
+	// The commit token changes with every commit, even if the object does not.
+	core.RemoveAnnotations(content, metadata.SyncTokenAnnotationKey)
+	data, err := json.Marshal(content.Object)
+	if err != nil {
+		return Entry{}, errors.Wrapf(err, "failed to hash %s", core.IDOf(u))
+	}
+	entry := Entry{
+		Hash:   fmt.Sprintf("%x", sha256.Sum256(data)),
+		Status: kstatus.UnknownStatus,
+	}
+	if result, err := kstatus.Compute(u); err == nil {
+		entry.Status = result.Status
+	}
+	return entry, nil
+}
+
+// Compare returns the changes of the objects from the before state to the
+// after state, sorted by ID. Unchanged objects are omitted.
+func Compare(before, after State) []Change {
+	var changes []Change
+	for id, b := range before {
+		b := b
+		a, found := after[id]
+		switch {
+		case !found:
+			changes = append(changes, Change{ID: id.String(), Type: Deleted, Before: &b})
+		case a.Hash != b.Hash:
+			changes = append(changes, Change{ID: id.String(), Type: Updated, Before: &b, After: &a})
+		case a.Status != b.Status:
+			changes = append(changes, Change{ID: id.String(), Type: HealthChanged, Before: &b, After: &a})
+		}
+	}
+	for id, a := range after {
+		a := a
+		if _, found := before[id]; !found {
+			changes = append(changes, Change{ID: id.String(), Type: Created, After: &a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// Name returns the name of the ConfigMap holding the diff of the reconciler
+// published at the given time. Names sort in publication order.
+func Name(reconcilerName string, now time.Time) string {
+	return fmt.Sprintf("%s-diff-%016x", reconcilerName, now.UnixNano())
+}
+
+// Publish stores the diff in a new immutable ConfigMap in the namespace, and
+// deletes the oldest diffs of the reconciler beyond the Retention.
+func Publish(ctx context.Context, c client.Client, namespace, reconcilerName string, diff Diff) error {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the cluster state diff")
+	}
+	immutable := true
+	cm := &corev1.ConfigMap{}
+	cm.Name = Name(reconcilerName, diff.AppliedAt)
+	cm.Namespace = namespace
+	core.SetLabel(cm, metadata.ClusterStateDiffLabel, "true")
+	core.SetLabel(cm, metadata.ReconcilerLabel, reconcilerName)
+	core.SetLabel(cm, metadata.ManagedByKey, metadata.ManagedByValue)
+	core.SetAnnotation(cm, metadata.SnapshotCommitAnnotationKey, diff.Commit)
+	cm.Data = map[string]string{DiffKey: string(data)}
+	cm.Immutable = &immutable
+	if err := c.Create(ctx, cm); err != nil {
+		return errors.Wrapf(err, "failed to create the cluster state diff of commit %s", diff.Commit)
+	}
+	return prune(ctx, c, namespace, reconcilerName)
+}
+
+// prune deletes the oldest diffs of the reconciler beyond the Retention.
+func prune(ctx context.Context, c client.Client, namespace, reconcilerName string) error {
+	cmList := &corev1.ConfigMapList{}
+	if err := c.List(ctx, cmList, client.InNamespace(namespace), client.MatchingLabels{
+		metadata.ClusterStateDiffLabel: "true",
+		metadata.ReconcilerLabel:       reconcilerName,
+	}); err != nil {
+		return errors.Wrap(err, "failed to list the cluster state diffs")
+	}
+	cms := cmList.Items
+	// Sort from the newest to the oldest diff.
+	sort.Slice(cms, func(i, j int) bool {
+		return cms[i].Name > cms[j].Name
+	})
+	for i := Retention; i < len(cms); i++ {
+		if err := c.Delete(ctx, &cms[i]); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the cluster state diff %s/%s", namespace, cms[i].Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterstate
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+func configMapRef(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(kinds.ConfigMap())
+	u.SetNamespace("bookstore")
+	u.SetName(name)
+	return u
+}
+
+func TestCaptureAndCompare(t *testing.T) {
+	ctx := context.Background()
+	c := syncerFake.NewClient(t, core.Scheme,
+		fake.ConfigMapObject(core.Name("kept"), core.Namespace("bookstore"), core.Annotation(metadata.SyncTokenAnnotationKey, "1")),
+		fake.ConfigMapObject(core.Name("updated"), core.Namespace("bookstore"), core.Label("version", "1")),
+		fake.ConfigMapObject(core.Name("deleted"), core.Namespace("bookstore")),
+	)
+	objs := []*unstructured.Unstructured{configMapRef("kept"), configMapRef("updated"), configMapRef("deleted"), configMapRef("created")}
+
+	before, err := Capture(ctx, c, objs)
+	require.NoError(t, err)
+	require.Len(t, before, 3)
+	assert.Equal(t, kstatus.CurrentStatus, before[core.IDOf(configMapRef("kept"))].Status)
+
+	// A new commit token alone does not change the object.
+	require.NoError(t, c.Update(ctx, fake.ConfigMapObject(core.Name("kept"), core.Namespace("bookstore"), core.Annotation(metadata.SyncTokenAnnotationKey, "2"))))
+	require.NoError(t, c.Update(ctx, fake.ConfigMapObject(core.Name("updated"), core.Namespace("bookstore"), core.Label("version", "2"))))
+	require.NoError(t, c.Delete(ctx, fake.ConfigMapObject(core.Name("deleted"), core.Namespace("bookstore"))))
+	require.NoError(t, c.Create(ctx, fake.ConfigMapObject(core.Name("created"), core.Namespace("bookstore"))))
+
+	after, err := Capture(ctx, c, objs)
+	require.NoError(t, err)
+
+	changes := Compare(before, after)
+	var got []ChangeType
+	for _, change := range changes {
+		got = append(got, change.Type)
+	}
+	// Sorted by ID: created, deleted, updated.
+	assert.Equal(t, []ChangeType{Created, Deleted, Updated}, got)
+	assert.Nil(t, changes[0].Before)
+	assert.Nil(t, changes[1].After)
+	assert.NotEqual(t, changes[2].Before.Hash, changes[2].After.Hash)
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	c := syncerFake.NewClient(t, core.Scheme)
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < Retention+2; i++ {
+		diff := Diff{
+			Commit:    "abc123",
+			AppliedAt: start.Add(time.Duration(i) * time.Minute),
+			Changes:   []Change{{ID: "/ConfigMap/bookstore/cm", Type: Created, After: &Entry{Hash: "h", Status: kstatus.CurrentStatus}}},
+		}
+		require.NoError(t, Publish(ctx, c, "bookstore", "ns-reconciler-bookstore", diff))
+	}
+
+	cmList := &corev1.ConfigMapList{}
+	require.NoError(t, c.List(ctx, cmList))
+	require.Len(t, cmList.Items, Retention)
+	for _, cm := range cmList.Items {
+		require.NotEqual(t, Name("ns-reconciler-bookstore", start), cm.Name, "the oldest diffs should be pruned")
+		require.True(t, *cm.Immutable)
+		var diff Diff
+		require.NoError(t, json.Unmarshal([]byte(cm.Data[DiffKey]), &diff))
+		require.Equal(t, "abc123", diff.Commit)
+	}
+}
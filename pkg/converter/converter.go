@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package converter executes the converter plugins, which convert the source
+// files that are not Kubernetes manifests into Kubernetes manifests.
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/util"
+)
+
+const (
+	// DefaultPluginDir is the directory into which the plugin is installed.
+	DefaultPluginDir = "/converter-plugins"
+
+	// timeout is how long the plugin may take to convert a single file.
+	timeout = time.Minute
+)
+
+// Parse decodes the plugin passed to the reconciler container.
+func Parse(s string) (*v1beta1.Converter, error) {
+	plugin := &v1beta1.Converter{}
+	if err := json.Unmarshal([]byte(s), plugin); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the converter plugin")
+	}
+	if plugin.Command == "" {
+		return nil, errors.New("the converter plugin must specify a command")
+	}
+	if !util.ValidPluginCommand(plugin.Command) {
+		return nil, errors.Errorf("the command %q of the converter plugin must be a path relative to the plugin directory", plugin.Command)
+	}
+	if len(plugin.Extensions) == 0 {
+		return nil, errors.New("the converter plugin must specify the extensions of the files it converts")
+	}
+	return plugin, nil
+}
+
+// Encode encodes the plugin to pass it to the reconciler container.
+func Encode(plugin *v1beta1.Converter) string {
+	// The plugin only has string fields, so encoding it never fails.
+	b, _ := json.Marshal(plugin)
+	return string(b)
+}
+
+// Exec executes a converter plugin for each file with one of its extensions.
+type Exec struct {
+	plugin     v1beta1.Converter
+	command    string
+	extensions map[string]bool
+}
+
+var _ reader.Converter = &Exec{}
+
+// NewExec returns an Exec for the plugin installed in dir.
+func NewExec(plugin *v1beta1.Converter, dir string) *Exec {
+	command := filepath.Join(dir, plugin.Command)
+	extensions := make(map[string]bool, len(plugin.Extensions))
+	for _, ext := range plugin.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions[ext] = true
+	}
+	return &Exec{
+		plugin:     *plugin,
+		command:    command,
+		extensions: extensions,
+	}
+}
+
+// Converts implements reader.Converter.
+func (e *Exec) Converts(file cmpath.Absolute) bool {
+	return e.extensions[filepath.Ext(file.OSPath())]
+}
+
+// Convert implements reader.Converter. The plugin is executed in the
+// directory of the file, so that it can resolve the files the converted file
+// refers to.
+func (e *Exec) Convert(file cmpath.Absolute) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := append(append([]string{}, e.plugin.Args...), file.OSPath())
+	cmd := exec.CommandContext(ctx, e.command, args...)
+	cmd.Dir = filepath.Dir(file.OSPath())
+	var vars []string
+	for _, env := range e.plugin.Env {
+		vars = append(vars, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+	cmd.Env = util.PluginEnv(vars...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.Errorf("converter plugin %q timed out after %v", e.plugin.Command, timeout)
+		}
+		return nil, errors.Wrapf(err, "converter plugin %q failed: %s", e.plugin.Command, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+// writePlugin writes a plugin which converts a file listing ConfigMap names
+// into ConfigMaps in the namespace given by its first argument, and fails on
+// a file listing no names, or if it is given the git-sync credentials.
+func writePlugin(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+if [ -n "$GIT_SYNC_PASSWORD" ]; then
+  echo "given the git-sync credentials" >&2
+  exit 1
+fi
+if [ ! -s "$2" ]; then
+  echo "$(basename "$2") is empty" >&2
+  exit 1
+fi
+while read -r name; do
+  printf -- '---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: %s\n  labels:\n    team: %s\n' "$name" "$1" "$TEAM"
+done < "$2"
+`
+	if err := os.WriteFile(filepath.Join(dir, "dsl2krm"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestExecConvert(t *testing.T) {
+	// The environment of the container is not passed to the plugin.
+	t.Setenv("GIT_SYNC_PASSWORD", "secret")
+	pluginDir := writePlugin(t)
+	e := NewExec(&v1beta1.Converter{
+		Command:    "dsl2krm",
+		Args:       []string{"bookstore"},
+		Env:        []v1beta1.ExecEnvVar{{Name: "TEAM", Value: "books"}},
+		Extensions: []string{"dsl"},
+	}, pluginDir)
+
+	testCases := []struct {
+		name     string
+		contents string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "converts the file",
+			contents: "cm-1\ncm-2\n",
+			want: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-1\n  namespace: bookstore\n  labels:\n    team: books\n" +
+				"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-2\n  namespace: bookstore\n  labels:\n    team: books\n",
+		},
+		{
+			name:     "reports the stderr of the failed plugin",
+			contents: "",
+			wantErr:  "config.dsl is empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.dsl")
+			if err := os.WriteFile(file, []byte(tc.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := e.Convert(cmpath.Absolute(file))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got Convert() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got Convert() error = %v, want nil", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got Convert() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecConverts(t *testing.T) {
+	e := NewExec(&v1beta1.Converter{Command: "dsl2krm", Extensions: []string{".dsl", "hcl"}}, DefaultPluginDir)
+
+	for file, want := range map[string]bool{
+		"/repo/config.dsl":  true,
+		"/repo/config.hcl":  true,
+		"/repo/config.yaml": false,
+		"/repo/dsl":         false,
+	} {
+		if got := e.Converts(cmpath.Absolute(file)); got != want {
+			t.Errorf("got Converts(%q) = %t, want %t", file, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	plugin := &v1beta1.Converter{Image: "dsl2krm:v1", Command: "dsl2krm", Extensions: []string{".dsl"}}
+	got, err := Parse(Encode(plugin))
+	if err != nil {
+		t.Fatalf("got Parse() error = %v, want nil", err)
+	}
+	if got.Command != plugin.Command || got.Image != plugin.Image || len(got.Extensions) != 1 {
+		t.Errorf("got Parse() = %+v, want %+v", got, plugin)
+	}
+
+	for _, encoded := range []string{
+		`{"command":"dsl2krm"}`,
+		`{"command":"/bin/sh","extensions":[".dsl"]}`,
+		`{"command":"../bin/sh","extensions":[".dsl"]}`,
+	} {
+		if _, err := Parse(encoded); err == nil {
+			t.Errorf("got Parse(%s) error = nil, want err", encoded)
+		}
+	}
+}
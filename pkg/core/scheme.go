@@ -32,6 +32,7 @@ import (
 	configsyncv1alpha1 "kpt.dev/configsync/pkg/api/configsync/v1alpha1"
 	configsyncv1beta1 "kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	hubv1 "kpt.dev/configsync/pkg/api/hub/v1"
+	resourcegroupv1alpha1 "kpt.dev/resourcegroup/apis/kpt.dev/v1alpha1"
 )
 
 // Scheme is a reference to the global scheme.
@@ -58,6 +59,7 @@ func init() {
 	utilruntime.Must(configmanagementv1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(configsyncv1alpha1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(configsyncv1beta1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(resourcegroupv1alpha1.AddToScheme(scheme.Scheme))
 
 	// Hub/Fleet types
 	utilruntime.Must(hubv1.AddToScheme(scheme.Scheme))
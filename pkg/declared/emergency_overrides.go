@@ -0,0 +1,57 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"time"
+
+	"kpt.dev/configsync/pkg/core"
+)
+
+// SetEmergencyOverride records that the managed object is under an emergency
+// override until the expiry.
+func (r *Resources) SetEmergencyOverride(id core.ID, expiry time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.emergencyOverrides == nil {
+		r.emergencyOverrides = make(map[core.ID]time.Time)
+	}
+	r.emergencyOverrides[id] = expiry
+}
+
+// RemoveEmergencyOverride records that the managed object is not under an
+// emergency override.
+func (r *Resources) RemoveEmergencyOverride(id core.ID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.emergencyOverrides, id)
+}
+
+// EmergencyOverrides returns the IDs of the managed objects under an
+// emergency override which has not yet expired at the given time.
+func (r *Resources) EmergencyOverrides(now time.Time) []core.ID {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var ids []core.ID
+	for id, expiry := range r.emergencyOverrides {
+		if now.Before(expiry) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/structured-merge-diff/v4/schema"
+)
+
+const (
+	// quantityType is the OpenAPI definition of resource.Quantity.
+	quantityType = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+	// durationType is the OpenAPI definition of metav1.Duration.
+	durationType = "io.k8s.apimachinery.pkg.apis.meta.v1.Duration"
+)
+
+// Normalize returns a copy of the object without the formatting and
+// defaulting differences which don't change its meaning, according to the
+// OpenAPI schema of its type:
+//   - fields set to the default value of the schema are removed,
+//   - quantities are formatted canonically, e.g. `1000m` becomes `1`,
+//   - durations are formatted canonically, e.g. `90s` becomes `1m30s`.
+//
+// The normalized object is only meant to be compared with other normalized
+// objects. It must not be applied, since dropping the defaulted fields
+// changes their ownership. Objects of types without a schema are copied as-is.
+func (v *ValueConverter) Normalize(u *unstructured.Unstructured) *unstructured.Unstructured {
+	result := u.DeepCopy()
+	res := v.openAPIResources.LookupResource(u.GroupVersionKind())
+	if res == nil {
+		return result
+	}
+	name := res.GetPath().String()
+	normalizeValue(&v.parser.Schema, schema.TypeRef{NamedType: &name}, result.Object)
+	return result
+}
+
+// normalizeValue normalizes the value of the given type in place, and returns
+// the normalized value, which replaces scalars.
+func normalizeValue(s *schema.Schema, tr schema.TypeRef, value interface{}) interface{} {
+	if tr.NamedType != nil {
+		switch *tr.NamedType {
+		case quantityType:
+			return normalizeQuantity(value)
+		case durationType:
+			return normalizeDuration(value)
+		}
+	}
+	atom, found := s.Resolve(tr)
+	if !found {
+		return value
+	}
+	switch {
+	case atom.Map != nil:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for _, field := range atom.Map.Fields {
+			fieldValue, found := m[field.Name]
+			if !found {
+				continue
+			}
+			if field.Default != nil && jsonEqual(field.Default, fieldValue) {
+				delete(m, field.Name)
+				continue
+			}
+			m[field.Name] = normalizeValue(s, field.Type, fieldValue)
+		}
+		if atom.Map.ElementType.NamedType == nil && atom.Map.ElementType.Inlined == (schema.Atom{}) {
+			return m
+		}
+		for key, elem := range m {
+			if _, isField := atom.Map.FindField(key); isField {
+				continue
+			}
+			m[key] = normalizeValue(s, atom.Map.ElementType, elem)
+		}
+		return m
+	case atom.List != nil:
+		l, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		for i := range l {
+			l[i] = normalizeValue(s, atom.List.ElementType, l[i])
+		}
+		return l
+	default:
+		return value
+	}
+}
+
+// normalizeQuantity returns the canonical string of a quantity, or the value
+// itself if it is not a valid quantity.
+func normalizeQuantity(value interface{}) interface{} {
+	switch value.(type) {
+	case string, int64, float64:
+		q, err := resource.ParseQuantity(fmt.Sprint(value))
+		if err != nil {
+			return value
+		}
+		return q.String()
+	default:
+		return value
+	}
+}
+
+// normalizeDuration returns the canonical string of a duration, or the value
+// itself if it is not a valid duration.
+func normalizeDuration(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return value
+	}
+	return d.String()
+}
+
+// jsonEqual returns true if the values have the same JSON encoding, which
+// ignores the differences between the number types of the schema defaults and
+// of the unstructured objects.
+func jsonEqual(a, b interface{}) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aJSON) == string(bJSON)
+}
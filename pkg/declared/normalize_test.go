@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"testing"
+
+	openapiv2 "github.com/google/gnostic/openapiv2"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func refSchema(name string) *openapiv2.Schema {
+	return &openapiv2.Schema{XRef: "#/definitions/" + name}
+}
+
+func TestNormalize(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "operator.example.com", Version: "v1", Kind: "Database"}
+	replicas := typeSchema("integer")
+	replicas.Default = &openapiv2.Any{Yaml: "1"}
+	database := typeSchema("object",
+		&openapiv2.NamedSchema{Name: "apiVersion", Value: typeSchema("string")},
+		&openapiv2.NamedSchema{Name: "kind", Value: typeSchema("string")},
+		&openapiv2.NamedSchema{Name: "spec", Value: typeSchema("object",
+			&openapiv2.NamedSchema{Name: "replicas", Value: replicas},
+			&openapiv2.NamedSchema{Name: "backupInterval", Value: refSchema(durationType)},
+			&openapiv2.NamedSchema{Name: "limits", Value: &openapiv2.Schema{
+				Type: &openapiv2.TypeItem{Value: []string{"object"}},
+				AdditionalProperties: &openapiv2.AdditionalPropertiesItem{
+					Oneof: &openapiv2.AdditionalPropertiesItem_Schema{Schema: refSchema(quantityType)},
+				},
+			}},
+		)},
+	)
+	database.VendorExtension = []*openapiv2.NamedAny{{
+		Name:  "x-kubernetes-group-version-kind",
+		Value: &openapiv2.Any{Yaml: "- group: operator.example.com\n  version: v1\n  kind: Database\n"},
+	}}
+	doc := &openapiv2.Document{
+		Swagger: "2.0",
+		Info:    &openapiv2.Info{Title: "test", Version: "v1"},
+		Definitions: &openapiv2.Definitions{AdditionalProperties: []*openapiv2.NamedSchema{
+			{Name: "com.example.operator.v1.Database", Value: database},
+			{Name: quantityType, Value: typeSchema("string")},
+			{Name: durationType, Value: typeSchema("string")},
+		}},
+	}
+	converter, err := NewValueConverter(fakeOpenAPISchema{doc: doc})
+	require.NoError(t, err)
+
+	newDatabase := func(spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		u.SetGroupVersionKind(gvk)
+		return u
+	}
+	formatted := newDatabase(map[string]interface{}{
+		"replicas":       int64(1),
+		"backupInterval": "90s",
+		"limits":         map[string]interface{}{"cpu": "1000m", "memory": "1024Mi"},
+	})
+	canonical := newDatabase(map[string]interface{}{
+		"backupInterval": "1m30s",
+		"limits":         map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+	})
+
+	require.Equal(t, canonical.Object, converter.Normalize(formatted).Object)
+	require.Equal(t, canonical.Object, converter.Normalize(canonical).Object)
+	// The object is not modified.
+	require.Equal(t, int64(1), formatted.Object["spec"].(map[string]interface{})["replicas"])
+
+	// A non-default value is kept.
+	scaled := newDatabase(map[string]interface{}{"replicas": int64(3)})
+	require.Equal(t, scaled.Object, converter.Normalize(scaled).Object)
+}
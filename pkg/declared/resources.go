@@ -17,6 +17,7 @@ package declared
 import (
 	"context"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,6 +42,9 @@ type Resources struct {
 	objectSet map[core.ID]*unstructured.Unstructured
 	// commit of the source in which the resources were declared
 	commit string
+	// emergencyOverrides are the expiries of the active emergency overrides
+	// of the managed objects, which the remediator observed on the cluster.
+	emergencyOverrides map[core.ID]time.Time
 }
 
 // Update performs an atomic update on the resource declaration set.
@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/kube-openapi/pkg/schemaconv"
 	"k8s.io/kube-openapi/pkg/util/proto"
 	"k8s.io/kubectl/pkg/util/openapi"
@@ -33,6 +34,9 @@ type ValueConverter struct {
 	discoveryClient  discovery.OpenAPISchemaInterface
 	openAPIResources openapi.Resources
 	parser           *typed.Parser
+	// preservingParser parses custom resources whose schemas don't declare all
+	// their fields, see TypedValue.
+	preservingParser *typed.Parser
 }
 
 // NewValueConverter returns a ValueConverter initialized with the given
@@ -57,16 +61,27 @@ func (v *ValueConverter) Refresh() error {
 	if err != nil {
 		return err
 	}
-	parser, err := typedParser(doc)
+	parser, err := typedParser(doc, false)
+	if err != nil {
+		return err
+	}
+	preservingParser, err := typedParser(doc, true)
 	if err != nil {
 		return err
 	}
 	v.openAPIResources = oa
 	v.parser = parser
+	v.preservingParser = preservingParser
 	return nil
 }
 
 // TypedValue returns the equivalent TypedValue for the given Object.
+//
+// Custom resources may have fields which are not declared in the OpenAPI
+// schema published for their CRD, e.g. when the CRD has a non-structural schema
+// or preserves unknown fields in a way which is lost in the OpenAPI v2
+// schema. Pruning these fields is up to the API server, so they are kept as
+// deduced fields instead of failing the conversion.
 func (v *ValueConverter) TypedValue(obj runtime.Object) (*typed.TypedValue, error) {
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	res := v.openAPIResources.LookupResource(gvk)
@@ -80,7 +95,14 @@ func (v *ValueConverter) TypedValue(obj runtime.Object) (*typed.TypedValue, erro
 	t := v.parser.Type(res.GetPath().String())
 	switch o := obj.(type) {
 	case *unstructured.Unstructured:
-		return t.FromUnstructured(o.UnstructuredContent())
+		val, err := t.FromUnstructured(o.UnstructuredContent())
+		if err != nil && !scheme.Scheme.Recognizes(gvk) {
+			pt := v.preservingParser.Type(res.GetPath().String())
+			if pval, perr := pt.FromUnstructured(o.UnstructuredContent()); perr == nil {
+				return pval, nil
+			}
+		}
+		return val, err
 	default:
 		return t.FromStructured(obj)
 	}
@@ -96,13 +118,14 @@ func typedValueDeduced(obj runtime.Object) (*typed.TypedValue, error) {
 }
 
 // typedParser returns a typed.Parser instantiated with schemas from the given
-// openapi Document.
-func typedParser(doc *openapiv2.Document) (*typed.Parser, error) {
+// openapi Document. If preserveUnknownFields is true, the fields which are not
+// declared in the schemas are parsed as deduced fields.
+func typedParser(doc *openapiv2.Document, preserveUnknownFields bool) (*typed.Parser, error) {
 	models, err := proto.NewOpenAPIData(doc)
 	if err != nil {
 		return nil, fmt.Errorf("interpreting models: %w", err)
 	}
-	typeSchema, err := schemaconv.ToSchemaWithPreserveUnknownFields(models, false)
+	typeSchema, err := schemaconv.ToSchemaWithPreserveUnknownFields(models, preserveUnknownFields)
 	if err != nil {
 		return nil, fmt.Errorf("converting models to schema: %w", err)
 	}
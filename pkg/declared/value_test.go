@@ -0,0 +1,107 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"testing"
+
+	openapiv2 "github.com/google/gnostic/openapiv2"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeOpenAPISchema struct {
+	doc *openapiv2.Document
+}
+
+func (f fakeOpenAPISchema) OpenAPISchema() (*openapiv2.Document, error) {
+	return f.doc, nil
+}
+
+func typeSchema(t string, properties ...*openapiv2.NamedSchema) *openapiv2.Schema {
+	s := &openapiv2.Schema{Type: &openapiv2.TypeItem{Value: []string{t}}}
+	if len(properties) > 0 {
+		s.Properties = &openapiv2.Properties{AdditionalProperties: properties}
+	}
+	return s
+}
+
+// kindSchema returns the schema of a kind whose spec only declares replicas.
+func kindSchema(name string, gvk schema.GroupVersionKind) *openapiv2.NamedSchema {
+	s := typeSchema("object",
+		&openapiv2.NamedSchema{Name: "apiVersion", Value: typeSchema("string")},
+		&openapiv2.NamedSchema{Name: "kind", Value: typeSchema("string")},
+		&openapiv2.NamedSchema{Name: "spec", Value: typeSchema("object",
+			&openapiv2.NamedSchema{Name: "replicas", Value: typeSchema("integer")})},
+	)
+	s.VendorExtension = []*openapiv2.NamedAny{{
+		Name: "x-kubernetes-group-version-kind",
+		Value: &openapiv2.Any{Yaml: "- group: " + gvk.Group + "\n  version: " + gvk.Version +
+			"\n  kind: " + gvk.Kind + "\n"},
+	}}
+	return &openapiv2.NamedSchema{Name: name, Value: s}
+}
+
+func TestValueConverter_UnknownFields(t *testing.T) {
+	customGVK := schema.GroupVersionKind{Group: "operator.example.com", Version: "v1", Kind: "Database"}
+	builtinGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	doc := &openapiv2.Document{
+		Swagger: "2.0",
+		Info:    &openapiv2.Info{Title: "test", Version: "v1"},
+		Definitions: &openapiv2.Definitions{AdditionalProperties: []*openapiv2.NamedSchema{
+			kindSchema("com.example.operator.v1.Database", customGVK),
+			kindSchema("io.k8s.api.apps.v1.Deployment", builtinGVK),
+		}},
+	}
+	converter, err := NewValueConverter(fakeOpenAPISchema{doc: doc})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		gvk     schema.GroupVersionKind
+		wantErr bool
+	}{
+		{
+			name: "custom resource keeps the unknown fields",
+			gvk:  customGVK,
+		},
+		{
+			name:    "built-in object rejects the unknown fields",
+			gvk:     builtinGVK,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": int64(1),
+					"backup":   map[string]interface{}{"schedule": "@daily"},
+				},
+			}}
+			u.SetGroupVersionKind(tc.gvk)
+
+			val, err := converter.TypedValue(u)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, u.UnstructuredContent(), val.AsValue().Unstructured())
+		})
+	}
+}
@@ -176,6 +176,9 @@ func (d Diff) deleteType(scope declared.Scope, syncName string) Operation {
 	case !differ.ManagedByConfigSync(d.Actual):
 		// d.Actual is not managed by Config Sync, so take no action.
 		return NoOp
+	case core.GetAnnotation(d.Actual, metadata.PendingPruneSinceKey) != "":
+		// The applier prunes this object once its prune delay expires.
+		return NoOp
 	}
 
 	// Anything below here has Nomos metadata and is manageable by this reconciler,
@@ -353,6 +353,13 @@ func TestDiffType(t *testing.T) {
 				core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion)),
 			want: Abandon,
 		},
+		{
+			name: "actual + no declared, managed by Config Sync, pending prune: noop",
+			actual: fake.RoleObject(syncertest.ManagementEnabled,
+				core.Annotation(metadata.ResourceIDKey, "rbac.authorization.k8s.io_role_default-name"),
+				core.Annotation(metadata.PendingPruneSinceKey, "2022-11-01T12:00:00Z")),
+			want: NoOp,
+		},
 		{
 			name: "actual + no declared, system Namespace: abandon",
 			actual: fake.NamespaceObject(metav1.NamespaceSystem,
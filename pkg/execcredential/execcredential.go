@@ -0,0 +1,196 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package execcredential runs the exec credential plugins which vend the
+// tokens used to authenticate to Git and OCI sources.
+package execcredential
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/util"
+)
+
+const (
+	// DefaultUsername is the username presented with the token, when the
+	// plugin doesn't specify one.
+	DefaultUsername = "oauth2accesstoken"
+
+	// DefaultPluginDir is the directory into which the plugin is installed.
+	DefaultPluginDir = "/credential-plugins"
+
+	// AskpassPath is the path of the git askpass endpoint.
+	AskpassPath = "/git_askpass"
+
+	// execInfoEnv is the environment variable which passes the ExecCredential
+	// input to the plugin, like the kubectl exec credential plugins.
+	execInfoEnv = "KUBERNETES_EXEC_INFO"
+
+	// refreshBefore is how long before the expiry of a token the plugin is
+	// executed again, so the token doesn't expire while it is in use.
+	refreshBefore = time.Minute
+)
+
+// Parse decodes the JSON encoded plugin passed to the reconciler containers.
+func Parse(s string) (*v1beta1.ExecCredential, error) {
+	plugin := &v1beta1.ExecCredential{}
+	if err := json.Unmarshal([]byte(s), plugin); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the exec credential plugin")
+	}
+	if plugin.Command == "" {
+		return nil, errors.New("the exec credential plugin must specify a command")
+	}
+	if !util.ValidPluginCommand(plugin.Command) {
+		return nil, errors.Errorf("the command %q of the exec credential plugin must be a path relative to the plugin directory", plugin.Command)
+	}
+	return plugin, nil
+}
+
+// Encode encodes the plugin to pass it to the reconciler containers.
+func Encode(plugin *v1beta1.ExecCredential) string {
+	// The plugin only has string fields, so encoding it never fails.
+	b, _ := json.Marshal(plugin)
+	return string(b)
+}
+
+// Provider executes an exec credential plugin, and caches the token it vends
+// until the token expires.
+type Provider struct {
+	plugin v1beta1.ExecCredential
+	dir    string
+	// now is stubbed out in tests.
+	now func() time.Time
+
+	mux     sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewProvider returns a Provider for the plugin installed in dir.
+func NewProvider(plugin *v1beta1.ExecCredential, dir string) *Provider {
+	return &Provider{
+		plugin: *plugin,
+		dir:    dir,
+		now:    time.Now,
+	}
+}
+
+// Username returns the username presented with the token.
+func (p *Provider) Username() string {
+	if p.plugin.Username == "" {
+		return DefaultUsername
+	}
+	return p.plugin.Username
+}
+
+// Token returns the cached token, or executes the plugin if there is no token
+// yet or the token is about to expire. A token without an expiry is cached
+// for the lifetime of the Provider.
+func (p *Provider) Token(ctx context.Context) (string, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.token != "" && (p.expires.IsZero() || p.now().Add(refreshBefore).Before(p.expires)) {
+		return p.token, nil
+	}
+	cred, err := p.execute(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token = cred.Status.Token
+	p.expires = time.Time{}
+	if cred.Status.ExpirationTimestamp != nil {
+		p.expires = cred.Status.ExpirationTimestamp.Time
+	}
+	klog.V(3).Infof("Exec credential plugin %q vended a token expiring at %v", p.plugin.Command, p.expires)
+	return p.token, nil
+}
+
+func (p *Provider) execute(ctx context.Context) (*clientauthv1.ExecCredential, error) {
+	command := filepath.Join(p.dir, p.plugin.Command)
+	info, err := json.Marshal(&clientauthv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clientauthv1.SchemeGroupVersion.String(),
+			Kind:       "ExecCredential",
+		},
+		Spec: clientauthv1.ExecCredentialSpec{Interactive: false},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, command, p.plugin.Args...)
+	vars := []string{fmt.Sprintf("%s=%s", execInfoEnv, info)}
+	for _, env := range p.plugin.Env {
+		vars = append(vars, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+	cmd.Env = util.PluginEnv(vars...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "exec credential plugin %q failed: %s", p.plugin.Command, stderr.String())
+	}
+
+	cred := &clientauthv1.ExecCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), cred); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the output of exec credential plugin %q", p.plugin.Command)
+	}
+	if cred.Kind != "ExecCredential" {
+		return nil, errors.Errorf("exec credential plugin %q printed a %q, want an ExecCredential", p.plugin.Command, cred.Kind)
+	}
+	if cred.Status == nil || cred.Status.Token == "" {
+		return nil, errors.Errorf("exec credential plugin %q did not vend a token", p.plugin.Command)
+	}
+	return cred, nil
+}
+
+// Authorization implements authn.Authenticator, so the token authenticates
+// the pulls of OCI images.
+func (p *Provider) Authorization() (*authn.AuthConfig, error) {
+	token, err := p.Token(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &authn.AuthConfig{
+		Username: p.Username(),
+		Password: token,
+	}, nil
+}
+
+// ServeHTTP serves the git askpass endpoint, which git-sync calls for the
+// credentials of the Git repo.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, err := p.Token(r.Context())
+	if err != nil {
+		klog.Errorf("Failed to vend a token for git askpass: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "username=%s\npassword=%s\n", p.Username(), token)
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execcredential
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+// writePlugin writes a plugin which counts its executions in a file, and
+// prints a token expiring at the time in the EXPIRES env.
+func writePlugin(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	script := fmt.Sprintf(`#!/bin/sh
+echo x >> %s
+n=$(wc -l < %s | tr -d ' ')
+case "$KUBERNETES_EXEC_INFO" in
+  *'"kind":"ExecCredential"'*) ;;
+  *) echo "missing exec info" >&2; exit 1 ;;
+esac
+if [ -n "$EXPIRES" ]; then
+  printf '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"%%s-%%s","expirationTimestamp":"%%s"}}' "$1" "$n" "$EXPIRES"
+else
+  printf '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"%%s-%%s"}}' "$1" "$n"
+fi
+`, counter, counter)
+	if err := os.WriteFile(filepath.Join(dir, "vend-token"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestProviderToken(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		env     []v1beta1.ExecEnvVar
+		advance time.Duration
+		want    []string
+	}{
+		{
+			name: "token without expiry is cached",
+			want: []string{"tok-1", "tok-1"},
+		},
+		{
+			name:    "unexpired token is cached",
+			env:     []v1beta1.ExecEnvVar{{Name: "EXPIRES", Value: expires.Format(time.RFC3339)}},
+			advance: 30 * time.Minute,
+			want:    []string{"tok-1", "tok-1"},
+		},
+		{
+			name:    "token about to expire is refreshed",
+			env:     []v1beta1.ExecEnvVar{{Name: "EXPIRES", Value: expires.Format(time.RFC3339)}},
+			advance: time.Hour - 30*time.Second,
+			want:    []string{"tok-1", "tok-2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writePlugin(t)
+			p := NewProvider(&v1beta1.ExecCredential{Command: "vend-token", Args: []string{"tok"}, Env: tc.env}, dir)
+			clock := now
+			p.now = func() time.Time { return clock }
+
+			var got []string
+			for range tc.want {
+				token, err := p.Token(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, token)
+				clock = clock.Add(tc.advance)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("got tokens %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fail"), []byte("#!/bin/sh\necho denied >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty"), []byte("#!/bin/sh\necho '{\"kind\":\"ExecCredential\",\"status\":{}}'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for command, want := range map[string]string{"fail": "denied", "empty": "did not vend a token"} {
+		_, err := NewProvider(&v1beta1.ExecCredential{Command: command}, dir).Token(context.Background())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", command, err, want)
+		}
+	}
+}
+
+func TestAskpassAndAuthorization(t *testing.T) {
+	dir := writePlugin(t)
+	plugin, err := Parse(Encode(&v1beta1.ExecCredential{Image: "image", Command: "vend-token", Args: []string{"tok"}, Username: "x-access-token"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewProvider(plugin, dir)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", AskpassPath, nil))
+	if got, want := w.Body.String(), "username=x-access-token\npassword=tok-1\n"; got != want {
+		t.Errorf("got askpass response %q, want %q", got, want)
+	}
+
+	auth, err := p.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Username != "x-access-token" || auth.Password != "tok-1" {
+		t.Errorf("got authorization %+v, want the cached token", auth)
+	}
+
+	for _, encoded := range []string{
+		`{"image":"image"}`,
+		`{"image":"image","command":"/bin/sh"}`,
+		`{"image":"image","command":"../bin/sh"}`,
+	} {
+		if _, err := Parse(encoded); err == nil {
+			t.Errorf("Parse(%s) succeeded, want error", encoded)
+		}
+	}
+}
+
+func TestProviderEnv(t *testing.T) {
+	t.Setenv("GIT_SYNC_PASSWORD", "secret")
+	dir := t.TempDir()
+	script := `#!/bin/sh
+printf '{"kind":"ExecCredential","status":{"token":"%s-%s"}}' "$GIT_SYNC_PASSWORD" "$TEAM"
+`
+	if err := os.WriteFile(filepath.Join(dir, "vend-token"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	p := NewProvider(&v1beta1.ExecCredential{Command: "vend-token", Env: []v1beta1.ExecEnvVar{{Name: "TEAM", Value: "books"}}}, dir)
+	token, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The environment of the container is not passed to the plugin.
+	if want := "-books"; token != want {
+		t.Errorf("got token %q, want %q", token, want)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export streams structured records of the sync attempts, the applied
+// objects and the drift corrections of a reconciler to a data warehouse, e.g.
+// BigQuery, for long-term fleet analytics.
+//
+// Like the metrics, the records are recorded with package-level functions,
+// which are no-ops unless an exporter was started. Exporting is best-effort:
+// records are buffered and written in batches, and dropped if the sink falls
+// behind or fails, so that exporting never slows down syncing.
+package export
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SyncAttemptsTable is the table of the SyncAttempt records.
+	SyncAttemptsTable = "sync_attempts"
+	// AppliedObjectsTable is the table of the AppliedObject records.
+	AppliedObjectsTable = "applied_objects"
+	// DriftEventsTable is the table of the DriftEvent records.
+	DriftEventsTable = "drift_events"
+
+	// bufferSize is the number of records buffered before records are dropped.
+	bufferSize = 10000
+	// batchSize is the largest number of records written to the sink at once.
+	batchSize = 500
+	// flushPeriod is how often the buffered records are written to the sink.
+	flushPeriod = 10 * time.Second
+	// writeTimeout is the timeout of writing a batch to the sink.
+	writeTimeout = 30 * time.Second
+)
+
+// Sync identifies the RootSync or RepoSync of the records.
+type Sync struct {
+	Cluster       string `json:"cluster"`
+	SyncKind      string `json:"sync_kind"`
+	SyncNamespace string `json:"sync_namespace"`
+	SyncName      string `json:"sync_name"`
+}
+
+// SyncAttempt is the record of a run of the reconciler.
+type SyncAttempt struct {
+	Sync
+	Time time.Time `json:"time"`
+	// Trigger is what triggered the run, e.g. "resync".
+	Trigger string `json:"trigger"`
+	// Result is the result of the run, e.g. "succeeded".
+	Result string `json:"result"`
+	// Stages are the comma-separated stages executed by the run.
+	Stages string `json:"stages"`
+	// Commit is the source commit of the run.
+	Commit string `json:"commit"`
+	// DurationMillis is the duration of the run in milliseconds.
+	DurationMillis int64 `json:"duration_millis"`
+	// ErrorCodes are the KNV codes of the errors of the run, one per error.
+	ErrorCodes []string `json:"error_codes"`
+}
+
+// AppliedObject is the record of an object applied or pruned by the
+// reconciler.
+type AppliedObject struct {
+	Sync
+	Time      time.Time `json:"time"`
+	Commit    string    `json:"commit"`
+	Group     string    `json:"group"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	// Strategy is how the object was actuated: "Apply" or "Delete".
+	Strategy string `json:"strategy"`
+	// Actuation is the result of the actuation, e.g. "Succeeded".
+	Actuation string `json:"actuation"`
+	// Reconcile is the result of waiting for the object to reconcile, e.g.
+	// "Succeeded".
+	Reconcile string `json:"reconcile"`
+}
+
+// DriftEvent is the record of a drift of a managed object corrected by the
+// remediator.
+type DriftEvent struct {
+	Sync
+	Time      time.Time `json:"time"`
+	Commit    string    `json:"commit"`
+	Group     string    `json:"group"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	// Operation is how the drift was corrected, e.g. "update".
+	Operation string `json:"operation"`
+	// Error is the error correcting the drift, if any.
+	Error string `json:"error"`
+}
+
+// Record is a record written to a table of a sink.
+type Record struct {
+	Table string
+	Row   interface{}
+}
+
+// Exporter buffers the records and writes them to a sink in batches.
+type Exporter struct {
+	sink    Sink
+	sync    Sync
+	records chan Record
+	stop    chan struct{}
+	done    chan struct{}
+
+	mux     sync.Mutex
+	dropped int
+}
+
+var (
+	exporterMux sync.RWMutex
+	exporter    *Exporter
+)
+
+// Start starts exporting the records of the RSync to the sink at the URL, and
+// registers the exporter used by the Record functions. See NewSink for the
+// supported URLs. The exporter is stopped, after flushing the buffered
+// records, when the context is done or Stop is called.
+func Start(ctx context.Context, sinkURL string, s Sync) (*Exporter, error) {
+	sink, err := NewSink(ctx, sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	e := newExporter(sink, s)
+	go e.run(ctx)
+
+	exporterMux.Lock()
+	exporter = e
+	exporterMux.Unlock()
+	return e, nil
+}
+
+func newExporter(sink Sink, s Sync) *Exporter {
+	return &Exporter{
+		sink:    sink,
+		sync:    s,
+		records: make(chan Record, bufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Stop unregisters the exporter, and waits until the buffered records are
+// written.
+func (e *Exporter) Stop() {
+	exporterMux.Lock()
+	if exporter == e {
+		exporter = nil
+	}
+	exporterMux.Unlock()
+	close(e.stop)
+	<-e.done
+}
+
+// RecordSyncAttempt exports the record of a run of the reconciler.
+func RecordSyncAttempt(a SyncAttempt) {
+	if e := current(); e != nil {
+		a.Sync = e.sync
+		e.add(Record{Table: SyncAttemptsTable, Row: a})
+	}
+}
+
+// RecordAppliedObjects exports the records of the objects applied or pruned
+// by an apply.
+func RecordAppliedObjects(objs []AppliedObject) {
+	if e := current(); e != nil {
+		for _, obj := range objs {
+			obj.Sync = e.sync
+			e.add(Record{Table: AppliedObjectsTable, Row: obj})
+		}
+	}
+}
+
+// RecordDrift exports the record of a drift corrected by the remediator.
+func RecordDrift(d DriftEvent) {
+	if e := current(); e != nil {
+		d.Sync = e.sync
+		e.add(Record{Table: DriftEventsTable, Row: d})
+	}
+}
+
+// Enabled returns true if the records are exported, so that callers can skip
+// building records which would be discarded.
+func Enabled() bool {
+	return current() != nil
+}
+
+func current() *Exporter {
+	exporterMux.RLock()
+	defer exporterMux.RUnlock()
+	return exporter
+}
+
+// add buffers the record, or drops it if the buffer is full.
+func (e *Exporter) add(r Record) {
+	select {
+	case e.records <- r:
+	default:
+		e.mux.Lock()
+		e.dropped++
+		e.mux.Unlock()
+	}
+}
+
+// run writes the buffered records to the sink, whenever a batch is full or
+// the flush period passes, until the exporter is stopped.
+func (e *Exporter) run(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(flushPeriod)
+	defer ticker.Stop()
+
+	var batch []Record
+	for {
+		select {
+		case r := <-e.records:
+			batch = append(batch, r)
+			if len(batch) >= batchSize {
+				e.flush(ctx, batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.flush(ctx, batch)
+			batch = nil
+		case <-e.stop:
+			e.drain(batch)
+			return
+		case <-ctx.Done():
+			e.drain(batch)
+			return
+		}
+	}
+}
+
+// drain writes the batch and the records left in the buffer to the sink.
+// The records recorded afterwards are dropped, since the buffer is not read
+// anymore.
+func (e *Exporter) drain(batch []Record) {
+	ctx := context.Background()
+	for {
+		select {
+		case r := <-e.records:
+			batch = append(batch, r)
+			if len(batch) >= batchSize {
+				e.flush(ctx, batch)
+				batch = nil
+			}
+		default:
+			e.flush(ctx, batch)
+			return
+		}
+	}
+}
+
+// flush writes the batch to the sink, one table at a time. Failed writes are
+// logged and dropped.
+func (e *Exporter) flush(ctx context.Context, batch []Record) {
+	e.mux.Lock()
+	dropped := e.dropped
+	e.dropped = 0
+	e.mux.Unlock()
+	if dropped > 0 {
+		klog.Warningf("Dropped %d records, because the export sink fell behind", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	var tables []string
+	rows := make(map[string][]interface{})
+	for _, r := range batch {
+		if _, found := rows[r.Table]; !found {
+			tables = append(tables, r.Table)
+		}
+		rows[r.Table] = append(rows[r.Table], r.Row)
+	}
+	for _, table := range tables {
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		err := e.sink.Write(writeCtx, table, rows[table])
+		cancel()
+		if err != nil {
+			klog.Warningf("Failed to export %d records to the %s table: %v", len(rows[table]), table, err)
+			continue
+		}
+		klog.V(3).Infof("Exported %d records to the %s table", len(rows[table]), table)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSink struct {
+	mux  sync.Mutex
+	rows map[string][]interface{}
+}
+
+func (s *fakeSink) Write(_ context.Context, table string, rows []interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.rows[table] = append(s.rows[table], rows...)
+	return nil
+}
+
+func TestExporter(t *testing.T) {
+	sink := &fakeSink{rows: make(map[string][]interface{})}
+	rsync := Sync{Cluster: "prod", SyncKind: "RootSync", SyncNamespace: "config-management-system", SyncName: "root-sync"}
+
+	RecordSyncAttempt(SyncAttempt{Result: "dropped"})
+	assert.False(t, Enabled())
+
+	e := newExporter(sink, rsync)
+	go e.run(context.Background())
+	exporterMux.Lock()
+	exporter = e
+	exporterMux.Unlock()
+
+	RecordSyncAttempt(SyncAttempt{Trigger: "resync", Result: "succeeded", Commit: "abc123"})
+	RecordAppliedObjects([]AppliedObject{
+		{Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-1"},
+		{Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-2"},
+	})
+	RecordDrift(DriftEvent{Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-1", Operation: "update"})
+	e.Stop()
+	assert.False(t, Enabled())
+
+	assert.Equal(t, []interface{}{
+		SyncAttempt{Sync: rsync, Trigger: "resync", Result: "succeeded", Commit: "abc123"},
+	}, sink.rows[SyncAttemptsTable])
+	assert.Equal(t, []interface{}{
+		AppliedObject{Sync: rsync, Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-1"},
+		AppliedObject{Sync: rsync, Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-2"},
+	}, sink.rows[AppliedObjectsTable])
+	assert.Equal(t, []interface{}{
+		DriftEvent{Sync: rsync, Kind: "ConfigMap", Namespace: "bookstore", Name: "cm-1", Operation: "update"},
+	}, sink.rows[DriftEventsTable])
+}
+
+func TestHTTPSink(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &got))
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(context.Background(), server.URL)
+	require.NoError(t, err)
+	err = sink.Write(context.Background(), DriftEventsTable, []interface{}{DriftEvent{Name: "cm-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, DriftEventsTable, got["table"])
+	rows := got["rows"].([]interface{})
+	require.Len(t, rows, 1)
+	assert.Equal(t, "cm-1", rows[0].(map[string]interface{})["name"])
+}
+
+func TestBigQuerySink(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		wantErr  bool
+	}{
+		{
+			name:     "rows inserted",
+			response: `{}`,
+		},
+		{
+			name:     "rows rejected",
+			response: `{"insertErrors": [{"index": 0, "errors": [{"reason": "invalid", "message": "no such field: foo"}]}]}`,
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPath string
+			var gotRequest bigQueryInsertAllRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(body, &gotRequest))
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			sink := &BigQuerySink{Client: server.Client(), Endpoint: server.URL, Project: "my-project", Dataset: "config_sync"}
+			err := sink.Write(context.Background(), SyncAttemptsTable, []interface{}{SyncAttempt{Result: "succeeded"}})
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "/projects/my-project/datasets/config_sync/tables/sync_attempts/insertAll", gotPath)
+			assert.True(t, gotRequest.IgnoreUnknownValues)
+			require.Len(t, gotRequest.Rows, 1)
+			assert.Equal(t, "succeeded", gotRequest.Rows[0].JSON.(map[string]interface{})["result"])
+		})
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	for _, sinkURL := range []string{"bigquery://my-project", "bigquery://my-project/a/b", "ftp://example.com"} {
+		_, err := NewSink(context.Background(), sinkURL)
+		assert.Error(t, err, sinkURL)
+	}
+}
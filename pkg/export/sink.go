@@ -0,0 +1,192 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// bigQueryScheme is the scheme of the URLs of the BigQuery sinks, e.g.
+	// `bigquery://my-project/my-dataset`.
+	bigQueryScheme = "bigquery"
+	// bigQueryEndpoint is the endpoint of the BigQuery API.
+	bigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	// bigQueryInsertScope is the OAuth scope needed to stream rows into
+	// BigQuery tables.
+	bigQueryInsertScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+)
+
+// Sink writes rows to the tables of a data warehouse.
+type Sink interface {
+	// Write writes the rows to the table.
+	Write(ctx context.Context, table string, rows []interface{}) error
+}
+
+// NewSink returns the sink at the URL, which is either:
+//   - `bigquery://<project>/<dataset>` to stream the records into the
+//     sync_attempts, applied_objects and drift_events tables of the BigQuery
+//     dataset, with the Application Default Credentials, e.g. the Google
+//     service account bound to the reconciler with Workload Identity.
+//   - an `http://` or `https://` URL to POST the records to as JSON.
+func NewSink(ctx context.Context, sinkURL string) (Sink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid export sink URL %q", sinkURL)
+	}
+	switch u.Scheme {
+	case bigQueryScheme:
+		dataset := strings.Trim(u.Path, "/")
+		if u.Host == "" || dataset == "" || strings.Contains(dataset, "/") {
+			return nil, errors.Errorf("invalid BigQuery export sink URL %q: must be %s://<project>/<dataset>", sinkURL, bigQueryScheme)
+		}
+		client, err := google.DefaultClient(ctx, bigQueryInsertScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find the credentials of the BigQuery export sink")
+		}
+		return &BigQuerySink{
+			Client:   client,
+			Endpoint: bigQueryEndpoint,
+			Project:  u.Host,
+			Dataset:  dataset,
+		}, nil
+	case "http", "https":
+		return &HTTPSink{Client: http.DefaultClient, URL: sinkURL}, nil
+	default:
+		return nil, errors.Errorf("unsupported export sink URL %q: the scheme must be %s, http or https", sinkURL, bigQueryScheme)
+	}
+}
+
+// HTTPSink POSTs the rows of each table as a JSON object like
+// `{"table": "sync_attempts", "rows": [...]}`, to a generic JSON-over-HTTP
+// endpoint, e.g. a log collector.
+type HTTPSink struct {
+	Client *http.Client
+	URL    string
+}
+
+var _ Sink = &HTTPSink{}
+
+// Write implements Sink.
+func (s *HTTPSink) Write(ctx context.Context, table string, rows []interface{}) error {
+	body := struct {
+		Table string        `json:"table"`
+		Rows  []interface{} `json:"rows"`
+	}{
+		Table: table,
+		Rows:  rows,
+	}
+	_, err := postJSON(ctx, s.Client, s.URL, body)
+	return err
+}
+
+// BigQuerySink streams the rows into the tables of a BigQuery dataset with the
+// tabledata.insertAll API. The tables must exist, with the columns of the
+// records.
+type BigQuerySink struct {
+	Client   *http.Client
+	Endpoint string
+	Project  string
+	Dataset  string
+}
+
+var _ Sink = &BigQuerySink{}
+
+type bigQueryRow struct {
+	JSON interface{} `json:"json"`
+}
+
+type bigQueryInsertAllRequest struct {
+	// IgnoreUnknownValues allows adding columns to the records before the
+	// tables are updated.
+	IgnoreUnknownValues bool          `json:"ignoreUnknownValues"`
+	Rows                []bigQueryRow `json:"rows"`
+}
+
+type bigQueryInsertAllResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+// Write implements Sink.
+func (s *BigQuerySink) Write(ctx context.Context, table string, rows []interface{}) error {
+	req := bigQueryInsertAllRequest{IgnoreUnknownValues: true}
+	for _, row := range rows {
+		req.Rows = append(req.Rows, bigQueryRow{JSON: row})
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/datasets/%s/tables/%s/insertAll",
+		s.Endpoint, url.PathEscape(s.Project), url.PathEscape(s.Dataset), url.PathEscape(table))
+	respBody, err := postJSON(ctx, s.Client, endpoint, req)
+	if err != nil {
+		return err
+	}
+	resp := bigQueryInsertAllResponse{}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return errors.Wrap(err, "failed to decode the BigQuery insertAll response")
+	}
+	if len(resp.InsertErrors) > 0 {
+		insertErr := resp.InsertErrors[0]
+		msg := "unknown error"
+		if len(insertErr.Errors) > 0 {
+			msg = fmt.Sprintf("%s: %s", insertErr.Errors[0].Reason, insertErr.Errors[0].Message)
+		}
+		return errors.Errorf("BigQuery rejected %d of %d rows, e.g. row %d: %s",
+			len(resp.InsertErrors), len(rows), insertErr.Index, msg)
+	}
+	return nil
+}
+
+// postJSON POSTs the body as JSON to the URL, and returns the response body.
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the records")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the response")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("unexpected response %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitwebhook receives push events from Git hosting services and
+// requests an immediate sync of the RootSyncs and RepoSyncs that sync from the
+// pushed branch.
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Provider is a Git hosting service that sends push events.
+type Provider string
+
+const (
+	// GitHub sends push events with the X-GitHub-Event header.
+	GitHub Provider = "github"
+	// GitLab sends push events with the X-Gitlab-Event header.
+	GitLab Provider = "gitlab"
+	// Bitbucket sends push events with the X-Event-Key header, both from
+	// Bitbucket Cloud and Bitbucket Data Center.
+	Bitbucket Provider = "bitbucket"
+)
+
+// PushEvent is a push to a repository, independent of the provider.
+type PushEvent struct {
+	// Provider is the service that sent the event.
+	Provider Provider
+	// Repos are the URLs the pushed repository can be cloned from.
+	Repos []string
+	// Refs are the names of the pushed branches and tags, like
+	// `refs/heads/main`.
+	Refs []string
+}
+
+// errIgnoredEvent is returned for events that are not pushes, like the ping
+// sent when a webhook is created.
+type errIgnoredEvent string
+
+func (e errIgnoredEvent) Error() string {
+	return fmt.Sprintf("ignored %s event", string(e))
+}
+
+// provider returns the provider that sent the request, and the type of the
+// event.
+func provider(header http.Header) (Provider, string, error) {
+	if e := header.Get("X-GitHub-Event"); e != "" {
+		return GitHub, e, nil
+	}
+	if e := header.Get("X-Gitlab-Event"); e != "" {
+		return GitLab, e, nil
+	}
+	if e := header.Get("X-Event-Key"); e != "" {
+		return Bitbucket, e, nil
+	}
+	return "", "", fmt.Errorf("unknown event: none of the X-GitHub-Event, X-Gitlab-Event or X-Event-Key headers is set")
+}
+
+// validSignature returns true if the request was sent with the shared secret.
+// GitHub and Bitbucket sign the body with an HMAC-SHA256, while GitLab sends
+// the secret itself as a token.
+func validSignature(p Provider, header http.Header, body, secret []byte) bool {
+	switch p {
+	case GitLab:
+		token := header.Get("X-Gitlab-Token")
+		return subtle.ConstantTimeCompare([]byte(token), secret) == 1
+	case GitHub:
+		return validHMAC(header.Get("X-Hub-Signature-256"), body, secret)
+	case Bitbucket:
+		return validHMAC(header.Get("X-Hub-Signature"), body, secret)
+	default:
+		return false
+	}
+}
+
+func validHMAC(signature string, body, secret []byte) bool {
+	hexSum := strings.TrimPrefix(signature, "sha256=")
+	if hexSum == signature {
+		// Only SHA-256 signatures are accepted.
+		return false
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+type githubPush struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		GitURL   string `json:"git_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+type gitlabPush struct {
+	Ref     string `json:"ref"`
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+type bitbucketLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type bitbucketPush struct {
+	// Bitbucket Cloud
+	Push struct {
+		Changes []struct {
+			New *struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"new"`
+		} `json:"changes"`
+	} `json:"push"`
+	// Bitbucket Data Center
+	Changes []struct {
+		RefID string `json:"refId"`
+	} `json:"changes"`
+	Repository struct {
+		// Bitbucket Cloud
+		FullName string `json:"full_name"`
+		Links    struct {
+			HTML bitbucketLink `json:"html"`
+			// Bitbucket Data Center
+			Clone []bitbucketLink `json:"clone"`
+		} `json:"links"`
+	} `json:"repository"`
+}
+
+// parsePushEvent parses the body of a push event sent by the provider.
+func parsePushEvent(p Provider, eventType string, body []byte) (*PushEvent, error) {
+	e := &PushEvent{Provider: p}
+	switch p {
+	case GitHub:
+		if eventType != "push" {
+			return nil, errIgnoredEvent(eventType)
+		}
+		payload := &githubPush{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, fmt.Errorf("invalid GitHub push event: %w", err)
+		}
+		e.Refs = []string{payload.Ref}
+		e.Repos = nonEmpty(payload.Repository.CloneURL, payload.Repository.SSHURL,
+			payload.Repository.GitURL, payload.Repository.HTMLURL)
+	case GitLab:
+		if eventType != "Push Hook" && eventType != "Tag Push Hook" {
+			return nil, errIgnoredEvent(eventType)
+		}
+		payload := &gitlabPush{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, fmt.Errorf("invalid GitLab push event: %w", err)
+		}
+		e.Refs = []string{payload.Ref}
+		e.Repos = nonEmpty(payload.Project.GitHTTPURL, payload.Project.GitSSHURL, payload.Project.WebURL)
+	case Bitbucket:
+		if eventType != "repo:push" && eventType != "repo:refs_changed" {
+			return nil, errIgnoredEvent(eventType)
+		}
+		payload := &bitbucketPush{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, fmt.Errorf("invalid Bitbucket push event: %w", err)
+		}
+		for _, c := range payload.Push.Changes {
+			if c.New == nil {
+				// The branch or tag was deleted.
+				continue
+			}
+			switch c.New.Type {
+			case "branch":
+				e.Refs = append(e.Refs, "refs/heads/"+c.New.Name)
+			case "tag":
+				e.Refs = append(e.Refs, "refs/tags/"+c.New.Name)
+			}
+		}
+		for _, c := range payload.Changes {
+			e.Refs = append(e.Refs, c.RefID)
+		}
+		e.Repos = nonEmpty(payload.Repository.Links.HTML.Href)
+		if payload.Repository.FullName != "" {
+			e.Repos = append(e.Repos, "https://bitbucket.org/"+payload.Repository.FullName)
+		}
+		for _, l := range payload.Repository.Links.Clone {
+			e.Repos = append(e.Repos, l.Href)
+		}
+	}
+	if len(e.Repos) == 0 {
+		return nil, fmt.Errorf("invalid %s push event: no repository URL", p)
+	}
+	return e, nil
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxBodyBytes is the largest event accepted. GitHub caps the payloads it
+// sends at 25 MB.
+const maxBodyBytes = 25 << 20
+
+// Handler is an http.Handler that receives push events, and requests an
+// immediate sync of the RootSyncs and RepoSyncs that sync from the pushed
+// branch, by setting their SyncRequestedAtAnnotationKey annotation.
+type Handler struct {
+	client client.Client
+	secret []byte
+	// minInterval is the minimum time between two requests for the same
+	// RootSync or RepoSync. Pushes received in between are coalesced.
+	minInterval time.Duration
+	now         func() time.Time
+}
+
+// NewHandler returns a Handler that accepts the events signed with the
+// secret.
+func NewHandler(c client.Client, secret []byte, minInterval time.Duration) *Handler {
+	return &Handler{
+		client:      c,
+		secret:      secret,
+		minInterval: minInterval,
+		now:         time.Now,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e := ReadPushEvent(w, req, h.secret)
+	if e == nil {
+		return
+	}
+
+	requested, err := h.requestSync(req.Context(), e)
+	if err != nil {
+		klog.Errorf("Failed to request a sync for a %s push to %v: %v", e.Provider, e.Refs, err)
+		http.Error(w, "failed to request a sync", http.StatusInternalServerError)
+		return
+	}
+	klog.Infof("Requested a sync of %d RootSyncs and RepoSyncs for a %s push of %v to %v", requested, e.Provider, e.Refs, e.Repos)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "requested a sync of %d RootSyncs and RepoSyncs\n", requested)
+}
+
+// ReadPushEvent reads the push event sent with the request, and checks that
+// it is signed with the secret. If the request is not a valid push event, or
+// is an event other than a push, ReadPushEvent responds to it and returns nil.
+func ReadPushEvent(w http.ResponseWriter, req *http.Request, secret []byte) *PushEvent {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	p, eventType, err := provider(req.Header)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the event: %v", err), http.StatusRequestEntityTooLarge)
+		return nil
+	}
+	if !validSignature(p, req.Header, body, secret) {
+		klog.Warningf("Rejected a %s %q event from %s: invalid signature", p, eventType, req.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return nil
+	}
+
+	e, err := parsePushEvent(p, eventType, body)
+	if err != nil {
+		var ignored errIgnoredEvent
+		if errors.As(err, &ignored) {
+			klog.V(3).Infof("Ignored a %s %q event", p, eventType)
+			_, _ = fmt.Fprintln(w, err.Error())
+			return nil
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	return e
+}
+
+// rsync is a RootSync or RepoSync.
+type rsync struct {
+	kind string
+	obj  client.Object
+}
+
+// requestSync annotates the RootSyncs and RepoSyncs that sync from the pushed
+// branch, and returns how many of them were annotated.
+func (h *Handler) requestSync(ctx context.Context, e *PushEvent) (int, error) {
+	var matched []rsync
+
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := h.client.List(ctx, rootSyncs, client.InNamespace(configsync.ControllerNamespace)); err != nil {
+		return 0, fmt.Errorf("failed to list RootSyncs: %w", err)
+	}
+	for i := range rootSyncs.Items {
+		rs := &rootSyncs.Items[i]
+		if v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.GitSource && e.Matches(rs.Spec.Git) {
+			matched = append(matched, rsync{kind: configsync.RootSyncKind, obj: rs})
+		}
+	}
+
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := h.client.List(ctx, repoSyncs); err != nil {
+		return 0, fmt.Errorf("failed to list RepoSyncs: %w", err)
+	}
+	for i := range repoSyncs.Items {
+		rs := &repoSyncs.Items[i]
+		if v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.GitSource && e.Matches(rs.Spec.Git) {
+			matched = append(matched, rsync{kind: configsync.RepoSyncKind, obj: rs})
+		}
+	}
+
+	requested := 0
+	for _, rs := range matched {
+		ok, err := h.annotate(ctx, rs)
+		if err != nil {
+			return requested, err
+		}
+		if ok {
+			requested++
+		}
+	}
+	return requested, nil
+}
+
+// annotate sets the SyncRequestedAtAnnotationKey annotation of the object to
+// the current time, unless it was set less than minInterval ago.
+func (h *Handler) annotate(ctx context.Context, rs rsync) (bool, error) {
+	obj := rs.obj
+	now := h.now()
+	if last, found := obj.GetAnnotations()[metadata.SyncRequestedAtAnnotationKey]; found {
+		if t, err := time.Parse(time.RFC3339, last); err == nil && now.Sub(t) < h.minInterval {
+			klog.V(3).Infof("Skipped the sync request for %s %s: a sync was requested at %s", rs.kind, client.ObjectKeyFromObject(obj), last)
+			return false, nil
+		}
+	}
+	existing := obj.DeepCopyObject().(client.Object)
+	core.SetAnnotation(obj, metadata.SyncRequestedAtAnnotationKey, now.UTC().Format(time.RFC3339))
+	if err := h.client.Patch(ctx, obj, client.MergeFrom(existing)); err != nil {
+		return false, fmt.Errorf("failed to annotate %s %s: %w", rs.kind, client.ObjectKeyFromObject(obj), err)
+	}
+	return true, nil
+}
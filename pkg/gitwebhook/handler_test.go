@@ -0,0 +1,224 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitwebhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	syncertest "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testSecret = "s3cr3t"
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func gitRootSync(name, repo, branch string) *v1beta1.RootSync {
+	rs := fake.RootSyncObjectV1Beta1(name)
+	rs.Spec.SourceType = string(v1beta1.GitSource)
+	rs.Spec.Git = &v1beta1.Git{Repo: repo, Branch: branch}
+	return rs
+}
+
+func gitRepoSync(ns, name, repo, branch string) *v1beta1.RepoSync {
+	rs := fake.RepoSyncObjectV1Beta1(ns, name)
+	rs.Spec.SourceType = string(v1beta1.GitSource)
+	rs.Spec.Git = &v1beta1.Git{Repo: repo, Branch: branch}
+	return rs
+}
+
+func TestNormalizeRepo(t *testing.T) {
+	for _, repo := range []string{
+		"https://github.com/org/repo",
+		"https://github.com/org/repo.git",
+		"https://user@github.com/Org/Repo/",
+		"git@github.com:org/repo.git",
+		"ssh://git@github.com:22/org/repo.git",
+		"github.com/org/repo",
+	} {
+		assert.Equal(t, "github.com/org/repo", normalizeRepo(repo), repo)
+	}
+}
+
+func TestRefMatches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ref      string
+		branch   string
+		revision string
+		want     bool
+	}{
+		{name: "default branch", ref: "refs/heads/master", want: true},
+		{name: "other branch", ref: "refs/heads/main", want: false},
+		{name: "branch", ref: "refs/heads/main", branch: "main", revision: "HEAD", want: true},
+		{name: "tag revision", ref: "refs/tags/v1.0.0", branch: "main", revision: "v1.0.0", want: true},
+		{name: "push to the branch of a pinned revision", ref: "refs/heads/main", branch: "main", revision: "v1.0.0", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, refMatches(tc.ref, tc.branch, tc.revision))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	githubPush := `{"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/org/repo.git","ssh_url":"git@github.com:org/repo.git"}}`
+	gitlabPush := `{"ref":"refs/heads/main","project":{"git_http_url":"https://gitlab.com/org/repo.git","git_ssh_url":"git@gitlab.com:org/repo.git"}}`
+	bitbucketPush := `{"push":{"changes":[{"new":{"type":"branch","name":"main"}}]},"repository":{"full_name":"org/repo","links":{"html":{"href":"https://bitbucket.org/org/repo"}}}}`
+
+	testCases := []struct {
+		name          string
+		header        map[string]string
+		body          string
+		wantCode      int
+		wantRequested []client.ObjectKey
+	}{
+		{
+			name: "GitHub push",
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": sign(githubPush),
+			},
+			body:     githubPush,
+			wantCode: http.StatusAccepted,
+			wantRequested: []client.ObjectKey{
+				{Namespace: configsync.ControllerNamespace, Name: "github-main"},
+				{Namespace: "bookstore", Name: "github-main"},
+			},
+		},
+		{
+			name: "GitHub push with an invalid signature",
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": sign("other"),
+			},
+			body:     githubPush,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "GitHub ping",
+			header: map[string]string{
+				"X-GitHub-Event":      "ping",
+				"X-Hub-Signature-256": sign(`{}`),
+			},
+			body:     `{}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "GitLab push",
+			header: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": testSecret,
+			},
+			body:     gitlabPush,
+			wantCode: http.StatusAccepted,
+			wantRequested: []client.ObjectKey{
+				{Namespace: configsync.ControllerNamespace, Name: "gitlab-main"},
+			},
+		},
+		{
+			name: "GitLab push with an invalid token",
+			header: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": "wrong",
+			},
+			body:     gitlabPush,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "Bitbucket push",
+			header: map[string]string{
+				"X-Event-Key":     "repo:push",
+				"X-Hub-Signature": sign(bitbucketPush),
+			},
+			body:     bitbucketPush,
+			wantCode: http.StatusAccepted,
+			wantRequested: []client.ObjectKey{
+				{Namespace: configsync.ControllerNamespace, Name: "bitbucket-main"},
+			},
+		},
+		{
+			name:     "unknown provider",
+			body:     githubPush,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+			recent := core.Annotation(metadata.SyncRequestedAtAnnotationKey, now.Add(-time.Second).Format(time.RFC3339))
+			objs := []client.Object{
+				gitRootSync("github-main", "git@github.com:org/repo", "main"),
+				gitRootSync("github-dev", "https://github.com/org/repo", "dev"),
+				gitRootSync("github-other", "https://github.com/org/other", "main"),
+				gitRootSync("gitlab-main", "https://gitlab.com/org/repo", "main"),
+				gitRootSync("bitbucket-main", "https://bitbucket.org/org/repo.git", "main"),
+				gitRepoSync("bookstore", "github-main", "https://github.com/org/repo", "main"),
+				// A sync was already requested for this RepoSync.
+				gitRepoSync("shoestore", "github-main", "https://github.com/org/repo", "main"),
+			}
+			recent(objs[len(objs)-1])
+			fakeClient := syncertest.NewClient(t, core.Scheme, objs...)
+
+			h := NewHandler(fakeClient, []byte(testSecret), time.Minute)
+			h.now = func() time.Time { return now }
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tc.wantCode, rec.Code, rec.Body.String())
+
+			var gotRequested []client.ObjectKey
+			rootSyncs := &v1beta1.RootSyncList{}
+			require.NoError(t, fakeClient.List(context.Background(), rootSyncs))
+			for _, rs := range rootSyncs.Items {
+				if core.GetAnnotation(&rs, metadata.SyncRequestedAtAnnotationKey) == now.Format(time.RFC3339) {
+					gotRequested = append(gotRequested, client.ObjectKeyFromObject(&rs))
+				}
+			}
+			repoSyncs := &v1beta1.RepoSyncList{}
+			require.NoError(t, fakeClient.List(context.Background(), repoSyncs))
+			for _, rs := range repoSyncs.Items {
+				if core.GetAnnotation(&rs, metadata.SyncRequestedAtAnnotationKey) == now.Format(time.RFC3339) {
+					gotRequested = append(gotRequested, client.ObjectKeyFromObject(&rs))
+				}
+			}
+			assert.ElementsMatch(t, tc.wantRequested, gotRequested)
+		})
+	}
+}
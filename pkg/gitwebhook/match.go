@@ -0,0 +1,99 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitwebhook
+
+import (
+	"strings"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+const (
+	// defaultBranch is the branch git-sync checks out when spec.git.branch is
+	// not set.
+	defaultBranch = "master"
+	// defaultRevision is the revision git-sync checks out when
+	// spec.git.revision is not set.
+	defaultRevision = "HEAD"
+)
+
+// normalizeRepo returns the host and path of a repository URL, so that the
+// HTTPS, SSH and scp-like URLs of the same repository compare equal.
+// e.g. `git@github.com:org/repo.git` and `https://github.com/org/repo` are
+// both normalized to `github.com/org/repo`.
+func normalizeRepo(repo string) string {
+	repo = strings.ToLower(strings.TrimSpace(repo))
+	hasScheme := false
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+len("://"):]
+		hasScheme = true
+	}
+	host, path := repo, ""
+	if hasScheme {
+		if i := strings.Index(repo, "/"); i >= 0 {
+			host, path = repo[:i], repo[i+1:]
+		}
+	} else if i := strings.Index(repo, ":"); i >= 0 {
+		// scp-like syntax: [user@]host:path
+		host, path = repo[:i], repo[i+1:]
+	} else if i := strings.Index(repo, "/"); i >= 0 {
+		host, path = repo[:i], repo[i+1:]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if i := strings.Index(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return host + "/" + path
+}
+
+// refMatches returns true if pushing the ref may change the commit that
+// git-sync checks out for the branch and revision.
+func refMatches(ref, branch, revision string) bool {
+	if revision != "" && revision != defaultRevision {
+		return ref == "refs/heads/"+revision || ref == "refs/tags/"+revision
+	}
+	if branch == "" {
+		branch = defaultBranch
+	}
+	return ref == "refs/heads/"+branch
+}
+
+// Matches returns true if the push event may change the commit synced from the
+// Git source.
+func (e *PushEvent) Matches(git *v1beta1.Git) bool {
+	if git == nil {
+		return false
+	}
+	repo := normalizeRepo(git.Repo)
+	repoMatched := false
+	for _, r := range e.Repos {
+		if normalizeRepo(r) == repo {
+			repoMatched = true
+			break
+		}
+	}
+	if !repoMatched {
+		return false
+	}
+	for _, ref := range e.Refs {
+		if refMatches(ref, git.Branch, git.Revision) {
+			return true
+		}
+	}
+	return false
+}
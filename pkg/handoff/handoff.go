@@ -0,0 +1,374 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package handoff moves the management of objects declared by two RootSyncs
+// or RepoSyncs from one to the other, without deleting or recreating them.
+//
+// While both RSyncs declare an object, the RSync which does not manage it
+// reports a management conflict. The handoff moves the object from the
+// inventory of the current manager to the inventory of the new one, and
+// updates its management metadata, so that:
+//   - the new manager adopts it without conflict,
+//   - the previous manager does not prune it once it is removed from its
+//     source of truth.
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/applier"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/resync"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Sync identifies a RootSync or RepoSync. RootSyncs are in the Config Sync
+// namespace.
+type Sync struct {
+	Name      string
+	Namespace string
+}
+
+// String returns the namespace and name of the RSync.
+func (s Sync) String() string {
+	return s.Namespace + "/" + s.Name
+}
+
+func (s Sync) isRoot() bool {
+	return s.Namespace == configsync.ControllerNamespace
+}
+
+// manager returns the value of the manager annotation of the objects managed
+// by the RSync.
+func (s Sync) manager() string {
+	if s.isRoot() {
+		return declared.ResourceManager(declared.RootReconciler, s.Name)
+	}
+	return declared.ResourceManager(declared.Scope(s.Namespace), s.Name)
+}
+
+// Options configures Handoff.
+type Options struct {
+	// From is the RSync managing the objects.
+	From Sync
+	// To is the RSync taking over the management of the objects.
+	To Sync
+	// Targets select the objects to move. Directory targets are not
+	// supported, since the inventory does not record the source paths.
+	Targets []resync.Target
+	// DryRun only checks that the objects can be moved.
+	DryRun bool
+}
+
+// Handoff moves the management of the objects selected by the targets from
+// one RSync to the other. Returns the IDs of the moved objects, or of the
+// objects which would be moved in dry-run mode.
+//
+// The handoff is refused unless:
+//   - neither RSync is syncing, so that no apply updates the inventories or
+//     the objects during the move,
+//   - every selected object is in the inventory of the current manager,
+//     and is managed by it,
+//   - the new manager reports a management conflict for every selected
+//     object, which shows that it declares the object, so that it does not
+//     prune the object after adopting it.
+//
+// The inventories are updated with optimistic concurrency, so the handoff
+// fails, instead of overwriting concurrent changes, if a reconciler starts
+// syncing during the move. The changes made before a failure are reverted.
+func Handoff(ctx context.Context, c client.Client, opts Options) ([]core.ID, error) {
+	for _, t := range opts.Targets {
+		if t.Dir != "" {
+			return nil, errors.Errorf("directory target %s is not supported, select the objects by kind, namespace and name", t)
+		}
+	}
+	if opts.From == opts.To {
+		return nil, errors.Errorf("cannot hand off objects from %s to itself", opts.From)
+	}
+
+	if _, err := getIdleStatus(ctx, c, opts.From); err != nil {
+		return nil, err
+	}
+	toStatus, err := getIdleStatus(ctx, c, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRG, err := getInventory(ctx, c, opts.From)
+	if err != nil {
+		return nil, err
+	}
+	toRG, err := getInventory(ctx, c, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectObjects(fromRG, opts.Targets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid handoff from %s", opts.From)
+	}
+	versions := conflictVersions(toStatus)
+
+	objs := make([]*unstructured.Unstructured, 0, len(selected))
+	for _, id := range selected {
+		version, found := versions[id]
+		if !found {
+			return nil, errors.Errorf("%s does not report a management conflict for %s: make sure its source of truth declares it, and that it synced", opts.To, id)
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(id.WithVersion(version))
+		if err := c.Get(ctx, id.ObjectKey, obj); err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s", id)
+		}
+		if manager := core.GetAnnotation(obj, metadata.ResourceManagerKey); manager != opts.From.manager() {
+			return nil, errors.Errorf("%s is managed by %q, not by %s", id, manager, opts.From)
+		}
+		objs = append(objs, obj)
+	}
+	if opts.DryRun {
+		return selected, nil
+	}
+
+	// Add the objects to the new inventory first, so that they are always in
+	// an inventory which is not going to prune them.
+	if err := updateInventory(ctx, c, toRG, selected, nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to add the objects to the inventory of %s", opts.To)
+	}
+	var moved []*unstructured.Unstructured
+	rollback := func(cause error) error {
+		for _, obj := range moved {
+			if err := setManager(ctx, c, obj, opts.From); err != nil {
+				cause = errors.Wrapf(cause, "failed to revert the manager of %s: %v", core.IDOf(obj), err)
+			}
+		}
+		if err := refreshAndUpdateInventory(ctx, c, opts.To, nil, selected); err != nil {
+			cause = errors.Wrapf(cause, "failed to revert the inventory of %s: %v", opts.To, err)
+		}
+		return cause
+	}
+	for _, obj := range objs {
+		if err := setManager(ctx, c, obj, opts.To); err != nil {
+			return nil, rollback(errors.Wrapf(err, "failed to update the manager of %s", core.IDOf(obj)))
+		}
+		moved = append(moved, obj)
+	}
+	if err := updateInventory(ctx, c, fromRG, nil, selected); err != nil {
+		return nil, rollback(errors.Wrapf(err, "failed to remove the objects from the inventory of %s", opts.From))
+	}
+	return selected, nil
+}
+
+// getIdleStatus returns the status of the RSync, or an error if the RSync is
+// syncing.
+func getIdleStatus(ctx context.Context, c client.Reader, s Sync) (*v1beta1.Status, error) {
+	key := client.ObjectKey{Namespace: s.Namespace, Name: s.Name}
+	if s.isRoot() {
+		rs := &v1beta1.RootSync{}
+		if err := c.Get(ctx, key, rs); err != nil {
+			return nil, errors.Wrapf(err, "failed to get RootSync %s", s)
+		}
+		for _, cond := range rs.Status.Conditions {
+			if cond.Type == v1beta1.RootSyncSyncing && cond.Status == metav1.ConditionTrue {
+				return nil, errors.Errorf("RootSync %s is syncing, retry once it is done", s)
+			}
+		}
+		return &rs.Status.Status, nil
+	}
+	rs := &v1beta1.RepoSync{}
+	if err := c.Get(ctx, key, rs); err != nil {
+		return nil, errors.Wrapf(err, "failed to get RepoSync %s", s)
+	}
+	for _, cond := range rs.Status.Conditions {
+		if cond.Type == v1beta1.RepoSyncSyncing && cond.Status == metav1.ConditionTrue {
+			return nil, errors.Errorf("RepoSync %s is syncing, retry once it is done", s)
+		}
+	}
+	return &rs.Status.Status, nil
+}
+
+// conflictVersions returns the versions of the objects for which the RSync
+// reports a management conflict, by ID.
+func conflictVersions(s *v1beta1.Status) map[core.ID]string {
+	versions := make(map[core.ID]string)
+	for _, cse := range s.Sync.Errors {
+		if cse.Code != status.ManagementConflictErrorCode {
+			continue
+		}
+		for _, r := range cse.Resources {
+			id := core.ID{
+				GroupKind: schema.GroupKind{Group: r.GVK.Group, Kind: r.GVK.Kind},
+				ObjectKey: client.ObjectKey{Namespace: r.Namespace, Name: r.Name},
+			}
+			versions[id] = r.GVK.Version
+		}
+	}
+	return versions
+}
+
+// getInventory returns the ResourceGroup inventory of the RSync.
+func getInventory(ctx context.Context, c client.Reader, s Sync) (*unstructured.Unstructured, error) {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	if err := c.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: s.Name}, rg); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the inventory of %s", s)
+	}
+	return rg, nil
+}
+
+// selectObjects returns the IDs of the objects in the inventory selected by
+// the targets, in the order of the targets. Every target must select an
+// object.
+func selectObjects(rg *unstructured.Unstructured, targets []resync.Target) ([]core.ID, error) {
+	resources, _, err := unstructured.NestedSlice(rg.Object, "spec", "resources")
+	if err != nil {
+		return nil, err
+	}
+	var ids []core.ID
+	for _, t := range targets {
+		found := false
+		for _, r := range resources {
+			id, ok := inventoryID(r)
+			if !ok {
+				continue
+			}
+			ref := &unstructured.Unstructured{}
+			ref.SetGroupVersionKind(id.WithVersion(""))
+			ref.SetNamespace(id.Namespace)
+			ref.SetName(id.Name)
+			if t.Matches(ref) {
+				ids = append(ids, id)
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("%s is not in the inventory", t)
+		}
+	}
+	return ids, nil
+}
+
+// inventoryID returns the ID of an entry of the spec.resources of a
+// ResourceGroup.
+func inventoryID(r interface{}) (core.ID, bool) {
+	m, ok := r.(map[string]interface{})
+	if !ok {
+		return core.ID{}, false
+	}
+	group, _, _ := unstructured.NestedString(m, "group")
+	kind, _, _ := unstructured.NestedString(m, "kind")
+	namespace, _, _ := unstructured.NestedString(m, "namespace")
+	name, _, _ := unstructured.NestedString(m, "name")
+	return core.ID{
+		GroupKind: schema.GroupKind{Group: group, Kind: kind},
+		ObjectKey: client.ObjectKey{Namespace: namespace, Name: name},
+	}, true
+}
+
+// updateInventory adds and removes the objects from the spec.resources of the
+// ResourceGroup. The update fails with a conflict if the ResourceGroup
+// changed since it was read.
+func updateInventory(ctx context.Context, c client.Client, rg *unstructured.Unstructured, add, remove []core.ID) error {
+	resources, _, err := unstructured.NestedSlice(rg.Object, "spec", "resources")
+	if err != nil {
+		return err
+	}
+	removed := make(map[core.ID]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	present := make(map[core.ID]bool, len(resources))
+	var updated []interface{}
+	for _, r := range resources {
+		id, ok := inventoryID(r)
+		if ok && removed[id] {
+			continue
+		}
+		present[id] = true
+		updated = append(updated, r)
+	}
+	for _, id := range add {
+		if present[id] {
+			continue
+		}
+		updated = append(updated, map[string]interface{}{
+			"group":     id.Group,
+			"kind":      id.Kind,
+			"namespace": id.Namespace,
+			"name":      id.Name,
+		})
+	}
+	if err := unstructured.SetNestedSlice(rg.Object, updated, "spec", "resources"); err != nil {
+		return err
+	}
+	return c.Update(ctx, rg)
+}
+
+// refreshAndUpdateInventory reads the ResourceGroup of the RSync again before
+// updating it.
+func refreshAndUpdateInventory(ctx context.Context, c client.Client, s Sync, add, remove []core.ID) error {
+	rg, err := getInventory(ctx, c, s)
+	if err != nil {
+		return err
+	}
+	return updateInventory(ctx, c, rg, add, remove)
+}
+
+// setManager updates the management metadata of the object for the RSync.
+// The patch carries the resourceVersion of the object, so it fails with a
+// conflict if the object changed since it was read.
+func setManager(ctx context.Context, c client.Client, obj *unstructured.Unstructured, s Sync) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": obj.GetResourceVersion(),
+			"annotations": map[string]interface{}{
+				metadata.ResourceManagerKey: s.manager(),
+				metadata.OwningInventoryKey: applier.InventoryID(s.Name, s.Namespace),
+			},
+			"labels": map[string]interface{}{
+				metadata.OwningInventoryLabel: applier.InventoryLabelValue(s.Name, s.Namespace),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch))
+}
+
+// FormatIDs returns the IDs as a comma-separated list.
+func FormatIDs(ids []core.ID) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+// NextSteps returns the instructions to complete the handoff.
+func NextSteps(opts Options) string {
+	return fmt.Sprintf("Remove the objects from the source of truth of %s: it no longer prunes them, and reports management conflicts for them until they are removed.", opts.From)
+}
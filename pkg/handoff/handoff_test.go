@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handoff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/applier"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/resync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	rootSync = Sync{Name: configsync.RootSyncName, Namespace: configsync.ControllerNamespace}
+	repoSync = Sync{Name: configsync.RepoSyncName, Namespace: "bookstore"}
+)
+
+func inventory(s Sync, objs ...client.Object) *unstructured.Unstructured {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	rg.SetName(s.Name)
+	rg.SetNamespace(s.Namespace)
+	var resources []interface{}
+	for _, obj := range objs {
+		id := core.IDOf(obj)
+		resources = append(resources, map[string]interface{}{
+			"group":     id.Group,
+			"kind":      id.Kind,
+			"namespace": id.Namespace,
+			"name":      id.Name,
+		})
+	}
+	_ = unstructured.SetNestedSlice(rg.Object, resources, "spec", "resources")
+	return rg
+}
+
+func managedBy(s Sync) core.MetaMutator {
+	return func(obj client.Object) {
+		core.SetAnnotation(obj, metadata.ResourceManagerKey, s.manager())
+		core.SetAnnotation(obj, metadata.OwningInventoryKey, applier.InventoryID(s.Name, s.Namespace))
+		core.SetLabel(obj, metadata.OwningInventoryLabel, applier.InventoryLabelValue(s.Name, s.Namespace))
+	}
+}
+
+func inventoryIDs(t *testing.T, c client.Reader, s Sync) []core.ID {
+	t.Helper()
+	rg, err := getInventory(context.Background(), c, s)
+	require.NoError(t, err)
+	resources, _, err := unstructured.NestedSlice(rg.Object, "spec", "resources")
+	require.NoError(t, err)
+	var ids []core.ID
+	for _, r := range resources {
+		id, ok := inventoryID(r)
+		require.True(t, ok)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestHandoff(t *testing.T) {
+	moved := fake.ConfigMapObject(core.Name("moved"), core.Namespace("bookstore"), managedBy(rootSync))
+	kept := fake.ConfigMapObject(core.Name("kept"), core.Namespace("bookstore"), managedBy(rootSync))
+	targets, err := resync.ParseTargets("configmap/bookstore/moved")
+	require.NoError(t, err)
+
+	conflict := v1beta1.ConfigSyncError{
+		Code:      status.ManagementConflictErrorCode,
+		Resources: []v1beta1.ResourceRef{status.ToResourceRef(moved)},
+	}
+
+	testCases := []struct {
+		name        string
+		repoSyncing bool
+		repoErrors  []v1beta1.ConfigSyncError
+		dryRun      bool
+		wantErr     bool
+		wantMoved   bool
+	}{
+		{
+			name:       "objects are moved",
+			repoErrors: []v1beta1.ConfigSyncError{conflict},
+			wantMoved:  true,
+		},
+		{
+			name:       "dry-run does not move the objects",
+			repoErrors: []v1beta1.ConfigSyncError{conflict},
+			dryRun:     true,
+		},
+		{
+			name:    "objects not declared by the new manager are not moved",
+			wantErr: true,
+		},
+		{
+			name:        "objects are not moved while the new manager is syncing",
+			repoSyncing: true,
+			repoErrors:  []v1beta1.ConfigSyncError{conflict},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			rs := fake.RootSyncObjectV1Beta1(rootSync.Name)
+			repo := fake.RepoSyncObjectV1Beta1(repoSync.Namespace, repoSync.Name)
+			repo.Status.Sync.Errors = tc.repoErrors
+			if tc.repoSyncing {
+				repo.Status.Conditions = []v1beta1.RepoSyncCondition{{Type: v1beta1.RepoSyncSyncing, Status: metav1.ConditionTrue}}
+			}
+			c := syncerFake.NewClient(t, core.Scheme, rs, repo,
+				inventory(rootSync, moved, kept), inventory(repoSync),
+				moved.DeepCopy(), kept.DeepCopy())
+
+			ids, err := Handoff(ctx, c, Options{From: rootSync, To: repoSync, Targets: targets, DryRun: tc.dryRun})
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, []core.ID{core.IDOf(moved)}, ids)
+			}
+
+			got := &corev1.ConfigMap{}
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(moved), got))
+			if tc.wantMoved {
+				assert.Equal(t, repoSync.manager(), core.GetAnnotation(got, metadata.ResourceManagerKey))
+				assert.Equal(t, applier.InventoryID(repoSync.Name, repoSync.Namespace), core.GetAnnotation(got, metadata.OwningInventoryKey))
+				assert.Equal(t, []core.ID{core.IDOf(kept)}, inventoryIDs(t, c, rootSync))
+				assert.Equal(t, []core.ID{core.IDOf(moved)}, inventoryIDs(t, c, repoSync))
+			} else {
+				assert.Equal(t, rootSync.manager(), core.GetAnnotation(got, metadata.ResourceManagerKey))
+				assert.Equal(t, []core.ID{core.IDOf(moved), core.IDOf(kept)}, inventoryIDs(t, c, rootSync))
+				assert.Empty(t, inventoryIDs(t, c, repoSync))
+			}
+		})
+	}
+}
@@ -47,6 +47,9 @@ const (
 type Hydrator struct {
 	// DonePath is the absolute path to the done file under the /repo directory.
 	DonePath cmpath.Absolute
+	// ProgressPath is the absolute path to the progress file under the /repo
+	// directory. The progress is not reported if it is empty.
+	ProgressPath cmpath.Absolute
 	// SourceType is the type of the source repository, must be git or oci.
 	SourceType v1beta1.SourceType
 	// SourceRoot is the absolute path to the source root directory.
@@ -65,6 +68,31 @@ type Hydrator struct {
 	RehydratePeriod time.Duration
 	// ReconcilerName is the name of the reconciler.
 	ReconcilerName string
+	// SigningKey is the key to sign the manifest of the hydrated configs with.
+	// The manifest is not signed if it is empty.
+	SigningKey []byte
+	// ResetCacheToken returns the value of the reset-cache annotation of the
+	// RootSync or RepoSync. The hydrated configs are purged and rendered again
+	// when it changes. Nil disables the purge on request.
+	ResetCacheToken func(ctx context.Context) (string, error)
+
+	// resetCacheToken is the last value returned by ResetCacheToken.
+	resetCacheToken string
+	// resetCacheTokenSeen indicates whether ResetCacheToken was called once.
+	resetCacheTokenSeen bool
+}
+
+// RunOnce renders the fetched commit once, for the reconcilers which run in a
+// Job, whose source is fetched once before the hydration-controller starts.
+// The rendering errors are reported to the reconciler in the error file, like
+// in Run.
+func (h *Hydrator) RunOnce() error {
+	commit, syncDir, err := SourceCommitAndDir(h.SourceType, h.absSourceDir(), h.SyncDir, h.ReconcilerName)
+	if err != nil {
+		return err
+	}
+	hydrateErr := h.hydrate(commit, syncDir.OSPath())
+	return h.complete(commit, hydrateErr)
 }
 
 // Run runs the hydration process periodically.
@@ -90,6 +118,9 @@ func (h *Hydrator) Run(ctx context.Context) {
 			}
 			rehydrateTimer.Reset(h.RehydratePeriod) // Schedule rehydrate attempt
 		case <-runTimer.C:
+			// Purging the hydrated configs removes the done file, so the
+			// current commit is rendered again below.
+			h.purgeOnRequest(ctx)
 			commit, syncDir, err := SourceCommitAndDir(h.SourceType, absSourceDir, h.SyncDir, h.ReconcilerName)
 			if err != nil {
 				klog.Errorf("failed to get the commit hash and sync directory from the source directory %s: %v", absSourceDir.OSPath(), err)
@@ -112,6 +143,13 @@ func (h *Hydrator) runHydrate(sourceCommit, syncDir string) HydrationError {
 	newHydratedDir := h.HydratedRoot.Join(cmpath.RelativeOS(sourceCommit))
 	dest := newHydratedDir.Join(h.SyncDir).OSPath()
 
+	progress := &Progress{
+		Commit:    sourceCommit,
+		Stage:     StageKustomizeBuild,
+		Target:    h.SyncDir.SlashPath(),
+		StartTime: time.Now(),
+	}
+	h.reportProgress(progress)
 	if err := kustomizeBuild(syncDir, dest, true); err != nil {
 		return err
 	}
@@ -123,6 +161,18 @@ func (h *Hydrator) runHydrate(sourceCommit, syncDir string) HydrationError {
 		return NewTransientError(fmt.Errorf("source commit changed while running Kustomize build, was %s, now %s. It will be retried in the next sync", sourceCommit, newCommit))
 	}
 
+	progress.Stage = StageWriteManifest
+	if progress.FilesProcessed, err = countFiles(newHydratedDir); err != nil {
+		klog.Warningf("unable to count the rendered files under %s: %v", newHydratedDir.OSPath(), err)
+	}
+	h.reportProgress(progress)
+
+	// Write the manifest before updating the symlink, so the reconciler never
+	// reads hydrated configs without a manifest.
+	if err := WriteManifest(newHydratedDir.OSPath(), h.SigningKey); err != nil {
+		return NewInternalError(errors.Wrapf(err, "unable to write the manifest of %s", newHydratedDir.OSPath()))
+	}
+
 	if err := updateSymlink(h.HydratedRoot.OSPath(), h.HydratedLink, newHydratedDir.OSPath()); err != nil {
 		return NewInternalError(errors.Wrapf(err, "unable to update the symbolic link to %s", newHydratedDir.OSPath()))
 	}
@@ -177,6 +227,39 @@ func (h *Hydrator) hydrate(sourceCommit, syncDir string) HydrationError {
 	return h.runHydrate(sourceCommit, syncDir)
 }
 
+// purgeOnRequest removes the hydrated configs and the done file, if the
+// reset-cache annotation changed since they were last purged.
+// The value observed when the hydration-controller starts is not acted upon,
+// like in the reconciler.
+func (h *Hydrator) purgeOnRequest(ctx context.Context) {
+	if h.ResetCacheToken == nil {
+		return
+	}
+	token, err := h.ResetCacheToken(ctx)
+	if err != nil {
+		klog.Warningf("unable to check whether a reset of the cache is requested: %v", err)
+		return
+	}
+	if !h.resetCacheTokenSeen {
+		h.resetCacheToken = token
+		h.resetCacheTokenSeen = true
+		return
+	}
+	if token == "" || token == h.resetCacheToken {
+		return
+	}
+	klog.Infof("Purging the hydrated configs, as requested by the %s annotation: %s", metadata.ResetCacheKey, token)
+	if err := os.RemoveAll(h.DonePath.OSPath()); err != nil {
+		klog.Errorf("unable to remove the done file %s: %v", h.DonePath.OSPath(), err)
+		return
+	}
+	if err := os.RemoveAll(h.HydratedRoot.OSPath()); err != nil {
+		klog.Errorf("unable to remove the hydrated configs under %s: %v", h.HydratedRoot.OSPath(), err)
+		return
+	}
+	h.resetCacheToken = token
+}
+
 // rehydrateOnError retries the hydration on errors.
 func (h *Hydrator) rehydrateOnError(sourceCommit, syncDir string) {
 	errorFile := h.HydratedRoot.Join(cmpath.RelativeSlash(ErrorFile))
@@ -222,6 +305,9 @@ func updateSymlink(hydratedRoot, link, newDir string) error {
 		if err := os.RemoveAll(oldDir); err != nil {
 			klog.Warningf("unable to remove the previously hydrated directory %s: %v", oldDir, err)
 		}
+		if err := os.Remove(ManifestPath(oldDir)); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("unable to remove the manifest of the previously hydrated directory %s: %v", oldDir, err)
+		}
 	}
 	return nil
 }
@@ -229,6 +315,7 @@ func updateSymlink(hydratedRoot, link, newDir string) error {
 // complete marks the hydration process is done with a done file under the /repo directory
 // and reset the error file (create, update or delete).
 func (h *Hydrator) complete(commit string, hydrationErr HydrationError) error {
+	h.clearProgress()
 	errorPath := h.HydratedRoot.Join(cmpath.RelativeSlash(ErrorFile)).OSPath()
 	var err error
 	if hydrationErr == nil {
@@ -355,6 +442,9 @@ func SourceCommitAndDir(sourceType v1beta1.SourceType, sourceRevDir cmpath.Absol
 			errFilePath, containerName, configsync.ControllerNamespace,
 			metadata.ReconcilerLabel, reconcilerName).Build()
 	} else if err == nil {
+		if sourceType == v1beta1.GitSource {
+			return "", "", gitSyncError(containerName, string(content))
+		}
 		return "", "", status.SourceError.Sprintf("error in the %s container: %s", containerName, string(content)).Build()
 	}
 
@@ -68,6 +68,22 @@ func (e TransientError) Code() string {
 	return status.TransientErrorCode
 }
 
+// VerificationError represents hydrated configs that do not match their manifest,
+// e.g. because they were partially written or modified after the rendering.
+type VerificationError struct {
+	error
+}
+
+// NewVerificationError returns the wrapper of the verification error.
+func NewVerificationError(e error) VerificationError {
+	return VerificationError{e}
+}
+
+// Code returns the verification error code.
+func (e VerificationError) Code() string {
+	return status.HydrationVerificationErrorCode
+}
+
 // HydrationErrorPayload is the payload of the hydration error in the error file.
 type HydrationErrorPayload struct {
 	// Code is the error code to indicate if it is a user error or an internal error.
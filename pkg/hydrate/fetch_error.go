@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"regexp"
+	"strings"
+
+	"kpt.dev/configsync/pkg/status"
+)
+
+// gitProvider identifies the hosting service of a git repository, so that
+// fetch failures can be reported with hints specific to that service.
+type gitProvider string
+
+const (
+	providerGitHub    gitProvider = "GitHub"
+	providerGitLab    gitProvider = "GitLab"
+	providerBitbucket gitProvider = "Bitbucket"
+	providerAzure     gitProvider = "Azure Repos"
+	providerCSR       gitProvider = "Cloud Source Repositories"
+	providerUnknown   gitProvider = ""
+)
+
+// fetchFailure is a known class of git fetch failures.
+type fetchFailure struct {
+	// builder builds the typed source error for the failure.
+	builder status.ErrorBuilder
+	// summary describes the failure.
+	summary string
+	// patterns are lower-case substrings of the git or git-sync output that
+	// identify the failure.
+	patterns []string
+	// hints are remediation hints keyed by provider. The hint for
+	// providerUnknown is used for providers without a specific hint.
+	hints map[gitProvider]string
+}
+
+// fetchFailures is the list of known fetch failures, in matching order.
+// Rate limiting is checked before authentication, because some providers
+// reject throttled requests with a 403.
+var fetchFailures = []fetchFailure{
+	{
+		builder: status.SourceHostKeyError,
+		summary: "the SSH host key of the git server does not match the known hosts",
+		patterns: []string{
+			"host key verification failed",
+			"remote host identification has changed",
+			"no matching host key",
+		},
+		hints: map[gitProvider]string{
+			providerGitHub:  "GitHub publishes its current SSH host keys at https://api.github.com/meta; update the known hosts to match them",
+			providerUnknown: "Verify the SSH host key of the git server with its administrator, and update the known hosts if the key was rotated",
+		},
+	},
+	{
+		builder: status.SourceRateLimitedError,
+		summary: "the git server is rate limiting requests",
+		patterns: []string{
+			"rate limit",
+			"too many requests",
+			"the requested url returned error: 429",
+		},
+		hints: map[gitProvider]string{
+			providerGitHub:  "GitHub applies lower rate limits to unauthenticated requests; configure token or ssh authentication, or increase spec.git.period",
+			providerGitLab:  "GitLab applies per-user and per-IP rate limits; use a dedicated access token, or increase spec.git.period",
+			providerUnknown: "Increase spec.git.period to fetch less often",
+		},
+	},
+	{
+		builder: status.SourceAuthError,
+		summary: "the git server rejected the credentials",
+		patterns: []string{
+			"authentication failed",
+			"permission denied (publickey",
+			"could not read username",
+			"could not read password",
+			"invalid username or password",
+			"http basic: access denied",
+			"the requested url returned error: 401",
+			"the requested url returned error: 403",
+		},
+		hints: map[gitProvider]string{
+			providerGitHub:    "Make sure the GitHub token has not expired and can read the repository, or that the SSH key is added as a deploy key or to a user with access",
+			providerGitLab:    "Make sure the GitLab access token has the read_repository scope and has not expired",
+			providerBitbucket: "Make sure the Bitbucket app password has the Repositories: Read permission",
+			providerAzure:     "Make sure the Azure DevOps personal access token has the Code (Read) scope and has not expired",
+			providerCSR:       "Make sure the Google service account has the roles/source.reader role on the repository",
+			providerUnknown:   "Check the Secret referenced by spec.git.secretRef and the spec.git.auth type",
+		},
+	},
+	{
+		builder: status.SourceNotFoundError,
+		summary: "the repository or revision was not found",
+		patterns: []string{
+			"repository not found",
+			"does not appear to be a git repository",
+			"couldn't find remote ref",
+			"the requested url returned error: 404",
+			"unknown revision",
+		},
+		hints: map[gitProvider]string{
+			providerGitHub:  "Check spec.git.repo, spec.git.branch and spec.git.revision. GitHub also reports private repositories the credentials cannot access as not found",
+			providerGitLab:  "Check spec.git.repo, spec.git.branch and spec.git.revision. GitLab also reports private projects the credentials cannot access as not found",
+			providerUnknown: "Check spec.git.repo, spec.git.branch and spec.git.revision",
+		},
+	},
+}
+
+// gitHostRegexp matches the host of a git URL in https, ssh or scp-like form.
+var gitHostRegexp = regexp.MustCompile(`(?:https?://|ssh://|git@)(?:[^@/\s'"]+@)?([a-z0-9.-]+)`)
+
+// detectProvider returns the hosting service of the first git URL found in
+// the fetcher output.
+func detectProvider(output string) gitProvider {
+	match := gitHostRegexp.FindStringSubmatch(strings.ToLower(output))
+	if match == nil {
+		return providerUnknown
+	}
+	host := match[1]
+	switch {
+	case host == "github.com" || strings.HasSuffix(host, ".github.com"):
+		return providerGitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return providerGitLab
+	case host == "bitbucket.org" || strings.HasPrefix(host, "bitbucket."):
+		return providerBitbucket
+	case host == "dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return providerAzure
+	case host == "source.developers.google.com":
+		return providerCSR
+	default:
+		return providerUnknown
+	}
+}
+
+// gitSyncError converts the content of the git-sync error file into a typed
+// source error with a remediation hint. Unrecognized failures are reported as
+// a generic SourceError with the raw content.
+func gitSyncError(containerName, content string) status.Error {
+	lower := strings.ToLower(content)
+	for _, failure := range fetchFailures {
+		for _, pattern := range failure.patterns {
+			if !strings.Contains(lower, pattern) {
+				continue
+			}
+			provider := detectProvider(content)
+			hint, found := failure.hints[provider]
+			if !found {
+				hint = failure.hints[providerUnknown]
+			}
+			return failure.builder.Sprintf("error in the %s container: %s: %s. %s",
+				containerName, failure.summary, matchingLine(content, pattern), hint).Build()
+		}
+	}
+	return status.SourceError.Sprintf("error in the %s container: %s", containerName, content).Build()
+}
+
+// matchingLine returns the trimmed line of the output that contains the
+// pattern, so the status carries the relevant message instead of the whole
+// output.
+func matchingLine(output, pattern string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(strings.ToLower(line), pattern) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return strings.TrimSpace(output)
+}
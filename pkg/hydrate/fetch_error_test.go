@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"strings"
+	"testing"
+
+	"kpt.dev/configsync/pkg/status"
+)
+
+func TestGitSyncError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "github auth denied",
+			content:     "Run(git fetch https://github.com/foo/bar.git): exit status 128: { stdout: \"\", stderr: \"remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/foo/bar.git/'\" }",
+			wantCode:    status.SourceAuthErrorCode,
+			wantMessage: "GitHub token",
+		},
+		{
+			name:        "gitlab auth denied",
+			content:     "fatal: could not read Username for 'https://gitlab.com': No such device or address",
+			wantCode:    status.SourceAuthErrorCode,
+			wantMessage: "read_repository",
+		},
+		{
+			name:        "ssh auth denied on unknown host",
+			content:     "git@git.example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.",
+			wantCode:    status.SourceAuthErrorCode,
+			wantMessage: "spec.git.secretRef",
+		},
+		{
+			name:        "repo not found",
+			content:     "remote: Repository not found.\nfatal: repository 'https://github.com/foo/missing/' not found",
+			wantCode:    status.SourceNotFoundErrorCode,
+			wantMessage: "private repositories",
+		},
+		{
+			name:        "branch not found",
+			content:     "fatal: couldn't find remote ref invalid-branch",
+			wantCode:    status.SourceNotFoundErrorCode,
+			wantMessage: "spec.git.branch",
+		},
+		{
+			name:        "rate limited",
+			content:     "error: RPC failed; HTTP 429 curl 22 The requested URL returned error: 429 https://github.com/foo/bar",
+			wantCode:    status.SourceRateLimitedErrorCode,
+			wantMessage: "unauthenticated requests",
+		},
+		{
+			name:        "host key mismatch",
+			content:     "@@@ WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED! @@@\nHost key verification failed.",
+			wantCode:    status.SourceHostKeyErrorCode,
+			wantMessage: "known hosts",
+		},
+		{
+			name:        "unknown failure",
+			content:     "fatal: something unexpected",
+			wantCode:    status.SourceErrorCode,
+			wantMessage: "fatal: something unexpected",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := gitSyncError("git-sync", tc.content)
+			if err.Code() != tc.wantCode {
+				t.Errorf("got code %s, want %s", err.Code(), tc.wantCode)
+			}
+			if !strings.Contains(err.Error(), tc.wantMessage) {
+				t.Errorf("got message %q, want it to contain %q", err.Error(), tc.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestSuffix is the suffix of the manifest file of the hydrated configs.
+// The manifest is written next to the hydrated directory of a commit, so it is
+// never read as a config file.
+const ManifestSuffix = ".manifest.json"
+
+// Manifest records the digests of the hydrated files of a commit, so the
+// reconciler can detect partially written or modified hydrated configs.
+type Manifest struct {
+	// Commit is the commit that the files were rendered from.
+	Commit string `json:"commit"`
+	// Files maps the slash-separated path of each hydrated file, relative to
+	// the hydrated directory, to the hex encoded SHA-256 digest of its content.
+	Files map[string]string `json:"files"`
+	// Signature is the hex encoded HMAC-SHA256 of Commit and Files, keyed by
+	// the hydration signing key. It is empty if no key is configured.
+	Signature string `json:"signature,omitempty"`
+}
+
+// ManifestPath returns the path to the manifest file of the hydrated directory.
+func ManifestPath(hydratedDir string) string {
+	return filepath.Clean(hydratedDir) + ManifestSuffix
+}
+
+// WriteManifest computes the manifest of the hydrated directory, signs it with
+// the key if not empty, and writes it next to the directory.
+func WriteManifest(hydratedDir string, key []byte) error {
+	files, err := digestFiles(hydratedDir)
+	if err != nil {
+		return err
+	}
+	m := Manifest{
+		Commit: filepath.Base(hydratedDir),
+		Files:  files,
+	}
+	if len(key) > 0 {
+		if m.Signature, err = m.sign(key); err != nil {
+			return err
+		}
+	}
+	content, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode the hydration manifest")
+	}
+
+	// Write to a temporary file first, so the reconciler never reads a
+	// partially written manifest.
+	manifestPath := ManifestPath(hydratedDir)
+	tmpFile, err := os.CreateTemp(filepath.Dir(manifestPath), "tmp-manifest-")
+	if err != nil {
+		return errors.Wrapf(err, "unable to create temporary manifest file under %s", filepath.Dir(manifestPath))
+	}
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrapf(err, "unable to write to temporary manifest file: %s", tmpFile.Name())
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrapf(err, "unable to close temporary manifest file: %s", tmpFile.Name())
+	}
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return errors.Wrapf(err, "unable to change permissions on the manifest file: %s", tmpFile.Name())
+	}
+	if err := os.Rename(tmpFile.Name(), manifestPath); err != nil {
+		return errors.Wrapf(err, "unable to rename %s to %s", tmpFile.Name(), manifestPath)
+	}
+	return nil
+}
+
+// VerifyManifest checks that the content of the hydrated directory matches its
+// manifest, and that the manifest is signed with the key if not empty.
+func VerifyManifest(hydratedDir string, key []byte) error {
+	manifestPath := ManifestPath(hydratedDir)
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the hydration manifest %s", manifestPath)
+	}
+	m := Manifest{}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return errors.Wrapf(err, "unable to decode the hydration manifest %s", manifestPath)
+	}
+	if commit := filepath.Base(hydratedDir); m.Commit != commit {
+		return fmt.Errorf("the hydration manifest %s is for commit %q, not %q", manifestPath, m.Commit, commit)
+	}
+	if len(key) > 0 {
+		if m.Signature == "" {
+			return fmt.Errorf("the hydration manifest %s is not signed", manifestPath)
+		}
+		signature, err := m.sign(key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(signature), []byte(m.Signature)) {
+			return fmt.Errorf("the hydration manifest %s has an invalid signature", manifestPath)
+		}
+	}
+
+	files, err := digestFiles(hydratedDir)
+	if err != nil {
+		return err
+	}
+	var mismatched []string
+	for file, digest := range files {
+		if expected, found := m.Files[file]; !found {
+			mismatched = append(mismatched, fmt.Sprintf("%s (unexpected)", file))
+		} else if expected != digest {
+			mismatched = append(mismatched, fmt.Sprintf("%s (modified)", file))
+		}
+	}
+	for file := range m.Files {
+		if _, found := files[file]; !found {
+			mismatched = append(mismatched, fmt.Sprintf("%s (missing)", file))
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return fmt.Errorf("the hydrated configs under %s do not match the hydration manifest: %s",
+			hydratedDir, strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// sign returns the signature of the manifest. Commit and Files are encoded as
+// JSON, which sorts the map keys, so the signature is deterministic.
+func (m Manifest) sign(key []byte) (string, error) {
+	payload, err := json.Marshal(Manifest{Commit: m.Commit, Files: m.Files})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encode the hydration manifest")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// digestFiles returns the SHA-256 digests of the files under dir, keyed by the
+// slash-separated path relative to dir.
+func digestFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		digest, err := digestFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = digest
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to compute the digests of the hydrated configs under %s", dir)
+	}
+	return files, nil
+}
+
+// digestFile returns the hex encoded SHA-256 digest of the content of the file.
+func digestFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RenderedDigest returns the digest of the rendered configs, which are the
+// files under dir. The digest only depends on the paths of the files relative
+// to dir and their contents, so the identical rendered configs of different
+// commits have the same digest.
+func RenderedDigest(dir string, files []string) (string, error) {
+	digests, err := FileDigests(dir, files)
+	if err != nil {
+		return "", err
+	}
+	return CombinedDigest(digests), nil
+}
+
+// FileDigests returns the digests of the contents of the files under dir,
+// keyed by the slash-separated paths of the files relative to dir.
+func FileDigests(dir string, files []string) (map[string]string, error) {
+	digests := make(map[string]string, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to compute the digest of %s", file)
+		}
+		digest, err := digestFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to compute the digest of %s", file)
+		}
+		digests[filepath.ToSlash(rel)] = digest
+	}
+	return digests, nil
+}
+
+// CombinedDigest returns the digest of the rendered configs from the digests
+// of their files, as returned by FileDigests.
+func CombinedDigest(digests map[string]string) string {
+	paths := make([]string, 0, len(digests))
+	for path := range digests {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", path, digests[path])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyManifest(t *testing.T) {
+	key := []byte("signing-key")
+	testCases := []struct {
+		name      string
+		writeKey  []byte
+		verifyKey []byte
+		mutate    func(t *testing.T, hydratedDir string)
+		wantErr   string
+	}{
+		{
+			name: "unsigned manifest without a key",
+		},
+		{
+			name:      "signed manifest with the same key",
+			writeKey:  key,
+			verifyKey: key,
+		},
+		{
+			name:      "unsigned manifest with a key",
+			verifyKey: key,
+			wantErr:   "is not signed",
+		},
+		{
+			name:      "signed manifest with a different key",
+			writeKey:  []byte("other-key"),
+			verifyKey: key,
+			wantErr:   "has an invalid signature",
+		},
+		{
+			name: "missing manifest",
+			mutate: func(t *testing.T, hydratedDir string) {
+				if err := os.Remove(ManifestPath(hydratedDir)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "unable to read the hydration manifest",
+		},
+		{
+			name: "modified file",
+			mutate: func(t *testing.T, hydratedDir string) {
+				writeTestFile(t, filepath.Join(hydratedDir, "ns.yaml"), "kind: Namespace\nmetadata:\n  name: bar")
+			},
+			wantErr: "ns.yaml (modified)",
+		},
+		{
+			name: "unexpected file",
+			mutate: func(t *testing.T, hydratedDir string) {
+				writeTestFile(t, filepath.Join(hydratedDir, "extra.yaml"), "kind: Namespace")
+			},
+			wantErr: "extra.yaml (unexpected)",
+		},
+		{
+			name: "missing file",
+			mutate: func(t *testing.T, hydratedDir string) {
+				if err := os.Remove(filepath.Join(hydratedDir, "sub", "cm.yaml")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "sub/cm.yaml (missing)",
+		},
+		{
+			name:      "tampered manifest",
+			writeKey:  key,
+			verifyKey: key,
+			mutate: func(t *testing.T, hydratedDir string) {
+				// Update the digest of a modified file without re-signing.
+				writeTestFile(t, filepath.Join(hydratedDir, "ns.yaml"), "kind: Namespace\nmetadata:\n  name: bar")
+				content, err := os.ReadFile(ManifestPath(hydratedDir))
+				if err != nil {
+					t.Fatal(err)
+				}
+				m := Manifest{}
+				if err := json.Unmarshal(content, &m); err != nil {
+					t.Fatal(err)
+				}
+				files, err := digestFiles(hydratedDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				m.Files = files
+				content, err = json.Marshal(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				writeTestFile(t, ManifestPath(hydratedDir), string(content))
+			},
+			wantErr: "has an invalid signature",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hydratedDir := filepath.Join(t.TempDir(), "abcd123")
+			writeTestFile(t, filepath.Join(hydratedDir, "ns.yaml"), "kind: Namespace\nmetadata:\n  name: foo")
+			writeTestFile(t, filepath.Join(hydratedDir, "sub", "cm.yaml"), "kind: ConfigMap")
+			if err := WriteManifest(hydratedDir, tc.writeKey); err != nil {
+				t.Fatal(err)
+			}
+			if tc.mutate != nil {
+				tc.mutate(t, hydratedDir)
+			}
+
+			err := VerifyManifest(hydratedDir, tc.verifyKey)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyManifest() got error %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("VerifyManifest() got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderedDigest(t *testing.T) {
+	digest := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), "abcd123")
+		var paths []string
+		for path, content := range files {
+			writeTestFile(t, filepath.Join(dir, path), content)
+			paths = append(paths, filepath.Join(dir, path))
+		}
+		d, err := RenderedDigest(dir, paths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	base := digest(t, map[string]string{
+		"ns.yaml":     "kind: Namespace",
+		"sub/cm.yaml": "kind: ConfigMap",
+	})
+	if !strings.HasPrefix(base, "sha256:") {
+		t.Errorf("RenderedDigest() got %q, want a sha256 digest", base)
+	}
+
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		wantEqual bool
+	}{
+		{
+			name: "same configs in a different directory",
+			files: map[string]string{
+				"sub/cm.yaml": "kind: ConfigMap",
+				"ns.yaml":     "kind: Namespace",
+			},
+			wantEqual: true,
+		},
+		{
+			name: "changed content",
+			files: map[string]string{
+				"ns.yaml":     "kind: Namespace",
+				"sub/cm.yaml": "kind: Secret",
+			},
+		},
+		{
+			name: "renamed file",
+			files: map[string]string{
+				"ns.yaml":    "kind: Namespace",
+				"sub/cm.yml": "kind: ConfigMap",
+			},
+		},
+		{
+			name: "removed file",
+			files: map[string]string{
+				"ns.yaml": "kind: Namespace",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := digest(t, tc.files)
+			if (got == base) != tc.wantEqual {
+				t.Errorf("RenderedDigest() got %q, base %q, want equal: %t", got, base, tc.wantEqual)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
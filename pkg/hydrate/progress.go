@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+const (
+	// ProgressFile is the file name of the rendering progress, which the
+	// hydration-controller updates while it renders a commit.
+	ProgressFile = "progress.json"
+
+	// StageKustomizeBuild is the rendering stage which runs kustomize build.
+	StageKustomizeBuild = "running kustomize build"
+	// StageWriteManifest is the rendering stage which writes the manifest of
+	// the rendered configs.
+	StageWriteManifest = "writing the manifest"
+)
+
+// Progress is the progress of the rendering of a commit, which the reconciler
+// reports in .status.rendering.message while the rendering is in progress.
+type Progress struct {
+	// Commit is the commit being rendered.
+	Commit string `json:"commit"`
+	// Stage is the rendering stage in progress.
+	Stage string `json:"stage"`
+	// Target is the directory built by kustomize, relative to the source root.
+	Target string `json:"target,omitempty"`
+	// FilesProcessed is the number of rendered files written so far.
+	FilesProcessed int `json:"filesProcessed,omitempty"`
+	// StartTime is when the rendering of the commit started.
+	StartTime time.Time `json:"startTime"`
+}
+
+// Describe returns a description of the progress, with the time elapsed
+// since the rendering started until now.
+func (p *Progress) Describe(now time.Time) string {
+	parts := []string{p.Stage}
+	if p.Target != "" {
+		parts[0] = fmt.Sprintf("%s in %s", p.Stage, p.Target)
+	}
+	if p.FilesProcessed > 0 {
+		parts = append(parts, fmt.Sprintf("%d files processed", p.FilesProcessed))
+	}
+	parts = append(parts, fmt.Sprintf("%v elapsed", now.Sub(p.StartTime).Round(time.Second)))
+	return strings.Join(parts, ", ")
+}
+
+// ReadProgress reads the progress file. It returns nil if there is no
+// rendering in progress.
+func ReadProgress(progressPath string) (*Progress, error) {
+	b, err := os.ReadFile(progressPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "unable to read the progress file %s", progressPath)
+	}
+	progress := &Progress{}
+	if err := json.Unmarshal(b, progress); err != nil {
+		return nil, errors.Wrapf(err, "unable to decode the progress file %s", progressPath)
+	}
+	return progress, nil
+}
+
+// reportProgress replaces the progress file with the progress. A failure is
+// only logged, since the progress is informational.
+func (h *Hydrator) reportProgress(progress *Progress) {
+	if h.ProgressPath == "" {
+		return
+	}
+	if err := writeProgress(h.ProgressPath.OSPath(), progress); err != nil {
+		klog.Warningf("unable to report the rendering progress: %v", err)
+	}
+}
+
+// clearProgress removes the progress file once the rendering completes.
+func (h *Hydrator) clearProgress() {
+	if h.ProgressPath == "" {
+		return
+	}
+	if err := os.Remove(h.ProgressPath.OSPath()); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("unable to remove the progress file %s: %v", h.ProgressPath.OSPath(), err)
+	}
+}
+
+// writeProgress writes the progress to a temporary file, and renames it to the
+// progress file, so the reconciler never reads a partially written file.
+func writeProgress(progressPath string, progress *Progress) error {
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(progressPath), "tmp-progress-")
+	if err != nil {
+		return errors.Wrapf(err, "unable to create the temporary progress file under directory %s", filepath.Dir(progressPath))
+	}
+	if _, err := tmpFile.Write(b); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrapf(err, "unable to write to the temporary progress file %s", tmpFile.Name())
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrapf(err, "unable to close the temporary progress file %s", tmpFile.Name())
+	}
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrapf(err, "unable to change permissions on the temporary progress file %s", tmpFile.Name())
+	}
+	if err := os.Rename(tmpFile.Name(), progressPath); err != nil {
+		return errors.Wrapf(err, "unable to rename %s to %s", tmpFile.Name(), progressPath)
+	}
+	return nil
+}
+
+// countFiles returns the number of regular files under dir.
+func countFiles(dir cmpath.Absolute) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir.OSPath(), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
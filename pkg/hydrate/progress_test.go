@@ -0,0 +1,83 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestProgressDescribe(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		progress Progress
+		want     string
+	}{
+		{
+			name:     "kustomize build",
+			progress: Progress{Stage: StageKustomizeBuild, Target: "clusters/prod", StartTime: start},
+			want:     "running kustomize build in clusters/prod, 1m5s elapsed",
+		},
+		{
+			name:     "files processed",
+			progress: Progress{Stage: StageWriteManifest, FilesProcessed: 12, StartTime: start},
+			want:     "writing the manifest, 12 files processed, 1m5s elapsed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.progress.Describe(start.Add(65*time.Second + 300*time.Millisecond)); got != tc.want {
+				t.Errorf("got Describe() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	progressPath := filepath.Join(t.TempDir(), ProgressFile)
+	h := &Hydrator{ProgressPath: cmpath.Absolute(filepath.ToSlash(progressPath))}
+
+	got, err := ReadProgress(progressPath)
+	if err != nil || got != nil {
+		t.Fatalf("got ReadProgress() = %v, %v before rendering, want nil, nil", got, err)
+	}
+
+	want := &Progress{
+		Commit:         "abcd123",
+		Stage:          StageWriteManifest,
+		Target:         "clusters/prod",
+		FilesProcessed: 3,
+		StartTime:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	h.reportProgress(want)
+	got, err = ReadProgress(progressPath)
+	if err != nil {
+		t.Fatalf("got ReadProgress() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("got ReadProgress() diff (-want +got):\n%s", diff)
+	}
+
+	h.clearProgress()
+	got, err = ReadProgress(progressPath)
+	if err != nil || got != nil {
+		t.Errorf("got ReadProgress() = %v, %v after rendering, want nil, nil", got, err)
+	}
+}
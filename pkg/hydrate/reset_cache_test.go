@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestPurgeOnRequest(t *testing.T) {
+	root := t.TempDir()
+	hydratedRoot := filepath.Join(root, "hydrated")
+	donePath := filepath.Join(root, DoneFile)
+	writeHydrated := func(t *testing.T) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Join(hydratedRoot, originCommit), 0755))
+		require.NoError(t, os.WriteFile(donePath, []byte(originCommit), 0644))
+	}
+	requirePurged := func(t *testing.T, want bool) {
+		t.Helper()
+		_, err := os.Stat(hydratedRoot)
+		require.Equal(t, want, os.IsNotExist(err))
+		require.Equal(t, want, DoneCommit(donePath) == "")
+	}
+
+	token := "t1"
+	h := &Hydrator{
+		DonePath:     cmpath.Absolute(filepath.ToSlash(donePath)),
+		HydratedRoot: cmpath.Absolute(filepath.ToSlash(hydratedRoot)),
+		ResetCacheToken: func(context.Context) (string, error) {
+			return token, nil
+		},
+	}
+	ctx := context.Background()
+
+	// The token observed when the hydration-controller starts is not acted
+	// upon.
+	writeHydrated(t)
+	h.purgeOnRequest(ctx)
+	requirePurged(t, false)
+
+	// An unchanged token does not purge the hydrated configs.
+	h.purgeOnRequest(ctx)
+	requirePurged(t, false)
+
+	// A new token purges the hydrated configs once.
+	token = "t2"
+	h.purgeOnRequest(ctx)
+	requirePurged(t, true)
+	writeHydrated(t)
+	h.purgeOnRequest(ctx)
+	requirePurged(t, false)
+}
@@ -284,6 +284,10 @@ func ValidateHydrateFlags(sourceFormat filesystem.SourceFormat) (cmpath.Absolute
 // ValidateOptions returns the validate options for nomos hydrate and vet commands.
 func ValidateOptions(ctx context.Context, rootDir cmpath.Absolute, apiServerTimeout time.Duration) (validate.Options, error) {
 	var options = validate.Options{}
+	if flags.ClusterState != "" {
+		return clusterStateOptions(rootDir)
+	}
+
 	syncedCRDs, err := nomosparse.GetSyncedCRDs(ctx, flags.SkipAPIServer, apiServerTimeout)
 	if err != nil {
 		return options, err
@@ -324,3 +328,38 @@ func ValidateOptions(ctx context.Context, rootDir cmpath.Absolute, apiServerTime
 	options.AllowUnknownKinds = flags.SkipAPIServer
 	return options, nil
 }
+
+// clusterStateOptions returns the options for validating against the fake
+// cluster state passed with --cluster-state, instead of the API Server.
+func clusterStateOptions(rootDir cmpath.Absolute) (validate.Options, error) {
+	var options = validate.Options{}
+	state, err := ReadClusterState()
+	if err != nil {
+		return options, err
+	}
+	syncedCRDs, errs := state.SyncedCRDs()
+	if errs != nil {
+		return options, errs
+	}
+
+	addFunc := vet.AddCachedAPIResources(rootDir.Join(vet.APIResourcesPath))
+
+	options.PolicyDir = cmpath.RelativeOS(rootDir.OSPath())
+	options.PreviousCRDs = syncedCRDs
+	options.BuildScoper = discovery.ScoperBuilder(discovery.NoOpServerResourcer{}, addFunc, state.AddResources())
+	options.AllowUnknownKinds = false
+	return options, nil
+}
+
+// ReadClusterState reads the fake cluster state passed with --cluster-state.
+func ReadClusterState() (*vet.ClusterState, error) {
+	abs, err := filepath.Abs(flags.ClusterState)
+	if err != nil {
+		return nil, err
+	}
+	state, errs := vet.ReadClusterState(cmpath.Absolute(abs))
+	if errs != nil {
+		return nil, errs
+	}
+	return state, nil
+}
@@ -44,6 +44,10 @@ type FileObject struct {
 	*unstructured.Unstructured
 	// Relative is the path of this object in the repo prefixed by the Nomos Root.
 	cmpath.Relative
+	// DefaultNamespace is the namespace to set on the object if it is
+	// namespace-scoped and does not declare one. It is set from the defaults
+	// file of the object's directory, if any.
+	DefaultNamespace string
 }
 
 var _ client.Object = &FileObject{}
@@ -54,8 +58,9 @@ var CompareFileObject = cmp.AllowUnexported(FileObject{})
 // DeepCopy returns a deep copy of the FileObject.
 func (o *FileObject) DeepCopy() FileObject {
 	return FileObject{
-		Unstructured: o.Unstructured.DeepCopy(),
-		Relative:     o.Relative,
+		Unstructured:     o.Unstructured.DeepCopy(),
+		Relative:         o.Relative,
+		DefaultNamespace: o.DefaultNamespace,
 	}
 }
 
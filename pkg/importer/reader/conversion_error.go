@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"kpt.dev/configsync/pkg/importer/id"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// ConversionErrorCode is the error code for when the converter plugin fails
+// to convert a source file into Kubernetes manifests.
+const ConversionErrorCode = "1081"
+
+var conversionErrorBase = status.NewErrorBuilder(ConversionErrorCode)
+
+// ConversionError reports that the converter plugin failed to convert the
+// file, or printed manifests which could not be parsed.
+func ConversionError(err error, file id.Path) status.Error {
+	return conversionErrorBase.Wrap(err).BuildWithPaths(file)
+}
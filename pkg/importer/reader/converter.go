@@ -0,0 +1,29 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+// Converter converts the source files which are not Kubernetes manifests,
+// e.g. files written in an in-house DSL, into Kubernetes manifests.
+type Converter interface {
+	// Converts returns true if the Converter converts the file.
+	Converts(file cmpath.Absolute) bool
+
+	// Convert returns the YAML manifests converted from the file.
+	Convert(file cmpath.Absolute) ([]byte, error)
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/yaml"
+)
+
+// DirectoryDefaultsFile is the name of the file which sets the defaults for
+// the objects declared in the same directory. Subdirectories are not affected.
+const DirectoryDefaultsFile = ".configsync-defaults.yaml"
+
+// DirectoryDefaults is the content of a DirectoryDefaultsFile.
+type DirectoryDefaults struct {
+	// Namespace is the namespace to set on the namespace-scoped objects which do
+	// not declare one. It is only used in unstructured repositories, since the
+	// namespaces of objects in hierarchical repositories are set by their
+	// directory.
+	Namespace string `json:"namespace,omitempty"`
+	// Labels are added to the objects, unless the objects declare the same
+	// label keys.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the objects, unless the objects declare the
+	// same annotation keys.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// isDirectoryDefaultsFile returns true if the file is a DirectoryDefaultsFile.
+func isDirectoryDefaultsFile(file cmpath.Absolute) bool {
+	return filepath.Base(file.OSPath()) == DirectoryDefaultsFile
+}
+
+// readDirectoryDefaults reads the DirectoryDefaultsFile.
+func readDirectoryDefaults(file cmpath.Absolute) (*DirectoryDefaults, status.Error) {
+	contents, err := os.ReadFile(file.OSPath())
+	if err != nil {
+		return nil, status.PathWrapError(err, file.OSPath())
+	}
+	contents, err = normalizeManifest(contents)
+	if err != nil {
+		return nil, status.PathWrapError(err, file.OSPath())
+	}
+	defaults := &DirectoryDefaults{}
+	if err := yaml.UnmarshalStrict(contents, defaults); err != nil {
+		return nil, status.PathWrapError(errors.Wrapf(err, "invalid %s", DirectoryDefaultsFile), file.OSPath())
+	}
+	return defaults, nil
+}
+
+// apply sets the defaults on the object. The values declared by the object
+// take precedence over the defaults.
+func (d *DirectoryDefaults) apply(obj *ast.FileObject) {
+	if len(d.Labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for k, v := range d.Labels {
+			if _, found := labels[k]; !found {
+				labels[k] = v
+			}
+		}
+		obj.SetLabels(labels)
+	}
+	if len(d.Annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for k, v := range d.Annotations {
+			if _, found := annotations[k]; !found {
+				annotations[k] = v
+			}
+		}
+		obj.SetAnnotations(annotations)
+	}
+	if obj.GetNamespace() == "" {
+		obj.DefaultNamespace = d.Namespace
+	}
+}
@@ -15,8 +15,11 @@
 package reader
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -33,6 +36,31 @@ import (
 // yamlWhitespace records the two valid YAML whitespace characters.
 const yamlWhitespace = " \t"
 
+var (
+	// utf8BOM is the byte order mark some Windows editors write at the start
+	// of UTF-8 files.
+	utf8BOM = []byte("\xef\xbb\xbf")
+	// utf16BOMs are the byte order marks of UTF-16 files.
+	utf16BOMs = [][]byte{[]byte("\xff\xfe"), []byte("\xfe\xff")}
+)
+
+// normalizeManifest removes the UTF-8 byte order marks and converts the CRLF
+// line endings of manifests authored on Windows, which otherwise cause
+// confusing decoding errors. UTF-16 encoded manifests are rejected with an
+// explicit error, since they cannot be decoded.
+func normalizeManifest(contents []byte) ([]byte, error) {
+	for _, bom := range utf16BOMs {
+		if bytes.HasPrefix(contents, bom) {
+			return nil, errors.New("the file is UTF-16 encoded, but manifests must be UTF-8 encoded")
+		}
+	}
+	contents = bytes.TrimPrefix(contents, utf8BOM)
+	// Concatenated files may have a byte order mark at the start of every
+	// document.
+	contents = bytes.ReplaceAll(contents, append([]byte("\n"), utf8BOM...), []byte("\n"))
+	return bytes.ReplaceAll(contents, []byte("\r\n"), []byte("\n")), nil
+}
+
 func parseFile(path string) ([]*unstructured.Unstructured, error) {
 	if !filepath.IsAbs(path) {
 		return nil, errors.New("attempted to read relative path")
@@ -47,7 +75,7 @@ func parseFile(path string) ([]*unstructured.Unstructured, error) {
 			return nil, err
 		}
 		return parseYAMLFile(contents)
-	case ".json":
+	case ".json", ".jsonl", ".ndjson":
 		contents, err := ioutil.ReadFile(path)
 		if err != nil {
 			klog.Errorf("Failed to read file declared in git from mounted filesystem: %s", path)
@@ -74,6 +102,11 @@ func isEmptyYAMLDocument(document string) bool {
 }
 
 func parseYAMLFile(contents []byte) ([]*unstructured.Unstructured, error) {
+	contents, err := normalizeManifest(contents)
+	if err != nil {
+		return nil, err
+	}
+
 	// We have to manually split documents with the YAML separator since by default
 	// yaml.Unmarshal only unmarshalls the first document, but a file may contain multiple.
 	var result []*unstructured.Unstructured
@@ -96,21 +129,35 @@ func parseYAMLFile(contents []byte) ([]*unstructured.Unstructured, error) {
 	return filterLocalConfigUnstructured(result), nil
 }
 
+// parseJSONFile parses a stream of JSON objects, e.g. a single object, or
+// JSON lines with one object per line.
+// While an empty file is not valid JSON, Kubernetes allows empty JSON files
+// when applying multiple files.
+// Kubernetes does not recognize arrays of Kubernetes objects in JSON files, so
+// neither do we.
 func parseJSONFile(contents []byte) ([]*unstructured.Unstructured, error) {
-	if len(contents) == 0 {
-		// While an empty files is not valid JSON, Kubernetes allows empty JSON
-		// files when applying multiple files.
-		return nil, nil
-	}
-	// Kubernetes does not recognize arrays of Kubernetes objects in JSON files.
-	// A single file must contain exactly one Kubernetes object, so we don't
-	// have to do the same work we had to do for YAML.
-	var u unstructured.Unstructured
-	err := u.UnmarshalJSON(contents)
+	contents, err := normalizeManifest(contents)
 	if err != nil {
 		return nil, err
 	}
-	return filterLocalConfigUnstructured([]*unstructured.Unstructured{&u}), nil
+
+	var result []*unstructured.Unstructured
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		var u unstructured.Unstructured
+		if err := u.UnmarshalJSON(document); err != nil {
+			return nil, err
+		}
+		result = append(result, &u)
+	}
+	return filterLocalConfigUnstructured(result), nil
 }
 
 func parseKptfile(contents []byte) ([]*unstructured.Unstructured, error) {
@@ -165,6 +165,19 @@ metadata:
     config.kubernetes.io/local-config: "true"
 `,
 		},
+		{
+			name:     "byte order mark and CRLF line endings",
+			contents: "\xef\xbb\xbfapiVersion: v1\r\nkind: Namespace\r\nmetadata:\r\n  name: shipping\r\n---\r\n\xef\xbb\xbfapiVersion: v1\r\nkind: Namespace\r\nmetadata:\r\n  name: billing\r\n",
+			expected: []*unstructured.Unstructured{
+				fake.UnstructuredObject(kinds.Namespace(), core.Name("shipping")),
+				fake.UnstructuredObject(kinds.Namespace(), core.Name("billing")),
+			},
+		},
+		{
+			name:      "UTF-16",
+			contents:  "\xff\xfea\x00p\x00i\x00",
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -303,6 +316,59 @@ func TestParseJsonFile(t *testing.T) {
 }
 `,
 		},
+		{
+			name: "multiple objects",
+			contents: `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shipping"}}
+{"apiVersion": "rbac/v1", "kind": "Role", "metadata": {"name": "admin", "namespace": "shipping"}}
+
+{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "config", "namespace": "shipping"}
+}
+`,
+			expected: []*unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Namespace",
+						"metadata": map[string]interface{}{
+							"name": "shipping",
+						},
+					},
+				},
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "rbac/v1",
+						"kind":       "Role",
+						"metadata": map[string]interface{}{
+							"name":      "admin",
+							"namespace": "shipping",
+						},
+					},
+				},
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "ConfigMap",
+						"metadata": map[string]interface{}{
+							"name":      "config",
+							"namespace": "shipping",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "array of objects",
+			contents:  `[{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shipping"}}]`,
+			expectErr: true,
+		},
+		{
+			name:      "truncated object",
+			contents:  `{"apiVersion": "v1", "kind": "Namespace"}` + "\n" + `{"apiVersion": "v1",`,
+			expectErr: true,
+		},
 		{
 			name: "ignore local configuration (with local-config: True)",
 			contents: `{
@@ -324,6 +390,21 @@ func TestParseJsonFile(t *testing.T) {
 }
 `,
 		},
+		{
+			name:     "byte order mark and CRLF line endings",
+			contents: "\xef\xbb\xbf{\r\n  \"apiVersion\": \"v1\",\r\n  \"kind\": \"Namespace\",\r\n  \"metadata\": {\r\n    \"name\": \"shipping\"\r\n  }\r\n}\r\n",
+			expected: []*unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Namespace",
+						"metadata": map[string]interface{}{
+							"name": "shipping",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
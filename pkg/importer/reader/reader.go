@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -53,20 +54,89 @@ type FilePaths struct {
 }
 
 // File reads FileObjects from a filesystem.
-type File struct{}
+type File struct {
+	// Concurrency is the largest number of files read at once. The files are
+	// read one at a time if it is less than 2.
+	Concurrency int
+
+	// Converter converts the files which are not Kubernetes manifests. The
+	// files are parsed as manifests if it is nil.
+	Converter Converter
+}
 
 var _ Reader = &File{}
 
+// fileResult is the result of reading a single file.
+type fileResult struct {
+	objs []ast.FileObject
+	errs status.MultiError
+}
+
 func (r *File) Read(filePaths FilePaths) ([]ast.FileObject, status.MultiError) {
 	var objs []ast.FileObject
 	var errs status.MultiError
+
+	// Read the directory defaults first, so they can be applied to the objects
+	// regardless of the order of the files.
+	dirDefaults := make(map[string]*DirectoryDefaults)
 	for _, f := range filePaths.Files {
-		newObjs, err := r.read(filePaths.RootDir, filePaths.PolicyDir, f)
+		if !isDirectoryDefaultsFile(f) {
+			continue
+		}
+		defaults, err := readDirectoryDefaults(f)
 		if err != nil {
 			errs = status.Append(errs, err)
 			continue
 		}
-		objs = append(objs, newObjs...)
+		dirDefaults[filepath.Dir(f.OSPath())] = defaults
+	}
+
+	readFile := func(f cmpath.Absolute) fileResult {
+		newObjs, err := r.read(filePaths.RootDir, filePaths.PolicyDir, f)
+		if err != nil {
+			return fileResult{errs: err}
+		}
+		if defaults, found := dirDefaults[filepath.Dir(f.OSPath())]; found {
+			for i := range newObjs {
+				defaults.apply(&newObjs[i])
+			}
+		}
+		return fileResult{objs: newObjs}
+	}
+
+	// The results are merged in the order of the files, so that the objects
+	// and errors do not depend on the order the files were read in.
+	results := make([]fileResult, len(filePaths.Files))
+	if r.Concurrency < 2 {
+		for i, f := range filePaths.Files {
+			if !isDirectoryDefaultsFile(f) {
+				results[i] = readFile(f)
+			}
+		}
+	} else {
+		indices := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < r.Concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indices {
+					results[i] = readFile(filePaths.Files[i])
+				}
+			}()
+		}
+		for i, f := range filePaths.Files {
+			if !isDirectoryDefaultsFile(f) {
+				indices <- i
+			}
+		}
+		close(indices)
+		wg.Wait()
+	}
+
+	for _, result := range results {
+		errs = status.Append(errs, result.errs)
+		objs = append(objs, result.objs...)
 	}
 	if errs != nil {
 		return nil, errs
@@ -84,9 +154,22 @@ func (r *File) read(rootDir cmpath.Absolute, policyDir cmpath.Relative, file cmp
 		}
 	}
 
-	unstructureds, err := parseFile(file.OSPath())
-	if err != nil {
-		return nil, status.PathWrapError(err, file.OSPath())
+	var unstructureds []*unstructured.Unstructured
+	if r.Converter != nil && r.Converter.Converts(file) {
+		contents, err := r.Converter.Convert(file)
+		if err != nil {
+			return nil, ConversionError(err, file)
+		}
+		unstructureds, err = parseYAMLFile(contents)
+		if err != nil {
+			return nil, ConversionError(errors.Wrap(err, "failed to parse the converted manifests"), file)
+		}
+	} else {
+		var err error
+		unstructureds, err = parseFile(file.OSPath())
+		if err != nil {
+			return nil, status.PathWrapError(err, file.OSPath())
+		}
 	}
 
 	var fileObjects []ast.FileObject
@@ -15,11 +15,16 @@
 package reader_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	ft "kpt.dev/configsync/pkg/importer/filesystem/filesystemtest"
 	"kpt.dev/configsync/pkg/importer/reader"
 	"kpt.dev/configsync/pkg/status"
@@ -228,3 +233,214 @@ workflow:
 		}
 	}
 }
+
+func TestFileReader_Read_DirectoryDefaults(t *testing.T) {
+	dir := ft.NewTestDir(t,
+		ft.FileContents("bookstore/"+reader.DirectoryDefaultsFile, `
+namespace: bookstore
+labels:
+  team: bookstore
+  tier: backend
+annotations:
+  owner: bookstore-admins
+`),
+		ft.FileContents("bookstore/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  labels:
+    tier: frontend
+`),
+		ft.FileContents("bookstore/other-ns.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: other
+`),
+		ft.FileContents("bookstore/sub/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: sub
+`))
+	fps := dir.FilePaths("bookstore/cm.yaml", "bookstore/"+reader.DirectoryDefaultsFile, "bookstore/other-ns.yaml", "bookstore/sub/cm.yaml")
+	r := reader.File{}
+	objs, err := r.Read(fps)
+	if err != nil {
+		t.Fatalf("got Read() = %v, want nil", err)
+	}
+	if len(objs) != 3 {
+		t.Fatalf("got Read() = %d objects, want 3", len(objs))
+	}
+
+	cm := objs[0]
+	if got := cm.GetLabels(); got["team"] != "bookstore" || got["tier"] != "frontend" {
+		t.Errorf("got labels %v, want the default team label and the declared tier label", got)
+	}
+	if got := cm.GetAnnotations()["owner"]; got != "bookstore-admins" {
+		t.Errorf("got owner annotation %q, want %q", got, "bookstore-admins")
+	}
+	if cm.DefaultNamespace != "bookstore" {
+		t.Errorf("got DefaultNamespace %q, want %q", cm.DefaultNamespace, "bookstore")
+	}
+
+	otherNS := objs[1]
+	if otherNS.DefaultNamespace != "" {
+		t.Errorf("got DefaultNamespace %q for an object declaring its namespace, want empty", otherNS.DefaultNamespace)
+	}
+
+	sub := objs[2]
+	if _, found := sub.GetLabels()["team"]; found || sub.DefaultNamespace != "" {
+		t.Errorf("got defaults applied to an object in a subdirectory: labels %v, DefaultNamespace %q", sub.GetLabels(), sub.DefaultNamespace)
+	}
+}
+
+func TestFileReader_Read_InvalidDirectoryDefaults(t *testing.T) {
+	dir := ft.NewTestDir(t,
+		ft.FileContents(reader.DirectoryDefaultsFile, `
+commonLabels:
+  team: bookstore
+`))
+	fps := dir.FilePaths(reader.DirectoryDefaultsFile)
+	r := reader.File{}
+	_, err := r.Read(fps)
+	if err == nil {
+		t.Fatal("got Read() = nil, want err")
+	}
+}
+
+func TestFileReader_Read_Concurrency(t *testing.T) {
+	var opts []ft.TestDirOpt
+	var files []string
+	for i := 0; i < 50; i++ {
+		file := fmt.Sprintf("bookstore/cm-%02d.yaml", i)
+		contents := fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-%02d
+`, i)
+		if i%10 == 0 {
+			// Objects with a status are invalid.
+			contents += "status:\n  phase: invalid\n"
+		}
+		opts = append(opts, ft.FileContents(file, contents))
+		files = append(files, file)
+	}
+	dir := ft.NewTestDir(t, opts...)
+	fps := dir.FilePaths(files...)
+
+	serial := reader.File{}
+	_, wantErr := serial.Read(fps)
+	if wantErr == nil {
+		t.Fatal("got serial Read() = nil, want err")
+	}
+
+	concurrent := reader.File{Concurrency: 8}
+	_, gotErr := concurrent.Read(fps)
+	if gotErr == nil || gotErr.Error() != wantErr.Error() {
+		t.Errorf("got concurrent Read() = %v, want %v", gotErr, wantErr)
+	}
+
+	// Without the invalid files, the objects are returned in the order of the
+	// files.
+	var validFiles []string
+	for i, file := range files {
+		if i%10 != 0 {
+			validFiles = append(validFiles, file)
+		}
+	}
+	objs, err := concurrent.Read(dir.FilePaths(validFiles...))
+	if err != nil {
+		t.Fatalf("got concurrent Read() = %v, want nil", err)
+	}
+	if len(objs) != len(validFiles) {
+		t.Fatalf("got concurrent Read() = %d objects, want %d", len(objs), len(validFiles))
+	}
+	for i, obj := range objs {
+		if want := path.Base(validFiles[i]); path.Base(obj.SlashPath()) != want {
+			t.Errorf("got object %d from %q, want %q", i, obj.SlashPath(), want)
+		}
+	}
+}
+
+// fakeConverter converts the .dsl files into a ConfigMap named after the file
+// contents, and fails to convert the empty ones.
+type fakeConverter struct{}
+
+func (fakeConverter) Converts(file cmpath.Absolute) bool {
+	return filepath.Ext(file.OSPath()) == ".dsl"
+}
+
+func (fakeConverter) Convert(file cmpath.Absolute) ([]byte, error) {
+	contents, err := os.ReadFile(file.OSPath())
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(string(contents))
+	if name == "" {
+		return nil, errors.New("empty file")
+	}
+	if name == "invalid" {
+		return []byte("apiVersion: v1\nkind: ConfigMap\nmetadata: [\n"), nil
+	}
+	return []byte(fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n", name)), nil
+}
+
+func TestFileReader_Read_Converter(t *testing.T) {
+	testCases := []struct {
+		name      string
+		contents  string
+		wantName  string
+		wantError bool
+	}{
+		{
+			name:     "converted file",
+			contents: "converted",
+			wantName: "converted",
+		},
+		{
+			name:      "converter fails",
+			contents:  "",
+			wantError: true,
+		},
+		{
+			name:      "converter prints invalid manifests",
+			contents:  "invalid",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := ft.NewTestDir(t,
+				ft.FileContents("bookstore/cm.dsl", tc.contents),
+				ft.FileContents("bookstore/cm.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: manifest\n"))
+
+			r := reader.File{Converter: fakeConverter{}}
+			objs, err := r.Read(dir.FilePaths("bookstore/cm.dsl", "bookstore/cm.yaml"))
+			if tc.wantError {
+				if err == nil || err.Errors()[0].Code() != reader.ConversionErrorCode {
+					t.Fatalf("got Read() error = %v, want a %s error", err, reader.ConversionErrorCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got Read() error = %v, want nil", err)
+			}
+			var names []string
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+			}
+			want := []string{tc.wantName, "manifest"}
+			if diff := cmp.Diff(want, names); diff != "" {
+				t.Errorf("got Read() objects diff (-want +got):\n%s", diff)
+			}
+			if got := objs[0].SlashPath(); got != "bookstore/cm.dsl" {
+				t.Errorf("got converted object path %q, want %q", got, "bookstore/cm.dsl")
+			}
+		})
+	}
+}
@@ -224,6 +224,11 @@ func RepoSyncV1Beta1() schema.GroupVersionKind {
 	return configsyncv1beta1.SchemeGroupVersion.WithKind(configsync.RepoSyncKind)
 }
 
+// NamespaceRequest returns the NamespaceRequest GroupVersionKind.
+func NamespaceRequest() schema.GroupVersionKind {
+	return configsyncv1beta1.SchemeGroupVersion.WithKind(configsync.NamespaceRequestKind)
+}
+
 // RootSyncV1Alpha1 returns the canonical RootSync GroupVersionKind.
 func RootSyncV1Alpha1() schema.GroupVersionKind {
 	return v1alpha1.SchemeGroupVersion.WithKind(configsync.RootSyncKind)
@@ -263,3 +268,8 @@ func APIService() schema.GroupVersionKind {
 func ValidatingWebhookConfiguration() schema.GroupVersionKind {
 	return admissionv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration")
 }
+
+// MutatingWebhookConfiguration returns the MutatingWebhookConfiguration kind.
+func MutatingWebhookConfiguration() schema.GroupVersionKind {
+	return admissionv1.SchemeGroupVersion.WithKind("MutatingWebhookConfiguration")
+}
@@ -16,6 +16,7 @@ package kinds
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -23,3 +24,8 @@ import (
 func DeploymentResource() schema.GroupVersionResource {
 	return appsv1.SchemeGroupVersion.WithResource("deployments")
 }
+
+// JobResource returns the canonical Job GroupVersionResource.
+func JobResource() schema.GroupVersionResource {
+	return batchv1.SchemeGroupVersion.WithResource("jobs")
+}
@@ -0,0 +1,258 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loadtest generates synthetic fleets of RepoSyncs and reports how
+// Config Sync scales while syncing them, to catch scalability regressions
+// before release.
+package loadtest
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/syncer/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultRootDir is the default directory of the shared repository
+	// synced by the RootSync, which declares the Namespaces, RoleBindings and
+	// RepoSyncs of the fleet.
+	DefaultRootDir = "fleet"
+
+	// DefaultNamespacePrefix is the default prefix of the Namespaces of the
+	// RepoSyncs of the fleet.
+	DefaultNamespacePrefix = "load"
+
+	// DefaultClusterRole is the default ClusterRole bound to the reconcilers of
+	// the RepoSyncs of the fleet, in their Namespaces.
+	DefaultClusterRole = "edit"
+
+	// RevisionKey is the key of the ConfigMap data changed by the churn.
+	RevisionKey = "revision"
+
+	// LabelKey is the label added to every object of the fleet, so that they
+	// can be listed and cleaned up.
+	LabelKey = "configsync.gke.io/load-test"
+)
+
+// Config describes a synthetic fleet of RepoSyncs, all synced from the
+// directories of one shared repository.
+type Config struct {
+	// Name identifies the fleet. It is the value of the LabelKey label of its
+	// objects.
+	Name string `json:"name"`
+	// RepoSyncs is the number of RepoSyncs, each in its own Namespace.
+	RepoSyncs int `json:"repoSyncs"`
+	// ObjectsPerRepoSync is the number of ConfigMaps synced by every
+	// RepoSync.
+	ObjectsPerRepoSync int `json:"objectsPerRepoSync"`
+	// ChurnPercent is the percentage of the ConfigMaps of every RepoSync
+	// changed by every iteration after the first one.
+	ChurnPercent int `json:"churnPercent"`
+	// Seed makes the churn deterministic, so that runs can be compared.
+	Seed int64 `json:"seed"`
+	// RootDir is the directory of the Namespaces, RoleBindings and RepoSyncs.
+	RootDir string `json:"rootDir,omitempty"`
+	// NamespacePrefix is the prefix of the Namespaces of the RepoSyncs.
+	NamespacePrefix string `json:"namespacePrefix,omitempty"`
+	// ClusterRole is bound to the reconcilers of the RepoSyncs.
+	ClusterRole string `json:"clusterRole,omitempty"`
+}
+
+// Default sets the unset optional fields to their defaults.
+func (c *Config) Default() {
+	if c.RootDir == "" {
+		c.RootDir = DefaultRootDir
+	}
+	if c.NamespacePrefix == "" {
+		c.NamespacePrefix = DefaultNamespacePrefix
+	}
+	if c.ClusterRole == "" {
+		c.ClusterRole = DefaultClusterRole
+	}
+}
+
+// Validate returns an error if the Config does not describe a fleet.
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name must be set")
+	}
+	if c.RepoSyncs < 1 {
+		return errors.Errorf("repoSyncs must be positive, got %d", c.RepoSyncs)
+	}
+	if c.ObjectsPerRepoSync < 0 {
+		return errors.Errorf("objectsPerRepoSync must not be negative, got %d", c.ObjectsPerRepoSync)
+	}
+	if c.ChurnPercent < 0 || c.ChurnPercent > 100 {
+		return errors.Errorf("churnPercent must be between 0 and 100, got %d", c.ChurnPercent)
+	}
+	return nil
+}
+
+// Namespaces returns the Namespaces of the RepoSyncs.
+func (c *Config) Namespaces() []string {
+	namespaces := make([]string, c.RepoSyncs)
+	for i := range namespaces {
+		namespaces[i] = fmt.Sprintf("%s-%d", c.NamespacePrefix, i)
+	}
+	return namespaces
+}
+
+// RepoSync returns the RepoSync of the Namespace, made from the template.
+// The template sets the source, like the repository URL and the credentials.
+// Its name and Namespace are replaced, and it syncs the directory named after
+// the Namespace.
+func (c *Config) RepoSync(template *v1beta1.RepoSync, namespace string) *v1beta1.RepoSync {
+	rs := template.DeepCopy()
+	rs.TypeMeta.APIVersion = kinds.RepoSyncV1Beta1().GroupVersion().String()
+	rs.TypeMeta.Kind = kinds.RepoSyncV1Beta1().Kind
+	rs.Name = configsync.RepoSyncName
+	rs.Namespace = namespace
+	rs.Status = v1beta1.RepoSyncStatus{}
+	if rs.Spec.Git == nil {
+		rs.Spec.Git = &v1beta1.Git{}
+	}
+	rs.Spec.Git.Dir = namespace
+	core.SetLabel(rs, LabelKey, c.Name)
+	return rs
+}
+
+// RootObjects returns the objects of the root directory: the Namespaces,
+// RoleBindings and RepoSyncs, keyed by their path in the repository.
+func (c *Config) RootObjects(template *v1beta1.RepoSync) map[string]client.Object {
+	objs := make(map[string]client.Object, 3*c.RepoSyncs)
+	for _, ns := range c.Namespaces() {
+		namespace := &corev1.Namespace{}
+		namespace.SetGroupVersionKind(kinds.Namespace())
+		namespace.Name = ns
+		core.SetLabel(namespace, LabelKey, c.Name)
+		objs[filepath.Join(c.RootDir, fmt.Sprintf("ns-%s.yaml", ns))] = namespace
+
+		objs[filepath.Join(c.RootDir, fmt.Sprintf("rb-%s.yaml", ns))] = c.roleBinding(ns)
+		objs[filepath.Join(c.RootDir, fmt.Sprintf("reposync-%s.yaml", ns))] = c.RepoSync(template, ns)
+	}
+	return objs
+}
+
+func (c *Config) roleBinding(namespace string) *rbacv1.RoleBinding {
+	rb := &rbacv1.RoleBinding{}
+	rb.SetGroupVersionKind(kinds.RoleBinding())
+	rb.Name = configsync.RepoSyncName
+	rb.Namespace = namespace
+	core.SetLabel(rb, LabelKey, c.Name)
+	rb.Subjects = []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      core.NsReconcilerName(namespace, configsync.RepoSyncName),
+		Namespace: configmanagement.ControllerNamespace,
+	}}
+	rb.RoleRef = rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     c.ClusterRole,
+	}
+	return rb
+}
+
+// Objects returns the ConfigMaps synced by the RepoSync of the Namespace at
+// the iteration, keyed by their path in the repository. Iteration 0 is the
+// initial state, and every later iteration changes ChurnPercent of them.
+func (c *Config) Objects(namespace string, iteration int) map[string]client.Object {
+	revisions := c.revisions(namespace, iteration)
+	objs := make(map[string]client.Object, c.ObjectsPerRepoSync)
+	for i, revision := range revisions {
+		cm := &corev1.ConfigMap{}
+		cm.SetGroupVersionKind(kinds.ConfigMap())
+		cm.Name = fmt.Sprintf("cm-%d", i)
+		cm.Namespace = namespace
+		core.SetLabel(cm, LabelKey, c.Name)
+		cm.Data = map[string]string{RevisionKey: strconv.Itoa(revision)}
+		objs[filepath.Join(namespace, fmt.Sprintf("cm-%d.yaml", i))] = cm
+	}
+	return objs
+}
+
+// ChurnedObjects returns the number of objects of the fleet changed by the
+// iteration.
+func (c *Config) ChurnedObjects(iteration int) int {
+	if iteration == 0 {
+		return c.RepoSyncs * c.ObjectsPerRepoSync
+	}
+	return c.RepoSyncs * c.churnCount()
+}
+
+// churnCount returns the number of objects of every RepoSync changed by an
+// iteration, rounded up so that any churn changes something.
+func (c *Config) churnCount() int {
+	return (c.ObjectsPerRepoSync*c.ChurnPercent + 99) / 100
+}
+
+// revisions returns, for every object of the RepoSync of the Namespace, the
+// last iteration up to the specified one which changed it.
+func (c *Config) revisions(namespace string, iteration int) []int {
+	revisions := make([]int, c.ObjectsPerRepoSync)
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(namespace))
+	nsSeed := int64(h.Sum64())
+	count := c.churnCount()
+	for it := 1; it <= iteration; it++ {
+		r := rand.New(rand.NewSource(c.Seed ^ nsSeed ^ int64(it)))
+		for _, i := range r.Perm(c.ObjectsPerRepoSync)[:count] {
+			revisions[i] = it
+		}
+	}
+	return revisions
+}
+
+// WriteRepository writes the fleet at the iteration to the directory of a
+// repository, replacing the files of any previous iteration.
+func (c *Config) WriteRepository(dir string, template *v1beta1.RepoSync, iteration int) error {
+	files := c.RootObjects(template)
+	for _, ns := range c.Namespaces() {
+		for path, obj := range c.Objects(ns, iteration) {
+			files[path] = obj
+		}
+	}
+	for path, obj := range files {
+		u, sErr := reconcile.AsUnstructuredSanitized(obj)
+		if sErr != nil {
+			return sErr
+		}
+		bytes, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s", path)
+		}
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, bytes, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
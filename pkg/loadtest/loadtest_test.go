@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func revisionsOf(objs map[string]client.Object) map[string]string {
+	result := make(map[string]string, len(objs))
+	for path, obj := range objs {
+		result[path] = obj.(*corev1.ConfigMap).Data[RevisionKey]
+	}
+	return result
+}
+
+func TestChurn(t *testing.T) {
+	config := Config{Name: "test", RepoSyncs: 3, ObjectsPerRepoSync: 20, ChurnPercent: 25, Seed: 42}
+	config.Default()
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ns := range config.Namespaces() {
+		initial := revisionsOf(config.Objects(ns, 0))
+		if len(initial) != 20 {
+			t.Fatalf("got %d objects, want 20", len(initial))
+		}
+		previous := initial
+		for it := 1; it <= 3; it++ {
+			current := revisionsOf(config.Objects(ns, it))
+			changed := 0
+			for path, revision := range current {
+				if revision != previous[path] {
+					changed++
+				}
+			}
+			// An iteration may pick objects already changed by a previous one,
+			// but it changes exactly ChurnPercent of them.
+			if changed != 5 {
+				t.Errorf("iteration %d of %s changed %d objects, want 5", it, ns, changed)
+			}
+			previous = current
+		}
+		// The churn is deterministic.
+		if diff := cmp.Diff(previous, revisionsOf(config.Objects(ns, 3))); diff != "" {
+			t.Error(diff)
+		}
+	}
+	if got := config.ChurnedObjects(1); got != 15 {
+		t.Errorf("ChurnedObjects(1) = %d, want 15", got)
+	}
+	if got := config.ChurnedObjects(0); got != 60 {
+		t.Errorf("ChurnedObjects(0) = %d, want 60", got)
+	}
+}
+
+func TestWriteRepository(t *testing.T) {
+	config := Config{Name: "test", RepoSyncs: 2, ObjectsPerRepoSync: 2}
+	config.Default()
+	template := &v1beta1.RepoSync{}
+	template.Spec.Git = &v1beta1.Git{Repo: "https://example.com/fleet", Branch: "main"}
+
+	dir := t.TempDir()
+	if err := config.WriteRepository(dir, template, 0); err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, rel)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"fleet/ns-load-0.yaml", "fleet/ns-load-1.yaml",
+		"fleet/rb-load-0.yaml", "fleet/rb-load-1.yaml",
+		"fleet/reposync-load-0.yaml", "fleet/reposync-load-1.yaml",
+		"load-0/cm-0.yaml", "load-0/cm-1.yaml",
+		"load-1/cm-0.yaml", "load-1/cm-1.yaml",
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Error(diff)
+	}
+
+	rs, err := os.ReadFile(filepath.Join(dir, "fleet/reposync-load-1.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"dir: load-1", "repo: https://example.com/fleet", "namespace: load-1"} {
+		if !bytes.Contains(rs, []byte(s)) {
+			t.Errorf("RepoSync %s does not contain %q", rs, s)
+		}
+	}
+	if bytes.Contains(rs, []byte("status")) {
+		t.Errorf("RepoSync %s contains a status", rs)
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	r := NewReport(Config{Name: "test"})
+	r.AddIteration(Iteration{Number: 0, SyncDuration: metav1.Duration{Duration: time.Minute}})
+	for i := 1; i <= 20; i++ {
+		r.AddIteration(Iteration{Number: i, SyncDuration: metav1.Duration{Duration: time.Duration(i) * time.Second}})
+	}
+	want := Summary{
+		Iterations:      21,
+		CreateDuration:  metav1.Duration{Duration: time.Minute},
+		SyncDurationP50: metav1.Duration{Duration: 10 * time.Second},
+		SyncDurationP95: metav1.Duration{Duration: 19 * time.Second},
+		SyncDurationMax: metav1.Duration{Duration: 20 * time.Second},
+	}
+	if diff := cmp.Diff(want, r.Summary); diff != "" {
+		t.Error(diff)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(r, got); diff != "" {
+		t.Error(diff)
+	}
+}
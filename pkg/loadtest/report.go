@@ -0,0 +1,242 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheusmodel "github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/metrics"
+)
+
+// MetricPrefix is the prefix of the Config Sync metrics exported to
+// Prometheus by the otel-collector.
+const MetricPrefix = "config_sync_"
+
+// Query is a Prometheus query of a pipeline metric collected into a Report.
+type Query struct {
+	// Name of the metric in the Report.
+	Name string
+	// Expr is the PromQL expression. It must evaluate to a scalar or to a
+	// vector, whose samples are summed.
+	Expr string
+}
+
+// DefaultQueries returns the queries of the pipeline metrics collected by
+// default, over the window of the run.
+func DefaultQueries(window time.Duration) []Query {
+	quantile := func(name, view string) Query {
+		return Query{
+			Name: name,
+			Expr: fmt.Sprintf("histogram_quantile(0.95, sum(rate(%s%s_bucket[%s])) by (le))",
+				MetricPrefix, view, prometheusmodel.Duration(window)),
+		}
+	}
+	total := func(name, view string) Query {
+		return Query{
+			Name: name,
+			Expr: fmt.Sprintf("sum(%s%s)", MetricPrefix, view),
+		}
+	}
+	return []Query{
+		quantile("parser_duration_p95_seconds", metrics.ParserDurationView.Name),
+		quantile("apply_duration_p95_seconds", metrics.ApplyDurationView.Name),
+		quantile("remediate_duration_p95_seconds", metrics.RemediateDurationView.Name),
+		quantile("api_duration_p95_seconds", metrics.APICallDurationView.Name),
+		total("declared_resources", metrics.DeclaredResourcesView.Name),
+		total("reconciler_errors", metrics.ReconcilerErrorsView.Name),
+		total("pipeline_errors", metrics.PipelineErrorView.Name),
+		total("resource_fights", metrics.ResourceFightsView.Name),
+		total("resource_conflicts", metrics.ResourceConflictsView.Name),
+		total("internal_errors", metrics.InternalErrorsView.Name),
+		total("api_calls_throttled", metrics.APICallThrottledView.Name),
+	}
+}
+
+// Iteration is the result of syncing one iteration of the fleet.
+type Iteration struct {
+	// Number of the iteration. Iteration 0 creates the fleet.
+	Number int `json:"number"`
+	// Commit synced by the iteration.
+	Commit string `json:"commit,omitempty"`
+	// ChangedObjects is the number of objects changed by the iteration.
+	ChangedObjects int `json:"changedObjects"`
+	// SyncDuration is how long all the RepoSyncs took to sync the commit,
+	// from its push.
+	SyncDuration metav1.Duration `json:"syncDuration"`
+}
+
+// Summary aggregates the iterations of a Report.
+type Summary struct {
+	Iterations int `json:"iterations"`
+	// SyncDurationP50 is the median SyncDuration of the churn iterations,
+	// after the fleet was created.
+	SyncDurationP50 metav1.Duration `json:"syncDurationP50"`
+	// SyncDurationP95 is the 95th percentile SyncDuration of the churn
+	// iterations.
+	SyncDurationP95 metav1.Duration `json:"syncDurationP95"`
+	// SyncDurationMax is the longest SyncDuration of the churn iterations.
+	SyncDurationMax metav1.Duration `json:"syncDurationMax"`
+	// CreateDuration is the SyncDuration of the iteration creating the fleet.
+	CreateDuration metav1.Duration `json:"createDuration"`
+}
+
+// Report is the result of a load test run.
+type Report struct {
+	Config     Config      `json:"config"`
+	Iterations []Iteration `json:"iterations"`
+	Summary    Summary     `json:"summary"`
+	// Metrics are the values of the pipeline metrics, keyed by Query name.
+	// Metrics without data are omitted.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// NewReport returns an empty Report of the fleet.
+func NewReport(config Config) *Report {
+	return &Report{Config: config}
+}
+
+// AddIteration records the result of an iteration and updates the Summary.
+func (r *Report) AddIteration(it Iteration) {
+	r.Iterations = append(r.Iterations, it)
+	r.summarize()
+}
+
+func (r *Report) summarize() {
+	r.Summary = Summary{Iterations: len(r.Iterations)}
+	var churn []time.Duration
+	for _, it := range r.Iterations {
+		if it.Number == 0 {
+			r.Summary.CreateDuration = it.SyncDuration
+			continue
+		}
+		churn = append(churn, it.SyncDuration.Duration)
+	}
+	if len(churn) == 0 {
+		return
+	}
+	sort.Slice(churn, func(i, j int) bool { return churn[i] < churn[j] })
+	r.Summary.SyncDurationP50 = metav1.Duration{Duration: percentile(churn, 50)}
+	r.Summary.SyncDurationP95 = metav1.Duration{Duration: percentile(churn, 95)}
+	r.Summary.SyncDurationMax = metav1.Duration{Duration: churn[len(churn)-1]}
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// CollectMetrics queries Prometheus and records the values of the metrics.
+func (r *Report) CollectMetrics(ctx context.Context, api prometheusv1.API, queries []Query) error {
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]float64, len(queries))
+	}
+	now := time.Now()
+	for _, q := range queries {
+		value, _, err := api.Query(ctx, q.Expr, now)
+		if err != nil {
+			return fmt.Errorf("failed to query %s: %w", q.Name, err)
+		}
+		if v, found := sampleValue(value); found {
+			r.Metrics[q.Name] = v
+		}
+	}
+	return nil
+}
+
+// sampleValue returns the sum of the samples of the query result, and whether
+// it had any.
+func sampleValue(value prometheusmodel.Value) (float64, bool) {
+	switch result := value.(type) {
+	case *prometheusmodel.Scalar:
+		v := float64(result.Value)
+		return v, !math.IsNaN(v)
+	case prometheusmodel.Vector:
+		var sum float64
+		var found bool
+		for _, sample := range result {
+			v := float64(sample.Value)
+			if math.IsNaN(v) {
+				continue
+			}
+			sum += v
+			found = true
+		}
+		return sum, found
+	default:
+		return 0, false
+	}
+}
+
+// ReadReport reads a Report written by WriteJSON.
+func ReadReport(path string) (*Report, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{}
+	if err := json.Unmarshal(bytes, r); err != nil {
+		return nil, fmt.Errorf("failed to parse the report %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// WriteJSON writes the Report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the Report as human readable tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Fleet %q: %d RepoSyncs x %d objects, %d%% churn\n\n",
+		r.Config.Name, r.Config.RepoSyncs, r.Config.ObjectsPerRepoSync, r.Config.ChurnPercent)
+	fmt.Fprintln(tw, "ITERATION\tCOMMIT\tCHANGED\tSYNC DURATION")
+	for _, it := range r.Iterations {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%v\n", it.Number, it.Commit, it.ChangedObjects, it.SyncDuration.Duration)
+	}
+	fmt.Fprintf(tw, "\ncreate\t%v\n", r.Summary.CreateDuration.Duration)
+	fmt.Fprintf(tw, "churn p50\t%v\n", r.Summary.SyncDurationP50.Duration)
+	fmt.Fprintf(tw, "churn p95\t%v\n", r.Summary.SyncDurationP95.Duration)
+	fmt.Fprintf(tw, "churn max\t%v\n", r.Summary.SyncDurationMax.Duration)
+	if len(r.Metrics) > 0 {
+		names := make([]string, 0, len(r.Metrics))
+		for name := range r.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(tw, "\nMETRIC\tVALUE")
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%g\n", name, r.Metrics[name])
+		}
+	}
+	return tw.Flush()
+}
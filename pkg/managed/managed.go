@@ -0,0 +1,193 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package managed lists the objects managed by a RootSync or RepoSync, by the
+// owning inventory label set on them by the reconciler. Unlike reading the
+// ResourceGroup of the RSync, which may be several megabytes for large
+// repositories, the objects are selected by the API server and fetched in
+// pages, one kind at a time.
+package managed
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/applier"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultChunkSize is the default maximum number of objects fetched per
+// request.
+const DefaultChunkSize = 500
+
+// Object is an object managed by a RootSync or RepoSync.
+type Object struct {
+	// ID identifies the object.
+	ID core.ID
+	// Status is the health of the object, as computed by kstatus.
+	Status kstatus.Status
+	// Commit is the commit the object was last applied from.
+	Commit string
+}
+
+// Options configures List.
+type Options struct {
+	// SyncName is the name of the RootSync or RepoSync.
+	SyncName string
+	// SyncNamespace is the namespace of the RootSync or RepoSync.
+	SyncNamespace string
+	// GVKs are the kinds of the objects to list.
+	GVKs []schema.GroupVersionKind
+	// ChunkSize is the maximum number of objects fetched per request.
+	// DefaultChunkSize is used if it is not positive.
+	ChunkSize int64
+}
+
+// Selector returns the label selector of the objects managed by the RootSync
+// or RepoSync.
+func Selector(syncName, syncNamespace string) client.MatchingLabels {
+	return client.MatchingLabels{
+		metadata.OwningInventoryLabel: applier.InventoryLabelValue(syncName, syncNamespace),
+	}
+}
+
+// List calls visit with every object managed by the RootSync or RepoSync, of
+// the kinds in the options, page by page, so that the objects of large RSyncs
+// are never all held in memory. The objects of a RepoSync are only listed in
+// its namespace. Kinds which are no longer served are skipped.
+// Listing stops at the first error returned by visit.
+func List(ctx context.Context, c client.Reader, opts Options, visit func([]Object) error) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	listOpts := []client.ListOption{Selector(opts.SyncName, opts.SyncNamespace), client.Limit(chunkSize)}
+	if opts.SyncNamespace != configsync.ControllerNamespace {
+		listOpts = append(listOpts, client.InNamespace(opts.SyncNamespace))
+	}
+
+	for _, gvk := range opts.GVKs {
+		continueToken := ""
+		for {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			pageOpts := listOpts
+			if continueToken != "" {
+				pageOpts = append(pageOpts[:len(pageOpts):len(pageOpts)], client.Continue(continueToken))
+			}
+			if err := c.List(ctx, list, pageOpts...); err != nil {
+				if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+					break
+				}
+				return errors.Wrapf(err, "failed to list the managed %s objects", gvk.GroupKind())
+			}
+			if len(list.Items) > 0 {
+				page := make([]Object, len(list.Items))
+				for i := range list.Items {
+					page[i] = toObject(&list.Items[i])
+				}
+				if err := visit(page); err != nil {
+					return err
+				}
+			}
+			continueToken = list.GetContinue()
+			if continueToken == "" {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// toObject returns the summary of the managed object.
+func toObject(u *unstructured.Unstructured) Object {
+	obj := Object{
+		ID:     core.IDOf(u),
+		Commit: core.GetAnnotation(u, metadata.SyncTokenAnnotationKey),
+	}
+	result, err := kstatus.Compute(u)
+	if err != nil {
+		obj.Status = kstatus.UnknownStatus
+	} else {
+		obj.Status = result.Status
+	}
+	return obj
+}
+
+// ListableGVKs returns a version of every kind in the resource lists which can
+// be listed, sorted by group and kind. The first version found is used, so
+// the resource lists are expected to hold the preferred versions only.
+// If kinds is not empty, only these kinds are returned, and an error is
+// returned for the kinds which are not served.
+func ListableGVKs(resourceLists []*metav1.APIResourceList, kinds []schema.GroupKind) ([]schema.GroupVersionKind, error) {
+	served := make(map[schema.GroupKind]schema.GroupVersionKind)
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "discovery returned invalid GroupVersion %q", list.GroupVersion)
+		}
+		for _, resource := range list.APIResources {
+			// Skip subresources, e.g. deployments/status.
+			if strings.Contains(resource.Name, "/") || !hasVerb(resource, "list") {
+				continue
+			}
+			gk := schema.GroupKind{Group: gv.Group, Kind: resource.Kind}
+			if _, found := served[gk]; !found {
+				served[gk] = gv.WithKind(resource.Kind)
+			}
+		}
+	}
+
+	var gvks []schema.GroupVersionKind
+	if len(kinds) == 0 {
+		for _, gvk := range served {
+			gvks = append(gvks, gvk)
+		}
+	} else {
+		for _, gk := range kinds {
+			gvk, found := served[gk]
+			if !found {
+				return nil, errors.Errorf("the kind %s is not served by the cluster", gk)
+			}
+			gvks = append(gvks, gvk)
+		}
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		if gvks[i].Group != gvks[j].Group {
+			return gvks[i].Group < gvks[j].Group
+		}
+		return gvks[i].Kind < gvks[j].Kind
+	})
+	return gvks, nil
+}
+
+func hasVerb(resource metav1.APIResource, verb string) bool {
+	for _, v := range resource.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func managedBy(syncName, syncNamespace string) core.MetaMutator {
+	return func(obj client.Object) {
+		for k, v := range Selector(syncName, syncNamespace) {
+			core.SetLabel(obj, k, v)
+		}
+		core.SetAnnotation(obj, metadata.SyncTokenAnnotationKey, "abc123")
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	c := syncerFake.NewClient(t, core.Scheme,
+		fake.ConfigMapObject(core.Name("root-cm"), core.Namespace("bookstore"), managedBy("root-sync", configsync.ControllerNamespace)),
+		fake.ConfigMapObject(core.Name("repo-cm"), core.Namespace("bookstore"), managedBy("repo-sync", "bookstore")),
+		fake.ConfigMapObject(core.Name("other-cm"), core.Namespace("shipping"), managedBy("repo-sync", "shipping")),
+		fake.ConfigMapObject(core.Name("unmanaged-cm"), core.Namespace("bookstore")),
+		fake.NamespaceObject("bookstore", managedBy("root-sync", configsync.ControllerNamespace)),
+	)
+	gvks := []schema.GroupVersionKind{kinds.ConfigMap(), kinds.Namespace()}
+
+	testCases := []struct {
+		name          string
+		syncName      string
+		syncNamespace string
+		want          []core.ID
+	}{
+		{
+			name:          "RootSync",
+			syncName:      "root-sync",
+			syncNamespace: configsync.ControllerNamespace,
+			want: []core.ID{
+				{GroupKind: kinds.ConfigMap().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "bookstore", Name: "root-cm"}},
+				{GroupKind: kinds.Namespace().GroupKind(), ObjectKey: client.ObjectKey{Name: "bookstore"}},
+			},
+		},
+		{
+			name:          "RepoSync",
+			syncName:      "repo-sync",
+			syncNamespace: "bookstore",
+			want: []core.ID{
+				{GroupKind: kinds.ConfigMap().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "bookstore", Name: "repo-cm"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []core.ID
+			opts := Options{SyncName: tc.syncName, SyncNamespace: tc.syncNamespace, GVKs: gvks}
+			err := List(ctx, c, opts, func(page []Object) error {
+				for _, obj := range page {
+					assert.Equal(t, "abc123", obj.Commit)
+					assert.Equal(t, kstatus.CurrentStatus, obj.Status)
+					got = append(got, obj.ID)
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestListableGVKs(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Verbs: []string{"get", "list"}},
+				{Name: "bindings", Kind: "Binding", Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Verbs: []string{"get", "list"}},
+				{Name: "deployments/status", Kind: "Deployment", Verbs: []string{"get"}},
+			},
+		},
+	}
+
+	gvks, err := ListableGVKs(resourceLists, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionKind{kinds.ConfigMap(), kinds.Deployment()}, gvks)
+
+	gvks, err = ListableGVKs(resourceLists, []schema.GroupKind{kinds.Deployment().GroupKind()})
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionKind{kinds.Deployment()}, gvks)
+
+	_, err = ListableGVKs(resourceLists, []schema.GroupKind{kinds.Namespace().GroupKind()})
+	assert.Error(t, err)
+}
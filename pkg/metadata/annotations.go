@@ -88,6 +88,11 @@ const (
 	// This annotation is set by Config Sync on a root-reconciler, namespace-reconciler, or otel-collector pod.
 	ConfigMapAnnotationKey = configsync.ConfigSyncPrefix + "configmap"
 
+	// TenantConfigMapAnnotationKey is the annotation key representing the hash
+	// of the custom ConfigMap of a tenant otel-collector.
+	// This annotation is set by Config Sync on a tenant otel-collector pod.
+	TenantConfigMapAnnotationKey = configsync.ConfigSyncPrefix + "tenant-configmap"
+
 	// DeclaredFieldsKey is the annotation key that stores the declared configuration of
 	// a resource in Git. This uses the same format as the managed fields of server-side apply.
 	// This annotation is set by Config Sync on a managed resource.
@@ -125,6 +130,251 @@ const (
 	// to disable updating the webhook configuration.
 	WebhookConfigurationUpdateDisabled = "disabled"
 
+	// RecreateOnImmutableChangeKey annotation declares if Config Sync should
+	// delete and recreate the object, when the declared changes modify its
+	// immutable fields.
+	// This annotation is set by Config Sync users on a managed resource.
+	RecreateOnImmutableChangeKey = configsync.ConfigSyncPrefix + "recreate-on-immutable-change"
+
+	// RecreateOnImmutableChangeEnabled is the value for RecreateOnImmutableChangeKey
+	// to enable recreating the object.
+	RecreateOnImmutableChangeEnabled = "true"
+
+	// RunOnceStrategyKey annotation declares how Config Sync applies changes
+	// to run-once resources, such as Jobs, whose spec cannot be updated.
+	// This annotation is set by Config Sync users on a managed resource.
+	RunOnceStrategyKey = configsync.ConfigSyncPrefix + "run-once-strategy"
+
+	// RunOnceStrategyReplace is the value for RunOnceStrategyKey to delete and
+	// recreate the object when the declared changes modify immutable fields.
+	RunOnceStrategyReplace = "replace"
+
+	// RunOnceStrategyIgnoreIfSucceeded is the value for RunOnceStrategyKey to
+	// skip applying the declared changes, if the object already ran successfully.
+	RunOnceStrategyIgnoreIfSucceeded = "ignore-if-succeeded"
+
+	// RunOnceStrategyAppendHash is the value for RunOnceStrategyKey to append
+	// a hash of the declared object to its name, so that changes create a new
+	// object, and the previous object gets pruned.
+	RunOnceStrategyAppendHash = "append-hash"
+
+	// SyncWeightKey annotation declares the order in which Config Sync applies
+	// the object within a commit. Objects with lower weights are applied, and
+	// reconciled, before the objects with higher weights. The value must be an
+	// integer, and defaults to 0.
+	// This annotation is set by Config Sync users on a managed resource.
+	SyncWeightKey = configsync.ConfigSyncPrefix + "sync-weight"
+
+	// DeclaredObjectMutationKey annotation declares if the DeclaredObjectMutators
+	// should be evaluated on the declared objects of a RootSync or RepoSync.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync object.
+	DeclaredObjectMutationKey = configsync.ConfigSyncPrefix + "declared-object-mutation"
+
+	// DeclaredObjectMutationDisabled is the value for DeclaredObjectMutationKey
+	// to opt out of the DeclaredObjectMutators.
+	DeclaredObjectMutationDisabled = "disabled"
+
+	// NamespaceProvisioningKey annotation declares if the root reconciler
+	// provisions the Namespaces of the RepoSyncs and NamespaceRequests declared
+	// in the source of truth of a RootSync.
+	// This annotation is set by Config Sync users on a RootSync object.
+	NamespaceProvisioningKey = configsync.ConfigSyncPrefix + "namespace-provisioning"
+
+	// NamespaceProvisioningEnabled is the value for NamespaceProvisioningKey
+	// to enable the namespace provisioning.
+	NamespaceProvisioningEnabled = "enabled"
+
+	// OrphanCleanupKey annotation declares if the reconciler removes the Config
+	// Sync metadata from the resources found by the orphan audit, which carry
+	// its management metadata but are missing from its inventory.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync object.
+	OrphanCleanupKey = configsync.ConfigSyncPrefix + "orphan-cleanup"
+
+	// OrphanCleanupEnabled is the value for OrphanCleanupKey to enable the
+	// cleanup of orphaned resources.
+	OrphanCleanupEnabled = "enabled"
+
+	// ClusterStateDiffKey annotation declares if the reconciler captures the
+	// state of the managed objects on the cluster before and after each apply,
+	// and publishes the diff in an immutable ConfigMap in the namespace of the
+	// RootSync or RepoSync.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync object.
+	ClusterStateDiffKey = configsync.ConfigSyncPrefix + "cluster-state-diff"
+
+	// ClusterStateDiffEnabled is the value for ClusterStateDiffKey to enable
+	// the cluster state diffs.
+	ClusterStateDiffEnabled = "enabled"
+
+	// CommitDiffKey annotation records, as JSON, which declared objects the
+	// latest synced commit created, updated or removed, compared to the
+	// previous commit, with a short summary of their changed fields.
+	// This annotation is set by the reconciler on the ResourceGroup inventory
+	// of a RootSync or RepoSync.
+	CommitDiffKey = configsync.ConfigSyncPrefix + "commit-diff"
+
+	// PlaintextSecretCheckKey annotation opts a RootSync or RepoSync in to
+	// scanning the declared Secrets for plaintext credentials. On a declared
+	// Secret, it skips the scan of that Secret.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync,
+	// or on a Secret in the source of truth.
+	PlaintextSecretCheckKey = configsync.ConfigSyncPrefix + "plaintext-secret-check"
+
+	// PlaintextSecretCheckWarn is the value for PlaintextSecretCheckKey on a
+	// RootSync or RepoSync to report the Secrets with plaintext credentials,
+	// without blocking the apply.
+	PlaintextSecretCheckWarn = "warn"
+
+	// PlaintextSecretCheckError is the value for PlaintextSecretCheckKey on a
+	// RootSync or RepoSync to block the apply of the Secrets with plaintext
+	// credentials.
+	PlaintextSecretCheckError = "error"
+
+	// PlaintextSecretCheckIgnore is the value for PlaintextSecretCheckKey on a
+	// Secret to skip its scan.
+	PlaintextSecretCheckIgnore = "ignore"
+
+	// DebugSnapshotRequestedAtKey is the annotation set on a RootSync or
+	// RepoSync to request a snapshot of the internal state of its reconciler in
+	// a ReconcilerDebug object. Its value is usually the RFC 3339 timestamp of
+	// the request: the reconciler takes a new snapshot every time it changes.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	DebugSnapshotRequestedAtKey = configsync.ConfigSyncPrefix + "debug-snapshot-requested-at"
+
+	// ResyncRequestedAtKey is the annotation set on a RootSync or RepoSync to
+	// request a selective resync of the objects listed in ResyncObjectsKey.
+	// Its value is usually the RFC 3339 timestamp of the request: the
+	// reconciler resyncs the objects every time it changes.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	ResyncRequestedAtKey = configsync.ConfigSyncPrefix + "resync-requested-at"
+
+	// ResetCacheKey is the annotation set on a RootSync or RepoSync to reset
+	// the caches of its reconciler and purge the rendered configs of its
+	// hydration-controller, which are then parsed, rendered and applied again
+	// from the current commit. Its value is an opaque token, usually the RFC
+	// 3339 timestamp of the request: the caches are reset every time it
+	// changes.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	ResetCacheKey = configsync.ConfigSyncPrefix + "reset-cache"
+
+	// ResyncObjectsKey is the annotation which lists the declared objects to
+	// re-apply from the current commit, when ResyncRequestedAtKey changes.
+	// Objects are listed as `<kind>/<namespace>/<name>` or `<kind>/<name>`,
+	// and directories of the source of truth as `dir:<path>`.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	ResyncObjectsKey = configsync.ConfigSyncPrefix + "resync-objects"
+
+	// EmergencyOverrideUntilKey annotation marks a manual change to a managed
+	// object as an approved emergency override. Its value is an RFC 3339
+	// timestamp, at most 24 hours in the future: until then, the admission
+	// webhook allows the change, and neither the applier nor the remediator
+	// reverts it. Afterwards, the remediator reverts the object, removes the
+	// annotation, and reports the expiry with an event.
+	// This annotation is set by the members of the emergency override group of
+	// the admission webhook on a live managed resource, and is not allowed in
+	// the source of truth. Without the admission webhook, anyone allowed to
+	// update a managed resource can set it, so the webhook should be enabled
+	// wherever emergency overrides must be restricted.
+	EmergencyOverrideUntilKey = configsync.ConfigSyncPrefix + "emergency-override-until"
+
+	// ExpiresAtSuffix is appended to the key of an escape-hatch annotation,
+	// e.g. the freeze or the restore-commit annotation, to get the key of the
+	// annotation holding its expiry. Its value is an RFC 3339 timestamp,
+	// after which the reconciler-manager removes both annotations.
+	// This annotation is set by Config Sync users alongside an escape-hatch
+	// annotation.
+	ExpiresAtSuffix = "-expires-at"
+
+	// KnownHostsPinnedAtAnnotationKey is the annotation set on a known hosts
+	// Secret created by the PinOnFirstUse policy. Its value is the RFC 3339
+	// timestamp of when the host key of the Git server was pinned.
+	KnownHostsPinnedAtAnnotationKey = configsync.ConfigSyncPrefix + "known-hosts-pinned-at"
+
+	// AllowCRDPruneKey is the annotation set on a managed
+	// CustomResourceDefinition removed from the source of truth, to prune it
+	// even though custom resources of it exist, which deletes them too.
+	// Without it, the reconciler refuses to prune the CustomResourceDefinition.
+	// This annotation is set by Config Sync users on a live managed
+	// CustomResourceDefinition.
+	AllowCRDPruneKey = configsync.ConfigSyncPrefix + "allow-crd-prune"
+
+	// AllowCRDPruneEnabled is the value for AllowCRDPruneKey to prune the
+	// CustomResourceDefinition.
+	AllowCRDPruneEnabled = "true"
+
+	// ConfirmPruneKey is the annotation set on a RootSync or RepoSync to
+	// confirm the prune of more objects than allowed by its spec.prune
+	// maxPercentage or maxCount. Its value is the number of objects to prune,
+	// so that the confirmation does not apply to a different prune.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	ConfirmPruneKey = configsync.ConfigSyncPrefix + "confirm-prune"
+
+	// PendingPruneSinceKey is the annotation set on a managed object removed
+	// from the source of truth, while its deletion is held off by the prune
+	// delay of the RootSync or RepoSync. Its value is the RFC 3339 timestamp
+	// of when the object was found removed.
+	// This annotation is set by Config Sync on a managed resource.
+	PendingPruneSinceKey = configsync.ConfigSyncPrefix + "pending-prune-since"
+
+	// RestoreCommitAnnotationKey is the annotation set on a RootSync or
+	// RepoSync to re-apply the snapshot of the objects applied for a previous
+	// commit, instead of syncing from the source of truth. The reconciler keeps
+	// the snapshot applied until the annotation is removed.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync,
+	// for example with `nomos restore`.
+	RestoreCommitAnnotationKey = configsync.ConfigSyncPrefix + "restore-commit"
+
+	// SnapshotCommitAnnotationKey is the annotation set on a snapshot Secret
+	// or a cluster state diff ConfigMap. Its value is the commit of the objects in
+	// the snapshot, or the commit whose apply is recorded in the diff.
+	SnapshotCommitAnnotationKey = configsync.ConfigSyncPrefix + "snapshot-commit"
+
+	// SnapshotSavedAtAnnotationKey is the annotation set on a snapshot
+	// Secret. Its value is the RFC 3339 timestamp of when the snapshot was
+	// last saved, which orders the snapshots for the retention.
+	SnapshotSavedAtAnnotationKey = configsync.ConfigSyncPrefix + "snapshot-saved-at"
+
+	// SyncRequestedAtAnnotationKey is the annotation set on a RootSync or
+	// RepoSync to request an immediate fetch of the source. Its value is the
+	// RFC 3339 timestamp of the request. The reconciler watches it, and reads
+	// the source again as soon as it changes, until git-sync fetched a new
+	// commit.
+	// This annotation is set by the git webhook receiver on a RootSync or
+	// RepoSync, when the repository receives a push.
+	SyncRequestedAtAnnotationKey = configsync.ConfigSyncPrefix + "sync-requested-at"
+
+	// SyncPausedAnnotationKey is the annotation set on a RootSync or RepoSync
+	// to pause the sync. While its value is SyncPausedEnabled, the reconciler
+	// keeps reporting the source and rendering status, but neither applies
+	// new commits nor corrects drift. Removing it resumes the sync with a full
+	// resync.
+	// This annotation is set by Config Sync users on a RootSync or RepoSync.
+	SyncPausedAnnotationKey = configsync.ConfigSyncPrefix + "sync-paused"
+
+	// SyncPausedEnabled is the value of SyncPausedAnnotationKey which pauses
+	// the sync.
+	SyncPausedEnabled = "true"
+
+	// ReferenceOnlyKey annotation marks a declared object as a shared resource
+	// owned by another system, which the source of truth depends on. The
+	// reconciler verifies that the object exists on the cluster and matches
+	// its declaration, but never creates, updates, takes ownership of, or
+	// prunes it.
+	// This annotation is set by Config Sync users on a resource in the source
+	// of truth.
+	ReferenceOnlyKey = configsync.ConfigSyncPrefix + "reference-only"
+
+	// ReferenceOnlyEnabled is the value for ReferenceOnlyKey to mark the
+	// object as reference-only.
+	ReferenceOnlyEnabled = "true"
+
+	// ReferenceFieldsKey annotation restricts the verification of a
+	// reference-only object to a comma-separated list of its declared fields,
+	// as dot-separated paths, e.g. `spec.replicas,metadata.labels.team`. All
+	// the declared fields are verified if it is not set.
+	// This annotation is set by Config Sync users on a reference-only resource
+	// in the source of truth.
+	ReferenceFieldsKey = configsync.ConfigSyncPrefix + "reference-fields"
+
 	// UnknownScopeAnnotationKey is the annotation that indicates the scope of a resource is unknown.
 	// This annotation is set by Config Sync on a managed resource whose scope is unknown.
 	UnknownScopeAnnotationKey = configsync.ConfigSyncPrefix + "unknown-scope"
@@ -137,6 +387,17 @@ const (
 	// RootSync/RepoSync objects to indicate what do do with the managed
 	// resources when the RootSync/RepoSync object is deleted.
 	DeletionPropagationPolicyAnnotationKey = configsync.ConfigSyncPrefix + "deletion-propagation-policy"
+
+	// NamespaceFreezeAnnotationKey is the annotation key set on Namespaces to
+	// stop syncing changes to the resources in that Namespace, e.g. during a
+	// change freeze.
+	// This annotation is set by Config Sync users on a Namespace, either in the
+	// source repository or directly on the cluster.
+	NamespaceFreezeAnnotationKey = configsync.ConfigSyncPrefix + "freeze"
+
+	// NamespaceFreezeEnabled is the value for NamespaceFreezeAnnotationKey
+	// to freeze the Namespace.
+	NamespaceFreezeEnabled = "true"
 )
 
 // Lifecycle annotations
@@ -0,0 +1,59 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EmergencyOverrideMaxDuration is how far in the future the expiry of an
+// emergency override may be. An override with a later expiry is invalid, so
+// that an override cannot suspend the remediation of an object indefinitely.
+const EmergencyOverrideMaxDuration = 24 * time.Hour
+
+// EmergencyOverrideExpiry returns the expiry of the emergency override of the
+// live object, and whether the object has a valid emergency override
+// annotation. Invalid timestamps are logged and ignored, so that a typo cannot
+// suspend the remediation of the object.
+func EmergencyOverrideExpiry(obj client.Object) (time.Time, bool) {
+	value, found := obj.GetAnnotations()[EmergencyOverrideUntilKey]
+	if !found {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.Warningf("Ignoring invalid %s annotation %q on %s/%s: %v",
+			EmergencyOverrideUntilKey, value, obj.GetNamespace(), obj.GetName(), err)
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// EmergencyOverrideActive returns true if the live object has an emergency
+// override which has not yet expired at the given time, and whose expiry is
+// at most EmergencyOverrideMaxDuration later.
+func EmergencyOverrideActive(obj client.Object, now time.Time) bool {
+	expiry, found := EmergencyOverrideExpiry(obj)
+	return found && now.Before(expiry) && !EmergencyOverrideTooLong(expiry, now)
+}
+
+// EmergencyOverrideTooLong returns true if the expiry of an emergency override
+// is more than EmergencyOverrideMaxDuration after the given time.
+func EmergencyOverrideTooLong(expiry, now time.Time) bool {
+	return expiry.After(now.Add(EmergencyOverrideMaxDuration))
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExpiresAtKey returns the key of the annotation holding the expiry of the
+// escape-hatch annotation with the key.
+func ExpiresAtKey(key string) string {
+	return key + ExpiresAtSuffix
+}
+
+// AnnotationExpiry returns the expiry of the escape-hatch annotation with the
+// key on the object, and whether the annotation is set with a valid expiry.
+// Invalid timestamps are logged and ignored, like a missing expiry.
+func AnnotationExpiry(obj client.Object, key string) (time.Time, bool) {
+	annotations := obj.GetAnnotations()
+	if _, found := annotations[key]; !found {
+		return time.Time{}, false
+	}
+	expiresAtKey := ExpiresAtKey(key)
+	value, found := annotations[expiresAtKey]
+	if !found {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.Warningf("Ignoring invalid %s annotation %q on %s/%s: %v",
+			expiresAtKey, value, obj.GetNamespace(), obj.GetName(), err)
+		return time.Time{}, false
+	}
+	return expiry, true
+}
@@ -49,10 +49,37 @@ const (
 	// SyncKindLabel indicates the RSync kind: RootSync or RepoSync.
 	SyncKindLabel = configsync.ConfigSyncPrefix + "sync-kind"
 
+	// OwningInventoryLabel indicates the inventory of the RootSync or RepoSync
+	// managing a resource, so that the managed resources of an RSync can be
+	// listed with a label selector, without reading its ResourceGroup.
+	// This label is set by Config Sync on a managed resource.
+	OwningInventoryLabel = configsync.ConfigSyncPrefix + "owning-inventory"
+
+	// SnapshotLabel indicates that a Secret holds a snapshot of the
+	// objects applied by a reconciler.
+	SnapshotLabel = configsync.ConfigSyncPrefix + "snapshot"
+
+	// ClusterStateDiffLabel indicates that a ConfigMap holds the diff of the
+	// state of the managed objects on the cluster before and after an apply.
+	ClusterStateDiffLabel = configsync.ConfigSyncPrefix + "cluster-state-diff"
+
+	// NotificationRouteLabel indicates that a ConfigMap in the Config Sync
+	// namespace holds a routing rule and a payload template of the sync
+	// failure notifications.
+	// This label is set by Config Sync users on a ConfigMap.
+	NotificationRouteLabel = configsync.ConfigSyncPrefix + "notification-route"
+
 	// DeploymentNameLabel indicates the name of the Deployment.
 	// This is used to enable selecting pods by label, primarily for printing logs.
 	// Example: kubectl logs deployment/<deploy-name> <container-name> -n config-management-system
 	DeploymentNameLabel = configsync.ConfigSyncPrefix + "deployment-name"
+
+	// OtelTenantLabel is set by users on a RootSync or RepoSync to route the
+	// telemetry of its reconciler to a dedicated otel-collector shared by the
+	// RootSyncs and RepoSyncs with the same tenant.
+	// This label is also set by Config Sync on the otel-collector resources of
+	// the tenant.
+	OtelTenantLabel = configsync.ConfigSyncPrefix + "otel-tenant"
 )
 
 // DepthSuffix is a label suffix for hierarchical namespace depth.
@@ -54,6 +54,11 @@ var sourceAnnotations = map[string]bool{
 	ResourceManagementKey:                  true,
 	LifecycleMutationAnnotation:            true,
 	DeletionPropagationPolicyAnnotationKey: true,
+	NamespaceFreezeAnnotationKey:           true,
+	RecreateOnImmutableChangeKey:           true,
+	RunOnceStrategyKey:                     true,
+	SyncWeightKey:                          true,
+	PlaintextSecretCheckKey:                true,
 }
 
 // IsSourceAnnotation returns true if the annotation is a ConfigSync source
@@ -148,3 +148,42 @@ func TestRemoveConfigSyncMetadata(t *testing.T) {
 		t.Errorf("the labels and annotations shouldn't be updated in this case")
 	}
 }
+
+func TestRemoveObsoleteMetadata(t *testing.T) {
+	obj := fake.UnstructuredObject(kinds.Deployment(), core.Name("deploy"),
+		core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
+		core.Annotation(metadata.DeclaredConfigAnnotationKey, "{}"),
+		core.Annotation(metadata.ResourceStatusErrorsKey, "[]"),
+		core.Annotation(metadata.LegacyNomosPrefix+"managed", "enabled"),
+		core.Annotation("example.com/owner", "team-a"),
+		core.Label(metadata.LegacyNomosPrefix+"namespace-management", "full"),
+		core.Label(metadata.ManagedByKey, metadata.ManagedByValue))
+	if !metadata.HasObsoleteMetadata(obj) {
+		t.Errorf("HasObsoleteMetadata should be true")
+	}
+	updated := metadata.RemoveObsoleteMetadata(obj)
+	if !updated {
+		t.Errorf("updated should be true")
+	}
+
+	expectedAnnotations := map[string]string{
+		metadata.ResourceManagementKey: metadata.ResourceManagementEnabled,
+		"example.com/owner":            "team-a",
+	}
+	if diff := cmp.Diff(expectedAnnotations, obj.GetAnnotations()); diff != "" {
+		t.Errorf("Diff from the annotations is %s", diff)
+	}
+	expectedLabels := map[string]string{
+		metadata.ManagedByKey: metadata.ManagedByValue,
+	}
+	if diff := cmp.Diff(expectedLabels, obj.GetLabels()); diff != "" {
+		t.Errorf("Diff from the labels is %s", diff)
+	}
+
+	if metadata.HasObsoleteMetadata(obj) {
+		t.Errorf("HasObsoleteMetadata should be false after the removal")
+	}
+	if metadata.RemoveObsoleteMetadata(obj) {
+		t.Errorf("the labels and annotations shouldn't be updated in this case")
+	}
+}
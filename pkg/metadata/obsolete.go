@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LegacyNomosPrefix is the prefix of the annotations and labels set by the
+// pre-1.0 versions of Nomos. They are no longer set or read by Config Sync.
+const LegacyNomosPrefix = "nomos.dev/"
+
+// obsoleteAnnotationKeys are the annotation keys that previous versions of
+// Config Sync set on managed resources, and that are no longer used.
+var obsoleteAnnotationKeys = map[string]bool{
+	DeclaredConfigAnnotationKey:  true,
+	ResourceStatusErrorsKey:      true,
+	ResourceStatusReconcilingKey: true,
+}
+
+// isObsoleteAnnotationKey returns whether an annotation key is no longer used
+// by Config Sync.
+func isObsoleteAnnotationKey(k string) bool {
+	return obsoleteAnnotationKeys[k] || strings.HasPrefix(k, LegacyNomosPrefix)
+}
+
+// isObsoleteLabelKey returns whether a label key is no longer used by
+// Config Sync.
+func isObsoleteLabelKey(k string) bool {
+	return strings.HasPrefix(k, LegacyNomosPrefix)
+}
+
+// HasObsoleteMetadata returns true if the given obj has at least one
+// annotation or label that is no longer used by Config Sync.
+func HasObsoleteMetadata(obj client.Object) bool {
+	for k := range obj.GetAnnotations() {
+		if isObsoleteAnnotationKey(k) {
+			return true
+		}
+	}
+	for k := range obj.GetLabels() {
+		if isObsoleteLabelKey(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveObsoleteMetadata removes the annotations and labels that are no longer
+// used by Config Sync from the given resource.
+// The resource is modified in place. Returns true if the object was modified.
+func RemoveObsoleteMetadata(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	labels := obj.GetLabels()
+	before := len(annotations) + len(labels)
+
+	for k := range annotations {
+		if isObsoleteAnnotationKey(k) {
+			delete(annotations, k)
+		}
+	}
+	obj.SetAnnotations(annotations)
+
+	for k := range labels {
+		if isObsoleteLabelKey(k) {
+			delete(labels, k)
+		}
+	}
+	obj.SetLabels(labels)
+
+	after := len(obj.GetAnnotations()) + len(obj.GetLabels())
+	return before != after
+}
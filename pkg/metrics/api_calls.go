@@ -0,0 +1,57 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// apiCalls is the number of API server calls made by the process, since it
+// started. Each reconciler process syncs a single RootSync or RepoSync, so
+// these are the API calls attributable to it.
+var apiCalls uint64
+
+// APICallCount returns the number of API server calls counted by the
+// NewAPICallCounter round trippers since the process started.
+func APICallCount() uint64 {
+	return atomic.LoadUint64(&apiCalls)
+}
+
+// NewAPICallCounter wraps a http.RoundTripper to count the API server calls.
+// It is meant to be passed to rest.Config.Wrap.
+func NewAPICallCounter(rt http.RoundTripper) http.RoundTripper {
+	return &apiCallCounter{delegate: rt}
+}
+
+// apiCallCounter is a http.RoundTripper which counts the requests.
+type apiCallCounter struct {
+	delegate http.RoundTripper
+}
+
+var _ utilnet.RoundTripperWrapper = &apiCallCounter{}
+
+// RoundTrip implements http.RoundTripper.
+func (c *apiCallCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddUint64(&apiCalls, 1)
+	return c.delegate.RoundTrip(req)
+}
+
+// WrappedRoundTripper implements utilnet.RoundTripperWrapper.
+func (c *apiCallCounter) WrappedRoundTripper() http.RoundTripper {
+	return c.delegate
+}
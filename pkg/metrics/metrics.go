@@ -56,6 +56,12 @@ var (
 		"The timestamp of the most recent sync from Git",
 		stats.UnitDimensionless)
 
+	// LastFullResync metric measures the timestamp of the latest successful periodic full resync.
+	LastFullResync = stats.Int64(
+		"last_full_resync_timestamp",
+		"The timestamp of the most recent periodic full resync that completed without errors",
+		stats.UnitDimensionless)
+
 	// DeclaredResources metric measures the number of declared resources parsed from Git.
 	DeclaredResources = stats.Int64(
 		"declared_resources",
@@ -98,9 +104,76 @@ var (
 		"The number of resource conflicts resulting from a mismatch between the cached resources and cluster resources",
 		stats.UnitDimensionless)
 
+	// ResourcesRecreated metric measures the number of managed resources
+	// recreated by the remediator after they were deleted out-of-band.
+	ResourcesRecreated = stats.Int64(
+		"resources_recreated",
+		"The number of managed resources recreated by the remediator after they were deleted out-of-band",
+		stats.UnitDimensionless)
+
 	// InternalErrors metric measures the number of unexpected internal errors triggered by defensive checks in Config Sync.
 	InternalErrors = stats.Int64(
 		"internal_errors",
 		"The number of internal errors triggered by Config Sync",
 		stats.UnitDimensionless)
+
+	// ReconcilerRetries metric measures the number of retries scheduled for the parse-apply-watch loop after errors.
+	ReconcilerRetries = stats.Int64(
+		"reconciler_retries",
+		"The number of retries scheduled for the reconciler after errors",
+		stats.UnitDimensionless)
+
+	// RetriesExhausted metric measures whether the reconciler stopped retrying the current commit after exhausting its retry budget.
+	RetriesExhausted = stats.Int64(
+		"retries_exhausted",
+		"Whether the reconciler stopped retrying the current commit after exhausting its retry budget",
+		stats.UnitDimensionless)
+
+	// SyncFlapping metric measures whether the sync alternated between failure and success too often recently.
+	SyncFlapping = stats.Int64(
+		"sync_flapping",
+		"Whether the sync alternated between failure and success too often recently",
+		stats.UnitDimensionless)
+
+	// CRDPruneBlockedResources metric measures the number of custom resources which would be deleted by pruning their CustomResourceDefinitions.
+	CRDPruneBlockedResources = stats.Int64(
+		"crd_prune_blocked_resources",
+		"The number of custom resources which would be deleted by pruning their CustomResourceDefinitions, so the reconciler refuses to prune them",
+		stats.UnitDimensionless)
+
+	// WebhookDegraded metric measures whether the Config Sync admission webhook cannot prevent drift.
+	WebhookDegraded = stats.Int64(
+		"webhook_degraded",
+		"Whether the Config Sync admission webhook cannot prevent drift, because its certificate or CA is expired or rotating, or because it is unreachable",
+		stats.UnitDimensionless)
+
+	// OrphanedResources metric measures the number of resources managed by the reconciler, but missing from its inventory.
+	OrphanedResources = stats.Int64(
+		"orphaned_resources",
+		"The number of resources managed by the reconciler, but missing from its inventory",
+		stats.UnitDimensionless)
+
+	// DeclaredResourcesBytes metric measures the approximate size of the declared resources.
+	DeclaredResourcesBytes = stats.Int64(
+		"declared_resources_bytes",
+		"The approximate size of the declared resources",
+		stats.UnitBytes)
+
+	// APICallRate metric measures the rate of API server calls made by the reconciler.
+	APICallRate = stats.Int64(
+		"api_calls_per_minute",
+		"The average rate of API server calls made by the reconciler",
+		stats.UnitDimensionless)
+
+	// RunDuration metric measures the duration of each run of the reconciler, whether it did any work or not.
+	RunDuration = stats.Float64(
+		"run_duration_seconds",
+		"The duration of a run of the reconciler in seconds",
+		stats.UnitSeconds)
+
+	// APICallThrottled metric measures the number of API server calls rejected with 429 Too Many Requests.
+	APICallThrottled = stats.Int64(
+		"api_throttled_requests",
+		"The number of API server calls throttled by the API server",
+		stats.UnitDimensionless)
 )
@@ -14,6 +14,8 @@
 
 package metrics
 
+import "fmt"
+
 const (
 	// OpenTelemetry is the app label for all otel resources.
 	OpenTelemetry = "opentelemetry"
@@ -33,6 +35,10 @@ const (
 	// MonitoringNamespace is the Namespace used for OpenTelemetry Collector deployment.
 	MonitoringNamespace = "config-management-monitoring"
 
+	// OtelCollectorPort is the port of the OpenCensus receiver of the
+	// OpenTelemetry Collector.
+	OtelCollectorPort = 55678
+
 	// CollectorConfigGooglecloud is the OpenTelemetry Collector configuration with
 	// the googlecloud exporter.
 	CollectorConfigGooglecloud = `receivers:
@@ -223,5 +229,30 @@ service:
     metrics/kubernetes:
       receivers: [opencensus]
       processors: [batch, filter/kubernetes, attributes/kubernetes, metricstransform/kubernetes, resourcedetection]
-      exporters: [googlecloud/kubernetes]`
+      exporters: [googlecloud/kubernetes]
+    traces/cloudtrace:
+      receivers: [opencensus]
+      processors: [batch, resourcedetection]
+      exporters: [googlecloud]`
 )
+
+// OtelCollectorTenantName returns the name of the OpenTelemetry Collector of
+// the tenant. The shared collector is used for the empty tenant.
+func OtelCollectorTenantName(tenant string) string {
+	if tenant == "" {
+		return OtelCollectorName
+	}
+	return OtelCollectorName + "-" + tenant
+}
+
+// OtelCollectorTenantCustomCM returns the name of the custom OpenTelemetry
+// Collector ConfigMap of the tenant.
+func OtelCollectorTenantCustomCM(tenant string) string {
+	return OtelCollectorCustomCM + "-" + tenant
+}
+
+// OtelCollectorEndpoint returns the address of the OpenCensus receiver of the
+// OpenTelemetry Collector of the tenant.
+func OtelCollectorEndpoint(tenant string) string {
+	return fmt.Sprintf("%s.%s:%d", OtelCollectorTenantName(tenant), MonitoringNamespace, OtelCollectorPort)
+}
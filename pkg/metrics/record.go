@@ -17,6 +17,7 @@ package metrics
 import (
 	"context"
 	"os"
+	"strings"
 	"time"
 
 	"go.opencensus.io/stats"
@@ -92,6 +93,16 @@ func RecordParserDuration(ctx context.Context, trigger, source, status string, s
 	record(tagCtx, measurement)
 }
 
+// RecordRun produces a measurement for the Runs and RunDuration views.
+func RecordRun(ctx context.Context, trigger, result, stages string, startTime time.Time) {
+	tagCtx, _ := tag.New(ctx,
+		tag.Upsert(KeyTrigger, trigger),
+		tag.Upsert(KeyRunResult, result),
+		tag.Upsert(KeyRunStages, stages))
+	measurement := RunDuration.M(time.Since(startTime).Seconds())
+	record(tagCtx, measurement)
+}
+
 // RecordLastSync produces a measurement for the LastSync view.
 func RecordLastSync(ctx context.Context, status, commit string, timestamp time.Time) {
 	tagCtx, _ := tag.New(ctx,
@@ -101,6 +112,12 @@ func RecordLastSync(ctx context.Context, status, commit string, timestamp time.T
 	record(tagCtx, measurement)
 }
 
+// RecordLastFullResync produces a measurement for the LastFullResyncTimestamp view.
+func RecordLastFullResync(ctx context.Context, timestamp time.Time) {
+	measurement := LastFullResync.M(timestamp.Unix())
+	record(ctx, measurement)
+}
+
 // RecordDeclaredResources produces a measurement for the DeclaredResources view.
 func RecordDeclaredResources(ctx context.Context, commit string, numResources int) {
 	tagCtx, _ := tag.New(ctx,
@@ -169,9 +186,81 @@ func RecordResourceConflict(ctx context.Context, _, commit string) {
 	record(tagCtx, measurement)
 }
 
+// RecordResourceRecreated produces a measurement for the ResourcesRecreated view.
+func RecordResourceRecreated(ctx context.Context, kind string) {
+	tagCtx, _ := tag.New(ctx, tag.Upsert(KeyType, kind))
+	measurement := ResourcesRecreated.M(1)
+	record(tagCtx, measurement)
+}
+
 // RecordInternalError produces measurements for the InternalErrors view.
 func RecordInternalError(ctx context.Context, source string) {
 	tagCtx, _ := tag.New(ctx, tag.Upsert(KeyInternalErrorSource, source))
 	measurement := InternalErrors.M(1)
 	record(tagCtx, measurement)
 }
+
+// RecordReconcilerRetry produces a measurement for the ReconcilerRetries view.
+func RecordReconcilerRetry(ctx context.Context, retryClass string) {
+	tagCtx, _ := tag.New(ctx, tag.Upsert(KeyErrorRetryClass, retryClass))
+	measurement := ReconcilerRetries.M(1)
+	record(tagCtx, measurement)
+}
+
+// RecordRetriesExhausted produces a measurement for the RetriesExhausted view.
+func RecordRetriesExhausted(ctx context.Context, exhausted bool) {
+	var value int64
+	if exhausted {
+		value = 1
+	}
+	measurement := RetriesExhausted.M(value)
+	record(ctx, measurement)
+}
+
+// RecordSyncFlapping produces a measurement for the SyncFlapping view.
+func RecordSyncFlapping(ctx context.Context, flapping bool) {
+	var value int64
+	if flapping {
+		value = 1
+	}
+	measurement := SyncFlapping.M(value)
+	record(ctx, measurement)
+}
+
+// RecordCRDPruneBlockedResources produces a measurement for the CRDPruneBlockedResources view.
+func RecordCRDPruneBlockedResources(ctx context.Context, count int) {
+	measurement := CRDPruneBlockedResources.M(int64(count))
+	record(ctx, measurement)
+}
+
+// RecordWebhookDegraded produces a measurement for the WebhookDegraded view.
+func RecordWebhookDegraded(ctx context.Context, degraded bool) {
+	var value int64
+	if degraded {
+		value = 1
+	}
+	measurement := WebhookDegraded.M(value)
+	record(ctx, measurement)
+}
+
+// RecordOrphanedResources produces a measurement for the OrphanedResources view.
+func RecordOrphanedResources(ctx context.Context, count int) {
+	measurement := OrphanedResources.M(int64(count))
+	record(ctx, measurement)
+}
+
+// RecordResourceConsumption produces measurements for the
+// DeclaredResourcesBytes and APICallRate views.
+func RecordResourceConsumption(ctx context.Context, bytes, apiCallsPerMinute int64) {
+	record(ctx, DeclaredResourcesBytes.M(bytes), APICallRate.M(apiCallsPerMinute))
+}
+
+// RecordAPICallThrottled produces a measurement for the APICallThrottled view.
+func RecordAPICallThrottled(ctx context.Context, method, priorityLevel string) {
+	tagCtx, _ := tag.New(ctx,
+		tag.Upsert(KeyOperation, strings.ToLower(method)),
+		tag.Upsert(KeyPriorityLevel, priorityLevel),
+	)
+	measurement := APICallThrottled.M(1)
+	record(tagCtx, measurement)
+}
@@ -44,24 +44,72 @@ func RegisterOCAgentExporter(containerName string) (*ocagent.Exporter, error) {
 
 // RegisterReconcilerManagerMetricsViews registers the views so that recorded metrics can be exported in the reconciler manager.
 func RegisterReconcilerManagerMetricsViews() error {
-	return view.Register(ReconcileDurationView)
+	return view.Register(ReconcileDurationView, WebhookDegradedView)
 }
 
-// RegisterReconcilerMetricsViews registers the views so that recorded metrics can be exported in the reconcilers.
-func RegisterReconcilerMetricsViews() error {
-	return view.Register(
-		APICallDurationView,
-		ReconcilerErrorsView,
-		ParserDurationView,
-		LastApplyTimestampView,
-		LastSyncTimestampView,
-		DeclaredResourcesView,
-		ApplyOperationsView,
-		ApplyDurationView,
-		ResourceFightsView,
-		RemediateDurationView,
-		ResourceConflictsView,
-		InternalErrorsView,
-		PipelineErrorView,
-	)
+// Metrics levels of the reconcilers.
+const (
+	// LevelDisabled disables the metrics of the reconciler.
+	LevelDisabled = "disabled"
+	// LevelBasic only enables the metrics of the sync health.
+	LevelBasic = "basic"
+	// LevelFull enables all the metrics. This is the default.
+	LevelFull = "full"
+)
+
+// basicReconcilerViews are the views of the sync health, which are enabled by
+// the basic metrics level.
+var basicReconcilerViews = []*view.View{
+	ReconcilerErrorsView,
+	PipelineErrorView,
+	LastApplyTimestampView,
+	LastSyncTimestampView,
+	DeclaredResourcesView,
+	ApplyDurationView,
+}
+
+// ReconcilerMetricsViews returns the views of the reconcilers enabled by the
+// metrics level. Unknown levels enable all the views.
+func ReconcilerMetricsViews(level string) []*view.View {
+	switch level {
+	case LevelDisabled:
+		return nil
+	case LevelBasic:
+		return basicReconcilerViews
+	default:
+		return []*view.View{
+			APICallDurationView,
+			ReconcilerErrorsView,
+			ParserDurationView,
+			LastApplyTimestampView,
+			LastSyncTimestampView,
+			LastFullResyncTimestampView,
+			DeclaredResourcesView,
+			ApplyOperationsView,
+			ApplyDurationView,
+			ResourceFightsView,
+			RemediateDurationView,
+			ResourceConflictsView,
+			ResourcesRecreatedView,
+			InternalErrorsView,
+			ReconcilerRetriesView,
+			RetriesExhaustedView,
+			SyncFlappingView,
+			OrphanedResourcesView,
+			CRDPruneBlockedResourcesView,
+			DeclaredResourcesBytesView,
+			APICallRateView,
+			APICallThrottledView,
+			PipelineErrorView,
+			RunsView,
+			RunDurationView,
+		}
+	}
+}
+
+// RegisterReconcilerMetricsViews registers the views enabled by the metrics
+// level, so that recorded metrics can be exported in the reconcilers.
+// Measurements of the views which are not registered are dropped.
+func RegisterReconcilerMetricsViews(level string) error {
+	return view.Register(ReconcilerMetricsViews(level)...)
 }
@@ -0,0 +1,33 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcilerMetricsViews(t *testing.T) {
+	assert.Empty(t, ReconcilerMetricsViews(LevelDisabled))
+
+	basic := ReconcilerMetricsViews(LevelBasic)
+	full := ReconcilerMetricsViews(LevelFull)
+	assert.Less(t, len(basic), len(full))
+	for _, v := range basic {
+		assert.Contains(t, full, v)
+	}
+	assert.Equal(t, full, ReconcilerMetricsViews(""))
+}
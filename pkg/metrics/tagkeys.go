@@ -48,6 +48,9 @@ var (
 	// KeyErrorClass groups metrics by their error code.
 	KeyErrorClass, _ = tag.NewKey("errorclass")
 
+	// KeyErrorRetryClass groups metrics by how their errors are retried. Possible values: transient, user, terminal.
+	KeyErrorRetryClass, _ = tag.NewKey("retry_class")
+
 	// KeyStatus groups metrics by their status. Possible values: success, error.
 	KeyStatus, _ = tag.NewKey("status")
 
@@ -63,6 +66,15 @@ var (
 	// KeyTrigger groups metrics by their trigger. Possible values: retry, watchUpdate, managementConflict, resync, reimport.
 	KeyTrigger, _ = tag.NewKey("trigger")
 
+	// KeyRunResult groups the metrics for the reconciler runs by their result. Possible values: succeeded, failed, skipped, rendering, deferred, superseded, restored.
+	KeyRunResult, _ = tag.NewKey("result")
+
+	// KeyRunStages groups the metrics for the reconciler runs by the stages they executed, e.g. "read,parse,update".
+	KeyRunStages, _ = tag.NewKey("stages")
+
+	// KeyPriorityLevel groups metrics by the UID of the API Priority and Fairness priority level the API server assigned the requests to.
+	KeyPriorityLevel, _ = tag.NewKey("priority_level")
+
 	// KeyCommit groups metrics by their git commit. Even though this tag has a high cardinality,
 	// it is only used by the `last_sync_timestamp` and `last_apply_timestamp` metrics.
 	// These are both aggregated as LastValue metrics so the number of recorded values will always be
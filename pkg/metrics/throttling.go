@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// priorityLevelHeader is the response header in which the API server returns
+// the UID of the API Priority and Fairness priority level it assigned the
+// request to.
+const priorityLevelHeader = "X-Kubernetes-PF-PriorityLevel-UID"
+
+// NewThrottlingRecorder wraps a http.RoundTripper to record the requests
+// throttled by the API server in the APICallThrottled metric.
+// It is meant to be passed to rest.Config.Wrap.
+func NewThrottlingRecorder(rt http.RoundTripper) http.RoundTripper {
+	return &throttlingRecorder{delegate: rt}
+}
+
+// throttlingRecorder is a http.RoundTripper which records the responses with
+// the 429 Too Many Requests status code.
+type throttlingRecorder struct {
+	delegate http.RoundTripper
+}
+
+var _ utilnet.RoundTripperWrapper = &throttlingRecorder{}
+
+// RoundTrip implements http.RoundTripper.
+func (t *throttlingRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.delegate.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		RecordAPICallThrottled(req.Context(), req.Method, resp.Header.Get(priorityLevelHeader))
+	}
+	return resp, err
+}
+
+// WrappedRoundTripper implements utilnet.RoundTripperWrapper.
+func (t *throttlingRecorder) WrappedRoundTripper() http.RoundTripper {
+	return t.delegate
+}
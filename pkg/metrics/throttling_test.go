@@ -0,0 +1,60 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"kpt.dev/configsync/pkg/testing/testmetrics"
+)
+
+func TestThrottlingRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.Header().Set(priorityLevelHeader, "workload-low")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := testmetrics.RegisterMetrics(APICallThrottledView)
+	client := &http.Client{Transport: NewThrottlingRecorder(http.DefaultTransport)}
+	for _, path := range []string{"/ok", "/throttled", "/throttled"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	wantMetrics := []*view.Row{
+		{
+			Data: &view.CountData{Value: 2},
+			Tags: []tag.Tag{
+				{Key: KeyOperation, Value: "get"},
+				{Key: KeyPriorityLevel, Value: "workload-low"},
+			},
+		},
+	}
+	if diff := m.ValidateMetrics(APICallThrottledView, wantMetrics); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -83,6 +83,14 @@ var (
 		Aggregation: view.LastValue(),
 	}
 
+	// LastFullResyncTimestampView aggregates the LastFullResync metric measurements.
+	LastFullResyncTimestampView = &view.View{
+		Name:        LastFullResync.Name(),
+		Measure:     LastFullResync,
+		Description: "The timestamp of the most recent periodic full resync that completed without errors",
+		Aggregation: view.LastValue(),
+	}
+
 	// DeclaredResourcesView aggregates the DeclaredResources metric measurements.
 	DeclaredResourcesView = &view.View{
 		Name:        DeclaredResources.Name(),
@@ -145,6 +153,15 @@ var (
 		Aggregation: view.Count(),
 	}
 
+	// ResourcesRecreatedView aggregates the ResourcesRecreated metric measurements.
+	ResourcesRecreatedView = &view.View{
+		Name:        ResourcesRecreated.Name() + "_total",
+		Measure:     ResourcesRecreated,
+		Description: "The total number of managed resources recreated by the remediator after they were deleted out-of-band",
+		TagKeys:     []tag.Key{KeyType},
+		Aggregation: view.Count(),
+	}
+
 	// InternalErrorsView aggregates the InternalErrors metric measurements.
 	InternalErrorsView = &view.View{
 		Name:        InternalErrors.Name() + "_total",
@@ -153,4 +170,96 @@ var (
 		TagKeys:     []tag.Key{KeyInternalErrorSource},
 		Aggregation: view.Count(),
 	}
+
+	// ReconcilerRetriesView aggregates the ReconcilerRetries metric measurements.
+	ReconcilerRetriesView = &view.View{
+		Name:        ReconcilerRetries.Name() + "_total",
+		Measure:     ReconcilerRetries,
+		Description: "The total number of retries scheduled for the reconciler, grouped by the class of the errors",
+		TagKeys:     []tag.Key{KeyErrorRetryClass},
+		Aggregation: view.Count(),
+	}
+
+	// RetriesExhaustedView aggregates the RetriesExhausted metric measurements.
+	RetriesExhaustedView = &view.View{
+		Name:        RetriesExhausted.Name(),
+		Measure:     RetriesExhausted,
+		Description: "Whether the reconciler stopped retrying the current commit after exhausting its retry budget (1) or not (0)",
+		Aggregation: view.LastValue(),
+	}
+
+	// SyncFlappingView aggregates the SyncFlapping metric measurements.
+	SyncFlappingView = &view.View{
+		Name:        SyncFlapping.Name(),
+		Measure:     SyncFlapping,
+		Description: "Whether the sync alternated between failure and success too often recently (1) or not (0)",
+		Aggregation: view.LastValue(),
+	}
+
+	// CRDPruneBlockedResourcesView aggregates the CRDPruneBlockedResources metric measurements.
+	CRDPruneBlockedResourcesView = &view.View{
+		Name:        CRDPruneBlockedResources.Name(),
+		Measure:     CRDPruneBlockedResources,
+		Description: "The current number of custom resources which would be deleted by pruning their CustomResourceDefinitions",
+		Aggregation: view.LastValue(),
+	}
+
+	// WebhookDegradedView aggregates the WebhookDegraded metric measurements.
+	WebhookDegradedView = &view.View{
+		Name:        WebhookDegraded.Name(),
+		Measure:     WebhookDegraded,
+		Description: "Whether the Config Sync admission webhook cannot prevent drift (1) or not (0)",
+		Aggregation: view.LastValue(),
+	}
+
+	// OrphanedResourcesView aggregates the OrphanedResources metric measurements.
+	OrphanedResourcesView = &view.View{
+		Name:        OrphanedResources.Name(),
+		Measure:     OrphanedResources,
+		Description: "The number of resources managed by the reconciler, but missing from its inventory, found by the last orphan audit",
+		Aggregation: view.LastValue(),
+	}
+
+	// DeclaredResourcesBytesView aggregates the DeclaredResourcesBytes metric measurements.
+	DeclaredResourcesBytesView = &view.View{
+		Name:        DeclaredResourcesBytes.Name(),
+		Measure:     DeclaredResourcesBytes,
+		Description: "The approximate size in bytes of the declared resources, computed from their JSON encoding",
+		Aggregation: view.LastValue(),
+	}
+
+	// APICallRateView aggregates the APICallRate metric measurements.
+	APICallRateView = &view.View{
+		Name:        APICallRate.Name(),
+		Measure:     APICallRate,
+		Description: "The average rate of API server calls per minute made by the reconciler since the previous resource consumption report",
+		Aggregation: view.LastValue(),
+	}
+
+	// APICallThrottledView aggregates the APICallThrottled metric measurements.
+	APICallThrottledView = &view.View{
+		Name:        APICallThrottled.Name() + "_total",
+		Measure:     APICallThrottled,
+		Description: "The total number of API server calls throttled by the API server, grouped by the HTTP method and the API Priority and Fairness priority level",
+		TagKeys:     []tag.Key{KeyOperation, KeyPriorityLevel},
+		Aggregation: view.Count(),
+	}
+
+	// RunsView counts the RunDuration metric measurements.
+	RunsView = &view.View{
+		Name:        "runs_total",
+		Measure:     RunDuration,
+		Description: "The total number of runs of the reconciler, grouped by their trigger, result, and executed stages",
+		TagKeys:     []tag.Key{KeyTrigger, KeyRunResult, KeyRunStages},
+		Aggregation: view.Count(),
+	}
+
+	// RunDurationView aggregates the RunDuration metric measurements.
+	RunDurationView = &view.View{
+		Name:        RunDuration.Name(),
+		Measure:     RunDuration,
+		Description: "The latency distribution of the runs of the reconciler, grouped by their trigger and result",
+		TagKeys:     []tag.Key{KeyTrigger, KeyRunResult},
+		Aggregation: view.Distribution(longDistributionBounds...),
+	}
 )
@@ -0,0 +1,61 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"io"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
+
+// WebhookReceiver is an http.Handler that receives push notifications from an
+// OCI registry. The content of a notification is ignored: every notification
+// triggers a fetch of the package, which is a no-op if the image is unchanged.
+type WebhookReceiver struct {
+	notifications chan struct{}
+}
+
+// NewWebhookReceiver returns a WebhookReceiver.
+func NewWebhookReceiver() *WebhookReceiver {
+	return &WebhookReceiver{
+		// Notifications received while a fetch is pending are coalesced.
+		notifications: make(chan struct{}, 1),
+	}
+}
+
+// Notifications returns the channel that receives a value after a
+// notification has been received.
+func (w *WebhookReceiver) Notifications() <-chan struct{} {
+	return w.notifications
+}
+
+// ServeHTTP implements http.Handler.
+func (w *WebhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	_, _ = io.Copy(io.Discard, req.Body)
+
+	select {
+	case w.notifications <- struct{}{}:
+		klog.Infof("Received a registry notification from %s", req.RemoteAddr)
+	default:
+		klog.V(3).Infof("Received a registry notification from %s, a fetch is already pending", req.RemoteAddr)
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookReceiver(t *testing.T) {
+	receiver := NewWebhookReceiver()
+
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	select {
+	case <-receiver.Notifications():
+		t.Fatal("GET should not trigger a fetch")
+	default:
+	}
+
+	// Notifications are coalesced while a fetch is pending.
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		receiver.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"events":[]}`)))
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("POST got status %d, want %d", rec.Code, http.StatusAccepted)
+		}
+	}
+	select {
+	case <-receiver.Notifications():
+	default:
+		t.Fatal("POST should trigger a fetch")
+	}
+	select {
+	case <-receiver.Notifications():
+		t.Fatal("notifications should be coalesced")
+	default:
+	}
+}
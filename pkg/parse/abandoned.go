@@ -0,0 +1,128 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxAbandonedResources is the maximum number of abandoned resources recorded
+// in the sync status. The oldest records are dropped first, to keep the size of
+// the RSync object bounded.
+const maxAbandonedResources = 100
+
+// ResourceAbandonedReason is the reason of the events emitted when the
+// reconciler stops managing a resource, because its management was disabled.
+const ResourceAbandonedReason = "ResourceAbandoned"
+
+// ResourceRetainedReason is the reason of the events emitted when the
+// reconciler stops managing a resource, because it was removed from the
+// source of truth, but its kind cannot be pruned.
+const ResourceRetainedReason = "ResourceRetained"
+
+// updateAbandonedResources records the objects which the applier stopped
+// managing in the sync status, with the reason why, and drops the records of
+// resources which are declared and managed again.
+// Returns the newly recorded resources.
+func updateAbandonedResources(syncStatus *v1beta1.SyncStatus, abandoned, retained, declared []client.Object, now metav1.Time) []v1beta1.AbandonedResource {
+	managed := make(map[core.ID]bool)
+	for _, obj := range declared {
+		// Reference-only objects are declared with management disabled, but
+		// are still declared, rather than abandoned.
+		if !differ.ManagementDisabled(obj) || isReferenceOnly(obj) {
+			managed[core.IDOf(obj)] = true
+		}
+	}
+
+	var records []v1beta1.AbandonedResource
+	recorded := make(map[core.ID]bool)
+	for _, r := range syncStatus.AbandonedResources {
+		id := resourceRefID(r.Resource)
+		if managed[id] {
+			continue
+		}
+		recorded[id] = true
+		records = append(records, r)
+	}
+
+	var added []v1beta1.AbandonedResource
+	add := func(objs []client.Object, reason string) {
+		for _, obj := range objs {
+			id := core.IDOf(obj)
+			if recorded[id] || managed[id] {
+				continue
+			}
+			recorded[id] = true
+			r := v1beta1.AbandonedResource{
+				Resource:    status.ToResourceRef(obj),
+				AbandonedAt: now,
+				Reason:      reason,
+			}
+			records = append(records, r)
+			added = append(added, r)
+		}
+	}
+	add(abandoned, v1beta1.AbandonedManagementDisabled)
+	add(retained, v1beta1.AbandonedPruneDenied)
+
+	if len(records) > maxAbandonedResources {
+		records = records[len(records)-maxAbandonedResources:]
+	}
+	syncStatus.AbandonedResources = records
+	return added
+}
+
+// resourceRefID returns the ID of the resource referenced by the ResourceRef.
+func resourceRefID(r v1beta1.ResourceRef) core.ID {
+	return core.ID{
+		GroupKind: schema.GroupKind{Group: r.GVK.Group, Kind: r.GVK.Kind},
+		ObjectKey: client.ObjectKey{Namespace: r.Namespace, Name: r.Name},
+	}
+}
+
+// recordAbandonedEvents emits an event on the RSync object for each of the
+// newly abandoned resources, so that they can be audited after the resources
+// are removed from the source of truth.
+// Failures are logged, but otherwise ignored, because events are best effort.
+func recordAbandonedEvents(ctx context.Context, c client.Client, rsync client.Object, gvk schema.GroupVersionKind, component string, resources []v1beta1.AbandonedResource) {
+	for _, r := range resources {
+		id := resourceRefID(r.Resource)
+		reason := ResourceAbandonedReason
+		message := fmt.Sprintf("Stopped managing %s, because it is annotated with `%s: %s`",
+			id, metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)
+		if r.Reason == v1beta1.AbandonedPruneDenied {
+			reason = ResourceRetainedReason
+			message = fmt.Sprintf("Stopped managing %s, because it was removed from the source of truth, but its kind cannot be pruned", id)
+		}
+		e := event.New(event.Reference(rsync, gvk), corev1.EventTypeNormal, reason, message,
+			component, r.AbandonedAt)
+		if err := c.Create(ctx, e); err != nil {
+			klog.Warningf("Failed to record the %s event for %s: %v", reason, id, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestUpdateAbandonedResources(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	oldRole := fake.RoleObject(core.Name("old"), core.Namespace("bookstore"))
+	newRole := fake.RoleObject(core.Name("new"), core.Namespace("bookstore"))
+	remanagedRole := fake.RoleObject(core.Name("remanaged"), core.Namespace("bookstore"))
+	retainedRole := fake.RoleObject(core.Name("retained"), core.Namespace("bookstore"))
+	disabled := core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)
+
+	syncStatus := &v1beta1.SyncStatus{
+		AbandonedResources: []v1beta1.AbandonedResource{
+			{Resource: status.ToResourceRef(oldRole), AbandonedAt: earlier},
+			{Resource: status.ToResourceRef(remanagedRole), AbandonedAt: earlier},
+		},
+	}
+	abandoned := []client.Object{oldRole, newRole}
+	declared := []client.Object{
+		fake.RoleObject(core.Name("old"), core.Namespace("bookstore"), disabled),
+		fake.RoleObject(core.Name("new"), core.Namespace("bookstore"), disabled),
+		fake.RoleObject(core.Name("remanaged"), core.Namespace("bookstore")),
+	}
+
+	retained := []client.Object{retainedRole}
+
+	added := updateAbandonedResources(syncStatus, abandoned, retained, declared, now)
+
+	wantAdded := []v1beta1.AbandonedResource{
+		{Resource: status.ToResourceRef(newRole), AbandonedAt: now, Reason: v1beta1.AbandonedManagementDisabled},
+		{Resource: status.ToResourceRef(retainedRole), AbandonedAt: now, Reason: v1beta1.AbandonedPruneDenied},
+	}
+	if diff := cmp.Diff(wantAdded, added); diff != "" {
+		t.Errorf("unexpected newly abandoned resources (-want, +got):\n%s", diff)
+	}
+	want := []v1beta1.AbandonedResource{
+		{Resource: status.ToResourceRef(oldRole), AbandonedAt: earlier},
+		{Resource: status.ToResourceRef(newRole), AbandonedAt: now, Reason: v1beta1.AbandonedManagementDisabled},
+		{Resource: status.ToResourceRef(retainedRole), AbandonedAt: now, Reason: v1beta1.AbandonedPruneDenied},
+	}
+	if diff := cmp.Diff(want, syncStatus.AbandonedResources); diff != "" {
+		t.Errorf("unexpected abandoned resources (-want, +got):\n%s", diff)
+	}
+}
+
+func TestUpdateAbandonedResourcesLimit(t *testing.T) {
+	now := metav1.Now()
+	syncStatus := &v1beta1.SyncStatus{}
+	var abandoned []client.Object
+	for i := 0; i <= maxAbandonedResources; i++ {
+		abandoned = append(abandoned, fake.UnstructuredObject(kinds.ConfigMap(),
+			core.Name(string(rune('a'+i%26))+string(rune('a'+i/26))), core.Namespace("bookstore")))
+	}
+
+	updateAbandonedResources(syncStatus, abandoned, nil, nil, now)
+
+	if got := len(syncStatus.AbandonedResources); got != maxAbandonedResources {
+		t.Fatalf("got %d abandoned resources, want %d", got, maxAbandonedResources)
+	}
+	// The oldest record is dropped first.
+	if got, want := syncStatus.AbandonedResources[0].Resource.Name, abandoned[1].GetName(); got != want {
+		t.Errorf("got first abandoned resource %q, want %q", got, want)
+	}
+}
+
+func TestRecordAbandonedEvents(t *testing.T) {
+	now := metav1.NewTime(time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC))
+	rs := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+	abandonedRole := fake.RoleObject(core.Name("abandoned"), core.Namespace("bookstore"))
+	retainedRole := fake.RoleObject(core.Name("retained"), core.Namespace("bookstore"))
+	c := syncerFake.NewClient(t, core.Scheme, rs)
+
+	recordAbandonedEvents(context.Background(), c, rs, kinds.RootSyncV1Beta1(), "root-reconciler", []v1beta1.AbandonedResource{
+		{Resource: status.ToResourceRef(abandonedRole), AbandonedAt: now, Reason: v1beta1.AbandonedManagementDisabled},
+		{Resource: status.ToResourceRef(retainedRole), AbandonedAt: now, Reason: v1beta1.AbandonedPruneDenied},
+	})
+
+	events := &corev1.EventList{}
+	if err := c.List(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, e := range events.Items {
+		got[e.Reason] = e.Message
+	}
+	want := map[string]string{
+		ResourceAbandonedReason: fmt.Sprintf("Stopped managing %s, because it is annotated with `%s: %s`",
+			core.IDOf(abandonedRole), metadata.ResourceManagementKey, metadata.ResourceManagementDisabled),
+		ResourceRetainedReason: fmt.Sprintf("Stopped managing %s, because it was removed from the source of truth, but its kind cannot be pruned",
+			core.IDOf(retainedRole)),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected events (-want, +got):\n%s", diff)
+	}
+}
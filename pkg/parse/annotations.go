@@ -33,19 +33,48 @@ type sourceContext struct {
 	Rev    string `json:"rev,omitempty"`
 }
 
+// addCommonLabelsAndAnnotations adds the common labels and annotations of the
+// RSync to the objects. The labels and annotations declared on an object take
+// precedence, and the Config Sync keys are skipped, so that the common
+// metadata cannot interfere with the metadata managed by Config Sync.
+func addCommonLabelsAndAnnotations(objs []ast.FileObject, labels, annotations map[string]string) {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		for k, v := range labels {
+			if metadata.IsConfigSyncLabelKey(k) {
+				continue
+			}
+			if _, found := obj.GetLabels()[k]; !found {
+				core.SetLabel(obj, k, v)
+			}
+		}
+		for k, v := range annotations {
+			if metadata.IsConfigSyncAnnotationKey(k) {
+				continue
+			}
+			if _, found := obj.GetAnnotations()[k]; !found {
+				core.SetAnnotation(obj, k, v)
+			}
+		}
+	}
+}
+
 func addAnnotationsAndLabels(objs []ast.FileObject, scope declared.Scope, syncName string, sc sourceContext, commitHash string) error {
 	gcVal, err := json.Marshal(sc)
 	if err != nil {
 		return fmt.Errorf("marshaling sourceContext: %w", err)
 	}
-	var inventoryID string
+	syncNamespace := string(scope)
 	if scope == declared.RootReconciler {
-		inventoryID = applier.InventoryID(syncName, configmanagement.ControllerNamespace)
-	} else {
-		inventoryID = applier.InventoryID(syncName, string(scope))
+		syncNamespace = configmanagement.ControllerNamespace
 	}
+	inventoryID := applier.InventoryID(syncName, syncNamespace)
+	inventoryLabel := applier.InventoryLabelValue(syncName, syncNamespace)
 	for _, obj := range objs {
 		core.SetLabel(obj, metadata.ManagedByKey, metadata.ManagedByValue)
+		core.SetLabel(obj, metadata.OwningInventoryLabel, inventoryLabel)
 		core.SetAnnotation(obj, metadata.GitContextKey, string(gcVal))
 		core.SetAnnotation(obj, metadata.ResourceManagerKey, declared.ResourceManager(scope, syncName))
 		core.SetAnnotation(obj, metadata.SyncTokenAnnotationKey, commitHash)
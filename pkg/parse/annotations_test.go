@@ -50,6 +50,7 @@ func TestAddAnnotationsAndLabels(t *testing.T) {
 			expected: []ast.FileObject{fake.Role(
 				core.Namespace("foo"),
 				core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+				core.Label(metadata.OwningInventoryLabel, applier.InventoryID("rs", "some-namespace")),
 				core.Annotation(metadata.ResourceManagementKey, "enabled"),
 				core.Annotation(metadata.ResourceManagerKey, "some-namespace_rs"),
 				core.Annotation(metadata.SyncTokenAnnotationKey, "1234567"),
@@ -71,3 +72,36 @@ func TestAddAnnotationsAndLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestAddCommonLabelsAndAnnotations(t *testing.T) {
+	labels := map[string]string{
+		"owner":               "platform",
+		"cost-center":         "1234",
+		metadata.ManagedByKey: "someone-else",
+	}
+	annotations := map[string]string{
+		"example.com/contact":          "platform@example.com",
+		metadata.ResourceManagementKey: metadata.ResourceManagementDisabled,
+	}
+	objs := []ast.FileObject{
+		fake.Role(core.Namespace("foo")),
+		fake.Role(core.Namespace("bar"), core.Label("owner", "team-bar")),
+	}
+	addCommonLabelsAndAnnotations(objs, labels, annotations)
+
+	expected := []ast.FileObject{
+		fake.Role(core.Namespace("foo"),
+			core.Label("owner", "platform"),
+			core.Label("cost-center", "1234"),
+			core.Annotation("example.com/contact", "platform@example.com"),
+		),
+		fake.Role(core.Namespace("bar"),
+			core.Label("owner", "team-bar"),
+			core.Label("cost-center", "1234"),
+			core.Annotation("example.com/contact", "platform@example.com"),
+		),
+	}
+	if diff := cmp.Diff(expected, objs, ast.CompareFileObject); diff != "" {
+		t.Errorf(diff)
+	}
+}
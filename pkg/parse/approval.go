@@ -0,0 +1,114 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkApproval returns whether the commit is approved for applying by the
+// external approval system. It requests the approval with the ApprovalRequest
+// of the reconciler if needed. It returns an error if the commit was denied,
+// or not approved before the request expired.
+//
+// Every commit is approved if no approval timeout is configured, and the commit
+// which is already synced needs no approval.
+func checkApproval(ctx context.Context, p Parser, state *reconcilerState, commit string) (bool, status.Error) {
+	opts := p.options()
+	if opts.ApprovalTimeout <= 0 || commit == state.approvedCommit {
+		return true, nil
+	}
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return false, status.APIServerError(err, "failed to get the RSync to request the approval")
+	}
+	lastSynced := lastSyncedCommit(rs)
+	if commit == lastSynced {
+		state.approvedCommit = commit
+		return true, nil
+	}
+
+	req := &v1beta1.ApprovalRequest{}
+	req.Name = opts.reconcilerName
+	req.Namespace = opts.syncNamespace()
+	now := metav1.Now()
+	spec := v1beta1.ApprovalRequestSpec{
+		Commit:           commit,
+		LastSyncedCommit: lastSynced,
+		RequestedAt:      now,
+		ExpiresAt:        metav1.NewTime(now.Add(opts.ApprovalTimeout)),
+	}
+	err = opts.k8sClient().Get(ctx, client.ObjectKeyFromObject(req), req)
+	switch {
+	case apierrors.IsNotFound(err):
+		// The request is deleted with the RootSync or RepoSync.
+		req.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(rs, rs.GetObjectKind().GroupVersionKind()),
+		}
+		req.Spec = spec
+		if err := opts.k8sClient().Create(ctx, req); err != nil {
+			return false, status.APIServerError(err, "failed to create the ApprovalRequest")
+		}
+		klog.Infof("Requested the approval of commit %s with ApprovalRequest %s/%s", commit, req.Namespace, req.Name)
+		return false, nil
+	case err != nil:
+		return false, status.APIServerError(err, "failed to get the ApprovalRequest")
+	case req.Spec.Commit != commit:
+		// The decision on the previous commit is ignored, since it is for
+		// another commit.
+		req.Spec = spec
+		if err := opts.k8sClient().Update(ctx, req); err != nil {
+			return false, status.APIServerError(err, "failed to update the ApprovalRequest")
+		}
+		klog.Infof("Requested the approval of commit %s with ApprovalRequest %s/%s", commit, req.Namespace, req.Name)
+		return false, nil
+	}
+
+	if req.Status.Commit == commit {
+		switch req.Status.Decision {
+		case v1beta1.ApprovalApproved:
+			klog.Infof("Commit %s was approved by %q", commit, req.Status.DecidedBy)
+			state.approvedCommit = commit
+			return true, nil
+		case v1beta1.ApprovalDenied:
+			return false, status.ApprovalDeniedError(commit, req.Status.DecidedBy, req.Status.Message)
+		}
+	}
+	// A late approval is still honored, so the request is checked before
+	// its expiry.
+	if now.After(req.Spec.ExpiresAt.Time) {
+		return false, status.ApprovalTimedOutError(commit, req.Spec.ExpiresAt.Time)
+	}
+	return false, nil
+}
+
+// lastSyncedCommit returns the commit last synced by the RootSync or RepoSync.
+func lastSyncedCommit(rs client.Object) string {
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		return rs.Status.LastSyncedCommit
+	case *v1beta1.RepoSync:
+		return rs.Status.LastSyncedCommit
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCheckApproval(t *testing.T) {
+	ctx := context.Background()
+	rs := fake.RootSyncObjectV1Beta1(rootSyncName)
+	rs.Status.LastSyncedCommit = "abc123"
+	p := newParser(t, FileSource{})
+	p.options().ApprovalTimeout = time.Hour
+	p.options().client = syncerFake.NewClient(t, core.Scheme, rs)
+	k8sClient := p.options().k8sClient()
+	reqKey := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: rootReconcilerName}
+	state := &reconcilerState{}
+
+	decide := func(commit string, decision v1beta1.ApprovalDecision) {
+		t.Helper()
+		req := &v1beta1.ApprovalRequest{}
+		require.NoError(t, k8sClient.Get(ctx, reqKey, req))
+		req.Status = v1beta1.ApprovalRequestStatus{Commit: commit, Decision: decision, DecidedBy: "CHG-1", Message: "reviewed"}
+		require.NoError(t, k8sClient.Status().Update(ctx, req))
+	}
+
+	// The synced commit needs no approval.
+	approved, err := checkApproval(ctx, p, state, "abc123")
+	require.NoError(t, err)
+	require.True(t, approved)
+	require.True(t, apierrors.IsNotFound(k8sClient.Get(ctx, reqKey, &v1beta1.ApprovalRequest{})))
+
+	// A new commit is requested for approval.
+	approved, err = checkApproval(ctx, p, state, "def456")
+	require.NoError(t, err)
+	require.False(t, approved)
+	req := &v1beta1.ApprovalRequest{}
+	require.NoError(t, k8sClient.Get(ctx, reqKey, req))
+	require.Equal(t, "def456", req.Spec.Commit)
+	require.Equal(t, "abc123", req.Spec.LastSyncedCommit)
+	require.Len(t, req.OwnerReferences, 1)
+	require.Equal(t, configsync.RootSyncKind, req.OwnerReferences[0].Kind)
+
+	// A decision on another commit is ignored.
+	decide("abc123", v1beta1.ApprovalDenied)
+	approved, err = checkApproval(ctx, p, state, "def456")
+	require.NoError(t, err)
+	require.False(t, approved)
+
+	decide("def456", v1beta1.ApprovalDenied)
+	_, err = checkApproval(ctx, p, state, "def456")
+	require.Error(t, err)
+	require.Equal(t, status.ApprovalErrorCode, err.Code())
+
+	// A newer commit replaces the pending request.
+	approved, err = checkApproval(ctx, p, state, "ghi789")
+	require.NoError(t, err)
+	require.False(t, approved)
+	require.NoError(t, k8sClient.Get(ctx, reqKey, req))
+	require.Equal(t, "ghi789", req.Spec.Commit)
+
+	decide("ghi789", v1beta1.ApprovalApproved)
+	approved, err = checkApproval(ctx, p, state, "ghi789")
+	require.NoError(t, err)
+	require.True(t, approved)
+	require.Equal(t, "ghi789", state.approvedCommit)
+}
+
+func TestCheckApproval_Expired(t *testing.T) {
+	ctx := context.Background()
+	rs := fake.RootSyncObjectV1Beta1(rootSyncName)
+	p := newParser(t, FileSource{})
+	p.options().ApprovalTimeout = time.Nanosecond
+	p.options().client = syncerFake.NewClient(t, core.Scheme, rs)
+	state := &reconcilerState{}
+
+	approved, err := checkApproval(ctx, p, state, "abc123")
+	require.NoError(t, err)
+	require.False(t, approved)
+	time.Sleep(time.Millisecond)
+	_, err = checkApproval(ctx, p, state, "abc123")
+	require.Error(t, err)
+	require.Equal(t, status.ApprovalErrorCode, err.Code())
+}
+
+func TestCheckApproval_Disabled(t *testing.T) {
+	p := newParser(t, FileSource{})
+	approved, err := checkApproval(context.Background(), p, &reconcilerState{}, "abc123")
+	require.NoError(t, err)
+	require.True(t, approved)
+}
@@ -19,9 +19,9 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
-	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/status"
 )
 
@@ -48,6 +48,14 @@ type cacheForCommit struct {
 	// objsToApply contains the objects which will be sent to the applier to apply.
 	objsToApply []ast.FileObject
 
+	// objsSelectorSkipped contains the declared objects which are filtered
+	// out by their cluster selector or namespace selector.
+	objsSelectorSkipped []ast.FileObject
+
+	// webhookWarning is the warning of the failed update of the admission
+	// webhook configuration with the parsed objects, or nil.
+	webhookWarning *v1beta1.SyncWarning
+
 	// parserErrs includes the parser errors.
 	parserErrs status.MultiError
 
@@ -69,9 +77,16 @@ type cacheForCommit struct {
 	// reconciliationWithSameErrs tracks the number of reconciliation attempts failed with the same errors.
 	reconciliationWithSameErrs int
 
+	// failures tracks the number of consecutive failed reconciliation attempts for the commit.
+	failures int
+
 	// nextRetryTime tracks when the next retry should happen.
 	nextRetryTime time.Time
 
+	// errClass is the class of the errors of the last failed reconciliation
+	// attempt, which determines the retry backoff.
+	errClass status.ErrorClass
+
 	// errs tracks all the errors encounted during the reconciliation.
 	errs status.MultiError
 }
@@ -84,6 +99,27 @@ func (c *cacheForCommit) setParserResult(objs []ast.FileObject, parserErrs statu
 	c.hasParserResult = true
 }
 
+// appliedDigest returns true if the rendered configs with the digest were
+// successfully parsed, applied and watched.
+func (c *cacheForCommit) appliedDigest(digest string) bool {
+	return digest != "" && c.source.digest == digest && c.parserResultUpToDate() &&
+		c.declaredResourcesUpdated && c.applied && c.watchesUpdated
+}
+
+// reuseResults copies the parse, apply and watch results of the other cache,
+// whose rendered configs are identical.
+func (c *cacheForCommit) reuseResults(other cacheForCommit) {
+	c.hasParserResult = other.hasParserResult
+	c.objsSkipped = other.objsSkipped
+	c.objsToApply = other.objsToApply
+	c.objsSelectorSkipped = other.objsSelectorSkipped
+	c.webhookWarning = other.webhookWarning
+	c.parserErrs = other.parserErrs
+	c.declaredResourcesUpdated = other.declaredResourcesUpdated
+	c.applied = other.applied
+	c.watchesUpdated = other.watchesUpdated
+}
+
 func (c *cacheForCommit) readyToRetry() bool {
 	return !time.Now().Before(c.nextRetryTime)
 }
@@ -101,7 +137,7 @@ func splitObjects(objs []ast.FileObject) ([]ast.FileObject, []ast.FileObject) {
 	var knownScopeObjs, unknownScopeObjs []ast.FileObject
 	var unknownScopeIDs []string
 	for _, obj := range objs {
-		if core.GetAnnotation(obj, metadata.UnknownScopeAnnotationKey) == metadata.UnknownScopeAnnotationValue {
+		if isUnknownScope(obj) {
 			unknownScopeObjs = append(unknownScopeObjs, obj)
 			unknownScopeIDs = append(unknownScopeIDs, core.GKNN(obj.Unstructured))
 		} else {
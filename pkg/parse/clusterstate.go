@@ -0,0 +1,107 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/clusterstate"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+)
+
+// clusterStateCapture is the state of the managed objects captured before an
+// apply.
+type clusterStateCapture struct {
+	// objs are the previously and newly declared objects.
+	objs []*unstructured.Unstructured
+	// before is the state of objs before the apply.
+	before clusterstate.State
+}
+
+// clusterStateDiffEnabled returns whether the RootSync or RepoSync opted in to
+// the cluster state diffs.
+func clusterStateDiffEnabled(ctx context.Context, opts *opts) bool {
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		klog.Warningf("Failed to get the RSync to check the %s annotation: %v", metadata.ClusterStateDiffKey, err)
+		return false
+	}
+	return core.GetAnnotation(rs, metadata.ClusterStateDiffKey) == metadata.ClusterStateDiffEnabled
+}
+
+// captureClusterState captures the state of the previously and newly
+// declared objects before they are applied, if the cluster state diffs are
+// enabled and the commit is not applied yet. Returns nil otherwise, or if the
+// capture fails, which does not fail the reconciliation.
+func captureClusterState(ctx context.Context, p Parser, state *reconcilerState) *clusterStateCapture {
+	opts := p.options()
+	if state.cache.applied || !clusterStateDiffEnabled(ctx, opts) {
+		return nil
+	}
+	previous, _ := opts.resources.DeclaredUnstructureds()
+	seen := make(map[core.ID]bool, len(previous)+len(state.cache.objsToApply))
+	var objs []*unstructured.Unstructured
+	for _, obj := range previous {
+		seen[core.IDOf(obj)] = true
+		objs = append(objs, obj)
+	}
+	for _, obj := range state.cache.objsToApply {
+		if id := core.IDOf(obj.Unstructured); !seen[id] {
+			seen[id] = true
+			objs = append(objs, obj.Unstructured)
+		}
+	}
+	before, err := clusterstate.Capture(ctx, opts.k8sClient(), objs)
+	if err != nil {
+		klog.Warningf("Failed to capture the cluster state before applying commit %s: %v", state.cache.source.commit, err)
+		return nil
+	}
+	return &clusterStateCapture{objs: objs, before: before}
+}
+
+// publishClusterStateDiff captures the state of the objects after the apply,
+// and publishes the diff with the state captured before, if any of them
+// changed. Failures are logged, but otherwise ignored.
+func publishClusterStateDiff(ctx context.Context, p Parser, state *reconcilerState, capture *clusterStateCapture) {
+	if capture == nil {
+		return
+	}
+	opts := p.options()
+	commit := state.cache.source.commit
+	after, err := clusterstate.Capture(ctx, opts.k8sClient(), capture.objs)
+	if err != nil {
+		klog.Warningf("Failed to capture the cluster state after applying commit %s: %v", commit, err)
+		return
+	}
+	changes := clusterstate.Compare(capture.before, after)
+	if len(changes) == 0 {
+		klog.V(3).Infof("Applying commit %s did not change the cluster state", commit)
+		return
+	}
+	diff := clusterstate.Diff{
+		Commit:    commit,
+		AppliedAt: time.Now().UTC(),
+		Changes:   changes,
+	}
+	if err := clusterstate.Publish(ctx, opts.k8sClient(), opts.syncNamespace(), opts.reconcilerName, diff); err != nil {
+		klog.Warningf("Failed to publish the cluster state diff of commit %s: %v", commit, err)
+		return
+	}
+	klog.Infof("Published the cluster state diff of commit %s with %d changes", commit, len(changes))
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// diffCreated means the object was added to the source of truth.
+	diffCreated = "Created"
+	// diffUpdated means the declared fields of the object changed.
+	diffUpdated = "Updated"
+	// diffRemoved means the object was removed from the source of truth.
+	diffRemoved = "Removed"
+
+	// maxDiffFieldDepth is the maximum depth of the changed field paths.
+	// Changes below are summarized by their ancestor at that depth, e.g.
+	// spec.template.spec.
+	maxDiffFieldDepth = 3
+	// maxDiffFields is the maximum number of changed field paths recorded per
+	// object.
+	maxDiffFields = 10
+	// maxCommitDiffSize is the maximum size of the commit diff annotation, so
+	// that it leaves room for the inventory in the ResourceGroup.
+	maxCommitDiffSize = 64 * 1024
+)
+
+// commitDiff is the value of the commit diff annotation on the ResourceGroup.
+// The objects of the inventory which are not listed were not changed by the
+// commit.
+type commitDiff struct {
+	// Commit is the synced commit.
+	Commit string `json:"commit"`
+	// PreviousCommit is the commit synced before.
+	PreviousCommit string `json:"previousCommit"`
+	// Resources are the objects created, updated or removed by the commit.
+	Resources []resourceDiff `json:"resources,omitempty"`
+	// Unchanged is the number of declared objects not changed by the commit.
+	Unchanged int `json:"unchanged"`
+	// Truncated is true if some changed objects or fields are not listed,
+	// to keep the annotation small.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// resourceDiff is the change of a declared object between two commits.
+type resourceDiff struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Action is one of Created, Updated and Removed.
+	Action string `json:"action"`
+	// Fields are the dotted paths of the changed fields of updated objects.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// diffCommits compares the objects declared in the previous commit with the
+// objects to apply, before they are applied. Returns nil if the commit is
+// already applied, or if the previous commit is unknown, e.g. after the
+// reconciler restarted, since every object would be reported as created.
+func diffCommits(p Parser, state *reconcilerState) *commitDiff {
+	if state.cache.applied {
+		return nil
+	}
+	previous, previousCommit := p.options().resources.DeclaredUnstructureds()
+	commit := state.cache.source.commit
+	if previousCommit == "" || previousCommit == commit {
+		return nil
+	}
+	diff := &commitDiff{Commit: commit, PreviousCommit: previousCommit}
+	before := make(map[core.ID]*unstructured.Unstructured, len(previous))
+	for _, obj := range previous {
+		before[core.IDOf(obj)] = obj
+	}
+	declared := make(map[core.ID]bool, len(state.cache.objsToApply))
+	for _, obj := range state.cache.objsToApply {
+		id := core.IDOf(obj.Unstructured)
+		declared[id] = true
+		old, found := before[id]
+		if !found {
+			diff.Resources = append(diff.Resources, newResourceDiff(id, diffCreated, nil))
+			continue
+		}
+		fields := changedFields(diffContent(old), diffContent(obj.Unstructured), "", 1)
+		if len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Resources = append(diff.Resources, newResourceDiff(id, diffUpdated, fields))
+	}
+	for id := range before {
+		if !declared[id] {
+			diff.Resources = append(diff.Resources, newResourceDiff(id, diffRemoved, nil))
+		}
+	}
+	sort.Slice(diff.Resources, func(i, j int) bool {
+		return resourceDiffKey(diff.Resources[i]) < resourceDiffKey(diff.Resources[j])
+	})
+	return diff
+}
+
+func newResourceDiff(id core.ID, action string, fields []string) resourceDiff {
+	if len(fields) > maxDiffFields {
+		fields = fields[:maxDiffFields]
+	}
+	return resourceDiff{
+		Group:     id.Group,
+		Kind:      id.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+		Action:    action,
+		Fields:    fields,
+	}
+}
+
+func resourceDiffKey(r resourceDiff) string {
+	return r.Group + "/" + r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// diffContent returns the content of the declared object without the metadata
+// set by Config Sync, e.g. the source path or the declared fields, which
+// change without the object changing.
+func diffContent(obj *unstructured.Unstructured) map[string]interface{} {
+	u := obj.DeepCopy()
+	metadata.RemoveConfigSyncMetadata(u)
+	return u.Object
+}
+
+// changedFields returns the sorted dotted paths of the fields which differ
+// between the two objects, down to maxDiffFieldDepth.
+func changedFields(before, after map[string]interface{}, prefix string, depth int) []string {
+	keys := make(map[string]bool, len(before)+len(after))
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	var fields []string
+	for k := range keys {
+		oldValue, newValue := before[k], after[k]
+		if equality.Semantic.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap && depth < maxDiffFieldDepth {
+			fields = append(fields, changedFields(oldMap, newMap, path, depth+1)...)
+			continue
+		}
+		fields = append(fields, path)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// encode returns the JSON of the diff, dropping the changed fields, then the
+// changed objects, until it fits in maxCommitDiffSize.
+func (d *commitDiff) encode() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil || len(data) <= maxCommitDiffSize {
+		return data, err
+	}
+	d.Truncated = true
+	for i := range d.Resources {
+		d.Resources[i].Fields = nil
+	}
+	for {
+		data, err = json.Marshal(d)
+		if err != nil || len(data) <= maxCommitDiffSize || len(d.Resources) == 0 {
+			return data, err
+		}
+		d.Resources = d.Resources[:len(d.Resources)/2]
+	}
+}
+
+// recordCommitDiff records the diff in the annotation of the ResourceGroup
+// inventory of the RootSync or RepoSync. Failures are logged, but otherwise
+// ignored, since the diff is informational.
+func recordCommitDiff(ctx context.Context, p Parser, diff *commitDiff) {
+	if diff == nil {
+		return
+	}
+	opts := p.options()
+	value, err := diff.encode()
+	if err != nil {
+		klog.Warningf("Failed to encode the diff of commit %s: %v", diff.Commit, err)
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{metadata.CommitDiffKey: string(value)},
+		},
+	})
+	if err != nil {
+		klog.Warningf("Failed to encode the diff of commit %s: %v", diff.Commit, err)
+		return
+	}
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	rg.SetNamespace(opts.syncNamespace())
+	rg.SetName(opts.syncName)
+	if err := opts.k8sClient().Patch(ctx, rg, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(3).Infof("Skipping the diff of commit %s: the ResourceGroup %s/%s does not exist", diff.Commit, opts.syncNamespace(), opts.syncName)
+			return
+		}
+		klog.Warningf("Failed to record the diff of commit %s on the ResourceGroup %s/%s: %v", diff.Commit, opts.syncNamespace(), opts.syncName, err)
+		return
+	}
+	klog.V(3).Infof("Recorded the diff of commit %s with %d changed objects", diff.Commit, len(diff.Resources))
+}
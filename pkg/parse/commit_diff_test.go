@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestChangedFields(t *testing.T) {
+	before := fake.UnstructuredObject(kinds.Deployment(),
+		core.Name("web"), core.Namespace("bookstore"),
+		core.Annotation(metadata.SourcePathAnnotationKey, "web.yaml"))
+	_ = unstructured.SetNestedField(before.Object, int64(1), "spec", "replicas")
+	_ = unstructured.SetNestedField(before.Object, "nginx:1.0", "spec", "template", "spec", "image")
+
+	after := before.DeepCopy()
+	core.SetAnnotation(after, metadata.SourcePathAnnotationKey, "apps/web.yaml")
+	core.SetLabel(after, "team", "books")
+	_ = unstructured.SetNestedField(after.Object, int64(2), "spec", "replicas")
+	_ = unstructured.SetNestedField(after.Object, "nginx:1.1", "spec", "template", "spec", "image")
+
+	got := changedFields(diffContent(before), diffContent(after), "", 1)
+	require.Equal(t, []string{"metadata.labels.team", "spec.replicas", "spec.template.spec"}, got)
+
+	require.Empty(t, changedFields(diffContent(before), diffContent(before.DeepCopy()), "", 1))
+}
+
+func TestCommitDiffEncode(t *testing.T) {
+	diff := &commitDiff{Commit: "def456", PreviousCommit: "abc123", Unchanged: 3}
+	for i := 0; i < 5000; i++ {
+		diff.Resources = append(diff.Resources, resourceDiff{
+			Kind:      "ConfigMap",
+			Namespace: "bookstore",
+			Name:      fmt.Sprintf("cm-%d", i),
+			Action:    diffUpdated,
+			Fields:    []string{"data." + strings.Repeat("k", 20)},
+		})
+	}
+	data, err := diff.encode()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), maxCommitDiffSize)
+
+	got := &commitDiff{}
+	require.NoError(t, json.Unmarshal(data, got))
+	require.True(t, got.Truncated)
+	require.NotEmpty(t, got.Resources)
+	require.Nil(t, got.Resources[0].Fields)
+	require.Equal(t, 3, got.Unchanged)
+
+	small := &commitDiff{Commit: "def456", PreviousCommit: "abc123",
+		Resources: []resourceDiff{{Kind: "ConfigMap", Name: "cm", Action: diffCreated}}}
+	data, err = small.encode()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"commit":"def456","previousCommit":"abc123","resources":[{"kind":"ConfigMap","name":"cm","action":"Created"}],"unchanged":0}`, string(data))
+}
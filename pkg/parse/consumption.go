@@ -0,0 +1,71 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metrics"
+)
+
+// consumptionReportPeriod is how long the reconciler waits between reports of
+// its resource consumption.
+const consumptionReportPeriod = 5 * time.Minute
+
+// updateResourceConsumption computes the approximate consumption of cluster
+// resources attributable to the RootSync or RepoSync: the number and the size
+// of the declared objects, and the rate of API server calls made by the
+// reconciler. The consumption is recorded in the reconciler state, to be
+// reported in the sync status, and in the DeclaredResourcesBytes and
+// APICallRate metrics.
+//
+// The API call rate is averaged since the previous report, so it is only
+// reported from the second report on.
+func updateResourceConsumption(ctx context.Context, p Parser, state *reconcilerState) {
+	now := time.Now()
+	if now.Sub(state.lastConsumptionReport) < consumptionReportPeriod {
+		return
+	}
+	objs, _ := p.options().resources.DeclaredUnstructureds()
+	consumption := &v1beta1.ResourceConsumption{
+		ObjectCount: len(objs),
+		LastUpdate:  metav1.NewTime(now),
+	}
+	for _, obj := range objs {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			klog.Warningf("Failed to encode %s to estimate its size: %v", core.IDOf(obj), err)
+			continue
+		}
+		consumption.EstimatedBytes += int64(len(data))
+	}
+
+	apiCalls := metrics.APICallCount()
+	if !state.lastConsumptionReport.IsZero() {
+		elapsed := now.Sub(state.lastConsumptionReport)
+		consumption.APICallsPerMinute = int64(math.Round(float64(apiCalls-state.lastAPICallCount) / elapsed.Minutes()))
+	}
+	state.lastConsumptionReport = now
+	state.lastAPICallCount = apiCalls
+	state.resourceConsumption = consumption
+	metrics.RecordResourceConsumption(ctx, consumption.EstimatedBytes, consumption.APICallsPerMinute)
+}
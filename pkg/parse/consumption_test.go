@@ -0,0 +1,71 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestUpdateResourceConsumption(t *testing.T) {
+	p := newParser(t, FileSource{}).(*root)
+	objs := []client.Object{
+		fake.NamespaceObject("foo"),
+		fake.RoleObject(core.Name("admin"), core.Namespace("foo")),
+	}
+	_, err := p.resources.Update(context.Background(), objs, "abc123")
+	require.NoError(t, err)
+
+	// The first report has no API call rate, since there is no previous report.
+	state := &reconcilerState{}
+	updateResourceConsumption(context.Background(), p, state)
+	first := state.resourceConsumption
+	require.NotNil(t, first)
+	require.Equal(t, 2, first.ObjectCount)
+	require.Greater(t, first.EstimatedBytes, int64(0))
+	require.Equal(t, int64(0), first.APICallsPerMinute)
+
+	// The consumption is not recomputed until the report period elapses.
+	updateResourceConsumption(context.Background(), p, state)
+	require.Same(t, first, state.resourceConsumption)
+
+	rt := metrics.NewAPICallCounter(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+	for i := 0; i < 20; i++ {
+		req, err := http.NewRequest(http.MethodGet, "https://kubernetes.default.svc/api", nil)
+		require.NoError(t, err)
+		_, err = rt.RoundTrip(req)
+		require.NoError(t, err)
+	}
+	state.lastConsumptionReport = time.Now().Add(-10 * time.Minute)
+	updateResourceConsumption(context.Background(), p, state)
+	require.Equal(t, int64(2), state.resourceConsumption.APICallsPerMinute)
+	require.Equal(t, first.EstimatedBytes, state.resourceConsumption.EstimatedBytes)
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxDebugSnapshotErrors is the maximum number of errors in a debug snapshot,
+// to keep the ReconcilerDebug object small.
+const maxDebugSnapshotErrors = 50
+
+// updateDebugSnapshot writes a snapshot of the reconciler state to the
+// ReconcilerDebug object of the reconciler, if the
+// `configsync.gke.io/debug-snapshot-requested-at` annotation of the RootSync
+// or RepoSync changed since the last snapshot.
+func updateDebugSnapshot(ctx context.Context, p Parser, state *reconcilerState) error {
+	opts := p.options()
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	requestedAt := core.GetAnnotation(rs, metadata.DebugSnapshotRequestedAtKey)
+	if requestedAt == "" || requestedAt == state.lastDebugSnapshot {
+		return nil
+	}
+
+	debug := &v1beta1.ReconcilerDebug{}
+	debug.Name = opts.reconcilerName
+	debug.Namespace = opts.syncNamespace()
+	err = opts.k8sClient().Get(ctx, client.ObjectKeyFromObject(debug), debug)
+	if apierrors.IsNotFound(err) {
+		// The snapshot is deleted with the RootSync or RepoSync.
+		debug.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(rs, rs.GetObjectKind().GroupVersionKind()),
+		}
+		err = opts.k8sClient().Create(ctx, debug)
+	}
+	if err != nil {
+		return err
+	}
+	debug.Status = debugSnapshot(opts, state, requestedAt)
+	if err := opts.k8sClient().Status().Update(ctx, debug); err != nil {
+		return err
+	}
+	klog.Infof("Saved the debug snapshot requested at %s to ReconcilerDebug %s/%s",
+		requestedAt, debug.Namespace, debug.Name)
+	state.lastDebugSnapshot = requestedAt
+	return nil
+}
+
+// debugSnapshot returns the sanitized state of the reconciler: it includes the
+// cached commit, the retry and cache state and the error backlog, but not the
+// declared objects.
+func debugSnapshot(opts *opts, state *reconcilerState, requestedAt string) v1beta1.ReconcilerDebugStatus {
+	snap := v1beta1.ReconcilerDebugStatus{
+		RequestedAt:     requestedAt,
+		SnapshotTime:    metav1.Now(),
+		Commit:          state.cache.source.commit,
+		SyncDir:         state.cache.source.syncDir.OSPath(),
+		LastApplied:     state.lastApplied,
+		SourceCommit:    state.sourceStatus.commit,
+		RenderingCommit: state.renderingStatus.commit,
+		SyncCommit:      state.syncStatus.commit,
+		Retry: v1beta1.ReconcilerDebugRetry{
+			NeedToRetry:        state.cache.needToRetry,
+			Retries:            state.cache.reconciliationWithSameErrs,
+			ManagementConflict: opts.managementConflict(),
+			NeedToUpdateWatch:  opts.needToUpdateWatch(),
+		},
+		Cache: v1beta1.ReconcilerDebugCache{
+			HasParserResult:          state.cache.hasParserResult,
+			ObjectsToApply:           len(state.cache.objsToApply),
+			ObjectsSkipped:           len(state.cache.objsSkipped),
+			DeclaredResourcesUpdated: state.cache.declaredResourcesUpdated,
+			Applied:                  state.cache.applied,
+			WatchesUpdated:           state.cache.watchesUpdated,
+		},
+	}
+	if !state.cache.nextRetryTime.IsZero() {
+		nextRetryTime := metav1.NewTime(state.cache.nextRetryTime)
+		snap.Retry.NextRetryTime = &nextRetryTime
+	}
+	if len(state.lastRuns) > 0 {
+		snap.LastRuns = make(map[string]metav1.Time, len(state.lastRuns))
+		for trigger, t := range state.lastRuns {
+			snap.LastRuns[trigger] = metav1.NewTime(t)
+		}
+	}
+
+	// The resources of the errors are left out, since the error messages are
+	// enough to tell which step is failing.
+	var errs []v1beta1.ConfigSyncError
+	for _, cse := range status.ToCSE(state.cache.errs) {
+		errs = append(errs, v1beta1.ConfigSyncError{Code: cse.Code, ErrorMessage: cse.ErrorMessage})
+	}
+	if len(errs) > 0 {
+		snap.ErrorSummary = &v1beta1.ErrorSummary{TotalCount: len(errs)}
+		if len(errs) > maxDebugSnapshotErrors {
+			errs = errs[:maxDebugSnapshotErrors]
+			snap.ErrorSummary.Truncated = true
+		}
+		snap.ErrorSummary.ErrorCountAfterTruncation = len(errs)
+		snap.Errors = errs
+	}
+	return snap
+}
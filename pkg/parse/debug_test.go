@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestUpdateDebugSnapshot(t *testing.T) {
+	ctx := context.Background()
+	rs := fake.RootSyncObjectV1Beta1(rootSyncName)
+	p := newParser(t, FileSource{})
+	p.options().client = syncerFake.NewClient(t, core.Scheme, rs)
+	k8sClient := p.options().k8sClient()
+	debugKey := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: rootReconcilerName}
+
+	state := &reconcilerState{
+		lastRuns: map[string]time.Time{triggerReimport: time.Now()},
+		cache: cacheForCommit{
+			source:                     sourceState{commit: "abc123", syncDir: cmpath.Absolute("/repo/rev/abc123")},
+			hasParserResult:            true,
+			needToRetry:                true,
+			reconciliationWithSameErrs: 3,
+			nextRetryTime:              time.Now().Add(time.Minute),
+			errs:                       status.InternalError("apply failed"),
+		},
+	}
+
+	// No snapshot is taken until it is requested.
+	require.NoError(t, updateDebugSnapshot(ctx, p, state))
+	require.True(t, apierrors.IsNotFound(k8sClient.Get(ctx, debugKey, &v1beta1.ReconcilerDebug{})))
+
+	requestSnapshot := func(requestedAt string) {
+		t.Helper()
+		rs := &v1beta1.RootSync{}
+		require.NoError(t, k8sClient.Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+		core.SetAnnotation(rs, metadata.DebugSnapshotRequestedAtKey, requestedAt)
+		require.NoError(t, k8sClient.Update(ctx, rs))
+	}
+
+	requestSnapshot("2023-01-01T00:00:00Z")
+	require.NoError(t, updateDebugSnapshot(ctx, p, state))
+	debug := &v1beta1.ReconcilerDebug{}
+	require.NoError(t, k8sClient.Get(ctx, debugKey, debug))
+	require.Equal(t, "2023-01-01T00:00:00Z", debug.Status.RequestedAt)
+	require.Equal(t, "abc123", debug.Status.Commit)
+	require.Equal(t, "/repo/rev/abc123", debug.Status.SyncDir)
+	require.True(t, debug.Status.Retry.NeedToRetry)
+	require.Equal(t, 3, debug.Status.Retry.Retries)
+	require.NotNil(t, debug.Status.Retry.NextRetryTime)
+	require.True(t, debug.Status.Cache.HasParserResult)
+	require.Contains(t, debug.Status.LastRuns, triggerReimport)
+	require.Len(t, debug.Status.Errors, 1)
+	require.Empty(t, debug.Status.Errors[0].Resources)
+	require.Len(t, debug.OwnerReferences, 1)
+	require.Equal(t, configsync.RootSyncKind, debug.OwnerReferences[0].Kind)
+
+	// The snapshot is not taken again for the same request.
+	state.cache.source.commit = "def456"
+	require.NoError(t, updateDebugSnapshot(ctx, p, state))
+	require.NoError(t, k8sClient.Get(ctx, debugKey, debug))
+	require.Equal(t, "abc123", debug.Status.Commit)
+
+	requestSnapshot("2023-01-02T00:00:00Z")
+	require.NoError(t, updateDebugSnapshot(ctx, p, state))
+	require.NoError(t, k8sClient.Get(ctx, debugKey, debug))
+	require.Equal(t, "2023-01-02T00:00:00Z", debug.Status.RequestedAt)
+	require.Equal(t, "def456", debug.Status.Commit)
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/applier"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// dryRunReason is the reason of the Syncing condition of a RootSync or
+// RepoSync in dry-run mode.
+const dryRunReason = "DryRun"
+
+// maxDryRunObjects is the largest number of objects listed in the dry-run
+// status, to keep the RootSync or RepoSync object small.
+const maxDryRunObjects = 200
+
+// previewCommit parses the commit, previews it with server-side dry-run
+// applies, and reports the objects it would add, change or prune in the
+// dry-run status of the RootSync or RepoSync. The parse errors are reported
+// in the source status, like for the commits which are applied.
+func previewCommit(ctx context.Context, p Parser, state *reconcilerState) status.MultiError {
+	commit := state.cache.source.commit
+	objs, sourceErrs := p.parseSource(ctx, state.cache.source)
+	newSourceStatus := sourceStatus{
+		commit:     commit,
+		errs:       sourceErrs,
+		lastUpdate: metav1.Now(),
+	}
+	if state.needToSetSourceStatus(newSourceStatus) {
+		if err := p.setSourceStatus(ctx, newSourceStatus); err != nil {
+			return status.Append(sourceErrs, err)
+		}
+		state.sourceStatus = newSourceStatus
+		state.syncingConditionLastUpdate = newSourceStatus.lastUpdate
+	}
+	if status.HasBlockingErrors(sourceErrs) {
+		return sourceErrs
+	}
+
+	result, errs := p.options().applier.DryRun(ctx, filesystem.AsCoreObjects(objs))
+	if result == nil {
+		result = &applier.DryRunResult{}
+	}
+	if err := setDryRunStatus(ctx, p, newDryRunStatus(commit, result, errs)); err != nil {
+		return status.Append(errs, err)
+	}
+	return errs
+}
+
+// newDryRunStatus returns the dry-run status of the result of the preview of
+// the commit.
+func newDryRunStatus(commit string, result *applier.DryRunResult, errs status.MultiError) *v1beta1.DryRunStatus {
+	dryRun := &v1beta1.DryRunStatus{
+		Commit:     commit,
+		LastUpdate: metav1.Now(),
+		Unchanged:  result.Unchanged,
+		Errors:     status.ToCSE(errs),
+	}
+	for _, diff := range result.Diffs {
+		switch diff.Action {
+		case v1beta1.DiffActionAdd:
+			dryRun.Adds++
+		case v1beta1.DiffActionChange:
+			dryRun.Changes++
+		case v1beta1.DiffActionPrune:
+			dryRun.Prunes++
+		}
+		if len(dryRun.Objects) == maxDryRunObjects {
+			dryRun.Truncated = true
+			continue
+		}
+		dryRun.Objects = append(dryRun.Objects, v1beta1.ObjectDiff{
+			Action: diff.Action,
+			Resource: v1beta1.ResourceRef{
+				SourcePath: diff.SourcePath,
+				Name:       diff.Name,
+				Namespace:  diff.Namespace,
+				GVK: metav1.GroupVersionKind{
+					Group:   diff.GVK.Group,
+					Version: diff.GVK.Version,
+					Kind:    diff.GVK.Kind,
+				},
+			},
+			Target: diff.Target,
+		})
+	}
+	return dryRun
+}
+
+// setDryRunStatus sets the dry-run status of the RootSync or RepoSync, and
+// its Syncing condition to False with the DryRun reason, since the previewed
+// commit is not synced.
+func setDryRunStatus(ctx context.Context, p Parser, dryRun *v1beta1.DryRunStatus) status.Error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to report the dry-run")
+	}
+	message := fmt.Sprintf("Dry-run of commit %s: %d objects to add, %d to change, %d to prune",
+		dryRun.Commit, dryRun.Adds, dryRun.Changes, dryRun.Prunes)
+	if len(dryRun.Errors) > 0 {
+		message += fmt.Sprintf(", %d errors", len(dryRun.Errors))
+	}
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		rs.Status.DryRun = dryRun
+		rootsync.SetSyncing(rs, false, dryRunReason, message, dryRun.Commit, nil, nil, dryRun.LastUpdate)
+	case *v1beta1.RepoSync:
+		rs.Status.DryRun = dryRun
+		reposync.SetSyncing(rs, false, dryRunReason, message, dryRun.Commit, nil, nil, dryRun.LastUpdate)
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to report the dry-run")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/applier"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/rootsync"
+)
+
+func TestNewDryRunStatus(t *testing.T) {
+	result := &applier.DryRunResult{Unchanged: 4}
+	for i := 0; i < maxDryRunObjects; i++ {
+		result.Diffs = append(result.Diffs, applier.ObjectDiff{
+			Action: v1beta1.DiffActionAdd, GVK: kinds.ConfigMap(), Namespace: "bookstore", Name: fmt.Sprintf("cm-%d", i),
+		})
+	}
+	result.Diffs = append(result.Diffs,
+		applier.ObjectDiff{Action: v1beta1.DiffActionChange, GVK: kinds.Role(), Namespace: "bookstore", Name: "reader"},
+		applier.ObjectDiff{Action: v1beta1.DiffActionPrune, GVK: kinds.Role(), Namespace: "bookstore", Name: "writer"},
+	)
+
+	dryRun := newDryRunStatus("abc123", result, nil)
+	assert.Equal(t, "abc123", dryRun.Commit)
+	assert.Equal(t, maxDryRunObjects, dryRun.Adds)
+	assert.Equal(t, 1, dryRun.Changes)
+	assert.Equal(t, 1, dryRun.Prunes)
+	assert.Equal(t, 4, dryRun.Unchanged)
+	assert.True(t, dryRun.Truncated)
+	require.Len(t, dryRun.Objects, maxDryRunObjects)
+	assert.Equal(t, v1beta1.ObjectDiff{
+		Action: v1beta1.DiffActionAdd,
+		Resource: v1beta1.ResourceRef{
+			Name:      "cm-0",
+			Namespace: "bookstore",
+			GVK:       metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		},
+	}, dryRun.Objects[0])
+}
+
+func TestPreviewCommit(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	p.options().DryRun = true
+	p.options().applier = &fakeApplier{dryRun: &applier.DryRunResult{
+		Diffs: []applier.ObjectDiff{
+			{Action: v1beta1.DiffActionPrune, GVK: kinds.Role(), Namespace: "bookstore", Name: "writer"},
+		},
+		Unchanged: 2,
+	}}
+	state := &reconcilerState{}
+	state.cache.source = sourceState{commit: "abc123", syncDir: cmpath.Absolute("/repo/rev/abc123")}
+
+	require.Nil(t, previewCommit(ctx, p, state))
+
+	rs := &v1beta1.RootSync{}
+	require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+	require.NotNil(t, rs.Status.DryRun)
+	assert.Equal(t, "abc123", rs.Status.DryRun.Commit)
+	assert.Equal(t, 1, rs.Status.DryRun.Prunes)
+	assert.Equal(t, 2, rs.Status.DryRun.Unchanged)
+	cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, dryRunReason, cond.Reason)
+	assert.Equal(t, "Dry-run of commit abc123: 0 objects to add, 0 to change, 1 to prune", cond.Message)
+	// Nothing is applied.
+	assert.Nil(t, p.options().applier.(*fakeApplier).got)
+}
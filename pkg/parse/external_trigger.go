@@ -0,0 +1,69 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+// externalTriggerTimeout is how long the reconciler keeps reading the source
+// after an external trigger, waiting for git-sync to fetch a new commit.
+const externalTriggerTimeout = 2 * time.Minute
+
+// pendingTrigger is an external trigger whose run found no new commit.
+type pendingTrigger struct {
+	// syncDir is the source directory read when the trigger was received.
+	syncDir cmpath.Absolute
+	// deadline is when the reconciler stops waiting for a new commit.
+	deadline time.Time
+}
+
+// waitForNewCommit is called after the run of an external trigger, e.g. a
+// push. git-sync fetches the pushed commit asynchronously, so the run usually
+// reads the previous commit, and is skipped. In that case, the trigger stays
+// pending, and the retries read the source again until a new commit is found,
+// or externalTriggerTimeout expires.
+func waitForNewCommit(state *reconcilerState, syncDir cmpath.Absolute, now time.Time) {
+	if state.cache.source.syncDir != syncDir {
+		state.pendingTrigger = nil
+		return
+	}
+	state.pendingTrigger = &pendingTrigger{
+		syncDir:  syncDir,
+		deadline: now.Add(externalTriggerTimeout),
+	}
+}
+
+// externalTriggerPending returns true if the reconciler is still waiting for
+// a new commit after an external trigger.
+func externalTriggerPending(state *reconcilerState, now time.Time) bool {
+	pending := state.pendingTrigger
+	if pending == nil {
+		return false
+	}
+	if state.cache.source.syncDir != pending.syncDir {
+		state.pendingTrigger = nil
+		return false
+	}
+	if now.After(pending.deadline) {
+		klog.Infof("No new commit was fetched within %v of the external trigger", externalTriggerTimeout)
+		state.pendingTrigger = nil
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestWaitForNewCommit(t *testing.T) {
+	oldDir := cmpath.Absolute("/repo/source/abc123")
+	newDir := cmpath.Absolute("/repo/source/def456")
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		runSyncDir  cmpath.Absolute
+		syncDir     cmpath.Absolute
+		checkAt     time.Time
+		wantPending bool
+	}{
+		{
+			name:       "the run read a new commit",
+			runSyncDir: newDir,
+			syncDir:    newDir,
+			checkAt:    now,
+		},
+		{
+			name:        "no new commit fetched yet",
+			runSyncDir:  oldDir,
+			syncDir:     oldDir,
+			checkAt:     now.Add(time.Second),
+			wantPending: true,
+		},
+		{
+			name:       "a new commit read by another run",
+			runSyncDir: oldDir,
+			syncDir:    newDir,
+			checkAt:    now.Add(time.Second),
+		},
+		{
+			name:       "no new commit fetched before the timeout",
+			runSyncDir: oldDir,
+			syncDir:    oldDir,
+			checkAt:    now.Add(externalTriggerTimeout + time.Second),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &reconcilerState{}
+			state.cache.source.syncDir = tc.runSyncDir
+			waitForNewCommit(state, oldDir, now)
+			state.cache.source.syncDir = tc.syncDir
+			assert.Equal(t, tc.wantPending, externalTriggerPending(state, tc.checkAt))
+			if !tc.wantPending {
+				assert.Nil(t, state.pendingTrigger)
+			}
+		})
+	}
+}
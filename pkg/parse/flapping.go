@@ -0,0 +1,145 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const (
+	// flapHalfLife is how long it takes for the weight of a transition
+	// between failure and success to halve.
+	flapHalfLife = 10 * time.Minute
+
+	// flapEnterScore is the score above which the sync is flapping.
+	flapEnterScore = 3.0
+
+	// flapExitScore is the score below which a flapping sync is stable again.
+	// It is lower than flapEnterScore, so that a sync close to the threshold
+	// does not flap in and out of the Flapping condition itself.
+	flapExitScore = 1.0
+)
+
+// flapDetector detects syncs which alternate between failure and success.
+// Each transition adds one to a score which decays exponentially with
+// flapHalfLife, and the sync is flapping between the score going above
+// flapEnterScore and below flapExitScore.
+type flapDetector struct {
+	// score is the smoothed number of recent transitions, as of lastOutcome.
+	score float64
+	// lastOutcome is when the last outcome was observed. Zero if none was.
+	lastOutcome time.Time
+	// lastFailed is whether the last outcome was a failure.
+	lastFailed bool
+	// flapping is whether the sync is flapping, as last computed by observe.
+	flapping bool
+	// reported is whether the Flapping condition is set on the RSync.
+	reported bool
+}
+
+// observe records the outcome of a sync at the given time, and returns
+// whether the sync is flapping.
+func (d *flapDetector) observe(failed bool, now time.Time) bool {
+	if !d.lastOutcome.IsZero() {
+		elapsed := now.Sub(d.lastOutcome)
+		if elapsed > 0 {
+			d.score *= math.Exp2(-float64(elapsed) / float64(flapHalfLife))
+		}
+		if failed != d.lastFailed {
+			d.score++
+		}
+	}
+	d.lastOutcome = now
+	d.lastFailed = failed
+
+	if d.flapping {
+		d.flapping = d.score >= flapExitScore
+	} else {
+		d.flapping = d.score >= flapEnterScore
+	}
+	return d.flapping
+}
+
+// updateFlapping records the outcome of a run, and sets the Flapping
+// condition of the RootSync or RepoSync while the sync alternates between
+// failure and success, so that alerts can ignore the transitions of the
+// Syncing condition while the condition is set.
+// Runs which neither succeeded nor failed, e.g. skipped runs, are ignored.
+func updateFlapping(ctx context.Context, p Parser, state *reconcilerState, outcome *runOutcome) {
+	if outcome.result != runSucceeded && outcome.result != runFailed {
+		return
+	}
+	flapping := state.flapping.observe(outcome.result == runFailed, time.Now())
+	if flapping == state.flapping.reported {
+		return
+	}
+	transitions := int(math.Round(state.flapping.score))
+	if flapping {
+		klog.Warningf("The sync alternated between failure and success about %d times recently", transitions)
+	} else {
+		klog.Infof("The sync is stable again after flapping")
+	}
+	if err := setFlappingCondition(ctx, p, flapping, state.cache.source.commit, transitions); err != nil {
+		// Try again after the next run.
+		klog.Warningf("Failed to update the Flapping condition: %v", err)
+		return
+	}
+	state.flapping.reported = flapping
+	metrics.RecordSyncFlapping(ctx, flapping)
+}
+
+// setFlappingCondition sets or removes the Flapping condition of the RootSync
+// or RepoSync.
+func setFlappingCondition(ctx context.Context, p Parser, flapping bool, commit string, transitions int) error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to update the Flapping condition")
+	}
+	var updated bool
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		if flapping {
+			updated = rootsync.SetFlapping(rs, commit, transitions)
+		} else {
+			updated = rootsync.RemoveCondition(rs, v1beta1.RootSyncFlapping)
+		}
+	case *v1beta1.RepoSync:
+		if flapping {
+			updated = reposync.SetFlapping(rs, commit, transitions)
+		} else {
+			updated = reposync.RemoveCondition(rs, v1beta1.RepoSyncFlapping)
+		}
+	}
+	if !updated {
+		return nil
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to update the Flapping condition")
+	}
+	return nil
+}
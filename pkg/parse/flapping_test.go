@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/rootsync"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestFlapDetector(t *testing.T) {
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	d := &flapDetector{}
+
+	// Repeated failures are not transitions.
+	for i := 0; i < 5; i++ {
+		require.False(t, d.observe(true, start.Add(time.Duration(i)*time.Second)))
+	}
+
+	// Alternating outcomes within a few minutes start flapping.
+	now := start.Add(time.Minute)
+	require.False(t, d.observe(false, now))
+	require.False(t, d.observe(true, now.Add(time.Minute)))
+	require.False(t, d.observe(false, now.Add(2*time.Minute)))
+	require.True(t, d.observe(true, now.Add(3*time.Minute)))
+
+	// Stable outcomes keep flapping until the score decays below the exit
+	// score, rather than the enter score.
+	now = now.Add(3 * time.Minute)
+	require.True(t, d.observe(false, now.Add(flapHalfLife)))
+	require.True(t, d.observe(false, now.Add(2*flapHalfLife)))
+	require.False(t, d.observe(false, now.Add(3*flapHalfLife)))
+
+	// A single transition afterwards does not flap again.
+	require.False(t, d.observe(true, now.Add(4*flapHalfLife)))
+}
+
+func TestUpdateFlapping(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	p.options().client = syncerFake.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
+	state := &reconcilerState{}
+	state.cache.source.commit = "abc123"
+
+	getCondition := func() *v1beta1.RootSyncCondition {
+		t.Helper()
+		rs := &v1beta1.RootSync{}
+		require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+		return rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncFlapping)
+	}
+
+	// Skipped runs are ignored.
+	updateFlapping(ctx, p, state, &runOutcome{result: runSkipped})
+	require.True(t, state.flapping.lastOutcome.IsZero())
+
+	for _, result := range []string{runFailed, runSucceeded, runFailed, runSucceeded} {
+		updateFlapping(ctx, p, state, &runOutcome{result: result})
+		require.False(t, state.flapping.reported)
+		require.Nil(t, getCondition())
+	}
+
+	updateFlapping(ctx, p, state, &runOutcome{result: runFailed})
+	require.True(t, state.flapping.reported)
+	cond := getCondition()
+	require.NotNil(t, cond)
+	require.Equal(t, "abc123", cond.Commit)
+	require.Equal(t, "SyncFlapping", cond.Reason)
+
+	// The condition is removed once the score decayed.
+	state.flapping.lastOutcome = state.flapping.lastOutcome.Add(-3 * flapHalfLife)
+	updateFlapping(ctx, p, state, &runOutcome{result: runFailed})
+	require.False(t, state.flapping.reported)
+	require.Nil(t, getCondition())
+}
@@ -0,0 +1,278 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// freeze returns the objects to declare, with the changes to the objects in
+// frozen Namespaces reverted to their previously declared versions.
+//
+// Objects in frozen Namespaces are neither created, updated, nor pruned. Each
+// skipped change is returned as a NamespaceFrozenError, which keeps the commit
+// from being marked as synced until the freeze is lifted.
+//
+// The Namespace objects themselves are never frozen, so that the freeze
+// annotation can be removed by a new commit.
+//
+// The previous declarations are kept in memory, so they are lost when the
+// reconciler restarts. Until the next apply, the objects in the inventory of
+// frozen Namespaces which are missing from memory are read from the cluster
+// instead, so that they are not pruned.
+func (u *updater) freeze(ctx context.Context, objs []client.Object) ([]client.Object, status.MultiError, status.Error) {
+	if u.namespaceReader == nil {
+		return objs, nil, nil
+	}
+
+	previousObjs, _ := u.resources.DeclaredUnstructureds()
+	previous := make(map[core.ID]*unstructured.Unstructured, len(previousObjs))
+	namespaces := make(map[string]bool)
+	for _, obj := range previousObjs {
+		previous[core.IDOf(obj)] = obj
+		if ns := obj.GetNamespace(); ns != "" {
+			namespaces[ns] = true
+		}
+	}
+	for _, obj := range objs {
+		if ns := obj.GetNamespace(); ns != "" {
+			namespaces[ns] = true
+		}
+	}
+	inventory, err := u.inventoryIDs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id := range inventory {
+		if id.Namespace != "" {
+			namespaces[id.Namespace] = true
+		}
+	}
+
+	frozen, err := u.frozenNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(frozen) == 0 {
+		return objs, nil, nil
+	}
+	live, err := u.liveObjects(ctx, inventory, frozen, previous)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, obj := range live {
+		previous[id] = obj
+	}
+
+	var errs status.MultiError
+	var result []client.Object
+	declared := make(map[core.ID]bool, len(objs))
+	for _, obj := range objs {
+		id := core.IDOf(obj)
+		declared[id] = true
+		ns := obj.GetNamespace()
+		if !frozen[ns] {
+			result = append(result, obj)
+			continue
+		}
+		prev, found := previous[id]
+		if !found {
+			// Skip creating the object.
+			errs = status.Append(errs, status.NamespaceFrozenError(ns, obj))
+			continue
+		}
+		var changed bool
+		var convErr status.Error
+		if live[id] != nil {
+			changed, convErr = liveObjectChanged(prev, obj)
+		} else {
+			changed, convErr = declarationChanged(prev, obj, u.normalizer)
+		}
+		if convErr != nil {
+			return nil, nil, convErr
+		}
+		if !changed {
+			result = append(result, obj)
+			continue
+		}
+		// Skip updating the object.
+		errs = status.Append(errs, status.NamespaceFrozenError(ns, obj))
+		result = append(result, prev)
+	}
+	for id, prev := range previous {
+		if declared[id] || !frozen[prev.GetNamespace()] {
+			continue
+		}
+		// Skip pruning the object.
+		errs = status.Append(errs, status.NamespaceFrozenError(prev.GetNamespace(), prev))
+		result = append(result, prev)
+	}
+	if errs != nil {
+		klog.Warningf("Skipped syncing %d change(s) to frozen namespace(s): %v", len(errs.Errors()), frozenNames(frozen))
+	}
+	return result, errs, nil
+}
+
+// frozenNamespaces returns the set of the given Namespaces which have the
+// freeze annotation on the cluster.
+//
+// Namespaces that do not exist are not considered frozen. Failing to read a
+// Namespace, including for lack of permissions, is an error, so that changes
+// are never synced into a frozen Namespace by mistake.
+func (u *updater) frozenNamespaces(ctx context.Context, namespaces map[string]bool) (map[string]bool, status.Error) {
+	frozen := make(map[string]bool)
+	for ns := range namespaces {
+		nsObj := &corev1.Namespace{}
+		if err := u.namespaceReader.Get(ctx, client.ObjectKey{Name: ns}, nsObj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, status.APIServerErrorf(err, "failed to get Namespace %q", ns)
+		}
+		if core.GetAnnotation(nsObj, metadata.NamespaceFreezeAnnotationKey) == metadata.NamespaceFreezeEnabled {
+			frozen[ns] = true
+		}
+	}
+	return frozen, nil
+}
+
+// inventoryIDs returns the IDs of the objects in the ResourceGroup inventory of
+// the reconciler. It returns no IDs if nothing has been applied yet.
+func (u *updater) inventoryIDs(ctx context.Context) (map[core.ID]bool, status.Error) {
+	if u.inventory.Name == "" {
+		return nil, nil
+	}
+	ids, err := inventoryIDs(ctx, u.namespaceReader, u.inventory)
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, status.APIServerErrorf(err, "failed to get the inventory %s", u.inventory)
+	}
+	return ids, nil
+}
+
+// liveObjects reads the objects in the inventory which are in frozen
+// Namespaces, but have no previous declaration. The objects are returned
+// without their server-side and Config Sync metadata, to be declared again as
+// they are. Objects which no longer exist are skipped.
+func (u *updater) liveObjects(ctx context.Context, inventory map[core.ID]bool, frozen map[string]bool, previous map[core.ID]*unstructured.Unstructured) (map[core.ID]*unstructured.Unstructured, status.Error) {
+	live := make(map[core.ID]*unstructured.Unstructured)
+	for id := range inventory {
+		if !frozen[id.Namespace] || previous[id] != nil {
+			continue
+		}
+		mapping, err := u.namespaceReader.RESTMapper().RESTMapping(id.GroupKind)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, status.APIServerErrorf(err, "failed to map %s", id.GroupKind)
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(mapping.GroupVersionKind)
+		if err := u.namespaceReader.Get(ctx, id.ObjectKey, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, status.APIServerErrorf(err, "failed to get %s", id)
+		}
+		sanitized, sErr := reconcile.AsUnstructuredSanitized(obj)
+		if sErr != nil {
+			return nil, sErr
+		}
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "selfLink"} {
+			unstructured.RemoveNestedField(sanitized.Object, "metadata", field)
+		}
+		metadata.RemoveConfigSyncMetadata(sanitized)
+		live[id] = sanitized
+	}
+	return live, nil
+}
+
+// liveObjectChanged returns true if applying the new declaration of an object
+// would change the object read from the cluster, i.e. if any field of the
+// declaration, except for the commit it was declared in, differs from the
+// object.
+func liveObjectChanged(live *unstructured.Unstructured, obj client.Object) (bool, status.Error) {
+	next, err := reconcile.AsUnstructuredSanitized(obj)
+	if err != nil {
+		return false, err
+	}
+	core.RemoveAnnotations(next, metadata.SyncTokenAnnotationKey)
+	return !isSubset(next.Object, live.Object), nil
+}
+
+// isSubset returns true if every field of want is set to the same value in
+// got. Maps are compared field by field, other values as a whole.
+func isSubset(want, got interface{}) bool {
+	wantMap, ok := want.(map[string]interface{})
+	if !ok {
+		return equality.Semantic.DeepEqual(want, got)
+	}
+	gotMap, ok := got.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for k, v := range wantMap {
+		if !isSubset(v, gotMap[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// declarationChanged returns true if the new declaration of an object differs
+// from its previous declaration, ignoring the commit it was declared in.
+// If the normalizer is not nil, the declarations are normalized first, to
+// ignore pure formatting and defaulting changes.
+func declarationChanged(prev *unstructured.Unstructured, obj client.Object, normalizer *declared.ValueConverter) (bool, status.Error) {
+	next, err := reconcile.AsUnstructuredSanitized(obj)
+	if err != nil {
+		return false, err
+	}
+	if normalizer != nil {
+		prev = normalizer.Normalize(prev)
+		next = normalizer.Normalize(next)
+	} else {
+		prev = prev.DeepCopy()
+	}
+	core.RemoveAnnotations(prev, metadata.SyncTokenAnnotationKey)
+	core.RemoveAnnotations(next, metadata.SyncTokenAnnotationKey)
+	return !equality.Semantic.DeepEqual(prev.Object, next.Object), nil
+}
+
+func frozenNames(frozen map[string]bool) []string {
+	var names []string
+	for ns := range frozen {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	return names
+}
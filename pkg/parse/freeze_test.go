@@ -0,0 +1,159 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/diff/difftest"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/syncertest"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	resourcegroupv1alpha1 "kpt.dev/resourcegroup/apis/kpt.dev/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestUpdaterFreeze(t *testing.T) {
+	frozenNS := fake.NamespaceObject("frozen", core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled))
+	openNS := fake.NamespaceObject("open")
+
+	unchangedOld := fake.ConfigMapObject(core.Name("unchanged"), core.Namespace("frozen"), core.Annotation(metadata.SyncTokenAnnotationKey, "1"))
+	unchangedNew := fake.ConfigMapObject(core.Name("unchanged"), core.Namespace("frozen"), core.Annotation(metadata.SyncTokenAnnotationKey, "2"))
+	updatedOld := fake.ConfigMapObject(core.Name("updated"), core.Namespace("frozen"), core.Label("version", "1"))
+	updatedNew := fake.ConfigMapObject(core.Name("updated"), core.Namespace("frozen"), core.Label("version", "2"))
+	pruned := fake.ConfigMapObject(core.Name("pruned"), core.Namespace("frozen"))
+	created := fake.ConfigMapObject(core.Name("created"), core.Namespace("frozen"))
+	openOld := fake.ConfigMapObject(core.Name("cm"), core.Namespace("open"), core.Label("version", "1"))
+	openNew := fake.ConfigMapObject(core.Name("cm"), core.Namespace("open"), core.Label("version", "2"))
+	openCreated := fake.ConfigMapObject(core.Name("created"), core.Namespace("open"))
+
+	resources := &declared.Resources{}
+	_, err := resources.Update(context.Background(), []client.Object{frozenNS, openNS, unchangedOld, updatedOld, pruned, openOld}, "1")
+	require.NoError(t, err)
+
+	u := &updater{
+		scope:           declared.RootReconciler,
+		resources:       resources,
+		namespaceReader: syncerFake.NewClient(t, core.Scheme, frozenNS, openNS),
+	}
+	// Unfreezing the Namespace in the source is not frozen.
+	newFrozenNS := fake.NamespaceObject("frozen")
+	objs, skipped, freezeErr := u.freeze(context.Background(), []client.Object{newFrozenNS, openNS, unchangedNew, updatedNew, created, openNew, openCreated})
+	require.NoError(t, freezeErr)
+
+	wantSkipped := status.Append(nil, status.NamespaceFrozenError("frozen", updatedNew))
+	wantSkipped = status.Append(wantSkipped, status.NamespaceFrozenError("frozen", created))
+	wantSkipped = status.Append(wantSkipped, status.NamespaceFrozenError("frozen", pruned))
+	require.Equal(t, wantSkipped.Error(), skipped.Error())
+
+	var gotIDs []string
+	for _, obj := range objs {
+		id := core.IDOf(obj).String()
+		gotIDs = append(gotIDs, id)
+		if obj.GetName() == "updated" {
+			require.Equal(t, "1", obj.GetLabels()["version"], "frozen object should keep its previous declaration")
+		}
+	}
+	sort.Strings(gotIDs)
+	var wantIDs []string
+	for _, obj := range []client.Object{newFrozenNS, openNS, unchangedNew, updatedOld, pruned, openNew, openCreated} {
+		wantIDs = append(wantIDs, core.IDOf(obj).String())
+	}
+	sort.Strings(wantIDs)
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("unexpected declared objects (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdaterFreeze_Restart(t *testing.T) {
+	frozenNS := fake.NamespaceObject("frozen", core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled))
+	managedBy := difftest.ManagedBy(declared.RootReconciler, rootSyncName)
+	unchanged := fake.ConfigMapObject(core.Name("unchanged"), core.Namespace("frozen"), core.Label("version", "1"),
+		syncertest.ManagementEnabled, managedBy)
+	updated := fake.ConfigMapObject(core.Name("updated"), core.Namespace("frozen"), core.Label("version", "1"),
+		syncertest.ManagementEnabled, managedBy)
+	pruned := fake.ConfigMapObject(core.Name("pruned"), core.Namespace("frozen"), core.Label("version", "1"),
+		syncertest.ManagementEnabled, managedBy)
+	deleted := fake.ConfigMapObject(core.Name("deleted"), core.Namespace("frozen"))
+	// The Namespace is no longer declared, so it is only known from the
+	// inventory.
+	inventory := orphanTestInventory(unchanged, updated, pruned, deleted)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, resourcegroupv1alpha1.AddToScheme(scheme))
+
+	// After a restart, nothing has been declared in memory yet.
+	u := &updater{
+		scope:           declared.RootReconciler,
+		resources:       &declared.Resources{},
+		namespaceReader: syncerFake.NewClient(t, scheme, frozenNS, unchanged, updated, pruned, inventory),
+		inventory:       client.ObjectKeyFromObject(inventory),
+	}
+	unchangedNew := fake.ConfigMapObject(core.Name("unchanged"), core.Namespace("frozen"), core.Label("version", "1"))
+	updatedNew := fake.ConfigMapObject(core.Name("updated"), core.Namespace("frozen"), core.Label("version", "2"))
+	objs, skipped, freezeErr := u.freeze(context.Background(), []client.Object{unchangedNew, updatedNew})
+	require.NoError(t, freezeErr)
+
+	wantSkipped := status.Append(nil, status.NamespaceFrozenError("frozen", updatedNew))
+	wantSkipped = status.Append(wantSkipped, status.NamespaceFrozenError("frozen", pruned))
+	require.Equal(t, wantSkipped.Error(), skipped.Error())
+
+	got := make(map[string]client.Object)
+	for _, obj := range objs {
+		got[obj.GetName()] = obj
+	}
+	require.Len(t, got, 3, "the objects in the frozen namespace should be neither updated nor pruned")
+	require.Equal(t, unchangedNew, got["unchanged"])
+	for _, name := range []string{"updated", "pruned"} {
+		obj := got[name]
+		require.NotNil(t, obj, name)
+		require.Equal(t, "1", obj.GetLabels()["version"], "frozen object should keep its version on the cluster")
+		require.Empty(t, obj.GetResourceVersion())
+		require.False(t, metadata.HasConfigSyncMetadata(obj), "frozen object should be declared without the Config Sync metadata")
+	}
+}
+
+// forbiddenReader fails to read any object for lack of permissions.
+type forbiddenReader struct {
+	client.Client
+}
+
+func (forbiddenReader) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return apierrors.NewForbidden(kinds.Namespace().GroupVersion().WithResource("namespaces").GroupResource(), key.Name, nil)
+}
+
+func TestUpdaterFreeze_Forbidden(t *testing.T) {
+	u := &updater{
+		scope:           declared.RootReconciler,
+		resources:       &declared.Resources{},
+		namespaceReader: forbiddenReader{},
+	}
+	objs := []client.Object{fake.ConfigMapObject(core.Name("cm"), core.Namespace("frozen"))}
+	_, _, freezeErr := u.freeze(context.Background(), objs)
+	require.Error(t, freezeErr, "a Namespace that cannot be read must not be treated as not frozen")
+}
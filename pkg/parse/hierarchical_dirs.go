@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate"
+	"kpt.dev/configsync/pkg/validate/final"
+)
+
+// splitHierarchicalDirs splits the files of an unstructured repository into
+// the files in the unstructured format, and the files of each hierarchical
+// directory.
+func splitHierarchicalDirs(syncDir cmpath.Absolute, files []cmpath.Absolute, dirs []cmpath.Relative) ([]cmpath.Absolute, map[cmpath.Relative][]cmpath.Absolute) {
+	var unstructuredFiles []cmpath.Absolute
+	hierarchicalFiles := make(map[cmpath.Relative][]cmpath.Absolute, len(dirs))
+	for _, file := range files {
+		dir, found := hierarchicalDirOf(syncDir, file, dirs)
+		if found {
+			hierarchicalFiles[dir] = append(hierarchicalFiles[dir], file)
+		} else {
+			unstructuredFiles = append(unstructuredFiles, file)
+		}
+	}
+	return unstructuredFiles, hierarchicalFiles
+}
+
+// hierarchicalDirOf returns the hierarchical directory the file is in, if any.
+func hierarchicalDirOf(syncDir, file cmpath.Absolute, dirs []cmpath.Relative) (cmpath.Relative, bool) {
+	for _, dir := range dirs {
+		if strings.HasPrefix(file.SlashPath(), syncDir.Join(dir).SlashPath()+"/") {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// validateWithHierarchicalDirs validates the objects of an unstructured
+// repository, and parses and validates the objects of each of its
+// hierarchical directories as a hierarchical repository, so that teams can
+// convert a repository one directory at a time. The implicit Namespaces are
+// added once the objects of all the directories are known, since the
+// hierarchical directories declare Namespaces with directories.
+func (p *root) validateWithHierarchicalDirs(ctx context.Context, objs []ast.FileObject, syncDir cmpath.Absolute, hierarchicalFiles map[cmpath.Relative][]cmpath.Absolute, options validate.Options) ([]ast.FileObject, status.MultiError) {
+	result, errs := validate.Unstructured(objs, options)
+	if status.HasBlockingErrors(errs) {
+		return nil, errs
+	}
+	for _, dir := range p.HierarchicalDirs {
+		dirObjs, dirErrs := p.parseHierarchicalDir(ctx, syncDir, dir, hierarchicalFiles[dir], options)
+		errs = status.Append(errs, dirErrs)
+		if status.HasBlockingErrors(dirErrs) {
+			return nil, errs
+		}
+		result = append(result, dirObjs...)
+	}
+	// Objects declared in both formats are duplicates.
+	if finalErrs := final.Validation(result); finalErrs != nil {
+		return nil, status.Append(errs, finalErrs)
+	}
+	result, nsErrs := p.addImplicitNamespaces(result)
+	errs = status.Append(errs, nsErrs)
+	if status.HasBlockingErrors(nsErrs) {
+		return nil, errs
+	}
+	return result, errs
+}
+
+// parseHierarchicalDir parses and validates the objects of the hierarchical
+// directory.
+func (p *root) parseHierarchicalDir(ctx context.Context, syncDir cmpath.Absolute, dir cmpath.Relative, files []cmpath.Absolute, options validate.Options) ([]ast.FileObject, status.MultiError) {
+	rootDir := syncDir.Join(dir)
+	filePaths := reader.FilePaths{
+		RootDir:   rootDir,
+		PolicyDir: p.SyncDir.Join(dir),
+		// Ignore the files outside of the allowed directories, as for
+		// hierarchical repositories.
+		Files: filesystem.FilterHierarchyFiles(rootDir, files),
+	}
+	klog.Infof("Parsing files from hierarchical dir: %s", rootDir.OSPath())
+	objs, err := p.parser.Parse(filePaths)
+	if err != nil {
+		return nil, err
+	}
+	if mutationErrs := mutateDeclaredObjects(ctx, p.client, &v1beta1.RootSync{}, rootsync.ObjectKey(p.syncName), objs); mutationErrs != nil {
+		return nil, mutationErrs
+	}
+	options.PolicyDir = filePaths.PolicyDir
+	return validate.Hierarchical(objs, options)
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestSplitHierarchicalDirs(t *testing.T) {
+	syncDir := cmpath.Absolute("/repo/source/abc123/configs")
+	file := func(p string) cmpath.Absolute {
+		return syncDir.Join(cmpath.RelativeSlash(p))
+	}
+	files := []cmpath.Absolute{
+		file("cluster-role.yaml"),
+		file("teams/a/namespaces/a/rb.yaml"),
+		file("teams/a/cluster/cr.yaml"),
+		file("teams/ab/cm.yaml"),
+		file("teams/b/namespaces/b/ns.yaml"),
+	}
+	dirs := []cmpath.Relative{cmpath.RelativeSlash("teams/a"), cmpath.RelativeSlash("teams/b")}
+
+	unstructuredFiles, hierarchicalFiles := splitHierarchicalDirs(syncDir, files, dirs)
+	assert.Equal(t, []cmpath.Absolute{file("cluster-role.yaml"), file("teams/ab/cm.yaml")}, unstructuredFiles)
+	assert.Equal(t, map[cmpath.Relative][]cmpath.Absolute{
+		dirs[0]: {file("teams/a/namespaces/a/rb.yaml"), file("teams/a/cluster/cr.yaml")},
+		dirs[1]: {file("teams/b/namespaces/b/ns.yaml")},
+	}, hierarchicalFiles)
+}
@@ -0,0 +1,334 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// imagePullSecretsNamesKey is the configMap key of the set-image-pull-secrets
+// function, holding the comma-separated names of the Secrets to add.
+const imagePullSecretsNamesKey = "names"
+
+// ignoreMutationTypesKey is the configMap key of the ignore-mutation function,
+// holding the comma-separated values of the `type` field of the objects to
+// mark. All the objects are marked if it is empty.
+const ignoreMutationTypesKey = "types"
+
+// podSpecPaths maps the kinds which embed a PodSpec to the path of the PodSpec.
+var podSpecPaths = map[schema.GroupKind][]string{
+	kinds.Pod().GroupKind():                   {"spec"},
+	kinds.ReplicationController().GroupKind(): {"spec", "template", "spec"},
+	kinds.Deployment().GroupKind():            {"spec", "template", "spec"},
+	kinds.ReplicaSet().GroupKind():            {"spec", "template", "spec"},
+	kinds.StatefulSet().GroupKind():           {"spec", "template", "spec"},
+	kinds.DaemonSet().GroupKind():             {"spec", "template", "spec"},
+	kinds.Job().GroupKind():                   {"spec", "template", "spec"},
+	kinds.CronJob().GroupKind():               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// mutateDeclaredObjects evaluates the mutation functions of all the
+// DeclaredObjectMutators on the declared objects, in place.
+//
+// rsync is the RootSync or RepoSync being reconciled. It is fetched with
+// rsyncKey to check whether it opted out of the mutation.
+//
+// DeclaredObjectMutators are optional, so no error is returned if the
+// DeclaredObjectMutator CRD is not installed.
+func mutateDeclaredObjects(ctx context.Context, c client.Reader, rsync client.Object, rsyncKey client.ObjectKey, objs []ast.FileObject) status.MultiError {
+	if err := c.Get(ctx, rsyncKey, rsync); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return status.APIServerError(err, "failed to get the RSync to check the declared object mutation opt-out")
+		}
+	} else if core.GetAnnotation(rsync, metadata.DeclaredObjectMutationKey) == metadata.DeclaredObjectMutationDisabled {
+		klog.V(3).Infof("Skipping the DeclaredObjectMutators, as they are disabled with the %s annotation", metadata.DeclaredObjectMutationKey)
+		return nil
+	}
+
+	mutatorList := &v1beta1.DeclaredObjectMutatorList{}
+	if err := c.List(ctx, mutatorList); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil
+		}
+		return status.APIServerError(err, "failed to list DeclaredObjectMutators")
+	}
+	mutators := mutatorList.Items
+	sort.Slice(mutators, func(i, j int) bool {
+		return mutators[i].Name < mutators[j].Name
+	})
+
+	var errs status.MultiError
+	for i := range mutators {
+		mutator := &mutators[i]
+		mutator.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(configsync.DeclaredObjectMutatorKind))
+		for _, fn := range mutator.Spec.Functions {
+			if err := validateMutationFunction(fn); err != nil {
+				errs = status.Append(errs, status.DeclaredObjectMutationError(mutator, err))
+				continue
+			}
+			var matching []ast.FileObject
+			for _, obj := range objs {
+				if matchesKinds(obj, fn.Kinds) {
+					matching = append(matching, obj)
+				}
+			}
+			if fn.Image != "" {
+				if err := evalImageFunction(fn, matching); err != nil {
+					errs = status.Append(errs, status.DeclaredObjectMutationError(mutator, err))
+				}
+				continue
+			}
+			for _, obj := range matching {
+				if err := evalMutationFunction(fn, obj.Unstructured); err != nil {
+					errs = status.Append(errs, status.DeclaredObjectMutationError(mutator, err, obj))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// matchesKinds returns true if the object is of one of the kinds, or if no
+// kinds are specified.
+func matchesKinds(obj ast.FileObject, gks []metav1.GroupKind) bool {
+	if len(gks) == 0 {
+		return true
+	}
+	objGK := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	for _, gk := range gks {
+		if gk.Group == objGK.Group && gk.Kind == objGK.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMutationFunction returns an error if the mutation function is not
+// either a known built-in function or an image, or if its configuration is
+// incomplete.
+func validateMutationFunction(fn v1beta1.MutationFunction) error {
+	if fn.Image != "" {
+		if fn.Builtin != "" {
+			return fmt.Errorf("the function sets both the built-in function %q and the image %q", fn.Builtin, fn.Image)
+		}
+		return nil
+	}
+	switch fn.Builtin {
+	case "":
+		return fmt.Errorf("the function sets neither a built-in function nor an image")
+	case v1beta1.SetLabelsFunction, v1beta1.SetAnnotationsFunction, v1beta1.IgnoreMutationFunction:
+		return nil
+	case v1beta1.SetImagePullSecretsFunction:
+		if fn.ConfigMap[imagePullSecretsNamesKey] == "" {
+			return fmt.Errorf("the %q function requires the %q key in its configMap", fn.Builtin, imagePullSecretsNamesKey)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown built-in function %q", fn.Builtin)
+	}
+}
+
+// evalMutationFunction evaluates a single validated mutation function on the
+// object.
+func evalMutationFunction(fn v1beta1.MutationFunction, obj *unstructured.Unstructured) error {
+	switch fn.Builtin {
+	case v1beta1.SetLabelsFunction:
+		for k, v := range fn.ConfigMap {
+			core.SetLabel(obj, k, v)
+		}
+		return nil
+	case v1beta1.SetAnnotationsFunction:
+		for k, v := range fn.ConfigMap {
+			core.SetAnnotation(obj, k, v)
+		}
+		return nil
+	case v1beta1.SetImagePullSecretsFunction:
+		return setImagePullSecrets(obj, fn.ConfigMap[imagePullSecretsNamesKey])
+	case v1beta1.IgnoreMutationFunction:
+		if matchesTypes(obj, fn.ConfigMap[ignoreMutationTypesKey]) {
+			core.SetAnnotation(obj, metadata.LifecycleMutationAnnotation, metadata.IgnoreMutation)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown built-in function %q", fn.Builtin)
+	}
+}
+
+// matchesTypes returns true if the `type` field of the object is one of the
+// comma-separated types, or if no types are specified.
+func matchesTypes(obj *unstructured.Unstructured, types string) bool {
+	if types == "" {
+		return true
+	}
+	objType, _, _ := unstructured.NestedString(obj.Object, "type")
+	for _, t := range strings.Split(types, ",") {
+		if strings.TrimSpace(t) == objType {
+			return true
+		}
+	}
+	return false
+}
+
+// setImagePullSecrets adds the comma-separated Secret names to the
+// imagePullSecrets of the object's PodSpec, if the object embeds one.
+func setImagePullSecrets(obj *unstructured.Unstructured, names string) error {
+	podSpecPath, found := podSpecPaths[obj.GroupVersionKind().GroupKind()]
+	if !found {
+		return nil
+	}
+	secretsPath := append(append([]string{}, podSpecPath...), "imagePullSecrets")
+	secrets, _, err := unstructured.NestedSlice(obj.Object, secretsPath...)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, secret := range secrets {
+		if ref, ok := secret.(map[string]interface{}); ok {
+			if name, ok := ref["name"].(string); ok {
+				existing[name] = true
+			}
+		}
+	}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || existing[name] {
+			continue
+		}
+		secrets = append(secrets, map[string]interface{}{"name": name})
+		existing[name] = true
+	}
+	return unstructured.SetNestedSlice(obj.Object, secrets, secretsPath...)
+}
+
+// functionReaderAnnotations are the annotations kyaml sets on the objects it
+// passes to and reads from the KRM functions.
+var functionReaderAnnotations = []string{
+	kioutil.IndexAnnotation,
+	kioutil.PathAnnotation,
+	kioutil.SeqIndentAnnotation,
+	kioutil.IdAnnotation,
+	kioutil.LegacyIndexAnnotation,
+	kioutil.LegacyPathAnnotation,
+	kioutil.LegacyIdAnnotation,
+}
+
+// newImageFunction returns the filter which runs the KRM function image.
+func newImageFunction(image string, functionConfig *yaml.RNode) kio.Filter {
+	fn := container.NewContainer(runtimeutil.ContainerSpec{Image: image}, "nobody")
+	fn.Exec.FunctionConfig = functionConfig
+	return &fn
+}
+
+// evalImageFunction evaluates the KRM function image of a validated mutation
+// function on the objects, and updates them with the function output, in
+// place. The function may only change the objects, not add or remove any.
+func evalImageFunction(fn v1beta1.MutationFunction, objs []ast.FileObject) error {
+	if len(objs) == 0 {
+		return nil
+	}
+	data := make(map[string]interface{}, len(fn.ConfigMap))
+	for k, v := range fn.ConfigMap {
+		data[k] = v
+	}
+	functionConfig, err := yaml.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "function-input"},
+		"data":       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	inputs := make(map[core.ID]ast.FileObject, len(objs))
+	nodes := make([]*yaml.RNode, 0, len(objs))
+	for _, obj := range objs {
+		node, err := yaml.FromMap(obj.Object)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, node)
+		inputs[core.IDOf(obj)] = obj
+	}
+
+	output, err := newImageFunction(fn.Image, functionConfig).Filter(nodes)
+	if err != nil {
+		return fmt.Errorf("the function image %q failed: %w", fn.Image, err)
+	}
+	if len(output) != len(objs) {
+		return fmt.Errorf("the function image %q returned %d objects out of %d, but it may not add or remove objects", fn.Image, len(output), len(objs))
+	}
+
+	mutated := make(map[core.ID]*unstructured.Unstructured, len(output))
+	for _, node := range output {
+		jsn, err := node.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(jsn); err != nil {
+			return err
+		}
+		id := core.IDOf(u)
+		in, found := inputs[id]
+		if !found {
+			return fmt.Errorf("the function image %q returned %s, which was not declared, but it may not add or remove objects", fn.Image, id)
+		}
+		// Restore the annotations which kyaml manages to their declared
+		// values.
+		for _, key := range functionReaderAnnotations {
+			if value, found := in.GetAnnotations()[key]; found {
+				core.SetAnnotation(u, key, value)
+			} else {
+				core.RemoveAnnotations(u, key)
+			}
+		}
+		if len(u.GetAnnotations()) == 0 {
+			u.SetAnnotations(nil)
+		}
+		mutated[id] = u
+	}
+	for id, obj := range inputs {
+		u, found := mutated[id]
+		if !found {
+			return fmt.Errorf("the function image %q did not return %s, but it may not add or remove objects", fn.Image, id)
+		}
+		obj.Object = u.Object
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func declaredObjectMutator(name string, functions ...v1beta1.MutationFunction) *v1beta1.DeclaredObjectMutator {
+	mutator := &v1beta1.DeclaredObjectMutator{Spec: v1beta1.DeclaredObjectMutatorSpec{Functions: functions}}
+	mutator.Name = name
+	return mutator
+}
+
+func TestMutateDeclaredObjects(t *testing.T) {
+	costCenter := v1beta1.MutationFunction{
+		Builtin:   v1beta1.SetLabelsFunction,
+		ConfigMap: map[string]string{"cost-center": "1234"},
+	}
+	pullSecrets := v1beta1.MutationFunction{
+		Builtin:   v1beta1.SetImagePullSecretsFunction,
+		ConfigMap: map[string]string{"names": "registry-a, registry-b"},
+		Kinds:     []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+	}
+
+	testCases := []struct {
+		name           string
+		objs           []client.Object
+		wantCostCenter string
+		wantPullSecret []interface{}
+		wantCodes      []string
+	}{
+		{
+			name: "no mutators",
+		},
+		{
+			name:           "set labels on all objects",
+			objs:           []client.Object{declaredObjectMutator("cost-center", costCenter)},
+			wantCostCenter: "1234",
+		},
+		{
+			name: "later mutators override earlier ones",
+			objs: []client.Object{
+				declaredObjectMutator("b", v1beta1.MutationFunction{
+					Builtin:   v1beta1.SetLabelsFunction,
+					ConfigMap: map[string]string{"cost-center": "5678"},
+				}),
+				declaredObjectMutator("a", costCenter),
+			},
+			wantCostCenter: "5678",
+		},
+		{
+			name: "set image pull secrets on the Deployment only",
+			objs: []client.Object{declaredObjectMutator("pull-secrets", pullSecrets)},
+			wantPullSecret: []interface{}{
+				map[string]interface{}{"name": "registry-a"},
+				map[string]interface{}{"name": "registry-b"},
+			},
+		},
+		{
+			name: "RepoSync opted out",
+			objs: []client.Object{
+				declaredObjectMutator("cost-center", costCenter),
+				fake.RepoSyncObjectV1Beta1("bookstore", "repo-sync",
+					core.Annotation(metadata.DeclaredObjectMutationKey, metadata.DeclaredObjectMutationDisabled)),
+			},
+		},
+		{
+			name: "incomplete function configuration",
+			objs: []client.Object{declaredObjectMutator("pull-secrets", v1beta1.MutationFunction{
+				Builtin: v1beta1.SetImagePullSecretsFunction,
+			})},
+			wantCodes: []string{status.DeclaredObjectMutationErrorCode},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := []ast.FileObject{
+				fake.Unstructured(kinds.ConfigMap(), core.Name("cm"), core.Namespace("bookstore")),
+				fake.Unstructured(kinds.Deployment(), core.Name("deploy"), core.Namespace("bookstore")),
+			}
+			c := syncerFake.NewClient(t, core.Scheme, tc.objs...)
+			errs := mutateDeclaredObjects(context.Background(), c, &v1beta1.RepoSync{}, reposync.ObjectKey("bookstore", "repo-sync"), objs)
+			var gotCodes []string
+			if errs != nil {
+				for _, err := range errs.Errors() {
+					gotCodes = append(gotCodes, err.Code())
+				}
+			}
+			require.Equal(t, tc.wantCodes, gotCodes)
+
+			for _, obj := range objs {
+				require.Equal(t, tc.wantCostCenter, core.GetLabel(obj, "cost-center"), "cost-center label of %s", core.IDOf(obj))
+			}
+			pullSecrets, _, err := unstructured.NestedSlice(objs[1].Object, "spec", "template", "spec", "imagePullSecrets")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPullSecret, pullSecrets)
+		})
+	}
+}
+
+func TestMutateDeclaredObjectsIgnoreMutation(t *testing.T) {
+	ignoreTLSSecrets := v1beta1.MutationFunction{
+		Builtin:   v1beta1.IgnoreMutationFunction,
+		ConfigMap: map[string]string{"types": "kubernetes.io/tls"},
+		Kinds:     []metav1.GroupKind{{Kind: "Secret"}},
+	}
+	tlsSecret := fake.Unstructured(kinds.Secret(), core.Name("tls"), core.Namespace("bookstore"))
+	require.NoError(t, unstructured.SetNestedField(tlsSecret.Object, "kubernetes.io/tls", "type"))
+	opaqueSecret := fake.Unstructured(kinds.Secret(), core.Name("opaque"), core.Namespace("bookstore"))
+	require.NoError(t, unstructured.SetNestedField(opaqueSecret.Object, "Opaque", "type"))
+	cm := fake.Unstructured(kinds.ConfigMap(), core.Name("cm"), core.Namespace("bookstore"))
+	objs := []ast.FileObject{tlsSecret, opaqueSecret, cm}
+
+	c := syncerFake.NewClient(t, core.Scheme, declaredObjectMutator("ignore-tls-secrets", ignoreTLSSecrets))
+	errs := mutateDeclaredObjects(context.Background(), c, &v1beta1.RepoSync{}, reposync.ObjectKey("bookstore", "repo-sync"), objs)
+	require.Nil(t, errs)
+
+	require.Equal(t, metadata.IgnoreMutation, core.GetAnnotation(tlsSecret, metadata.LifecycleMutationAnnotation))
+	require.Empty(t, core.GetAnnotation(opaqueSecret, metadata.LifecycleMutationAnnotation))
+	require.Empty(t, core.GetAnnotation(cm, metadata.LifecycleMutationAnnotation))
+}
+
+// fakeDocker puts a fake `docker` command on the PATH, which runs the shell
+// script on the ResourceList it reads from stdin, and records its arguments
+// in the returned file.
+func fakeDocker(t *testing.T, script string) string {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	content := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\n%s\n", argsFile, script)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(content), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestMutateDeclaredObjectsImage(t *testing.T) {
+	const image = "gcr.io/kpt-fn/set-image:v0.1"
+	bumpImage := v1beta1.MutationFunction{
+		Image:     image,
+		ConfigMap: map[string]string{"image": "nginx:1.1"},
+		Kinds:     []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+	}
+
+	testCases := []struct {
+		name      string
+		fn        v1beta1.MutationFunction
+		script    string
+		wantImage string
+		wantCodes []string
+	}{
+		{
+			name:      "mutate the objects",
+			fn:        bumpImage,
+			script:    "sed 's/nginx:1.0/nginx:1.1/'",
+			wantImage: "nginx:1.1",
+		},
+		{
+			name:      "remove the objects",
+			fn:        bumpImage,
+			script:    "cat > /dev/null; printf 'apiVersion: config.kubernetes.io/v1\\nkind: ResourceList\\nitems: []\\n'",
+			wantImage: "nginx:1.0",
+			wantCodes: []string{status.DeclaredObjectMutationErrorCode},
+		},
+		{
+			name:      "function failure",
+			fn:        bumpImage,
+			script:    "cat > /dev/null; exit 1",
+			wantImage: "nginx:1.0",
+			wantCodes: []string{status.DeclaredObjectMutationErrorCode},
+		},
+		{
+			name: "both a built-in function and an image",
+			fn: v1beta1.MutationFunction{
+				Builtin: v1beta1.SetLabelsFunction,
+				Image:   image,
+			},
+			script:    "cat",
+			wantImage: "nginx:1.0",
+			wantCodes: []string{status.DeclaredObjectMutationErrorCode},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			argsFile := fakeDocker(t, tc.script)
+			deploy := fake.Unstructured(kinds.Deployment(), core.Name("deploy"), core.Namespace("bookstore"))
+			require.NoError(t, unstructured.SetNestedSlice(deploy.Object, []interface{}{
+				map[string]interface{}{"name": "nginx", "image": "nginx:1.0"},
+			}, "spec", "template", "spec", "containers"))
+			cm := fake.Unstructured(kinds.ConfigMap(), core.Name("nginx:1.0"), core.Namespace("bookstore"))
+			objs := []ast.FileObject{deploy, cm}
+
+			c := syncerFake.NewClient(t, core.Scheme, declaredObjectMutator("bump-image", tc.fn))
+			errs := mutateDeclaredObjects(context.Background(), c, &v1beta1.RepoSync{}, reposync.ObjectKey("bookstore", "repo-sync"), objs)
+			var gotCodes []string
+			if errs != nil {
+				for _, err := range errs.Errors() {
+					gotCodes = append(gotCodes, err.Code())
+				}
+			}
+			require.Equal(t, tc.wantCodes, gotCodes)
+
+			containers, _, err := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "containers")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantImage, containers[0].(map[string]interface{})["image"])
+			require.Empty(t, deploy.GetAnnotations(), "the annotations kyaml sets must not be declared")
+			// The ConfigMap does not match the kinds of the function.
+			require.Equal(t, "nginx:1.0", cm.GetName())
+
+			if tc.wantCodes == nil {
+				args, err := os.ReadFile(argsFile)
+				require.NoError(t, err)
+				require.Contains(t, string(args), "--network none")
+				require.Contains(t, string(args), image)
+			}
+		})
+	}
+}
@@ -30,10 +30,13 @@ import (
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/remediator"
+	"kpt.dev/configsync/pkg/rename"
 	"kpt.dev/configsync/pkg/reposync"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tunables"
 	"kpt.dev/configsync/pkg/util/compare"
 	utildiscovery "kpt.dev/configsync/pkg/util/discovery"
 	"kpt.dev/configsync/pkg/validate"
@@ -41,13 +44,13 @@ import (
 )
 
 // NewNamespaceRunner creates a new runnable parser for parsing a Namespace repo.
-func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope declared.Scope, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
+func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope declared.Scope, fileReader reader.Reader, c client.Client, pollingPeriod *tunables.Duration, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, ro RunnerOptions, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
 	converter, err := declared.NewValueConverter(dc)
 	if err != nil {
 		return nil, err
 	}
 
-	return &namespace{
+	p := &namespace{
 		opts: opts{
 			clusterName:        clusterName,
 			client:             c,
@@ -57,20 +60,29 @@ func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope decl
 			resyncPeriod:       resyncPeriod,
 			retryPeriod:        retryPeriod,
 			statusUpdatePeriod: statusUpdatePeriod,
+			upgradeDetector:    newUpgradeDetector(dc, ro.UpgradeSettlePeriod),
 			files:              files{FileSource: fs},
 			parser:             filesystem.NewParser(fileReader),
 			updater: updater{
-				scope:      scope,
-				resources:  resources,
-				applier:    app,
-				remediator: rem,
+				scope:           scope,
+				resources:       resources,
+				applier:         app,
+				remediator:      rem,
+				namespaceReader: c,
+				inventory:       client.ObjectKey{Namespace: string(scope), Name: syncName},
+				referenceReader: c,
 			},
 			discoveryInterface: dc,
 			converter:          converter,
 			mux:                &sync.Mutex{},
+			RunnerOptions:      ro,
 		},
 		scope: scope,
-	}, nil
+	}
+	if ro.NormalizeDeclarations {
+		p.normalizer = converter
+	}
+	return p, nil
 }
 
 type namespace struct {
@@ -89,7 +101,7 @@ func (p *namespace) options() *opts {
 }
 
 // parseSource implements the Parser interface
-func (p *namespace) parseSource(_ context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
+func (p *namespace) parseSource(ctx context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
@@ -105,11 +117,16 @@ func (p *namespace) parseSource(_ context.Context, state sourceState) ([]ast.Fil
 	builder := utildiscovery.ScoperBuilder(p.discoveryInterface)
 
 	klog.Infof("Parsing files from source dir: %s", state.syncDir.OSPath())
-	objs, err := p.parser.Parse(filePaths)
+	objs, err := p.parseChangedFiles(p.parser, filePaths, state.fileDigests)
 	if err != nil {
 		return nil, err
 	}
 
+	// Duplicated with root.go.
+	if mutationErrs := mutateDeclaredObjects(ctx, p.client, &v1beta1.RepoSync{}, reposync.ObjectKey(p.scope, p.syncName), objs); mutationErrs != nil {
+		return nil, mutationErrs
+	}
+
 	options := validate.Options{
 		ClusterName:    p.clusterName,
 		ReconcilerName: p.reconcilerName,
@@ -118,20 +135,48 @@ func (p *namespace) parseSource(_ context.Context, state sourceState) ([]ast.Fil
 		BuildScoper:    builder,
 		Converter:      p.converter,
 	}
+	p.selectorSkipped = nil
+	options.SelectorSkipped = &p.selectorSkipped
 	options = OptionsForScope(options, p.scope)
+	options.Visitors = append(options.Visitors, addSyncWeightDependencies)
 
 	objs, err = validate.Unstructured(objs, options)
+	// The namespace reconciler cannot create the missing CRDs, so report the
+	// objects as waiting for them, instead of failing to sync.
+	err = withClusterPrerequisiteErrors(err, objs)
 
 	if status.HasBlockingErrors(err) {
 		return nil, err
 	}
 
 	// Duplicated with root.go.
+	if secretErrs := checkPlaintextSecrets(ctx, p.client, &v1beta1.RepoSync{}, reposync.ObjectKey(p.scope, p.syncName), objs); secretErrs != nil {
+		err = status.Append(err, secretErrs)
+		if status.HasBlockingErrors(secretErrs) {
+			return nil, err
+		}
+	}
+
+	// Duplicated with root.go.
+	// The objects are renamed after the validation, which checks them against
+	// the directories of the hierarchical format.
+	if renameErrs := rename.Apply(p.Renames, objs); renameErrs != nil {
+		return nil, status.Append(err, renameErrs)
+	}
+
+	// Duplicated with root.go.
+	addCommonLabelsAndAnnotations(objs, p.CommonLabels, p.CommonAnnotations)
 	e := addAnnotationsAndLabels(objs, p.scope, p.syncName, p.sourceContext(), state.commit)
 	if e != nil {
 		err = status.Append(err, status.InternalErrorf("unable to add annotations and labels: %v", e))
 		return nil, err
 	}
+
+	// Enforce the quotas after the annotations and labels are added, so that
+	// the size of the objects matches the size of the applied objects.
+	if quotaErrs := enforceRepoSyncQuotas(ctx, p.client, string(p.scope), objs); quotaErrs != nil {
+		return nil, status.Append(err, quotaErrs)
+	}
 	return objs, err
 }
 
@@ -284,6 +329,8 @@ func (p *namespace) setSyncStatusWithRetries(ctx context.Context, newStatus sync
 	currentRS := rs.DeepCopy()
 
 	setSyncStatusFields(&rs.Status.Status, newStatus, denominator)
+	declaredObjs, _ := p.resources.DeclaredObjects()
+	newlyAbandoned := updateAbandonedResources(&rs.Status.Sync, p.applier.AbandonedObjects(), p.applier.RetainedObjects(), declaredObjs, newStatus.lastUpdate)
 
 	errorSources, errorSummary := summarizeErrors(rs.Status.Source, rs.Status.Sync)
 	if newStatus.syncing {
@@ -291,12 +338,16 @@ func (p *namespace) setSyncStatusWithRetries(ctx context.Context, newStatus sync
 	} else {
 		if errorSummary.TotalCount == 0 {
 			rs.Status.LastSyncedCommit = rs.Status.Sync.Commit
+			if newStatus.fullResync {
+				rs.Status.Sync.LastFullResync = newStatus.lastUpdate.DeepCopy()
+			}
 		}
 		reposync.SetSyncing(rs, false, "Sync", "Sync Completed", rs.Status.Sync.Commit, errorSources, errorSummary, rs.Status.Sync.LastUpdate)
 	}
 
-	// Avoid unnecessary status updates.
-	if !currentRS.Status.Sync.LastUpdate.IsZero() && cmp.Equal(currentRS.Status, rs.Status, compare.IgnoreTimestampUpdates) {
+	// Avoid unnecessary status updates, but always record the time of a full
+	// resync, even if nothing else changed.
+	if !newStatus.fullResync && !currentRS.Status.Sync.LastUpdate.IsZero() && cmp.Equal(currentRS.Status, rs.Status, compare.IgnoreTimestampUpdates) {
 		klog.V(5).Infof("Skipping status update for RepoSync %s/%s", rs.Namespace, rs.Name)
 		return nil
 	}
@@ -311,6 +362,9 @@ func (p *namespace) setSyncStatusWithRetries(ctx context.Context, newStatus sync
 	if !newStatus.syncing && rs.Status.Sync.Commit != "" {
 		metrics.RecordLastSync(ctx, metrics.StatusTagValueFromSummary(errorSummary), rs.Status.Sync.Commit, rs.Status.Sync.LastUpdate.Time)
 	}
+	if newStatus.fullResync && rs.Status.Sync.LastFullResync != nil {
+		metrics.RecordLastFullResync(ctx, rs.Status.Sync.LastFullResync.Time)
+	}
 
 	if klog.V(5).Enabled() {
 		klog.Infof("Updating status for RepoSync %s/%s:\nDiff (- Expected, + Actual):\n%s",
@@ -325,6 +379,7 @@ func (p *namespace) setSyncStatusWithRetries(ctx context.Context, newStatus sync
 		}
 		return status.APIServerError(err, fmt.Sprintf("failed to update the RepoSync sync status for the %v namespace", p.scope))
 	}
+	recordAbandonedEvents(ctx, p.client, rs, kinds.RepoSyncV1Beta1(), p.reconcilerName, newlyAbandoned)
 	return nil
 }
 
@@ -22,7 +22,11 @@ import (
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/rename"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncwindow"
+	"kpt.dev/configsync/pkg/tunables"
 	"kpt.dev/configsync/pkg/util/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -47,7 +51,8 @@ type opts struct {
 
 	// pollingPeriod is the period of time between checking the filesystem for
 	// source updates to sync.
-	pollingPeriod time.Duration
+	// It can be changed while the parser runs, from the tunables ConfigMap.
+	pollingPeriod *tunables.Duration
 
 	// ResyncPeriod is the period of time between forced re-sync from source
 	// (even without a new commit).
@@ -60,6 +65,10 @@ type opts struct {
 	// sync status, to account for management conflict errors from the remediator.
 	statusUpdatePeriod time.Duration
 
+	// upgradeDetector holds off applying new commits while the cluster control
+	// plane is being upgraded. It is nil if the upgrade detection is disabled.
+	upgradeDetector *upgradeDetector
+
 	// discoveryInterface is how the parser learns what types are currently
 	// available on the cluster.
 	discoveryInterface discovery.ServerResourcer
@@ -71,8 +80,74 @@ type opts struct {
 	// mux prevents status update conflicts.
 	mux *sync.Mutex
 
+	// selectorSkipped are the objects of the last parsed source, which are
+	// filtered out by their cluster selector or namespace selector.
+	selectorSkipped []ast.FileObject
+
 	files
 	updater
+	RunnerOptions
+}
+
+// RunnerOptions holds the optional settings of a parser, which are disabled
+// when left unset.
+type RunnerOptions struct {
+	// TargetClient reads the resources on the cluster that the resources are
+	// synced to, when it differs from the client that updates the RSync.
+	// Only applicable to the root reconciler.
+	TargetClient client.Client
+	// UpgradeSettlePeriod is how long to hold off applying new commits after
+	// an upgrade of the cluster control plane. Zero disables the upgrade
+	// detection.
+	UpgradeSettlePeriod time.Duration
+	// SelfUpdateTimeout is how long to wait for the Config Sync components
+	// declared in the source to become healthy after they change, before
+	// reverting them. Zero applies them without a health check. Only
+	// applicable to the root reconciler.
+	SelfUpdateTimeout time.Duration
+	// RetryBudget is the number of consecutive failed retries of a commit
+	// after which the parser stops retrying it, until the source changes.
+	// Zero retries forever.
+	RetryBudget int
+	// SupersedeInFlightApply enables stopping to apply a commit as soon as a
+	// newer commit is fetched, rendered and validated, to apply the newer
+	// commit instead.
+	SupersedeInFlightApply bool
+	// ApprovalTimeout is how long to wait for the approval of a new commit
+	// before applying it. New commits are applied without approval if it is
+	// not positive.
+	ApprovalTimeout time.Duration
+	// SyncWindows restrict when new commits are applied. New commits are
+	// applied at any time if there are none.
+	SyncWindows []syncwindow.Window
+	// DryRun previews the commits with server-side dry-run applies, and
+	// reports the diff in the RSync status, instead of applying them.
+	DryRun bool
+	// Renames rename the declared objects after rendering, so that the same
+	// package can be synced multiple times in a cluster.
+	Renames []rename.Rule
+	// OneShot stops the parser once a commit is synced, for the reconcilers
+	// which run in a Job.
+	OneShot bool
+	// NormalizeDeclarations enables comparing the declarations of objects
+	// with their previous declarations after a schema-aware normalization,
+	// so that pure formatting or defaulting changes are not reported as
+	// changes.
+	NormalizeDeclarations bool
+	// CommonLabels are added to every declared object, unless the object
+	// declares a label with the same key.
+	CommonLabels map[string]string
+	// CommonAnnotations are added to every declared object, unless the
+	// object declares an annotation with the same key.
+	CommonAnnotations map[string]string
+	// HierarchicalDirs are the directories of an unstructured repository,
+	// relative to SyncDir, which are in the hierarchy format. Only applicable
+	// to the root reconciler.
+	HierarchicalDirs []cmpath.Relative
+	// ExternalTriggers receives a value when the reconciler is asked to run
+	// immediately, e.g. by the sync request controller after a push. Nil if
+	// there are no external triggers.
+	ExternalTriggers <-chan struct{}
 }
 
 // Parser represents a parser that can be pointed at and continuously parse a source.
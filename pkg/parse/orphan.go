@@ -0,0 +1,201 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// orphanAuditPeriod is how long the reconciler waits between audits of the
+// resources managed by it, but missing from its inventory. Each audit lists
+// all the declared types, so it runs much less often than the status updates.
+const orphanAuditPeriod = 10 * time.Minute
+
+// maxOrphanedResources is the maximum number of orphaned resources recorded in
+// the sync status, to keep the size of the RSync object bounded.
+const maxOrphanedResources = 100
+
+// auditOrphanedResources finds the resources which carry the management
+// metadata of the reconciler, but are missing both from its ResourceGroup
+// inventory and from the declared resources. Such resources are neither
+// updated nor pruned, so they are usually left behind by older versions of
+// Config Sync or by manual edits.
+//
+// The orphans are recorded in the reconciler state, to be reported in the sync
+// status, and in the OrphanedResources metric. If the RootSync or RepoSync is
+// annotated with `configsync.gke.io/orphan-cleanup: enabled`, the Config Sync
+// metadata is also removed from the orphans, which releases them.
+//
+// The audit is skipped while the updater is running, because the inventory is
+// not up to date until the apply completes.
+func auditOrphanedResources(ctx context.Context, p Parser, state *reconcilerState) error {
+	if time.Since(state.lastOrphanAudit) < orphanAuditPeriod || p.Syncing() {
+		return nil
+	}
+	opts := p.options()
+	inventory, err := inventoryIDs(ctx, opts.k8sClient(), client.ObjectKey{Namespace: opts.syncNamespace(), Name: opts.syncName})
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			// Nothing has been applied yet.
+			return nil
+		}
+		return err
+	}
+	orphans, err := findOrphanedResources(ctx, opts, inventory)
+	if err != nil {
+		return err
+	}
+	state.lastOrphanAudit = time.Now()
+	metrics.RecordOrphanedResources(ctx, len(orphans))
+	if len(orphans) > 0 {
+		klog.Warningf("Found %d resources managed by the reconciler, but missing from its inventory", len(orphans))
+	}
+
+	if len(orphans) > 0 && orphanCleanupEnabled(ctx, opts) {
+		orphans = cleanupOrphanedResources(ctx, opts.k8sClient(), orphans)
+	}
+
+	refs := make([]v1beta1.ResourceRef, 0, len(orphans))
+	for _, obj := range orphans {
+		refs = append(refs, status.ToResourceRef(obj))
+	}
+	if len(refs) > maxOrphanedResources {
+		refs = refs[:maxOrphanedResources]
+	}
+	if len(refs) == 0 {
+		refs = nil
+	}
+	state.orphanedResources = refs
+	return nil
+}
+
+// inventoryIDs returns the IDs of the resources in the ResourceGroup inventory
+// with the given key.
+func inventoryIDs(ctx context.Context, c client.Reader, key client.ObjectKey) (map[core.ID]bool, error) {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	if err := c.Get(ctx, key, rg); err != nil {
+		return nil, err
+	}
+	resources, _, err := unstructured.NestedSlice(rg.Object, "spec", "resources")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[core.ID]bool, len(resources))
+	for _, r := range resources {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(m, "group")
+		kind, _, _ := unstructured.NestedString(m, "kind")
+		namespace, _, _ := unstructured.NestedString(m, "namespace")
+		name, _, _ := unstructured.NestedString(m, "name")
+		ids[core.ID{
+			GroupKind: schema.GroupKind{Group: group, Kind: kind},
+			ObjectKey: client.ObjectKey{Namespace: namespace, Name: name},
+		}] = true
+	}
+	return ids, nil
+}
+
+// findOrphanedResources lists the resources of the declared types which are
+// managed by the reconciler, and returns the ones which are neither in the
+// inventory nor declared, sorted by ID.
+// Types which are no longer served are skipped.
+func findOrphanedResources(ctx context.Context, opts *opts, inventory map[core.ID]bool) ([]*unstructured.Unstructured, error) {
+	declaredObjs, _ := opts.resources.DeclaredObjects()
+	declaredIDs := make(map[core.ID]bool, len(declaredObjs))
+	for _, obj := range declaredObjs {
+		declaredIDs[core.IDOf(obj)] = true
+	}
+	gvks, _ := opts.resources.DeclaredGVKs()
+
+	listOpts := []client.ListOption{client.MatchingLabels{metadata.ManagedByKey: metadata.ManagedByValue}}
+	if opts.scope != declared.RootReconciler {
+		listOpts = append(listOpts, client.InNamespace(string(opts.scope)))
+	}
+
+	var orphans []*unstructured.Unstructured
+	for gvk := range gvks {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := opts.k8sClient().List(ctx, list, listOpts...); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			id := core.IDOf(obj)
+			if inventory[id] || declaredIDs[id] || !diff.IsManager(opts.scope, opts.syncName, obj) {
+				continue
+			}
+			orphans = append(orphans, obj)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return core.IDOf(orphans[i]).String() < core.IDOf(orphans[j]).String()
+	})
+	return orphans, nil
+}
+
+// orphanCleanupEnabled returns whether the RootSync or RepoSync opted in to
+// the cleanup of orphaned resources.
+func orphanCleanupEnabled(ctx context.Context, opts *opts) bool {
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		klog.Warningf("Failed to get the RSync to check the %s annotation: %v", metadata.OrphanCleanupKey, err)
+		return false
+	}
+	return core.GetAnnotation(rs, metadata.OrphanCleanupKey) == metadata.OrphanCleanupEnabled
+}
+
+// cleanupOrphanedResources removes the Config Sync metadata from the orphaned
+// resources, so that they are no longer reported as managed.
+// Returns the resources which could not be cleaned up.
+func cleanupOrphanedResources(ctx context.Context, c client.Client, orphans []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var remaining []*unstructured.Unstructured
+	for _, orphan := range orphans {
+		id := core.IDOf(orphan)
+		obj := orphan.DeepCopy()
+		metadata.RemoveConfigSyncMetadata(obj)
+		if err := c.Update(ctx, obj); err != nil {
+			klog.Warningf("Failed to remove the Config Sync metadata from the orphaned resource %s: %v", id, err)
+			remaining = append(remaining, orphan)
+			continue
+		}
+		klog.Infof("Removed the Config Sync metadata from the orphaned resource %s", id)
+	}
+	return remaining
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/diff/difftest"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/syncertest"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// orphanTestInventory returns the inventory of the test RootSync, which
+// tracks the given objects.
+func orphanTestInventory(objs ...client.Object) *unstructured.Unstructured {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	rg.SetName(rootSyncName)
+	rg.SetNamespace(configsync.ControllerNamespace)
+	var resources []interface{}
+	for _, obj := range objs {
+		id := core.IDOf(obj)
+		resources = append(resources, map[string]interface{}{
+			"group":     id.Group,
+			"kind":      id.Kind,
+			"namespace": id.Namespace,
+			"name":      id.Name,
+		})
+	}
+	_ = unstructured.SetNestedSlice(rg.Object, resources, "spec", "resources")
+	return rg
+}
+
+func TestAuditOrphanedResources(t *testing.T) {
+	managedBy := difftest.ManagedBy(declared.RootReconciler, rootSyncName)
+	inventoried := fake.RoleObject(core.Name("inventoried"), core.Namespace("foo"), syncertest.ManagementEnabled, managedBy)
+	declaredRole := fake.RoleObject(core.Name("declared"), core.Namespace("foo"), syncertest.ManagementEnabled, managedBy)
+	orphan := fake.RoleObject(core.Name("orphan"), core.Namespace("foo"), syncertest.ManagementEnabled, managedBy)
+	otherManager := fake.RoleObject(core.Name("other"), core.Namespace("foo"), syncertest.ManagementEnabled,
+		difftest.ManagedBy(declared.Scope("foo"), configsync.RepoSyncName))
+	unmanaged := fake.RoleObject(core.Name("unmanaged"), core.Namespace("foo"))
+
+	testCases := []struct {
+		name            string
+		rsyncAnnotation string
+		wantOrphans     []v1beta1.ResourceRef
+		wantCleanedUp   bool
+	}{
+		{
+			name:        "orphans are reported",
+			wantOrphans: []v1beta1.ResourceRef{status.ToResourceRef(orphan)},
+		},
+		{
+			name:            "orphans are cleaned up",
+			rsyncAnnotation: metadata.OrphanCleanupEnabled,
+			wantCleanedUp:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := fake.RootSyncObjectV1Beta1(rootSyncName)
+			if tc.rsyncAnnotation != "" {
+				core.SetAnnotation(rs, metadata.OrphanCleanupKey, tc.rsyncAnnotation)
+			}
+			p := newParser(t, FileSource{}).(*root)
+			p.client = syncerFake.NewClient(t, core.Scheme, rs, orphanTestInventory(inventoried),
+				inventoried.DeepCopy(), declaredRole.DeepCopy(), orphan.DeepCopy(), otherManager.DeepCopy(), unmanaged.DeepCopy())
+			_, err := p.resources.Update(context.Background(), []client.Object{inventoried.DeepCopy(), declaredRole.DeepCopy()}, "abc123")
+			require.NoError(t, err)
+
+			state := &reconcilerState{}
+			require.NoError(t, auditOrphanedResources(context.Background(), p, state))
+			require.Equal(t, tc.wantOrphans, state.orphanedResources)
+			require.False(t, state.lastOrphanAudit.IsZero())
+
+			got := fake.RoleObject()
+			require.NoError(t, p.client.Get(context.Background(), client.ObjectKeyFromObject(orphan), got))
+			require.Equal(t, tc.wantCleanedUp, core.GetAnnotation(got, metadata.ResourceManagerKey) == "")
+
+			// The audit is not repeated until the audit period elapses.
+			state.orphanedResources = nil
+			require.NoError(t, auditOrphanedResources(context.Background(), p, state))
+			require.Nil(t, state.orphanedResources)
+		})
+	}
+}
+
+func TestAuditOrphanedResourcesWithoutInventory(t *testing.T) {
+	p := newParser(t, FileSource{}).(*root)
+	p.client = syncerFake.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
+
+	state := &reconcilerState{}
+	require.NoError(t, auditOrphanedResources(context.Background(), p, state))
+	require.Nil(t, state.orphanedResources)
+	require.True(t, state.lastOrphanAudit.IsZero())
+
+	rs := &v1beta1.RootSync{}
+	require.NoError(t, p.client.Get(context.Background(), rootsync.ObjectKey(rootSyncName), rs))
+	require.Empty(t, rs.Status.Sync.OrphanedResources)
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"path"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// parsedFile is the result of parsing a file, which is reused as long as the
+// file and the defaults of its directory are unchanged.
+type parsedFile struct {
+	// digest is the digest of the file, combined with the digest of the
+	// directory defaults file of its directory, if any.
+	digest string
+	// objs are the objects declared in the file.
+	objs []ast.FileObject
+}
+
+// parseChangedFiles parses the files of filePaths, like parser.Parse, but only
+// reads the files which changed since they were last parsed, and reuses the
+// objects last parsed from the unchanged files.
+// fileDigests are the digests of the files, as tracked in the sourceState.
+// Without digests, all the files are parsed.
+func (o *files) parseChangedFiles(parser filesystem.ConfigParser, filePaths reader.FilePaths, fileDigests map[string]string) ([]ast.FileObject, status.MultiError) {
+	if fileDigests == nil {
+		return parser.Parse(filePaths)
+	}
+
+	parsed := make(map[string]parsedFile, len(filePaths.Files))
+	var changedFiles []cmpath.Absolute
+	changedDirs := make(map[string]bool)
+	for _, f := range filePaths.Files {
+		rel, ok := relativeSlashPath(filePaths.RootDir, f)
+		if !ok || isDirectoryDefaults(rel) {
+			continue
+		}
+		digest := fileDigests[rel] + fileDigests[path.Join(path.Dir(rel), reader.DirectoryDefaultsFile)]
+		if last, found := o.parsedFiles[rel]; found && digest != "" && last.digest == digest {
+			parsed[rel] = last
+			continue
+		}
+		parsed[rel] = parsedFile{digest: digest}
+		changedFiles = append(changedFiles, f)
+		changedDirs[path.Dir(rel)] = true
+	}
+	// The directory defaults apply to the objects of the changed files.
+	for _, f := range filePaths.Files {
+		if rel, ok := relativeSlashPath(filePaths.RootDir, f); ok && isDirectoryDefaults(rel) && changedDirs[path.Dir(rel)] {
+			changedFiles = append(changedFiles, f)
+		}
+	}
+
+	if len(changedFiles) > 0 {
+		klog.Infof("Parsing %d changed files of %d", len(changedFiles), len(filePaths.Files))
+		objs, errs := parser.Parse(reader.FilePaths{
+			RootDir:   filePaths.RootDir,
+			PolicyDir: filePaths.PolicyDir,
+			Files:     changedFiles,
+		})
+		if errs != nil {
+			return nil, errs
+		}
+		for _, obj := range objs {
+			rel := obj.SlashPath()
+			file := parsed[rel]
+			file.objs = append(file.objs, obj)
+			parsed[rel] = file
+		}
+	} else {
+		klog.V(4).Infof("All the %d files are unchanged", len(filePaths.Files))
+	}
+	// The files which are no longer listed are dropped.
+	o.parsedFiles = parsed
+
+	// The objects are copied, since they are mutated once parsed, and are
+	// returned in the order of the files, like parser.Parse does.
+	var objs []ast.FileObject
+	for _, f := range filePaths.Files {
+		rel, ok := relativeSlashPath(filePaths.RootDir, f)
+		if !ok {
+			continue
+		}
+		for _, obj := range parsed[rel].objs {
+			objs = append(objs, obj.DeepCopy())
+		}
+	}
+	return objs, nil
+}
+
+// relativeSlashPath returns the slash-separated path of the file relative to
+// dir, as keyed in the sourceState.fileDigests.
+func relativeSlashPath(dir, file cmpath.Absolute) (string, bool) {
+	rel, err := filepath.Rel(dir.OSPath(), file.OSPath())
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// isDirectoryDefaults returns true if the slash-separated path is a directory
+// defaults file, which declares no objects.
+func isDirectoryDefaults(rel string) bool {
+	return path.Base(rel) == reader.DirectoryDefaultsFile
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// countingReader records the files read by the File reader.
+type countingReader struct {
+	reader.File
+	read []string
+}
+
+func (r *countingReader) Read(filePaths reader.FilePaths) ([]ast.FileObject, status.MultiError) {
+	for _, f := range filePaths.Files {
+		r.read = append(r.read, filepath.Base(f.OSPath()))
+	}
+	return r.File.Read(filePaths)
+}
+
+func TestParseChangedFiles(t *testing.T) {
+	writeCommit := func(t *testing.T, files map[string]string) (reader.FilePaths, map[string]string) {
+		t.Helper()
+		dir := t.TempDir()
+		var paths []string
+		var absolutes []cmpath.Absolute
+		for _, name := range []string{"a.yaml", "b.yaml", "c.yaml", reader.DirectoryDefaultsFile} {
+			content, found := files[name]
+			if !found {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			paths = append(paths, path)
+			absolutes = append(absolutes, cmpath.Absolute(filepath.ToSlash(path)))
+		}
+		digests, err := hydrate.FileDigests(dir, paths)
+		require.NoError(t, err)
+		return reader.FilePaths{RootDir: cmpath.Absolute(filepath.ToSlash(dir)), Files: absolutes}, digests
+	}
+	configMap := func(name, data string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: bookstore\ndata:\n  key: " + data + "\n"
+	}
+	names := func(objs []ast.FileObject) []string {
+		var result []string
+		for _, obj := range objs {
+			result = append(result, obj.GetName()+"="+obj.Object["data"].(map[string]interface{})["key"].(string))
+		}
+		return result
+	}
+
+	r := &countingReader{}
+	parser := filesystem.NewParser(r)
+	f := &files{}
+
+	// The first commit is parsed entirely.
+	filePaths, digests := writeCommit(t, map[string]string{
+		"a.yaml": configMap("a", "v1"),
+		"b.yaml": configMap("b", "v1"),
+		"c.yaml": configMap("c", "v1"),
+	})
+	objs, errs := f.parseChangedFiles(parser, filePaths, digests)
+	require.Nil(t, errs)
+	require.Equal(t, []string{"a=v1", "b=v1", "c=v1"}, names(objs))
+	require.Equal(t, []string{"a.yaml", "b.yaml", "c.yaml"}, r.read)
+	// The returned objects are copies, so mutating them does not change the
+	// objects reused for the next commits.
+	objs[0].SetName("mutated")
+
+	// Only the changed file of the next commit is parsed, and the deleted
+	// file is dropped.
+	r.read = nil
+	filePaths, digests = writeCommit(t, map[string]string{
+		"a.yaml": configMap("a", "v1"),
+		"b.yaml": configMap("b", "v2"),
+	})
+	objs, errs = f.parseChangedFiles(parser, filePaths, digests)
+	require.Nil(t, errs)
+	require.Equal(t, []string{"a=v1", "b=v2"}, names(objs))
+	require.Equal(t, []string{"b.yaml"}, r.read)
+
+	// The files of a directory whose defaults changed are parsed again.
+	r.read = nil
+	filePaths, digests = writeCommit(t, map[string]string{
+		"a.yaml":                     configMap("a", "v1"),
+		"b.yaml":                     configMap("b", "v2"),
+		reader.DirectoryDefaultsFile: "labels:\n  team: bookstore\n",
+	})
+	objs, errs = f.parseChangedFiles(parser, filePaths, digests)
+	require.Nil(t, errs)
+	require.Equal(t, []string{"a=v1", "b=v2"}, names(objs))
+	require.Equal(t, []string{"a.yaml", "b.yaml", reader.DirectoryDefaultsFile}, r.read)
+	require.Equal(t, "bookstore", objs[0].GetLabels()["team"])
+
+	// Without digests, all the files are parsed.
+	r.read = nil
+	objs, errs = f.parseChangedFiles(parser, filePaths, nil)
+	require.Nil(t, errs)
+	require.Equal(t, []string{"a=v1", "b=v2"}, names(objs))
+	require.Equal(t, []string{"a.yaml", "b.yaml", reader.DirectoryDefaultsFile}, r.read)
+}
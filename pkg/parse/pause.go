@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// syncPausedReason is the reason of the Syncing condition of a RootSync or
+// RepoSync whose sync is paused.
+const syncPausedReason = "Paused"
+
+// isSyncPaused returns true if the annotations of the RootSync or RepoSync
+// pause the sync.
+func isSyncPaused(annotations map[string]string) bool {
+	return annotations[metadata.SyncPausedAnnotationKey] == metadata.SyncPausedEnabled
+}
+
+// pauseSync stops the remediator, so that the reconciler no longer mutates
+// the cluster while the sync is paused. The runs keep reading the source and
+// reporting the source and rendering status, but stop before applying.
+func pauseSync(p Parser, state *reconcilerState) {
+	if state.paused {
+		return
+	}
+	klog.Infof("Pausing the sync, as requested by the %s annotation", metadata.SyncPausedAnnotationKey)
+	p.options().remediator.Pause()
+	state.paused = true
+}
+
+// resumeSync resumes the sync with a full resync, since the cluster may have
+// drifted while the sync was paused. The remediator is resumed once the
+// resync applied the declared resources.
+func resumeSync(p Parser, state *reconcilerState) {
+	if !state.paused {
+		return
+	}
+	klog.Infof("Resuming the sync with a full resync, since the %s annotation was removed", metadata.SyncPausedAnnotationKey)
+	state.paused = false
+	state.resetAllButSourceState()
+	p.options().applier.RequestFullApply()
+}
+
+// reportPausedSource parses the commit read while the sync is paused, and
+// reports the result in the source status, so that errors in the source of
+// truth are visible before the sync is resumed. Each commit is only parsed
+// once. The parser result is not cached, since the commit is parsed again
+// when it is applied after the sync is resumed.
+func reportPausedSource(ctx context.Context, p Parser, state *reconcilerState) status.MultiError {
+	if state.sourceStatus.commit == state.cache.source.commit {
+		return nil
+	}
+	_, sourceErrs := p.parseSource(ctx, state.cache.source)
+	newSourceStatus := sourceStatus{
+		commit:     state.cache.source.commit,
+		errs:       sourceErrs,
+		lastUpdate: metav1.Now(),
+	}
+	if !state.needToSetSourceStatus(newSourceStatus) {
+		return nil
+	}
+	if err := p.setSourceStatus(ctx, newSourceStatus); err != nil {
+		return status.Append(nil, err)
+	}
+	state.sourceStatus = newSourceStatus
+	state.syncingConditionLastUpdate = newSourceStatus.lastUpdate
+	return nil
+}
+
+// setPausedCondition sets the Syncing condition of the RootSync or RepoSync to
+// False with the Paused reason, after the source and rendering status updates
+// of the run set it. Failures are logged, and retried after the next run.
+func setPausedCondition(ctx context.Context, p Parser, state *reconcilerState) {
+	if err := updatePausedCondition(ctx, p, state.cache.source.commit); err != nil {
+		klog.Warningf("Failed to report the paused sync: %v", err)
+	}
+}
+
+// updatePausedCondition sets the Syncing condition of the RootSync or RepoSync
+// to report that the sync of the commit is paused.
+func updatePausedCondition(ctx context.Context, p Parser, commit string) error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to report the paused sync")
+	}
+	message := fmt.Sprintf("Sync paused by the %s annotation", metadata.SyncPausedAnnotationKey)
+	var updated bool
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		updated, _ = rootsync.SetSyncing(rs, false, syncPausedReason, message, commit, nil, nil, metav1.Now())
+	case *v1beta1.RepoSync:
+		updated, _ = reposync.SetSyncing(rs, false, syncPausedReason, message, commit, nil, nil, metav1.Now())
+	}
+	if !updated {
+		return nil
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to report the paused sync")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/rootsync"
+)
+
+func TestIsSyncPaused(t *testing.T) {
+	require.False(t, isSyncPaused(nil))
+	require.False(t, isSyncPaused(map[string]string{metadata.SyncPausedAnnotationKey: "false"}))
+	require.True(t, isSyncPaused(map[string]string{metadata.SyncPausedAnnotationKey: metadata.SyncPausedEnabled}))
+}
+
+func TestPauseAndResumeSync(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	state := &reconcilerState{}
+	state.cache.source = sourceState{commit: "abc123", syncDir: cmpath.Absolute("/repo/rev/abc123")}
+	state.cache.applied = true
+
+	pauseSync(p, state)
+	require.True(t, state.paused)
+
+	setPausedCondition(ctx, p, state)
+	rs := &v1beta1.RootSync{}
+	require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+	cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, syncPausedReason, cond.Reason)
+	require.Equal(t, "abc123", cond.Commit)
+
+	// Resuming forces a full resync, but keeps the source.
+	resumeSync(p, state)
+	require.False(t, state.paused)
+	require.False(t, state.cache.applied)
+	require.Equal(t, "abc123", state.cache.source.commit)
+}
@@ -0,0 +1,161 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// minSensitiveValueLength is the minimum length of the value of a key
+	// whose name suggests a credential, for the value to be reported.
+	minSensitiveValueLength = 8
+	// minHighEntropyValueLength is the minimum length of a value to be
+	// reported based on its entropy alone.
+	minHighEntropyValueLength = 20
+	// minEntropy is the minimum Shannon entropy, in bits per character, of a
+	// value to be reported based on its entropy alone. Random base64 strings
+	// are around 5 to 6 bits per character, while English text and common
+	// configuration values are below 4.
+	minEntropy = 4.2
+)
+
+var (
+	// sensitiveKeyPattern matches the Secret keys whose name suggests a
+	// credential.
+	sensitiveKeyPattern = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[_.-]?key|private[_.-]?key|credential|access[_.-]?key|auth|\.pem$|\.key$|\.p12$)`)
+	// privateKeyPattern matches PEM encoded private keys.
+	privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	// placeholderPattern matches the values which are obviously not real
+	// credentials, such as templating variables, references to external
+	// secret stores and fillers.
+	placeholderPattern = regexp.MustCompile(`(?i)^(\$\{[^}]*\}|\$\([^)]*\)|\{\{.*\}\}|<[^>]*>|%[a-z_]+%|(ref\+|vault:|sm://|gcp:|aws:|azure:|op://).*|changeme|change[_-]me|replace[_-]?me|todo|placeholder|dummy|example|redacted|none|null|x+|\*+|\.+)$`)
+)
+
+// checkPlaintextSecrets scans the declared Secrets for plaintext credentials,
+// if the RSync opted in with the `configsync.gke.io/plaintext-secret-check`
+// annotation. The Secrets found are reported as non-blocking warnings, or as
+// errors, depending on the annotation value.
+//
+// rsync is the RootSync or RepoSync being reconciled. It is fetched with
+// rsyncKey to check the annotation.
+func checkPlaintextSecrets(ctx context.Context, c client.Reader, rsync client.Object, rsyncKey client.ObjectKey, objs []ast.FileObject) status.MultiError {
+	if err := c.Get(ctx, rsyncKey, rsync); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return status.APIServerError(err, "failed to get the RSync to check the plaintext secret scan opt-in")
+	}
+	mode := core.GetAnnotation(rsync, metadata.PlaintextSecretCheckKey)
+	if mode != metadata.PlaintextSecretCheckWarn && mode != metadata.PlaintextSecretCheckError {
+		return nil
+	}
+
+	var errs status.MultiError
+	for _, obj := range objs {
+		if obj.GetObjectKind().GroupVersionKind().GroupKind() != kinds.Secret().GroupKind() ||
+			core.GetAnnotation(obj, metadata.PlaintextSecretCheckKey) == metadata.PlaintextSecretCheckIgnore {
+			continue
+		}
+		keys := plaintextSecretKeys(obj.Unstructured)
+		if len(keys) == 0 {
+			continue
+		}
+		klog.Warningf("Secret %s seems to contain plaintext credentials in the keys %v", core.GKNN(obj), keys)
+		if mode == metadata.PlaintextSecretCheckError {
+			errs = status.Append(errs, status.PlaintextSecretError(obj, keys))
+		} else {
+			errs = status.Append(errs, status.PlaintextSecretWarning(obj, keys))
+		}
+	}
+	return errs
+}
+
+// plaintextSecretKeys returns the sorted keys of the data and stringData of
+// the Secret whose values seem to be plaintext credentials.
+func plaintextSecretKeys(secret *unstructured.Unstructured) []string {
+	values := make(map[string]string)
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	for key, encoded := range data {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			// Invalid data is reported by the API server on apply.
+			continue
+		}
+		values[key] = string(decoded)
+	}
+	stringData, _, _ := unstructured.NestedStringMap(secret.Object, "stringData")
+	for key, value := range stringData {
+		values[key] = value
+	}
+
+	var keys []string
+	for key, value := range values {
+		if isPlaintextCredential(key, value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isPlaintextCredential returns true if the value of the Secret key seems to
+// be a real credential, based on the name of the key and the entropy of the
+// value.
+func isPlaintextCredential(key, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || placeholderPattern.MatchString(value) {
+		return false
+	}
+	if privateKeyPattern.MatchString(value) {
+		return true
+	}
+	if sensitiveKeyPattern.MatchString(key) && len(value) >= minSensitiveValueLength {
+		return true
+	}
+	return len(value) >= minHighEntropyValueLength && shannonEntropy(value) >= minEntropy
+}
+
+// shannonEntropy returns the Shannon entropy of the string, in bits per
+// character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
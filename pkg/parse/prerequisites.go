@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	utildiscovery "kpt.dev/configsync/pkg/util/discovery"
+)
+
+// clusterPrerequisiteCheckPeriod is the minimum period between two checks of
+// whether the missing cluster prerequisites became available.
+const clusterPrerequisiteCheckPeriod = 10 * time.Second
+
+// withClusterPrerequisiteErrors replaces the generic unknown kind errors of the
+// objects declared for a RepoSync with ClusterPrerequisiteErrors, since the
+// namespace reconciler cannot create the missing CustomResourceDefinitions.
+func withClusterPrerequisiteErrors(errs status.MultiError, objs []ast.FileObject) status.MultiError {
+	if errs == nil {
+		return nil
+	}
+	var result status.MultiError
+	replaced := false
+	for _, err := range errs.Errors() {
+		if err.Code() == status.UnknownKindErrorCode {
+			replaced = true
+			continue
+		}
+		result = status.Append(result, err)
+	}
+	if !replaced {
+		return errs
+	}
+	for _, obj := range objs {
+		if isUnknownScope(obj) {
+			result = status.Append(result, status.ClusterPrerequisiteError(obj.Unstructured))
+		}
+	}
+	return result
+}
+
+// clusterPrerequisites returns the CustomResourceDefinitions which the
+// skipped objects are waiting for, sorted by type.
+func clusterPrerequisites(objs []ast.FileObject) []v1beta1.ClusterPrerequisite {
+	byType := make(map[schema.GroupKind]*v1beta1.ClusterPrerequisite)
+	for _, obj := range objs {
+		gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+		prerequisite, found := byType[gk]
+		if !found {
+			prerequisite = &v1beta1.ClusterPrerequisite{
+				Kind:      kinds.CustomResourceDefinitionKind,
+				GroupKind: gk.String(),
+			}
+			byType[gk] = prerequisite
+		}
+		prerequisite.Resources = append(prerequisite.Resources, status.ToResourceRef(obj.Unstructured))
+	}
+	var result []v1beta1.ClusterPrerequisite
+	for _, prerequisite := range byType {
+		result = append(result, *prerequisite)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GroupKind < result[j].GroupKind
+	})
+	return result
+}
+
+// clusterPrerequisitesAvailable returns true if the type of any object
+// skipped by a namespace reconciler, because its CustomResourceDefinition was
+// missing, is now available on the cluster. This allows the reconciler to
+// resume syncing the objects without waiting for the retry backoff.
+func clusterPrerequisitesAvailable(p Parser, state *reconcilerState) bool {
+	opts := p.options()
+	if opts.scope == declared.RootReconciler || len(state.cache.objsSkipped) == 0 {
+		return false
+	}
+	if time.Since(state.lastClusterPrerequisiteCheck) < clusterPrerequisiteCheckPeriod {
+		return false
+	}
+	state.lastClusterPrerequisiteCheck = time.Now()
+
+	scoper, err := utildiscovery.APIResourceScoper(opts.discoveryClient())
+	if err != nil {
+		klog.Warningf("Failed to check the cluster prerequisites: %v", err)
+		return false
+	}
+	for _, obj := range state.cache.objsSkipped {
+		gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+		if _, err := scoper.GetGroupKindScope(gk); err == nil {
+			klog.Infof("The cluster prerequisite of type %s is available", gk)
+			return true
+		}
+	}
+	return false
+}
+
+func isUnknownScope(obj ast.FileObject) bool {
+	return core.GetAnnotation(obj, metadata.UnknownScopeAnnotationKey) == metadata.UnknownScopeAnnotationValue
+}
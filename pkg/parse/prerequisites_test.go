@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestClusterPrerequisites(t *testing.T) {
+	databaseGVK := schema.GroupVersionKind{Group: "operator.example.com", Version: "v1", Kind: "Database"}
+	unknownScope := core.Annotation(metadata.UnknownScopeAnnotationKey, metadata.UnknownScopeAnnotationValue)
+	db1 := fake.UnstructuredAtPath(databaseGVK, "db1.yaml", core.Name("db1"), core.Namespace("foo"), unknownScope)
+	db2 := fake.UnstructuredAtPath(databaseGVK, "db2.yaml", core.Name("db2"), core.Namespace("foo"), unknownScope)
+	role := fake.RoleAtPath("role.yaml", core.Name("role"), core.Namespace("foo"))
+	objs := []ast.FileObject{db1, role, db2}
+
+	errs := status.Append(status.UnknownObjectKindError(db1.Unstructured), status.UnknownObjectKindError(db2.Unstructured))
+	errs = status.Append(errs, status.InternalError("other"))
+	got := withClusterPrerequisiteErrors(errs, objs)
+	var codes []string
+	for _, err := range got.Errors() {
+		codes = append(codes, err.Code())
+	}
+	require.Equal(t, []string{status.InternalErrorCode, status.ClusterPrerequisiteErrorCode, status.ClusterPrerequisiteErrorCode}, codes)
+	require.False(t, status.HasBlockingErrors(status.ClusterPrerequisiteError(db1.Unstructured)))
+
+	require.Equal(t, []v1beta1.ClusterPrerequisite{{
+		Kind:      kinds.CustomResourceDefinitionKind,
+		GroupKind: "Database.operator.example.com",
+		Resources: []v1beta1.ResourceRef{status.ToResourceRef(db1.Unstructured), status.ToResourceRef(db2.Unstructured)},
+	}}, clusterPrerequisites([]ast.FileObject{db1, db2}))
+	require.Nil(t, clusterPrerequisites(nil))
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// namespaceProvisioningTemplate is the name of the ConfigMap in the
+	// config-management-system namespace, which holds the objects to create in
+	// every provisioned Namespace.
+	namespaceProvisioningTemplate = "namespace-provisioning-template"
+	// namespaceProvisioningTemplateKey is the key of the ConfigMap data which
+	// holds the objects, as a multi-document YAML.
+	namespaceProvisioningTemplateKey = "template.yaml"
+	// namespaceProvisioningPlaceholder is replaced with the name of the
+	// provisioned Namespace in the template. Namespaced objects must set their
+	// namespace to it explicitly.
+	namespaceProvisioningPlaceholder = "$(NAMESPACE)"
+)
+
+// provisionNamespaces adds the Namespaces of the declared RepoSyncs and
+// NamespaceRequests to the declared objects, along with the objects of the
+// namespace provisioning template, if the RootSync enables the namespace
+// provisioning with the `configsync.gke.io/namespace-provisioning: enabled`
+// annotation.
+//
+// The declared objects take precedence: a Namespace or template object that
+// is declared in the source of truth is not added again.
+func provisionNamespaces(ctx context.Context, c client.Reader, rsKey client.ObjectKey, objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+	rs := &v1beta1.RootSync{}
+	if err := c.Get(ctx, rsKey, rs); err != nil {
+		if apierrors.IsNotFound(err) {
+			return objs, nil
+		}
+		return nil, status.APIServerError(err, "failed to get the RootSync to check the namespace provisioning")
+	}
+	if core.GetAnnotation(rs, metadata.NamespaceProvisioningKey) != metadata.NamespaceProvisioningEnabled {
+		return objs, nil
+	}
+
+	requests := namespaceRequests(objs)
+	if len(requests) == 0 {
+		return objs, nil
+	}
+
+	template := &corev1.ConfigMap{}
+	templateKey := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: namespaceProvisioningTemplate}
+	if err := c.Get(ctx, templateKey, template); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, status.APIServerError(err, "failed to get the namespace provisioning template")
+		}
+		klog.V(3).Infof("The namespace provisioning template %s is not found, only provisioning the Namespaces", templateKey)
+		template = nil
+	} else {
+		template.SetGroupVersionKind(kinds.ConfigMap())
+	}
+
+	declared := make(map[core.ID]bool, len(objs))
+	for _, obj := range objs {
+		declared[core.IDOf(obj)] = true
+	}
+
+	var names []string
+	for ns := range requests {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	var errs status.MultiError
+	for _, ns := range names {
+		var provisioned []*unstructured.Unstructured
+		provisioned = append(provisioned, provisionedNamespace(ns, requests[ns]))
+		if template != nil {
+			templateObjs, err := renderNamespaceTemplate(template.Data[namespaceProvisioningTemplateKey], ns)
+			if err != nil {
+				errs = status.Append(errs, status.NamespaceProvisioningError(template, ns, err))
+				continue
+			}
+			provisioned = append(provisioned, templateObjs...)
+		}
+		for _, u := range provisioned {
+			id := core.IDOf(u)
+			if declared[id] {
+				continue
+			}
+			declared[id] = true
+			objs = append(objs, ast.NewFileObject(u, cmpath.RelativeOS("")))
+		}
+	}
+	return objs, errs
+}
+
+// namespaceRequests returns the specs of the Namespaces requested by the
+// declared objects, keyed by the Namespace name. The spec is nil for the
+// Namespaces of RepoSyncs.
+func namespaceRequests(objs []ast.FileObject) map[string]*v1beta1.NamespaceRequestSpec {
+	requests := make(map[string]*v1beta1.NamespaceRequestSpec)
+	for _, obj := range objs {
+		switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+		case kinds.RepoSyncV1Beta1().GroupKind():
+			if _, found := requests[obj.GetNamespace()]; !found {
+				requests[obj.GetNamespace()] = nil
+			}
+		case kinds.NamespaceRequest().GroupKind():
+			spec := &v1beta1.NamespaceRequestSpec{}
+			spec.Labels, _, _ = unstructured.NestedStringMap(obj.Unstructured.Object, "spec", "labels")
+			spec.Annotations, _, _ = unstructured.NestedStringMap(obj.Unstructured.Object, "spec", "annotations")
+			requests[obj.GetName()] = spec
+		}
+	}
+	return requests
+}
+
+// provisionedNamespace returns the Namespace to provision for the request.
+func provisionedNamespace(name string, request *v1beta1.NamespaceRequestSpec) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(kinds.Namespace())
+	u.SetName(name)
+	if request != nil {
+		u.SetLabels(request.Labels)
+		for k, v := range request.Annotations {
+			core.SetAnnotation(u, k, v)
+		}
+	}
+	// As with the implicit Namespaces, the provisioned Namespaces are not
+	// deleted when their RepoSyncs or NamespaceRequests are removed, because
+	// deleting a tenant Namespace deletes all the objects in it.
+	core.SetAnnotation(u, common.LifecycleDeleteAnnotation, common.PreventDeletion)
+	return u
+}
+
+// renderNamespaceTemplate replaces the placeholder in the template with the
+// Namespace name, and decodes the objects of the template.
+func renderNamespaceTemplate(template, namespace string) ([]*unstructured.Unstructured, error) {
+	rendered := strings.ReplaceAll(template, namespaceProvisioningPlaceholder, namespace)
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(rendered), 4096)
+	var result []*unstructured.Unstructured
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, errors.Wrap(err, "decoding the template")
+		}
+		if len(u.Object) == 0 {
+			// Skip the empty documents.
+			continue
+		}
+		if u.GetKind() == "" || u.GetName() == "" {
+			return nil, errors.Errorf("the template object %v must have a kind and a name", u.Object)
+		}
+		result = append(result, u)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testNamespaceTemplate = `
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: tenant-quota
+  namespace: $(NAMESPACE)
+spec:
+  hard:
+    pods: "10"
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ns-reconciler
+  namespace: $(NAMESPACE)
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: admin
+subjects:
+- kind: ServiceAccount
+  name: ns-reconciler-$(NAMESPACE)
+  namespace: config-management-system
+`
+
+func TestProvisionNamespaces(t *testing.T) {
+	enabled := core.Annotation(metadata.NamespaceProvisioningKey, metadata.NamespaceProvisioningEnabled)
+	template := fake.ConfigMapObject(core.Name(namespaceProvisioningTemplate), core.Namespace(configsync.ControllerNamespace))
+	template.Data = map[string]string{namespaceProvisioningTemplateKey: testNamespaceTemplate}
+	invalidTemplate := template.DeepCopy()
+	invalidTemplate.Data = map[string]string{namespaceProvisioningTemplateKey: "kind: ResourceQuota"}
+
+	namespaceRequest := fake.Unstructured(kinds.NamespaceRequest(), core.Name("frontend"))
+	require.NoError(t, unstructured.SetNestedStringMap(namespaceRequest.Object, map[string]string{"team": "web"}, "spec", "labels"))
+
+	testCases := []struct {
+		name      string
+		objs      []client.Object
+		declared  []ast.FileObject
+		wantAdded []core.ID
+		wantCodes []string
+	}{
+		{
+			name:     "disabled",
+			objs:     []client.Object{fake.RootSyncObjectV1Beta1(rootSyncName), template},
+			declared: []ast.FileObject{fake.RepoSyncV1Beta1("bookstore", "repo-sync")},
+		},
+		{
+			name:     "RepoSync without template",
+			objs:     []client.Object{fake.RootSyncObjectV1Beta1(rootSyncName, enabled)},
+			declared: []ast.FileObject{fake.RepoSyncV1Beta1("bookstore", "repo-sync")},
+			wantAdded: []core.ID{
+				{GroupKind: kinds.Namespace().GroupKind(), ObjectKey: client.ObjectKey{Name: "bookstore"}},
+			},
+		},
+		{
+			name:     "RepoSync and NamespaceRequest with template",
+			objs:     []client.Object{fake.RootSyncObjectV1Beta1(rootSyncName, enabled), template},
+			declared: []ast.FileObject{fake.RepoSyncV1Beta1("bookstore", "repo-sync"), namespaceRequest},
+			wantAdded: []core.ID{
+				{GroupKind: kinds.Namespace().GroupKind(), ObjectKey: client.ObjectKey{Name: "bookstore"}},
+				{GroupKind: kinds.ResourceQuota().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "bookstore", Name: "tenant-quota"}},
+				{GroupKind: kinds.RoleBinding().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "bookstore", Name: "ns-reconciler"}},
+				{GroupKind: kinds.Namespace().GroupKind(), ObjectKey: client.ObjectKey{Name: "frontend"}},
+				{GroupKind: kinds.ResourceQuota().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "frontend", Name: "tenant-quota"}},
+				{GroupKind: kinds.RoleBinding().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "frontend", Name: "ns-reconciler"}},
+			},
+		},
+		{
+			name: "declared objects take precedence",
+			objs: []client.Object{fake.RootSyncObjectV1Beta1(rootSyncName, enabled), template},
+			declared: []ast.FileObject{
+				fake.RepoSyncV1Beta1("bookstore", "repo-sync"),
+				fake.Namespace("namespaces/bookstore"),
+				fake.Unstructured(kinds.ResourceQuota(), core.Name("tenant-quota"), core.Namespace("bookstore")),
+			},
+			wantAdded: []core.ID{
+				{GroupKind: kinds.RoleBinding().GroupKind(), ObjectKey: client.ObjectKey{Namespace: "bookstore", Name: "ns-reconciler"}},
+			},
+		},
+		{
+			name:      "invalid template",
+			objs:      []client.Object{fake.RootSyncObjectV1Beta1(rootSyncName, enabled), invalidTemplate},
+			declared:  []ast.FileObject{fake.RepoSyncV1Beta1("bookstore", "repo-sync")},
+			wantCodes: []string{status.NamespaceProvisioningErrorCode},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := syncerFake.NewClient(t, core.Scheme, tc.objs...)
+			objs, errs := provisionNamespaces(context.Background(), c, rootsync.ObjectKey(rootSyncName), tc.declared)
+			var gotCodes []string
+			if errs != nil {
+				for _, err := range errs.Errors() {
+					gotCodes = append(gotCodes, err.Code())
+				}
+			}
+			require.Equal(t, tc.wantCodes, gotCodes)
+
+			var gotAdded []core.ID
+			for _, obj := range objs[len(tc.declared):] {
+				gotAdded = append(gotAdded, core.IDOf(obj))
+			}
+			require.Equal(t, tc.wantAdded, gotAdded)
+			for _, obj := range objs[len(tc.declared):] {
+				if obj.GetObjectKind().GroupVersionKind() != kinds.Namespace() {
+					continue
+				}
+				require.Equal(t, common.PreventDeletion, core.GetAnnotation(obj, common.LifecycleDeleteAnnotation))
+				if obj.GetName() == "frontend" {
+					require.Equal(t, "web", core.GetLabel(obj, "team"))
+				}
+			}
+		})
+	}
+}
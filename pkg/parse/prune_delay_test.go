@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneDelayExpired(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	p := newParser(t, FileSource{})
+	require.False(t, pruneDelayExpired(p, now), "no object is pending prune")
+
+	p.options().applier.(*fakeApplier).pendingPruneExpiry = now.Add(time.Minute)
+	require.False(t, pruneDelayExpired(p, now))
+	require.True(t, pruneDelayExpired(p, now.Add(time.Minute)))
+}
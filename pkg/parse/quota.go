@@ -0,0 +1,99 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// enforceRepoSyncQuotas returns an error for each limit of the RepoSyncQuotas
+// in the namespace that is exceeded by the declared objects.
+//
+// RepoSyncQuotas are optional, so no error is returned if the RepoSyncQuota
+// CRD is not installed.
+func enforceRepoSyncQuotas(ctx context.Context, c client.Reader, namespace string, objs []ast.FileObject) status.MultiError {
+	quotaList := &v1beta1.RepoSyncQuotaList{}
+	if err := c.List(ctx, quotaList, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil
+		}
+		return status.APIServerError(err, "failed to list RepoSyncQuotas")
+	}
+
+	var errs status.MultiError
+	for i := range quotaList.Items {
+		quota := &quotaList.Items[i]
+		quota.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(configsync.RepoSyncQuotaKind))
+		errs = status.Append(errs, checkRepoSyncQuota(quota, objs))
+	}
+	return errs
+}
+
+// checkRepoSyncQuota returns an error for each limit of the quota that is
+// exceeded by the declared objects.
+func checkRepoSyncQuota(quota *v1beta1.RepoSyncQuota, objs []ast.FileObject) status.MultiError {
+	var errs status.MultiError
+	spec := quota.Spec
+
+	if spec.MaxObjects != nil && int64(len(objs)) > *spec.MaxObjects {
+		errs = status.Append(errs, status.RepoSyncQuotaExceededError(quota,
+			fmt.Sprintf("%d objects are declared, but maxObjects is %d", len(objs), *spec.MaxObjects)))
+	}
+
+	if spec.MaxTotalSize != nil {
+		var totalSize int64
+		for _, obj := range objs {
+			data, err := json.Marshal(obj.Unstructured)
+			if err != nil {
+				errs = status.Append(errs, status.InternalErrorBuilder.Wrap(err).
+					Sprint("failed to encode the declared object").BuildWithResources(obj))
+				continue
+			}
+			totalSize += int64(len(data))
+		}
+		if totalSize > spec.MaxTotalSize.Value() {
+			errs = status.Append(errs, status.RepoSyncQuotaExceededError(quota,
+				fmt.Sprintf("the total size of the declared objects is %s, but maxTotalSize is %s",
+					resource.NewQuantity(totalSize, resource.BinarySI), spec.MaxTotalSize)))
+		}
+	}
+
+	if len(spec.ForbiddenKinds) > 0 {
+		forbidden := make(map[schema.GroupKind]bool, len(spec.ForbiddenKinds))
+		for _, gk := range spec.ForbiddenKinds {
+			forbidden[schema.GroupKind{Group: gk.Group, Kind: gk.Kind}] = true
+		}
+		for _, obj := range objs {
+			gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+			if forbidden[gk] {
+				errs = status.Append(errs, status.RepoSyncQuotaExceededError(quota,
+					fmt.Sprintf("objects of kind %s are forbidden", gk.String()), obj))
+			}
+		}
+	}
+	return errs
+}
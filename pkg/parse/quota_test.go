@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func repoSyncQuota(name, namespace string, spec v1beta1.RepoSyncQuotaSpec) *v1beta1.RepoSyncQuota {
+	quota := &v1beta1.RepoSyncQuota{Spec: spec}
+	quota.Name = name
+	quota.Namespace = namespace
+	return quota
+}
+
+func TestEnforceRepoSyncQuotas(t *testing.T) {
+	objs := []ast.FileObject{
+		fake.Unstructured(kinds.ConfigMap(), core.Name("cm-1"), core.Namespace("bookstore")),
+		fake.Unstructured(kinds.ConfigMap(), core.Name("cm-2"), core.Namespace("bookstore")),
+		fake.Unstructured(kinds.Role(), core.Name("role"), core.Namespace("bookstore")),
+	}
+	maxTotalSize := resource.MustParse("100")
+
+	testCases := []struct {
+		name      string
+		quotas    []client.Object
+		wantCodes []string
+	}{
+		{
+			name: "no quotas",
+		},
+		{
+			name: "within the limits",
+			quotas: []client.Object{
+				repoSyncQuota("quota", "bookstore", v1beta1.RepoSyncQuotaSpec{
+					MaxObjects:     pointer.Int64(3),
+					ForbiddenKinds: []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+				}),
+			},
+		},
+		{
+			name: "quotas in other namespaces are ignored",
+			quotas: []client.Object{
+				repoSyncQuota("quota", "shipping", v1beta1.RepoSyncQuotaSpec{MaxObjects: pointer.Int64(1)}),
+			},
+		},
+		{
+			name: "too many objects",
+			quotas: []client.Object{
+				repoSyncQuota("quota", "bookstore", v1beta1.RepoSyncQuotaSpec{MaxObjects: pointer.Int64(2)}),
+			},
+			wantCodes: []string{status.RepoSyncQuotaExceededErrorCode},
+		},
+		{
+			name: "total size too large",
+			quotas: []client.Object{
+				repoSyncQuota("quota", "bookstore", v1beta1.RepoSyncQuotaSpec{MaxTotalSize: &maxTotalSize}),
+			},
+			wantCodes: []string{status.RepoSyncQuotaExceededErrorCode},
+		},
+		{
+			name: "forbidden kind",
+			quotas: []client.Object{
+				repoSyncQuota("quota", "bookstore", v1beta1.RepoSyncQuotaSpec{
+					ForbiddenKinds: []metav1.GroupKind{{Group: "rbac.authorization.k8s.io", Kind: "Role"}},
+				}),
+			},
+			wantCodes: []string{status.RepoSyncQuotaExceededErrorCode},
+		},
+		{
+			name: "multiple quotas",
+			quotas: []client.Object{
+				repoSyncQuota("quota-1", "bookstore", v1beta1.RepoSyncQuotaSpec{MaxObjects: pointer.Int64(1)}),
+				repoSyncQuota("quota-2", "bookstore", v1beta1.RepoSyncQuotaSpec{
+					ForbiddenKinds: []metav1.GroupKind{{Kind: "ConfigMap"}},
+				}),
+			},
+			wantCodes: []string{
+				status.RepoSyncQuotaExceededErrorCode,
+				status.RepoSyncQuotaExceededErrorCode,
+				status.RepoSyncQuotaExceededErrorCode,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := syncerFake.NewClient(t, core.Scheme, tc.quotas...)
+			errs := enforceRepoSyncQuotas(context.Background(), c, "bookstore", objs)
+			var gotCodes []string
+			if errs != nil {
+				for _, err := range errs.Errors() {
+					gotCodes = append(gotCodes, err.Code())
+				}
+			}
+			require.Equal(t, tc.wantCodes, gotCodes)
+		})
+	}
+}
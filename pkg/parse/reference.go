@@ -0,0 +1,160 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isReferenceOnly returns true if the declared object is a reference-only
+// object, which is owned by another system.
+func isReferenceOnly(obj client.Object) bool {
+	return core.GetAnnotation(obj, metadata.ReferenceOnlyKey) == metadata.ReferenceOnlyEnabled
+}
+
+// references verifies that the reference-only objects exist on the cluster
+// and match their declarations, and returns the objects to declare, with the
+// reference-only objects marked as management disabled.
+//
+// Objects with management disabled are neither applied nor pruned, and the
+// remediator leaves them alone. If a reference-only object was previously
+// managed, the applier removes it from the inventory and strips the Config
+// Sync metadata from it, which releases its ownership.
+//
+// Each missing or mismatched object is returned as a ReferenceMismatchError,
+// which keeps the commit from being marked as synced until it is fixed.
+func (u *updater) references(ctx context.Context, objs []client.Object) ([]client.Object, status.MultiError, status.Error) {
+	if u.referenceReader == nil {
+		return objs, nil, nil
+	}
+
+	var errs status.MultiError
+	result := make([]client.Object, 0, len(objs))
+	for _, obj := range objs {
+		if !isReferenceOnly(obj) {
+			result = append(result, obj)
+			continue
+		}
+		declaredObj, err := reconcile.AsUnstructuredSanitized(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		mismatch, getErr := u.verifyReference(ctx, declaredObj)
+		if getErr != nil {
+			return nil, nil, getErr
+		}
+		if mismatch != "" {
+			errs = status.Append(errs, status.ReferenceMismatchError(mismatch, obj))
+		}
+		core.SetAnnotation(declaredObj, metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)
+		result = append(result, declaredObj)
+	}
+	if errs != nil {
+		klog.Warningf("Found %d reference-only object(s) which do not match the cluster", len(errs.Errors()))
+	}
+	return result, errs, nil
+}
+
+// verifyReference compares the reference-only object with the object on the
+// cluster, and returns the reason of the mismatch, or an empty string if they
+// match.
+func (u *updater) verifyReference(ctx context.Context, declaredObj *unstructured.Unstructured) (string, status.Error) {
+	id := core.IDOf(declaredObj)
+	liveObj := &unstructured.Unstructured{}
+	liveObj.SetGroupVersionKind(declaredObj.GroupVersionKind())
+	if err := u.referenceReader.Get(ctx, client.ObjectKeyFromObject(declaredObj), liveObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("%s does not exist on the cluster", id), nil
+		}
+		return "", status.APIServerErrorf(err, "failed to get the reference-only object %s", id)
+	}
+
+	expected := declaredObj.DeepCopy()
+	metadata.RemoveConfigSyncMetadata(expected)
+	if paths := core.GetAnnotation(declaredObj, metadata.ReferenceFieldsKey); paths != "" {
+		for _, path := range strings.Split(paths, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			fields := strings.Split(path, ".")
+			want, found, _ := unstructured.NestedFieldNoCopy(expected.Object, fields...)
+			if !found {
+				return fmt.Sprintf("%s does not declare the verified field %s", id, path), nil
+			}
+			got, found, _ := unstructured.NestedFieldNoCopy(liveObj.Object, fields...)
+			if !found || !fieldSubset(want, got) {
+				return fmt.Sprintf("%s does not match the declared field %s on the cluster", id, path), nil
+			}
+		}
+		return "", nil
+	}
+
+	for field, want := range expected.Object {
+		switch field {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			for _, metaField := range []string{"labels", "annotations"} {
+				want, found, _ := unstructured.NestedFieldNoCopy(expected.Object, "metadata", metaField)
+				if !found {
+					continue
+				}
+				got, _, _ := unstructured.NestedFieldNoCopy(liveObj.Object, "metadata", metaField)
+				if !fieldSubset(want, got) {
+					return fmt.Sprintf("%s does not match the declared metadata.%s on the cluster", id, metaField), nil
+				}
+			}
+		default:
+			if !fieldSubset(want, liveObj.Object[field]) {
+				return fmt.Sprintf("%s does not match the declared field %s on the cluster", id, field), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// fieldSubset returns true if the declared value is set on the live value.
+// Maps are compared recursively, so that the fields defaulted or set by other
+// clients on the live object are ignored. Other values must be equal.
+func fieldSubset(want, got interface{}) bool {
+	wantMap, ok := want.(map[string]interface{})
+	if !ok {
+		return equality.Semantic.DeepEqual(want, got)
+	}
+	gotMap, ok := got.(map[string]interface{})
+	if !ok {
+		return len(wantMap) == 0
+	}
+	for k, v := range wantMap {
+		gotValue, found := gotMap[k]
+		if !found || !fieldSubset(v, gotValue) {
+			return false
+		}
+	}
+	return true
+}
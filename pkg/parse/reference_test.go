@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestUpdaterReferences(t *testing.T) {
+	referenceOnly := core.Annotation(metadata.ReferenceOnlyKey, metadata.ReferenceOnlyEnabled)
+	live := fake.ConfigMapObject(core.Name("shared"), core.Namespace("platform"),
+		core.Label("team", "platform"), core.Label("tier", "shared"))
+
+	testCases := []struct {
+		name     string
+		obj      client.Object
+		wantErrs bool
+	}{
+		{
+			name: "matching reference",
+			obj: fake.ConfigMapObject(core.Name("shared"), core.Namespace("platform"), referenceOnly,
+				core.Label("team", "platform")),
+		},
+		{
+			name: "mismatched reference",
+			obj: fake.ConfigMapObject(core.Name("shared"), core.Namespace("platform"), referenceOnly,
+				core.Label("team", "apps")),
+			wantErrs: true,
+		},
+		{
+			name: "mismatch outside of the verified fields",
+			obj: fake.ConfigMapObject(core.Name("shared"), core.Namespace("platform"), referenceOnly,
+				core.Annotation(metadata.ReferenceFieldsKey, "metadata.labels.tier"),
+				core.Label("team", "apps"), core.Label("tier", "shared")),
+		},
+		{
+			name:     "missing reference",
+			obj:      fake.ConfigMapObject(core.Name("missing"), core.Namespace("platform"), referenceOnly),
+			wantErrs: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			managed := fake.ConfigMapObject(core.Name("managed"), core.Namespace("platform"))
+			u := &updater{
+				scope:           declared.RootReconciler,
+				referenceReader: syncerFake.NewClient(t, core.Scheme, live),
+			}
+			objs, errs, err := u.references(context.Background(), []client.Object{managed, tc.obj})
+			require.NoError(t, err)
+			if tc.wantErrs {
+				require.Len(t, errs.Errors(), 1)
+				require.Equal(t, status.ReferenceMismatchErrorCode, errs.Errors()[0].(status.Error).Code())
+			} else {
+				require.Nil(t, errs)
+			}
+			require.Len(t, objs, 2)
+			require.False(t, differ.ManagementDisabled(objs[0]))
+			require.True(t, differ.ManagementDisabled(objs[1]), "reference-only objects must not be applied")
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/metadata"
+)
+
+// resetCacheOnRequest resets the caches of the reconciler, if the
+// `configsync.gke.io/reset-cache` annotation of the RootSync or RepoSync
+// changed since the caches were last reset, so that the current commit is
+// read, parsed and applied again from scratch. The hydration-controller purges
+// its rendered configs on the same annotation.
+//
+// The value observed when the reconciler starts is not acted upon, since the
+// caches are empty then.
+func resetCacheOnRequest(p Parser, state *reconcilerState, annotations map[string]string) {
+	token := annotations[metadata.ResetCacheKey]
+	if !state.resetCacheTokenSeen {
+		state.resetCacheToken = token
+		state.resetCacheTokenSeen = true
+		return
+	}
+	if token == "" || token == state.resetCacheToken {
+		return
+	}
+	state.resetCacheToken = token
+	klog.Infof("Resetting the caches, as requested by the %s annotation: %s", metadata.ResetCacheKey, token)
+	// Unlike resetAllButSourceState, the files are read again too.
+	state.resetCache()
+	state.lastApplied = ""
+	opts := p.options()
+	opts.currentSyncDir = ""
+	opts.verifiedHydratedDir = ""
+	opts.parsedFiles = nil
+}
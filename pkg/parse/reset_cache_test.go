@@ -0,0 +1,63 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/metadata"
+)
+
+func TestResetCacheOnRequest(t *testing.T) {
+	p := newParser(t, FileSource{}).(*root)
+	state := &reconcilerState{}
+	fillCaches := func() {
+		state.lastApplied = "/repo/source/abc123"
+		state.cache.source = sourceState{commit: "abc123", syncDir: cmpath.Absolute("/repo/source/abc123")}
+		state.cache.hasParserResult = true
+		p.currentSyncDir = "/repo/source/abc123"
+		p.parsedFiles = map[string]parsedFile{"ns.yaml": {digest: "1234"}}
+	}
+	requireReset := func(t *testing.T, want bool) {
+		t.Helper()
+		require.Equal(t, want, state.cache.source.commit == "")
+		require.Equal(t, want, state.lastApplied == "")
+		require.Equal(t, want, !state.cache.hasParserResult)
+		require.Equal(t, want, p.currentSyncDir == "")
+		require.Equal(t, want, p.parsedFiles == nil)
+	}
+
+	// The token observed when the reconciler starts is not acted upon.
+	fillCaches()
+	resetCacheOnRequest(p, state, map[string]string{metadata.ResetCacheKey: "t1"})
+	requireReset(t, false)
+
+	// An unchanged token does not reset the caches.
+	resetCacheOnRequest(p, state, map[string]string{metadata.ResetCacheKey: "t1"})
+	requireReset(t, false)
+
+	// A removed token does not reset the caches.
+	resetCacheOnRequest(p, state, nil)
+	requireReset(t, false)
+
+	// A new token resets the caches once.
+	resetCacheOnRequest(p, state, map[string]string{metadata.ResetCacheKey: "t2"})
+	requireReset(t, true)
+	fillCaches()
+	resetCacheOnRequest(p, state, map[string]string{metadata.ResetCacheKey: "t2"})
+	requireReset(t, false)
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"path"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/snapshot"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restoreDir is the pseudo sync directory of the restored snapshots. It is
+// never read, but it tells the restored commits apart from the source
+// commits in the reconciler state.
+const restoreDir = "/snapshots"
+
+// syncNamespace returns the namespace of the RootSync or RepoSync, where the
+// snapshots of the reconciler are stored.
+func (o *opts) syncNamespace() string {
+	if o.scope == declared.RootReconciler {
+		return configsync.ControllerNamespace
+	}
+	return string(o.scope)
+}
+
+// getRSync returns the RootSync or RepoSync of the reconciler, with its
+// GroupVersionKind set.
+func getRSync(ctx context.Context, o *opts) (client.Object, error) {
+	var rs client.Object
+	var key client.ObjectKey
+	var gvk schema.GroupVersionKind
+	if o.scope == declared.RootReconciler {
+		rs = &v1beta1.RootSync{}
+		key = rootsync.ObjectKey(o.syncName)
+		gvk = kinds.RootSyncV1Beta1()
+	} else {
+		rs = &v1beta1.RepoSync{}
+		key = reposync.ObjectKey(o.scope, o.syncName)
+		gvk = kinds.RepoSyncV1Beta1()
+	}
+	if err := o.k8sClient().Get(ctx, key, rs); err != nil {
+		return nil, err
+	}
+	// The client clears the GroupVersionKind of typed objects.
+	rs.GetObjectKind().SetGroupVersionKind(gvk)
+	return rs, nil
+}
+
+// getRSyncAnnotations returns the annotations of the RootSync or RepoSync,
+// which carry the requests of users, e.g. to restore the snapshot of a commit
+// or to pause the sync. Returns nil if the RSync is not found.
+func getRSyncAnnotations(ctx context.Context, p Parser) (map[string]string, status.Error) {
+	rs, err := getRSync(ctx, p.options())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, status.APIServerError(err, "failed to get the RSync to check for a restore or a pause")
+	}
+	return rs.GetAnnotations(), nil
+}
+
+// restore applies the snapshot of a previous commit instead of the source,
+// as requested by the `configsync.gke.io/restore-commit` annotation.
+func restore(ctx context.Context, p Parser, trigger string, state *reconcilerState, commit string) {
+	opts := p.options()
+	syncDir := cmpath.Absolute(path.Join(restoreDir, commit))
+	if state.cache.source.syncDir == syncDir && state.cache.hasParserResult {
+		// The parse-apply-watch sequence is skipped for the same reasons as
+		// when reading the source.
+		if trigger == triggerReimport || trigger == triggerExternal {
+			return
+		}
+	} else {
+		snap, err := snapshot.Load(ctx, opts.k8sClient(), opts.syncNamespace(), opts.reconcilerName, commit)
+		if err != nil {
+			errs := status.SourceError.Wrap(err).
+				Sprintf("failed to load the snapshot of commit %s to restore", commit).Build()
+			gs := sourceStatus{commit: commit, errs: errs, lastUpdate: metav1.Now()}
+			var setSourceStatusErr error
+			if state.needToSetSourceStatus(gs) {
+				setSourceStatusErr = p.setSourceStatus(ctx, gs)
+				if setSourceStatusErr == nil {
+					state.sourceStatus = gs
+					state.syncingConditionLastUpdate = gs.lastUpdate
+				}
+			}
+			state.invalidate(ctx, status.Append(errs, setSourceStatusErr))
+			return
+		}
+		klog.Infof("Restoring the snapshot of commit %s with %d objects", commit, len(snap.Objects))
+		state.resetCache()
+		state.cache.source = sourceState{commit: commit, syncDir: syncDir}
+		state.cache.setParserResult(snapshotFileObjects(snap.Objects), nil)
+	}
+
+	if errs := parseAndUpdate(ctx, p, trigger, state); errs != nil {
+		state.invalidate(ctx, errs)
+		return
+	}
+	state.checkpoint()
+}
+
+// saveSnapshot saves the snapshot of the objects applied for the commit in the
+// reconciler state, if it has not been saved yet. Failing to save a snapshot
+// does not fail the reconciliation.
+func saveSnapshot(ctx context.Context, p Parser, state *reconcilerState) {
+	commit := state.cache.source.commit
+	if commit == "" || commit == state.lastSnapshot {
+		return
+	}
+	opts := p.options()
+	objs := make([]*unstructured.Unstructured, len(state.cache.objsToApply))
+	for i, obj := range state.cache.objsToApply {
+		objs[i] = obj.Unstructured
+	}
+	if err := snapshot.Save(ctx, opts.k8sClient(), opts.syncNamespace(), opts.reconcilerName, commit, objs, time.Now()); err != nil {
+		klog.Warningf("Failed to save the snapshot of commit %s: %v", commit, err)
+		return
+	}
+	klog.V(3).Infof("Saved the snapshot of commit %s", commit)
+	state.lastSnapshot = commit
+}
+
+// snapshotFileObjects converts the objects of a snapshot back to the parser
+// results they were saved from.
+func snapshotFileObjects(objs []*unstructured.Unstructured) []ast.FileObject {
+	fileObjs := make([]ast.FileObject, len(objs))
+	for i, obj := range objs {
+		fileObjs[i] = ast.NewFileObject(obj, cmpath.RelativeSlash(core.GetAnnotation(obj, metadata.SourcePathAnnotationKey)))
+	}
+	return fileObjs
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/resync"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectsResyncedReason is the reason of the event recorded on a RootSync or
+// RepoSync after a selective resync.
+const ObjectsResyncedReason = "ObjectsResynced"
+
+// resyncObjects re-applies the declared objects selected by the
+// `configsync.gke.io/resync-objects` annotation of the RootSync or RepoSync
+// from the current commit, if the `configsync.gke.io/resync-requested-at`
+// annotation changed since the last selective resync.
+//
+// Unlike a force-resync, it doesn't reset the cache, so the other declared
+// objects are neither re-parsed nor re-applied.
+func resyncObjects(ctx context.Context, p Parser, state *reconcilerState) error {
+	opts := p.options()
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	requestedAt := core.GetAnnotation(rs, metadata.ResyncRequestedAtKey)
+	if requestedAt == "" || requestedAt == state.lastResyncRequest {
+		return nil
+	}
+	// Wait for the ongoing sync, which applies the objects anyway.
+	if p.Syncing() {
+		return nil
+	}
+	// Don't retry an invalid request until it changes.
+	state.lastResyncRequest = requestedAt
+
+	targets, err := resync.ParseTargets(core.GetAnnotation(rs, metadata.ResyncObjectsKey))
+	if err != nil {
+		recordResyncEvent(ctx, opts, rs, corev1.EventTypeWarning, fmt.Sprintf(
+			"Ignored the resync requested at %s: %v", requestedAt, err))
+		return err
+	}
+
+	objs, commit := opts.resources.DeclaredUnstructureds()
+	var resynced, failed []string
+	for _, obj := range objs {
+		if differ.ManagementDisabled(obj) || !resync.MatchesAny(targets, obj) {
+			continue
+		}
+		id := core.IDOf(obj).String()
+		// Use server-side apply with the field manager of the applier, so
+		// that the resync is indistinguishable from a regular apply.
+		err := opts.k8sClient().Patch(ctx, obj.DeepCopy(), client.Apply,
+			client.FieldOwner(configsync.FieldManager), client.ForceOwnership)
+		if err != nil {
+			klog.Warningf("Failed to resync %s: %v", id, err)
+			failed = append(failed, id)
+			continue
+		}
+		resynced = append(resynced, id)
+	}
+	klog.Infof("Resynced %d objects from commit %q, as requested at %s (%d failed)",
+		len(resynced), commit, requestedAt, len(failed))
+
+	eventType := corev1.EventTypeNormal
+	message := fmt.Sprintf("Resynced %d objects from commit %q, as requested at %s",
+		len(resynced), commit, requestedAt)
+	if len(resynced) == 0 && len(failed) == 0 {
+		eventType = corev1.EventTypeWarning
+		message = fmt.Sprintf("No declared object matches the resync requested at %s: %s",
+			requestedAt, core.GetAnnotation(rs, metadata.ResyncObjectsKey))
+	} else if len(failed) > 0 {
+		eventType = corev1.EventTypeWarning
+		message += fmt.Sprintf("; failed to resync %s", strings.Join(failed, ", "))
+	}
+	recordResyncEvent(ctx, opts, rs, eventType, message)
+	return nil
+}
+
+// recordResyncEvent records the outcome of a selective resync as an event of
+// the RootSync or RepoSync.
+func recordResyncEvent(ctx context.Context, opts *opts, rs client.Object, eventType, message string) {
+	e := event.New(event.Reference(rs, rs.GetObjectKind().GroupVersionKind()), eventType, ObjectsResyncedReason, message,
+		opts.reconcilerName, metav1.Now())
+	if err := opts.k8sClient().Create(ctx, e); err != nil {
+		klog.Warningf("Failed to record the %s event: %v", ObjectsResyncedReason, err)
+	}
+}
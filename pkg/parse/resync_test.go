@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/syncer/syncertest"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestResyncObjects(t *testing.T) {
+	declaredA := fake.ConfigMapObject(core.Name("a"), core.Namespace("foo"), syncertest.ManagementEnabled,
+		core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/a.yaml"))
+	declaredA.Data = map[string]string{"key": "declared"}
+	declaredB := fake.ConfigMapObject(core.Name("b"), core.Namespace("foo"), syncertest.ManagementEnabled,
+		core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/bar/b.yaml"))
+	driftedA := declaredA.DeepCopy()
+	driftedA.Data = map[string]string{"key": "drifted"}
+
+	testCases := []struct {
+		name        string
+		targets     string
+		wantA       string
+		wantBExists bool
+		wantEvent   string
+	}{
+		{
+			name:      "resync an object",
+			targets:   "configmap/foo/a",
+			wantA:     "declared",
+			wantEvent: corev1.EventTypeNormal,
+		},
+		{
+			name:        "resync a directory",
+			targets:     "dir:namespaces/bar",
+			wantA:       "drifted",
+			wantBExists: true,
+			wantEvent:   corev1.EventTypeNormal,
+		},
+		{
+			name:      "no matching object",
+			targets:   "configmap/foo/c",
+			wantA:     "drifted",
+			wantEvent: corev1.EventTypeWarning,
+		},
+		{
+			name:      "invalid target",
+			targets:   "configmap",
+			wantA:     "drifted",
+			wantEvent: corev1.EventTypeWarning,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := fake.RootSyncObjectV1Beta1(rootSyncName,
+				core.Annotation(metadata.ResyncObjectsKey, tc.targets),
+				core.Annotation(metadata.ResyncRequestedAtKey, "2022-10-01T00:00:00Z"))
+			p := newParser(t, FileSource{}).(*root)
+			p.client = syncerFake.NewClient(t, core.Scheme, rs, driftedA.DeepCopy())
+			_, updateErr := p.resources.Update(context.Background(), []client.Object{declaredA.DeepCopy(), declaredB.DeepCopy()}, "abc123")
+			require.NoError(t, updateErr)
+
+			state := &reconcilerState{}
+			_ = resyncObjects(context.Background(), p, state)
+			require.Equal(t, "2022-10-01T00:00:00Z", state.lastResyncRequest)
+
+			gotA := &corev1.ConfigMap{}
+			require.NoError(t, p.client.Get(context.Background(), client.ObjectKeyFromObject(declaredA), gotA))
+			require.Equal(t, tc.wantA, gotA.Data["key"])
+			err := p.client.Get(context.Background(), client.ObjectKeyFromObject(declaredB), &corev1.ConfigMap{})
+			require.Equal(t, tc.wantBExists, !apierrors.IsNotFound(err))
+
+			events := &corev1.EventList{}
+			require.NoError(t, p.client.List(context.Background(), events))
+			require.Len(t, events.Items, 1)
+			require.Equal(t, ObjectsResyncedReason, events.Items[0].Reason)
+			require.Equal(t, tc.wantEvent, events.Items[0].Type)
+
+			// The objects are not resynced again until the request changes.
+			require.NoError(t, p.client.Update(context.Background(), driftedA.DeepCopy()))
+			require.NoError(t, resyncObjects(context.Background(), p, state))
+			require.NoError(t, p.client.Get(context.Background(), client.ObjectKeyFromObject(declaredA), gotA))
+			require.Equal(t, "drifted", gotA.Data["key"])
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// retriesExhaustedFor returns true if the commit in the cache failed more times
+// than the retry budget allows.
+func (s *reconcilerState) retriesExhaustedFor(retryBudget int) bool {
+	// The first attempt is not a retry.
+	return retryBudget > 0 && s.cache.needToRetry && s.cache.failures > retryBudget
+}
+
+// resetRetryBudget gives the commit in the cache a new retry budget, after a
+// sync request, so that a commit whose retries are exhausted is retried
+// without a new commit.
+func resetRetryBudget(state *reconcilerState) {
+	if !state.retriesExhausted {
+		return
+	}
+	klog.Infof("Retrying commit %s, as requested", state.cache.source.commit)
+	state.cache.failures = 0
+	state.cache.nextRetryTime = time.Time{}
+}
+
+// updateRetriesExhausted sets the RetriesExhausted condition of the RootSync
+// or RepoSync when the retry budget of the commit is exhausted, and removes it
+// once the reconciler retries again, e.g. after the source changed.
+// Returns true if the retries are exhausted.
+func updateRetriesExhausted(ctx context.Context, p Parser, state *reconcilerState) bool {
+	exhausted := state.retriesExhaustedFor(p.options().RetryBudget)
+	if exhausted == state.retriesExhausted {
+		return exhausted
+	}
+	commit := state.cache.source.commit
+	if exhausted {
+		klog.Warningf("Stopped retrying commit %s after %d failed attempts: %v",
+			commit, state.cache.failures, status.FormatSingleLine(state.cache.errs))
+	} else {
+		klog.Infof("Resumed retrying after the retry budget was exhausted")
+	}
+	if err := setRetriesExhaustedCondition(ctx, p, exhausted, commit, state.cache.failures); err != nil {
+		// Try again when the retry timer fires next.
+		klog.Warningf("Failed to update the RetriesExhausted condition: %v", err)
+		return exhausted
+	}
+	state.retriesExhausted = exhausted
+	metrics.RecordRetriesExhausted(ctx, exhausted)
+	return exhausted
+}
+
+// setRetriesExhaustedCondition sets or removes the RetriesExhausted condition
+// of the RootSync or RepoSync.
+func setRetriesExhaustedCondition(ctx context.Context, p Parser, exhausted bool, commit string, failures int) error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to update the RetriesExhausted condition")
+	}
+	var updated bool
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		if exhausted {
+			updated = rootsync.SetRetriesExhausted(rs, commit, failures)
+		} else {
+			updated = rootsync.RemoveCondition(rs, v1beta1.RootSyncRetriesExhausted)
+		}
+	case *v1beta1.RepoSync:
+		if exhausted {
+			updated = reposync.SetRetriesExhausted(rs, commit, failures)
+		} else {
+			updated = reposync.RemoveCondition(rs, v1beta1.RepoSyncRetriesExhausted)
+		}
+	}
+	if !updated {
+		return nil
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to update the RetriesExhausted condition")
+	}
+	return nil
+}
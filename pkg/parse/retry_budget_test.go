@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/tunables"
+)
+
+func TestRetriesExhaustedFor(t *testing.T) {
+	testCases := []struct {
+		name        string
+		retryBudget int
+		needToRetry bool
+		failures    int
+		want        bool
+	}{
+		{name: "unlimited retries", retryBudget: 0, needToRetry: true, failures: 100},
+		{name: "within the budget", retryBudget: 3, needToRetry: true, failures: 3},
+		{name: "budget exhausted", retryBudget: 3, needToRetry: true, failures: 4, want: true},
+		{name: "no retry needed", retryBudget: 3, failures: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &reconcilerState{cache: cacheForCommit{needToRetry: tc.needToRetry, failures: tc.failures}}
+			require.Equal(t, tc.want, state.retriesExhaustedFor(tc.retryBudget))
+		})
+	}
+}
+
+func TestUpdateRetriesExhausted(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	p.options().client = syncerFake.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
+	p.options().RetryBudget = 2
+	state := &reconcilerState{}
+	state.cache.source.commit = "abc123"
+
+	getCondition := func() *v1beta1.RootSyncCondition {
+		t.Helper()
+		rs := &v1beta1.RootSync{}
+		require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+		return rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncRetriesExhausted)
+	}
+
+	for i := 0; i < 3; i++ {
+		require.False(t, updateRetriesExhausted(ctx, p, state))
+		require.Nil(t, getCondition())
+		state.invalidate(ctx, status.InternalError("apply failed"))
+	}
+
+	require.True(t, updateRetriesExhausted(ctx, p, state))
+	require.True(t, state.retriesExhausted)
+	cond := getCondition()
+	require.NotNil(t, cond)
+	require.Equal(t, "abc123", cond.Commit)
+
+	// A new commit resets the cache, which resumes the retries.
+	state.resetCache()
+	require.False(t, updateRetriesExhausted(ctx, p, state))
+	require.False(t, state.retriesExhausted)
+	require.Nil(t, getCondition())
+
+	// A sync request resumes the retries of the same commit.
+	for i := 0; i < 3; i++ {
+		state.invalidate(ctx, status.InternalError("apply failed"))
+	}
+	require.True(t, updateRetriesExhausted(ctx, p, state))
+	resetRetryBudget(state)
+	require.False(t, updateRetriesExhausted(ctx, p, state))
+	require.False(t, state.retriesExhausted)
+	require.Nil(t, getCondition())
+}
+
+func TestRunRetryBudgetExhausted(t *testing.T) {
+	rootDir := t.TempDir()
+	sourceCommit := "abcd123"
+	sourceRoot := filepath.Join(rootDir, "source")
+	hydratedRoot := filepath.Join(rootDir, "hydrated")
+	require.NoError(t, createRootDir(sourceRoot, sourceCommit))
+	require.NoError(t, createRootDir(hydratedRoot, sourceCommit))
+	require.NoError(t, hydrate.WriteManifest(filepath.Join(hydratedRoot, sourceCommit), nil))
+	require.NoError(t, writeFile(rootDir, hydrate.DoneFile, sourceCommit))
+
+	p := newParser(t, FileSource{
+		SourceDir:    cmpath.Absolute(filepath.Join(sourceRoot, symLink)),
+		RepoRoot:     cmpath.Absolute(rootDir),
+		HydratedRoot: hydratedRoot,
+		HydratedLink: symLink,
+		SourceType:   v1beta1.GitSource,
+		SourceRepo:   "https://github.com/test/test.git",
+		SourceBranch: "main",
+	})
+	opts := p.options()
+	opts.RetryBudget = 1
+	opts.pollingPeriod = tunables.NewDuration(200 * time.Millisecond)
+	opts.retryPeriod = 10 * time.Millisecond
+	opts.resyncPeriod = time.Hour
+	opts.statusUpdatePeriod = time.Hour
+	fakeApplier := &fakeApplier{errors: []status.Error{status.InternalError("apply failed")}}
+	opts.applier = fakeApplier
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, p)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// The first attempt and the one retry allowed by the budget fail, after
+	// which the RootSync reports that the retries are exhausted.
+	var cond *v1beta1.RootSyncCondition
+	require.Eventually(t, func() bool {
+		rs := &v1beta1.RootSync{}
+		if err := opts.k8sClient().Get(context.Background(), rootsync.ObjectKey(rootSyncName), rs); err != nil {
+			return false
+		}
+		cond = rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncRetriesExhausted)
+		return cond != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, sourceCommit, cond.Commit)
+
+	// The commit is not retried anymore, until the source changes.
+	time.Sleep(2 * time.Second)
+	cancel()
+	<-done
+	require.Equal(t, 2, fakeApplier.applies)
+}
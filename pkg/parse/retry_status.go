@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// updateRetryStatus surfaces the retry backoff of the reconciler in the
+// `Status.Retry` field of the RootSync or RepoSync, so that users can see
+// when the next retry happens. The field is removed once a run succeeds.
+// The RSync is only updated when the backoff changed.
+func updateRetryStatus(ctx context.Context, p Parser, state *reconcilerState) {
+	var retry *v1beta1.RetryStatus
+	if state.cache.needToRetry && state.cache.errs != nil {
+		retry = &v1beta1.RetryStatus{
+			ErrorClass:    string(state.cache.errClass),
+			Attempts:      state.cache.reconciliationWithSameErrs,
+			NextRetryTime: metav1.NewTime(state.cache.nextRetryTime),
+		}
+	}
+	if equality.Semantic.DeepEqual(retry, state.retryStatus) {
+		return
+	}
+	if err := setRetryStatus(ctx, p, retry); err != nil {
+		// Try again after the next run.
+		klog.Warningf("Failed to update the retry status: %v", err)
+		return
+	}
+	state.retryStatus = retry
+}
+
+// setRetryStatus sets or removes the `Status.Retry` field of the RootSync or
+// RepoSync.
+func setRetryStatus(ctx context.Context, p Parser, retry *v1beta1.RetryStatus) error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to update the retry status")
+	}
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		rs.Status.Retry = retry
+	case *v1beta1.RepoSync:
+		rs.Status.Retry = retry
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to update the retry status")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestJitter(t *testing.T) {
+	interval := 5 * time.Minute
+	for i := 0; i < 100; i++ {
+		got := jitter(interval)
+		require.LessOrEqual(t, got, interval)
+		require.GreaterOrEqual(t, got, time.Duration(float64(interval)*(1-retryJitterFactor)))
+	}
+}
+
+func TestUpdateRetryStatus(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	p.options().client = syncerFake.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
+	state := &reconcilerState{}
+
+	getRetry := func() *v1beta1.RetryStatus {
+		t.Helper()
+		rs := &v1beta1.RootSync{}
+		require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+		return rs.Status.Retry
+	}
+
+	errs := status.APIServerError(errors.New("connection refused"), "failed to list the objects")
+	state.invalidate(ctx, errs)
+	state.invalidate(ctx, errs)
+	updateRetryStatus(ctx, p, state)
+	retry := getRetry()
+	require.NotNil(t, retry)
+	require.Equal(t, string(status.Classify(errs)), retry.ErrorClass)
+	require.Equal(t, 2, retry.Attempts)
+	require.False(t, retry.NextRetryTime.Time.After(time.Now().Add(time.Second)))
+
+	// The field is removed once a run succeeds.
+	state.cache.source.syncDir = cmpath.Absolute("/repo/rev/abc123")
+	state.checkpoint()
+	updateRetryStatus(ctx, p, state)
+	require.Nil(t, state.retryStatus)
+	require.Nil(t, getRetry())
+}
@@ -41,8 +41,11 @@ import (
 	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/remediator"
+	"kpt.dev/configsync/pkg/remediator/watch"
+	"kpt.dev/configsync/pkg/rename"
 	"kpt.dev/configsync/pkg/rootsync"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tunables"
 	"kpt.dev/configsync/pkg/util/compare"
 	utildiscovery "kpt.dev/configsync/pkg/util/discovery"
 	"kpt.dev/configsync/pkg/validate"
@@ -51,13 +54,20 @@ import (
 )
 
 // NewRootRunner creates a new runnable parser for parsing a Root repository.
-func NewRootRunner(clusterName, syncName, reconcilerName string, format filesystem.SourceFormat, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
+//
+// c is used to update the RootSync object, while ro.TargetClient, if set, is
+// used to read the resources on the cluster that the resources are synced to.
+func NewRootRunner(clusterName, syncName, reconcilerName string, format filesystem.SourceFormat, fileReader reader.Reader, c client.Client, pollingPeriod *tunables.Duration, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, ro RunnerOptions, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
 	converter, err := declared.NewValueConverter(dc)
 	if err != nil {
 		return nil, err
 	}
 
-	return &root{
+	tc := ro.TargetClient
+	if tc == nil {
+		tc = c
+	}
+	p := &root{
 		opts: opts{
 			clusterName:        clusterName,
 			syncName:           syncName,
@@ -67,20 +77,31 @@ func NewRootRunner(clusterName, syncName, reconcilerName string, format filesyst
 			resyncPeriod:       resyncPeriod,
 			retryPeriod:        retryPeriod,
 			statusUpdatePeriod: statusUpdatePeriod,
+			upgradeDetector:    newUpgradeDetector(dc, ro.UpgradeSettlePeriod),
 			files:              files{FileSource: fs},
 			parser:             filesystem.NewParser(fileReader),
 			updater: updater{
-				scope:      declared.RootReconciler,
-				resources:  resources,
-				applier:    app,
-				remediator: rem,
+				scope:           declared.RootReconciler,
+				resources:       resources,
+				applier:         app,
+				remediator:      rem,
+				namespaceReader: tc,
+				inventory:       client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: syncName},
+				referenceReader: tc,
+				selfUpdate:      newSelfUpdate(tc, c, reconcilerName, ro.SelfUpdateTimeout),
 			},
 			discoveryInterface: dc,
 			converter:          converter,
 			mux:                &sync.Mutex{},
+			RunnerOptions:      ro,
 		},
 		sourceFormat: format,
-	}, nil
+		targetClient: tc,
+	}
+	if ro.NormalizeDeclarations {
+		p.normalizer = converter
+	}
+	return p, nil
 }
 
 type root struct {
@@ -90,6 +111,10 @@ type root struct {
 	// repository may be SourceFormatHierarchy; all others are implicitly
 	// SourceFormatUnstructured.
 	sourceFormat filesystem.SourceFormat
+
+	// targetClient reads the resources on the cluster that the resources are
+	// synced to.
+	targetClient client.Client
 }
 
 var _ Parser = &root{}
@@ -99,12 +124,16 @@ func (p *root) options() *opts {
 }
 
 // parseSource implements the Parser interface
-func (p *root) parseSource(_ context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
+func (p *root) parseSource(ctx context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
 	wantFiles := state.files
+	var hierarchicalFiles map[cmpath.Relative][]cmpath.Absolute
 	if p.sourceFormat == filesystem.SourceFormatHierarchy {
 		// We're using hierarchical mode for the root repository, so ignore files
 		// outside of the allowed directories.
 		wantFiles = filesystem.FilterHierarchyFiles(state.syncDir, wantFiles)
+	} else if len(p.HierarchicalDirs) > 0 {
+		// The files of the hierarchical directories are parsed separately.
+		wantFiles, hierarchicalFiles = splitHierarchicalDirs(state.syncDir, wantFiles, p.HierarchicalDirs)
 	}
 
 	filePaths := reader.FilePaths{
@@ -120,11 +149,25 @@ func (p *root) parseSource(_ context.Context, state sourceState) ([]ast.FileObje
 	builder := utildiscovery.ScoperBuilder(p.discoveryInterface)
 
 	klog.Infof("Parsing files from source dir: %s", state.syncDir.OSPath())
-	objs, err := p.parser.Parse(filePaths)
+	objs, err := p.parseChangedFiles(p.parser, filePaths, state.fileDigests)
 	if err != nil {
 		return nil, err
 	}
 
+	// Duplicated with namespace.go.
+	if mutationErrs := mutateDeclaredObjects(ctx, p.client, &v1beta1.RootSync{}, rootsync.ObjectKey(p.syncName), objs); mutationErrs != nil {
+		return nil, mutationErrs
+	}
+
+	// The Namespaces are only provisioned in the unstructured format, because
+	// the hierarchical format declares the Namespaces with directories.
+	if p.sourceFormat == filesystem.SourceFormatUnstructured {
+		var provisionErrs status.MultiError
+		if objs, provisionErrs = provisionNamespaces(ctx, p.client, rootsync.ObjectKey(p.syncName), objs); provisionErrs != nil {
+			return nil, provisionErrs
+		}
+	}
+
 	options := validate.Options{
 		ClusterName:    p.clusterName,
 		ReconcilerName: p.reconcilerName,
@@ -133,9 +176,14 @@ func (p *root) parseSource(_ context.Context, state sourceState) ([]ast.FileObje
 		BuildScoper:    builder,
 		Converter:      p.converter,
 	}
+	p.selectorSkipped = nil
+	options.SelectorSkipped = &p.selectorSkipped
 	options = OptionsForScope(options, p.scope)
+	options.Visitors = append(options.Visitors, addWebhookDependencies, addSyncWeightDependencies)
 
-	if p.sourceFormat == filesystem.SourceFormatUnstructured {
+	if p.sourceFormat == filesystem.SourceFormatUnstructured && len(p.HierarchicalDirs) > 0 {
+		objs, err = p.validateWithHierarchicalDirs(ctx, objs, state.syncDir, hierarchicalFiles, options)
+	} else if p.sourceFormat == filesystem.SourceFormatUnstructured {
 		options.Visitors = append(options.Visitors, p.addImplicitNamespaces)
 		objs, err = validate.Unstructured(objs, options)
 	} else {
@@ -147,6 +195,22 @@ func (p *root) parseSource(_ context.Context, state sourceState) ([]ast.FileObje
 	}
 
 	// Duplicated with namespace.go.
+	if secretErrs := checkPlaintextSecrets(ctx, p.client, &v1beta1.RootSync{}, rootsync.ObjectKey(p.syncName), objs); secretErrs != nil {
+		err = status.Append(err, secretErrs)
+		if status.HasBlockingErrors(secretErrs) {
+			return nil, err
+		}
+	}
+
+	// Duplicated with namespace.go.
+	// The objects are renamed after the validation, which checks them against
+	// the directories of the hierarchical format.
+	if renameErrs := rename.Apply(p.Renames, objs); renameErrs != nil {
+		return nil, status.Append(err, renameErrs)
+	}
+
+	// Duplicated with namespace.go.
+	addCommonLabelsAndAnnotations(objs, p.CommonLabels, p.CommonAnnotations)
 	e := addAnnotationsAndLabels(objs, declared.RootReconciler, p.syncName, p.sourceContext(), state.commit)
 	if e != nil {
 		err = status.Append(err, status.InternalErrorf("unable to add annotations and labels: %v", e))
@@ -317,6 +381,7 @@ func (p *root) setRenderingStatusWithRetires(ctx context.Context, newStatus rend
 func setRenderingStatusFields(rendering *v1beta1.RenderingStatus, p Parser, newStatus renderingStatus, denominator int) {
 	cse := status.ToCSE(newStatus.errs)
 	rendering.Commit = newStatus.commit
+	rendering.Digest = newStatus.digest
 	switch p.options().SourceType {
 	case v1beta1.GitSource:
 		rendering.Git = &v1beta1.GitStatus{
@@ -375,6 +440,11 @@ func (p *root) setSyncStatusWithRetries(ctx context.Context, newStatus syncStatu
 	currentRS := rs.DeepCopy()
 
 	setSyncStatusFields(&rs.Status.Status, newStatus, denominator)
+	declaredObjs, _ := p.resources.DeclaredObjects()
+	newlyAbandoned := updateAbandonedResources(&rs.Status.Sync, p.applier.AbandonedObjects(), p.applier.RetainedObjects(), declaredObjs, newStatus.lastUpdate)
+	if multiTarget, ok := p.applier.(targetResulter); ok && !newStatus.syncing {
+		rs.Status.Targets = targetStatuses(multiTarget.TargetResults(), newStatus.commit, denominator)
+	}
 
 	errorSources, errorSummary := summarizeErrors(rs.Status.Source, rs.Status.Sync)
 	if newStatus.syncing {
@@ -382,12 +452,16 @@ func (p *root) setSyncStatusWithRetries(ctx context.Context, newStatus syncStatu
 	} else {
 		if errorSummary.TotalCount == 0 {
 			rs.Status.LastSyncedCommit = rs.Status.Sync.Commit
+			if newStatus.fullResync {
+				rs.Status.Sync.LastFullResync = newStatus.lastUpdate.DeepCopy()
+			}
 		}
 		rootsync.SetSyncing(rs, false, "Sync", "Sync Completed", rs.Status.Sync.Commit, errorSources, errorSummary, rs.Status.Sync.LastUpdate)
 	}
 
-	// Avoid unnecessary status updates.
-	if !currentRS.Status.Sync.LastUpdate.IsZero() && cmp.Equal(currentRS.Status, rs.Status, compare.IgnoreTimestampUpdates) {
+	// Avoid unnecessary status updates, but always record the time of a full
+	// resync, even if nothing else changed.
+	if !newStatus.fullResync && !currentRS.Status.Sync.LastUpdate.IsZero() && cmp.Equal(currentRS.Status, rs.Status, compare.IgnoreTimestampUpdates) {
 		klog.V(5).Infof("Skipping sync status update for RootSync %s/%s", rs.Namespace, rs.Name)
 		return nil
 	}
@@ -402,6 +476,9 @@ func (p *root) setSyncStatusWithRetries(ctx context.Context, newStatus syncStatu
 	if !newStatus.syncing && rs.Status.Sync.Commit != "" {
 		metrics.RecordLastSync(ctx, metrics.StatusTagValueFromSummary(errorSummary), rs.Status.Sync.Commit, rs.Status.Sync.LastUpdate.Time)
 	}
+	if newStatus.fullResync && rs.Status.Sync.LastFullResync != nil {
+		metrics.RecordLastFullResync(ctx, rs.Status.Sync.LastFullResync.Time)
+	}
 
 	if klog.V(5).Enabled() {
 		klog.Infof("Updating sync status for RootSync %s/%s:\nDiff (- Expected, + Actual):\n%s",
@@ -416,6 +493,7 @@ func (p *root) setSyncStatusWithRetries(ctx context.Context, newStatus syncStatu
 		}
 		return status.APIServerError(err, "failed to update RootSync sync status")
 	}
+	recordAbandonedEvents(ctx, p.client, rs, kinds.RootSyncV1Beta1(), p.reconcilerName, newlyAbandoned)
 	return nil
 }
 
@@ -426,9 +504,67 @@ func setSyncStatusFields(syncStatus *v1beta1.Status, newStatus syncStatus, denom
 	syncStatus.Sync.Oci = syncStatus.Source.Oci
 	syncStatus.Sync.Helm = syncStatus.Source.Helm
 	setSyncStatusErrors(syncStatus, cse, denominator)
+	syncStatus.Sync.ImmutableFieldChanges = immutableFieldChanges(cse)
+	syncStatus.Sync.WatchHealth = newStatus.watchHealth
+	syncStatus.Sync.OrphanedResources = newStatus.orphanedResources
+	syncStatus.Sync.ResourceConsumption = newStatus.resourceConsumption
+	syncStatus.Sync.ClusterPrerequisites = newStatus.clusterPrerequisites
+	syncStatus.Sync.SelectorSkipped = newStatus.selectorSkipped
+	syncStatus.Sync.Warnings = newStatus.warnings
 	syncStatus.Sync.LastUpdate = newStatus.lastUpdate
 }
 
+// immutableFieldChanges returns the resources which could not be applied,
+// because the declared changes modify their immutable fields.
+func immutableFieldChanges(cse []v1beta1.ConfigSyncError) []v1beta1.ResourceRef {
+	var refs []v1beta1.ResourceRef
+	for _, e := range cse {
+		if e.Code == applier.ImmutableFieldErrorCode {
+			refs = append(refs, e.Resources...)
+		}
+	}
+	return refs
+}
+
+// watchHealth converts the failing remediator watches to their status.
+func watchHealth(failures []watch.Failure) []v1beta1.WatchFailure {
+	var watchHealth []v1beta1.WatchFailure
+	for _, failure := range failures {
+		watchHealth = append(watchHealth, v1beta1.WatchFailure{
+			GVK: metav1.GroupVersionKind{
+				Group:   failure.GVK.Group,
+				Version: failure.GVK.Version,
+				Kind:    failure.GVK.Kind,
+			},
+			Message: failure.Message,
+			Since:   metav1.NewTime(failure.Since).Rfc3339Copy(),
+		})
+	}
+	return watchHealth
+}
+
+// targetResulter is implemented by the appliers which fan the resources out to
+// multiple target clusters.
+type targetResulter interface {
+	TargetResults() []applier.TargetResult
+}
+
+// targetStatuses converts the results of the last apply to each target into
+// the RootSync target statuses.
+func targetStatuses(results []applier.TargetResult, commit string, denominator int) []v1beta1.TargetStatus {
+	var statuses []v1beta1.TargetStatus
+	for _, result := range results {
+		cse := status.ToCSE(result.Errors)
+		statuses = append(statuses, v1beta1.TargetStatus{
+			Name:       result.Name,
+			Commit:     commit,
+			LastUpdate: metav1.NewTime(result.LastUpdate),
+			Errors:     cse[0 : len(cse)/denominator],
+		})
+	}
+	return statuses
+}
+
 func setSyncStatusErrors(syncStatus *v1beta1.Status, cse []v1beta1.ConfigSyncError, denominator int) {
 	syncStatus.Sync.ErrorSummary = &v1beta1.ErrorSummary{
 		TotalCount: len(cse),
@@ -488,7 +624,7 @@ func (p *root) addImplicitNamespaces(objs []ast.FileObject) ([]ast.FileObject, s
 			continue
 		}
 		existingNs := &corev1.Namespace{}
-		err := p.client.Get(context.Background(), types.NamespacedName{Name: ns}, existingNs)
+		err := p.targetClient.Get(context.Background(), types.NamespacedName{Name: ns}, existingNs)
 		if err != nil && !apierrors.IsNotFound(err) {
 			errs = status.Append(errs, errors.Wrapf(err, "unable to check the existence of the implicit namespace %q", ns))
 			continue
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -43,6 +44,8 @@ import (
 	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/remediator/watch"
+	"kpt.dev/configsync/pkg/rootsync"
 	"kpt.dev/configsync/pkg/status"
 	syncertest "kpt.dev/configsync/pkg/syncer/syncertest/fake"
 	"kpt.dev/configsync/pkg/testing/fake"
@@ -77,6 +80,10 @@ func (r *noOpRemediator) FightErrors() []status.Error {
 	return nil
 }
 
+func (r *noOpRemediator) WatchFailures() []watch.Failure {
+	return nil
+}
+
 func (r *noOpRemediator) NeedsUpdate() bool {
 	return r.needsUpdate
 }
@@ -128,6 +135,7 @@ func TestRoot_Parse(t *testing.T) {
 					"",
 					core.Name("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -138,6 +146,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -155,6 +164,7 @@ func TestRoot_Parse(t *testing.T) {
 			format: filesystem.SourceFormatUnstructured,
 			existingObjects: []client.Object{fake.NamespaceObject("foo",
 				core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+				core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 				core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 				core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 				core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -170,6 +180,7 @@ func TestRoot_Parse(t *testing.T) {
 					"",
 					core.Name("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -180,6 +191,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -197,6 +209,7 @@ func TestRoot_Parse(t *testing.T) {
 			format: filesystem.SourceFormatUnstructured,
 			existingObjects: []client.Object{fake.NamespaceObject("foo",
 				core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+				core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 				core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 				core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 				core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -210,6 +223,7 @@ func TestRoot_Parse(t *testing.T) {
 			want: []ast.FileObject{
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -232,6 +246,7 @@ func TestRoot_Parse(t *testing.T) {
 			want: []ast.FileObject{
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -254,6 +269,7 @@ func TestRoot_Parse(t *testing.T) {
 				fake.RootSyncV1Beta1("test", gitSpec("https://github.com/test/test.git", configsync.AuthNone),
 					fake.WithRootSyncSourceType(v1beta1.GitSource),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1beta1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:spec":{".":{},"f:git":{".":{},"f:auth":{},"f:period":{},"f:repo":{}},"f:sourceType":{}},"f:status":{".":{},"f:rendering":{".":{},"f:lastUpdate":{}},"f:source":{".":{},"f:lastUpdate":{}},"f:sync":{".":{},"f:lastUpdate":{}}}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, fmt.Sprintf("namespaces/%s/test.yaml", configsync.ControllerNamespace)),
@@ -278,6 +294,7 @@ func TestRoot_Parse(t *testing.T) {
 					"",
 					core.Name("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -288,6 +305,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.Role(core.Namespace("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -300,6 +318,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.ConfigMap(core.Namespace("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/configmap.yaml"),
@@ -320,6 +339,7 @@ func TestRoot_Parse(t *testing.T) {
 				// bar not exists, should be added as an implicit namespace
 				fake.NamespaceObject("baz", // baz exists and self-managed, should be added as an implicit namespace
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -338,6 +358,7 @@ func TestRoot_Parse(t *testing.T) {
 			want: []ast.FileObject{
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -352,6 +373,7 @@ func TestRoot_Parse(t *testing.T) {
 					"",
 					core.Name("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -362,6 +384,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.Role(core.Namespace("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -374,6 +397,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.ConfigMap(core.Namespace("bar"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/configmap.yaml"),
@@ -388,6 +412,7 @@ func TestRoot_Parse(t *testing.T) {
 					"",
 					core.Name("baz"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -398,6 +423,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.Role(core.Namespace("baz"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -410,6 +436,7 @@ func TestRoot_Parse(t *testing.T) {
 				),
 				fake.ConfigMap(core.Namespace("baz"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/configmap.yaml"),
@@ -431,13 +458,15 @@ func TestRoot_Parse(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: tc.format,
+				targetClient: fakeClient,
 				opts: opts{
 					parser:             &fakeParser{parse: tc.parsed},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
 					converter:          converter,
 					updater: updater{
@@ -583,6 +612,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 					"",
 					core.Name("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -593,6 +623,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 				),
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -620,6 +651,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 					"",
 					core.Name("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion),
 					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
 					core.Annotation(metadata.GitContextKey, nilGitContext),
@@ -630,6 +662,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 				),
 				fake.Role(core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:rules":{}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "namespaces/foo/role.yaml"),
@@ -642,6 +675,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 				),
 				fakeCRD(core.Name("anvils.acme.com"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}},"f:spec":{"f:group":{},"f:names":{"f:kind":{},"f:plural":{},"f:singular":{}},"f:scope":{},"f:versions":{}},"f:status":{"f:acceptedNames":{"f:kind":{},"f:plural":{}},"f:conditions":{},"f:storedVersions":{}}}`),
 					core.Annotation(metadata.SourcePathAnnotationKey, "cluster/crd.yaml"),
@@ -655,6 +689,7 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 					core.Name("deploy"),
 					core.Namespace("foo"),
 					core.Label(metadata.ManagedByKey, metadata.ManagedByValue),
+					core.Label(metadata.OwningInventoryLabel, applier.InventoryID(rootSyncName, configmanagement.ControllerNamespace)),
 					core.Label(metadata.DeclaredVersionLabel, "v1"),
 					core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{},"f:labels":{}}}`),
 					core.Annotation(metadata.ResourceManagerKey, ":root_my-rs"),
@@ -676,13 +711,15 @@ func TestRoot_Parse_Discovery(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := syncertest.NewClient(t, runtime.NewScheme(), fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
 				opts: opts{
 					parser:             &fakeParser{parse: tc.parsed},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, runtime.NewScheme(), fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: tc.discoveryClient,
 					converter:          converter,
 					updater: updater{
@@ -736,13 +773,15 @@ func TestRoot_ParseErrorsMetricValidation(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
 				opts: opts{
 					parser:             &fakeParser{errors: tc.errors},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
 					updater: updater{
 						scope:     declared.RootReconciler,
@@ -794,13 +833,15 @@ func TestRoot_SourceReconcilerErrorsMetricValidation(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			m := testmetrics.RegisterMetrics(metrics.ReconcilerErrorsView)
 
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
 				opts: opts{
 					parser:             &fakeParser{errors: tc.parseErrors},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
 					updater: updater{
 						scope:     declared.RootReconciler,
@@ -861,8 +902,10 @@ func TestRoot_SourceAndSyncReconcilerErrorsMetricValidation(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			m := testmetrics.RegisterMetrics(metrics.ReconcilerErrorsView)
 
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
 				opts: opts{
 					parser: &fakeParser{},
 					updater: updater{
@@ -873,7 +916,7 @@ func TestRoot_SourceAndSyncReconcilerErrorsMetricValidation(t *testing.T) {
 					},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
 					mux:                &sync.Mutex{},
 				},
@@ -889,6 +932,61 @@ func TestRoot_SourceAndSyncReconcilerErrorsMetricValidation(t *testing.T) {
 	}
 }
 
+func TestRoot_LastFullResync(t *testing.T) {
+	testCases := []struct {
+		name    string
+		trigger string
+		want    bool
+	}{
+		{
+			name:    "resync records last full resync",
+			trigger: triggerResync,
+			want:    true,
+		},
+		{
+			name:    "reimport does not record last full resync",
+			trigger: triggerReimport,
+			want:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
+			parser := &root{
+				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
+				opts: opts{
+					parser: &fakeParser{},
+					updater: updater{
+						scope:      declared.RootReconciler,
+						resources:  &declared.Resources{},
+						remediator: &noOpRemediator{},
+						applier:    &fakeApplier{},
+					},
+					syncName:           rootSyncName,
+					reconcilerName:     rootReconcilerName,
+					client:             fakeClient,
+					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
+					mux:                &sync.Mutex{},
+				},
+			}
+			state := &reconcilerState{}
+			if err := parseAndUpdate(context.Background(), parser, tc.trigger, state); err != nil {
+				t.Fatalf("parseAndUpdate() got error: %v", err)
+			}
+
+			rs := &v1beta1.RootSync{}
+			if err := fakeClient.Get(context.Background(), rootsync.ObjectKey(rootSyncName), rs); err != nil {
+				t.Fatal(err)
+			}
+			if got := rs.Status.Sync.LastFullResync != nil; got != tc.want {
+				t.Errorf("lastFullResync set = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
 func sortObjects(left, right client.Object) bool {
 	leftID := core.IDOf(left)
 	rightID := core.IDOf(right)
@@ -920,11 +1018,19 @@ func (p *fakeParser) ReadClusterNamesFromSelector(_ reader.FilePaths) ([]string,
 }
 
 type fakeApplier struct {
-	got    []client.Object
-	errors []status.Error
+	got       []client.Object
+	errors    []status.Error
+	abandoned []client.Object
+	previewed []client.Object
+	dryRun    *applier.DryRunResult
+
+	pendingPruneExpiry time.Time
+	// applies counts the calls to Apply.
+	applies int
 }
 
 func (a *fakeApplier) Apply(_ context.Context, objs []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+	a.applies++
 	if a.errors == nil {
 		a.got = objs
 		gvks := make(map[schema.GroupVersionKind]struct{})
@@ -948,6 +1054,29 @@ func (a *fakeApplier) Errors() status.MultiError {
 	return errs
 }
 
+func (a *fakeApplier) AbandonedObjects() []client.Object {
+	return a.abandoned
+}
+
+func (a *fakeApplier) RetainedObjects() []client.Object {
+	return nil
+}
+
+func (a *fakeApplier) RequestFullApply() {}
+
+func (a *fakeApplier) PendingPruneExpiry() time.Time {
+	return a.pendingPruneExpiry
+}
+
+func (a *fakeApplier) DryRun(_ context.Context, objs []client.Object) (*applier.DryRunResult, status.MultiError) {
+	a.previewed = objs
+	var errs status.MultiError
+	for _, e := range a.errors {
+		errs = status.Append(errs, e)
+	}
+	return a.dryRun, errs
+}
+
 func (a *fakeApplier) Syncing() bool {
 	return false
 }
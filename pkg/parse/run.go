@@ -16,7 +16,9 @@ package parse
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,6 +27,7 @@ import (
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/hydrate"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/status"
 	webhookconfiguration "kpt.dev/configsync/pkg/webhook/configuration"
@@ -32,11 +35,15 @@ import (
 )
 
 const (
-	triggerResync             = "resync"
-	triggerReimport           = "reimport"
-	triggerRetry              = "retry"
-	triggerManagementConflict = "managementConflict"
-	triggerWatchUpdate        = "watchUpdate"
+	triggerResync              = "resync"
+	triggerReimport            = "reimport"
+	triggerRetry               = "retry"
+	triggerManagementConflict  = "managementConflict"
+	triggerWatchUpdate         = "watchUpdate"
+	triggerSupersede           = "supersede"
+	triggerClusterPrerequisite = "clusterPrerequisite"
+	triggerExternal            = "external"
+	triggerPruneDelay          = "pruneDelay"
 )
 
 const (
@@ -59,7 +66,7 @@ func Run(ctx context.Context, p Parser) {
 	// Use timers, not tickers.
 	// Tickers can cause memory leaks and continuous execution, when execution
 	// takes longer than the tick duration.
-	runTimer := time.NewTimer(opts.pollingPeriod)
+	runTimer := time.NewTimer(opts.pollingPeriod.Get())
 	defer runTimer.Stop()
 
 	resyncTimer := time.NewTimer(opts.resyncPeriod)
@@ -71,10 +78,30 @@ func Run(ctx context.Context, p Parser) {
 	statusUpdateTimer := time.NewTimer(opts.statusUpdatePeriod)
 	defer statusUpdateTimer.Stop()
 
+	// A nil channel never receives, so the loop only polls when the source
+	// links are not watched.
+	var sourceChanges <-chan struct{}
+	if opts.WatchSource {
+		hydratedLink := filepath.Join(opts.HydratedRoot, opts.HydratedLink)
+		var err error
+		sourceChanges, err = watchSourceLinks(ctx, opts.SourceDir.OSPath(), hydratedLink)
+		if err != nil {
+			klog.Warningf("Failed to watch the source links, falling back to polling: %v", err)
+		}
+	}
+
 	state := &reconcilerState{}
 	for {
+		if opts.OneShot && state.lastApplied != "" {
+			klog.Infof("Stopping the parser in one-shot mode, since %s is synced", state.lastApplied)
+			return
+		}
 		select {
 		case <-ctx.Done():
+			// The in-flight run, if any, was cancelled with the context. So
+			// report the interrupted sync before exiting.
+			klog.Info("Stopping the parser")
+			flushShutdownStatus(p, state)
 			return
 
 		// Re-apply even if no changes have been detected.
@@ -84,6 +111,9 @@ func Run(ctx context.Context, p Parser) {
 			// Reset the cache to make sure all the steps of a parse-apply-watch loop will run.
 			// The cached sourceState will not be reset to avoid reading all the source files unnecessarily.
 			state.resetAllButSourceState()
+			// Apply all the objects, and prune the removed ones, even if
+			// partial apply is enabled.
+			opts.applier.RequestFullApply()
 			run(ctx, p, triggerResync, state)
 
 			resyncTimer.Reset(opts.resyncPeriod)             // Schedule resync attempt
@@ -94,20 +124,63 @@ func Run(ctx context.Context, p Parser) {
 		case <-runTimer.C:
 			run(ctx, p, triggerReimport, state)
 
-			runTimer.Reset(opts.pollingPeriod)               // Schedule re-run attempt
+			runTimer.Reset(opts.pollingPeriod.Get())         // Schedule re-run attempt
+			retryTimer.Reset(opts.retryPeriod)               // Schedule retry attempt
+			statusUpdateTimer.Reset(opts.statusUpdatePeriod) // Schedule status update attempt
+
+		// Re-import declared resources as soon as a new commit has been fetched
+		// or rendered, without waiting for the polling period.
+		case <-sourceChanges:
+			klog.Infof("The source has changed")
+			run(ctx, p, triggerReimport, state)
+
+			runTimer.Reset(opts.pollingPeriod.Get())         // Schedule re-run attempt
+			retryTimer.Reset(opts.retryPeriod)               // Schedule retry attempt
+			statusUpdateTimer.Reset(opts.statusUpdatePeriod) // Schedule status update attempt
+
+		// Re-import declared resources as soon as an external trigger, e.g. a
+		// push event, is received. A nil channel never receives.
+		case <-opts.ExternalTriggers:
+			klog.Infof("Received an external trigger")
+			resetRetryBudget(state)
+			syncDir := state.cache.source.syncDir
+			run(ctx, p, triggerExternal, state)
+			waitForNewCommit(state, syncDir, time.Now())
+
+			runTimer.Reset(opts.pollingPeriod.Get())         // Schedule re-run attempt
 			retryTimer.Reset(opts.retryPeriod)               // Schedule retry attempt
 			statusUpdateTimer.Reset(opts.statusUpdatePeriod) // Schedule status update attempt
 
 		// Retry if there was an error, conflict, or any watches need to be updated.
 		case <-retryTimer.C:
 			var trigger string
-			if opts.managementConflict() {
+			if state.paused {
+				// Nothing is retried while the sync is paused. The next run
+				// of the polling resumes it once the annotation is removed.
+				continue
+			} else if externalTriggerPending(state, time.Now()) {
+				// Read the source again, until git-sync fetched the commit
+				// which the external trigger was received for.
+				trigger = triggerExternal
+			} else if opts.managementConflict() {
 				// Reset the cache to make sure all the steps of a parse-apply-watch loop will run.
 				// The cached sourceState will not be reset to avoid reading all the source files unnecessarily.
 				state.resetAllButSourceState()
 				trigger = triggerManagementConflict
 				// When conflict is detected, wait longer (same as the polling frequency) for the next retry.
-				time.Sleep(opts.pollingPeriod)
+				time.Sleep(opts.pollingPeriod.Get())
+			} else if updateRetriesExhausted(ctx, p, state) {
+				// Stop retrying the commit until the source changes, or the
+				// cache is reset by a force-resync.
+				continue
+			} else if pruneDelayExpired(p, time.Now()) {
+				klog.Infof("The prune delay of an object pending prune expired")
+				// Reset the cache to make sure the objects are applied
+				// again, which prunes the expired objects.
+				state.resetAllButSourceState()
+				trigger = triggerPruneDelay
+			} else if clusterPrerequisitesAvailable(p, state) {
+				trigger = triggerClusterPrerequisite
 			} else if state.cache.needToRetry && state.cache.readyToRetry() {
 				klog.Infof("The last reconciliation failed")
 				trigger = triggerRetry
@@ -125,17 +198,29 @@ func Run(ctx context.Context, p Parser) {
 
 		// Update the sync status to report management conflicts (from the remediator).
 		case <-statusUpdateTimer.C:
+			if err := auditOrphanedResources(ctx, p, state); err != nil {
+				klog.Warningf("failed to audit the orphaned resources: %v", err)
+			}
+			updateResourceConsumption(ctx, p, state)
 			// Skip sync status update if the .status.sync.commit is out of date.
 			// This avoids overwriting a newer Syncing condition with the status
 			// from an older commit.
-			if state.syncStatus.commit == state.sourceStatus.commit &&
+			// Nor while the sync is paused, which would overwrite the paused
+			// Syncing condition.
+			if !state.paused && state.syncStatus.commit == state.sourceStatus.commit &&
 				state.syncStatus.commit == state.renderingStatus.commit {
 
 				klog.V(3).Info("Updating sync status (periodic while not syncing)")
-				if err := setSyncStatus(ctx, p, state, p.Syncing(), p.SyncErrors()); err != nil {
+				if err := setSyncStatus(ctx, p, state, p.Syncing(), false, p.SyncErrors()); err != nil {
 					klog.Warningf("failed to update sync status: %v", err)
 				}
 			}
+			if err := updateDebugSnapshot(ctx, p, state); err != nil {
+				klog.Warningf("failed to update the debug snapshot: %v", err)
+			}
+			if err := resyncObjects(ctx, p, state); err != nil {
+				klog.Warningf("failed to resync the requested objects: %v", err)
+			}
 
 			statusUpdateTimer.Reset(opts.statusUpdatePeriod) // Schedule status update attempt
 		}
@@ -143,6 +228,41 @@ func Run(ctx context.Context, p Parser) {
 }
 
 func run(ctx context.Context, p Parser, trigger string, state *reconcilerState) {
+	if state.lastRuns == nil {
+		state.lastRuns = make(map[string]time.Time)
+	}
+	state.lastRuns[trigger] = time.Now()
+
+	ctx, span := startSpan(ctx, "run", trigger)
+	outcome := newRunOutcome(trigger, state)
+	outcome.span = span
+	defer outcome.finish(ctx, state)
+	defer updateFlapping(ctx, p, state, outcome)
+	defer updateRetryStatus(ctx, p, state)
+
+	annotations, annotationsErr := getRSyncAnnotations(ctx, p)
+	if annotationsErr != nil {
+		state.invalidate(ctx, annotationsErr)
+		return
+	}
+	resetCacheOnRequest(p, state, annotations)
+	paused := isSyncPaused(annotations)
+	resumed := false
+	if paused {
+		pauseSync(p, state)
+		// Report the paused sync last, since the source and rendering status
+		// updates of the run set the Syncing condition too.
+		defer setPausedCondition(ctx, p, state)
+	} else if state.paused {
+		resumeSync(p, state)
+		resumed = true
+	}
+	if restoreCommit := annotations[metadata.RestoreCommitAnnotationKey]; restoreCommit != "" && !paused {
+		restore(ctx, p, trigger, state, restoreCommit)
+		outcome.result = runRestored
+		return
+	}
+
 	var syncDir cmpath.Absolute
 	gs := sourceStatus{}
 	gs.commit, syncDir, gs.errs = hydrate.SourceCommitAndDir(p.options().SourceType, p.options().SourceDir, p.options().SyncDir, p.options().reconcilerName)
@@ -161,7 +281,7 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 				state.syncingConditionLastUpdate = gs.lastUpdate
 			}
 		}
-		state.invalidate(status.Append(gs.errs, setSourceStatusErr))
+		state.invalidate(ctx, status.Append(gs.errs, setSourceStatusErr))
 		return
 	}
 
@@ -173,7 +293,7 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 	doneFilePath := p.options().RepoRoot.Join(cmpath.RelativeSlash(hydrate.DoneFile)).OSPath()
 	_, err := os.Stat(doneFilePath)
 	if os.IsNotExist(err) || (err == nil && hydrate.DoneCommit(doneFilePath) != gs.commit) {
-		rs.message = RenderingInProgress
+		rs.message = renderingInProgressMessage(p.options().RepoRoot, gs.commit, time.Now())
 		rs.lastUpdate = metav1.Now()
 		klog.V(3).Info("Updating rendering status (before read): %#v", rs)
 		setRenderingStatusErr := p.setRenderingStatus(ctx, state.renderingStatus, rs)
@@ -183,8 +303,10 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 			state.syncingConditionLastUpdate = rs.lastUpdate
 		} else {
 			var m status.MultiError
-			state.invalidate(status.Append(m, setRenderingStatusErr))
+			state.invalidate(ctx, status.Append(m, setRenderingStatusErr))
+			return
 		}
+		outcome.result = runRendering
 		return
 	}
 	if err != nil {
@@ -197,7 +319,7 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 			state.renderingStatus = rs
 			state.syncingConditionLastUpdate = rs.lastUpdate
 		}
-		state.invalidate(status.Append(rs.errs, setRenderingStatusErr))
+		state.invalidate(ctx, status.Append(rs.errs, setRenderingStatusErr))
 		return
 	}
 
@@ -209,34 +331,161 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 		syncDir: syncDir,
 	}
 	if errs := read(ctx, p, trigger, state, ps); errs != nil {
-		state.invalidate(errs)
+		state.invalidate(ctx, errs)
 		return
 	}
 
 	newSyncDir := state.cache.source.syncDir
-	// The parse-apply-watch sequence will be skipped if the trigger type is `triggerReimport` and
-	// there is no new source changes. The reasons are:
+	// The parse-apply-watch sequence will be skipped if the trigger type is `triggerReimport` or
+	// `triggerExternal` and there is no new source changes. The reasons are:
 	//   * If a former parse-apply-watch sequence for syncDir succeeded, there is no need to run the sequence again;
 	//   * If all the former parse-apply-watch sequences for syncDir failed, the next retry will call the sequence;
 	//   * The retry logic tracks the number of reconciliation attempts failed with the same errors, and when
 	//     the next retry should happen. Calling the parse-apply-watch sequence here makes the retry logic meaningless.
-	if trigger == triggerReimport && oldSyncDir == newSyncDir {
+	// The sequence is never skipped when the sync is resumed, since resuming forces a full resync.
+	if (trigger == triggerReimport || trigger == triggerExternal) && oldSyncDir == newSyncDir && !resumed {
+		outcome.result = runSkipped
 		return
 	}
 
+	// Stop before parsing and applying while the sync is paused. Nothing is
+	// retried until the sync is resumed.
+	if paused {
+		klog.Infof("Not applying commit %s, since the sync is paused", state.cache.source.commit)
+		state.cache.needToRetry = false
+		if errs := reportPausedSource(ctx, p, state); errs != nil {
+			klog.Warningf("Failed to report the source status while the sync is paused: %v", errs)
+		}
+		outcome.result = runPaused
+		return
+	}
+
+	// Preview the commit instead of applying it in dry-run mode. Nothing is
+	// applied, so the remediator is never resumed and the cluster is never
+	// mutated.
+	if p.options().DryRun {
+		klog.Infof("Previewing commit %s, since the sync is in dry-run mode", state.cache.source.commit)
+		if errs := previewCommit(ctx, p, state); errs != nil {
+			state.invalidate(ctx, errs)
+			return
+		}
+		state.cache.needToRetry = false
+		outcome.result = runDryRun
+		return
+	}
+
+	// Hold off applying a new commit while the cluster control plane is being
+	// upgraded. The remediator keeps correcting drift of the resources from the
+	// last applied commit in the meantime.
+	if settling, settleUntil := p.options().upgradeDetector.settling(); settling && state.cache.source.commit != state.syncStatus.commit {
+		held, err := holdForUpgrade(ctx, p, state.cache.source.commit, settleUntil)
+		if err != nil {
+			klog.Warningf("Failed to report the pending sync: %v", err)
+		}
+		if held {
+			klog.Infof("Holding off applying commit %s until %s, while the cluster control plane settles after an upgrade",
+				state.cache.source.commit, settleUntil.Format(time.RFC3339))
+			state.deferRetry(settleUntil)
+			outcome.result = runDeferred
+			return
+		}
+	}
+
+	// Wait for the external approval system to approve a new commit.
+	if state.cache.source.commit != state.syncStatus.commit {
+		approved, err := checkApproval(ctx, p, state, state.cache.source.commit)
+		if err != nil && err.Code() != status.ApprovalErrorCode {
+			state.invalidate(ctx, err)
+			return
+		}
+		if err != nil {
+			// Surface the denial in `.status.source`, so that it is visible
+			// without reading the ApprovalRequest.
+			gs := sourceStatus{
+				commit:     state.cache.source.commit,
+				errs:       err,
+				lastUpdate: metav1.Now(),
+			}
+			var setSourceStatusErr error
+			if state.needToSetSourceStatus(gs) {
+				setSourceStatusErr = p.setSourceStatus(ctx, gs)
+				if setSourceStatusErr == nil {
+					state.sourceStatus = gs
+					state.syncingConditionLastUpdate = gs.lastUpdate
+				}
+			}
+			state.invalidate(ctx, status.Append(gs.errs, setSourceStatusErr))
+			return
+		}
+		if !approved {
+			klog.Infof("Holding off applying commit %s until it is approved", state.cache.source.commit)
+			state.deferRetry(time.Now().Add(p.options().pollingPeriod.Get()))
+			outcome.result = runDeferred
+			return
+		}
+	}
+
+	// Hold off applying a new commit outside the sync windows. The source is
+	// still fetched and rendered, and the remediator keeps correcting drift
+	// of the resources from the last applied commit in the meantime.
+	if now := time.Now(); state.cache.source.commit != state.syncStatus.commit && outsideSyncWindow(p, now) {
+		held, err := holdForSyncWindow(ctx, p, state.cache.source.commit)
+		if err != nil {
+			klog.Warningf("Failed to report the pending sync: %v", err)
+		}
+		if held {
+			klog.Infof("Holding off applying commit %s, since it is outside the sync windows", state.cache.source.commit)
+			state.deferRetry(nextSyncWindowCheck(now))
+			outcome.result = runDeferred
+			return
+		}
+	}
+
 	errs := parseAndUpdate(ctx, p, trigger, state)
+	if next := state.supersededBy; next != "" {
+		klog.Infof("Stopped applying commit %s, because commit %s is ready to apply", state.cache.source.commit, next)
+		state.supersededBy = ""
+		// The run of the newer commit records its own outcome.
+		outcome.result = runSuperseded
+		outcome.stages = append([]string{}, state.runStages...)
+		run(ctx, p, triggerSupersede, state)
+		return
+	}
 	if errs != nil {
-		state.invalidate(errs)
+		state.invalidate(ctx, errs)
 		return
 	}
 
 	// Only checkpoint the state after *everything* succeeded, including status update.
 	state.checkpoint()
+	saveSnapshot(ctx, p, state)
+	outcome.result = runSucceeded
+}
+
+// renderingInProgressMessage returns the rendering message of the commit
+// being rendered, with the progress reported by the hydration-controller in
+// the progress file, if it is rendering the commit.
+func renderingInProgressMessage(repoRoot cmpath.Absolute, commit string, now time.Time) string {
+	progressPath := repoRoot.Join(cmpath.RelativeSlash(hydrate.ProgressFile)).OSPath()
+	progress, err := hydrate.ReadProgress(progressPath)
+	if err != nil {
+		klog.Warningf("Unable to read the rendering progress: %v", err)
+		return RenderingInProgress
+	}
+	if progress == nil || progress.Commit != commit {
+		return RenderingInProgress
+	}
+	return fmt.Sprintf("%s: %s", RenderingInProgress, progress.Describe(now))
 }
 
 // read reads config files from source if no rendering is needed, or from hydrated output if rendering is done.
 // It also updates the .status.rendering and .status.source fields.
-func read(ctx context.Context, p Parser, trigger string, state *reconcilerState, sourceState sourceState) status.MultiError {
+func read(ctx context.Context, p Parser, trigger string, state *reconcilerState, sourceState sourceState) (errs status.MultiError) {
+	ctx, span := startSpan(ctx, "read", trigger)
+	defer func() {
+		endSpan(span, sourceState.commit, errs)
+	}()
+
 	hydrationStatus, sourceStatus := readFromSource(ctx, p, trigger, state, sourceState)
 	// Return the transient errors here to avoid surfacing them to the R*Sync status field.
 	// The transient errors might be auto-resolved in the next retry loop, so no need to expose to users.
@@ -321,12 +570,14 @@ func readFromSource(ctx context.Context, p Parser, trigger string, state *reconc
 	}
 
 	if sourceState.syncDir == state.cache.source.syncDir {
+		hydrationStatus.digest = state.cache.source.digest
 		return hydrationStatus, sourceStatus
 	}
 
 	klog.Infof("New source changes (%s) detected, reset the cache", sourceState.syncDir.OSPath())
 
 	// Reset the cache to make sure all the steps of a parse-apply-watch loop will run.
+	lastCache := state.cache
 	state.resetCache()
 
 	// Read all the files under state.syncDir
@@ -334,12 +585,27 @@ func readFromSource(ctx context.Context, p Parser, trigger string, state *reconc
 	if sourceStatus.errs == nil {
 		// Set `state.cache.source` after `readConfigFiles` succeeded
 		state.cache.source = sourceState
+		hydrationStatus.digest = sourceState.digest
+		// The rendered configs of the new commit are identical to those of
+		// the last applied commit, so reuse its results instead of parsing
+		// and applying the same configs again.
+		if state.lastApplied != "" && lastCache.appliedDigest(sourceState.digest) {
+			klog.Infof("The rendered configs of commit %s are identical to those of commit %s (digest %s), skipping the parse and apply",
+				sourceState.commit, lastCache.source.commit, sourceState.digest)
+			state.cache.reuseResults(lastCache)
+		}
 	}
 	metrics.RecordParserDuration(ctx, trigger, "read", metrics.StatusTagKey(sourceStatus.errs), start)
+	state.addRunStage("read")
 	return hydrationStatus, sourceStatus
 }
 
-func parseSource(ctx context.Context, p Parser, trigger string, state *reconcilerState) status.MultiError {
+func parseSource(ctx context.Context, p Parser, trigger string, state *reconcilerState) (errs status.MultiError) {
+	ctx, span := startSpan(ctx, "parseSource", trigger)
+	defer func() {
+		endSpan(span, state.cache.source.commit, errs)
+	}()
+
 	if state.cache.parserResultUpToDate() {
 		return nil
 	}
@@ -347,26 +613,29 @@ func parseSource(ctx context.Context, p Parser, trigger string, state *reconcile
 	start := time.Now()
 	objs, sourceErrs := p.parseSource(ctx, state.cache.source)
 	metrics.RecordParserDuration(ctx, trigger, "parse", metrics.StatusTagKey(sourceErrs), start)
+	state.addRunStage("parse")
 	state.cache.setParserResult(objs, sourceErrs)
+	state.cache.objsSelectorSkipped = p.options().selectorSkipped
+	if n := len(state.cache.objsSelectorSkipped); n > 0 {
+		klog.Infof("Skipped %d declared objects of commit %s, which are not selected by their cluster selector or namespace selector", n, state.cache.source.commit)
+	}
 
+	state.cache.webhookWarning = nil
 	if !status.HasBlockingErrors(sourceErrs) {
-		err := webhookconfiguration.Update(ctx, p.options().k8sClient(), p.options().discoveryClient(), objs)
-		if err != nil {
-			// Don't block if updating the admission webhook fails.
-			// Return an error instead if we remove the remediator as otherwise we
-			// will simply never correct the type.
-			// This should be treated as a warning once we have
-			// that capability.
-			klog.Errorf("Failed to update admission webhook: %v", err)
-			// TODO: Handle case where multiple reconciler Pods try to
-			//  create or update the Configuration simultaneously.
-		}
+		state.cache.webhookWarning = updateWebhookConfiguration(ctx, webhookUpdateBackoff, func() status.MultiError {
+			return webhookconfiguration.Update(ctx, p.options().k8sClient(), p.options().discoveryClient(), objs)
+		})
 	}
 
 	return sourceErrs
 }
 
-func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconcilerState) status.MultiError {
+func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconcilerState) (errs status.MultiError) {
+	ctx, span := startSpan(ctx, "parseAndUpdate", trigger)
+	defer func() {
+		endSpan(span, state.cache.source.commit, errs)
+	}()
+
 	klog.V(3).Info("Parser starting...")
 	sourceErrs := parseSource(ctx, p, trigger, state)
 	klog.V(3).Info("Parser stopped")
@@ -396,17 +665,38 @@ func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconc
 
 	go updateSyncStatusPeriodically(ctxForUpdateSyncStatus, p, state)
 
+	// Stop applying the commit if a newer commit is ready to apply, when
+	// enabled.
+	ctxForUpdate, cancelUpdate := context.WithCancel(ctx)
+	defer cancelUpdate()
+	stopWatchingSupersede := watchSupersedingCommit(ctx, p, state.cache.source.commit, cancelUpdate)
+
+	capture := captureClusterState(ctx, p, state)
+	diff := diffCommits(p, state)
+
 	klog.V(3).Info("Updater starting...")
 	start := time.Now()
-	syncErrs := p.options().Update(ctx, &state.cache)
+	syncErrs := p.options().Update(ctxForUpdate, &state.cache)
 	metrics.RecordParserDuration(ctx, trigger, "update", metrics.StatusTagKey(syncErrs), start)
+	state.addRunStage("update")
 	klog.V(3).Info("Updater stopped")
 
 	// This is to terminate `updateSyncStatusPeriodically`.
 	cancel()
 
+	if next := stopWatchingSupersede(); next != "" {
+		// Leave the sync status of the superseded commit as syncing, instead of
+		// reporting the errors caused by the cancellation. The newer commit
+		// will update it once applied.
+		state.supersededBy = next
+		return nil
+	}
+	publishClusterStateDiff(ctx, p, state, capture)
+	recordCommitDiff(ctx, p, diff)
+
 	klog.V(3).Info("Updating sync status (after sync)")
-	if err := setSyncStatus(ctx, p, state, false, syncErrs); err != nil {
+	fullResync := trigger == triggerResync && sourceErrs == nil && syncErrs == nil
+	if err := setSyncStatus(ctx, p, state, false, fullResync, syncErrs); err != nil {
 		syncErrs = status.Append(syncErrs, err)
 	}
 
@@ -415,14 +705,24 @@ func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconc
 
 // setSyncStatus updates `.status.sync` and the Syncing condition, if needed,
 // as well as `state.syncStatus` and `state.syncingConditionLastUpdate` if
-// the update is successful.
-func setSyncStatus(ctx context.Context, p Parser, state *reconcilerState, syncing bool, syncErrs status.MultiError) error {
+// the update is successful. fullResync records the update as the completion
+// of a periodic full resync.
+func setSyncStatus(ctx context.Context, p Parser, state *reconcilerState, syncing, fullResync bool, syncErrs status.MultiError) error {
 	// Update the RSync status, if necessary
 	newSyncStatus := syncStatus{
-		syncing:    syncing,
-		commit:     state.cache.source.commit,
-		errs:       syncErrs,
-		lastUpdate: metav1.Now(),
+		syncing:             syncing,
+		fullResync:          fullResync,
+		commit:              state.cache.source.commit,
+		errs:                syncErrs,
+		watchHealth:         watchHealth(p.options().remediator.WatchFailures()),
+		orphanedResources:   state.orphanedResources,
+		resourceConsumption: state.resourceConsumption,
+		selectorSkipped:     selectorSkipped(state.cache.objsSelectorSkipped),
+		warnings:            syncWarnings(state.cache),
+		lastUpdate:          metav1.Now(),
+	}
+	if p.options().scope != declared.RootReconciler {
+		newSyncStatus.clusterPrerequisites = clusterPrerequisites(state.cache.objsSkipped)
 	}
 	if state.needToSetSyncStatus(newSyncStatus) {
 		if err := p.SetSyncStatus(ctx, newSyncStatus); err != nil {
@@ -464,7 +764,7 @@ func updateSyncStatusPeriodically(ctx context.Context, p Parser, state *reconcil
 
 		case <-updateTimer.C:
 			klog.V(3).Info("Updating sync status (periodic while syncing)")
-			if err := setSyncStatus(ctx, p, state, true, p.SyncErrors()); err != nil {
+			if err := setSyncStatus(ctx, p, state, true, false, p.SyncErrors()); err != nil {
 				klog.Warningf("failed to update sync status: %v", err)
 			}
 
@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/export"
+	"kpt.dev/configsync/pkg/metrics"
+)
+
+const (
+	runSucceeded  = "succeeded"
+	runFailed     = "failed"
+	runSkipped    = "skipped"
+	runRendering  = "rendering"
+	runDeferred   = "deferred"
+	runSuperseded = "superseded"
+	runRestored   = "restored"
+	runPaused     = "paused"
+	runDryRun     = "dry-run"
+)
+
+// runOutcome records the outcome of a single run of the reconciler, so that
+// one can tell how often each trigger actually does any work.
+type runOutcome struct {
+	// trigger is the trigger of the run, e.g. triggerResync.
+	trigger string
+	// start is when the run started.
+	start time.Time
+	// result is the result of the run, e.g. runSucceeded. Runs which return
+	// without setting the result failed.
+	result string
+	// stages are the stages executed by the run, in order: read, parse,
+	// and update.
+	stages []string
+	// span is the span of the run, ended with the outcome. Nil if the run is
+	// not traced.
+	span *trace.Span
+}
+
+func newRunOutcome(trigger string, state *reconcilerState) *runOutcome {
+	state.runStages = nil
+	return &runOutcome{
+		trigger: trigger,
+		start:   time.Now(),
+		result:  runFailed,
+	}
+}
+
+// finish logs, records and exports the outcome with the stages executed since
+// the run started.
+func (o *runOutcome) finish(ctx context.Context, state *reconcilerState) {
+	if o.stages == nil {
+		o.stages = state.runStages
+	}
+	stages := strings.Join(o.stages, ",")
+	if stages == "" {
+		stages = "none"
+	}
+	klog.Infof("Reconciler run finished: trigger=%s result=%s stages=%s duration=%s",
+		o.trigger, o.result, stages, time.Since(o.start).Round(time.Millisecond))
+	metrics.RecordRun(ctx, o.trigger, o.result, stages, o.start)
+	if o.span != nil {
+		o.span.AddAttributes(trace.StringAttribute(spanAttrResult, o.result))
+		endSpan(o.span, state.cache.source.commit, state.cache.errs)
+	}
+	if export.Enabled() {
+		var codes []string
+		if state.cache.errs != nil {
+			for _, err := range state.cache.errs.Errors() {
+				codes = append(codes, err.Code())
+			}
+		}
+		export.RecordSyncAttempt(export.SyncAttempt{
+			Time:           o.start,
+			Trigger:        o.trigger,
+			Result:         o.result,
+			Stages:         stages,
+			Commit:         state.cache.source.commit,
+			DurationMillis: time.Since(o.start).Milliseconds(),
+			ErrorCodes:     codes,
+		})
+	}
+}
+
+// addRunStage records that the current run executed the stage.
+func (s *reconcilerState) addRunStage(stage string) {
+	s.runStages = append(s.runStages, stage)
+}
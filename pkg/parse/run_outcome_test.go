@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/testing/testmetrics"
+)
+
+func TestRunOutcome(t *testing.T) {
+	testCases := []struct {
+		name       string
+		trigger    string
+		result     string
+		stages     []string
+		wantStages string
+	}{
+		{
+			name:       "resync doing work",
+			trigger:    triggerResync,
+			result:     runSucceeded,
+			stages:     []string{"parse", "update"},
+			wantStages: "parse,update",
+		},
+		{
+			name:       "reimport without changes",
+			trigger:    triggerReimport,
+			result:     runSkipped,
+			wantStages: "none",
+		},
+		{
+			name:       "failed retry",
+			trigger:    triggerRetry,
+			stages:     []string{"read"},
+			wantStages: "read",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := testmetrics.RegisterMetrics(metrics.RunsView)
+			state := &reconcilerState{runStages: []string{"stale"}}
+
+			outcome := newRunOutcome(tc.trigger, state)
+			for _, stage := range tc.stages {
+				state.addRunStage(stage)
+			}
+			if tc.result != "" {
+				outcome.result = tc.result
+			}
+			outcome.finish(context.Background(), state)
+
+			wantResult := tc.result
+			if wantResult == "" {
+				wantResult = runFailed
+			}
+			want := []*view.Row{{
+				Data: &view.CountData{Value: 1},
+				Tags: []tag.Tag{
+					{Key: metrics.KeyRunResult, Value: wantResult},
+					{Key: metrics.KeyRunStages, Value: tc.wantStages},
+					{Key: metrics.KeyTrigger, Value: tc.trigger},
+				},
+			}}
+			if diff := m.ValidateMetrics(metrics.RunsView, want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
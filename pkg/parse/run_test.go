@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
@@ -84,6 +85,11 @@ func createRootDir(rootDir, commit string) error {
 	return os.Symlink(commitDir, symLinkPath)
 }
 
+func modifiedHydratedConfigsError(tempDir string) string {
+	return fmt.Sprintf("refusing to sync the hydrated configs, which might be partially written or modified after the rendering: "+
+		"the hydrated configs under %s/5/hydrated/abcd123 do not match the hydration manifest: extra.yaml (unexpected)", tempDir)
+}
+
 func writeFile(rootDir, file, content string) error {
 	errFile := filepath.Join(rootDir, file)
 	return os.WriteFile(errFile, []byte(content), 0644)
@@ -162,6 +168,8 @@ func TestRun(t *testing.T) {
 		sourceError                string
 		hydratedError              string
 		hydrationDone              bool
+		hydratedManifest           bool
+		hydratedModified           bool
 		needRetry                  bool
 		expectedMsg                string
 		expectedErrorSourceRefs    []v1beta1.ErrorSource
@@ -220,9 +228,26 @@ func TestRun(t *testing.T) {
 			sourceRootExist:   true,
 			hydratedRootExist: true,
 			hydrationDone:     true,
+			hydratedManifest:  true,
 			needRetry:         false,
 			expectedMsg:       "Sync Completed",
 		},
+		{
+			id:                         "5",
+			name:                       "hydrated configs modified after rendering",
+			sourceRootExist:            true,
+			hydratedRootExist:          true,
+			hydrationDone:              true,
+			hydratedManifest:           true,
+			hydratedModified:           true,
+			needRetry:                  true,
+			expectedMsg:                "Rendering failed",
+			expectedErrors:             fmt.Sprintf("1 error(s)\n\n\n[1] KNV2024: %s\n\nFor more information, see https://g.co/cloud/acm-errors#knv2024\n", modifiedHydratedConfigsError(tempDir)),
+			expectedStateRenderingErrs: status.HydrationError(status.HydrationVerificationErrorCode, fmt.Errorf(modifiedHydratedConfigsError(tempDir))),
+			// verification error is exposed to the RootSync status
+			expectedRSRenderingErrs: status.ToCSE(status.HydrationError(status.HydrationVerificationErrorCode, fmt.Errorf(modifiedHydratedConfigsError(tempDir)))),
+			expectedErrorSourceRefs: []v1beta1.ErrorSource{v1beta1.RenderingError},
+		},
 	}
 
 	sourceCommit := "abcd123"
@@ -251,6 +276,16 @@ func TestRun(t *testing.T) {
 					t.Fatal(err)
 				}
 			}
+			if tc.hydratedManifest {
+				if err = hydrate.WriteManifest(filepath.Join(hydratedRoot, sourceCommit), nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.hydratedModified {
+				if err = writeFile(filepath.Join(hydratedRoot, sourceCommit), "extra.yaml", "kind: Namespace"); err != nil {
+					t.Fatal(err)
+				}
+			}
 			if tc.hydrationDone {
 				if err = writeFile(rootDir, hydrate.DoneFile, sourceCommit); err != nil {
 					t.Fatal(err)
@@ -298,3 +333,44 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderingInProgressMessage(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		progress string
+		want     string
+	}{
+		{
+			name: "no progress file",
+			want: RenderingInProgress,
+		},
+		{
+			name:     "progress of the commit",
+			progress: `{"commit":"abcd123","stage":"running kustomize build","target":"clusters/prod","startTime":"2026-01-01T00:00:00Z"}`,
+			want:     "Rendering is still in progress: running kustomize build in clusters/prod, 30s elapsed",
+		},
+		{
+			name:     "progress of another commit",
+			progress: `{"commit":"efgh456","stage":"running kustomize build","startTime":"2026-01-01T00:00:00Z"}`,
+			want:     RenderingInProgress,
+		},
+		{
+			name:     "invalid progress file",
+			progress: `{"commit":`,
+			want:     RenderingInProgress,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			if tc.progress != "" {
+				if err := writeFile(repoRoot, hydrate.ProgressFile, tc.progress); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := renderingInProgressMessage(cmpath.Absolute(repoRoot), "abcd123", start.Add(30*time.Second))
+			testutil.AssertEqual(t, tc.want, got, "unexpected rendering message")
+		})
+	}
+}
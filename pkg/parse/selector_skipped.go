@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// maxSelectorSkippedObjects is the maximum number of skipped objects listed in
+// the sync status, to keep the RootSync or RepoSync object small.
+const maxSelectorSkippedObjects = 20
+
+// selectorSkipped summarizes the declared objects which are filtered out by
+// their cluster selector or namespace selector, with a sample of at most
+// maxSelectorSkippedObjects objects. It returns nil if no object is skipped.
+func selectorSkipped(objs []ast.FileObject) *v1beta1.SkippedObjects {
+	if len(objs) == 0 {
+		return nil
+	}
+	summary := &v1beta1.SkippedObjects{TotalCount: len(objs)}
+	if len(objs) > maxSelectorSkippedObjects {
+		objs = objs[:maxSelectorSkippedObjects]
+		summary.Truncated = true
+	}
+	for _, obj := range objs {
+		ref := status.ToResourceRef(obj.Unstructured)
+		if ref.SourcePath == "" {
+			// The objects may be skipped before their source path annotation
+			// is set.
+			ref.SourcePath = obj.SlashPath()
+		}
+		summary.Objects = append(summary.Objects, ref)
+	}
+	return summary
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestSelectorSkipped(t *testing.T) {
+	require.Nil(t, selectorSkipped(nil))
+
+	summary := selectorSkipped([]ast.FileObject{
+		fake.RoleBindingAtPath("namespaces/foo/rolebinding.yaml", core.Name("reader"), core.Namespace("foo")),
+	})
+	require.Equal(t, 1, summary.TotalCount)
+	require.False(t, summary.Truncated)
+	require.Len(t, summary.Objects, 1)
+	require.Equal(t, "reader", summary.Objects[0].Name)
+	require.Equal(t, "foo", summary.Objects[0].Namespace)
+	require.Equal(t, "RoleBinding", summary.Objects[0].GVK.Kind)
+	require.Equal(t, "namespaces/foo/rolebinding.yaml", summary.Objects[0].SourcePath)
+
+	var objs []ast.FileObject
+	for i := 0; i < maxSelectorSkippedObjects+5; i++ {
+		objs = append(objs, fake.RoleBindingAtPath("namespaces/foo/rolebinding.yaml", core.Name(fmt.Sprintf("role-%d", i)), core.Namespace("foo")))
+	}
+	summary = selectorSkipped(objs)
+	require.Equal(t, maxSelectorSkippedObjects+5, summary.TotalCount)
+	require.True(t, summary.Truncated)
+	require.Len(t, summary.Objects, maxSelectorSkippedObjects)
+}
@@ -0,0 +1,498 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/pkg/live"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// selfUpdatePollPeriod is how often the health of the Config Sync components
+// is checked after a self-update.
+const selfUpdatePollPeriod = 5 * time.Second
+
+// selfUpdate guards the changes a root reconciler makes to the Config Sync
+// components declared in its own source of truth, so that a bad commit cannot
+// leave Config Sync unable to sync the fix.
+//
+// Changes to the components are applied in two stages. The first stage applies
+// every other change while keeping the components at their previously declared
+// versions. The second stage applies the components, and waits for their
+// Deployments to become available. If they do not, because they crash-loop or
+// time out, the components are reverted to their previous versions and the
+// commit is not retried until its component changes are fixed.
+//
+// The state is persisted in a Secret, since a self-update may restart the
+// reconciler itself. After a restart, the components are compared with their
+// last declarations known to be healthy, and the health check of a pending
+// self-update is resumed, rather than treating it as healthy.
+type selfUpdate struct {
+	// reader reads the Deployments and Pods of the Config Sync components.
+	reader client.Reader
+	// client reads and writes the Secret persisting the state.
+	client client.Client
+	// key is the key of the Secret persisting the state.
+	key client.ObjectKey
+	// timeout is how long to wait for the components to become healthy.
+	timeout time.Duration
+
+	// pending is the self-update waiting for the health check, or nil.
+	pending *pendingSelfUpdate
+	// failedCommit is the latest commit whose component changes were
+	// reverted.
+	failedCommit string
+	// failedErr is why the components of failedCommit were reverted.
+	failedErr error
+}
+
+// newSelfUpdate returns a selfUpdate which waits up to timeout for the
+// components to become healthy, and persists its state with c. Returns nil if
+// the timeout is zero, which disables the staged self-updates.
+func newSelfUpdate(reader client.Reader, c client.Client, reconcilerName string, timeout time.Duration) *selfUpdate {
+	if timeout <= 0 {
+		return nil
+	}
+	return &selfUpdate{
+		reader:  reader,
+		client:  c,
+		key:     client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: reconcilerName + "-self-update"},
+		timeout: timeout,
+	}
+}
+
+type pendingSelfUpdate struct {
+	commit string
+	// appliedAt is when the components of the commit were applied.
+	appliedAt time.Time
+	// staged is the objects declared in the first stage, with the components
+	// at their previously declared versions.
+	staged []client.Object
+	// changed is the components changed by the commit.
+	changed []client.Object
+	// deployments is the component Deployments declared by the commit.
+	deployments []client.Object
+}
+
+// componentGroups is the API groups of the CRDs installed with Config Sync.
+var componentGroups = []string{configsync.GroupName, configmanagement.GroupName, live.ResourceGroupGVK.Group}
+
+// isSelfComponent returns true if the object is part of the Config Sync
+// installation: the objects labeled as Config Sync system objects, as in the
+// installation manifests, and the CRDs of the Config Sync API groups. Other
+// objects in the Config Sync namespaces, e.g. the RootSyncs and their
+// Secrets, are synced as usual.
+func isSelfComponent(obj client.Object) bool {
+	if obj.GetLabels()[metadata.SystemLabel] == "true" {
+		return true
+	}
+	if obj.GetObjectKind().GroupVersionKind().GroupKind() == kinds.CustomResourceDefinition() {
+		for _, group := range componentGroups {
+			if strings.HasSuffix(obj.GetName(), "."+group) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stageSelfUpdate returns the objects to declare for the commit, and applies
+// the first stage of the self-update if the commit changes any Config Sync
+// components.
+//
+// If the component changes of the commit were already reverted, the
+// components are kept at their previous versions, and each skipped change is
+// returned as a SelfUpdateRevertedError, which keeps the commit from being
+// marked as synced.
+func (u *updater) stageSelfUpdate(ctx context.Context, objs []client.Object, commit string) ([]client.Object, status.MultiError, status.MultiError) {
+	if u.selfUpdate == nil {
+		return objs, nil, nil
+	}
+	previousObjs, _ := u.resources.DeclaredUnstructureds()
+	var resumed *selfUpdateRecord
+	if len(previousObjs) == 0 {
+		// The reconciler just started, so compare the components with their
+		// declarations persisted before the restart, if any.
+		record, err := u.selfUpdate.load(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if record == nil {
+			// Nothing is known to be healthy before the first sync.
+			return objs, nil, nil
+		}
+		previousObjs = record.components()
+		switch record.Stage {
+		case selfUpdatePending:
+			resumed = record
+		case selfUpdateReverted:
+			u.selfUpdate.failedCommit = record.Commit
+			u.selfUpdate.failedErr = errors.New(record.Failure)
+		}
+	}
+	staged, changed, deployments, err := stageComponents(previousObjs, objs, u.normalizer)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.selfUpdate.pending = nil
+	if len(changed) == 0 {
+		return objs, nil, nil
+	}
+
+	if commit == u.selfUpdate.failedCommit {
+		var skipped status.MultiError
+		for _, obj := range changed {
+			skipped = status.Append(skipped, status.SelfUpdateRevertedError(commit, u.selfUpdate.failedErr, obj))
+		}
+		klog.Warningf("Skipped syncing %d reverted change(s) to the Config Sync components from commit %s", len(changed), commit)
+		return staged, skipped, nil
+	}
+
+	pending := &pendingSelfUpdate{
+		commit:      commit,
+		staged:      staged,
+		changed:     changed,
+		deployments: deployments,
+	}
+	if resumed != nil && resumed.Commit == commit {
+		// The components were applied before the restart, which they may
+		// have caused. So resume waiting for them, without reverting them
+		// with the first stage.
+		klog.Infof("Resuming the health check of the Config Sync components from commit %s", commit)
+		pending.appliedAt = resumed.AppliedAt
+		u.selfUpdate.pending = pending
+		return objs, nil, nil
+	}
+
+	klog.Infof("Commit %s changes %d Config Sync component(s), applying the other changes first", commit, len(changed))
+	if _, err := u.declare(ctx, staged, commit); err != nil {
+		return nil, nil, err
+	}
+	if _, err := u.apply(ctx, staged, commit); err != nil {
+		return nil, nil, err
+	}
+	pending.appliedAt = time.Now()
+	// Persist the previous components before applying the new ones, which
+	// may restart the reconciler.
+	if err := u.selfUpdate.save(ctx, &selfUpdateRecord{
+		Commit:    commit,
+		Stage:     selfUpdatePending,
+		AppliedAt: pending.appliedAt,
+	}, staged); err != nil {
+		return nil, nil, err
+	}
+	u.selfUpdate.pending = pending
+	return objs, nil, nil
+}
+
+// stageComponents returns the objects with the changes to the Config Sync
+// components reverted to their previous declarations, the changed components,
+// and the component Deployments to check the health of.
+func stageComponents(previousObjs []*unstructured.Unstructured, objs []client.Object, normalizer *declared.ValueConverter) ([]client.Object, []client.Object, []client.Object, status.Error) {
+	previous := make(map[core.ID]*unstructured.Unstructured, len(previousObjs))
+	for _, obj := range previousObjs {
+		previous[core.IDOf(obj)] = obj
+	}
+
+	var staged, changed, deployments []client.Object
+	declared := make(map[core.ID]bool, len(objs))
+	for _, obj := range objs {
+		id := core.IDOf(obj)
+		declared[id] = true
+		if !isSelfComponent(obj) {
+			staged = append(staged, obj)
+			continue
+		}
+		if id.GroupKind == kinds.Deployment().GroupKind() {
+			deployments = append(deployments, obj)
+		}
+		prev, found := previous[id]
+		if !found {
+			// Skip creating the component.
+			changed = append(changed, obj)
+			continue
+		}
+		diff, err := declarationChanged(prev, obj, normalizer)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if diff {
+			changed = append(changed, obj)
+		}
+		staged = append(staged, prev)
+	}
+	for id, prev := range previous {
+		if declared[id] || !isSelfComponent(prev) {
+			continue
+		}
+		// Skip pruning the component.
+		changed = append(changed, prev)
+		staged = append(staged, prev)
+	}
+	return staged, changed, deployments, nil
+}
+
+// checkSelfUpdate waits for the components of the pending self-update to
+// become healthy. If they do not, the components are reverted to their
+// previous versions, and the reverted changes are returned.
+//
+// The self-update stays pending if the revert fails, so that the health check
+// is repeated when the apply is retried.
+func (u *updater) checkSelfUpdate(ctx context.Context) (status.MultiError, status.MultiError) {
+	if u.selfUpdate == nil || u.selfUpdate.pending == nil {
+		return nil, nil
+	}
+	pending := u.selfUpdate.pending
+
+	healthErr := u.selfUpdate.waitForHealthy(ctx, pending.deployments, pending.appliedAt)
+	if healthErr == nil {
+		klog.Infof("Config Sync components from commit %s are healthy", pending.commit)
+		u.selfUpdate.pending = nil
+		declaredObjs, _ := u.resources.DeclaredObjects()
+		if err := u.selfUpdate.save(ctx, &selfUpdateRecord{
+			Commit: pending.commit,
+			Stage:  selfUpdateHealthy,
+		}, declaredObjs); err != nil {
+			// The health check is resumed after a restart, and passes again.
+			klog.Warningf("Failed to persist the healthy Config Sync components from commit %s: %v", pending.commit, err)
+		}
+		return nil, nil
+	}
+	if ctx.Err() != nil {
+		return nil, status.InternalWrap(healthErr)
+	}
+	klog.Errorf("Reverting the Config Sync components from commit %s: %v", pending.commit, healthErr)
+	if _, err := u.declare(ctx, pending.staged, pending.commit); err != nil {
+		return nil, err
+	}
+	if _, err := u.apply(ctx, pending.staged, pending.commit); err != nil {
+		return nil, err
+	}
+	u.selfUpdate.pending = nil
+	u.selfUpdate.failedCommit = pending.commit
+	u.selfUpdate.failedErr = healthErr
+	if err := u.selfUpdate.save(ctx, &selfUpdateRecord{
+		Commit:  pending.commit,
+		Stage:   selfUpdateReverted,
+		Failure: healthErr.Error(),
+	}, pending.staged); err != nil {
+		// The self-update is checked and reverted again after a restart.
+		klog.Warningf("Failed to persist the reverted Config Sync components from commit %s: %v", pending.commit, err)
+	}
+
+	var reverted status.MultiError
+	for _, obj := range pending.changed {
+		reverted = status.Append(reverted, status.SelfUpdateRevertedError(pending.commit, healthErr, obj))
+	}
+	return reverted, nil
+}
+
+// waitForHealthy waits until all the Deployments are available. Returns an
+// error if any of them crash-loops, fails, or is still unavailable after the
+// timeout, counted from when they were applied.
+func (s *selfUpdate) waitForHealthy(ctx context.Context, deployments []client.Object, appliedAt time.Time) error {
+	deadline := appliedAt.Add(s.timeout)
+	for {
+		healthy, err := s.healthy(ctx, deployments)
+		if err != nil || healthy {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %v waiting for the Deployments to become available", s.timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(selfUpdatePollPeriod):
+		}
+	}
+}
+
+// healthy returns true if all the Deployments are available, and an error if
+// any of them has failed.
+func (s *selfUpdate) healthy(ctx context.Context, deployments []client.Object) (bool, error) {
+	allCurrent := true
+	for _, d := range deployments {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(kinds.Deployment())
+		if err := s.reader.Get(ctx, client.ObjectKeyFromObject(d), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				allCurrent = false
+				continue
+			}
+			return false, err
+		}
+		result, err := kstatus.Compute(obj)
+		if err != nil {
+			return false, err
+		}
+		switch result.Status {
+		case kstatus.CurrentStatus:
+			continue
+		case kstatus.FailedStatus:
+			return false, fmt.Errorf("Deployment %s/%s failed: %s", d.GetNamespace(), d.GetName(), result.Message)
+		}
+		allCurrent = false
+		if err := s.checkCrashLoop(ctx, obj); err != nil {
+			return false, err
+		}
+	}
+	return allCurrent, nil
+}
+
+// checkCrashLoop returns an error if any container of the Deployment's Pods
+// is in CrashLoopBackOff.
+func (s *selfUpdate) checkCrashLoop(ctx context.Context, deployment *unstructured.Unstructured) error {
+	matchLabels, _, err := unstructured.NestedStringMap(deployment.Object, "spec", "selector", "matchLabels")
+	if err != nil || len(matchLabels) == 0 {
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := s.reader.List(ctx, pods, client.InNamespace(deployment.GetNamespace()), client.MatchingLabels(matchLabels)); err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return fmt.Errorf("container %s of Pod %s/%s is in CrashLoopBackOff", cs.Name, pod.Namespace, pod.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// The stages of a persisted self-update.
+const (
+	// selfUpdatePending is the stage of a self-update waiting for the
+	// components to become healthy.
+	selfUpdatePending = "Pending"
+	// selfUpdateHealthy is the stage of a self-update whose components
+	// became healthy.
+	selfUpdateHealthy = "Healthy"
+	// selfUpdateReverted is the stage of a self-update whose components were
+	// reverted to their previous declarations.
+	selfUpdateReverted = "Reverted"
+)
+
+// selfUpdateStateKey is the key of the JSON encoded selfUpdateRecord in the
+// data of the Secret persisting the state of the self-update.
+const selfUpdateStateKey = "state.json"
+
+// selfUpdateRecord is the state of a self-update, persisted in a Secret. A
+// Secret is used, rather than a ConfigMap, because the components may
+// include Secrets.
+type selfUpdateRecord struct {
+	// Commit is the commit whose component changes are pending, healthy, or
+	// reverted.
+	Commit string `json:"commit"`
+	// Stage is the stage of the self-update of Commit.
+	Stage string `json:"stage"`
+	// AppliedAt is when the components of a pending self-update were
+	// applied.
+	AppliedAt time.Time `json:"appliedAt,omitempty"`
+	// Failure is why the components of a reverted self-update were reverted.
+	Failure string `json:"failure,omitempty"`
+	// Components are the last declarations of the components known to be
+	// healthy: the previous declarations while the self-update is pending or
+	// after it was reverted, and the declarations from Commit once they are
+	// healthy.
+	Components []map[string]interface{} `json:"components,omitempty"`
+}
+
+// components returns the declarations of the components.
+func (r *selfUpdateRecord) components() []*unstructured.Unstructured {
+	objs := make([]*unstructured.Unstructured, len(r.Components))
+	for i, obj := range r.Components {
+		objs[i] = &unstructured.Unstructured{Object: obj}
+	}
+	return objs
+}
+
+// load returns the persisted state of the self-update, or nil if there is
+// none.
+func (s *selfUpdate) load(ctx context.Context) (*selfUpdateRecord, status.Error) {
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, s.key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, status.APIServerError(err, "failed to get the self-update state")
+	}
+	record := &selfUpdateRecord{}
+	if err := json.Unmarshal(secret.Data[selfUpdateStateKey], record); err != nil {
+		// Start over, rather than blocking the sync.
+		klog.Warningf("Ignoring the invalid self-update state in Secret %s: %v", s.key, err)
+		return nil, nil
+	}
+	return record, nil
+}
+
+// save persists the state of the self-update, with the declarations of the
+// components among the objects.
+func (s *selfUpdate) save(ctx context.Context, record *selfUpdateRecord, objs []client.Object) status.Error {
+	for _, obj := range objs {
+		if !isSelfComponent(obj) {
+			continue
+		}
+		u, err := kinds.ToUnstructured(obj, core.Scheme)
+		if err != nil {
+			return status.InternalWrap(fmt.Errorf("failed to convert %s: %w", core.IDOf(obj), err))
+		}
+		record.Components = append(record.Components, u.Object)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return status.InternalWrap(fmt.Errorf("failed to encode the self-update state: %w", err))
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, s.key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return status.APIServerError(err, "failed to get the self-update state")
+		}
+		secret.Name = s.key.Name
+		secret.Namespace = s.key.Namespace
+		core.SetLabel(secret, metadata.ManagedByKey, metadata.ManagedByValue)
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{selfUpdateStateKey: data}
+		if err := s.client.Create(ctx, secret); err != nil {
+			return status.APIServerError(err, "failed to create the self-update state")
+		}
+		return nil
+	}
+	secret.Data = map[string][]byte{selfUpdateStateKey: data}
+	if err := s.client.Update(ctx, secret); err != nil {
+		return status.APIServerError(err, "failed to update the self-update state")
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestUpdaterStageSelfUpdate(t *testing.T) {
+	ns := configsync.ControllerNamespace
+	system := core.Label(metadata.SystemLabel, "true")
+	cmOld := fake.ConfigMapObject(core.Name("reconciler-manager-cm"), core.Namespace(ns), system, core.Label("version", "1"))
+	cmNew := fake.ConfigMapObject(core.Name("reconciler-manager-cm"), core.Namespace(ns), system, core.Label("version", "2"))
+	crd := fake.CustomResourceDefinitionV1Object(core.Name("rootsyncs.configsync.gke.io"))
+	created := fake.ConfigMapObject(core.Name("created"), core.Namespace(ns), system)
+	// Objects in the Config Sync namespace without the system label, e.g. the
+	// Secrets of the RootSyncs, are not components.
+	secretOld := fake.SecretObject("git-creds", core.Namespace(ns), core.Label("version", "1"))
+	secretNew := fake.SecretObject("git-creds", core.Namespace(ns), core.Label("version", "2"))
+	userOld := fake.ConfigMapObject(core.Name("cm"), core.Namespace("foo"), core.Label("version", "1"))
+	userNew := fake.ConfigMapObject(core.Name("cm"), core.Namespace("foo"), core.Label("version", "2"))
+
+	resources := &declared.Resources{}
+	_, err := resources.Update(context.Background(), []client.Object{cmOld, crd, userOld, secretOld}, "1")
+	require.NoError(t, err)
+
+	u := &updater{
+		scope:      declared.RootReconciler,
+		resources:  resources,
+		selfUpdate: newSelfUpdate(nil, syncerFake.NewClient(t, core.Scheme), "root-reconciler", time.Minute),
+	}
+	// The component changes of a reverted commit are skipped.
+	unhealthy := errors.New("unhealthy")
+	u.selfUpdate.failedCommit = "2"
+	u.selfUpdate.failedErr = unhealthy
+	objs, skipped, stageErr := u.stageSelfUpdate(context.Background(), []client.Object{cmNew, crd, created, userNew, secretNew}, "2")
+	require.NoError(t, stageErr)
+
+	wantSkipped := status.Append(nil, status.SelfUpdateRevertedError("2", unhealthy, cmNew))
+	wantSkipped = status.Append(wantSkipped, status.SelfUpdateRevertedError("2", unhealthy, created))
+	require.Equal(t, wantSkipped.Error(), skipped.Error())
+
+	var gotIDs []string
+	for _, obj := range objs {
+		gotIDs = append(gotIDs, core.IDOf(obj).String())
+		if obj.GetName() == "reconciler-manager-cm" {
+			require.Equal(t, "1", obj.GetLabels()["version"], "component should keep its previous declaration")
+		}
+		if obj.GetName() == "cm" || obj.GetName() == "git-creds" {
+			require.Equal(t, "2", obj.GetLabels()["version"], "other objects should be updated")
+		}
+	}
+	sort.Strings(gotIDs)
+	var wantIDs []string
+	for _, obj := range []client.Object{cmOld, crd, userNew, secretNew} {
+		wantIDs = append(wantIDs, core.IDOf(obj).String())
+	}
+	sort.Strings(wantIDs)
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("unexpected declared objects (-want +got):\n%s", diff)
+	}
+}
+
+func TestSelfUpdateResume(t *testing.T) {
+	ctx := context.Background()
+	ns := configsync.ControllerNamespace
+	system := core.Label(metadata.SystemLabel, "true")
+	cmOld := fake.ConfigMapObject(core.Name("reconciler-manager-cm"), core.Namespace(ns), system, core.Label("version", "1"))
+	cmNew := fake.ConfigMapObject(core.Name("reconciler-manager-cm"), core.Namespace(ns), system, core.Label("version", "2"))
+	userNew := fake.ConfigMapObject(core.Name("cm"), core.Namespace("foo"), core.Label("version", "2"))
+	objs := []client.Object{cmNew, userNew}
+	c := syncerFake.NewClient(t, core.Scheme)
+
+	// The reconciler stages the self-update of commit 2.
+	resources := &declared.Resources{}
+	_, err := resources.Update(ctx, []client.Object{cmOld}, "1")
+	require.NoError(t, err)
+	u := &updater{
+		scope:      declared.RootReconciler,
+		resources:  resources,
+		applier:    &fakeApplier{},
+		selfUpdate: newSelfUpdate(c, c, "root-reconciler", time.Minute),
+	}
+	_, _, stageErr := u.stageSelfUpdate(ctx, objs, "2")
+	require.NoError(t, stageErr)
+	require.NotNil(t, u.selfUpdate.pending)
+	appliedAt := u.selfUpdate.pending.appliedAt
+
+	// After a restart, the health check is resumed, without applying the
+	// previous components again.
+	restarted := func() *updater {
+		return &updater{
+			scope:      declared.RootReconciler,
+			resources:  &declared.Resources{},
+			selfUpdate: newSelfUpdate(c, c, "root-reconciler", time.Minute),
+		}
+	}
+	u = restarted()
+	_, _, stageErr = u.stageSelfUpdate(ctx, objs, "2")
+	require.NoError(t, stageErr)
+	pending := u.selfUpdate.pending
+	require.NotNil(t, pending)
+	require.Equal(t, "2", pending.commit)
+	require.True(t, appliedAt.Equal(pending.appliedAt), "the timeout should be counted from the first apply")
+	for _, obj := range pending.staged {
+		if obj.GetName() == "reconciler-manager-cm" {
+			require.Equal(t, "1", obj.GetLabels()["version"], "the previous component should be restored on revert")
+		}
+	}
+
+	// Once healthy, the components of commit 2 are the new baseline.
+	_, err = u.resources.Update(ctx, objs, "2")
+	require.NoError(t, err)
+	reverted, checkErr := u.checkSelfUpdate(ctx)
+	require.NoError(t, checkErr)
+	require.Nil(t, reverted)
+
+	u = restarted()
+	_, _, stageErr = u.stageSelfUpdate(ctx, objs, "2")
+	require.NoError(t, stageErr)
+	require.Nil(t, u.selfUpdate.pending, "the healthy components should not be staged again")
+
+	// A reverted self-update stays reverted after a restart.
+	require.NoError(t, u.selfUpdate.save(ctx, &selfUpdateRecord{
+		Commit:  "3",
+		Stage:   selfUpdateReverted,
+		Failure: "unhealthy",
+	}, []client.Object{cmNew}))
+	u = restarted()
+	cmNewer := fake.ConfigMapObject(core.Name("reconciler-manager-cm"), core.Namespace(ns), system, core.Label("version", "3"))
+	_, skipped, stageErr := u.stageSelfUpdate(ctx, []client.Object{cmNewer, userNew}, "3")
+	require.NoError(t, stageErr)
+	wantSkipped := status.Append(nil, status.SelfUpdateRevertedError("3", errors.New("unhealthy"), cmNewer))
+	require.Equal(t, wantSkipped.Error(), skipped.Error())
+}
+
+func TestSelfUpdateHealthy(t *testing.T) {
+	ns := configsync.ControllerNamespace
+	labels := map[string]string{"app": "reconciler-manager"}
+	deployment := func(available bool) *appsv1.Deployment {
+		d := fake.DeploymentObject(core.Name("reconciler-manager"), core.Namespace(ns), core.Generation(1))
+		d.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		d.Status.ObservedGeneration = 1
+		d.Status.Replicas = 1
+		d.Status.UpdatedReplicas = 1
+		if available {
+			d.Status.ReadyReplicas = 1
+			d.Status.AvailableReplicas = 1
+			d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+		}
+		return d
+	}
+	crashLooping := fake.PodObject("reconciler-manager-abc", nil, core.Namespace(ns), core.Labels(labels))
+	crashLooping.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "reconciler-manager",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}}
+
+	testCases := []struct {
+		name        string
+		objs        []client.Object
+		wantHealthy bool
+		wantErr     bool
+	}{
+		{
+			name:        "available",
+			objs:        []client.Object{deployment(true)},
+			wantHealthy: true,
+		},
+		{
+			name: "rolling out",
+			objs: []client.Object{deployment(false)},
+		},
+		{
+			name: "not found",
+		},
+		{
+			name:    "crash-looping",
+			objs:    []client.Object{deployment(false), crashLooping},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := syncerFake.NewClient(t, core.Scheme, tc.objs...)
+			s := newSelfUpdate(c, c, "root-reconciler", time.Minute)
+			healthy, err := s.healthy(context.Background(), []client.Object{deployment(true)})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantHealthy, healthy)
+		})
+	}
+}
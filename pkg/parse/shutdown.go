@@ -0,0 +1,114 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const (
+	// reconcilerRestartingReason is the reason of the Syncing condition of a
+	// RootSync or RepoSync whose reconciler stopped mid-sync.
+	reconcilerRestartingReason = "Restarting"
+
+	// ReconcilerRestarting is the message of the Syncing condition and of
+	// the rendering status, when the reconciler stopped mid-sync.
+	ReconcilerRestarting = "Reconciler restarting"
+
+	// shutdownStatusTimeout is how long the reconciler tries to flush the
+	// final status once it is asked to stop. It is well within the default
+	// termination grace period of the Pods.
+	shutdownStatusTimeout = 10 * time.Second
+)
+
+// flushShutdownStatus reports that the reconciler is restarting, if it was
+// stopped mid-sync, so that the Syncing condition does not stay True until
+// the restarted reconciler reports its first run. The run context is already
+// cancelled, so the status is written with a context of its own.
+// Failures are logged, since the reconciler is exiting anyway.
+func flushShutdownStatus(p Parser, state *reconcilerState) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownStatusTimeout)
+	defer cancel()
+	if err := updateRestartingStatus(ctx, p, state.cache.source.commit); err != nil {
+		klog.Warningf("Failed to report the reconciler restart: %v", err)
+	}
+}
+
+// updateRestartingStatus sets the Syncing condition of the RootSync or
+// RepoSync to False, and the in-progress rendering status, to report that the
+// sync of the commit was interrupted by a restart. The status is left as is if
+// the RSync is not syncing, or is being deleted, in which case the finalizer
+// reports its progress.
+func updateRestartingStatus(ctx context.Context, p Parser, commit string) error {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return status.APIServerError(err, "failed to get the RSync to report the reconciler restart")
+	}
+	if rs.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	now := metav1.Now()
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+		if cond == nil || cond.Status != metav1.ConditionTrue {
+			return nil
+		}
+		if commit == "" {
+			commit = cond.Commit
+		}
+		setRestartingRendering(&rs.Status.Status, now)
+		rootsync.SetSyncing(rs, false, reconcilerRestartingReason, ReconcilerRestarting, commit, cond.ErrorSourceRefs, cond.ErrorSummary, now)
+	case *v1beta1.RepoSync:
+		cond := reposync.GetCondition(rs.Status.Conditions, v1beta1.RepoSyncSyncing)
+		if cond == nil || cond.Status != metav1.ConditionTrue {
+			return nil
+		}
+		if commit == "" {
+			commit = cond.Commit
+		}
+		setRestartingRendering(&rs.Status.Status, now)
+		reposync.SetSyncing(rs, false, reconcilerRestartingReason, ReconcilerRestarting, commit, cond.ErrorSourceRefs, cond.ErrorSummary, now)
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return status.APIServerError(err, "failed to report the reconciler restart")
+	}
+	klog.Infof("Reported the reconciler restart in the status of the %s", rs.GetObjectKind().GroupVersionKind().Kind)
+	return nil
+}
+
+// setRestartingRendering replaces the in-progress rendering status, which
+// would otherwise stay in progress until the restarted reconciler reads the
+// rendered commit.
+func setRestartingRendering(s *v1beta1.Status, now metav1.Time) {
+	if !strings.HasPrefix(s.Rendering.Message, RenderingInProgress) {
+		return
+	}
+	s.Rendering.Message = ReconcilerRestarting
+	s.Rendering.LastUpdate = now
+}
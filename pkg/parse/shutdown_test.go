@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/rootsync"
+)
+
+func TestFlushShutdownStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		syncing       bool
+		rendering     string
+		wantStatus    metav1.ConditionStatus
+		wantReason    string
+		wantRendering string
+	}{
+		{
+			name:          "stopped mid-sync",
+			syncing:       true,
+			rendering:     RenderingInProgress,
+			wantStatus:    metav1.ConditionFalse,
+			wantReason:    reconcilerRestartingReason,
+			wantRendering: ReconcilerRestarting,
+		},
+		{
+			name:          "stopped mid-sync after rendering",
+			syncing:       true,
+			rendering:     RenderingSucceeded,
+			wantStatus:    metav1.ConditionFalse,
+			wantReason:    reconcilerRestartingReason,
+			wantRendering: RenderingSucceeded,
+		},
+		{
+			name:          "stopped after the sync completed",
+			syncing:       false,
+			rendering:     RenderingSucceeded,
+			wantStatus:    metav1.ConditionFalse,
+			wantReason:    "Sync",
+			wantRendering: RenderingSucceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := newParser(t, FileSource{})
+			c := p.options().k8sClient()
+
+			rs := &v1beta1.RootSync{}
+			require.NoError(t, c.Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+			rs.Status.Rendering.Message = tc.rendering
+			rootsync.SetSyncing(rs, tc.syncing, "Sync", "Syncing", "abc123", nil, nil, metav1.Now())
+			require.NoError(t, c.Status().Update(ctx, rs))
+
+			state := &reconcilerState{}
+			state.cache.source = sourceState{commit: "abc123"}
+			flushShutdownStatus(p, state)
+
+			require.NoError(t, c.Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+			cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+			require.NotNil(t, cond)
+			require.Equal(t, tc.wantStatus, cond.Status)
+			require.Equal(t, tc.wantReason, cond.Reason)
+			require.Equal(t, "abc123", cond.Commit)
+			require.Equal(t, tc.wantRendering, rs.Status.Rendering.Message)
+		})
+	}
+}
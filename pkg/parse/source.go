@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/monochromegane/go-gitignore"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	v1 "kpt.dev/configsync/pkg/api/configmanagement/v1"
@@ -31,6 +32,16 @@ import (
 	"kpt.dev/configsync/pkg/status"
 )
 
+// configSyncIgnoreFile is the file in the root of the source repository that
+// lists, in gitignore syntax, the files and directories to skip when reading
+// the configs.
+//
+// It only applies to the source files, so it is not applied when the configs
+// are rendered: the rendered configs are in the hydrated directory, which has
+// no ignore file, and whose file paths do not match the ones of the source.
+// Kustomize and Helm only render the files they reference anyway.
+const configSyncIgnoreFile = ".configsyncignore"
+
 // FileSource includes all settings to configure where a Parser reads files from.
 type FileSource struct {
 	// SourceDir is the path to the symbolic link of the source repository.
@@ -51,6 +62,12 @@ type FileSource struct {
 	SourceBranch string
 	// SourceRev is the revision of the source repo to sync.
 	SourceRev string
+	// WatchSource enables reimporting as soon as the source or hydrated link
+	// changes, instead of waiting for the next polling period.
+	WatchSource bool
+	// HydrationSigningKey is the key to verify the signature of the manifest
+	// of the hydrated configs with. The signature is not verified if it is empty.
+	HydrationSigningKey []byte
 }
 
 // files lists files in a repository and ensures the source repository hasn't been
@@ -61,6 +78,14 @@ type files struct {
 	// currentSyncDir is the directory (including git commit hash or OCI image digest)
 	// last seen by the Parser.
 	currentSyncDir string
+
+	// verifiedHydratedDir is the hydrated directory (including git commit hash
+	// or OCI image digest) whose manifest was last verified by the Parser.
+	verifiedHydratedDir string
+
+	// parsedFiles are the objects last parsed from each file, keyed by the
+	// slash-separated path of the file relative to the sync directory.
+	parsedFiles map[string]parsedFile
 }
 
 // sourceState contains all state read from the mounted source repo.
@@ -71,12 +96,21 @@ type sourceState struct {
 	syncDir cmpath.Absolute
 	// files is the list of all observed files in the sync directory (recursively).
 	files []cmpath.Absolute
+	// digest is the digest of the files, which is identical for the commits
+	// with identical rendered configs.
+	digest string
+	// fileDigests are the digests of the contents of the files, keyed by
+	// their slash-separated paths relative to syncDir. Only the files whose
+	// digest changed since the previous commit are parsed again.
+	fileDigests map[string]string
 }
 
 // readConfigFiles reads all the files under state.syncDir and sets state.files.
 // - if rendered is true, state.syncDir contains the hydrated files.
 // - if rendered is false, state.syncDir contains the source files.
 // readConfigFiles should be called after sourceState is populated.
+// The files matching the .configsyncignore file in the repository root are
+// skipped, if the configs are not rendered.
 func (o *files) readConfigFiles(state *sourceState, p Parser) status.Error {
 	if state == nil || state.commit == "" || state.syncDir.OSPath() == "" {
 		return status.InternalError("sourceState is not populated yet")
@@ -89,9 +123,14 @@ func (o *files) readConfigFiles(state *sourceState, p Parser) status.Error {
 		o.currentSyncDir = syncDir.OSPath()
 	}
 
+	ignoreMatcher, ignoreErr := o.readIgnoreFile(syncDir)
+	if ignoreErr != nil {
+		return ignoreErr
+	}
+
 	var fileList []cmpath.Absolute
 	var err error
-	fileList, err = listFiles(syncDir, map[string]bool{".git": true})
+	fileList, err = listFiles(syncDir, map[string]bool{".git": true}, ignoreMatcher)
 	if err != nil {
 		return status.PathWrapError(errors.Wrap(err, "listing files in the configs directory"), syncDir.OSPath())
 	}
@@ -103,10 +142,44 @@ func (o *files) readConfigFiles(state *sourceState, p Parser) status.Error {
 		return status.TransientError(fmt.Errorf("source commit changed while listing files, was %s, now %s. It will be retried in the next sync", state.commit, newCommit))
 	}
 
+	paths := make([]string, len(fileList))
+	for i, f := range fileList {
+		paths[i] = f.OSPath()
+	}
+	fileDigests, err := hydrate.FileDigests(syncDir.OSPath(), paths)
+	if err != nil {
+		return status.PathWrapError(err, syncDir.OSPath())
+	}
+
 	state.files = fileList
+	state.fileDigests = fileDigests
+	state.digest = hydrate.CombinedDigest(fileDigests)
 	return nil
 }
 
+// readIgnoreFile returns the matcher for the configSyncIgnoreFile in the root
+// of the repository containing syncDir, or nil if the file does not exist.
+// For a hydrated syncDir, the root is the hydrated directory of the commit,
+// which never contains the file.
+func (o *files) readIgnoreFile(syncDir cmpath.Absolute) (gitignore.IgnoreMatcher, status.Error) {
+	repoRoot := syncDir.OSPath()
+	if !o.SyncDir.IsRoot() {
+		for range o.SyncDir.Split() {
+			repoRoot = filepath.Dir(repoRoot)
+		}
+	}
+	ignoreFile := filepath.Join(repoRoot, configSyncIgnoreFile)
+	matcher, err := gitignore.NewGitIgnore(ignoreFile, repoRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, status.PathWrapError(errors.Wrapf(err, "reading %s", configSyncIgnoreFile), ignoreFile)
+	}
+	klog.V(4).Infof("Skipping the files matching %s", ignoreFile)
+	return matcher, nil
+}
+
 func (o *files) sourceContext() sourceContext {
 	return sourceContext{
 		Repo:   o.SourceRepo,
@@ -130,6 +203,14 @@ func (o *files) readHydratedDir(hydratedRoot cmpath.Absolute, link, reconciler s
 		return result, hydrate.NewInternalError(errors.Wrapf(err, "unable to load the hydrated configs under %s", hydratedRoot.OSPath()))
 	}
 
+	if hydratedDir.OSPath() != o.verifiedHydratedDir {
+		if err := hydrate.VerifyManifest(hydratedDir.OSPath(), o.HydrationSigningKey); err != nil {
+			return result, hydrate.NewVerificationError(errors.Wrapf(err, "refusing to sync the hydrated configs, "+
+				"which might be partially written or modified after the rendering"))
+		}
+		o.verifiedHydratedDir = hydratedDir.OSPath()
+	}
+
 	result.commit = filepath.Base(hydratedDir.OSPath())
 
 	relSyncDir := hydratedDir.Join(o.SyncDir)
@@ -142,19 +223,25 @@ func (o *files) readHydratedDir(hydratedRoot cmpath.Absolute, link, reconciler s
 }
 
 // listFiles returns a list of all files in the specified directory.
-func listFiles(dir cmpath.Absolute, ignore map[string]bool) ([]cmpath.Absolute, error) {
+// Directories named in ignore, and files and directories matched by
+// ignoreMatcher, if not nil, are skipped.
+func listFiles(dir cmpath.Absolute, ignore map[string]bool, ignoreMatcher gitignore.IgnoreMatcher) ([]cmpath.Absolute, error) {
 	var result []cmpath.Absolute
 	err := filepath.Walk(dir.OSPath(),
 		func(path string, fi os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
+			ignored := ignoreMatcher != nil && path != dir.OSPath() && ignoreMatcher.Match(path, fi.IsDir())
 			if fi.IsDir() {
-				if _, contains := ignore[fi.Name()]; contains {
+				if _, contains := ignore[fi.Name()]; contains || ignored {
 					return filepath.SkipDir
 				}
 				return nil
 			}
+			if ignored {
+				return nil
+			}
 			abs, err := cmpath.AbsoluteOS(path)
 			if err != nil {
 				return err
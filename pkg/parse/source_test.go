@@ -104,13 +104,15 @@ func TestReadConfigFiles(t *testing.T) {
 				files:   nil,
 			}
 
+			fakeClient := syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName))
 			parser := &root{
 				sourceFormat: filesystem.SourceFormatUnstructured,
+				targetClient: fakeClient,
 				opts: opts{
 					parser:             &fakeParser{},
 					syncName:           rootSyncName,
 					reconcilerName:     rootReconcilerName,
-					client:             syncertest.NewClient(t, core.Scheme, fake.RootSyncObjectV1Beta1(rootSyncName)),
+					client:             fakeClient,
 					discoveryInterface: syncertest.NewDiscoveryClient(kinds.Namespace(), kinds.Role()),
 					updater: updater{
 						scope:     declared.RootReconciler,
@@ -128,3 +130,101 @@ func TestReadConfigFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestReadConfigFilesWithIgnoreFile(t *testing.T) {
+	tempRoot, err := ioutil.TempDir(os.TempDir(), "read-config-ignore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tempRoot); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repoFiles := map[string]string{
+		configSyncIgnoreFile:               "# vendored examples\nexamples/\n*.test.yaml\n!keep.test.yaml\n",
+		"configs/ns.yaml":                  "",
+		"configs/examples/example.yaml":    "",
+		"configs/sub/fixture.test.yaml":    "",
+		"configs/sub/keep.test.yaml":       "",
+		"configs/sub/examples.yaml":        "",
+		"outside-sync-dir/unrelated.yaml":  "",
+		"configs/nested/examples/too.yaml": "",
+	}
+	for path, content := range repoFiles {
+		osPath := filepath.Join(tempRoot, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(osPath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(osPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncDir := cmpath.Absolute(filepath.ToSlash(filepath.Join(tempRoot, "configs")))
+	f := &files{FileSource: FileSource{SyncDir: cmpath.RelativeSlash("configs")}}
+	ignoreMatcher, ignoreErr := f.readIgnoreFile(syncDir)
+	if ignoreErr != nil {
+		t.Fatal(ignoreErr)
+	}
+	got, err := listFiles(syncDir, map[string]bool{".git": true}, ignoreMatcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotRelative []string
+	for _, file := range got {
+		rel, err := filepath.Rel(syncDir.OSPath(), file.OSPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotRelative = append(gotRelative, filepath.ToSlash(rel))
+	}
+	want := []string{"ns.yaml", "sub/examples.yaml", "sub/keep.test.yaml"}
+	testutil.AssertEqual(t, want, gotRelative)
+}
+
+func TestReadConfigFilesWithIgnoreFileHydrated(t *testing.T) {
+	tempRoot := t.TempDir()
+	commit := "abcd123"
+
+	// The ignore file of the source is not applied to the rendered configs.
+	repoFiles := map[string]string{
+		"source/" + commit + "/" + configSyncIgnoreFile:     "*.yaml\n",
+		"source/" + commit + "/configs/kustomization.yaml":  "",
+		"hydrated/" + commit + "/configs/ns.yaml":           "",
+		"hydrated/" + commit + "/configs/examples/ex.yaml":  "",
+		"hydrated/" + commit + "/configs/sub/fixture.yaml":  "",
+		"hydrated/" + commit + "/configs/sub/another.yaml":  "",
+		"hydrated/" + commit + "/configs/sub/nested/x.yaml": "",
+	}
+	for path, content := range repoFiles {
+		osPath := filepath.Join(tempRoot, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(osPath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(osPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncDir := cmpath.Absolute(filepath.ToSlash(filepath.Join(tempRoot, "hydrated", commit, "configs")))
+	f := &files{FileSource: FileSource{
+		SourceDir:    cmpath.Absolute(filepath.ToSlash(filepath.Join(tempRoot, "source", commit))),
+		HydratedRoot: filepath.Join(tempRoot, "hydrated"),
+		SyncDir:      cmpath.RelativeSlash("configs"),
+	}}
+	ignoreMatcher, ignoreErr := f.readIgnoreFile(syncDir)
+	if ignoreErr != nil {
+		t.Fatal(ignoreErr)
+	}
+	if ignoreMatcher != nil {
+		t.Fatalf("got an ignore matcher for the hydrated configs, want none")
+	}
+	got, err := listFiles(syncDir, map[string]bool{".git": true}, ignoreMatcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertEqual(t, 5, len(got))
+}
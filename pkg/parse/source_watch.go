@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+)
+
+// watchSourceLinks returns a channel that receives a value whenever one of the
+// symbolic links is replaced. git-sync, oci-sync, helm-sync and the
+// hydration-controller all replace their link atomically once a new commit has
+// been fetched or rendered, so a change of link means there is something new to
+// reimport. Changes that arrive while a value is pending are coalesced.
+func watchSourceLinks(ctx context.Context, links ...string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(links))
+	dirs := make(map[string]bool, len(links))
+	for _, link := range links {
+		link = filepath.Clean(link)
+		names[link] = true
+		dir := filepath.Dir(link)
+		if dirs[dir] {
+			continue
+		}
+		// The directories of the links are created by the other containers of
+		// the reconciler Pod, which may not have started yet.
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+		dirs[dir] = true
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer func() {
+			_ = watcher.Close()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// A link replaced by a rename is reported as a Create event.
+				if !names[filepath.Clean(event.Name)] || event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+					continue
+				}
+				klog.V(3).Infof("Source link changed: %s", event)
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				klog.Warningf("Failed to watch the source links: %v", err)
+			}
+		}
+	}()
+	return changes, nil
+}
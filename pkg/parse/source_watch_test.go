@@ -0,0 +1,63 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchSourceLinks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	root := t.TempDir()
+	link := filepath.Join(root, "source", "rev")
+	changes, err := watchSourceLinks(ctx, link, filepath.Join(root, "hydrated", "rev"))
+	if err != nil {
+		t.Fatalf("watchSourceLinks() got error: %v", err)
+	}
+
+	// Unrelated files do not trigger a reimport.
+	if err := os.WriteFile(filepath.Join(root, "source", "error.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("got a change for an unrelated file")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Replace the link the way the syncers do.
+	commitDir := filepath.Join(root, "source", "abc123")
+	if err := os.Mkdir(commitDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	tmpLink := filepath.Join(root, "source", "tmp-link")
+	if err := os.Symlink(commitDir, tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the link change")
+	}
+}
@@ -15,18 +15,44 @@
 package parse
 
 import (
+	"context"
 	"math"
+	"math/rand"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/status"
 )
 
-const (
-	retriesBeforeStartingBackoff = 5
-	maxRetryInterval             = time.Duration(5) * time.Minute
-)
+// retryBackoff configures how the reconciler retries after errors of a
+// specific status.ErrorClass.
+type retryBackoff struct {
+	// retriesBeforeStartingBackoff is the number of retries with a 1 second
+	// interval before starting the exponential backoff.
+	retriesBeforeStartingBackoff int
+	// maxRetryInterval is the upper bound of the exponential backoff.
+	maxRetryInterval time.Duration
+}
+
+// retryBackoffs maps each error class to its retry behavior:
+//   - transient errors are retried quickly, and never wait more than a minute;
+//   - user errors are unlikely to be resolved until the source or the cluster
+//     changes, so the backoff starts right away;
+//   - terminal errors keep the default behavior.
+var retryBackoffs = map[status.ErrorClass]retryBackoff{
+	status.TransientErrorClass: {retriesBeforeStartingBackoff: 5, maxRetryInterval: time.Minute},
+	status.UserErrorClass:      {retriesBeforeStartingBackoff: 1, maxRetryInterval: 5 * time.Minute},
+	status.TerminalErrorClass:  {retriesBeforeStartingBackoff: 5, maxRetryInterval: 5 * time.Minute},
+}
+
+// retryJitterFactor is the largest fraction of the retry interval removed at
+// random, so that the reconcilers failing on a shared dependency, e.g. the API
+// server, don't all retry at the same time.
+const retryJitterFactor = 0.2
 
 type sourceStatus struct {
 	commit     string
@@ -40,30 +66,54 @@ func (gs sourceStatus) equal(other sourceStatus) bool {
 
 type renderingStatus struct {
 	commit     string
+	digest     string
 	message    string
 	errs       status.MultiError
 	lastUpdate metav1.Time
 }
 
 func (rs renderingStatus) equal(other renderingStatus) bool {
-	return rs.commit == other.commit && rs.message == other.message && status.DeepEqual(rs.errs, other.errs)
+	return rs.commit == other.commit && rs.digest == other.digest && rs.message == other.message && status.DeepEqual(rs.errs, other.errs)
 }
 
 type syncStatus struct {
-	syncing    bool
-	commit     string
-	errs       status.MultiError
+	syncing     bool
+	commit      string
+	errs        status.MultiError
+	watchHealth []v1beta1.WatchFailure
+	// orphanedResources are the resources found by the last orphan audit.
+	orphanedResources []v1beta1.ResourceRef
+	// resourceConsumption is the last resource consumption report.
+	resourceConsumption *v1beta1.ResourceConsumption
+	// clusterPrerequisites are the missing cluster prerequisites of the
+	// skipped objects.
+	clusterPrerequisites []v1beta1.ClusterPrerequisite
+	// selectorSkipped summarizes the objects skipped by their selectors.
+	selectorSkipped *v1beta1.SkippedObjects
+	// warnings are the problems of the sync which don't block it.
+	warnings   []v1beta1.SyncWarning
 	lastUpdate metav1.Time
+	// fullResync is true if the status is set after a periodic full resync.
+	fullResync bool
 }
 
 func (gs syncStatus) equal(other syncStatus) bool {
-	return gs.syncing == other.syncing && gs.commit == other.commit && status.DeepEqual(gs.errs, other.errs)
+	return gs.syncing == other.syncing && gs.commit == other.commit && status.DeepEqual(gs.errs, other.errs) &&
+		equality.Semantic.DeepEqual(gs.watchHealth, other.watchHealth) &&
+		equality.Semantic.DeepEqual(gs.orphanedResources, other.orphanedResources) &&
+		equality.Semantic.DeepEqual(gs.resourceConsumption, other.resourceConsumption) &&
+		equality.Semantic.DeepEqual(gs.clusterPrerequisites, other.clusterPrerequisites) &&
+		equality.Semantic.DeepEqual(gs.selectorSkipped, other.selectorSkipped) &&
+		equality.Semantic.DeepEqual(gs.warnings, other.warnings)
 }
 
 type reconcilerState struct {
 	// lastApplied keeps the state for the last successful-applied syncDir.
 	lastApplied string
 
+	// lastSnapshot is the commit of the last snapshot saved.
+	lastSnapshot string
+
 	// sourceStatus tracks info from the `Status.Source` field of a RepoSync/RootSync.
 	sourceStatus sourceStatus
 
@@ -78,6 +128,74 @@ type reconcilerState struct {
 
 	// cache tracks the progress made by the reconciler for a source commit.
 	cache cacheForCommit
+
+	// retriesExhausted indicates whether the RetriesExhausted condition is
+	// set on the RootSync or RepoSync.
+	retriesExhausted bool
+
+	// flapping tracks whether the sync alternated between failure and success
+	// too often recently, see updateFlapping.
+	flapping flapDetector
+
+	// retryStatus is the last `Status.Retry` set on the RootSync or RepoSync.
+	retryStatus *v1beta1.RetryStatus
+
+	// paused indicates whether the sync is paused by the sync-paused
+	// annotation of the RootSync or RepoSync, see pauseSync.
+	paused bool
+
+	// lastRuns tracks when the reconciler last ran for each trigger.
+	lastRuns map[string]time.Time
+
+	// runStages are the stages executed by the current run.
+	runStages []string
+
+	// approvedCommit is the last commit approved by the external approval
+	// system.
+	approvedCommit string
+
+	// lastDebugSnapshot is the value of the annotation which requested the
+	// last debug snapshot.
+	lastDebugSnapshot string
+
+	// lastClusterPrerequisiteCheck is when the reconciler last checked whether
+	// the missing cluster prerequisites became available.
+	lastClusterPrerequisiteCheck time.Time
+
+	// lastResyncRequest is the value of the annotation which requested the
+	// last selective resync.
+	lastResyncRequest string
+
+	// resetCacheToken is the value of the reset-cache annotation when the
+	// caches were last reset, see resetCacheOnRequest.
+	resetCacheToken string
+	// resetCacheTokenSeen indicates whether resetCacheToken was read once.
+	resetCacheTokenSeen bool
+
+	// lastOrphanAudit is when the last audit of the orphaned resources
+	// completed.
+	lastOrphanAudit time.Time
+
+	// orphanedResources are the resources found by the last orphan audit,
+	// which are managed by the reconciler, but missing from its inventory.
+	orphanedResources []v1beta1.ResourceRef
+
+	// lastConsumptionReport is when the resource consumption was last
+	// computed, and lastAPICallCount is the number of API calls made by the
+	// reconciler at that time.
+	lastConsumptionReport time.Time
+	lastAPICallCount      uint64
+
+	// resourceConsumption is the last resource consumption report.
+	resourceConsumption *v1beta1.ResourceConsumption
+
+	// supersededBy is the newer commit which the apply of the cached commit
+	// was stopped for, if any.
+	supersededBy string
+
+	// pendingTrigger is the external trigger which the reconciler is still
+	// waiting for a new commit for, see waitForNewCommit.
+	pendingTrigger *pendingTrigger
 }
 
 func (s *reconcilerState) checkpoint() {
@@ -90,6 +208,7 @@ func (s *reconcilerState) checkpoint() {
 	s.lastApplied = applied
 	s.cache.needToRetry = false
 	s.cache.reconciliationWithSameErrs = 0
+	s.cache.failures = 0
 	s.cache.nextRetryTime = time.Time{}
 	s.cache.errs = nil
 }
@@ -104,10 +223,18 @@ func (s *reconcilerState) reset() {
 	s.cache.nextRetryTime = time.Now().Add(time.Second)
 }
 
+// deferRetry schedules a retry at the given time, without invalidating the
+// reconciler checkpoint or recording any errors.
+func (s *reconcilerState) deferRetry(retryTime time.Time) {
+	s.cache.needToRetry = true
+	s.cache.nextRetryTime = retryTime
+}
+
 // invalidate logs the errors, clears the state tracking information.
 // invalidate does not clean up the `s.cache`.
-func (s *reconcilerState) invalidate(errs status.MultiError) {
-	klog.Errorf("Invalidating reconciler checkpoint: %v", status.FormatSingleLine(errs))
+func (s *reconcilerState) invalidate(ctx context.Context, errs status.MultiError) {
+	errClass := status.Classify(errs)
+	klog.Errorf("Invalidating reconciler checkpoint (%s errors): %v", errClass, status.FormatSingleLine(errs))
 	oldErrs := s.cache.errs
 	s.cache.errs = errs
 	// Invalidate state on error since this could be the result of switching
@@ -115,28 +242,44 @@ func (s *reconcilerState) invalidate(errs status.MultiError) {
 	// result in repeating a previous state that was checkpointed.
 	s.lastApplied = ""
 	s.cache.needToRetry = true
+	s.cache.failures++
 	if status.DeepEqual(oldErrs, s.cache.errs) {
 		s.cache.reconciliationWithSameErrs++
 	} else {
 		s.cache.reconciliationWithSameErrs = 1
 	}
-	s.cache.nextRetryTime = calculateNextRetryTime(s.cache.reconciliationWithSameErrs)
+	s.cache.errClass = errClass
+	s.cache.nextRetryTime = time.Now().Add(jitter(retryInterval(errClass, s.cache.reconciliationWithSameErrs)))
+	metrics.RecordReconcilerRetry(ctx, string(errClass))
 }
 
-func calculateNextRetryTime(retries int) time.Time {
+// retryInterval returns how long the reconciler should wait before the next
+// retry, after `retries` reconciliation attempts failed with the same errors.
+func retryInterval(errClass status.ErrorClass, retries int) time.Duration {
+	backoff, ok := retryBackoffs[errClass]
+	if !ok {
+		backoff = retryBackoffs[status.TerminalErrorClass]
+	}
 	// For the first several retries, the reconciler waits 1 second before retrying.
-	if retries <= retriesBeforeStartingBackoff {
-		return time.Now().Add(time.Second)
+	if retries <= backoff.retriesBeforeStartingBackoff {
+		return time.Second
 	}
 
-	// For the remaining retries, the reconciler does exponential backoff retry up to 5 minutes.
+	// For the remaining retries, the reconciler does exponential backoff retry up to maxRetryInterval.
 	// i.e., 1s, 2s, 4s, 8s, 16s, 32s, 64s, 128s, 256s, 5m, 5m, ...
-	seconds := int64(math.Pow(2, float64(retries-retriesBeforeStartingBackoff)))
+	seconds := int64(math.Pow(2, float64(retries-backoff.retriesBeforeStartingBackoff)))
 	duration := time.Duration(seconds) * time.Second
-	if duration > maxRetryInterval {
-		duration = maxRetryInterval
+	if duration > backoff.maxRetryInterval {
+		duration = backoff.maxRetryInterval
 	}
-	return time.Now().Add(duration)
+	return duration
+}
+
+// jitter returns the interval shortened by a random fraction of up to
+// retryJitterFactor, so that the jittered interval never exceeds the maximum
+// retry interval.
+func jitter(interval time.Duration) time.Duration {
+	return interval - time.Duration(rand.Float64()*retryJitterFactor*float64(interval))
 }
 
 // resetCache resets the whole cache.
@@ -179,8 +322,8 @@ func (s *reconcilerState) needToSetSyncStatus(newStatus syncStatus) bool {
 	if status.HasTransientErrors(newStatus.errs) {
 		return false
 	}
-	// Update if not initialized
-	if s.syncStatus.lastUpdate.IsZero() {
+	// Update if not initialized, or to record a full resync
+	if s.syncStatus.lastUpdate.IsZero() || newStatus.fullResync {
 		return true
 	}
 	// Update if sync status was last updated before the rendering status
@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"kpt.dev/configsync/pkg/status"
+)
+
+func TestRetryInterval(t *testing.T) {
+	testCases := []struct {
+		name     string
+		errClass status.ErrorClass
+		retries  int
+		want     time.Duration
+	}{
+		{
+			name:     "transient errors retry every second at first",
+			errClass: status.TransientErrorClass,
+			retries:  5,
+			want:     time.Second,
+		},
+		{
+			name:     "transient errors back off up to a minute",
+			errClass: status.TransientErrorClass,
+			retries:  20,
+			want:     time.Minute,
+		},
+		{
+			name:     "user errors start backing off after the first retry",
+			errClass: status.UserErrorClass,
+			retries:  3,
+			want:     4 * time.Second,
+		},
+		{
+			name:     "user errors back off up to 5 minutes",
+			errClass: status.UserErrorClass,
+			retries:  20,
+			want:     5 * time.Minute,
+		},
+		{
+			name:     "terminal errors back off after 5 retries",
+			errClass: status.TerminalErrorClass,
+			retries:  7,
+			want:     4 * time.Second,
+		},
+		{
+			name:     "unknown class falls back to terminal errors",
+			errClass: status.ErrorClass("unknown"),
+			retries:  20,
+			want:     5 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := retryInterval(tc.errClass, tc.retries); got != tc.want {
+				t.Errorf("retryInterval(%q, %d) = %v, want %v", tc.errClass, tc.retries, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// watchSupersedingCommit calls cancel as soon as a commit newer than `commit`
+// is fetched, rendered and validated, if SupersedeInFlightApply is enabled.
+// The hydration-controller renders the newer commit while the reconciler is
+// still applying `commit`, so the newer commit can be applied right away.
+// A newer commit which fails to validate does not stop the apply, because it
+// would not be applied either.
+//
+// The returned function stops the watch, and returns the newer commit if
+// cancel was called, or an empty string otherwise.
+func watchSupersedingCommit(ctx context.Context, p Parser, commit string, cancel context.CancelFunc) func() string {
+	opts := p.options()
+	if !opts.SupersedeInFlightApply {
+		return func() string { return "" }
+	}
+	ctx, stop := context.WithCancel(ctx)
+	result := make(chan string, 1)
+	go func() {
+		defer close(result)
+		// Use timers, not tickers.
+		// Tickers can cause memory leaks and continuous execution, when execution
+		// takes longer than the tick duration.
+		timer := time.NewTimer(opts.pollingPeriod.Get())
+		defer timer.Stop()
+		// invalidCommit is the last newer commit which failed to validate, to
+		// avoid parsing it again on every poll.
+		var invalidCommit string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				if next, syncDir, ready := nextCommitReady(opts, commit); ready && next != invalidCommit {
+					if errs := validateCommit(ctx, p, next, syncDir); errs != nil {
+						klog.Infof("Continuing applying commit %s, because commit %s failed to validate: %v", commit, next, errs)
+						invalidCommit = next
+					} else {
+						klog.Infof("Commit %s is ready to apply, stopping applying commit %s", next, commit)
+						result <- next
+						cancel()
+						return
+					}
+				}
+				timer.Reset(opts.pollingPeriod.Get())
+			}
+		}
+	}()
+	return func() string {
+		stop()
+		return <-result
+	}
+}
+
+// nextCommitReady returns the commit of the source and the absolute path to
+// its sync directory, if the commit differs from `commit` and its rendering
+// succeeded, in which case the reconciler can read and apply it right away.
+func nextCommitReady(opts *opts, commit string) (string, cmpath.Absolute, bool) {
+	next, syncDir, sourceErr := hydrate.SourceCommitAndDir(opts.SourceType, opts.SourceDir, opts.SyncDir, opts.reconcilerName)
+	if sourceErr != nil || next == commit {
+		return "", "", false
+	}
+	// The hydration-controller writes the done file once it is done with a
+	// commit, whether the commit needs rendering or not.
+	doneFilePath := opts.RepoRoot.Join(cmpath.RelativeSlash(hydrate.DoneFile)).OSPath()
+	if hydrate.DoneCommit(doneFilePath) != next {
+		return "", "", false
+	}
+	if opts.HydratedRoot == "" {
+		return next, syncDir, true
+	}
+	hydratedRoot := cmpath.Absolute(opts.HydratedRoot)
+	if _, err := os.Stat(hydratedRoot.OSPath()); os.IsNotExist(err) {
+		// The commit does not need rendering.
+		return next, syncDir, true
+	}
+	// Keep applying the current commit if the newer commit failed to render.
+	errorFile := hydratedRoot.Join(cmpath.RelativeSlash(hydrate.ErrorFile)).OSPath()
+	if _, err := os.Stat(errorFile); !os.IsNotExist(err) {
+		return "", "", false
+	}
+	hydratedDir, err := hydratedRoot.Join(cmpath.RelativeSlash(opts.HydratedLink)).EvalSymlinks()
+	if err != nil || filepath.Base(hydratedDir.OSPath()) != next {
+		return "", "", false
+	}
+	hydratedSyncDir, err := hydratedDir.Join(opts.SyncDir).EvalSymlinks()
+	if err != nil {
+		return "", "", false
+	}
+	return next, hydratedSyncDir, true
+}
+
+// validateCommit reads and parses the configs of `commit` under syncDir, and
+// returns the errors which would block applying it.
+//
+// It does not update the reconciler state, since the reconciler is still
+// applying the previous commit.
+func validateCommit(ctx context.Context, p Parser, commit string, syncDir cmpath.Absolute) status.MultiError {
+	opts := p.options()
+	ignoreMatcher, ignoreErr := opts.readIgnoreFile(syncDir)
+	if ignoreErr != nil {
+		return ignoreErr
+	}
+	files, err := listFiles(syncDir, map[string]bool{".git": true}, ignoreMatcher)
+	if err != nil {
+		return status.PathWrapError(errors.Wrap(err, "listing files in the configs directory"), syncDir.OSPath())
+	}
+	_, errs := p.parseSource(ctx, sourceState{
+		commit:  commit,
+		syncDir: syncDir,
+		files:   files,
+	})
+	if !status.HasBlockingErrors(errs) {
+		return nil
+	}
+	return errs
+}
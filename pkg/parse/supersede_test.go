@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/tunables"
+)
+
+func TestNextCommitReady(t *testing.T) {
+	testCases := []struct {
+		name         string
+		sourceCommit string
+		doneCommit   string
+		renderingErr bool
+		wantCommit   string
+		wantReady    bool
+	}{
+		{
+			name:         "same commit",
+			sourceCommit: "abc",
+			doneCommit:   "abc",
+		},
+		{
+			name:         "newer commit is rendering",
+			sourceCommit: "def",
+			doneCommit:   "abc",
+		},
+		{
+			name:         "newer commit failed to render",
+			sourceCommit: "def",
+			doneCommit:   "def",
+			renderingErr: true,
+		},
+		{
+			name:         "newer commit is ready",
+			sourceCommit: "def",
+			doneCommit:   "def",
+			wantCommit:   "def",
+			wantReady:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newSupersedeTestParser(t, tc.sourceCommit, tc.doneCommit, tc.renderingErr)
+			commit, _, ready := nextCommitReady(p.options(), "abc")
+			if commit != tc.wantCommit || ready != tc.wantReady {
+				t.Errorf("nextCommitReady() = (%q, %t), want (%q, %t)", commit, ready, tc.wantCommit, tc.wantReady)
+			}
+		})
+	}
+}
+
+func TestWatchSupersedingCommit(t *testing.T) {
+	testCases := []struct {
+		name       string
+		enabled    bool
+		invalid    bool
+		wantCommit string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:       "enabled",
+			enabled:    true,
+			wantCommit: "def",
+		},
+		{
+			name:    "newer commit fails to validate",
+			enabled: true,
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newSupersedeTestParser(t, "def", "def", false)
+			p.opts.SupersedeInFlightApply = tc.enabled
+			if tc.invalid {
+				if err := writeFile(filepath.Join(p.HydratedRoot, "def"), "ns.yaml", "kind: [invalid"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stop := watchSupersedingCommit(context.Background(), p, "abc", cancel)
+			if tc.wantCommit != "" {
+				select {
+				case <-ctx.Done():
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for the apply to be cancelled")
+				}
+			} else {
+				// Give the watch a few polls to (not) cancel the apply.
+				time.Sleep(100 * time.Millisecond)
+			}
+			if commit := stop(); commit != tc.wantCommit {
+				t.Errorf("watchSupersedingCommit() got commit %q, want %q", commit, tc.wantCommit)
+			}
+			if tc.wantCommit == "" && ctx.Err() != nil {
+				t.Error("watchSupersedingCommit() cancelled the apply unexpectedly")
+			}
+		})
+	}
+}
+
+func newSupersedeTestParser(t *testing.T, sourceCommit, doneCommit string, renderingErr bool) *root {
+	t.Helper()
+	rootDir := t.TempDir()
+	sourceRoot := filepath.Join(rootDir, "source")
+	hydratedRoot := filepath.Join(rootDir, "hydrated")
+	if err := createRootDir(sourceRoot, sourceCommit); err != nil {
+		t.Fatal(err)
+	}
+	if err := createRootDir(hydratedRoot, doneCommit); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(rootDir, hydrate.DoneFile, doneCommit); err != nil {
+		t.Fatal(err)
+	}
+	if renderingErr {
+		if err := writeFile(hydratedRoot, hydrate.ErrorFile, `{"code": "1068", "error": "rendering error"}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := newParser(t, FileSource{
+		SourceDir:    cmpath.Absolute(filepath.Join(sourceRoot, symLink)),
+		RepoRoot:     cmpath.Absolute(rootDir),
+		HydratedRoot: hydratedRoot,
+		HydratedLink: symLink,
+		SourceType:   v1beta1.GitSource,
+	}).(*root)
+	p.pollingPeriod = tunables.NewDuration(10 * time.Millisecond)
+	return p
+}
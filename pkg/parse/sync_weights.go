@@ -0,0 +1,105 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"sort"
+	"strconv"
+
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+// addSyncWeightDependencies converts the sync weight annotations into
+// depends-on annotations, so that the applier applies the objects in the
+// order of their weights: each object depends on all the objects with the
+// next lower weight, and so transitively on all the objects with lower
+// weights. Objects without the annotation have a weight of 0.
+//
+// Namespaces and CustomResourceDefinitions are ignored, because the applier
+// always applies them before the objects that need them. Ordering them by
+// weight could otherwise create a dependency cycle.
+func addSyncWeightDependencies(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+	waves := make(map[int][]ast.FileObject)
+	weighted := false
+	for _, obj := range objs {
+		if differ.ManagementDisabled(obj) || !isWeighted(obj) {
+			continue
+		}
+		weight := 0
+		if value, found := obj.GetAnnotations()[metadata.SyncWeightKey]; found {
+			// Invalid weights are reported by the validation.
+			w, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			weight = w
+			weighted = true
+		}
+		waves[weight] = append(waves[weight], obj)
+	}
+	if !weighted || len(waves) < 2 {
+		return objs, nil
+	}
+
+	weights := make([]int, 0, len(waves))
+	for weight := range waves {
+		weights = append(weights, weight)
+	}
+	sort.Ints(weights)
+
+	var errs status.MultiError
+	for i := 1; i < len(weights); i++ {
+		previous := waves[weights[i-1]]
+		for _, obj := range waves[weights[i]] {
+			depSet, err := dependson.ReadAnnotation(obj.Unstructured)
+			if err != nil {
+				errs = status.Append(errs, status.ResourceErrorBuilder.Wrap(err).BuildWithResources(obj))
+				continue
+			}
+			for _, prev := range previous {
+				id := core.IDOf(prev)
+				dep := object.ObjMetadata{GroupKind: id.GroupKind, Namespace: id.Namespace, Name: id.Name}
+				if !containsObjMetadata(depSet, dep) {
+					depSet = append(depSet, dep)
+				}
+			}
+			if err := dependson.WriteAnnotation(obj.Unstructured, depSet); err != nil {
+				errs = status.Append(errs, status.InternalErrorBuilder.Wrap(err).BuildWithResources(obj))
+				continue
+			}
+		}
+		klog.V(3).Infof("Objects with sync weight %d depend on the %d objects with sync weight %d",
+			weights[i], len(previous), weights[i-1])
+	}
+	return objs, errs
+}
+
+// isWeighted returns true if the object is ordered by its sync weight.
+func isWeighted(obj ast.FileObject) bool {
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+	case kinds.Namespace().GroupKind(), kinds.CustomResourceDefinition():
+		return false
+	default:
+		return true
+	}
+}
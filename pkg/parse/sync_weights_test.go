@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+func TestAddSyncWeightDependencies(t *testing.T) {
+	weight := func(w string) core.MetaMutator {
+		return core.Annotation(metadata.SyncWeightKey, w)
+	}
+
+	testCases := []struct {
+		name     string
+		objs     []ast.FileObject
+		wantDeps map[string]string
+	}{
+		{
+			name: "no weights",
+			objs: []ast.FileObject{
+				fake.Unstructured(kinds.ConfigMap(), core.Namespace("bookstore"), core.Name("config")),
+				fake.Unstructured(kinds.Deployment(), core.Namespace("bookstore"), core.Name("app")),
+			},
+		},
+		{
+			name: "objects depend on the next lower weight",
+			objs: []ast.FileObject{
+				fake.Unstructured(kinds.Namespace(), core.Name("bookstore"), weight("5")),
+				fake.Unstructured(kinds.ServiceAccount(), core.Namespace("bookstore"), core.Name("sa"), weight("-1")),
+				fake.Unstructured(kinds.ConfigMap(), core.Namespace("bookstore"), core.Name("config")),
+				fake.Unstructured(kinds.Deployment(), core.Namespace("bookstore"), core.Name("app"), weight("1")),
+				fake.Unstructured(kinds.Service(), core.Namespace("bookstore"), core.Name("svc"), weight("1"),
+					core.Annotation(dependson.Annotation, "/namespaces/bookstore/Secret/creds")),
+				fake.Unstructured(kinds.Job(), core.Namespace("bookstore"), core.Name("migrate"), weight("2")),
+				fake.Unstructured(kinds.Job(), core.Namespace("bookstore"), core.Name("skipped"), weight("3"),
+					core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)),
+			},
+			wantDeps: map[string]string{
+				"config":  "/namespaces/bookstore/ServiceAccount/sa",
+				"app":     "/namespaces/bookstore/ConfigMap/config",
+				"svc":     "/namespaces/bookstore/Secret/creds,/namespaces/bookstore/ConfigMap/config",
+				"migrate": "apps/namespaces/bookstore/Deployment/app,/namespaces/bookstore/Service/svc",
+			},
+		},
+		{
+			name: "invalid weights are ignored",
+			objs: []ast.FileObject{
+				fake.Unstructured(kinds.ConfigMap(), core.Namespace("bookstore"), core.Name("config"), weight("first")),
+				fake.Unstructured(kinds.Deployment(), core.Namespace("bookstore"), core.Name("app")),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs, errs := addSyncWeightDependencies(tc.objs)
+			if errs != nil {
+				t.Fatalf("addSyncWeightDependencies() got unexpected errors: %v", errs)
+			}
+			gotDeps := make(map[string]string)
+			for _, obj := range objs {
+				if dep, found := obj.GetAnnotations()[dependson.Annotation]; found {
+					gotDeps[obj.GetName()] = dep
+				}
+			}
+			wantDeps := tc.wantDeps
+			if wantDeps == nil {
+				wantDeps = map[string]string{}
+			}
+			if diff := cmp.Diff(wantDeps, gotDeps); diff != "" {
+				t.Errorf("unexpected dependencies (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncwindow"
+)
+
+// syncPendingReason is the reason of the Syncing condition of a RootSync or
+// RepoSync whose new commit waits for a sync window, or for the cluster
+// control plane to settle after an upgrade.
+const syncPendingReason = "SyncPending"
+
+// outsideSyncWindow returns true if the sync windows of the RootSync or
+// RepoSync do not allow applying changes at the time.
+func outsideSyncWindow(p Parser, now time.Time) bool {
+	windows := p.options().SyncWindows
+	return len(windows) > 0 && !syncwindow.Allowed(windows, now)
+}
+
+// nextSyncWindowCheck returns when the sync windows are checked again. The
+// windows open and close on the minute.
+func nextSyncWindowCheck(now time.Time) time.Time {
+	return now.Truncate(time.Minute).Add(time.Minute)
+}
+
+// holdForSyncWindow reports in the Syncing condition of the RootSync or
+// RepoSync that the commit waits for a sync window, and returns true, unless
+// the commit was already synced, e.g. before the reconciler restarted. The
+// commit is held if the RSync cannot be read.
+func holdForSyncWindow(ctx context.Context, p Parser, commit string) (bool, status.Error) {
+	return holdCommit(ctx, p, commit, fmt.Sprintf("SyncPending (outside window): commit %s is applied once the sync windows allow it", commit))
+}
+
+// holdCommit reports in the Syncing condition of the RootSync or RepoSync
+// that the commit is pending with the message, and returns true, unless the
+// commit was already synced, e.g. before the reconciler restarted. The commit
+// is held if the RSync cannot be read.
+func holdCommit(ctx context.Context, p Parser, commit, message string) (bool, status.Error) {
+	opts := p.options()
+	opts.mux.Lock()
+	defer opts.mux.Unlock()
+
+	rs, err := getRSync(ctx, opts)
+	if err != nil {
+		return true, status.APIServerError(err, "failed to get the RSync to report the pending sync")
+	}
+	var updated bool
+	switch rs := rs.(type) {
+	case *v1beta1.RootSync:
+		if rs.Status.LastSyncedCommit == commit {
+			return false, nil
+		}
+		updated, _ = rootsync.SetSyncing(rs, false, syncPendingReason, message, commit, nil, nil, metav1.Now())
+	case *v1beta1.RepoSync:
+		if rs.Status.LastSyncedCommit == commit {
+			return false, nil
+		}
+		updated, _ = reposync.SetSyncing(rs, false, syncPendingReason, message, commit, nil, nil, metav1.Now())
+	}
+	if !updated {
+		return true, nil
+	}
+	if err := opts.k8sClient().Status().Update(ctx, rs); err != nil {
+		return true, status.APIServerError(err, "failed to report the pending sync")
+	}
+	return true, nil
+}
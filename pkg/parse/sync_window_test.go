@@ -0,0 +1,69 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/syncwindow"
+)
+
+func TestOutsideSyncWindow(t *testing.T) {
+	// Monday.
+	now := time.Date(2022, 11, 7, 12, 0, 0, 0, time.UTC)
+	windows, err := syncwindow.Parse([]v1beta1.SyncWindow{{
+		Kind:     v1beta1.SyncWindowAllow,
+		Schedule: "0 22 * * 1-5",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}})
+	require.NoError(t, err)
+
+	require.False(t, outsideSyncWindow(newParser(t, FileSource{}), now))
+	p := newParser(t, FileSource{})
+	p.options().SyncWindows = windows
+	require.True(t, outsideSyncWindow(p, now))
+	require.False(t, outsideSyncWindow(p, now.Add(11*time.Hour)))
+	require.Equal(t, now.Add(time.Minute), nextSyncWindowCheck(now.Add(30*time.Second)))
+}
+
+func TestHoldForSyncWindow(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+
+	held, err := holdForSyncWindow(ctx, p, "abc123")
+	require.NoError(t, err)
+	require.True(t, held)
+	rs := &v1beta1.RootSync{}
+	require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+	cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, syncPendingReason, cond.Reason)
+	require.Contains(t, cond.Message, "SyncPending (outside window)")
+	require.Equal(t, "abc123", cond.Commit)
+
+	// The commit synced before the reconciler restarted is not held.
+	rs.Status.LastSyncedCommit = "def456"
+	require.NoError(t, p.options().k8sClient().Status().Update(ctx, rs))
+	held, err = holdForSyncWindow(ctx, p, "def456")
+	require.NoError(t, err)
+	require.False(t, held)
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// The attributes of the spans of the reconciler.
+const (
+	// spanAttrTrigger is the trigger of the run, e.g. triggerResync.
+	spanAttrTrigger = "configsync.trigger"
+	// spanAttrCommit is the source commit processed by the span.
+	spanAttrCommit = "configsync.commit"
+	// spanAttrResult is the result of the run, e.g. runSucceeded.
+	spanAttrResult = "configsync.result"
+	// spanAttrErrorClass is the class of the errors of the span: transient,
+	// user or terminal.
+	spanAttrErrorClass = "configsync.error_class"
+)
+
+// startSpan starts the span of a step of a run of the reconciler, so that a
+// single sync attempt can be traced from the run down to the update.
+func startSpan(ctx context.Context, name, trigger string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, "reconciler/"+name)
+	span.AddAttributes(trace.StringAttribute(spanAttrTrigger, trigger))
+	return ctx, span
+}
+
+// endSpan ends the span, with the commit it processed and the class of its
+// errors, if any.
+func endSpan(span *trace.Span, commit string, errs status.MultiError) {
+	if commit != "" {
+		span.AddAttributes(trace.StringAttribute(spanAttrCommit, commit))
+	}
+	if errs != nil {
+		span.AddAttributes(trace.StringAttribute(spanAttrErrorClass, string(status.Classify(errs))))
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: errs.Error()})
+	}
+	span.End()
+}
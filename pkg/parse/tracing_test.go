@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/trace"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// spanRecorder records the exported spans.
+type spanRecorder struct {
+	mux   sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func TestSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+
+	ctx, runSpan := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	_, readSpan := startSpan(ctx, "read", triggerResync)
+	endSpan(readSpan, "abc123", status.SourceError.Wrap(errors.New("unreadable")).Build())
+	runSpan.AddAttributes(trace.StringAttribute(spanAttrResult, runFailed))
+	endSpan(runSpan, "", nil)
+
+	require.Len(t, recorder.spans, 2)
+	read, run := recorder.spans[0], recorder.spans[1]
+	require.Equal(t, "reconciler/read", read.Name)
+	require.Equal(t, run.SpanContext.TraceID, read.SpanContext.TraceID)
+	require.Equal(t, run.SpanContext.SpanID, read.ParentSpanID)
+	require.Equal(t, triggerResync, read.Attributes[spanAttrTrigger])
+	require.Equal(t, "abc123", read.Attributes[spanAttrCommit])
+	require.Equal(t, string(status.UserErrorClass), read.Attributes[spanAttrErrorClass])
+	require.Equal(t, int32(trace.StatusCodeUnknown), read.Status.Code)
+	require.Equal(t, runFailed, run.Attributes[spanAttrResult])
+	require.NotContains(t, run.Attributes, spanAttrErrorClass)
+}
@@ -39,9 +39,27 @@ type updater struct {
 	resources  *declared.Resources
 	remediator remediator.Interface
 	applier    applier.Applier
+	// namespaceReader reads the Namespaces on the cluster, to check whether
+	// they are frozen, and the objects in the inventory of frozen Namespaces.
+	// Namespace freezing is disabled if nil.
+	namespaceReader client.Client
+	// inventory is the key of the ResourceGroup inventory of the reconciler.
+	inventory client.ObjectKey
+	// referenceReader reads the reference-only objects on the cluster, to
+	// verify them. Reference-only objects are declared as they are if nil.
+	referenceReader client.Reader
+	// selfUpdate stages and health checks the changes to the Config Sync
+	// components declared in the source. Disabled if nil.
+	selfUpdate *selfUpdate
+	// normalizer normalizes the declarations before comparing them with their
+	// previous declarations, e.g. for frozen namespaces. Disabled if nil.
+	normalizer *declared.ValueConverter
 
 	errorMux       sync.RWMutex
 	validationErrs status.MultiError
+	freezeErrs     status.MultiError
+	referenceErrs  status.MultiError
+	selfUpdateErrs status.MultiError
 	watchErrs      status.MultiError
 
 	updateMux sync.RWMutex
@@ -66,6 +84,9 @@ func (u *updater) Errors() status.MultiError {
 	errs = status.Append(errs, u.conflictErrors())
 	errs = status.Append(errs, u.fightErrors())
 	errs = status.Append(errs, u.validationErrs)
+	errs = status.Append(errs, u.freezeErrs)
+	errs = status.Append(errs, u.referenceErrs)
+	errs = status.Append(errs, u.selfUpdateErrs)
 	errs = status.Append(errs, u.applier.Errors())
 	errs = status.Append(errs, u.watchErrs)
 	return errs
@@ -97,6 +118,24 @@ func (u *updater) setValidationErrs(errs status.MultiError) {
 	u.validationErrs = errs
 }
 
+func (u *updater) setFreezeErrs(errs status.MultiError) {
+	u.errorMux.Lock()
+	defer u.errorMux.Unlock()
+	u.freezeErrs = errs
+}
+
+func (u *updater) setReferenceErrs(errs status.MultiError) {
+	u.errorMux.Lock()
+	defer u.errorMux.Unlock()
+	u.referenceErrs = errs
+}
+
+func (u *updater) setSelfUpdateErrs(errs status.MultiError) {
+	u.errorMux.Lock()
+	defer u.errorMux.Unlock()
+	u.selfUpdateErrs = errs
+}
+
 func (u *updater) setWatchErrs(errs status.MultiError) {
 	u.errorMux.Lock()
 	defer u.errorMux.Unlock()
@@ -164,18 +203,44 @@ func (u *updater) update(ctx context.Context, cache *cacheForCommit) status.Mult
 	// Queued objects will be remediated when the workers are started again.
 	u.remediator.Pause()
 
+	// Changes to the objects in frozen Namespaces, and reverted changes to the
+	// Config Sync components, are skipped, but still reported as errors, so
+	// that the update is retried until the freeze is lifted or the components
+	// are fixed.
+	var skipErrs status.MultiError
+
 	// Update the declared resources (source of truth for the Remediator).
 	// After this, any objects removed from the declared resources will no
 	// longer be remediated, if they drift.
 	if !cache.declaredResourcesUpdated {
 		objs := filesystem.AsCoreObjects(cache.objsToApply)
+		objs, skipped, freezeErr := u.freeze(ctx, objs)
+		if freezeErr != nil {
+			return freezeErr
+		}
+		u.setFreezeErrs(skipped)
+		// Reference-only objects are verified, but never applied or pruned.
+		objs, mismatched, referenceErr := u.references(ctx, objs)
+		if referenceErr != nil {
+			return referenceErr
+		}
+		u.setReferenceErrs(mismatched)
+		skipped = status.Append(skipped, mismatched)
+		// Changes to the Config Sync components are applied last, and only
+		// kept if the components stay healthy.
+		objs, reverted, stageErr := u.stageSelfUpdate(ctx, objs, cache.source.commit)
+		if stageErr != nil {
+			return stageErr
+		}
+		u.setSelfUpdateErrs(reverted)
+		skipErrs = status.Append(skipped, reverted)
 		_, err := u.declare(ctx, objs, cache.source.commit)
 		if err != nil {
 			return err
 		}
-		// Only mark the declared resources as updated if there were no (non-blocking) parse errors.
-		// This ensures the update will be retried until parsing fully succeeds.
-		if cache.parserErrs == nil {
+		// Only mark the declared resources as updated if there were no (non-blocking) parse or skip errors.
+		// This ensures the update will be retried until parsing fully succeeds and the skipped changes can be synced.
+		if cache.parserErrs == nil && skipErrs == nil {
 			cache.declaredResourcesUpdated = true
 		}
 	}
@@ -187,9 +252,20 @@ func (u *updater) update(ctx context.Context, cache *cacheForCommit) status.Mult
 		if err != nil {
 			return err
 		}
-		// Only mark the commit as applied if there were no (non-blocking) parse errors.
-		// This ensures the apply will be retried until parsing fully succeeds.
-		if cache.parserErrs == nil {
+		reverted, err := u.checkSelfUpdate(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted != nil {
+			u.setSelfUpdateErrs(reverted)
+			// Declare the commit again on retry, so that the reverted changes
+			// keep being reported.
+			cache.declaredResourcesUpdated = false
+			return reverted
+		}
+		// Only mark the commit as applied if there were no (non-blocking) parse or skip errors.
+		// This ensures the apply will be retried until parsing fully succeeds and the skipped changes can be synced.
+		if cache.parserErrs == nil && skipErrs == nil {
 			cache.applied = true
 		}
 	}
@@ -201,9 +277,9 @@ func (u *updater) update(ctx context.Context, cache *cacheForCommit) status.Mult
 		if err != nil {
 			return err
 		}
-		// Only mark the watches as updated if there were no (non-blocking) parse errors.
-		// This ensures the update will be retried until parsing fully succeeds.
-		if cache.parserErrs == nil {
+		// Only mark the watches as updated if there were no (non-blocking) parse or skip errors.
+		// This ensures the update will be retried until parsing fully succeeds and the skipped changes can be synced.
+		if cache.parserErrs == nil && skipErrs == nil {
 			cache.watchesUpdated = true
 		}
 	}
@@ -215,7 +291,7 @@ func (u *updater) update(ctx context.Context, cache *cacheForCommit) status.Mult
 	// otherwise the objects may be updated in the wrong order (dependencies).
 	u.remediator.Resume()
 
-	return nil
+	return skipErrs
 }
 
 func (u *updater) declare(ctx context.Context, objs []client.Object, commit string) ([]client.Object, status.MultiError) {
@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// controlPlaneDiscovery is the subset of the discovery client used to detect
+// control-plane upgrades.
+type controlPlaneDiscovery interface {
+	discovery.ServerVersionInterface
+	discovery.ServerGroupsInterface
+}
+
+// upgradeDetector detects in-progress upgrades of the cluster control plane,
+// by watching for changes of the API server version and of the served
+// API group versions (discovery churn).
+//
+// After a change is detected, applying new commits is held off until the
+// control plane has not changed for settlePeriod, to avoid half-applied
+// commits against a flapping API server. Drift correction by the remediator
+// is not affected.
+type upgradeDetector struct {
+	discovery    controlPlaneDiscovery
+	settlePeriod time.Duration
+	now          func() time.Time
+
+	// fingerprint identifies the last observed control plane state.
+	fingerprint string
+	// settleUntil is when the current settle period ends.
+	settleUntil time.Time
+}
+
+// newUpgradeDetector returns an upgradeDetector, or nil if the settle period
+// is not positive, which disables the upgrade detection.
+func newUpgradeDetector(dc controlPlaneDiscovery, settlePeriod time.Duration) *upgradeDetector {
+	if settlePeriod <= 0 {
+		return nil
+	}
+	return &upgradeDetector{
+		discovery:    dc,
+		settlePeriod: settlePeriod,
+		now:          time.Now,
+	}
+}
+
+// settling checks the control plane for changes, and returns whether applying
+// new commits should be held off, and until when.
+//
+// A nil upgradeDetector never holds off applying.
+func (d *upgradeDetector) settling() (bool, time.Time) {
+	if d == nil {
+		return false, time.Time{}
+	}
+	now := d.now()
+	fingerprint, err := d.controlPlaneFingerprint()
+	switch {
+	case err != nil:
+		// An unreachable or flapping API server is a sign of an in-progress
+		// upgrade too.
+		klog.Warningf("Failed to discover the control plane, assuming it is being upgraded: %v", err)
+		d.settleUntil = now.Add(d.settlePeriod)
+	case d.fingerprint == "":
+		// The first observation establishes the baseline.
+		d.fingerprint = fingerprint
+	case fingerprint != d.fingerprint:
+		klog.Infof("The API server version or the served API group versions changed, holding off applying new commits for %v",
+			d.settlePeriod)
+		d.fingerprint = fingerprint
+		d.settleUntil = now.Add(d.settlePeriod)
+	}
+	return now.Before(d.settleUntil), d.settleUntil
+}
+
+// controlPlaneFingerprint returns a string which changes whenever the
+// API server version or the set of served API group versions changes.
+func (d *upgradeDetector) controlPlaneFingerprint() (string, error) {
+	info, err := d.discovery.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	groups, err := d.discovery.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	var groupVersions []string
+	for _, group := range groups.Groups {
+		for _, gv := range group.Versions {
+			groupVersions = append(groupVersions, gv.GroupVersion)
+		}
+	}
+	sort.Strings(groupVersions)
+	return serverVersionString(info) + ";" + strings.Join(groupVersions, ","), nil
+}
+
+func serverVersionString(info *version.Info) string {
+	if info == nil {
+		return ""
+	}
+	return info.GitVersion
+}
+
+// holdForUpgrade reports in the Syncing condition of the RootSync or RepoSync
+// that the commit waits for the cluster control plane to settle after an
+// upgrade, and returns true, unless the commit was already synced.
+func holdForUpgrade(ctx context.Context, p Parser, commit string, settleUntil time.Time) (bool, status.Error) {
+	return holdCommit(ctx, p, commit, fmt.Sprintf("SyncPending (upgrade settling): commit %s is applied once the cluster control plane settles after an upgrade, at %s",
+		commit, settleUntil.Format(time.RFC3339)))
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/rootsync"
+)
+
+type fakeControlPlaneDiscovery struct {
+	version *version.Info
+	groups  *metav1.APIGroupList
+	err     error
+}
+
+func (f *fakeControlPlaneDiscovery) ServerVersion() (*version.Info, error) {
+	return f.version, f.err
+}
+
+func (f *fakeControlPlaneDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	return f.groups, f.err
+}
+
+func apiGroupList(groupVersions ...string) *metav1.APIGroupList {
+	list := &metav1.APIGroupList{}
+	for _, gv := range groupVersions {
+		list.Groups = append(list.Groups, metav1.APIGroup{
+			Versions: []metav1.GroupVersionForDiscovery{{GroupVersion: gv}},
+		})
+	}
+	return list
+}
+
+func TestUpgradeDetectorDisabled(t *testing.T) {
+	d := newUpgradeDetector(&fakeControlPlaneDiscovery{}, 0)
+	if d != nil {
+		t.Fatalf("got %v, want nil upgradeDetector for a zero settle period", d)
+	}
+	if settling, _ := d.settling(); settling {
+		t.Errorf("a nil upgradeDetector should never hold off applying")
+	}
+}
+
+func TestUpgradeDetectorSettling(t *testing.T) {
+	dc := &fakeControlPlaneDiscovery{
+		version: &version.Info{GitVersion: "v1.24.3"},
+		groups:  apiGroupList("v1", "apps/v1"),
+	}
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	d := newUpgradeDetector(dc, 10*time.Minute)
+	d.now = func() time.Time { return now }
+
+	if settling, _ := d.settling(); settling {
+		t.Fatalf("the first observation should not hold off applying")
+	}
+
+	// API server version change.
+	now = now.Add(time.Minute)
+	dc.version = &version.Info{GitVersion: "v1.25.0"}
+	settling, settleUntil := d.settling()
+	if !settling {
+		t.Fatalf("an API server version change should hold off applying")
+	}
+	if want := now.Add(10 * time.Minute); !settleUntil.Equal(want) {
+		t.Errorf("got settleUntil %v, want %v", settleUntil, want)
+	}
+
+	// Discovery churn extends the settle period.
+	now = now.Add(5 * time.Minute)
+	dc.groups = apiGroupList("v1", "apps/v1", "batch/v1")
+	if _, settleUntil = d.settling(); !settleUntil.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("got settleUntil %v, want the settle period to be extended by discovery churn", settleUntil)
+	}
+
+	// Discovery errors extend the settle period too.
+	now = now.Add(5 * time.Minute)
+	dc.err = errors.New("connection refused")
+	if settling, _ = d.settling(); !settling {
+		t.Errorf("a discovery error should hold off applying")
+	}
+
+	// The control plane is stable for the whole settle period.
+	dc.err = nil
+	now = now.Add(10 * time.Minute)
+	if settling, _ = d.settling(); settling {
+		t.Errorf("applying should resume after the settle period")
+	}
+}
+
+func TestHoldForUpgrade(t *testing.T) {
+	ctx := context.Background()
+	p := newParser(t, FileSource{})
+	settleUntil := time.Date(2022, 11, 7, 12, 0, 0, 0, time.UTC)
+
+	held, err := holdForUpgrade(ctx, p, "abc123", settleUntil)
+	require.NoError(t, err)
+	require.True(t, held)
+	rs := &v1beta1.RootSync{}
+	require.NoError(t, p.options().k8sClient().Get(ctx, rootsync.ObjectKey(rootSyncName), rs))
+	cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, syncPendingReason, cond.Reason)
+	require.Contains(t, cond.Message, "SyncPending (upgrade settling)")
+	require.Contains(t, cond.Message, "2022-11-07T12:00:00Z")
+	require.Equal(t, "abc123", cond.Commit)
+}
@@ -0,0 +1,187 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// certManagerInjectCAFromAnnotation is the cert-manager cainjector
+	// annotation which references the Certificate whose CA is injected into
+	// the caBundle of a webhook configuration.
+	certManagerInjectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// certManagerCertificate is the GroupKind of cert-manager Certificates.
+var certManagerCertificate = schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+
+// addWebhookDependencies adds depends-on annotations to the webhook
+// configurations which reference Services or cert-manager Certificates declared
+// in the same source. The applier then applies the webhook configurations only
+// after their backend is ready:
+//   - the Service and the workloads selected by the Service are reconciled, so
+//     the Service has ready endpoints.
+//   - the Certificate is ready, so the CA bundle can be injected.
+//
+// Otherwise, the admission webhooks would reject the requests until their
+// backends are ready, causing apply failures until the next retry.
+func addWebhookDependencies(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+	// Objects with management disabled are not applied, so they cannot be
+	// dependencies.
+	declared := make(map[core.ID]ast.FileObject, len(objs))
+	for _, obj := range objs {
+		if differ.ManagementDisabled(obj) {
+			continue
+		}
+		declared[core.IDOf(obj)] = obj
+	}
+
+	var errs status.MultiError
+	for _, obj := range objs {
+		gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+		if gk != kinds.ValidatingWebhookConfiguration().GroupKind() && gk != kinds.MutatingWebhookConfiguration().GroupKind() {
+			continue
+		}
+		deps := webhookDependencies(obj, objs, declared)
+		if len(deps) == 0 {
+			continue
+		}
+		depSet, err := dependson.ReadAnnotation(obj.Unstructured)
+		if err != nil {
+			errs = status.Append(errs, status.ResourceErrorBuilder.Wrap(err).BuildWithResources(obj))
+			continue
+		}
+		for _, dep := range deps {
+			if !containsObjMetadata(depSet, dep) {
+				depSet = append(depSet, dep)
+			}
+		}
+		if err := dependson.WriteAnnotation(obj.Unstructured, depSet); err != nil {
+			errs = status.Append(errs, status.InternalErrorBuilder.Wrap(err).BuildWithResources(obj))
+			continue
+		}
+		klog.V(3).Infof("Added dependencies to %s: %v", core.IDOf(obj), depSet)
+	}
+	return objs, errs
+}
+
+// webhookDependencies returns the declared objects which the webhook
+// configuration depends on.
+func webhookDependencies(webhookConfig ast.FileObject, objs []ast.FileObject, declared map[core.ID]ast.FileObject) []object.ObjMetadata {
+	var deps []object.ObjMetadata
+	addDep := func(id core.ID) {
+		dep := object.ObjMetadata{GroupKind: id.GroupKind, Namespace: id.Namespace, Name: id.Name}
+		if !containsObjMetadata(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+
+	webhooks, _, _ := unstructured.NestedSlice(webhookConfig.Object, "webhooks")
+	for _, webhook := range webhooks {
+		webhookMap, ok := webhook.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		svcNamespace, _, _ := unstructured.NestedString(webhookMap, "clientConfig", "service", "namespace")
+		svcName, _, _ := unstructured.NestedString(webhookMap, "clientConfig", "service", "name")
+		if svcName == "" {
+			continue
+		}
+		svcID := core.ID{
+			GroupKind: kinds.Service().GroupKind(),
+			ObjectKey: client.ObjectKey{Namespace: svcNamespace, Name: svcName},
+		}
+		svc, found := declared[svcID]
+		if !found {
+			continue
+		}
+		addDep(svcID)
+		for _, workload := range selectedWorkloads(svc, objs) {
+			addDep(core.IDOf(workload))
+		}
+	}
+
+	if ref := webhookConfig.GetAnnotations()[certManagerInjectCAFromAnnotation]; ref != "" {
+		if ns, name, found := strings.Cut(ref, "/"); found {
+			certID := core.ID{
+				GroupKind: certManagerCertificate,
+				ObjectKey: client.ObjectKey{Namespace: ns, Name: name},
+			}
+			if _, declaredCert := declared[certID]; declaredCert {
+				addDep(certID)
+			}
+		}
+	}
+	return deps
+}
+
+// selectedWorkloads returns the declared workloads whose Pods are selected by
+// the Service.
+func selectedWorkloads(svc ast.FileObject, objs []ast.FileObject) []ast.FileObject {
+	selector, _, _ := unstructured.NestedStringMap(svc.Object, "spec", "selector")
+	if len(selector) == 0 {
+		return nil
+	}
+	var result []ast.FileObject
+	for _, obj := range objs {
+		if obj.GetNamespace() != svc.GetNamespace() {
+			continue
+		}
+		switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+		case kinds.Deployment().GroupKind(), kinds.StatefulSet().GroupKind(), kinds.DaemonSet().GroupKind():
+		default:
+			continue
+		}
+		if differ.ManagementDisabled(obj) {
+			continue
+		}
+		podLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+		if matchesSelector(podLabels, selector) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+func matchesSelector(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func containsObjMetadata(set []object.ObjMetadata, obj object.ObjMetadata) bool {
+	for _, o := range set {
+		if o == obj {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+func webhookConfig(gvk schema.GroupVersionKind, svcNamespace, svcName string, opts ...core.MetaMutator) ast.FileObject {
+	obj := fake.Unstructured(gvk, append(opts, core.Name("webhook"))...)
+	_ = unstructured.SetNestedSlice(obj.Object, []interface{}{
+		map[string]interface{}{
+			"name": "webhook.example.com",
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"namespace": svcNamespace,
+					"name":      svcName,
+				},
+			},
+		},
+	}, "webhooks")
+	return obj
+}
+
+func withNestedStringMap(obj ast.FileObject, value map[string]string, fields ...string) ast.FileObject {
+	_ = unstructured.SetNestedStringMap(obj.Object, value, fields...)
+	return obj
+}
+
+func TestAddWebhookDependencies(t *testing.T) {
+	svc := withNestedStringMap(fake.Unstructured(kinds.Service(), core.Namespace("webhook-system"), core.Name("webhook-svc")),
+		map[string]string{"app": "webhook"}, "spec", "selector")
+	deployment := withNestedStringMap(fake.Unstructured(kinds.Deployment(), core.Namespace("webhook-system"), core.Name("webhook")),
+		map[string]string{"app": "webhook", "version": "v1"}, "spec", "template", "metadata", "labels")
+	otherDeployment := withNestedStringMap(fake.Unstructured(kinds.Deployment(), core.Namespace("webhook-system"), core.Name("other")),
+		map[string]string{"app": "other"}, "spec", "template", "metadata", "labels")
+	disabledDeployment := withNestedStringMap(fake.Unstructured(kinds.Deployment(), core.Namespace("webhook-system"), core.Name("disabled"),
+		core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementDisabled)),
+		map[string]string{"app": "webhook"}, "spec", "template", "metadata", "labels")
+	cert := fake.Unstructured(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+		core.Namespace("webhook-system"), core.Name("webhook-cert"))
+
+	testCases := []struct {
+		name    string
+		objs    []ast.FileObject
+		wantDep string
+	}{
+		{
+			name: "service, workload and certificate declared",
+			objs: []ast.FileObject{
+				webhookConfig(kinds.ValidatingWebhookConfiguration(), "webhook-system", "webhook-svc",
+					core.Annotation(certManagerInjectCAFromAnnotation, "webhook-system/webhook-cert")),
+				svc, deployment, otherDeployment, disabledDeployment, cert,
+			},
+			wantDep: "/namespaces/webhook-system/Service/webhook-svc," +
+				"apps/namespaces/webhook-system/Deployment/webhook," +
+				"cert-manager.io/namespaces/webhook-system/Certificate/webhook-cert",
+		},
+		{
+			name: "existing dependencies are kept",
+			objs: []ast.FileObject{
+				webhookConfig(kinds.MutatingWebhookConfiguration(), "webhook-system", "webhook-svc",
+					core.Annotation(dependson.Annotation, "/namespaces/webhook-system/ConfigMap/config")),
+				svc,
+			},
+			wantDep: "/namespaces/webhook-system/ConfigMap/config," +
+				"/namespaces/webhook-system/Service/webhook-svc",
+		},
+		{
+			name: "service not declared",
+			objs: []ast.FileObject{
+				webhookConfig(kinds.ValidatingWebhookConfiguration(), "webhook-system", "undeclared-svc"),
+				svc, deployment,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs, errs := addWebhookDependencies(tc.objs)
+			if errs != nil {
+				t.Fatalf("unexpected error: %v", errs)
+			}
+			got := objs[0].GetAnnotations()[dependson.Annotation]
+			if diff := cmp.Diff(tc.wantDep, got); diff != "" {
+				t.Errorf("unexpected depends-on annotation (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// WebhookUpdateFailedWarning is the code of the sync warning reported when
+// the admission webhook configuration cannot be updated with the types of the
+// declared objects.
+const WebhookUpdateFailedWarning = "WebhookUpdateFailed"
+
+// webhookUpdateBackoff is the backoff of the retries of the admission webhook
+// configuration update. The reconcilers of all the RootSyncs and RepoSyncs
+// update the same configuration, so updates may conflict when several
+// reconcilers parse new commits at the same time.
+var webhookUpdateBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    4,
+}
+
+// updateWebhookConfiguration calls update, retrying with webhookUpdateBackoff
+// on failures, and returns a warning if the last attempt fails.
+//
+// Failures don't block the sync, since the remediator still corrects drift of
+// the objects whose types are not protected by the admission webhook.
+func updateWebhookConfiguration(ctx context.Context, backoff wait.Backoff, update func() status.MultiError) *v1beta1.SyncWarning {
+	var lastErr status.MultiError
+	retryable := func(error) bool { return ctx.Err() == nil }
+	_ = retry.OnError(backoff, retryable, func() error {
+		lastErr = update()
+		if lastErr != nil {
+			klog.Warningf("Failed to update admission webhook: %v", lastErr)
+			return lastErr
+		}
+		return nil
+	})
+	if lastErr == nil {
+		return nil
+	}
+	return &v1beta1.SyncWarning{
+		Code:    WebhookUpdateFailedWarning,
+		Message: fmt.Sprintf("Failed to update the admission webhook, so drift prevention may not cover the newly declared types: %v", lastErr),
+	}
+}
+
+// syncWarnings returns the warnings of the cached parse results, or nil.
+func syncWarnings(cache cacheForCommit) []v1beta1.SyncWarning {
+	if cache.webhookWarning == nil {
+		return nil
+	}
+	return []v1beta1.SyncWarning{*cache.webhookWarning}
+}
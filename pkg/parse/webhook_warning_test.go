@@ -0,0 +1,54 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/status"
+)
+
+func TestUpdateWebhookConfiguration(t *testing.T) {
+	ctx := context.Background()
+	backoff := wait.Backoff{Steps: 3}
+
+	attempts := 0
+	warning := updateWebhookConfiguration(ctx, backoff, func() status.MultiError {
+		attempts++
+		if attempts < 3 {
+			return status.APIServerError(errors.New("conflict"), "failed to update")
+		}
+		return nil
+	})
+	require.Nil(t, warning)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	warning = updateWebhookConfiguration(ctx, backoff, func() status.MultiError {
+		attempts++
+		return status.APIServerError(errors.New("forbidden"), "failed to update")
+	})
+	require.Equal(t, 3, attempts)
+	require.NotNil(t, warning)
+	require.Equal(t, WebhookUpdateFailedWarning, warning.Code)
+	require.Contains(t, warning.Message, "forbidden")
+
+	require.Nil(t, syncWarnings(cacheForCommit{}))
+	require.Len(t, syncWarnings(cacheForCommit{webhookWarning: warning}), 1)
+}
@@ -82,10 +82,11 @@ func TestRepoSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("2")
 				obj.Status.Conditions = []v1beta1.RepoSyncCondition{
 					{
-						Type:    v1beta1.RepoSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RepoSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -114,10 +115,11 @@ func TestRepoSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("2")
 				obj.Status.Conditions = []v1beta1.RepoSyncCondition{
 					{
-						Type:    v1beta1.RepoSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RepoSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -137,10 +139,11 @@ func TestRepoSyncFinalize(t *testing.T) {
 				// ReconcilerFinalizerFailure condition added
 				obj.Status.Conditions = []v1beta1.RepoSyncCondition{
 					{
-						Type:    v1beta1.RepoSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RepoSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RepoSyncReconcilerFinalizerFailure,
@@ -153,6 +156,7 @@ func TestRepoSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -170,10 +174,11 @@ func TestRepoSyncFinalize(t *testing.T) {
 				// ReconcilerFinalizerFailure condition added
 				obj.Status.Conditions = []v1beta1.RepoSyncCondition{
 					{
-						Type:    v1beta1.RepoSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RepoSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RepoSyncReconcilerFinalizerFailure,
@@ -186,6 +191,7 @@ func TestRepoSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -197,10 +203,11 @@ func TestRepoSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("3")
 				obj.Status.Conditions = []v1beta1.RepoSyncCondition{
 					{
-						Type:    v1beta1.RepoSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RepoSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RepoSyncReconcilerFinalizerFailure,
@@ -213,6 +220,7 @@ func TestRepoSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -91,10 +91,11 @@ func TestRootSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("2")
 				obj.Status.Conditions = []v1beta1.RootSyncCondition{
 					{
-						Type:    v1beta1.RootSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RootSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -123,10 +124,11 @@ func TestRootSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("2")
 				obj.Status.Conditions = []v1beta1.RootSyncCondition{
 					{
-						Type:    v1beta1.RootSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RootSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -146,10 +148,11 @@ func TestRootSyncFinalize(t *testing.T) {
 				// ReconcilerFinalizerFailure condition added
 				obj.Status.Conditions = []v1beta1.RootSyncCondition{
 					{
-						Type:    v1beta1.RootSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RootSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RootSyncReconcilerFinalizerFailure,
@@ -162,6 +165,7 @@ func TestRootSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -179,10 +183,11 @@ func TestRootSyncFinalize(t *testing.T) {
 				// ReconcilerFinalizerFailure condition added
 				obj.Status.Conditions = []v1beta1.RootSyncCondition{
 					{
-						Type:    v1beta1.RootSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RootSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RootSyncReconcilerFinalizerFailure,
@@ -195,6 +200,7 @@ func TestRootSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
@@ -206,10 +212,11 @@ func TestRootSyncFinalize(t *testing.T) {
 				obj.SetResourceVersion("3")
 				obj.Status.Conditions = []v1beta1.RootSyncCondition{
 					{
-						Type:    v1beta1.RootSyncReconcilerFinalizing,
-						Status:  metav1.ConditionTrue,
-						Reason:  "ResourcesDeleting",
-						Message: "Deleting managed resource objects",
+						Type:               v1beta1.RootSyncReconcilerFinalizing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourcesDeleting",
+						Message:            "Deleting managed resource objects",
+						ObservedGeneration: 1,
 					},
 					{
 						Type:    v1beta1.RootSyncReconcilerFinalizerFailure,
@@ -222,6 +229,7 @@ func TestRootSyncFinalize(t *testing.T) {
 								ErrorMessage: "KNV2002: example message: APIServer error: destroy error\n\nFor more information, see https://g.co/cloud/acm-errors#knv2002",
 							},
 						},
+						ObservedGeneration: 1,
 					},
 				}
 				return obj
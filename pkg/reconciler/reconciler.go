@@ -19,9 +19,11 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
+	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/client/restconfig"
@@ -30,14 +32,20 @@ import (
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/importer/reader"
+	ocmetrics "kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/parse"
 	"kpt.dev/configsync/pkg/reconciler/finalizer"
+	"kpt.dev/configsync/pkg/reconciler/syncrequest"
+	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/remediator"
 	"kpt.dev/configsync/pkg/remediator/watch"
+	"kpt.dev/configsync/pkg/rename"
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
 	"kpt.dev/configsync/pkg/syncer/metrics"
 	"kpt.dev/configsync/pkg/syncer/reconcile"
 	"kpt.dev/configsync/pkg/syncer/reconcile/fight"
+	"kpt.dev/configsync/pkg/syncwindow"
+	"kpt.dev/configsync/pkg/tunables"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -73,6 +81,12 @@ type Options struct {
 	// PollingPeriod is the period of time between checking the filesystem for
 	// source updates to sync.
 	PollingPeriod time.Duration
+	// WatchSource enables reimporting the source as soon as it is fetched or
+	// rendered, instead of waiting for the next polling period.
+	WatchSource bool
+	// SupersedeInFlightApply stops applying a commit as soon as a newer commit
+	// is ready to apply.
+	SupersedeInFlightApply bool
 	// RetryPeriod is the period of time between checking the filesystem for
 	// source updates to sync, after an error.
 	RetryPeriod time.Duration
@@ -90,6 +104,8 @@ type Options struct {
 	// HydratedLink is the relative path to the hydrated root.
 	// It is a symlink that links to the hydrated configs under the hydrated root dir.
 	HydratedLink string
+	// HydrationSigningKey is the key to verify the hydrated configs with.
+	HydrationSigningKey []byte
 	// SourceRev is the git revision or a helm chart version being synced.
 	SourceRev string
 	// SourceBranch is the git branch being synced.
@@ -106,6 +122,83 @@ type Options struct {
 	ReconcileTimeout string
 	// APIServerTimeout is the client-side timeout used for talking to the API server
 	APIServerTimeout string
+	// UpgradeSettlePeriod is how long the reconciler holds off applying new
+	// commits after detecting a cluster control plane upgrade.
+	// Zero disables the upgrade detection.
+	UpgradeSettlePeriod time.Duration
+	// SelfUpdateTimeout is how long the root reconciler waits for the Config
+	// Sync components declared in its source to become healthy after changing
+	// them, before reverting them. Zero disables the health check.
+	SelfUpdateTimeout time.Duration
+	// RetryBudget is the number of consecutive failed retries of a commit
+	// after which the reconciler stops retrying it, until the source changes.
+	// Zero retries forever.
+	RetryBudget int
+	// NormalizeDeclarations enables comparing the declarations of objects
+	// with their previous declarations after a schema-aware normalization.
+	NormalizeDeclarations bool
+	// CommonLabels are added to every applied object, unless the object
+	// declares a label with the same key.
+	CommonLabels map[string]string
+	// CommonAnnotations are added to every applied object, unless the object
+	// declares an annotation with the same key.
+	CommonAnnotations map[string]string
+	// PruneObsoleteMetadata enables removing the annotations and labels that
+	// are no longer used by Config Sync from the managed objects during apply.
+	PruneObsoleteMetadata bool
+	// MigrateClientSideApply enables transferring the fields owned by kubectl
+	// client-side apply to Config Sync, and removing the
+	// last-applied-configuration annotation, from the applied objects.
+	MigrateClientSideApply bool
+	// PruneDelay is how long to hold off deleting the objects removed from the
+	// source of truth. Zero prunes them immediately.
+	PruneDelay time.Duration
+	// ApprovalTimeout is how long to wait for the approval of a new commit
+	// before applying it. Zero applies new commits without approval.
+	ApprovalTimeout time.Duration
+	// OneShot makes the reconciler exit once the source of truth is synced,
+	// instead of syncing continuously.
+	OneShot bool
+	// SyncWindows restrict when new commits are applied. New commits are
+	// applied at any time if there are none.
+	SyncWindows []syncwindow.Window
+	// DryRun previews the commits with server-side dry-run applies instead
+	// of syncing them.
+	DryRun bool
+	// Renames rename the declared objects after rendering.
+	Renames []rename.Rule
+	// PruneAllowedKinds are the kinds of the objects which are pruned when
+	// they are removed from the source of truth. Empty allows all kinds.
+	PruneAllowedKinds []schema.GroupKind
+	// PruneDeniedKinds are the kinds of the objects which are never pruned.
+	PruneDeniedKinds []schema.GroupKind
+	// PruneMaxPercentage is the largest percentage of the managed objects
+	// which are pruned at once without confirmation. Zero is unlimited.
+	PruneMaxPercentage int
+	// PruneMaxCount is the largest number of the managed objects which are
+	// pruned at once without confirmation. Zero is unlimited.
+	PruneMaxCount int
+	// MaxWorkloadRollouts is the largest number of the managed workloads
+	// which roll out at once. Zero is unlimited.
+	MaxWorkloadRollouts int
+	// MaxNamespaceRollouts is the largest number of the managed workloads of
+	// a namespace which roll out at once. Zero is unlimited.
+	MaxNamespaceRollouts int
+	// ParseConcurrency is the largest number of the source files parsed at
+	// once. The files are parsed one at a time if it is less than 2.
+	ParseConcurrency int
+	// Converter converts the source files which are not Kubernetes manifests
+	// into Kubernetes manifests. The files are parsed as manifests if it is
+	// nil.
+	Converter reader.Converter
+	// PartialApply only applies the objects declared in the source files
+	// changed since the last successful apply, along with their dependents.
+	// All the objects are applied on every resync.
+	PartialApply bool
+	// APIPriorityGroup is the group added to the identity of the reconciler
+	// on the API requests to the current cluster, so that a FlowSchema can
+	// match them. Empty sends the requests as the reconciler service account.
+	APIPriorityGroup string
 	// RootOptions is the set of options to fill in if this is configuring the
 	// Root reconciler.
 	// Unset for Namespace repositories.
@@ -116,6 +209,16 @@ type Options struct {
 type RootOptions struct {
 	// SourceFormat is how the Root repository is structured.
 	SourceFormat filesystem.SourceFormat
+	// HierarchicalDirs are the directories of an unstructured Root repository,
+	// relative to the sync directory, which are in the hierarchy format.
+	HierarchicalDirs []cmpath.Relative
+	// TargetKubeconfig is the path to the kubeconfig of the cluster to sync
+	// resources to. If empty, resources are synced to the current cluster.
+	TargetKubeconfig string
+	// Targets are the clusters to fan the resources out to. If set, the
+	// resources are applied to every target, each with its own inventory, and
+	// TargetKubeconfig is ignored.
+	Targets []reconcilermanager.TargetConfig
 }
 
 // Run configures and starts the various components of a reconciler process.
@@ -134,8 +237,36 @@ func Run(opts Options) {
 	if err != nil {
 		klog.Fatalf("Error creating rest config: %v", err)
 	}
+	restconfig.SetPriorityGroup(cfg, configsync.ControllerNamespace, opts.ReconcilerName, opts.APIPriorityGroup)
+	cfg.Wrap(ocmetrics.NewThrottlingRecorder)
+	cfg.Wrap(ocmetrics.NewAPICallCounter)
+	// The client-side throttling can be changed from the tunables ConfigMap.
+	rateLimiter := tunables.NewRateLimiter(cfg.QPS, cfg.Burst)
+	cfg.RateLimiter = rateLimiter
 
-	configFlags, err := restconfig.NewConfigFlags(cfg)
+	// The target cluster is the cluster that resources are synced to.
+	// The RootSync or RepoSync object is always in the current cluster, but a
+	// root reconciler may sync to a remote cluster using a separate kubeconfig.
+	var targetKubeconfig string
+	var syncTargets []reconcilermanager.TargetConfig
+	if opts.RootOptions != nil {
+		targetKubeconfig = opts.TargetKubeconfig
+		syncTargets = opts.Targets
+	}
+	if len(syncTargets) > 0 {
+		// The first target is used to discover the available resource types.
+		targetKubeconfig = syncTargets[0].Kubeconfig
+	}
+	targetCfg := cfg
+	if targetKubeconfig != "" {
+		klog.Infof("Syncing to the target cluster with kubeconfig %s", targetKubeconfig)
+		targetCfg, err = restconfig.NewTargetRestConfig(targetKubeconfig, apiServerTimeout)
+		if err != nil {
+			klog.Fatalf("Error creating rest config for the target cluster: %v", err)
+		}
+	}
+
+	configFlags, err := restconfig.NewConfigFlags(targetCfg)
 	if err != nil {
 		klog.Fatalf("Error creating config flags from rest config: %v", err)
 	}
@@ -160,9 +291,24 @@ func Run(opts Options) {
 		klog.Fatalf("failed to create client: %v", err)
 	}
 
+	targetCl := cl
+	if targetKubeconfig != "" {
+		targetMapper, err := apiutil.NewDynamicRESTMapper(targetCfg)
+		if err != nil {
+			klog.Fatalf("Error creating DynamicRESTMapper for the target cluster: %v", err)
+		}
+		targetCl, err = client.New(targetCfg, client.Options{
+			Scheme: core.Scheme,
+			Mapper: targetMapper,
+		})
+		if err != nil {
+			klog.Fatalf("failed to create client for the target cluster: %v", err)
+		}
+	}
+
 	// Configure the Applier.
-	genericClient := syncerclient.New(cl, metrics.APICallDuration)
-	baseApplier, err := reconcile.NewApplierForMultiRepo(cfg, genericClient)
+	genericClient := syncerclient.New(targetCl, metrics.APICallDuration)
+	baseApplier, err := reconcile.NewApplierForMultiRepo(targetCfg, genericClient)
 	if err != nil {
 		klog.Fatalf("Instantiating Applier: %v", err)
 	}
@@ -174,18 +320,34 @@ func Run(opts Options) {
 	if reconcileTimeout < 0 {
 		klog.Fatalf("Invalid reconcileTimeout: %v, timeout should not be negative", reconcileTimeout)
 	}
-	clientSet, err := applier.NewClientSet(cl, configFlags, opts.StatusMode)
+	clientSet, err := applier.NewClientSet(targetCl, configFlags, opts.StatusMode)
 	if err != nil {
 		klog.Fatalf("Error creating clients: %v", err)
 	}
-	supervisor, err := applier.NewSupervisor(clientSet, opts.ReconcilerScope, opts.SyncName, reconcileTimeout)
-	if err != nil {
-		klog.Fatalf("Error creating applier: %v", err)
+	clientSet.PruneObsoleteMetadata = opts.PruneObsoleteMetadata
+	clientSet.MigrateClientSideApply = opts.MigrateClientSideApply
+	clientSet.PruneDelay = opts.PruneDelay
+	clientSet.PruneAllowedKinds = opts.PruneAllowedKinds
+	clientSet.PruneDeniedKinds = opts.PruneDeniedKinds
+	clientSet.PruneMaxPercentage = opts.PruneMaxPercentage
+	clientSet.PruneMaxCount = opts.PruneMaxCount
+	clientSet.MaxWorkloadRollouts = opts.MaxWorkloadRollouts
+	clientSet.MaxNamespaceRollouts = opts.MaxNamespaceRollouts
+	clientSet.PartialApply = opts.PartialApply
+	// The remediator tracks the emergency overrides in the declared resources.
+	decls := &declared.Resources{}
+	clientSet.EmergencyOverrides = decls.EmergencyOverrides
+	var supervisor applier.Supervisor
+	if len(syncTargets) > 0 {
+		supervisor = newMultiTargetSupervisor(syncTargets, opts, apiServerTimeout, reconcileTimeout)
+	} else {
+		supervisor, err = applier.NewSupervisor(clientSet, opts.ReconcilerScope, opts.SyncName, reconcileTimeout)
+		if err != nil {
+			klog.Fatalf("Error creating applier: %v", err)
+		}
 	}
 
 	// Configure the Remediator.
-	decls := &declared.Resources{}
-
 	// Get a separate config for the remediator to talk to the apiserver since
 	// we want a longer REST config timeout for the remediator to avoid restarting
 	// idle watches too frequently.
@@ -193,34 +355,76 @@ func Run(opts Options) {
 	if err != nil {
 		klog.Fatalf("Error creating rest config for the remediator: %v", err)
 	}
+	restconfig.SetPriorityGroup(cfgForWatch, configsync.ControllerNamespace, opts.ReconcilerName, opts.APIPriorityGroup)
+	cfgForWatch.Wrap(ocmetrics.NewThrottlingRecorder)
+	cfgForWatch.Wrap(ocmetrics.NewAPICallCounter)
+	targetCfgForWatch := cfgForWatch
+	if targetKubeconfig != "" {
+		targetCfgForWatch, err = restconfig.NewTargetRestConfig(targetKubeconfig, watch.RESTConfigTimeout)
+		if err != nil {
+			klog.Fatalf("Error creating rest config for the remediator on the target cluster: %v", err)
+		}
+	}
 
-	rem, err := remediator.New(opts.ReconcilerScope, opts.SyncName, cfgForWatch, baseApplier, decls, opts.NumWorkers)
-	if err != nil {
-		klog.Fatalf("Instantiating Remediator: %v", err)
+	var rem remediator.Runner
+	if len(syncTargets) > 0 {
+		// The declared resources differ from the applied resources by the
+		// per-target variables, so drift on the targets is corrected by the
+		// periodic resync instead of the remediator.
+		rem = remediator.NewDisabled()
+	} else {
+		rem, err = remediator.New(opts.ReconcilerScope, opts.SyncName, targetCfgForWatch, baseApplier, decls, opts.NumWorkers)
+		if err != nil {
+			klog.Fatalf("Instantiating Remediator: %v", err)
+		}
 	}
 
+	// Pushes are delivered by the git webhook receiver, through the
+	// sync-requested-at annotation of the RSync.
+	syncRequests := make(chan struct{}, 1)
+
 	// Configure the Parser.
 	var parser parse.Parser
+	pollingPeriod := tunables.NewDuration(opts.PollingPeriod)
 	fs := parse.FileSource{
-		SourceDir:    opts.SourceRoot,
-		RepoRoot:     opts.RepoRoot,
-		HydratedRoot: opts.HydratedRoot,
-		HydratedLink: opts.HydratedLink,
-		SyncDir:      opts.SyncDir,
-		SourceType:   opts.SourceType,
-		SourceRepo:   opts.SourceRepo,
-		SourceBranch: opts.SourceBranch,
-		SourceRev:    opts.SourceRev,
+		SourceDir:           opts.SourceRoot,
+		RepoRoot:            opts.RepoRoot,
+		HydratedRoot:        opts.HydratedRoot,
+		HydratedLink:        opts.HydratedLink,
+		SyncDir:             opts.SyncDir,
+		SourceType:          opts.SourceType,
+		SourceRepo:          opts.SourceRepo,
+		SourceBranch:        opts.SourceBranch,
+		SourceRev:           opts.SourceRev,
+		WatchSource:         opts.WatchSource,
+		HydrationSigningKey: opts.HydrationSigningKey,
+	}
+	ro := parse.RunnerOptions{
+		UpgradeSettlePeriod:    opts.UpgradeSettlePeriod,
+		RetryBudget:            opts.RetryBudget,
+		SupersedeInFlightApply: opts.SupersedeInFlightApply,
+		ApprovalTimeout:        opts.ApprovalTimeout,
+		OneShot:                opts.OneShot,
+		SyncWindows:            opts.SyncWindows,
+		DryRun:                 opts.DryRun,
+		Renames:                opts.Renames,
+		NormalizeDeclarations:  opts.NormalizeDeclarations,
+		CommonLabels:           opts.CommonLabels,
+		CommonAnnotations:      opts.CommonAnnotations,
+		ExternalTriggers:       syncRequests,
 	}
 	if opts.ReconcilerScope == declared.RootReconciler {
-		parser, err = parse.NewRootRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.SourceFormat, &reader.File{}, cl,
-			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem)
+		ro.TargetClient = targetCl
+		ro.SelfUpdateTimeout = opts.SelfUpdateTimeout
+		ro.HierarchicalDirs = opts.HierarchicalDirs
+		parser, err = parse.NewRootRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.SourceFormat, &reader.File{Concurrency: opts.ParseConcurrency, Converter: opts.Converter}, cl,
+			pollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, ro, discoveryClient, decls, supervisor, rem)
 		if err != nil {
 			klog.Fatalf("Instantiating Root Repository Parser: %v", err)
 		}
 	} else {
-		parser, err = parse.NewNamespaceRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.ReconcilerScope, &reader.File{}, cl,
-			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem)
+		parser, err = parse.NewNamespaceRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.ReconcilerScope, &reader.File{Concurrency: opts.ParseConcurrency, Converter: opts.Converter}, cl,
+			pollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, ro, discoveryClient, decls, supervisor, rem)
 		if err != nil {
 			klog.Fatalf("Instantiating Namespace Repository Parser: %v", err)
 		}
@@ -281,6 +485,19 @@ func Run(opts Options) {
 		klog.Fatalf("Instantiating Finalizer: %v", err)
 	}
 
+	// Create the sync request Controller
+	syncRequestController := &syncrequest.Controller{
+		SyncScope: opts.ReconcilerScope,
+		SyncName:  opts.SyncName,
+		Client:    mgr.GetClient(), // caching client
+		Triggers:  syncRequests,
+	}
+
+	// Register the sync request Controller
+	if err := syncRequestController.SetupWithManager(mgr); err != nil {
+		klog.Fatalf("Instantiating the sync request controller: %v", err)
+	}
+
 	klog.Info("Starting ControllerManager")
 	// TODO: Once everything is using the controller-manager, move mgr.Start to the top level.
 	doneChanForManager := make(chan struct{})
@@ -304,11 +521,28 @@ func Run(opts Options) {
 	// TODO: Convert the Remediator to use the controller-manager framework.
 	doneChanForRemediator := rem.Start(ctx) // non-blocking
 
+	klog.Info("Starting the tunables reloader")
+	tunablesNamespace := configsync.ControllerNamespace
+	if opts.ReconcilerScope != declared.RootReconciler {
+		tunablesNamespace = string(opts.ReconcilerScope)
+	}
+	tunablesKey := client.ObjectKey{Namespace: tunablesNamespace, Name: tunables.ConfigMapName(opts.ReconcilerName)}
+	go tunables.NewReloader(cl, tunablesKey, pollingPeriod, rateLimiter).Run(ctx)
+
 	klog.Info("Starting Parser")
 	// TODO: Convert the Parser to use the controller-manager framework.
-	parse.Run(ctx, parser) // blocks until ctx.Done()
+	parse.Run(ctx, parser) // blocks until ctx.Done(), or the source is synced in one-shot mode
 	klog.Info("Parser exited")
 
+	if opts.OneShot && ctx.Err() == nil {
+		// The source of truth is synced, so exit to complete the Job,
+		// without waiting for an exit signal.
+		stopControllers()
+		<-doneChanForRemediator
+		klog.Info("Remediator exited, the one-shot sync completed")
+		return
+	}
+
 	// Wait for Remediator to exit
 	<-doneChanForRemediator
 	klog.Info("Remediator exited")
@@ -324,3 +558,55 @@ func Run(opts Options) {
 	<-signalCtx.Done()
 	klog.Info("All controllers exited")
 }
+
+// newMultiTargetSupervisor returns a Supervisor which applies the resources to
+// every target cluster, each with its own inventory.
+func newMultiTargetSupervisor(syncTargets []reconcilermanager.TargetConfig, opts Options, apiServerTimeout, reconcileTimeout time.Duration) applier.Supervisor {
+	var targets []applier.Target
+	for _, syncTarget := range syncTargets {
+		klog.Infof("Syncing to the target cluster %q with kubeconfig %s", syncTarget.Name, syncTarget.Kubeconfig)
+		cfg, err := restconfig.NewTargetRestConfig(syncTarget.Kubeconfig, apiServerTimeout)
+		if err != nil {
+			klog.Fatalf("Error creating rest config for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		configFlags, err := restconfig.NewConfigFlags(cfg)
+		if err != nil {
+			klog.Fatalf("Error creating config flags for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+		if err != nil {
+			klog.Fatalf("Error creating DynamicRESTMapper for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		cl, err := client.New(cfg, client.Options{
+			Scheme: core.Scheme,
+			Mapper: mapper,
+		})
+		if err != nil {
+			klog.Fatalf("failed to create client for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		clientSet, err := applier.NewClientSet(cl, configFlags, opts.StatusMode)
+		if err != nil {
+			klog.Fatalf("Error creating clients for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		clientSet.PruneObsoleteMetadata = opts.PruneObsoleteMetadata
+		clientSet.MigrateClientSideApply = opts.MigrateClientSideApply
+		clientSet.PruneDelay = opts.PruneDelay
+		clientSet.PruneAllowedKinds = opts.PruneAllowedKinds
+		clientSet.PruneDeniedKinds = opts.PruneDeniedKinds
+		clientSet.PruneMaxPercentage = opts.PruneMaxPercentage
+		clientSet.PruneMaxCount = opts.PruneMaxCount
+		clientSet.MaxWorkloadRollouts = opts.MaxWorkloadRollouts
+		clientSet.MaxNamespaceRollouts = opts.MaxNamespaceRollouts
+		clientSet.PartialApply = opts.PartialApply
+		supervisor, err := applier.NewSupervisor(clientSet, opts.ReconcilerScope, opts.SyncName, reconcileTimeout)
+		if err != nil {
+			klog.Fatalf("Error creating applier for the target cluster %q: %v", syncTarget.Name, err)
+		}
+		targets = append(targets, applier.Target{
+			Name:       syncTarget.Name,
+			Supervisor: supervisor,
+			Variables:  syncTarget.Variables,
+		})
+	}
+	return applier.NewMultiTargetSupervisor(targets)
+}
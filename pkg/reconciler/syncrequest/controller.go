@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncrequest
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reconciler/finalizer"
+	"kpt.dev/configsync/pkg/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Controller that watches a RootSync or RepoSync, and triggers a run of the
+// parser when its `configsync.gke.io/sync-requested-at` annotation changes.
+//
+// The annotation is set by the git webhook receiver when the Git repository
+// receives a push, so the push is synced without waiting for the polling
+// period, and without restarting the reconciler.
+type Controller struct {
+	SyncScope declared.Scope
+	SyncName  string
+	Client    client.Client
+	// Triggers receives a value for every new sync request. Requests received
+	// while a run is pending are coalesced.
+	Triggers chan<- struct{}
+
+	// observed indicates whether the annotation was read at least once.
+	observed bool
+	// lastRequest is the value of the annotation which requested the last
+	// sync.
+	lastRequest string
+}
+
+// SetupWithManager registers the sync request Controller with the manager.
+func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
+	exampleObj := c.newExampleObject()
+	exampleKey := client.ObjectKeyFromObject(exampleObj)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("SyncRequest").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		For(exampleObj, builder.WithPredicates(
+			// Ignore the status updates
+			predicate.AnnotationChangedPredicate{},
+			// Filter the watch down to a single object
+			finalizer.SingleObjectPredicate(exampleKey),
+		)).
+		Complete(c)
+}
+
+// newExampleObject returns new RootSync or RepoSync with name and namespace set.
+func (c *Controller) newExampleObject() client.Object {
+	if c.SyncScope == declared.RootReconciler {
+		exampleObj := &v1beta1.RootSync{}
+		exampleObj.Name = c.SyncName
+		exampleObj.Namespace = configmanagement.ControllerNamespace
+		return exampleObj
+	}
+	exampleObj := &v1beta1.RepoSync{}
+	exampleObj.Name = c.SyncName
+	exampleObj.Namespace = string(c.SyncScope)
+	return exampleObj
+}
+
+// Reconcile responds to changes in the RootSync/RepoSync being watched.
+func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var result reconcile.Result
+
+	rs := c.newExampleObject()
+	rsKey := client.ObjectKeyFromObject(rs)
+	if err := c.Client.Get(ctx, rsKey, rs); err != nil {
+		if apierrors.IsNotFound(err) {
+			return result, nil
+		}
+		return result, status.APIServerError(err, fmt.Sprintf("failed to get %T %s", rs, rsKey))
+	}
+
+	requestedAt := core.GetAnnotation(rs, metadata.SyncRequestedAtAnnotationKey)
+	if !c.observed {
+		// The parser reads the source on startup anyway, so the request made
+		// before the reconciler started is already handled.
+		c.observed = true
+		c.lastRequest = requestedAt
+		return result, nil
+	}
+	if requestedAt == "" || requestedAt == c.lastRequest {
+		return result, nil
+	}
+	c.lastRequest = requestedAt
+
+	select {
+	case c.Triggers <- struct{}{}:
+		klog.Infof("Received the sync request made at %s", requestedAt)
+	default:
+		klog.V(3).Infof("Received the sync request made at %s, a run is already pending", requestedAt)
+	}
+	return result, nil
+}
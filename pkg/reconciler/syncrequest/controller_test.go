@@ -0,0 +1,82 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncrequest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestController(t *testing.T) {
+	rs := &v1beta1.RepoSync{}
+	rs.Name = "repo-sync"
+	rs.Namespace = "bookstore"
+	core.SetAnnotation(rs, metadata.SyncRequestedAtAnnotationKey, "2022-01-01T00:00:00Z")
+
+	ctx := context.Background()
+	fakeClient := fake.NewClient(t, core.Scheme, rs)
+	triggers := make(chan struct{}, 1)
+	c := &Controller{
+		SyncScope: declared.Scope(rs.Namespace),
+		SyncName:  rs.Name,
+		Client:    fakeClient,
+		Triggers:  triggers,
+	}
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	requestSync := func(requestedAt string) {
+		t.Helper()
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(rs), rs))
+		core.SetAnnotation(rs, metadata.SyncRequestedAtAnnotationKey, requestedAt)
+		require.NoError(t, fakeClient.Update(ctx, rs))
+	}
+	reconcileAndCheck := func(wantTrigger bool) {
+		t.Helper()
+		_, err := c.Reconcile(ctx, req)
+		require.NoError(t, err)
+		select {
+		case <-triggers:
+			require.True(t, wantTrigger, "unexpected trigger")
+		default:
+			require.False(t, wantTrigger, "missing trigger")
+		}
+	}
+
+	// The request made before the reconciler started is ignored.
+	reconcileAndCheck(false)
+	reconcileAndCheck(false)
+
+	requestSync("2022-01-01T00:01:00Z")
+	reconcileAndCheck(true)
+	// The same request only triggers one run.
+	reconcileAndCheck(false)
+
+	// The requests received while a run is pending are coalesced.
+	requestSync("2022-01-01T00:02:00Z")
+	_, err := c.Reconcile(ctx, req)
+	require.NoError(t, err)
+	requestSync("2022-01-01T00:03:00Z")
+	reconcileAndCheck(true)
+	reconcileAndCheck(false)
+}
@@ -74,9 +74,136 @@ const (
 	// APIServerTimeout is to control the client-side timeout when talking to the API server
 	APIServerTimeout = "API_SERVER_TIMEOUT"
 
+	// UpgradeSettlePeriod is to control how long the reconciler holds off
+	// applying new commits after detecting a cluster control plane upgrade.
+	UpgradeSettlePeriod = "UPGRADE_SETTLE_PERIOD"
+
+	// PruneDelay is to control how long the reconciler holds off deleting the
+	// objects removed from the source of truth.
+	PruneDelay = "PRUNE_DELAY"
+
+	// ApprovalTimeout is to control how long the reconciler waits for the
+	// approval of a new commit before applying it.
+	ApprovalTimeout = "APPROVAL_TIMEOUT"
+
+	// OneShot makes the reconciler and the hydration-controller exit once the
+	// source of truth is synced, when they run in a Job.
+	OneShot = "ONE_SHOT"
+
+	// PruneAllowedKinds is the comma-separated list of the kinds of the
+	// objects that the reconciler deletes when they are removed from the
+	// source of truth, in the `Kind.group` format.
+	PruneAllowedKinds = "PRUNE_ALLOWED_KINDS"
+
+	// PruneDeniedKinds is the comma-separated list of the kinds of the
+	// objects that the reconciler never deletes, in the `Kind.group` format.
+	PruneDeniedKinds = "PRUNE_DENIED_KINDS"
+
+	// HierarchicalDirs is the comma-separated list of the directories of an
+	// unstructured repository which are in the hierarchy format.
+	HierarchicalDirs = "HIERARCHICAL_DIRS"
+
+	// PruneMaxPercentage is the largest percentage of the managed objects
+	// that the reconciler prunes at once without confirmation.
+	PruneMaxPercentage = "PRUNE_MAX_PERCENTAGE"
+
+	// PruneMaxCount is the largest number of the managed objects that the
+	// reconciler prunes at once without confirmation.
+	PruneMaxCount = "PRUNE_MAX_COUNT"
+
+	// RolloutMaxConcurrentUpdates is the largest number of the managed
+	// workloads that the reconciler rolls out at once.
+	RolloutMaxConcurrentUpdates = "ROLLOUT_MAX_CONCURRENT_UPDATES"
+
+	// RolloutMaxConcurrentUpdatesPerNamespace is the largest number of the
+	// managed workloads of a namespace that the reconciler rolls out at once.
+	RolloutMaxConcurrentUpdatesPerNamespace = "ROLLOUT_MAX_CONCURRENT_UPDATES_PER_NAMESPACE"
+
+	// SyncWindows is the OS env variable key for the JSON-encoded sync
+	// windows, which restrict when the reconciler applies changes.
+	SyncWindows = "SYNC_WINDOWS"
+
+	// DryRun makes the reconciler preview the commits of the source of truth
+	// with server-side dry-run applies, instead of syncing them.
+	DryRun = "DRY_RUN"
+
+	// Renames is the OS env variable key for the JSON-encoded rename rules of
+	// the declared objects.
+	Renames = "RENAMES"
+
+	// ParseConcurrency is the largest number of the source files the
+	// reconciler parses at once.
+	ParseConcurrency = "PARSE_CONCURRENCY"
+
+	// ExportSink is the URL of the sink the reconciler exports its sync
+	// attempts, applied objects and drift events to, e.g.
+	// `bigquery://<project>/<dataset>`.
+	ExportSink = "EXPORT_SINK"
+
+	// APIPriorityGroup is the group the reconciler adds to its identity, so
+	// that its API requests can be matched by a FlowSchema.
+	APIPriorityGroup = "API_PRIORITY_GROUP"
+
+	// WatchSource is to control if the reconciler reimports the source as soon
+	// as the source or hydrated link changes, instead of waiting for the next
+	// polling period.
+	WatchSource = "WATCH_SOURCE"
+
+	// SupersedeInFlightApply is to control if the reconciler stops applying a
+	// commit as soon as a newer commit is ready to apply.
+	SupersedeInFlightApply = "SUPERSEDE_IN_FLIGHT_APPLY"
+
+	// PartialApply is to control if the reconciler only applies the objects
+	// declared in the source files changed by a new commit.
+	PartialApply = "PARTIAL_APPLY"
+
+	// MetricsLevel is to control which metrics the reconciler emits: none,
+	// the basic sync health metrics, or all of them.
+	MetricsLevel = "METRICS_LEVEL"
+
+	// CommonLabels is the JSON object of the labels that the reconciler adds
+	// to every object it applies.
+	CommonLabels = "COMMON_LABELS"
+
+	// CommonAnnotations is the JSON object of the annotations that the
+	// reconciler adds to every object it applies.
+	CommonAnnotations = "COMMON_ANNOTATIONS"
+
+	// PruneObsoleteMetadata is to control if the reconciler removes the
+	// annotations and labels that are no longer used by Config Sync from the
+	// managed objects.
+	PruneObsoleteMetadata = "PRUNE_OBSOLETE_METADATA"
+
+	// MigrateClientSideApply is to control if the reconciler transfers the
+	// fields owned by kubectl client-side apply to Config Sync, and removes
+	// the last-applied-configuration annotation, from the applied objects.
+	MigrateClientSideApply = "MIGRATE_CLIENT_SIDE_APPLY"
+
+	// NormalizeDeclarations is to control if the reconciler normalizes the
+	// declarations of objects, e.g. the defaulted fields and the quantities,
+	// before comparing them with their previous declarations.
+	NormalizeDeclarations = "NORMALIZE_DECLARATIONS"
+
+	// SelfUpdateTimeout is to control how long the root reconciler waits for
+	// the Config Sync components declared in the source of truth to become
+	// healthy after changing them, before reverting them.
+	SelfUpdateTimeout = "SELF_UPDATE_TIMEOUT"
+
+	// RetryBudget is the number of consecutive failed retries of a commit
+	// after which the reconciler stops retrying it, until the source changes.
+	RetryBudget = "RETRY_BUDGET"
+
 	// StatusMode is to control if the kpt applier needs to inject the actuation data
 	// into the ResourceGroup object.
 	StatusMode = "STATUS_MODE"
+
+	// TargetKubeconfig is the path to the kubeconfig of the cluster that the
+	// root reconciler syncs the resources to, if it is not the current cluster.
+	TargetKubeconfig = "TARGET_KUBECONFIG"
+
+	// Targets is the JSON encoded list of TargetConfig of the clusters that
+	// the root reconciler fans the resources out to.
+	Targets = "TARGETS"
 )
 
 const (
@@ -98,6 +225,10 @@ const (
 	// filesystem for updates to the source or rendered configs.
 	ReconcilerPollingPeriod = "RECONCILER_POLLING_PERIOD"
 
+	// StatusUpdatePeriod defines how often the reconciler should update the
+	// sync status of the RootSync or RepoSync.
+	StatusUpdatePeriod = "STATUS_UPDATE_PERIOD"
+
 	// HydrationPollingPeriod defines how often the hydration controller should
 	// poll the filesystem for rendering the DRY configs.
 	HydrationPollingPeriod = "HYDRATION_POLLING_PERIOD"
@@ -112,8 +243,99 @@ const (
 
 	// OciSyncWait is the OS env variable key for the OCI sync wait period in seconds.
 	OciSyncWait = "OCI_SYNC_WAIT"
+
+	// OciSyncWebhookAddr is the OS env variable key for the address of the
+	// registry webhook receiver in the oci-sync container.
+	OciSyncWebhookAddr = "OCI_SYNC_WEBHOOK_ADDR"
+
+	// OciSyncWebhookPort is the port of the registry webhook receiver in the
+	// oci-sync container.
+	OciSyncWebhookPort = 8680
 )
 
+const (
+	// ExecCredential is the OS env variable key for the JSON encoded exec
+	// credential plugin, which vends the token used to authenticate to the
+	// source.
+	ExecCredential = "EXEC_CREDENTIAL"
+
+	// CredentialPlugin is the name of the init container which installs the
+	// exec credential plugin.
+	CredentialPlugin = "credential-plugin"
+
+	// CredentialPluginDir is the OS env variable key for the directory into
+	// which the init container installs the exec credential plugin.
+	CredentialPluginDir = "CREDENTIAL_PLUGIN_DIR"
+
+	// CredentialAskpassPort is the port of the git askpass endpoint, which the
+	// reconciler serves with the token vended by the exec credential plugin.
+	CredentialAskpassPort = 9104
+)
+
+const (
+	// Converter is the OS env variable key for the JSON encoded converter
+	// plugin, which converts the source files that are not Kubernetes
+	// manifests into Kubernetes objects.
+	Converter = "CONVERTER"
+
+	// ConverterPlugin is the name of the init container which installs the
+	// converter plugin.
+	ConverterPlugin = "converter-plugin"
+
+	// ConverterPluginDir is the OS env variable key for the directory into
+	// which the init container installs the converter plugin.
+	ConverterPluginDir = "CONVERTER_PLUGIN_DIR"
+)
+
+const (
+	// GitWebhookReceiver is the name of the git webhook receiver Deployment,
+	// Service, ServiceAccount and Secret.
+	GitWebhookReceiver = "git-webhook-receiver"
+
+	// GitWebhookReceiverPort is the port of the git webhook receiver.
+	GitWebhookReceiverPort = 8080
+
+	// GitWebhookReceiverEnabled is the OS env variable key for whether the
+	// reconciler-manager runs the git webhook receiver.
+	GitWebhookReceiverEnabled = "GIT_WEBHOOK_RECEIVER"
+
+	// GitWebhookReceiverImage is the OS env variable key for the image of the
+	// git webhook receiver.
+	GitWebhookReceiverImage = "GIT_WEBHOOK_RECEIVER_IMAGE"
+
+	// TenantNamespaces is the OS env variable key for the comma-separated list
+	// of namespaces whose RepoSyncs are reconciled, when the reconciler-manager
+	// runs with only namespaced permissions.
+	TenantNamespaces = "TENANT_NAMESPACES"
+
+	// GitWebhookSecret is the OS env variable key for the secret shared with
+	// the Git hosting service, used to validate the push events.
+	GitWebhookSecret = "GIT_WEBHOOK_SECRET"
+
+	// GitWebhookSecretKey is the key of the shared secret in the git webhook
+	// receiver Secret.
+	GitWebhookSecretKey = "secret"
+)
+
+const (
+	// HydrationSigningKey is the OS env variable key for the key that the
+	// hydration-controller signs the hydrated configs with, and the reconciler
+	// verifies them with.
+	HydrationSigningKey = "HYDRATION_SIGNING_KEY"
+
+	// HydrationSigningKeySecret is the name of the Secret that holds the
+	// hydration signing key.
+	HydrationSigningKeySecret = "hydration-signing-key"
+
+	// HydrationSigningKeySecretKey is the key of the signing key in the
+	// hydration signing key Secret.
+	HydrationSigningKeySecretKey = "key"
+)
+
+// OtelCollectorEndpoint is the OS env variable key for the address of the
+// otel-collector that the otel-agent container exports the telemetry to.
+const OtelCollectorEndpoint = "OTEL_COLLECTOR_ENDPOINT"
+
 const (
 	// HelmRepo is the OS env variable key for the Helm repository URL.
 	HelmRepo = "HELM_REPO"
@@ -0,0 +1,208 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// annotationTTLCheckPeriod is how often the reconciler-manager looks for
+	// expired escape-hatch annotations.
+	annotationTTLCheckPeriod = time.Minute
+
+	// AnnotationExpiredReason is the reason of the events emitted when an
+	// expired escape-hatch annotation is removed.
+	AnnotationExpiredReason = "AnnotationExpired"
+)
+
+// expiringAnnotations are the escape-hatch annotations which expire, by the
+// kind of the objects they are set on. New escape hatches are registered here,
+// so that they come with a cleanup path.
+//
+// The emergency override annotation is not registered, since its value is its
+// expiry, and the remediator removes it when it reverts the object.
+var expiringAnnotations = []struct {
+	gvk  schema.GroupVersionKind
+	keys []string
+}{
+	{
+		gvk:  kinds.RootSyncV1Beta1(),
+		keys: []string{metadata.RestoreCommitAnnotationKey, metadata.ConfirmPruneKey, metadata.SyncPausedAnnotationKey},
+	},
+	{
+		gvk:  kinds.RepoSyncV1Beta1(),
+		keys: []string{metadata.RestoreCommitAnnotationKey, metadata.ConfirmPruneKey, metadata.SyncPausedAnnotationKey},
+	},
+	{
+		gvk:  kinds.Namespace(),
+		keys: []string{metadata.NamespaceFreezeAnnotationKey},
+	},
+	{
+		gvk:  kinds.CustomResourceDefinitionV1(),
+		keys: []string{metadata.AllowCRDPruneKey},
+	},
+}
+
+// AnnotationTTLController periodically removes the escape-hatch annotations,
+// e.g. the freeze of a Namespace or the restore of a snapshot, whose expiry
+// set with the matching `-expires-at` annotation has passed, and reports each
+// removal with an event on the object. Escape hatches set without an expiry
+// are kept until removed by users.
+//
+// Annotations declared in the source of truth are never removed, since the
+// reconciler would restore them.
+type AnnotationTTLController struct {
+	client client.Client
+	log    logr.Logger
+	now    func() time.Time
+}
+
+// NewAnnotationTTLController returns a new AnnotationTTLController.
+func NewAnnotationTTLController(c client.Client, log logr.Logger) *AnnotationTTLController {
+	return &AnnotationTTLController{
+		client: c,
+		log:    log,
+		now:    time.Now,
+	}
+}
+
+// Start implements manager.Runnable.
+func (a *AnnotationTTLController) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := a.expire(ctx); err != nil {
+			a.log.Error(err, "Failed to remove the expired annotations")
+		}
+	}, annotationTTLCheckPeriod)
+	return nil
+}
+
+// expire removes the expired escape-hatch annotations from all the objects.
+func (a *AnnotationTTLController) expire(ctx context.Context) error {
+	now := a.now()
+	var errs []error
+	for _, ea := range expiringAnnotations {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(ea.gvk.GroupVersion().WithKind(ea.gvk.Kind + "List"))
+		if err := a.client.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "failed to list the %s objects", ea.gvk.Kind))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			var expired []string
+			for _, key := range ea.keys {
+				expiry, found := metadata.AnnotationExpiry(obj, key)
+				if !found || now.Before(expiry) {
+					continue
+				}
+				if declaresAnnotation(obj, key) {
+					a.log.V(3).Info("Keeping the expired annotation declared in the source of truth",
+						logFieldObject, client.ObjectKeyFromObject(obj).String(),
+						logFieldKind, ea.gvk.Kind,
+						"annotation", key)
+					continue
+				}
+				expired = append(expired, key)
+			}
+			if len(expired) == 0 {
+				continue
+			}
+			if err := a.removeAnnotations(ctx, obj, expired); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// removeAnnotations removes the expired annotations and their expiries from
+// the object, and records an event for each of them.
+func (a *AnnotationTTLController) removeAnnotations(ctx context.Context, obj *unstructured.Unstructured, keys []string) error {
+	annotations := make(map[string]interface{}, 2*len(keys))
+	for _, key := range keys {
+		annotations[key] = nil
+		annotations[metadata.ExpiresAtKey(key)] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	// Keep the expiries for the events before patching the object.
+	expiries := make(map[string]string, len(keys))
+	for _, key := range keys {
+		expiries[key] = core.GetAnnotation(obj, metadata.ExpiresAtKey(key))
+	}
+	if err := a.client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return errors.Wrapf(err, "failed to remove the expired annotations %s from %s %s",
+			strings.Join(keys, ", "), obj.GetKind(), client.ObjectKeyFromObject(obj))
+	}
+	for _, key := range keys {
+		a.log.Info("Removed the expired annotation",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, obj.GetKind(),
+			"annotation", key,
+			"expiry", expiries[key])
+		a.recordExpiry(ctx, obj, key, expiries[key])
+	}
+	return nil
+}
+
+// recordExpiry records an event on the object for the removal of the expired
+// annotation. Failures are logged, but otherwise ignored, since the
+// annotation was already removed.
+func (a *AnnotationTTLController) recordExpiry(ctx context.Context, obj *unstructured.Unstructured, key, expiry string) {
+	e := event.New(event.Reference(obj, obj.GroupVersionKind()), corev1.EventTypeNormal, AnnotationExpiredReason,
+		fmt.Sprintf("Removed the %s annotation, because it expired at %s", key, expiry),
+		"reconciler-manager", metav1.NewTime(a.now()))
+	if err := a.client.Create(ctx, e); err != nil {
+		a.log.Error(err, "Failed to record the event",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, obj.GetKind(),
+			"reason", AnnotationExpiredReason)
+	}
+}
+
+// declaresAnnotation returns true if the annotation of the managed object is
+// declared in the source of truth.
+func declaresAnnotation(obj client.Object, key string) bool {
+	declaredFields := core.GetAnnotation(obj, metadata.DeclaredFieldsKey)
+	return strings.Contains(declaredFields, fmt.Sprintf(`"f:%s"`, key))
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestAnnotationTTLController(t *testing.T) {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute).Format(time.RFC3339)
+	future := now.Add(time.Minute).Format(time.RFC3339)
+	freezeExpiresAt := metadata.ExpiresAtKey(metadata.NamespaceFreezeAnnotationKey)
+
+	testCases := []struct {
+		name            string
+		obj             client.Object
+		wantAnnotations map[string]string
+		wantEvent       bool
+	}{
+		{
+			name: "expired freeze is removed",
+			obj: fake.NamespaceObject("bookstore",
+				core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled),
+				core.Annotation(freezeExpiresAt, past)),
+			wantEvent: true,
+		},
+		{
+			name: "freeze which has not expired is kept",
+			obj: fake.NamespaceObject("bookstore",
+				core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled),
+				core.Annotation(freezeExpiresAt, future)),
+			wantAnnotations: map[string]string{
+				metadata.NamespaceFreezeAnnotationKey: metadata.NamespaceFreezeEnabled,
+				freezeExpiresAt:                       future,
+			},
+		},
+		{
+			name: "freeze without expiry is kept",
+			obj: fake.NamespaceObject("bookstore",
+				core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled)),
+			wantAnnotations: map[string]string{
+				metadata.NamespaceFreezeAnnotationKey: metadata.NamespaceFreezeEnabled,
+			},
+		},
+		{
+			name: "declared freeze is kept",
+			obj: fake.NamespaceObject("bookstore",
+				core.Annotation(metadata.NamespaceFreezeAnnotationKey, metadata.NamespaceFreezeEnabled),
+				core.Annotation(freezeExpiresAt, past),
+				core.Annotation(metadata.DeclaredFieldsKey, `{"f:metadata":{"f:annotations":{"f:configsync.gke.io/freeze":{}}}}`)),
+			wantAnnotations: map[string]string{
+				metadata.NamespaceFreezeAnnotationKey: metadata.NamespaceFreezeEnabled,
+				freezeExpiresAt:                       past,
+				metadata.DeclaredFieldsKey:            `{"f:metadata":{"f:annotations":{"f:configsync.gke.io/freeze":{}}}}`,
+			},
+		},
+		{
+			name: "expired restore of a RootSync is removed",
+			obj: fake.RootSyncObjectV1Beta1(configsync.RootSyncName,
+				core.Annotation(metadata.RestoreCommitAnnotationKey, "abc123"),
+				core.Annotation(metadata.ExpiresAtKey(metadata.RestoreCommitAnnotationKey), past),
+				core.Annotation(metadata.ConfirmPruneKey, "10")),
+			wantAnnotations: map[string]string{
+				metadata.ConfirmPruneKey: "10",
+			},
+			wantEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := syncerFake.NewClient(t, core.Scheme, tc.obj)
+			controller := NewAnnotationTTLController(fakeClient, logr.Discard())
+			controller.now = func() time.Time { return now }
+			require.NoError(t, controller.expire(ctx))
+
+			var got client.Object
+			if _, ok := tc.obj.(*v1beta1.RootSync); ok {
+				got = &v1beta1.RootSync{}
+			} else {
+				got = &corev1.Namespace{}
+			}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(tc.obj), got))
+			gotAnnotations := got.GetAnnotations()
+			if len(gotAnnotations) == 0 {
+				gotAnnotations = nil
+			}
+			var wantAnnotations map[string]string
+			if len(tc.wantAnnotations) > 0 {
+				wantAnnotations = tc.wantAnnotations
+			}
+			require.Equal(t, wantAnnotations, gotAnnotations)
+
+			events := &corev1.EventList{}
+			require.NoError(t, fakeClient.List(ctx, events))
+			if !tc.wantEvent {
+				require.Empty(t, events.Items)
+				return
+			}
+			require.Len(t, events.Items, 1)
+			require.Equal(t, AnnotationExpiredReason, events.Items[0].Reason)
+			require.Equal(t, tc.obj.GetName(), events.Items[0].InvolvedObject.Name)
+		})
+	}
+}
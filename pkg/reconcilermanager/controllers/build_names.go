@@ -32,6 +32,17 @@ func RepoSyncPermissionsName() string {
 	return fmt.Sprintf("%s:%s", configsync.GroupName, core.NsReconcilerPrefix)
 }
 
+// RepoSyncClusterPermissionsNames returns the names of the ClusterRoles which
+// grant the namespace reconcilers access to cluster-scoped objects, and of the
+// ClusterRoleBindings which bind them to the namespace reconcilers.
+// e.g. configsync.gke.io:ns-reconciler-namespace-reader
+func RepoSyncClusterPermissionsNames() []string {
+	return []string{
+		RepoSyncPermissionsName() + "-namespace-reader",
+		RepoSyncPermissionsName() + "-declared-object-mutator-reader",
+	}
+}
+
 // RootSyncPermissionsName returns root reconciler permissions name.
 // e.g. configsync.gke.io:root-reconciler
 func RootSyncPermissionsName() string {
@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/converter"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+)
+
+// ConverterPluginVolume is the name of the volume into which the converter
+// plugin is installed.
+const ConverterPluginVolume = "converter-plugin"
+
+// converterEnvs returns the environment variables which pass the converter
+// plugin to the reconciler container.
+func converterEnvs(plugin *v1beta1.Converter) []corev1.EnvVar {
+	if plugin == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.Converter,
+		Value: converter.Encode(plugin),
+	}}
+}
+
+// converterPluginVolume returns the volume shared by the init container which
+// installs the converter plugin, and the reconciler container which executes
+// it.
+func converterPluginVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: ConverterPluginVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+// converterPluginVolumeMount returns the VolumeMount of the volume returned
+// by converterPluginVolume.
+func converterPluginVolumeMount(readOnly bool) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      ConverterPluginVolume,
+		MountPath: converter.DefaultPluginDir,
+		ReadOnly:  readOnly,
+	}
+}
+
+// converterPluginInitContainer returns the init container which installs the
+// converter plugin from its image.
+func converterPluginInitContainer(plugin *v1beta1.Converter) corev1.Container {
+	return corev1.Container{
+		Name:  reconcilermanager.ConverterPlugin,
+		Image: plugin.Image,
+		Env: []corev1.EnvVar{{
+			Name:  reconcilermanager.ConverterPluginDir,
+			Value: converter.DefaultPluginDir,
+		}},
+		VolumeMounts:             []corev1.VolumeMount{converterPluginVolumeMount(false)},
+		SecurityContext:          setSecurityContext(),
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/remediator/reconcile"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// dashboardAPIPath is the path of the JSON variant of the dashboard.
+	dashboardAPIPath = "/api/rsyncs"
+
+	// dashboardMaxErrors is the maximum number of errors shown per RSync.
+	dashboardMaxErrors = 10
+
+	// dashboardMaxDriftEvents is the maximum number of drift events shown per
+	// RSync.
+	dashboardMaxDriftEvents = 10
+
+	// dashboardShutdownTimeout is how long to wait for the in-flight
+	// dashboard requests to complete when the reconciler-manager stops.
+	dashboardShutdownTimeout = 5 * time.Second
+)
+
+// DashboardRSync is the view of a single RootSync or RepoSync on the
+// dashboard.
+type DashboardRSync struct {
+	SyncStatusSummary `json:",inline"`
+
+	Stages          []DashboardStage             `json:"stages"`
+	Conditions      []DashboardCondition         `json:"conditions,omitempty"`
+	Errors          []v1beta1.ConfigSyncError    `json:"errors,omitempty"`
+	DriftEvents     []DashboardEvent             `json:"driftEvents,omitempty"`
+	PendingPlan     *v1beta1.DryRunStatus        `json:"pendingPlan,omitempty"`
+	PendingApproval *v1beta1.ApprovalRequestSpec `json:"pendingApproval,omitempty"`
+}
+
+// DashboardStage is the progress of the RSync through one of the source,
+// rendering and sync stages.
+type DashboardStage struct {
+	Name       string      `json:"name"`
+	Commit     string      `json:"commit,omitempty"`
+	ErrorCount int         `json:"errorCount"`
+	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+}
+
+// DashboardCondition is a condition of the RSync.
+type DashboardCondition struct {
+	Type    string                 `json:"type"`
+	Status  metav1.ConditionStatus `json:"status"`
+	Reason  string                 `json:"reason,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// DashboardEvent is a drift event recorded on the RSync.
+type DashboardEvent struct {
+	Time    metav1.Time `json:"time"`
+	Reason  string      `json:"reason"`
+	Message string      `json:"message"`
+	Count   int32       `json:"count,omitempty"`
+}
+
+// Dashboard serves a read-only web dashboard of all the RSyncs on the
+// cluster, with their stages, recent errors, drift events and pending plans.
+//
+// The dashboard has no authentication of its own, and only ever reads from
+// the API server, so it is meant to be exposed through the authenticating
+// proxy of the cluster, or with a port-forward. The reconciler-manager only
+// serves it with the explicit --dashboard-allow-unauthenticated opt-in.
+type Dashboard struct {
+	addr   string
+	client client.Reader
+	log    logr.Logger
+}
+
+// NewDashboard returns a Dashboard listening on addr, which reads the RSyncs
+// with the client.
+func NewDashboard(addr string, c client.Reader, log logr.Logger) *Dashboard {
+	return &Dashboard{
+		addr:   addr,
+		client: c,
+		log:    log,
+	}
+}
+
+// Start serves the dashboard until the context is cancelled. It implements
+// manager.Runnable.
+func (d *Dashboard) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              d.addr,
+		Handler:           d,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), dashboardShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			d.log.Error(err, "Failed to shut down the dashboard")
+		}
+	}()
+	d.log.Info("Serving the dashboard", "addr", d.addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != "/" && r.URL.Path != dashboardAPIPath {
+		http.NotFound(w, r)
+		return
+	}
+	rsyncs, err := d.list(r.Context())
+	if err != nil {
+		d.log.Error(err, "Failed to list the RSyncs for the dashboard")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	if r.URL.Path == dashboardAPIPath {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rsyncs)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, rsyncs); err != nil {
+		d.log.Error(err, "Failed to render the dashboard")
+	}
+}
+
+// list returns the views of all the RSyncs, sorted like the status summaries.
+func (d *Dashboard) list(ctx context.Context) ([]DashboardRSync, error) {
+	var rsyncs []DashboardRSync
+
+	rootSyncList := &v1beta1.RootSyncList{}
+	if err := d.client.List(ctx, rootSyncList, client.InNamespace(configsync.ControllerNamespace)); err != nil {
+		return nil, status.APIServerError(err, "failed to list RootSyncs")
+	}
+	for i := range rootSyncList.Items {
+		rs := &rootSyncList.Items[i]
+		view := dashboardView(summarizeRootSync(rs), &rs.Status.Status)
+		for _, c := range rs.Status.Conditions {
+			view.Conditions = append(view.Conditions, DashboardCondition{
+				Type: string(c.Type), Status: c.Status, Reason: c.Reason, Message: c.Message,
+			})
+		}
+		rsyncs = append(rsyncs, view)
+	}
+
+	repoSyncList := &v1beta1.RepoSyncList{}
+	if err := d.client.List(ctx, repoSyncList); err != nil {
+		return nil, status.APIServerError(err, "failed to list RepoSyncs")
+	}
+	for i := range repoSyncList.Items {
+		rs := &repoSyncList.Items[i]
+		view := dashboardView(summarizeRepoSync(rs), &rs.Status.Status)
+		for _, c := range rs.Status.Conditions {
+			view.Conditions = append(view.Conditions, DashboardCondition{
+				Type: string(c.Type), Status: c.Status, Reason: c.Reason, Message: c.Message,
+			})
+		}
+		rsyncs = append(rsyncs, view)
+	}
+
+	approvals := &v1beta1.ApprovalRequestList{}
+	if err := d.client.List(ctx, approvals); err != nil {
+		return nil, status.APIServerError(err, "failed to list ApprovalRequests")
+	}
+	pendingApprovals := make(map[string]*v1beta1.ApprovalRequestSpec)
+	for i := range approvals.Items {
+		ar := &approvals.Items[i]
+		if ar.Spec.Commit == "" || (ar.Status.Commit == ar.Spec.Commit && ar.Status.Decision != "") {
+			continue
+		}
+		pendingApprovals[ar.Namespace+"/"+ar.Name] = &ar.Spec
+	}
+
+	driftEvents, err := d.driftEvents(ctx, rsyncs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rsyncs {
+		rs := &rsyncs[i]
+		reconcilerName := core.RootReconcilerName(rs.Name)
+		if rs.Kind == configsync.RepoSyncKind {
+			reconcilerName = core.NsReconcilerName(rs.Namespace, rs.Name)
+		}
+		rs.PendingApproval = pendingApprovals[rs.Namespace+"/"+reconcilerName]
+		rs.DriftEvents = driftEvents[badgeKey(rs.Kind, rs.Namespace, rs.Name)]
+	}
+
+	sort.Slice(rsyncs, func(i, j int) bool {
+		if rsyncs[i].Kind != rsyncs[j].Kind {
+			return rsyncs[i].Kind < rsyncs[j].Kind
+		}
+		if rsyncs[i].Namespace != rsyncs[j].Namespace {
+			return rsyncs[i].Namespace < rsyncs[j].Namespace
+		}
+		return rsyncs[i].Name < rsyncs[j].Name
+	})
+	return rsyncs, nil
+}
+
+// driftEvents returns the most recent drift events of each RSync, keyed by
+// badgeKey. The drift events are the events the remediator records on the
+// RSyncs when it recreates a deleted object. Only those are listed, with field
+// selectors, since the events are not cached.
+func (d *Dashboard) driftEvents(ctx context.Context, rsyncs []DashboardRSync) (map[string][]DashboardEvent, error) {
+	// The namespaces of the RSyncs, by kind.
+	namespaces := make(map[string]map[string]bool)
+	for _, rs := range rsyncs {
+		if namespaces[rs.Kind] == nil {
+			namespaces[rs.Kind] = make(map[string]bool)
+		}
+		namespaces[rs.Kind][rs.Namespace] = true
+	}
+	result := make(map[string][]DashboardEvent)
+	for kind, kindNamespaces := range namespaces {
+		for ns := range kindNamespaces {
+			events := &corev1.EventList{}
+			if err := d.client.List(ctx, events, client.InNamespace(ns), client.MatchingFields{
+				"involvedObject.kind": kind,
+				"reason":              reconcile.ObjectRecreatedReason,
+			}); err != nil {
+				return nil, status.APIServerErrorf(err, "failed to list the %s events in namespace %s", kind, ns)
+			}
+			for _, e := range events.Items {
+				key := badgeKey(kind, e.Namespace, e.InvolvedObject.Name)
+				eventTime := e.LastTimestamp
+				if eventTime.IsZero() {
+					eventTime = e.FirstTimestamp
+				}
+				result[key] = append(result[key], DashboardEvent{
+					Time:    eventTime,
+					Reason:  e.Reason,
+					Message: e.Message,
+					Count:   e.Count,
+				})
+			}
+		}
+	}
+	for key, events := range result {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[j].Time.Before(&events[i].Time)
+		})
+		if len(events) > dashboardMaxDriftEvents {
+			result[key] = events[:dashboardMaxDriftEvents]
+		}
+	}
+	return result, nil
+}
+
+// dashboardView returns the view of the fields that are common to RootSyncs
+// and RepoSyncs.
+func dashboardView(summary SyncStatusSummary, s *v1beta1.Status) DashboardRSync {
+	view := DashboardRSync{
+		SyncStatusSummary: summary,
+		Stages: []DashboardStage{
+			{Name: "Source", Commit: s.Source.Commit, ErrorCount: errorCount(s.Source.ErrorSummary, s.Source.Errors), LastUpdate: s.Source.LastUpdate},
+			{Name: "Rendering", Commit: s.Rendering.Commit, ErrorCount: errorCount(s.Rendering.ErrorSummary, s.Rendering.Errors), LastUpdate: s.Rendering.LastUpdate},
+			{Name: "Sync", Commit: s.Sync.Commit, ErrorCount: errorCount(s.Sync.ErrorSummary, s.Sync.Errors), LastUpdate: s.Sync.LastUpdate},
+		},
+		PendingPlan: s.DryRun,
+	}
+	for _, errs := range [][]v1beta1.ConfigSyncError{s.Rendering.Errors, s.Source.Errors, s.Sync.Errors} {
+		for _, e := range errs {
+			if len(view.Errors) == dashboardMaxErrors {
+				return view
+			}
+			view.Errors = append(view.Errors, e)
+		}
+	}
+	return view
+}
+
+// errorCount returns the total number of errors of a stage, which may be more
+// than the errors in the status, since those are truncated.
+func errorCount(summary *v1beta1.ErrorSummary, errs []v1beta1.ConfigSyncError) int {
+	if summary != nil {
+		return summary.TotalCount
+	}
+	return len(errs)
+}
+
+// shortCommit returns the short hash of the commit.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"short": shortCommit,
+	"color": func(state SyncState) string { return badgeColors[state] },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>Config Sync</title>
+<style>
+body { font-family: Verdana, Geneva, "DejaVu Sans", sans-serif; font-size: 13px; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 0.5em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+.rsync { border: 1px solid #ccc; border-radius: 4px; padding: 1em; margin-bottom: 1.5em; }
+.state { color: #fff; border-radius: 3px; padding: 2px 6px; }
+code { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Config Sync</h1>
+{{- if not .}}
+<p>No RootSyncs or RepoSyncs on the cluster.</p>
+{{- end}}
+{{- range .}}
+<div class="rsync">
+<h2>{{.Kind}} {{.Namespace}}/{{.Name}} <span class="state" style="background: {{color .State}}">{{.State}}</span></h2>
+<table>
+<tr><th>Stage</th><th>Commit</th><th>Errors</th><th>Last update</th></tr>
+{{- range .Stages}}
+<tr><td>{{.Name}}</td><td><code>{{short .Commit}}</code></td><td>{{.ErrorCount}}</td><td>{{if not .LastUpdate.IsZero}}{{.LastUpdate.Format "2006-01-02 15:04:05Z07:00"}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Conditions}}
+<h3>Conditions</h3>
+<table>
+<tr><th>Type</th><th>Status</th><th>Reason</th><th>Message</th></tr>
+{{- range .Conditions}}
+<tr><td>{{.Type}}</td><td>{{.Status}}</td><td>{{.Reason}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Errors}}
+<h3>Recent errors</h3>
+<table>
+<tr><th>Code</th><th>Message</th></tr>
+{{- range .Errors}}
+<tr><td>KNV{{.Code}}</td><td>{{.ErrorMessage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .DriftEvents}}
+<h3>Drift events</h3>
+<table>
+<tr><th>Time</th><th>Reason</th><th>Count</th><th>Message</th></tr>
+{{- range .DriftEvents}}
+<tr><td>{{.Time.Format "2006-01-02 15:04:05Z07:00"}}</td><td>{{.Reason}}</td><td>{{.Count}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .PendingApproval}}
+<h3>Pending approval</h3>
+<p>Commit <code>{{short .Commit}}</code> replacing <code>{{short .LastSyncedCommit}}</code>, expires at {{.ExpiresAt.Format "2006-01-02 15:04:05Z07:00"}}.</p>
+{{- end}}
+{{- with .PendingPlan}}
+<h3>Pending plan</h3>
+<p>Commit <code>{{short .Commit}}</code>: {{.Adds}} to add, {{.Changes}} to change, {{.Prunes}} to prune, {{.Unchanged}} unchanged.</p>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/remediator/reconcile"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+)
+
+func TestDashboard(t *testing.T) {
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+
+	rootSync := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+	rootSync.Status.Source.Commit = "1234567890abcdef"
+	rootSync.Status.Sync.Commit = "1234567890abcdef"
+	rootSync.Status.Sync.Errors = []v1beta1.ConfigSyncError{
+		{Code: "2009", ErrorMessage: "failed to apply <script>"},
+	}
+	rootSync.Status.Sync.ErrorSummary = &v1beta1.ErrorSummary{TotalCount: 1}
+	rootSync.Status.DryRun = &v1beta1.DryRunStatus{Commit: "fedcba0987654321", Adds: 2, Prunes: 1}
+
+	repoSync := fake.RepoSyncObjectV1Beta1("bookstore", configsync.RepoSyncName)
+	repoSync.Status.Source.Commit = "abcdef1234567890"
+	repoSync.Status.Sync.Commit = "abcdef1234567890"
+	repoSync.Status.LastSyncedCommit = "abcdef1234567890"
+
+	approval := &v1beta1.ApprovalRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      core.NsReconcilerName("bookstore", configsync.RepoSyncName),
+			Namespace: "bookstore",
+		},
+		Spec: v1beta1.ApprovalRequestSpec{
+			Commit:           "0123456789abcdef",
+			LastSyncedCommit: "abcdef1234567890",
+		},
+	}
+
+	drift := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "drift", Namespace: "bookstore"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      configsync.RepoSyncKind,
+			Namespace: "bookstore",
+			Name:      configsync.RepoSyncName,
+		},
+		Reason:        reconcile.ObjectRecreatedReason,
+		Message:       "Recreated the deleted Role bookstore/admin",
+		LastTimestamp: now,
+		Count:         3,
+	}
+	other := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "bookstore"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      configsync.RepoSyncKind,
+			Namespace: "bookstore",
+			Name:      configsync.RepoSyncName,
+		},
+		Reason:        "SyncPending",
+		LastTimestamp: now,
+	}
+
+	// Recorded by the remediator of another tool on a managed object.
+	objectEvent := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "object", Namespace: "bookstore"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Role",
+			Namespace: "bookstore",
+			Name:      "admin",
+		},
+		Reason:        reconcile.ObjectRecreatedReason,
+		LastTimestamp: now,
+	}
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme, rootSync, repoSync, approval, drift, other, objectEvent)
+	d := NewDashboard(":0", fakeClient, controllerruntime.Log.WithName("dashboard"))
+
+	t.Run("JSON", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, dashboardAPIPath, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var got []DashboardRSync
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got, 2)
+
+		require.Equal(t, configsync.RepoSyncKind, got[0].Kind)
+		require.Equal(t, SyncStatePending, got[0].State)
+		require.Equal(t, []DashboardEvent{{
+			Time:    now,
+			Reason:  reconcile.ObjectRecreatedReason,
+			Message: "Recreated the deleted Role bookstore/admin",
+			Count:   3,
+		}}, got[0].DriftEvents)
+		require.Equal(t, &approval.Spec, got[0].PendingApproval)
+
+		require.Equal(t, configsync.RootSyncKind, got[1].Kind)
+		require.Equal(t, SyncStateError, got[1].State)
+		require.Equal(t, rootSync.Status.Sync.Errors, got[1].Errors)
+		require.Equal(t, []DashboardStage{
+			{Name: "Source", Commit: "1234567890abcdef"},
+			{Name: "Rendering"},
+			{Name: "Sync", Commit: "1234567890abcdef", ErrorCount: 1},
+		}, got[1].Stages)
+		require.Equal(t, rootSync.Status.DryRun, got[1].PendingPlan)
+		require.Nil(t, got[1].PendingApproval)
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		require.Contains(t, body, "RepoSync bookstore/repo-sync")
+		require.Contains(t, body, "Recreated the deleted Role bookstore/admin")
+		require.Contains(t, body, "2 to add, 0 to change, 1 to prune")
+		require.Contains(t, body, "failed to apply &lt;script&gt;")
+		require.False(t, strings.Contains(body, "<script>"))
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/execcredential"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"kpt.dev/configsync/pkg/validate/raw/validate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialPluginVolume is the name of the volume into which the exec
+// credential plugin is installed.
+const CredentialPluginVolume = "credential-plugin"
+
+// execCredentialAskpassURL is the git askpass endpoint served by the
+// reconciler container, when the Git auth is exec.
+var execCredentialAskpassURL = fmt.Sprintf("http://localhost:%d%s", reconcilermanager.CredentialAskpassPort, execcredential.AskpassPath)
+
+// SetPluginImages restricts the images of the plugins of the RepoSyncs to
+// the images allowed by the cluster admin. The plugins run in the reconciler
+// Pods, so an arbitrary image could steal the credentials of the reconciler.
+// RepoSyncs cannot use plugins if no image is allowed. RootSyncs are managed
+// by the cluster admins, so their plugins may use any image.
+func (r *reconcilerBase) SetPluginImages(images []string) {
+	r.pluginImages = images
+}
+
+// ParsePluginImages parses the comma-separated list of the images allowed for
+// the plugins of the RepoSyncs. Empty entries are dropped.
+func ParsePluginImages(value string) []string {
+	var images []string
+	for _, image := range strings.Split(value, ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// validatePluginImage returns an error if the RSync is a RepoSync, and the
+// image of its plugin declared by the field is not allowed.
+func (r *reconcilerBase) validatePluginImage(rs client.Object, field, image string) error {
+	if _, isRepoSync := rs.(*v1beta1.RepoSync); !isRepoSync {
+		return nil
+	}
+	for _, allowed := range r.pluginImages {
+		if image == allowed {
+			return nil
+		}
+	}
+	return validate.DisallowedPluginImage(rs, field, image)
+}
+
+// sourceExecCredential returns the exec credential plugin of the source, or
+// nil if the source doesn't use the exec auth.
+func sourceExecCredential(sourceType string, git *v1beta1.Git, oci *v1beta1.Oci) *v1beta1.ExecCredential {
+	switch v1beta1.SourceType(sourceType) {
+	case v1beta1.GitSource:
+		if git != nil && git.Auth == configsync.AuthExec {
+			return git.ExecCredential
+		}
+	case v1beta1.OciSource:
+		if oci != nil && oci.Auth == configsync.AuthExec {
+			return oci.ExecCredential
+		}
+	}
+	return nil
+}
+
+// execCredentialContainer returns the name of the container which executes
+// the exec credential plugin. git-sync can't execute the plugin, so the
+// reconciler executes it, and serves the token to git-sync from the git
+// askpass endpoint.
+func execCredentialContainer(sourceType string) string {
+	if v1beta1.SourceType(sourceType) == v1beta1.OciSource {
+		return reconcilermanager.OciSync
+	}
+	return reconcilermanager.Reconciler
+}
+
+// execCredentialEnvs returns the environment variables which pass the exec
+// credential plugin to the container that executes it.
+func execCredentialEnvs(plugin *v1beta1.ExecCredential) []corev1.EnvVar {
+	if plugin == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.ExecCredential,
+		Value: execcredential.Encode(plugin),
+	}}
+}
+
+// credentialPluginVolume returns the volume shared by the init container
+// which installs the exec credential plugin, and the container which executes
+// it.
+func credentialPluginVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: CredentialPluginVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+// credentialPluginVolumeMount returns the VolumeMount of the volume returned
+// by credentialPluginVolume.
+func credentialPluginVolumeMount(readOnly bool) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      CredentialPluginVolume,
+		MountPath: execcredential.DefaultPluginDir,
+		ReadOnly:  readOnly,
+	}
+}
+
+// credentialPluginInitContainer returns the init container which installs
+// the exec credential plugin from its image.
+func credentialPluginInitContainer(plugin *v1beta1.ExecCredential) corev1.Container {
+	return corev1.Container{
+		Name:  reconcilermanager.CredentialPlugin,
+		Image: plugin.Image,
+		Env: []corev1.EnvVar{{
+			Name:  reconcilermanager.CredentialPluginDir,
+			Value: execcredential.DefaultPluginDir,
+		}},
+		VolumeMounts:             []corev1.VolumeMount{credentialPluginVolumeMount(false)},
+		SecurityContext:          setSecurityContext(),
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+	}
+}
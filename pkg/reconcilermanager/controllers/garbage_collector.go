@@ -53,6 +53,10 @@ func (r *RepoSyncReconciler) cleanupNSControllerResources(ctx context.Context, r
 	if err := r.deleteDeployment(ctx, reconcilerRef); err != nil {
 		return err
 	}
+	// Job of one-shot syncs
+	if err := r.deleteWorkload(ctx, reconcilerRef, kinds.Job()); err != nil {
+		return err
+	}
 	// configmaps
 	if err := r.deleteConfigMaps(ctx, reconcilerRef); err != nil {
 		return err
@@ -65,10 +69,18 @@ func (r *RepoSyncReconciler) cleanupNSControllerResources(ctx context.Context, r
 	if err := r.deleteRoleBinding(ctx, reconcilerRef, rsKey); err != nil {
 		return err
 	}
+	// clusterrolebindings
+	if err := r.deleteClusterRoleBindings(ctx, reconcilerRef); err != nil {
+		return err
+	}
 	// secret
 	if err := r.deleteSecrets(ctx, reconcilerRef); err != nil {
 		return err
 	}
+	// registry webhook receiver service
+	if err := r.deleteOciWebhookService(ctx, reconcilerRef); err != nil {
+		return err
+	}
 
 	delete(r.repoSyncs, rsKey)
 	return nil
@@ -147,6 +159,35 @@ func (r *RepoSyncReconciler) deleteDeployment(ctx context.Context, reconcilerRef
 	return r.cleanup(ctx, reconcilerRef, kinds.Deployment())
 }
 
+func (r *RepoSyncReconciler) deleteClusterRoleBindings(ctx context.Context, reconcilerRef types.NamespacedName) error {
+	if r.namespacedOnly {
+		return nil
+	}
+	for _, name := range RepoSyncClusterPermissionsNames() {
+		crbKey := client.ObjectKey{Name: name}
+		// Update the CRB to delete the subject for the deleted RepoSync's reconciler
+		crb := &rbacv1.ClusterRoleBinding{}
+		if err := r.client.Get(ctx, crbKey, crb); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get the ClusterRoleBinding object %s", crbKey)
+		}
+		crb.Subjects = removeSubject(crb.Subjects, r.serviceAccountSubject(reconcilerRef))
+		if len(crb.Subjects) == 0 {
+			// Delete the whole CRB
+			if err := r.cleanup(ctx, crbKey, kinds.ClusterRoleBinding()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.client.Update(ctx, crb); err != nil {
+			return errors.Wrapf(err, "failed to update the ClusterRoleBinding object %s", crbKey)
+		}
+	}
+	return nil
+}
+
 func (r *RootSyncReconciler) deleteClusterRoleBinding(ctx context.Context, reconcilerRef types.NamespacedName) error {
 	crbKey := client.ObjectKey{Name: RootSyncPermissionsName()}
 	// Update the CRB to delete the subject for the deleted RootSync's reconciler
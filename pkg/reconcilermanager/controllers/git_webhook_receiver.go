@@ -0,0 +1,242 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// gitWebhookReceiverResyncPeriod is how often the git webhook receiver
+	// objects are restored, if they were changed or deleted.
+	gitWebhookReceiverResyncPeriod = 5 * time.Minute
+
+	gitWebhookReceiverPortName = "http"
+)
+
+// GitWebhookReceiverPermissionsName returns the name of the ClusterRole and
+// ClusterRoleBinding of the git webhook receiver.
+// e.g. configsync.gke.io:git-webhook-receiver
+func GitWebhookReceiverPermissionsName() string {
+	return fmt.Sprintf("%s:%s", configsync.GroupName, reconcilermanager.GitWebhookReceiver)
+}
+
+// GitWebhookReceiver runs the git webhook receiver, which requests an
+// immediate sync of the RootSyncs and RepoSyncs when their Git repository
+// receives a push. It keeps the ServiceAccount, permissions, Deployment and
+// Service of the receiver up to date.
+//
+// The receiver reads the secret shared with the Git hosting service from the
+// `git-webhook-receiver` Secret in the config-management-system namespace,
+// which is created by the user. The Service is not exposed outside of the
+// cluster: users route the webhooks of their Git hosting service to it, with
+// an Ingress for example.
+type GitWebhookReceiver struct {
+	client client.Client
+	image  string
+	log    logr.Logger
+}
+
+// NewGitWebhookReceiver returns a new GitWebhookReceiver that runs the image.
+func NewGitWebhookReceiver(c client.Client, image string, log logr.Logger) *GitWebhookReceiver {
+	return &GitWebhookReceiver{
+		client: c,
+		image:  image,
+		log:    log,
+	}
+}
+
+// Start implements manager.Runnable.
+func (g *GitWebhookReceiver) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.upsert(ctx); err != nil {
+			g.log.Error(err, "Failed to upsert the git webhook receiver")
+		}
+	}, gitWebhookReceiverResyncPeriod)
+	return nil
+}
+
+func (g *GitWebhookReceiver) upsert(ctx context.Context) error {
+	labelMap := map[string]string{
+		"app":                reconcilermanager.GitWebhookReceiver,
+		metadata.SystemLabel: "true",
+		metadata.ArchLabel:   "csmr",
+	}
+
+	sa := &corev1.ServiceAccount{}
+	sa.Name = reconcilermanager.GitWebhookReceiver
+	sa.Namespace = configsync.ControllerNamespace
+	if err := g.createOrUpdate(ctx, sa, "ServiceAccount", func() error {
+		sa.Labels = mergeLabels(sa.Labels, labelMap)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	cr := &rbacv1.ClusterRole{}
+	cr.Name = GitWebhookReceiverPermissionsName()
+	if err := g.createOrUpdate(ctx, cr, "ClusterRole", func() error {
+		cr.Labels = mergeLabels(cr.Labels, labelMap)
+		cr.Rules = []rbacv1.PolicyRule{{
+			APIGroups: []string{configsync.GroupName},
+			Resources: []string{"rootsyncs", "reposyncs"},
+			Verbs:     []string{"get", "list", "patch"},
+		}}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	crb := &rbacv1.ClusterRoleBinding{}
+	crb.Name = GitWebhookReceiverPermissionsName()
+	if err := g.createOrUpdate(ctx, crb, "ClusterRoleBinding", func() error {
+		crb.Labels = mergeLabels(crb.Labels, labelMap)
+		crb.RoleRef = rolereference(GitWebhookReceiverPermissionsName(), kinds.ClusterRole().Kind)
+		crb.Subjects = []rbacv1.Subject{
+			newSubject(sa.Name, sa.Namespace, kinds.ServiceAccount().Kind),
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	dep := &appsv1.Deployment{}
+	dep.Name = reconcilermanager.GitWebhookReceiver
+	dep.Namespace = configsync.ControllerNamespace
+	if err := g.createOrUpdate(ctx, dep, "Deployment", func() error {
+		dep.Labels = mergeLabels(dep.Labels, labelMap)
+		if dep.Spec.Selector == nil {
+			// The selector is immutable.
+			dep.Spec.Selector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": reconcilermanager.GitWebhookReceiver},
+			}
+		}
+		dep.Spec.Replicas = pointer.Int32(1)
+		dep.Spec.Template.Labels = mergeLabels(dep.Spec.Template.Labels, labelMap)
+		dep.Spec.Template.Spec.ServiceAccountName = sa.Name
+		// Only set the fields of the container owned by the reconciler-manager,
+		// keeping the defaults set by the API server, to avoid an update on
+		// every resync.
+		container := corev1.Container{Name: reconcilermanager.GitWebhookReceiver}
+		if containers := dep.Spec.Template.Spec.Containers; len(containers) == 1 && containers[0].Name == container.Name {
+			container = containers[0]
+		}
+		g.mutateContainer(&container)
+		dep.Spec.Template.Spec.Containers = []corev1.Container{container}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	svc := &corev1.Service{}
+	svc.Name = reconcilermanager.GitWebhookReceiver
+	svc.Namespace = configsync.ControllerNamespace
+	return g.createOrUpdate(ctx, svc, "Service", func() error {
+		svc.Labels = mergeLabels(svc.Labels, labelMap)
+		svc.Spec.Selector = map[string]string{"app": reconcilermanager.GitWebhookReceiver}
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       gitWebhookReceiverPortName,
+			Port:       80,
+			TargetPort: intstr.FromString(gitWebhookReceiverPortName),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+		return nil
+	})
+}
+
+func (g *GitWebhookReceiver) mutateContainer(container *corev1.Container) {
+	container.Image = g.image
+	container.Command = []string{"/" + reconcilermanager.GitWebhookReceiver}
+	container.Ports = []corev1.ContainerPort{{
+		Name:          gitWebhookReceiverPortName,
+		ContainerPort: reconcilermanager.GitWebhookReceiverPort,
+		Protocol:      corev1.ProtocolTCP,
+	}}
+	container.Env = []corev1.EnvVar{{
+		Name: reconcilermanager.GitWebhookSecret,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: reconcilermanager.GitWebhookReceiver},
+				Key:                  reconcilermanager.GitWebhookSecretKey,
+			},
+		},
+	}}
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/healthz",
+				Port:   intstr.FromString(gitWebhookReceiverPortName),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+		TimeoutSeconds:   1,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	container.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("20Mi"),
+		},
+	}
+	container.SecurityContext = &corev1.SecurityContext{
+		AllowPrivilegeEscalation: pointer.Bool(false),
+		ReadOnlyRootFilesystem:   pointer.Bool(true),
+	}
+}
+
+func (g *GitWebhookReceiver) createOrUpdate(ctx context.Context, obj client.Object, kind string, mutate controllerutil.MutateFn) error {
+	op, err := controllerruntime.CreateOrUpdate(ctx, g.client, obj, mutate)
+	if err != nil {
+		return fmt.Errorf("failed to upsert the git webhook receiver %s: %w", kind, err)
+	}
+	if op != controllerutil.OperationResultNone {
+		g.log.Info("Managed object upsert successful",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, kind,
+			logFieldOperation, op)
+	}
+	return nil
+}
+
+func mergeLabels(labels, labelMap map[string]string) map[string]string {
+	if labels == nil {
+		labels = make(map[string]string, len(labelMap))
+	}
+	for k, v := range labelMap {
+		labels[k] = v
+	}
+	return labels
+}
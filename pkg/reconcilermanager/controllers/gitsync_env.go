@@ -65,6 +65,9 @@ type options struct {
 	noSSLVerify bool
 	// caCertSecretRef specifies the name of a secret containing a CA certificate
 	caCertSecretRef string
+	// knownHosts specifies whether to verify the SSH host key of the Git server
+	// against the known hosts mounted at KnownHostsPath.
+	knownHosts bool
 }
 
 // gitSyncTokenAuthEnv returns environment variables for git-sync container for 'token' Auth.
@@ -134,11 +137,21 @@ func gitSyncEnvs(_ context.Context, opts options) []corev1.EnvVar {
 		Name:  "GIT_SYNC_REPO",
 		Value: opts.repo,
 	})
-	// disable known_hosts checking because it provides no benefit for our use case.
-	result = append(result, corev1.EnvVar{
-		Name:  "GIT_KNOWN_HOSTS",
-		Value: "false",
-	})
+	if opts.knownHosts {
+		result = append(result, corev1.EnvVar{
+			Name:  "GIT_KNOWN_HOSTS",
+			Value: "true",
+		}, corev1.EnvVar{
+			Name:  "GIT_SSH_KNOWN_HOSTS_FILE",
+			Value: fmt.Sprintf("%s/%s", KnownHostsPath, KnownHostsSecretKey),
+		})
+	} else {
+		// disable known_hosts checking unless a known hosts Secret is configured.
+		result = append(result, corev1.EnvVar{
+			Name:  "GIT_KNOWN_HOSTS",
+			Value: "false",
+		})
+	}
 	if opts.noSSLVerify {
 		result = append(result, corev1.EnvVar{
 			Name:  "GIT_SSL_NO_VERIFY",
@@ -204,6 +217,11 @@ func gitSyncEnvs(_ context.Context, opts options) []corev1.EnvVar {
 			Name:  "GIT_ASKPASS_URL",
 			Value: gceNodeAskpassURL,
 		})
+	case configsync.AuthExec:
+		result = append(result, corev1.EnvVar{
+			Name:  "GIT_ASKPASS_URL",
+			Value: execCredentialAskpassURL,
+		})
 	case configsync.AuthSSH:
 		result = append(result, corev1.EnvVar{
 			Name:  "GIT_SYNC_SSH",
@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hydrationSigningKeyResyncPeriod is how often the hydration signing key
+// Secret is recreated, if it was deleted.
+const hydrationSigningKeyResyncPeriod = 5 * time.Minute
+
+// hydrationSigningKeyLength is the length of the generated signing key in bytes.
+const hydrationSigningKeyLength = 32
+
+// HydrationSigningKey makes sure the Secret with the key that the
+// hydration-controller signs the hydrated configs with exists.
+// The reconcilers mount the key from the Secret to verify the hydrated configs.
+//
+// An existing key is never rotated, because the reconcilers would fail to
+// verify the configs hydrated before the rotation until the next commit.
+type HydrationSigningKey struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// NewHydrationSigningKey returns a new HydrationSigningKey.
+func NewHydrationSigningKey(c client.Client, log logr.Logger) *HydrationSigningKey {
+	return &HydrationSigningKey{
+		client: c,
+		log:    log,
+	}
+}
+
+// Start implements manager.Runnable.
+func (h *HydrationSigningKey) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := h.ensure(ctx); err != nil {
+			h.log.Error(err, "Failed to create the hydration signing key")
+		}
+	}, hydrationSigningKeyResyncPeriod)
+	return nil
+}
+
+func (h *HydrationSigningKey) ensure(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: reconcilermanager.HydrationSigningKeySecret}
+	err := h.client.Get(ctx, key, secret)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get the Secret %s", key)
+	}
+
+	signingKey := make([]byte, hydrationSigningKeyLength)
+	if _, err := rand.Read(signingKey); err != nil {
+		return errors.Wrap(err, "failed to generate the hydration signing key")
+	}
+	secret.Name = key.Name
+	secret.Namespace = key.Namespace
+	secret.Labels = map[string]string{
+		metadata.SystemLabel: "true",
+		metadata.ArchLabel:   "csmr",
+	}
+	secret.Type = corev1.SecretTypeOpaque
+	secret.Data = map[string][]byte{
+		reconcilermanager.HydrationSigningKeySecretKey: []byte(hex.EncodeToString(signingKey)),
+	}
+	if err := h.client.Create(ctx, secret); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to create the Secret %s", key)
+	}
+	h.log.Info("Created the hydration signing key", "secret", key.String())
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/webhook/configuration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigSyncStatusName is the name of the ConfigSyncStatus maintained by
+	// the reconciler-manager.
+	ConfigSyncStatusName = "config-sync"
+
+	// installationStatusCheckPeriod is how often the reconciler-manager
+	// refreshes the ConfigSyncStatus.
+	installationStatusCheckPeriod = 30 * time.Second
+)
+
+// installationComponents are the Deployments of the components of the Config
+// Sync installation. The optional components do not make the installation
+// unready when they are not installed.
+var installationComponents = []struct {
+	name      string
+	namespace string
+	optional  bool
+}{
+	{name: reconcilermanager.ManagerName, namespace: configmanagement.ControllerNamespace},
+	{name: configuration.ShortName, namespace: configmanagement.ControllerNamespace, optional: true},
+	{name: metrics.OtelCollectorName, namespace: metrics.MonitoringNamespace, optional: true},
+	{name: configmanagement.RGControllerName, namespace: configmanagement.RGControllerNamespace},
+}
+
+// InstallationStatusController periodically aggregates the health of the
+// components of the Config Sync installation, and of all the RootSyncs and
+// RepoSyncs, into the ConfigSyncStatus singleton, which it creates if needed.
+type InstallationStatusController struct {
+	client client.Client
+	log    logr.Logger
+	now    func() time.Time
+}
+
+// NewInstallationStatusController returns a new InstallationStatusController.
+func NewInstallationStatusController(c client.Client, log logr.Logger) *InstallationStatusController {
+	return &InstallationStatusController{
+		client: c,
+		log:    log,
+		now:    time.Now,
+	}
+}
+
+// Start implements manager.Runnable.
+func (i *InstallationStatusController) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := i.refresh(ctx); err != nil {
+			i.log.Error(err, "Failed to update the installation status")
+		}
+	}, installationStatusCheckPeriod)
+	return nil
+}
+
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=configsyncstatuses,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=configsyncstatuses/status,verbs=get;update;patch
+
+// refresh computes the installation status, and updates the ConfigSyncStatus
+// if it changed.
+func (i *InstallationStatusController) refresh(ctx context.Context) error {
+	newStatus, err := i.installationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	css := &v1beta1.ConfigSyncStatus{}
+	if err := i.client.Get(ctx, client.ObjectKey{Name: ConfigSyncStatusName}, css); err != nil {
+		if meta.IsNoMatchError(err) {
+			// The ConfigSyncStatus CRD is not installed yet.
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get the %s %s", configsync.ConfigSyncStatusKind, ConfigSyncStatusName)
+		}
+		css.Name = ConfigSyncStatusName
+		if err := i.client.Create(ctx, css); err != nil {
+			return errors.Wrapf(err, "failed to create the %s %s", configsync.ConfigSyncStatusKind, ConfigSyncStatusName)
+		}
+	}
+
+	// The update time is only bumped when the status changes, so that the
+	// ConfigSyncStatus is not updated on every check.
+	newStatus.LastUpdateTime = css.Status.LastUpdateTime
+	if equality.Semantic.DeepEqual(css.Status, newStatus) {
+		return nil
+	}
+	newStatus.LastUpdateTime = metav1.NewTime(i.now())
+	if css.Status.Ready != newStatus.Ready {
+		i.log.Info("The readiness of the installation changed", "ready", newStatus.Ready)
+	}
+	css.Status = newStatus
+	if err := i.client.Status().Update(ctx, css); err != nil {
+		return errors.Wrapf(err, "failed to update the status of the %s %s", configsync.ConfigSyncStatusKind, ConfigSyncStatusName)
+	}
+	return nil
+}
+
+// installationStatus returns the health of the components and of the RootSyncs
+// and RepoSyncs.
+func (i *InstallationStatusController) installationStatus(ctx context.Context) (v1beta1.ConfigSyncStatusStatus, error) {
+	result := v1beta1.ConfigSyncStatusStatus{Ready: true}
+	for _, component := range installationComponents {
+		cs := v1beta1.ComponentStatus{Name: component.name, Namespace: component.namespace}
+		d := &appsv1.Deployment{}
+		err := i.client.Get(ctx, client.ObjectKey{Namespace: component.namespace, Name: component.name}, d)
+		switch {
+		case apierrors.IsNotFound(err):
+			cs.State = v1beta1.ComponentNotInstalled
+			if !component.optional {
+				result.Ready = false
+			}
+		case err != nil:
+			return v1beta1.ConfigSyncStatusStatus{}, errors.Wrapf(err, "failed to get the Deployment %s/%s", component.namespace, component.name)
+		case deploymentAvailable(d):
+			cs.State = v1beta1.ComponentAvailable
+		default:
+			cs.State = v1beta1.ComponentUnavailable
+			cs.Message = deploymentUnavailableMessage(d)
+			result.Ready = false
+		}
+		result.Components = append(result.Components, cs)
+	}
+
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := i.client.List(ctx, rootSyncs); err != nil {
+		return v1beta1.ConfigSyncStatusStatus{}, errors.Wrap(err, "failed to list the RootSyncs")
+	}
+	for _, rs := range rootSyncs.Items {
+		healthy := !rootsync.IsStalled(&rs) && !hasSyncErrors(rs.Status.Sync)
+		addSyncHealth(&result.Syncs, configsync.RootSyncKind, rs.Namespace, rs.Name, healthy)
+	}
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := i.client.List(ctx, repoSyncs); err != nil {
+		return v1beta1.ConfigSyncStatusStatus{}, errors.Wrap(err, "failed to list the RepoSyncs")
+	}
+	for _, rs := range repoSyncs.Items {
+		healthy := !reposync.IsStalled(&rs) && !hasSyncErrors(rs.Status.Sync)
+		addSyncHealth(&result.Syncs, configsync.RepoSyncKind, rs.Namespace, rs.Name, healthy)
+	}
+	sort.Strings(result.Syncs.Unhealthy)
+	if len(result.Syncs.Unhealthy) > 0 {
+		result.Ready = false
+	}
+	return result, nil
+}
+
+// addSyncHealth counts the health of a RootSync or RepoSync in the summary.
+func addSyncHealth(summary *v1beta1.SyncsSummary, kind, namespace, name string, healthy bool) {
+	summary.Total++
+	if healthy {
+		summary.Healthy++
+		return
+	}
+	summary.Unhealthy = append(summary.Unhealthy, fmt.Sprintf("%s/%s/%s", kind, namespace, name))
+}
+
+// deploymentUnavailableMessage describes why the Deployment is not available.
+func deploymentUnavailableMessage(d *appsv1.Deployment) string {
+	if d.Status.ObservedGeneration < d.Generation {
+		return fmt.Sprintf("generation %d is not observed yet", d.Generation)
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return fmt.Sprintf("%d/%d replicas updated, %d/%d replicas available",
+		d.Status.UpdatedReplicas, replicas, d.Status.AvailableReplicas, replicas)
+}
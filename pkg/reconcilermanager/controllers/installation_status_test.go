@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func componentDeployment(namespace, name string, availableReplicas int32) *appsv1.Deployment {
+	d := fake.DeploymentObject(core.Name(name), core.Namespace(namespace), core.Generation(1))
+	d.Status.ObservedGeneration = 1
+	d.Status.UpdatedReplicas = 1
+	d.Status.AvailableReplicas = availableReplicas
+	return d
+}
+
+func TestInstallationStatusController(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	healthyComponents := []client.Object{
+		componentDeployment(configmanagement.ControllerNamespace, reconcilermanager.ManagerName, 1),
+		componentDeployment(metrics.MonitoringNamespace, metrics.OtelCollectorName, 1),
+		componentDeployment(configmanagement.RGControllerNamespace, configmanagement.RGControllerName, 1),
+	}
+	unhealthyRepoSync := fake.RepoSyncObjectV1Beta1("bookstore", configsync.RepoSyncName)
+	unhealthyRepoSync.Status.Sync.ErrorSummary = &v1beta1.ErrorSummary{TotalCount: 1}
+
+	testCases := []struct {
+		name          string
+		objs          []client.Object
+		wantReady     bool
+		wantStates    map[string]v1beta1.ComponentState
+		wantUnhealthy []string
+	}{
+		{
+			name:      "healthy installation without the optional webhook",
+			objs:      append([]client.Object{fake.RootSyncObjectV1Beta1(configsync.RootSyncName)}, healthyComponents...),
+			wantReady: true,
+			wantStates: map[string]v1beta1.ComponentState{
+				reconcilermanager.ManagerName:     v1beta1.ComponentAvailable,
+				"admission-webhook":               v1beta1.ComponentNotInstalled,
+				metrics.OtelCollectorName:         v1beta1.ComponentAvailable,
+				configmanagement.RGControllerName: v1beta1.ComponentAvailable,
+			},
+		},
+		{
+			name: "unavailable and missing components",
+			objs: []client.Object{
+				componentDeployment(configmanagement.ControllerNamespace, reconcilermanager.ManagerName, 0),
+			},
+			wantStates: map[string]v1beta1.ComponentState{
+				reconcilermanager.ManagerName:     v1beta1.ComponentUnavailable,
+				"admission-webhook":               v1beta1.ComponentNotInstalled,
+				metrics.OtelCollectorName:         v1beta1.ComponentNotInstalled,
+				configmanagement.RGControllerName: v1beta1.ComponentNotInstalled,
+			},
+		},
+		{
+			name: "unhealthy RepoSync",
+			objs: append([]client.Object{fake.RootSyncObjectV1Beta1(configsync.RootSyncName), unhealthyRepoSync}, healthyComponents...),
+			wantStates: map[string]v1beta1.ComponentState{
+				reconcilermanager.ManagerName:     v1beta1.ComponentAvailable,
+				"admission-webhook":               v1beta1.ComponentNotInstalled,
+				metrics.OtelCollectorName:         v1beta1.ComponentAvailable,
+				configmanagement.RGControllerName: v1beta1.ComponentAvailable,
+			},
+			wantUnhealthy: []string{"RepoSync/bookstore/repo-sync"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := syncerFake.NewClient(t, core.Scheme, tc.objs...)
+			controller := NewInstallationStatusController(fakeClient, logr.Discard())
+			controller.now = func() time.Time { return now }
+			require.NoError(t, controller.refresh(ctx))
+
+			got := &v1beta1.ConfigSyncStatus{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: ConfigSyncStatusName}, got))
+			require.Equal(t, tc.wantReady, got.Status.Ready)
+			gotStates := make(map[string]v1beta1.ComponentState)
+			for _, c := range got.Status.Components {
+				gotStates[c.Name] = c.State
+			}
+			require.Equal(t, tc.wantStates, gotStates)
+			require.Equal(t, tc.wantUnhealthy, got.Status.Syncs.Unhealthy)
+			require.Equal(t, now, got.Status.LastUpdateTime.Time.UTC())
+
+			// The status is not updated again if nothing changed.
+			controller.now = func() time.Time { return now.Add(time.Minute) }
+			require.NoError(t, controller.refresh(ctx))
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: ConfigSyncStatusName}, got))
+			require.Equal(t, now, got.Status.LastUpdateTime.Time.UTC())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
+)
+
+const (
+	// hostKeyScanTimeout is the timeout for scanning the host key of a Git
+	// server, from dialing to receiving the host key.
+	hostKeyScanTimeout = 10 * time.Second
+	// defaultSSHPort is the port used when the Git repo URL does not specify one.
+	defaultSSHPort = "22"
+)
+
+// errHostKeyCaptured aborts the SSH handshake once the host key is received.
+var errHostKeyCaptured = errors.New("host key captured")
+
+// useKnownHosts returns true if git-sync should verify the host key of the
+// Git server against the known hosts secret.
+func useKnownHosts(auth configsync.AuthType, knownHostsSecretName string) bool {
+	return auth == configsync.AuthSSH && knownHostsSecretName != ""
+}
+
+// ensureKnownHostsSecret verifies that the known hosts secret referenced by
+// the git spec exists in the namespace. With the PinOnFirstUse policy, a
+// missing secret is created from the host key presented by the Git server.
+func (r *reconcilerBase) ensureKnownHostsSecret(ctx context.Context, namespace string, git *v1beta1.Git) error {
+	secretName := v1beta1.GetSecretName(git.KnownHostsSecretRef)
+	if !useKnownHosts(git.Auth, secretName) {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	secretRef := types.NamespacedName{Namespace: namespace, Name: secretName}
+	err := r.client.Get(ctx, secretRef, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		if git.KnownHostsPolicy != v1beta1.KnownHostsPolicyPinOnFirstUse {
+			return errors.Errorf("Secret %s not found, create one with the SSH known hosts of the Git server in a key named %q", secretName, KnownHostsSecretKey)
+		}
+		knownHosts, err := scanKnownHosts(ctx, git.Repo)
+		if err != nil {
+			return errors.Wrapf(err, "failed to pin the host key of %s", git.Repo)
+		}
+		secret.Name = secretRef.Name
+		secret.Namespace = secretRef.Namespace
+		secret.Annotations = map[string]string{
+			metadata.KnownHostsPinnedAtAnnotationKey: time.Now().UTC().Format(time.RFC3339),
+		}
+		secret.Data = map[string][]byte{KnownHostsSecretKey: knownHosts}
+		if err := r.client.Create(ctx, secret); err != nil {
+			return errors.Wrapf(err, "Secret %s create failed", secretName)
+		}
+		r.log.Info("Pinned the host key of the Git server",
+			logFieldObject, secretRef.String(),
+			logFieldKind, "Secret",
+			"repo", git.Repo)
+		return nil
+	case err != nil:
+		return errors.Wrapf(err, "Secret %s get failed", secretName)
+	}
+	if _, found := secret.Data[KnownHostsSecretKey]; !found {
+		return errors.Errorf("knownHostsSecretRef was set, but %s key is not present in %s Secret", KnownHostsSecretKey, secretName)
+	}
+	return nil
+}
+
+// sshHostAndPort returns the host and port of an SSH Git repo URL, in either
+// the ssh://[user@]host[:port]/path or the scp-like [user@]host:path form.
+func sshHostAndPort(repo string) (string, string, error) {
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "invalid repo URL %q", repo)
+		}
+		if u.Scheme != "ssh" {
+			return "", "", errors.Errorf("repo URL %q is not an SSH URL", repo)
+		}
+		port := u.Port()
+		if port == "" {
+			port = defaultSSHPort
+		}
+		return u.Hostname(), port, nil
+	}
+	hostPath := repo[strings.LastIndex(repo, "@")+1:]
+	host, _, found := strings.Cut(hostPath, ":")
+	if !found || host == "" {
+		return "", "", errors.Errorf("repo URL %q is not an SSH URL", repo)
+	}
+	return host, defaultSSHPort, nil
+}
+
+// scanKnownHosts connects to the Git server of the repo and returns a
+// known_hosts line with the host key it presents.
+//
+// This is the only egress of the reconciler-manager: it dials the host and
+// port of the repo URL of the RSync, and nothing else, only when the known
+// hosts secret of a RSync with the PinOnFirstUse policy is missing. The
+// handshake is aborted as soon as the host key is received, before any
+// authentication.
+func scanKnownHosts(ctx context.Context, repo string) ([]byte, error) {
+	host, port, err := sshHostAndPort(repo)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, hostKeyScanTimeout)
+	defer cancel()
+	addr := net.JoinHostPort(host, port)
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	// The handshake does not honor the context, so bound it with the
+	// deadline of the connection.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "git",
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errHostKeyCaptured
+		},
+		Timeout: hostKeyScanTimeout,
+	}
+	if _, _, _, err := ssh.NewClientConn(conn, addr, config); err != nil && hostKey == nil {
+		return nil, errors.Wrap(err, "failed to receive the SSH host key")
+	}
+	return []byte(knownhosts.Line([]string{addr}, hostKey) + "\n"), nil
+}
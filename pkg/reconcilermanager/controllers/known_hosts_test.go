@@ -0,0 +1,173 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+)
+
+func TestSSHHostAndPort(t *testing.T) {
+	testCases := []struct {
+		repo     string
+		wantHost string
+		wantPort string
+		wantErr  bool
+	}{
+		{repo: "git@github.com:org/repo.git", wantHost: "github.com", wantPort: "22"},
+		{repo: "ssh://git@git.example.com:2222/org/repo.git", wantHost: "git.example.com", wantPort: "2222"},
+		{repo: "ssh://git.example.com/org/repo.git", wantHost: "git.example.com", wantPort: "22"},
+		{repo: "https://github.com/org/repo.git", wantErr: true},
+		{repo: "repo.git", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.repo, func(t *testing.T) {
+			host, port, err := sshHostAndPort(tc.repo)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantHost, host)
+			require.Equal(t, tc.wantPort, port)
+		})
+	}
+}
+
+// newFakeHostKey returns a new ed25519 host key.
+func newFakeHostKey(t *testing.T) ssh.Signer {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+	return signer
+}
+
+// serveFakeSSH accepts a single connection and runs the server side of the
+// SSH handshake with the host key.
+func serveFakeSSH(l net.Listener, hostKey ssh.Signer) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(hostKey)
+	// The client aborts the handshake once it receives the host key.
+	_, _, _, _ = ssh.NewServerConn(conn, config)
+}
+
+func TestScanKnownHosts(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+	hostKey := newFakeHostKey(t)
+	go serveFakeSSH(l, hostKey)
+
+	port := l.Addr().(*net.TCPAddr).Port
+	knownHosts, err := scanKnownHosts(context.Background(), fmt.Sprintf("ssh://git@127.0.0.1:%d/org/repo.git", port))
+	require.NoError(t, err)
+	want := fmt.Sprintf("[127.0.0.1]:%d ssh-ed25519 %s\n", port, base64.StdEncoding.EncodeToString(hostKey.PublicKey().Marshal()))
+	require.Equal(t, want, string(knownHosts))
+}
+
+func TestScanKnownHostsTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+	// The server accepts the connection, but never sends its version.
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer func() {
+				_ = conn.Close()
+			}()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = scanKnownHosts(ctx, fmt.Sprintf("ssh://git@127.0.0.1:%d/org/repo.git", l.Addr().(*net.TCPAddr).Port))
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestEnsureKnownHostsSecret(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+	go serveFakeSSH(l, newFakeHostKey(t))
+	repo := fmt.Sprintf("ssh://git@127.0.0.1:%d/org/repo.git", l.Addr().(*net.TCPAddr).Port)
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme)
+	r := &reconcilerBase{client: fakeClient, log: logr.Discard()}
+	git := &v1beta1.Git{
+		Repo:                repo,
+		Auth:                configsync.AuthSSH,
+		KnownHostsSecretRef: &v1beta1.SecretReference{Name: "known-hosts"},
+	}
+
+	err = r.ensureKnownHostsSecret(context.Background(), configsync.ControllerNamespace, git)
+	require.Error(t, err, "the Manual policy requires the Secret")
+
+	git.KnownHostsPolicy = v1beta1.KnownHostsPolicyPinOnFirstUse
+	require.NoError(t, r.ensureKnownHostsSecret(context.Background(), configsync.ControllerNamespace, git))
+
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Namespace: configsync.ControllerNamespace, Name: "known-hosts"}, secret))
+	require.True(t, strings.HasPrefix(string(secret.Data[KnownHostsSecretKey]), "[127.0.0.1]:"))
+	require.NotEmpty(t, secret.Annotations[metadata.KnownHostsPinnedAtAnnotationKey])
+
+	// An existing Secret is never re-pinned.
+	require.NoError(t, r.ensureKnownHostsSecret(context.Background(), configsync.ControllerNamespace, git))
+}
+
+func TestGitSyncEnvsKnownHosts(t *testing.T) {
+	envs := gitSyncEnvs(context.Background(), options{repo: "git@github.com:org/repo.git", secretType: configsync.AuthSSH, knownHosts: true})
+	values := map[string]string{}
+	for _, env := range envs {
+		values[env.Name] = env.Value
+	}
+	require.Equal(t, "true", values["GIT_KNOWN_HOSTS"])
+	require.Equal(t, KnownHostsPath+"/"+KnownHostsSecretKey, values["GIT_SSH_KNOWN_HOSTS_FILE"])
+}
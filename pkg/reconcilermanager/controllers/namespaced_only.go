@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sort"
+	"strings"
+
+	"kpt.dev/configsync/pkg/api/configsync"
+)
+
+// ParseTenantNamespaces parses the comma-separated list of tenant namespaces,
+// whose RepoSyncs are reconciled when the reconciler-manager runs with only
+// namespaced permissions. Empty entries and duplicates are dropped.
+// Returns nil if the list is empty, which means the reconciler-manager runs
+// with cluster-admin permissions.
+func ParseTenantNamespaces(value string) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, ns := range strings.Split(value, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// NamespacedOnlyCacheNamespaces returns the namespaces watched by the
+// reconciler-manager when it runs with only namespaced permissions: the tenant
+// namespaces, and the namespace of the reconcilers.
+func NamespacedOnlyCacheNamespaces(tenantNamespaces []string) []string {
+	namespaces := []string{configsync.ControllerNamespace}
+	for _, ns := range tenantNamespaces {
+		if ns != configsync.ControllerNamespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
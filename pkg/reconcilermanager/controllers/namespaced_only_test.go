@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestParseTenantNamespaces(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "single namespace",
+			value: "team-a",
+			want:  []string{"team-a"},
+		},
+		{
+			name:  "spaces, empty entries and duplicates",
+			value: " team-b, team-a,,team-b ",
+			want:  []string{"team-a", "team-b"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, ParseTenantNamespaces(tc.value))
+		})
+	}
+}
+
+func TestNamespacedOnlyCacheNamespaces(t *testing.T) {
+	require.Equal(t,
+		[]string{configsync.ControllerNamespace, "team-a", "team-b"},
+		NamespacedOnlyCacheNamespaces([]string{"team-a", configsync.ControllerNamespace, "team-b"}))
+}
+
+func TestRepoSyncNamespacedOnlyRoleBinding(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	rs := repoSync(reposyncNs, reposyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthSSH), reposyncSecretRef(reposyncSSHKey))
+	fakeClient, _, testReconciler := setupNSReconciler(t, rs, secretObj(t, reposyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs.Namespace)))
+	testReconciler.SetNamespacedOnly()
+
+	ctx := context.Background()
+	_, err := testReconciler.Reconcile(ctx, namespacedName(rs.Name, rs.Namespace))
+	require.NoError(t, err)
+
+	rb := &rbacv1.RoleBinding{}
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Namespace: reposyncNs, Name: RepoSyncPermissionsName()}, rb))
+	require.Equal(t, "Role", rb.RoleRef.Kind)
+	require.Equal(t, RepoSyncPermissionsName(), rb.RoleRef.Name)
+}
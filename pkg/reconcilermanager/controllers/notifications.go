@@ -0,0 +1,302 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NotificationURLKey is the key of the notification route ConfigMaps
+	// holding the URL the payloads are POSTed to, e.g. the incoming webhook of
+	// the channel of the team.
+	NotificationURLKey = "url"
+	// NotificationTemplateKey is the key of the notification route ConfigMaps
+	// holding the Go template of the payloads.
+	NotificationTemplateKey = "template"
+	// NotificationContentTypeKey is the key of the notification route
+	// ConfigMaps holding the content type of the payloads. Defaults to
+	// application/json.
+	NotificationContentTypeKey = "contentType"
+	// NotificationSyncsKey is the key of the notification route ConfigMaps
+	// holding the comma-separated `<kind>/<namespace>/<name>` patterns of the
+	// RootSyncs and RepoSyncs to notify about. Patterns use the path.Match
+	// syntax, e.g. `RepoSync/team-*/*`. Empty matches all of them.
+	NotificationSyncsKey = "syncs"
+	// NotificationNamespaceSelectorKey is the key of the notification route
+	// ConfigMaps holding the label selector of the namespaces of the RootSyncs
+	// and RepoSyncs to notify about, e.g. `team=books`. Empty matches all the
+	// namespaces.
+	NotificationNamespaceSelectorKey = "namespaceSelector"
+	// NotificationErrorCodesKey is the key of the notification route
+	// ConfigMaps holding the comma-separated error codes to notify about,
+	// e.g. `1060,2009`. Empty matches all the failures, including the stalled
+	// RootSyncs and RepoSyncs, which have no error code.
+	NotificationErrorCodesKey = "errorCodes"
+
+	// notificationTimeout is the timeout of the delivery of a notification.
+	notificationTimeout = 10 * time.Second
+)
+
+// Notification is the data of the payload templates.
+type Notification struct {
+	SyncStatusSummary
+	// Route is the name of the notification route ConfigMap.
+	Route string
+}
+
+// notificationRoute is a routing rule and a payload template, parsed from a
+// notification route ConfigMap.
+type notificationRoute struct {
+	name              string
+	url               string
+	contentType       string
+	template          *template.Template
+	syncs             []string
+	namespaceSelector labels.Selector
+	errorCodes        map[string]bool
+}
+
+// notificationFuncs are the functions available to the payload templates.
+var notificationFuncs = template.FuncMap{
+	// json encodes a value, e.g. to quote a string in a JSON payload.
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+// parseNotificationRoute returns the route of the ConfigMap.
+func parseNotificationRoute(cm *corev1.ConfigMap) (*notificationRoute, error) {
+	route := &notificationRoute{
+		name:        cm.Name,
+		url:         cm.Data[NotificationURLKey],
+		contentType: cm.Data[NotificationContentTypeKey],
+	}
+	if route.url == "" {
+		return nil, errors.Errorf("missing %q", NotificationURLKey)
+	}
+	if route.contentType == "" {
+		route.contentType = "application/json"
+	}
+	tmpl, err := template.New(cm.Name).Funcs(notificationFuncs).Option("missingkey=error").Parse(cm.Data[NotificationTemplateKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %q", NotificationTemplateKey)
+	}
+	route.template = tmpl
+	for _, pattern := range splitList(cm.Data[NotificationSyncsKey]) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q in %q", pattern, NotificationSyncsKey)
+		}
+		route.syncs = append(route.syncs, pattern)
+	}
+	route.namespaceSelector = labels.Everything()
+	if value := cm.Data[NotificationNamespaceSelectorKey]; value != "" {
+		route.namespaceSelector, err = labels.Parse(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %q", NotificationNamespaceSelectorKey)
+		}
+	}
+	for _, code := range splitList(cm.Data[NotificationErrorCodesKey]) {
+		if route.errorCodes == nil {
+			route.errorCodes = make(map[string]bool)
+		}
+		route.errorCodes[strings.TrimPrefix(code, "KNV")] = true
+	}
+	return route, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matches returns true if the failure of the RootSync or RepoSync, whose
+// namespace has the labels, is routed to the route.
+func (r *notificationRoute) matches(summary SyncStatusSummary, namespaceLabels labels.Set) bool {
+	if len(r.syncs) > 0 {
+		id := fmt.Sprintf("%s/%s/%s", summary.Kind, summary.Namespace, summary.Name)
+		matched := false
+		for _, pattern := range r.syncs {
+			if ok, _ := path.Match(pattern, id); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if !r.namespaceSelector.Matches(namespaceLabels) {
+		return false
+	}
+	if len(r.errorCodes) > 0 {
+		for _, code := range summary.ErrorCodes {
+			if r.errorCodes[code] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// NotificationPublisher is a StatusPublisher which notifies the failures of the
+// RootSyncs and RepoSyncs, i.e. when they report errors or are stalled.
+//
+// The notifications are routed by the ConfigMaps labeled with
+// `configsync.gke.io/notification-route: "true"` in the Config Sync
+// namespace, so that platform teams can send specific failures to the channel
+// of the owning team. Every route matching a failure POSTs the payload
+// rendered from its Go template to its URL.
+//
+// A failure is notified once per route, until the state, the source commit or
+// the error codes of the RootSync or RepoSync change.
+type NotificationPublisher struct {
+	client     client.Client
+	httpClient *http.Client
+	log        logr.Logger
+
+	// notified is the fingerprint of the last failure notified, by route and
+	// RootSync or RepoSync.
+	notified map[string]string
+}
+
+var _ StatusPublisher = &NotificationPublisher{}
+
+// NewNotificationPublisher returns a new NotificationPublisher.
+func NewNotificationPublisher(c client.Client, log logr.Logger) *NotificationPublisher {
+	return &NotificationPublisher{
+		client:     c,
+		httpClient: &http.Client{Timeout: notificationTimeout},
+		log:        log,
+		notified:   make(map[string]string),
+	}
+}
+
+// Publish implements StatusPublisher.
+func (n *NotificationPublisher) Publish(ctx context.Context, summaries []SyncStatusSummary) error {
+	cms := &corev1.ConfigMapList{}
+	if err := n.client.List(ctx, cms, client.InNamespace(configsync.ControllerNamespace),
+		client.MatchingLabels{metadata.NotificationRouteLabel: "true"}); err != nil {
+		return status.APIServerError(err, "failed to list the notification routes")
+	}
+	var routes []*notificationRoute
+	for i := range cms.Items {
+		route, err := parseNotificationRoute(&cms.Items[i])
+		if err != nil {
+			// An invalid route does not block the other routes.
+			n.log.Error(err, "Skipping invalid notification route",
+				logFieldObject, client.ObjectKeyFromObject(&cms.Items[i]).String())
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	namespaceLabels := make(map[string]labels.Set)
+	var errs []error
+	for _, summary := range summaries {
+		syncKey := fmt.Sprintf("%s/%s/%s", summary.Kind, summary.Namespace, summary.Name)
+		failed := summary.State == SyncStateError || summary.State == SyncStateStalled
+		for _, route := range routes {
+			key := route.name + "/" + syncKey
+			if !failed {
+				// Notify again when the RootSync or RepoSync fails next.
+				delete(n.notified, key)
+				continue
+			}
+			fingerprint := fmt.Sprintf("%s/%s/%s", summary.State, summary.SourceCommit, strings.Join(summary.ErrorCodes, ","))
+			if n.notified[key] == fingerprint {
+				continue
+			}
+			nsLabels, found := namespaceLabels[summary.Namespace]
+			if !found {
+				var err error
+				nsLabels, err = n.getNamespaceLabels(ctx, summary.Namespace)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				namespaceLabels[summary.Namespace] = nsLabels
+			}
+			if !route.matches(summary, nsLabels) {
+				continue
+			}
+			if err := n.notify(ctx, route, Notification{SyncStatusSummary: summary, Route: route.name}); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to notify %s to route %s", syncKey, route.name))
+				continue
+			}
+			n.log.Info("Notified the sync failure", logFieldObject, syncKey, "route", route.name, "state", summary.State)
+			n.notified[key] = fingerprint
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (n *NotificationPublisher) getNamespaceLabels(ctx context.Context, name string) (labels.Set, error) {
+	ns := &corev1.Namespace{}
+	if err := n.client.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+		return nil, status.APIServerErrorf(err, "failed to get the Namespace %s", name)
+	}
+	return ns.Labels, nil
+}
+
+// notify renders the payload of the route, and POSTs it to its URL.
+func (n *NotificationPublisher) notify(ctx context.Context, route *notificationRoute, data Notification) error {
+	payload := &bytes.Buffer{}
+	if err := route.template.Execute(payload, data); err != nil {
+		return errors.Wrap(err, "failed to render the payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, route.url, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", route.contentType)
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
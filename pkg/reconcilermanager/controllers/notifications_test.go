@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestNotificationPublisher(t *testing.T) {
+	var mux sync.Mutex
+	var payloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mux.Lock()
+		payloads = append(payloads, r.URL.Path+" "+string(body))
+		mux.Unlock()
+	}))
+	defer server.Close()
+
+	books := fake.ConfigMapObject(core.Name("books"), core.Namespace(configsync.ControllerNamespace),
+		core.Label(metadata.NotificationRouteLabel, "true"))
+	books.Data = map[string]string{
+		NotificationURLKey:               server.URL + "/books",
+		NotificationNamespaceSelectorKey: "team=books",
+		NotificationTemplateKey:          `{"text":{{ json (printf "%s %s/%s is %s: %s" .Kind .Namespace .Name .State (join .ErrorCodes ",")) }}}`,
+	}
+	conflicts := fake.ConfigMapObject(core.Name("conflicts"), core.Namespace(configsync.ControllerNamespace),
+		core.Label(metadata.NotificationRouteLabel, "true"))
+	conflicts.Data = map[string]string{
+		NotificationURLKey:        server.URL + "/conflicts",
+		NotificationSyncsKey:      "RepoSync/*/*",
+		NotificationErrorCodesKey: "KNV1060",
+		NotificationTemplateKey:   `{{ .Route }}: {{ .Name }}`,
+	}
+	invalid := fake.ConfigMapObject(core.Name("invalid"), core.Namespace(configsync.ControllerNamespace),
+		core.Label(metadata.NotificationRouteLabel, "true"))
+	invalid.Data = map[string]string{NotificationTemplateKey: "{{ .Name }}"}
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme, books, conflicts, invalid,
+		fake.NamespaceObject("bookstore", core.Label("team", "books")),
+		fake.NamespaceObject("shipping", core.Label("team", "shipping")))
+	publisher := NewNotificationPublisher(fakeClient, logr.Discard())
+	ctx := context.Background()
+
+	failing := []SyncStatusSummary{
+		{Kind: configsync.RepoSyncKind, Namespace: "bookstore", Name: configsync.RepoSyncName, State: SyncStateError, SourceCommit: "abc", ErrorCount: 1, ErrorCodes: []string{"1060"}},
+		{Kind: configsync.RepoSyncKind, Namespace: "shipping", Name: configsync.RepoSyncName, State: SyncStateError, SourceCommit: "abc", ErrorCount: 1, ErrorCodes: []string{"2009"}},
+		{Kind: configsync.RepoSyncKind, Namespace: "shipping", Name: "synced", State: SyncStateSynced, SourceCommit: "abc"},
+	}
+	require.NoError(t, publisher.Publish(ctx, failing))
+	require.ElementsMatch(t, []string{
+		`/books {"text":"RepoSync bookstore/repo-sync is Error: 1060"}`,
+		`/conflicts conflicts: repo-sync`,
+	}, payloads)
+
+	// The same failures are not notified again.
+	payloads = nil
+	require.NoError(t, publisher.Publish(ctx, failing))
+	require.Empty(t, payloads)
+
+	// A recovered RepoSync is notified again when it fails next.
+	recovered := []SyncStatusSummary{
+		{Kind: configsync.RepoSyncKind, Namespace: "bookstore", Name: configsync.RepoSyncName, State: SyncStateSynced, SourceCommit: "def"},
+	}
+	require.NoError(t, publisher.Publish(ctx, recovered))
+	require.NoError(t, publisher.Publish(ctx, failing[:1]))
+	require.Len(t, payloads, 2)
+}
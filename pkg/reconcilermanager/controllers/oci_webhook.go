@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ociSyncWebhookPortName is the name of the registry webhook receiver port
+	// of the oci-sync container.
+	ociSyncWebhookPortName = "oci-webhook"
+)
+
+// OciWebhookServiceName returns the name of the Service that exposes the
+// registry webhook receiver of a reconciler.
+// e.g. root-reconciler-oci-webhook
+func OciWebhookServiceName(reconcilerName string) string {
+	return ReconcilerResourceName(reconcilerName, ociSyncWebhookPortName)
+}
+
+func ociSyncWebhookContainerPort() corev1.ContainerPort {
+	return corev1.ContainerPort{
+		Name:          ociSyncWebhookPortName,
+		ContainerPort: reconcilermanager.OciSyncWebhookPort,
+		Protocol:      corev1.ProtocolTCP,
+	}
+}
+
+// reconcileOciWebhookService creates or updates the Service that routes
+// registry notifications to the oci-sync container of the reconciler, if the
+// webhook is enabled. Otherwise, it deletes the Service, if it exists.
+func (r *reconcilerBase) reconcileOciWebhookService(ctx context.Context, reconcilerRef types.NamespacedName, enabled bool, labelMap map[string]string, refs ...metav1.OwnerReference) (types.NamespacedName, error) {
+	svcRef := types.NamespacedName{
+		Namespace: reconcilerRef.Namespace,
+		Name:      OciWebhookServiceName(reconcilerRef.Name),
+	}
+	if !enabled {
+		return svcRef, r.deleteOciWebhookService(ctx, reconcilerRef)
+	}
+
+	svc := &corev1.Service{}
+	svc.Name = svcRef.Name
+	svc.Namespace = svcRef.Namespace
+	r.addLabels(svc, labelMap)
+
+	op, err := controllerruntime.CreateOrUpdate(ctx, r.client, svc, func() error {
+		// Do not set ownerRefs for the RepoSync Service, since Reconciler
+		// Manager performs garbage collection for RepoSync controller resources.
+		if len(refs) > 0 {
+			svc.OwnerReferences = refs
+		}
+		svc.Spec.Selector = map[string]string{
+			metadata.DeploymentNameLabel: reconcilerRef.Name,
+		}
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       ociSyncWebhookPortName,
+			Port:       reconcilermanager.OciSyncWebhookPort,
+			TargetPort: intstr.FromString(ociSyncWebhookPortName),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+		return nil
+	})
+	if err != nil {
+		return svcRef, err
+	}
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, svcRef.String(),
+			logFieldKind, "Service",
+			logFieldOperation, op)
+	}
+	return svcRef, nil
+}
+
+// deleteOciWebhookService deletes the webhook receiver Service of the
+// reconciler. Unlike cleanup, a missing Service is not logged, because most
+// reconcilers never have one.
+func (r *reconcilerBase) deleteOciWebhookService(ctx context.Context, reconcilerRef types.NamespacedName) error {
+	svc := &corev1.Service{}
+	svc.Name = OciWebhookServiceName(reconcilerRef.Name)
+	svc.Namespace = reconcilerRef.Namespace
+	if err := r.client.Delete(ctx, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	r.log.Info("Managed object delete successful",
+		logFieldObject, client.ObjectKeyFromObject(svc).String(),
+		logFieldKind, "Service")
+	return nil
+}
@@ -0,0 +1,331 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"kpt.dev/configsync/pkg/status"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// OtelTenantLoggerName defines the logger name for OtelTenantReconciler.
+const OtelTenantLoggerName = "OtelTenant"
+
+// otelTenantRequest is the single request reconciled by the
+// OtelTenantReconciler, which manages the collectors of all the tenants at
+// once.
+var otelTenantRequest = reconcile.Request{NamespacedName: types.NamespacedName{
+	Namespace: metrics.MonitoringNamespace,
+	Name:      metrics.OtelCollectorName,
+}}
+
+var _ reconcile.Reconciler = &OtelTenantReconciler{}
+
+// OtelTenantReconciler runs a dedicated otel-collector for each tenant of the
+// RootSyncs and RepoSyncs, set with the `configsync.gke.io/otel-tenant` label,
+// so that a noisy tenant cannot exhaust the queue of the shared collector.
+//
+// The tenant collectors are copies of the shared otel-collector Deployment and
+// Service. They read the same ConfigMaps as the shared collector, except that
+// the `otel-collector-custom-<tenant>` ConfigMap replaces the
+// `otel-collector-custom` ConfigMap.
+type OtelTenantReconciler struct {
+	client client.Client
+	log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+// NewOtelTenantReconciler returns a new OtelTenantReconciler.
+func NewOtelTenantReconciler(client client.Client, log logr.Logger, scheme *runtime.Scheme) *OtelTenantReconciler {
+	return &OtelTenantReconciler{
+		client: client,
+		log:    log,
+		scheme: scheme,
+	}
+}
+
+// Reconcile the collectors of all the tenants.
+func (r *OtelTenantReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	tenants, err := r.tenants(ctx)
+	if err != nil {
+		return controllerruntime.Result{}, err
+	}
+
+	sharedDep := &appsv1.Deployment{}
+	if err := r.client.Get(ctx, otelTenantRequest.NamespacedName, sharedDep); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Nothing to copy, the tenant collectors are created once the
+			// shared collector is installed.
+			return controllerruntime.Result{}, nil
+		}
+		return controllerruntime.Result{}, status.APIServerError(err, "failed to get otel-collector Deployment")
+	}
+	sharedSvc := &corev1.Service{}
+	if err := r.client.Get(ctx, otelTenantRequest.NamespacedName, sharedSvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return controllerruntime.Result{}, nil
+		}
+		return controllerruntime.Result{}, status.APIServerError(err, "failed to get otel-collector Service")
+	}
+
+	for tenant := range tenants {
+		if err := r.upsertCollector(ctx, tenant, sharedDep, sharedSvc); err != nil {
+			r.log.Error(err, "Failed to upsert the tenant otel-collector", "tenant", tenant)
+			return controllerruntime.Result{}, err
+		}
+	}
+	if err := r.deleteStaleCollectors(ctx, tenants); err != nil {
+		return controllerruntime.Result{}, err
+	}
+	return controllerruntime.Result{}, nil
+}
+
+// tenants returns the set of the valid tenants of the RootSyncs and
+// RepoSyncs.
+func (r *OtelTenantReconciler) tenants(ctx context.Context) (map[string]bool, error) {
+	tenants := make(map[string]bool)
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := r.client.List(ctx, rootSyncs, client.HasLabels{metadata.OtelTenantLabel}); err != nil {
+		return nil, status.APIServerError(err, "failed to list RootSyncs")
+	}
+	for i := range rootSyncs.Items {
+		if tenant := otelTenant(&rootSyncs.Items[i]); tenant != "" {
+			tenants[tenant] = true
+		}
+	}
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := r.client.List(ctx, repoSyncs, client.HasLabels{metadata.OtelTenantLabel}); err != nil {
+		return nil, status.APIServerError(err, "failed to list RepoSyncs")
+	}
+	for i := range repoSyncs.Items {
+		if tenant := otelTenant(&repoSyncs.Items[i]); tenant != "" {
+			tenants[tenant] = true
+		}
+	}
+	return tenants, nil
+}
+
+// upsertCollector creates or updates the Deployment and Service of the
+// collector of the tenant, from the shared collector.
+func (r *OtelTenantReconciler) upsertCollector(ctx context.Context, tenant string, sharedDep *appsv1.Deployment, sharedSvc *corev1.Service) error {
+	name := metrics.OtelCollectorTenantName(tenant)
+	selector := map[string]string{
+		"app":                    metrics.OpenTelemetry,
+		"component":              name,
+		metadata.OtelTenantLabel: tenant,
+	}
+
+	configMapHash, err := r.tenantConfigMapHash(ctx, tenant)
+	if err != nil {
+		return err
+	}
+
+	dep := &appsv1.Deployment{}
+	dep.Name = name
+	dep.Namespace = metrics.MonitoringNamespace
+	if err := r.createOrUpdate(ctx, dep, "Deployment", func() error {
+		dep.Labels = mergeLabels(dep.Labels, sharedDep.Labels)
+		dep.Labels = mergeLabels(dep.Labels, selector)
+		if dep.Spec.Selector == nil {
+			// The selector is immutable.
+			dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+		}
+		dep.Spec.Replicas = sharedDep.Spec.Replicas
+		dep.Spec.MinReadySeconds = sharedDep.Spec.MinReadySeconds
+		dep.Spec.ProgressDeadlineSeconds = sharedDep.Spec.ProgressDeadlineSeconds
+		template := sharedDep.Spec.Template.DeepCopy()
+		template.Labels = mergeLabels(template.Labels, selector)
+		if configMapHash != "" {
+			core.SetAnnotation(template, metadata.TenantConfigMapAnnotationKey, configMapHash)
+		}
+		useTenantCustomConfigMap(&template.Spec, tenant)
+		dep.Spec.Template = *template
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	svc := &corev1.Service{}
+	svc.Name = name
+	svc.Namespace = metrics.MonitoringNamespace
+	return r.createOrUpdate(ctx, svc, "Service", func() error {
+		svc.Labels = mergeLabels(svc.Labels, sharedSvc.Labels)
+		svc.Labels = mergeLabels(svc.Labels, map[string]string{metadata.OtelTenantLabel: tenant})
+		svc.Spec.Selector = selector
+		svc.Spec.Ports = sharedSvc.Spec.DeepCopy().Ports
+		return nil
+	})
+}
+
+// tenantConfigMapHash returns the hash of the custom ConfigMap of the tenant,
+// or an empty string if the tenant has none.
+func (r *OtelTenantReconciler) tenantConfigMapHash(ctx context.Context, tenant string) (string, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: metrics.MonitoringNamespace, Name: metrics.OtelCollectorTenantCustomCM(tenant)}
+	if err := r.client.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", status.APIServerErrorf(err, "failed to get otel ConfigMap %s", key)
+	}
+	h, err := hash(cm.Data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h), nil
+}
+
+// useTenantCustomConfigMap replaces the shared custom ConfigMap of the
+// collector with the custom ConfigMap of the tenant.
+func useTenantCustomConfigMap(podSpec *corev1.PodSpec, tenant string) {
+	for _, volume := range podSpec.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for _, src := range volume.Projected.Sources {
+			if src.ConfigMap != nil && src.ConfigMap.Name == metrics.OtelCollectorCustomCM {
+				src.ConfigMap.Name = metrics.OtelCollectorTenantCustomCM(tenant)
+			}
+		}
+	}
+}
+
+// deleteStaleCollectors deletes the collectors of the tenants that are no
+// longer used by any RootSync or RepoSync.
+func (r *OtelTenantReconciler) deleteStaleCollectors(ctx context.Context, tenants map[string]bool) error {
+	opts := []client.ListOption{client.InNamespace(metrics.MonitoringNamespace), client.HasLabels{metadata.OtelTenantLabel}}
+	deps := &appsv1.DeploymentList{}
+	if err := r.client.List(ctx, deps, opts...); err != nil {
+		return status.APIServerError(err, "failed to list tenant otel-collector Deployments")
+	}
+	for i := range deps.Items {
+		if err := r.deleteIfStale(ctx, &deps.Items[i], "Deployment", tenants); err != nil {
+			return err
+		}
+	}
+	svcs := &corev1.ServiceList{}
+	if err := r.client.List(ctx, svcs, opts...); err != nil {
+		return status.APIServerError(err, "failed to list tenant otel-collector Services")
+	}
+	for i := range svcs.Items {
+		if err := r.deleteIfStale(ctx, &svcs.Items[i], "Service", tenants); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OtelTenantReconciler) deleteIfStale(ctx context.Context, obj client.Object, kind string, tenants map[string]bool) error {
+	if tenants[obj.GetLabels()[metadata.OtelTenantLabel]] {
+		return nil
+	}
+	if err := r.client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return status.APIServerErrorf(err, "failed to delete tenant otel-collector %s %s", kind, client.ObjectKeyFromObject(obj))
+	}
+	r.log.Info("Managed object delete successful",
+		logFieldObject, client.ObjectKeyFromObject(obj).String(),
+		logFieldKind, kind)
+	return nil
+}
+
+func (r *OtelTenantReconciler) createOrUpdate(ctx context.Context, obj client.Object, kind string, mutate controllerutil.MutateFn) error {
+	op, err := controllerruntime.CreateOrUpdate(ctx, r.client, obj, mutate)
+	if err != nil {
+		return fmt.Errorf("failed to upsert the tenant otel-collector %s: %w", kind, err)
+	}
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, kind,
+			logFieldOperation, op)
+	}
+	return nil
+}
+
+// SetupWithManager registers the otel tenant controller with reconciler-manager.
+func (r *OtelTenantReconciler) SetupWithManager(mgr controllerruntime.Manager) error {
+	enqueue := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{otelTenantRequest}
+	})
+	inMonitoringNamespace := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == metrics.MonitoringNamespace
+	}))
+	return controllerruntime.NewControllerManagedBy(mgr).
+		Named(OtelTenantLoggerName).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		Watches(&source.Kind{Type: &v1beta1.RootSync{}}, enqueue).
+		Watches(&source.Kind{Type: &v1beta1.RepoSync{}}, enqueue).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}}, enqueue, inMonitoringNamespace).
+		Watches(&source.Kind{Type: &corev1.Service{}}, enqueue, inMonitoringNamespace).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueue, inMonitoringNamespace).
+		Complete(r)
+}
+
+// otelTenant returns the otel-collector tenant of the RootSync or RepoSync, or
+// an empty string if it uses the shared collector. Tenants that cannot be
+// used in the name of the collector are ignored.
+func otelTenant(rs client.Object) string {
+	tenant := rs.GetLabels()[metadata.OtelTenantLabel]
+	if tenant == "" {
+		return ""
+	}
+	if errs := validation.IsDNS1035Label(metrics.OtelCollectorTenantName(tenant)); len(errs) > 0 {
+		return ""
+	}
+	return tenant
+}
+
+// setOtelCollectorEndpoint routes the telemetry of the otel-agent container to
+// the collector of the tenant of the RootSync or RepoSync. The endpoint of the
+// shared collector from the reconciler template is kept without a tenant.
+func setOtelCollectorEndpoint(container *corev1.Container, rs client.Object) {
+	tenant := otelTenant(rs)
+	if tenant == "" {
+		return
+	}
+	endpoint := corev1.EnvVar{Name: reconcilermanager.OtelCollectorEndpoint, Value: metrics.OtelCollectorEndpoint(tenant)}
+	for i, env := range container.Env {
+		if env.Name == endpoint.Name {
+			container.Env[i] = endpoint
+			return
+		}
+	}
+	container.Env = append(container.Env, endpoint)
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestOtelTenantReconciler(t *testing.T) {
+	sharedDep := fake.DeploymentObject(core.Name(metrics.OtelCollectorName), core.Namespace(metrics.MonitoringNamespace))
+	sharedDep.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name: "otel-collector-config-vol",
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+			{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: metrics.OtelCollectorName}}},
+			{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: metrics.OtelCollectorCustomCM}}},
+		}}},
+	}}
+	sharedSvc := fake.ServiceObject(core.Name(metrics.OtelCollectorName), core.Namespace(metrics.MonitoringNamespace))
+	sharedSvc.Spec.Ports = []corev1.ServicePort{{Name: "opencensus", Port: metrics.OtelCollectorPort}}
+	staleDep := fake.DeploymentObject(core.Name(metrics.OtelCollectorTenantName("old")), core.Namespace(metrics.MonitoringNamespace),
+		core.Label(metadata.OtelTenantLabel, "old"))
+	tenantCM := fake.ConfigMapObject(core.Name(metrics.OtelCollectorTenantCustomCM("team-a")), core.Namespace(metrics.MonitoringNamespace))
+	tenantCM.Data = map[string]string{"otel-collector-config.yaml": "receivers:"}
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme, sharedDep, sharedSvc, staleDep, tenantCM,
+		fake.RootSyncObjectV1Beta1("root-sync", core.Label(metadata.OtelTenantLabel, "team-a")),
+		fake.RepoSyncObjectV1Beta1("bookstore", "repo-sync", core.Label(metadata.OtelTenantLabel, "Invalid_Tenant")),
+		fake.RepoSyncObjectV1Beta1("shipping", "repo-sync"))
+	r := NewOtelTenantReconciler(fakeClient, controllerruntime.Log.WithName(OtelTenantLoggerName), fakeClient.Scheme())
+
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, otelTenantRequest)
+	require.NoError(t, err)
+
+	key := client.ObjectKey{Namespace: metrics.MonitoringNamespace, Name: metrics.OtelCollectorTenantName("team-a")}
+	dep := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, key, dep))
+	require.Equal(t, "team-a", dep.Spec.Selector.MatchLabels[metadata.OtelTenantLabel])
+	require.Equal(t, "team-a", dep.Spec.Template.Labels[metadata.OtelTenantLabel])
+	require.NotEmpty(t, dep.Spec.Template.Annotations[metadata.TenantConfigMapAnnotationKey])
+	sources := dep.Spec.Template.Spec.Volumes[0].Projected.Sources
+	require.Equal(t, metrics.OtelCollectorName, sources[0].ConfigMap.Name)
+	require.Equal(t, metrics.OtelCollectorTenantCustomCM("team-a"), sources[1].ConfigMap.Name)
+	// The shared collector is not modified.
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(sharedDep), sharedDep))
+	require.Equal(t, metrics.OtelCollectorCustomCM, sharedDep.Spec.Template.Spec.Volumes[0].Projected.Sources[1].ConfigMap.Name)
+
+	svc := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, key, svc))
+	require.Equal(t, dep.Spec.Selector.MatchLabels, svc.Spec.Selector)
+	require.Equal(t, sharedSvc.Spec.Ports, svc.Spec.Ports)
+
+	err = fakeClient.Get(ctx, client.ObjectKeyFromObject(staleDep), &appsv1.Deployment{})
+	require.True(t, apierrors.IsNotFound(err), "stale tenant collector should be deleted, got %v", err)
+
+	deps := &appsv1.DeploymentList{}
+	require.NoError(t, fakeClient.List(ctx, deps, client.InNamespace(metrics.MonitoringNamespace)))
+	require.Len(t, deps.Items, 2, "only the shared and team-a collectors should exist")
+}
+
+func TestSetOtelCollectorEndpoint(t *testing.T) {
+	sharedEndpoint := corev1.EnvVar{Name: reconcilermanager.OtelCollectorEndpoint, Value: metrics.OtelCollectorEndpoint("")}
+	testCases := []struct {
+		name string
+		rs   client.Object
+		want string
+	}{
+		{
+			name: "no tenant",
+			rs:   fake.RootSyncObjectV1Beta1("root-sync"),
+			want: "otel-collector.config-management-monitoring:55678",
+		},
+		{
+			name: "tenant",
+			rs:   fake.RepoSyncObjectV1Beta1("bookstore", "repo-sync", core.Label(metadata.OtelTenantLabel, "team-a")),
+			want: "otel-collector-team-a.config-management-monitoring:55678",
+		},
+		{
+			name: "invalid tenant",
+			rs:   fake.RepoSyncObjectV1Beta1("bookstore", "repo-sync", core.Label(metadata.OtelTenantLabel, "Team.A")),
+			want: "otel-collector.config-management-monitoring:55678",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := corev1.Container{Name: metrics.OtelAgentName, Env: []corev1.EnvVar{sharedEndpoint}}
+			setOtelCollectorEndpoint(&container, tc.rs)
+			require.Equal(t, []corev1.EnvVar{{Name: reconcilermanager.OtelCollectorEndpoint, Value: tc.want}}, container.Env)
+		})
+	}
+}
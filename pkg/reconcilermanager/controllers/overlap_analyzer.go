@@ -0,0 +1,244 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// overlapAnalysisPeriod is how often the reconciler-manager
+	// cross-references the inventories of the RootSyncs and RepoSyncs.
+	overlapAnalysisPeriod = time.Minute
+
+	// maxOverlapIDs is the maximum number of overlapping objects listed in
+	// the message of the Overlapping condition.
+	maxOverlapIDs = 10
+)
+
+// rsyncRef identifies a RootSync or a RepoSync.
+type rsyncRef struct {
+	kind string
+	key  client.ObjectKey
+}
+
+func (r rsyncRef) String() string {
+	return fmt.Sprintf("%s %s", r.kind, r.key)
+}
+
+// OverlapAnalyzer periodically cross-references the ResourceGroup
+// inventories of all the RootSyncs and RepoSyncs, and reports the objects
+// declared by more than one of them with the Overlapping condition of every
+// RSync declaring them.
+// The overlaps are otherwise only reported as management conflicts once both
+// reconcilers try to apply the same object.
+type OverlapAnalyzer struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// NewOverlapAnalyzer returns a new OverlapAnalyzer.
+func NewOverlapAnalyzer(c client.Client, log logr.Logger) *OverlapAnalyzer {
+	return &OverlapAnalyzer{
+		client: c,
+		log:    log,
+	}
+}
+
+// Start implements manager.Runnable.
+func (a *OverlapAnalyzer) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := a.analyze(ctx); err != nil {
+			a.log.Error(err, "Failed to analyze the overlaps of the RSync inventories")
+		}
+	}, overlapAnalysisPeriod)
+	return nil
+}
+
+func (a *OverlapAnalyzer) analyze(ctx context.Context) error {
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := a.client.List(ctx, rootSyncs); err != nil {
+		return errors.Wrap(err, "failed to list the RootSyncs")
+	}
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := a.client.List(ctx, repoSyncs); err != nil {
+		return errors.Wrap(err, "failed to list the RepoSyncs")
+	}
+
+	var errs []error
+	declaredBy := make(map[core.ID][]rsyncRef)
+	addInventory := func(ref rsyncRef) {
+		ids, err := a.inventoryIDs(ctx, ref.key)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		for _, id := range ids {
+			declaredBy[id] = append(declaredBy[id], ref)
+		}
+	}
+	for i := range rootSyncs.Items {
+		addInventory(rsyncRef{kind: configsync.RootSyncKind, key: client.ObjectKeyFromObject(&rootSyncs.Items[i])})
+	}
+	for i := range repoSyncs.Items {
+		addInventory(rsyncRef{kind: configsync.RepoSyncKind, key: client.ObjectKeyFromObject(&repoSyncs.Items[i])})
+	}
+	overlaps := findOverlaps(declaredBy)
+	if len(overlaps) > 0 {
+		a.log.Info("Objects are declared by multiple RSyncs", "rsyncs", len(overlaps))
+	}
+
+	for i := range rootSyncs.Items {
+		rs := &rootSyncs.Items[i]
+		ref := rsyncRef{kind: configsync.RootSyncKind, key: client.ObjectKeyFromObject(rs)}
+		var updated bool
+		if message := overlapMessage(overlaps[ref]); message != "" {
+			updated = rootsync.SetOverlapping(rs, message)
+		} else {
+			updated = rootsync.RemoveCondition(rs, v1beta1.RootSyncOverlapping)
+		}
+		if updated {
+			if err := a.client.Status().Update(ctx, rs); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to update the status of the RootSync %s", ref.key))
+			}
+		}
+	}
+	for i := range repoSyncs.Items {
+		rs := &repoSyncs.Items[i]
+		ref := rsyncRef{kind: configsync.RepoSyncKind, key: client.ObjectKeyFromObject(rs)}
+		var updated bool
+		if message := overlapMessage(overlaps[ref]); message != "" {
+			updated = reposync.SetOverlapping(rs, message)
+		} else {
+			updated = reposync.RemoveCondition(rs, v1beta1.RepoSyncOverlapping)
+		}
+		if updated {
+			if err := a.client.Status().Update(ctx, rs); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to update the status of the RepoSync %s", ref.key))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// inventoryIDs returns the IDs of the objects in the ResourceGroup inventory
+// of the RSync. An RSync without an inventory has not synced yet, and has no
+// objects.
+func (a *OverlapAnalyzer) inventoryIDs(ctx context.Context, key client.ObjectKey) ([]core.ID, error) {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	if err := a.client.Get(ctx, key, rg); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the inventory %s", key)
+	}
+	resources, _, err := unstructured.NestedSlice(rg.Object, "spec", "resources")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the inventory %s", key)
+	}
+	var ids []core.ID
+	for _, r := range resources {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		str := func(field string) string {
+			s, _ := m[field].(string)
+			return s
+		}
+		ids = append(ids, core.ID{
+			GroupKind: schema.GroupKind{Group: str("group"), Kind: str("kind")},
+			ObjectKey: client.ObjectKey{Namespace: str("namespace"), Name: str("name")},
+		})
+	}
+	return ids, nil
+}
+
+// findOverlaps returns, for every RSync declaring objects also declared by
+// other RSyncs, the other RSyncs and the objects they also declare.
+func findOverlaps(declaredBy map[core.ID][]rsyncRef) map[rsyncRef]map[rsyncRef][]core.ID {
+	overlaps := make(map[rsyncRef]map[rsyncRef][]core.ID)
+	for id, refs := range declaredBy {
+		if len(refs) < 2 {
+			continue
+		}
+		for _, ref := range refs {
+			for _, other := range refs {
+				if other == ref {
+					continue
+				}
+				if overlaps[ref] == nil {
+					overlaps[ref] = make(map[rsyncRef][]core.ID)
+				}
+				overlaps[ref][other] = append(overlaps[ref][other], id)
+			}
+		}
+	}
+	return overlaps
+}
+
+// overlapMessage returns the message of the Overlapping condition, which lists
+// the other RSyncs and the objects they also declare, or an empty string if
+// there is no overlap.
+func overlapMessage(others map[rsyncRef][]core.ID) string {
+	if len(others) == 0 {
+		return ""
+	}
+	refs := make([]rsyncRef, 0, len(others))
+	for ref := range others {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	var parts []string
+	for _, ref := range refs {
+		ids := others[ref]
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i].String() < ids[j].String()
+		})
+		var listed []string
+		for i, id := range ids {
+			if i == maxOverlapIDs {
+				listed = append(listed, fmt.Sprintf("and %d more", len(ids)-maxOverlapIDs))
+				break
+			}
+			listed = append(listed, id.String())
+		}
+		parts = append(parts, fmt.Sprintf("The %s also declares %d of the objects: %s.", ref, len(ids), strings.Join(listed, "; ")))
+	}
+	return fmt.Sprintf("The management of the objects declared by multiple RSyncs is going to conflict. Declare them in only one source. %s",
+		strings.Join(parts, " "))
+}
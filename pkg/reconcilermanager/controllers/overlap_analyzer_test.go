@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func testInventory(key client.ObjectKey, ids ...core.ID) *unstructured.Unstructured {
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	rg.SetName(key.Name)
+	rg.SetNamespace(key.Namespace)
+	var resources []interface{}
+	for _, id := range ids {
+		resources = append(resources, map[string]interface{}{
+			"group":     id.Group,
+			"kind":      id.Kind,
+			"namespace": id.Namespace,
+			"name":      id.Name,
+		})
+	}
+	_ = unstructured.SetNestedSlice(rg.Object, resources, "spec", "resources")
+	return rg
+}
+
+func TestOverlapAnalyzer(t *testing.T) {
+	shared := core.IDOf(fake.RoleObject(core.Namespace("bookstore"), core.Name("shared")))
+	rootOnly := core.IDOf(fake.ClusterRoleObject(core.Name("root-only")))
+	repoOnly := core.IDOf(fake.RoleObject(core.Namespace("bookstore"), core.Name("repo-only")))
+
+	testCases := []struct {
+		name        string
+		rootIDs     []core.ID
+		repoIDs     []core.ID
+		wantOverlap bool
+	}{
+		{
+			name:    "disjoint inventories",
+			rootIDs: []core.ID{rootOnly},
+			repoIDs: []core.ID{repoOnly},
+		},
+		{
+			name:        "overlapping inventories",
+			rootIDs:     []core.ID{rootOnly, shared},
+			repoIDs:     []core.ID{shared, repoOnly},
+			wantOverlap: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			rootSync := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+			// A stale condition is removed once the inventories are disjoint.
+			rootsync.SetOverlapping(rootSync, "stale")
+			repoSync := fake.RepoSyncObjectV1Beta1("bookstore", configsync.RepoSyncName)
+			// An RSync without an inventory has no objects.
+			otherRepoSync := fake.RepoSyncObjectV1Beta1("shoestore", configsync.RepoSyncName)
+			fakeClient := syncerFake.NewClient(t, core.Scheme, rootSync, repoSync, otherRepoSync,
+				testInventory(client.ObjectKeyFromObject(rootSync), tc.rootIDs...),
+				testInventory(client.ObjectKeyFromObject(repoSync), tc.repoIDs...))
+
+			analyzer := NewOverlapAnalyzer(fakeClient, logr.Discard())
+			require.NoError(t, analyzer.analyze(ctx))
+
+			gotRootSync := &v1beta1.RootSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(rootSync), gotRootSync))
+			gotRepoSync := &v1beta1.RepoSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(repoSync), gotRepoSync))
+			gotOtherRepoSync := &v1beta1.RepoSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(otherRepoSync), gotOtherRepoSync))
+			rootCond := rootsync.GetCondition(gotRootSync.Status.Conditions, v1beta1.RootSyncOverlapping)
+			repoCond := reposync.GetCondition(gotRepoSync.Status.Conditions, v1beta1.RepoSyncOverlapping)
+			require.Nil(t, reposync.GetCondition(gotOtherRepoSync.Status.Conditions, v1beta1.RepoSyncOverlapping))
+			if !tc.wantOverlap {
+				require.Nil(t, rootCond)
+				require.Nil(t, repoCond)
+				return
+			}
+			require.NotNil(t, rootCond)
+			require.NotNil(t, repoCond)
+			require.Equal(t, metav1.ConditionTrue, rootCond.Status)
+			require.Equal(t, metav1.ConditionTrue, repoCond.Status)
+			require.Contains(t, rootCond.Message, "The RepoSync bookstore/repo-sync also declares 1 of the objects: "+shared.String()+".")
+			require.Contains(t, repoCond.Message, "The RootSync config-management-system/root-sync also declares 1 of the objects: "+shared.String()+".")
+			require.NotContains(t, rootCond.Message, rootOnly.String())
+		})
+	}
+}
@@ -57,6 +57,11 @@ const (
 	// It will be used in both the indexing and watching.
 	caCertSecretRefField = ".spec.git.caCertSecretRef.name"
 
+	// knownHostsSecretRefField is the path of the field in the RootSync|RepoSync CRDs
+	// that we wish to use as the "object reference".
+	// It will be used in both the indexing and watching.
+	knownHostsSecretRefField = ".spec.git.knownHostsSecretRef.name"
+
 	// helmSecretRefField is the path of the field in the RootSync|RepoSync CRDs
 	// that we wish to use as the "object reference".
 	// It will be used in both the indexing and watching.
@@ -101,6 +106,18 @@ type reconcilerBase struct {
 	hydrationPollingPeriod  time.Duration
 	membership              *hubv1.Membership
 
+	// oneShotJobs is true if the reconcilers of the one-shot RSyncs run as
+	// Jobs. See SetOneShotJobs.
+	oneShotJobs bool
+
+	// exportSink is the URL of the sink the reconcilers export their records
+	// to. Empty disables exporting. See SetExportSink.
+	exportSink string
+
+	// pluginImages are the images the plugins of the RepoSyncs may run.
+	// See SetPluginImages.
+	pluginImages []string
+
 	// syncKind is the kind of the sync object: RootSync or RepoSync.
 	syncKind string
 
@@ -114,6 +131,12 @@ type reconcilerBase struct {
 	lastReconciledResourceVersions map[types.NamespacedName]string
 }
 
+// SetExportSink configures the reconcilers to export their sync attempts,
+// applied objects and drift events to the sink at the URL.
+func (r *reconcilerBase) SetExportSink(sink string) {
+	r.exportSink = sink
+}
+
 func (r *reconcilerBase) serviceAccountSubject(reconcilerRef types.NamespacedName) rbacv1.Subject {
 	return newSubject(reconcilerRef.Name, reconcilerRef.Namespace, kinds.ServiceAccount().Kind)
 }
@@ -162,9 +185,27 @@ func (r *reconcilerBase) upsertServiceAccount(
 type mutateFn func(client.Object) error
 
 func (r *reconcilerBase) upsertDeployment(ctx context.Context, reconcilerRef types.NamespacedName, labelMap map[string]string, mutateObject mutateFn) (*unstructured.Unstructured, controllerutil.OperationResult, error) {
+	reconcilerDeployment, err := r.renderDeployment(reconcilerRef, labelMap, mutateObject)
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+	appliedObj, op, err := r.createOrPatchDeployment(ctx, reconcilerDeployment)
+
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, reconcilerRef.String(),
+			logFieldKind, "Deployment",
+			logFieldOperation, op)
+	}
+	return appliedObj, op, err
+}
+
+// renderDeployment renders the reconciler Deployment from the manifest in the
+// ConfigMap, with the common labels and the mutations applied.
+func (r *reconcilerBase) renderDeployment(reconcilerRef types.NamespacedName, labelMap map[string]string, mutateObject mutateFn) (*appsv1.Deployment, error) {
 	reconcilerDeployment := &appsv1.Deployment{}
 	if err := parseDeployment(reconcilerDeployment); err != nil {
-		return nil, controllerutil.OperationResultNone, errors.Wrap(err, "failed to parse reconciler Deployment manifest from ConfigMap")
+		return nil, errors.Wrap(err, "failed to parse reconciler Deployment manifest from ConfigMap")
 	}
 
 	reconcilerDeployment.Name = reconcilerRef.Name
@@ -191,17 +232,9 @@ func (r *reconcilerBase) upsertDeployment(ctx context.Context, reconcilerRef typ
 	})
 
 	if err := mutateObject(reconcilerDeployment); err != nil {
-		return nil, controllerutil.OperationResultNone, err
-	}
-	appliedObj, op, err := r.createOrPatchDeployment(ctx, reconcilerDeployment)
-
-	if op != controllerutil.OperationResultNone {
-		r.log.Info("Managed object upsert successful",
-			logFieldObject, reconcilerRef.String(),
-			logFieldKind, "Deployment",
-			logFieldOperation, op)
+		return nil, err
 	}
-	return appliedObj, op, err
+	return reconcilerDeployment, nil
 }
 
 // createOrPatchDeployment() first call Get() on the object. If the
@@ -0,0 +1,256 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// jobTemplateHashAnnotation records the hash of the pod template of a
+// reconciler Job. The pod template of a Job is immutable, so the Job is
+// replaced when the hash changes.
+const jobTemplateHashAnnotation = configsync.ConfigSyncPrefix + "job-template-hash"
+
+// oneTimeEnvs maps the fetcher containers to the env var which makes them
+// fetch the source once and exit.
+var oneTimeEnvs = map[string]string{
+	reconcilermanager.GitSync:  "GIT_SYNC_ONE_TIME",
+	reconcilermanager.OciSync:  "OCI_SYNC_ONE_TIME",
+	reconcilermanager.HelmSync: "HELM_SYNC_ONE_TIME",
+}
+
+// SetOneShotJobs configures the reconciler to run the reconcilers of the
+// one-shot RootSyncs and RepoSyncs as Jobs, which complete once the source
+// commit is synced, instead of as Deployments.
+func (r *reconcilerBase) SetOneShotJobs() {
+	r.oneShotJobs = true
+}
+
+// runAsJob returns true if the reconciler of an RSync with the mode runs as a
+// Job.
+func (r *reconcilerBase) runAsJob(mode string) bool {
+	return r.oneShotJobs && v1beta1.SyncMode(mode) == v1beta1.OneShotSyncMode
+}
+
+// upsertWorkload upserts the reconciler Deployment, or the reconciler Job if
+// asJob is true, and deletes the other one, if any. It returns the kind of
+// the upserted workload along with the object.
+func (r *reconcilerBase) upsertWorkload(ctx context.Context, reconcilerRef types.NamespacedName, labelMap map[string]string, mutateObject mutateFn, asJob bool) (*unstructured.Unstructured, string, controllerutil.OperationResult, error) {
+	if !asJob {
+		// Jobs are only created with one-shot Jobs enabled. Skip the cleanup
+		// otherwise, to avoid an extra request on every reconcile.
+		if r.oneShotJobs {
+			if err := r.deleteWorkload(ctx, reconcilerRef, kinds.Job()); err != nil {
+				return nil, "Job", controllerutil.OperationResultNone, err
+			}
+		}
+		obj, op, err := r.upsertDeployment(ctx, reconcilerRef, labelMap, mutateObject)
+		return obj, "Deployment", op, err
+	}
+	if err := r.deleteWorkload(ctx, reconcilerRef, kinds.Deployment()); err != nil {
+		return nil, "Deployment", controllerutil.OperationResultNone, err
+	}
+	obj, op, err := r.upsertJob(ctx, reconcilerRef, labelMap, mutateObject)
+	return obj, "Job", op, err
+}
+
+// workload gets the reconciler Deployment or Job, by kind.
+func (r *reconcilerBase) workload(ctx context.Context, kind string, ref types.NamespacedName) (*unstructured.Unstructured, error) {
+	if kind == "Job" {
+		return r.job(ctx, ref)
+	}
+	return r.deployment(ctx, ref)
+}
+
+// upsertJob renders the reconciler Deployment, converts it into a Job and
+// creates the Job. The Job is replaced when its pod template changes, which
+// syncs the source again.
+func (r *reconcilerBase) upsertJob(ctx context.Context, reconcilerRef types.NamespacedName, labelMap map[string]string, mutateObject mutateFn) (*unstructured.Unstructured, controllerutil.OperationResult, error) {
+	reconcilerDeployment, err := r.renderDeployment(reconcilerRef, labelMap, mutateObject)
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+	declared, err := jobFromDeployment(reconcilerDeployment)
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+
+	jobClient := r.dynamicClient.Resource(kinds.JobResource()).Namespace(reconcilerRef.Namespace)
+	op := controllerutil.OperationResultCreated
+	current, err := jobClient.Get(ctx, reconcilerRef.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, controllerutil.OperationResultNone, errors.Wrap(err, "failed to get the reconciler Job")
+	case current.GetAnnotations()[jobTemplateHashAnnotation] == declared.Annotations[jobTemplateHashAnnotation]:
+		return current, controllerutil.OperationResultNone, nil
+	default:
+		// The pod template of a Job is immutable.
+		if err := r.deleteWorkload(ctx, reconcilerRef, kinds.Job()); err != nil {
+			return nil, controllerutil.OperationResultNone, err
+		}
+		op = controllerutil.OperationResultUpdated
+	}
+
+	u, err := kinds.ToUnstructured(declared, r.scheme)
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+	appliedObj, err := jobClient.Create(ctx, u, metav1.CreateOptions{FieldManager: reconcilermanager.ManagerName})
+	if err != nil {
+		// The deleted Job may not be gone yet. Retry later.
+		return nil, controllerutil.OperationResultNone, errors.Wrap(err, "failed to create the reconciler Job")
+	}
+	r.log.Info("Managed object upsert successful",
+		logFieldObject, reconcilerRef.String(),
+		logFieldKind, "Job",
+		logFieldOperation, op)
+	return appliedObj, op, nil
+}
+
+// job gets the reconciler Job.
+func (r *reconcilerBase) job(ctx context.Context, ref types.NamespacedName) (*unstructured.Unstructured, error) {
+	jobObj, err := r.dynamicClient.Resource(kinds.JobResource()).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Errorf(
+				"Job %s not found in namespace: %s.", ref.Name, ref.Namespace)
+		}
+		return nil, errors.Wrapf(err, "error while retrieving job")
+	}
+	return jobObj, nil
+}
+
+// deleteWorkload deletes the reconciler Deployment or Job, and its pods, if
+// it exists.
+func (r *reconcilerBase) deleteWorkload(ctx context.Context, ref types.NamespacedName, gvk schema.GroupVersionKind) error {
+	u := &unstructured.Unstructured{}
+	u.SetName(ref.Name)
+	u.SetNamespace(ref.Namespace)
+	u.SetGroupVersionKind(gvk)
+	// Jobs orphan their pods by default.
+	propagation := metav1.DeletePropagationBackground
+	if err := r.client.Delete(ctx, u, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to delete the reconciler %s", gvk.Kind)
+	}
+	r.log.Info("Managed object delete successful",
+		logFieldObject, ref.String(),
+		logFieldKind, gvk.Kind)
+	return nil
+}
+
+// jobFromDeployment converts the reconciler Deployment into a Job, which
+// syncs the source once and completes.
+//
+// Without sidecar support, all the containers of a Job pod must exit. So the
+// fetcher and the hydration-controller run once as init containers, before the
+// reconciler, and the otel-agent is dropped.
+func jobFromDeployment(d *appsv1.Deployment) (*batchv1.Job, error) {
+	template := d.Spec.Template.DeepCopy()
+	var initContainers, hydration, containers []corev1.Container
+	for _, c := range template.Spec.Containers {
+		switch c.Name {
+		case reconcilermanager.Reconciler:
+			c.Env = append(c.Env, corev1.EnvVar{Name: reconcilermanager.OneShot, Value: "true"})
+			containers = append(containers, c)
+		case reconcilermanager.HydrationController:
+			c.Env = append(c.Env, corev1.EnvVar{Name: reconcilermanager.OneShot, Value: "true"})
+			hydration = append(hydration, initContainer(c))
+		case reconcilermanager.GitSync, reconcilermanager.OciSync, reconcilermanager.HelmSync:
+			for _, e := range c.Env {
+				// The askpass endpoints are served by sidecars, which don't
+				// run alongside init containers.
+				if e.Name == "GIT_ASKPASS_URL" {
+					return nil, errors.Errorf("the askpass auth of %s is not supported in one-shot Jobs", c.Name)
+				}
+			}
+			c.Env = append(c.Env, corev1.EnvVar{Name: oneTimeEnvs[c.Name], Value: "true"})
+			initContainers = append(initContainers, initContainer(c))
+		case metrics.OtelAgentName:
+			// Metrics are not exported from one-shot Jobs.
+		default:
+			return nil, errors.Errorf("the %s container is not supported in one-shot Jobs", c.Name)
+		}
+	}
+	template.Spec.InitContainers = append(append(template.Spec.InitContainers, initContainers...), hydration...)
+	template.Spec.Containers = containers
+	template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+
+	hash, err := templateHash(template)
+	if err != nil {
+		return nil, err
+	}
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            d.Name,
+			Namespace:       d.Namespace,
+			Labels:          d.Labels,
+			Annotations:     map[string]string{jobTemplateHashAnnotation: hash},
+			OwnerReferences: d.OwnerReferences,
+		},
+		Spec: batchv1.JobSpec{Template: *template},
+	}
+	for k, v := range d.Annotations {
+		job.Annotations[k] = v
+	}
+	return job, nil
+}
+
+// initContainer returns the container without the fields which are not
+// allowed in init containers.
+func initContainer(c corev1.Container) corev1.Container {
+	c.Lifecycle = nil
+	c.LivenessProbe = nil
+	c.ReadinessProbe = nil
+	c.StartupProbe = nil
+	c.Ports = nil
+	return c
+}
+
+// templateHash returns the hash of the pod template.
+func templateHash(template *corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash the reconciler Job template")
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
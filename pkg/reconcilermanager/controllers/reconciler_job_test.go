@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+)
+
+func jobTestDeployment(containers ...corev1.Container) *appsv1.Deployment {
+	d := &appsv1.Deployment{}
+	d.Name = "root-reconciler"
+	d.Namespace = "config-management-system"
+	d.Spec.Template.Spec.Containers = containers
+	return d
+}
+
+func TestJobFromDeployment(t *testing.T) {
+	probe := &corev1.Probe{}
+	reconciler := corev1.Container{Name: reconcilermanager.Reconciler}
+	hydration := corev1.Container{Name: reconcilermanager.HydrationController}
+	gitSync := corev1.Container{Name: reconcilermanager.GitSync, ReadinessProbe: probe}
+	otelAgent := corev1.Container{Name: metrics.OtelAgentName}
+
+	oneShot := corev1.EnvVar{Name: reconcilermanager.OneShot, Value: "true"}
+
+	testCases := []struct {
+		name               string
+		deployment         *appsv1.Deployment
+		wantInitContainers []corev1.Container
+		wantContainers     []corev1.Container
+		wantErr            bool
+	}{
+		{
+			name:       "fetcher and hydration-controller run before the reconciler",
+			deployment: jobTestDeployment(hydration, reconciler, gitSync, otelAgent),
+			wantInitContainers: []corev1.Container{
+				{Name: reconcilermanager.GitSync, Env: []corev1.EnvVar{{Name: "GIT_SYNC_ONE_TIME", Value: "true"}}},
+				{Name: reconcilermanager.HydrationController, Env: []corev1.EnvVar{oneShot}},
+			},
+			wantContainers: []corev1.Container{
+				{Name: reconcilermanager.Reconciler, Env: []corev1.EnvVar{oneShot}},
+			},
+		},
+		{
+			name: "askpass auth is not supported",
+			deployment: jobTestDeployment(reconciler, corev1.Container{
+				Name: reconcilermanager.GitSync,
+				Env:  []corev1.EnvVar{{Name: "GIT_ASKPASS_URL", Value: "http://localhost:9102"}},
+			}),
+			wantErr: true,
+		},
+		{
+			name:       "sidecars are not supported",
+			deployment: jobTestDeployment(reconciler, corev1.Container{Name: GceNodeAskpassSidecarName}),
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := jobFromDeployment(tc.deployment)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("got nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := job.Spec.Template.Spec
+			if diff := cmp.Diff(tc.wantInitContainers, spec.InitContainers); diff != "" {
+				t.Errorf("init containers diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantContainers, spec.Containers); diff != "" {
+				t.Errorf("containers diff (-want +got):\n%s", diff)
+			}
+			if spec.RestartPolicy != corev1.RestartPolicyOnFailure {
+				t.Errorf("got restart policy %q, want %q", spec.RestartPolicy, corev1.RestartPolicyOnFailure)
+			}
+			if job.Annotations[jobTemplateHashAnnotation] == "" {
+				t.Errorf("missing %s annotation", jobTemplateHashAnnotation)
+			}
+		})
+	}
+}
+
+func TestJobFromDeploymentHash(t *testing.T) {
+	d := jobTestDeployment(corev1.Container{Name: reconcilermanager.Reconciler, Image: "reconciler:v1"})
+	job1, err := jobFromDeployment(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job2, err := jobFromDeployment(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job1.Annotations[jobTemplateHashAnnotation] != job2.Annotations[jobTemplateHashAnnotation] {
+		t.Error("got different hashes for the same Deployment")
+	}
+	d.Spec.Template.Spec.Containers[0].Image = "reconciler:v2"
+	job3, err := jobFromDeployment(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job1.Annotations[jobTemplateHashAnnotation] == job3.Annotations[jobTemplateHashAnnotation] {
+		t.Error("got the same hash for a changed Deployment")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -65,6 +66,9 @@ type RepoSyncReconciler struct {
 	reconcilerBase
 	// repoSyncs is a cache of the reconciled RepoSync objects.
 	repoSyncs map[types.NamespacedName]struct{}
+	// namespacedOnly is true if the reconciler-manager runs with only
+	// namespaced permissions. See SetNamespacedOnly.
+	namespacedOnly bool
 
 	lock sync.Mutex
 }
@@ -86,6 +90,17 @@ func NewRepoSyncReconciler(clusterName string, reconcilerPollingPeriod, hydratio
 	}
 }
 
+// SetNamespacedOnly configures the reconciler to run with only namespaced
+// permissions. The namespace reconcilers are bound to the
+// `configsync.gke.io:ns-reconciler` Role of their namespace, instead of the
+// ClusterRole with the same name, and the cluster-scoped Autopilot check is
+// skipped.
+func (r *RepoSyncReconciler) SetNamespacedOnly() {
+	r.namespacedOnly = true
+	isAutopilot := false
+	r.isAutopilotCluster = &isAutopilot
+}
+
 // +kubebuilder:rbac:groups=configsync.gke.io,resources=reposyncs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=configsync.gke.io,resources=reposyncs/status,verbs=get;update;patch
 
@@ -233,6 +248,27 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "Secret reconcile failed")
 	}
 
+	// Create secret in config-management-system namespace using the
+	// existing secret in the reposync.namespace.
+	if sRef, err := upsertKnownHostsSecret(ctx, log, rs, r.client, reconcilerRef); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, sRef.String(),
+			logFieldKind, "Secret",
+			"type", "knownHosts")
+		reposync.SetStalled(rs, "Secret", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "Secret reconcile failed")
+	}
+
 	labelMap := map[string]string{
 		metadata.SyncNamespaceLabel: rs.Namespace,
 		metadata.SyncNameLabel:      rs.Name,
@@ -292,16 +328,78 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "RoleBinding reconcile failed")
 	}
 
-	containerEnvs := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
-	mut := r.mutationsFor(ctx, rs, containerEnvs)
+	// Overwrite reconciler clusterrolebindings.
+	if crbRef, err := r.upsertClusterRoleBindings(ctx, reconcilerRef); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, crbRef.String(),
+			logFieldKind, "ClusterRoleBinding")
+		reposync.SetStalled(rs, "ClusterRoleBinding", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "ClusterRoleBinding reconcile failed")
+	}
+
+	// Overwrite or remove the registry webhook receiver Service.
+	ociWebhook := v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.OciSource && rs.Spec.Oci != nil && rs.Spec.Oci.Webhook
+	if svcRef, err := r.reconcileOciWebhookService(ctx, reconcilerRef, ociWebhook, labelMap); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, svcRef.String(),
+			logFieldKind, "Service")
+		reposync.SetStalled(rs, "Service", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "Service reconcile failed")
+	}
 
-	// Upsert Namespace reconciler deployment.
-	deployObj, op, err := r.upsertDeployment(ctx, reconcilerRef, labelMap, mut)
+	override, err := r.overrideWithDefaults(ctx, rs.Spec.Override)
+	if err != nil {
+		log.Error(err, "Failed to read the override defaults",
+			logFieldObject, rsRef.String(),
+			logFieldKind, r.syncKind)
+		reposync.SetStalled(rs, "ConfigMap", err)
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "override defaults reconcile failed")
+	}
+	// Render the reconciler Deployment from a copy, so the fleet defaults are
+	// never written back into the RepoSync spec.
+	rsWithDefaults := rs.DeepCopy()
+	rsWithDefaults.Spec.Override = override
+
+	containerEnvs := r.populateContainerEnvs(ctx, rsWithDefaults, reconcilerRef.Name)
+	mut := r.mutationsFor(ctx, rsWithDefaults, containerEnvs)
+	// Hold back reconciler image upgrades according to the upgrade policy.
+	var upgradeHeld bool
+	mut = r.holdUpgrade(ctx, rsRef, mut, &upgradeHeld)
+
+	// Upsert Namespace reconciler deployment, or job for one-shot syncs.
+	deployObj, workloadKind, op, err := r.upsertWorkload(ctx, reconcilerRef, labelMap, mut, r.runAsJob(rs.Spec.Mode))
 	if err != nil {
 		log.Error(err, "Managed object get failed",
 			logFieldObject, reconcilerRef.String(),
-			logFieldKind, "Deployment")
-		reposync.SetStalled(rs, "Deployment", err)
+			logFieldKind, workloadKind)
+		reposync.SetStalled(rs, workloadKind, err)
 		// Upsert errors should always trigger retry (return error),
 		// even if status update is successful.
 		_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -312,19 +410,19 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		}
 		// Use the upsert error for metric tagging.
 		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
-		return controllerruntime.Result{}, errors.Wrap(err, "Deployment reconcile failed")
+		return controllerruntime.Result{}, errors.Wrapf(err, "%s reconcile failed", workloadKind)
 	}
 	rs.Status.Reconciler = reconcilerRef.Name
 
 	// Get the latest deployment to check the status.
-	// For other operations, upsertDeployment will have returned the latest already.
+	// For other operations, upsertWorkload will have returned the latest already.
 	if op == controllerutil.OperationResultNone {
-		deployObj, err = r.deployment(ctx, reconcilerRef)
+		deployObj, err = r.workload(ctx, workloadKind, reconcilerRef)
 		if err != nil {
 			log.Error(err, "Managed object get failed",
 				logFieldObject, reconcilerRef.String(),
-				logFieldKind, "Deployment")
-			reposync.SetStalled(rs, "Deployment", err)
+				logFieldKind, workloadKind)
+			reposync.SetStalled(rs, workloadKind, err)
 			// Get errors should always trigger retry (return error),
 			// even if status update is successful.
 			_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -341,8 +439,8 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 	if err != nil {
 		log.Error(err, "Managed object status check failed",
 			logFieldObject, reconcilerRef.String(),
-			logFieldKind, "Deployment")
-		reposync.SetStalled(rs, "Deployment", err)
+			logFieldKind, workloadKind)
+		reposync.SetStalled(rs, workloadKind, err)
 		// Get errors should always trigger retry (return error),
 		// even if status update is successful.
 		_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -354,8 +452,9 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, err
 	}
 
-	log.V(3).Info("RepoSync reconciler Deployment status",
+	log.V(3).Info("RepoSync reconciler workload status",
 		logFieldObject, reconcilerRef.String(),
+		logFieldKind, workloadKind,
 		"resourceVersion", deployObj.GetResourceVersion(),
 		"status", result.Status,
 		"message", result.Message)
@@ -365,18 +464,19 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 	case kstatus.InProgressStatus:
 		// inProgressStatus indicates that the deployment is not yet
 		// available. Hence update the Reconciling status condition.
-		reposync.SetReconciling(rs, "Deployment", result.Message)
+		reposync.SetReconciling(rs, workloadKind, result.Message)
 		// Clear Stalled condition.
 		reposync.ClearCondition(rs, v1beta1.RepoSyncStalled)
 	case kstatus.FailedStatus:
 		// statusFailed indicates that the deployment failed to reconcile. Update
 		// Reconciling status condition with appropriate message specifying the
 		// reason for failure.
-		reposync.SetReconciling(rs, "Deployment", result.Message)
+		reposync.SetReconciling(rs, workloadKind, result.Message)
 		// Set Stalled condition with the deployment statusFailed.
-		reposync.SetStalled(rs, "Deployment", errors.New(string(result.Status)))
+		reposync.SetStalled(rs, workloadKind, errors.New(string(result.Status)))
 	case kstatus.CurrentStatus:
-		// currentStatus indicates that the deployment is available, which qualifies
+		// currentStatus indicates that the deployment is available, or that the
+		// job is complete, which qualifies
 		// to clear the Reconciling status condition in RepoSync.
 		reposync.ClearCondition(rs, v1beta1.RepoSyncReconciling)
 		// Since there were no errors, we can clear any previous Stalled condition.
@@ -395,6 +495,10 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 			logFieldObject, rsRef.String(),
 			logFieldKind, r.syncKind)
 	}
+	if upgradeHeld {
+		// Check again later whether the upgrade can proceed.
+		return controllerruntime.Result{RequeueAfter: upgradeWaveCheckPeriod}, nil
+	}
 	return controllerruntime.Result{}, nil
 }
 
@@ -430,6 +534,26 @@ func (r *RepoSyncReconciler) SetupWithManager(mgr controllerruntime.Manager, wat
 	}); err != nil {
 		return err
 	}
+	// Index the `knownHostsSecretRefField` field, so that we will be able to lookup RepoSync be a referenced `knownHostsSecretRefField` name.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1beta1.RepoSync{}, knownHostsSecretRefField, func(rawObj client.Object) []string {
+		rs := rawObj.(*v1beta1.RepoSync)
+		if rs.Spec.Git == nil || v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef) == "" {
+			return nil
+		}
+		return []string{rs.Spec.Git.KnownHostsSecretRef.Name}
+	}); err != nil {
+		return err
+	}
+	// Index the `helmSecretRefName` field, so that we will be able to lookup RepoSync be a referenced `SecretRef` name.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1beta1.RepoSync{}, helmSecretRefField, func(rawObj client.Object) []string {
+		rs := rawObj.(*v1beta1.RepoSync)
+		if rs.Spec.Helm == nil || v1beta1.GetSecretName(rs.Spec.Helm.SecretRef) == "" {
+			return nil
+		}
+		return []string{rs.Spec.Helm.SecretRef.Name}
+	}); err != nil {
+		return err
+	}
 
 	controllerBuilder := controllerruntime.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
@@ -448,8 +572,17 @@ func (r *RepoSyncReconciler) SetupWithManager(mgr controllerruntime.Manager, wat
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Watches(&source.Kind{Type: &rbacv1.RoleBinding{}},
 			handler.EnqueueRequestsFromMapFunc(r.mapObjectToRepoSync),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapSyncDefaultsToRepoSyncs),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
 
+	if r.oneShotJobs {
+		controllerBuilder.Watches(&source.Kind{Type: &batchv1.Job{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapObjectToRepoSync),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+	}
+
 	if watchFleetMembership {
 		// Custom Watch for membership to trigger reconciliation.
 		controllerBuilder.Watches(&source.Kind{Type: &hubv1.Membership{}},
@@ -500,11 +633,21 @@ func (r *RepoSyncReconciler) requeueAllRepoSyncs() []reconcile.Request {
 		}
 	}
 	if len(requests) > 0 {
-		klog.Infof("Changes to membership trigger reconciliations for %d RepoSync objects.", len(allRepoSyncs.Items))
+		klog.Infof("Triggering reconciliations for %d RepoSync objects.", len(allRepoSyncs.Items))
 	}
 	return requests
 }
 
+// mapSyncDefaultsToRepoSyncs triggers a reconciliation of all the RepoSync
+// objects when the config-sync-defaults ConfigMap changes.
+func (r *RepoSyncReconciler) mapSyncDefaultsToRepoSyncs(cm client.Object) []reconcile.Request {
+	if !isSyncDefaultsConfigMap(cm) {
+		return nil
+	}
+	klog.Infof("Changes to ConfigMap %s/%s trigger reconciliations for all RepoSync objects", cm.GetNamespace(), cm.GetName())
+	return r.requeueAllRepoSyncs()
+}
+
 // mapSecretToRepoSyncs define a mapping from the Secret object to its attached
 // RepoSync objects via the `spec.git.secretRef.name` field .
 // The update to the Secret object will trigger a reconciliation of the RepoSync objects.
@@ -556,7 +699,7 @@ func (r *RepoSyncReconciler) mapSecretToRepoSyncs(secret client.Object) []reconc
 	// The user-managed ns-reconciler Secret might be shared among multiple RepoSync objects in the same namespace,
 	// so requeue all the attached RepoSync objects.
 	attachedRepoSyncs := &v1beta1.RepoSyncList{}
-	secretFields := []string{gitSecretRefField, caCertSecretRefField, helmSecretRefField}
+	secretFields := []string{gitSecretRefField, caCertSecretRefField, knownHostsSecretRefField, helmSecretRefField}
 	for _, secretField := range secretFields {
 		listOps := &client.ListOptions{
 			FieldSelector: fields.OneTermEqualSelector(secretField, secret.GetName()),
@@ -628,7 +771,7 @@ func (r *RepoSyncReconciler) mapObjectToRepoSync(obj client.Object) []reconcile.
 				})
 				attachedRSNames = append(attachedRSNames, rs.GetName())
 			}
-		default: // Deployment and ServiceAccount
+		default: // Deployment, Job and ServiceAccount
 			if obj.GetName() == reconcilerName {
 				return requeueRepoSyncRequest(obj, &rs)
 			}
@@ -666,8 +809,8 @@ func requeueRepoSyncRequest(obj client.Object, rs *v1beta1.RepoSync) []reconcile
 
 func (r *RepoSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RepoSync, reconcilerName string) map[string][]corev1.EnvVar {
 	result := map[string][]corev1.EnvVar{
-		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.Scope(rs.Namespace), reconcilerName, r.hydrationPollingPeriod.String()),
-		reconcilermanager.Reconciler:          reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.Scope(rs.Namespace), rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, reposync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)),
+		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.Scope(rs.Namespace), rs.Name, reconcilerName, r.hydrationPollingPeriod.String()),
+		reconcilermanager.Reconciler:          reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.Scope(rs.Namespace), rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, reposync.GetHelmBase(rs.Spec.Helm), reconcilerPollingPeriod(rs.Spec.SafeOverride().PollingPeriod, r.reconcilerPollingPeriod), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)),
 	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
@@ -681,16 +824,62 @@ func (r *RepoSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1be
 			depth:           rs.Spec.SafeOverride().GitSyncDepth,
 			noSSLVerify:     rs.Spec.Git.NoSSLVerify,
 			caCertSecretRef: v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef),
+			knownHosts:      useKnownHosts(rs.Spec.Git.Auth, v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef)),
 		})
 	case v1beta1.OciSource:
-		result[reconcilermanager.OciSync] = ociSyncEnvs(rs.Spec.Oci.Image, rs.Spec.Oci.Auth, v1beta1.GetPeriodSecs(rs.Spec.Oci.Period))
+		result[reconcilermanager.OciSync] = append(ociSyncEnvs(rs.Spec.Oci.Image, rs.Spec.Oci.Auth, v1beta1.GetPeriodSecs(rs.Spec.Oci.Period)), ociSyncWebhookEnvs(rs.Spec.Oci.Webhook)...)
+		result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], watchSourceEnvs(rs.Spec.Oci.Webhook)...)
 	case v1beta1.HelmSource:
 		result[reconcilermanager.HelmSync] = helmSyncEnvs(&rs.Spec.Helm.HelmBase, rs.Namespace, "")
 	}
+	if plugin := sourceExecCredential(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci); plugin != nil {
+		container := execCredentialContainer(rs.Spec.SourceType)
+		result[container] = append(result[container], execCredentialEnvs(plugin)...)
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], upgradeSettlePeriodEnvs(rs.Spec.SafeOverride().UpgradeSettlePeriod)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], supersedeInFlightApplyEnvs(rs.Spec.SafeOverride().SupersedeInFlightApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], partialApplyEnvs(rs.Spec.SafeOverride().PartialApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], commonMetadataEnvs(rs.Spec.SafeOverride().CommonLabels, rs.Spec.SafeOverride().CommonAnnotations)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneDelayEnvs(rs.Spec.SafeOverride().PruneDelay)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], approvalTimeoutEnvs(rs.Spec.SafeOverride().ApprovalTimeout)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], parseConcurrencyEnvs(rs.Spec.SafeOverride().ParseConcurrency)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], retryBudgetEnvs(rs.Spec.SafeOverride().RetryBudget)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], normalizeDeclarationsEnvs(rs.Spec.SafeOverride().NormalizeDeclarations)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], migrateClientSideApplyEnvs(rs.Spec.SafeOverride().MigrateClientSideApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneObsoleteMetadataEnvs(rs.Spec.SafeOverride().PruneObsoleteMetadata)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], statusUpdatePeriodEnvs(rs.Spec.SafeOverride().StatusUpdatePeriod)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], apiPriorityGroupEnvs(rs.Spec.SafeOverride().APIPriorityGroup)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], metricsLevelEnvs(rs.Spec.SafeOverride().Metrics)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneKindsEnvs(rs.Spec.Prune)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], rolloutEnvs(rs.Spec.Rollout)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], syncWindowsEnvs(rs.Spec.SyncWindows)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], dryRunEnvs(rs.Spec.DryRun)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], renamesEnvs(rs.Spec.Renames)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], converterEnvs(rs.Spec.Converter)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], exportSinkEnvs(r.exportSink)...)
 	return result
 }
 
 func (r *RepoSyncReconciler) validateSpec(ctx context.Context, rs *v1beta1.RepoSync, reconcilerName string) error {
+	if err := validate.SyncWindows(rs.Spec.SyncWindows, rs); err != nil {
+		return err
+	}
+	if err := validate.Renames(rs.Spec.Renames, rs); err != nil {
+		return err
+	}
+	if err := validate.Converter(rs.Spec.Converter, rs); err != nil {
+		return err
+	}
+	if rs.Spec.Converter != nil {
+		if err := r.validatePluginImage(rs, "spec.converter.image", rs.Spec.Converter.Image); err != nil {
+			return err
+		}
+	}
+	if plugin := sourceExecCredential(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci); plugin != nil {
+		if err := r.validatePluginImage(rs, fmt.Sprintf("spec.%s.execCredential.image", rs.Spec.SourceType), plugin.Image); err != nil {
+			return err
+		}
+	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		return r.validateGitSpec(ctx, rs, reconcilerName)
@@ -710,6 +899,9 @@ func (r *RepoSyncReconciler) validateGitSpec(ctx context.Context, rs *v1beta1.Re
 	if err := r.validateCACertSecret(ctx, rs.Namespace, v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef)); err != nil {
 		return err
 	}
+	if err := r.ensureKnownHostsSecret(ctx, rs.Namespace, rs.Spec.Git); err != nil {
+		return err
+	}
 	return r.validateNamespaceSecret(ctx, rs, reconcilerName)
 }
 
@@ -763,8 +955,12 @@ func (r *RepoSyncReconciler) upsertRoleBinding(ctx context.Context, reconcilerRe
 	childRB.Name = rbRef.Name
 	childRB.Namespace = rbRef.Namespace
 
+	roleKind := "ClusterRole"
+	if r.namespacedOnly {
+		roleKind = "Role"
+	}
 	op, err := controllerruntime.CreateOrUpdate(ctx, r.client, childRB, func() error {
-		childRB.RoleRef = rolereference(RepoSyncPermissionsName(), "ClusterRole")
+		childRB.RoleRef = rolereference(RepoSyncPermissionsName(), roleKind)
 		childRB.Subjects = addSubject(childRB.Subjects, r.serviceAccountSubject(reconcilerRef))
 		return nil
 	})
@@ -780,6 +976,45 @@ func (r *RepoSyncReconciler) upsertRoleBinding(ctx context.Context, reconcilerRe
 	return rbRef, nil
 }
 
+// upsertClusterRoleBindings adds the reconciler to the subjects of the
+// ClusterRoleBindings which grant the namespace reconcilers access to
+// cluster-scoped objects. Other kinds of subjects, e.g. the group of all the
+// service accounts in config-management-system bound by earlier versions, are
+// removed. They are skipped if the reconciler-manager can only manage
+// namespaced objects.
+func (r *RepoSyncReconciler) upsertClusterRoleBindings(ctx context.Context, reconcilerRef types.NamespacedName) (client.ObjectKey, error) {
+	if r.namespacedOnly {
+		return client.ObjectKey{}, nil
+	}
+	for _, name := range RepoSyncClusterPermissionsNames() {
+		crbRef := client.ObjectKey{Name: name}
+		childCRB := &rbacv1.ClusterRoleBinding{}
+		childCRB.Name = crbRef.Name
+
+		op, err := controllerruntime.CreateOrUpdate(ctx, r.client, childCRB, func() error {
+			childCRB.RoleRef = rolereference(name, "ClusterRole")
+			var subjects []rbacv1.Subject
+			for _, subject := range childCRB.Subjects {
+				if subject.Kind == kinds.ServiceAccount().Kind {
+					subjects = append(subjects, subject)
+				}
+			}
+			childCRB.Subjects = addSubject(subjects, r.serviceAccountSubject(reconcilerRef))
+			return nil
+		})
+		if err != nil {
+			return crbRef, err
+		}
+		if op != controllerutil.OperationResultNone {
+			r.log.Info("Managed object upsert successful",
+				logFieldObject, crbRef.String(),
+				logFieldKind, "ClusterRoleBinding",
+				logFieldOperation, op)
+		}
+	}
+	return client.ObjectKey{}, nil
+}
+
 func (r *RepoSyncReconciler) updateStatus(ctx context.Context, currentRS, rs *v1beta1.RepoSync) (bool, error) {
 	rs.Status.ObservedGeneration = rs.Generation
 
@@ -821,12 +1056,14 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 		var gcpSAEmail string
 		var secretRefName string
 		var caCertSecretRefName string
+		var knownHostsSecretRefName string
 		switch v1beta1.SourceType(rs.Spec.SourceType) {
 		case v1beta1.GitSource:
 			auth = rs.Spec.Auth
 			gcpSAEmail = rs.Spec.GCPServiceAccountEmail
 			secretRefName = v1beta1.GetSecretName(rs.Spec.SecretRef)
 			caCertSecretRefName = v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef)
+			knownHostsSecretRefName = v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef)
 		case v1beta1.OciSource:
 			auth = rs.Spec.Oci.Auth
 			gcpSAEmail = rs.Spec.Oci.GCPServiceAccountEmail
@@ -860,6 +1097,18 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 			caCertSecretRefName = ReconcilerResourceName(reconcilerName, caCertSecretRefName)
 		}
 		templateSpec.Volumes = filterVolumes(templateSpec.Volumes, auth, secretName, caCertSecretRefName, rs.Spec.SourceType, r.membership)
+		if useKnownHosts(auth, knownHostsSecretRefName) {
+			templateSpec.Volumes = append(templateSpec.Volumes, knownHostsVolume(ReconcilerResourceName(reconcilerName, knownHostsSecretRefName)))
+		}
+		execCredential := sourceExecCredential(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci)
+		if execCredential != nil {
+			templateSpec.Volumes = append(templateSpec.Volumes, credentialPluginVolume())
+			templateSpec.InitContainers = append(templateSpec.InitContainers, credentialPluginInitContainer(execCredential))
+		}
+		if rs.Spec.Converter != nil {
+			templateSpec.Volumes = append(templateSpec.Volumes, converterPluginVolume())
+			templateSpec.InitContainers = append(templateSpec.InitContainers, converterPluginInitContainer(rs.Spec.Converter))
+		}
 		var updatedContainers []corev1.Container
 		// Mutate spec.Containers to update name, configmap references and volumemounts.
 		for _, container := range templateSpec.Containers {
@@ -867,6 +1116,9 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 			switch container.Name {
 			case reconcilermanager.Reconciler:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				if rs.Spec.Converter != nil {
+					container.VolumeMounts = append(container.VolumeMounts, converterPluginVolumeMount(true))
+				}
 				mutateContainerResource(&container, rs.Spec.Override)
 			case reconcilermanager.HydrationController:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
@@ -882,6 +1134,9 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 					addContainer = false
 				} else {
 					container.Env = append(container.Env, containerEnvs[container.Name]...)
+					if rs.Spec.Oci.Webhook {
+						container.Ports = append(container.Ports, ociSyncWebhookContainerPort())
+					}
 					injectFWICredsToContainer(&container, injectFWICreds)
 					mutateContainerResource(&container, rs.Spec.Override)
 				}
@@ -906,6 +1161,9 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 					container.Env = append(container.Env, containerEnvs[container.Name]...)
 					// Don't mount git-creds volume if auth is 'none' or 'gcenode'.
 					container.VolumeMounts = volumeMounts(rs.Spec.Auth, caCertSecretRefName, rs.Spec.SourceType, container.VolumeMounts)
+					if useKnownHosts(auth, knownHostsSecretRefName) {
+						container.VolumeMounts = append(container.VolumeMounts, knownHostsVolumeMount())
+					}
 					// Update Environment variables for `token` Auth, which
 					// passes the credentials as the Username and Password.
 					if authTypeToken(rs.Spec.Auth) {
@@ -917,11 +1175,13 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 					mutateContainerResource(&container, rs.Spec.Override)
 				}
 			case metrics.OtelAgentName:
-				// The no-op case to avoid unknown container error after
-				// first-ever reconcile.
+				setOtelCollectorEndpoint(&container, rs)
 			default:
 				return errors.Errorf("unknown container in reconciler deployment template: %q", container.Name)
 			}
+			if execCredential != nil && container.Name == execCredentialContainer(rs.Spec.SourceType) {
+				container.VolumeMounts = append(container.VolumeMounts, credentialPluginVolumeMount(true))
+			}
 			if addContainer {
 				updatedContainers = append(updatedContainers, container)
 			}
@@ -26,6 +26,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -205,6 +206,18 @@ func reposyncOverrideAPIServerTimeout(apiServerTimout metav1.Duration) func(*v1b
 	}
 }
 
+func reposyncOverridePollingPeriod(pollingPeriod metav1.Duration) func(*v1beta1.RepoSync) {
+	return func(rs *v1beta1.RepoSync) {
+		rs.Spec.SafeOverride().PollingPeriod = &pollingPeriod
+	}
+}
+
+func reposyncOverrideStatusUpdatePeriod(statusUpdatePeriod metav1.Duration) func(*v1beta1.RepoSync) {
+	return func(rs *v1beta1.RepoSync) {
+		rs.Spec.SafeOverride().StatusUpdatePeriod = &statusUpdatePeriod
+	}
+}
+
 func reposyncNoSSLVerify() func(*v1beta1.RepoSync) {
 	return func(rs *v1beta1.RepoSync) {
 		rs.Spec.NoSSLVerify = true
@@ -1837,6 +1850,61 @@ func TestRepoSyncReconcilerRestart(t *testing.T) {
 // - rs3: "my-rs-3", namespace is videoinfo, auth type is gcpserviceaccount
 // - rs4: "my-rs-4", namespace is bookinfo, auth type is cookiefile with proxy
 // - rs5: "my-rs-5", namespace is bookinfo, auth type is token with proxy
+func TestRepoSyncClusterRoleBindings(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	rs1 := repoSync(reposyncNs, reposyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthSSH), reposyncSecretRef(reposyncSSHKey))
+	rs2 := repoSync("videoinfo", reposyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthSSH), reposyncSecretRef(reposyncSSHKey))
+	crbName := RepoSyncClusterPermissionsNames()[0]
+	// A binding left by an earlier version for the whole group of service
+	// accounts in config-management-system.
+	legacyCRB := fake.ClusterRoleBindingObject(core.Name(crbName))
+	legacyCRB.Subjects = []rbacv1.Subject{{
+		Kind:     rbacv1.GroupKind,
+		APIGroup: rbacv1.GroupName,
+		Name:     "system:serviceaccounts:" + configsync.ControllerNamespace,
+	}}
+	fakeClient, _, testReconciler := setupNSReconciler(t, rs1, rs2, legacyCRB,
+		secretObj(t, reposyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs1.Namespace)),
+		secretObj(t, reposyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs2.Namespace)))
+	nsReconcilerName1 := core.NsReconcilerName(rs1.Namespace, rs1.Name)
+	nsReconcilerName2 := core.NsReconcilerName(rs2.Namespace, rs2.Name)
+
+	ctx := context.Background()
+	_, err := testReconciler.Reconcile(ctx, namespacedName(rs1.Name, rs1.Namespace))
+	require.NoError(t, err)
+	_, err = testReconciler.Reconcile(ctx, namespacedName(rs2.Name, rs2.Namespace))
+	require.NoError(t, err)
+
+	crb := &rbacv1.ClusterRoleBinding{}
+	for _, name := range RepoSyncClusterPermissionsNames() {
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: name}, crb))
+		require.Equal(t, rolereference(name, "ClusterRole"), crb.RoleRef)
+		require.ElementsMatch(t, addSubjectByName(addSubjectByName(nil, nsReconcilerName1), nsReconcilerName2), crb.Subjects)
+	}
+
+	// The subject of rs1 is removed when rs1 is deleted.
+	rs1.ResourceVersion = "" // Skip ResourceVersion validation
+	require.NoError(t, fakeClient.Delete(ctx, rs1))
+	_, err = testReconciler.Reconcile(ctx, namespacedName(rs1.Name, rs1.Namespace))
+	require.NoError(t, err)
+	for _, name := range RepoSyncClusterPermissionsNames() {
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: name}, crb))
+		require.Equal(t, addSubjectByName(nil, nsReconcilerName2), crb.Subjects)
+	}
+
+	// The bindings are deleted with their last subject.
+	rs2.ResourceVersion = "" // Skip ResourceVersion validation
+	require.NoError(t, fakeClient.Delete(ctx, rs2))
+	_, err = testReconciler.Reconcile(ctx, namespacedName(rs2.Name, rs2.Namespace))
+	require.NoError(t, err)
+	for _, name := range RepoSyncClusterPermissionsNames() {
+		crb.Name = name
+		require.NoError(t, validateResourceDeleted(core.IDOf(crb), fakeClient))
+	}
+}
+
 func TestMultipleRepoSyncs(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -3423,6 +3491,7 @@ func TestPopulateRepoContainerEnvs(t *testing.T) {
 			reconcilermanager.ReconcilerNameKey:      nsReconcilerName,
 			reconcilermanager.ScopeKey:               reposyncNs,
 			reconcilermanager.SourceTypeKey:          string(gitSource),
+			reconcilermanager.SyncNameKey:            reposyncName,
 			reconcilermanager.SyncDirKey:             reposyncDir,
 		},
 		reconcilermanager.Reconciler: {
@@ -3492,6 +3561,14 @@ func TestPopulateRepoContainerEnvs(t *testing.T) {
 			repoSync: repoSync(reposyncNs, reposyncName, reposyncOverrideAPIServerTimeout(metav1.Duration{Duration: 40 * time.Second})),
 			expected: createEnv(map[string]map[string]string{reconcilermanager.Reconciler: {reconcilermanager.APIServerTimeout: "40s"}}),
 		},
+		{
+			name:     "override polling and status update periods",
+			repoSync: repoSync(reposyncNs, reposyncName, reposyncOverridePollingPeriod(metav1.Duration{Duration: time.Minute}), reposyncOverrideStatusUpdatePeriod(metav1.Duration{Duration: 30 * time.Second})),
+			expected: createEnv(map[string]map[string]string{reconcilermanager.Reconciler: {
+				reconcilermanager.ReconcilerPollingPeriod: "1m0s",
+				reconcilermanager.StatusUpdatePeriod:      "30s",
+			}}),
+		},
 	}
 
 	ctx := context.Background()
@@ -3521,9 +3598,14 @@ func validateRepoSyncStatus(t *testing.T, want *v1beta1.RepoSync, fakeClient *sy
 	require.NoError(t, err, "RepoSync[%s] not found", key)
 
 	asserter := testutil.NewAsserter(
-		cmpopts.IgnoreFields(v1beta1.RepoSyncCondition{}, "LastUpdateTime", "LastTransitionTime"))
+		cmpopts.IgnoreFields(v1beta1.RepoSyncCondition{}, "LastUpdateTime", "LastTransitionTime", "ObservedGeneration"))
 	// cmpopts.SortSlices(func(x, y v1beta1.RepoSyncCondition) bool { return x.Message < y.Message })
 	asserter.Equal(t, want.Status.Conditions, got.Status.Conditions, "Unexpected status conditions")
+	// The conditions set by the reconciler-manager are always for the
+	// current generation.
+	for _, condition := range got.Status.Conditions {
+		assert.Equal(t, got.Generation, condition.ObservedGeneration, "Unexpected observedGeneration of the %s condition", condition.Type)
+	}
 }
 
 func validateServiceAccounts(wants map[core.ID]*corev1.ServiceAccount, fakeClient *syncerFake.Client) error {
@@ -3756,3 +3838,127 @@ func newDeploymentCondition(condType appsv1.DeploymentConditionType, status core
 		Message:            message,
 	}
 }
+
+func TestRepoSyncExecCredentialImages(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	plugin := &v1beta1.ExecCredential{Image: "plugin-image", Command: "vend-token"}
+	testCases := []struct {
+		name         string
+		pluginImages []string
+		wantErr      bool
+	}{
+		{
+			name:    "no plugin image is allowed",
+			wantErr: true,
+		},
+		{
+			name:         "other plugin images are allowed",
+			pluginImages: []string{"other-image"},
+			wantErr:      true,
+		},
+		{
+			name:         "plugin image is allowed",
+			pluginImages: []string{"other-image", plugin.Image},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := repoSync(reposyncNs, reposyncName, reposyncSecretType(configsync.AuthExec), func(rs *v1beta1.RepoSync) {
+				rs.Spec.Git.ExecCredential = plugin
+			})
+			fakeClient, fakeDynamicClient, testReconciler := setupNSReconciler(t, rs)
+			testReconciler.SetPluginImages(tc.pluginImages)
+			ctx := context.Background()
+			if _, err := testReconciler.Reconcile(ctx, namespacedName(rs.Name, rs.Namespace)); err != nil {
+				t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+			}
+
+			_, err := fakeDynamicClient.Resource(kinds.DeploymentResource()).
+				Namespace(v1.NSConfigManagementSystem).
+				Get(ctx, nsReconcilerName, metav1.GetOptions{})
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsNotFound(err), "got error %v, want NotFound", err)
+			wantRs := fake.RepoSyncObjectV1Beta1(reposyncNs, reposyncName)
+			reposync.SetStalled(wantRs, "Validation", validate.DisallowedPluginImage(rs, "spec.git.execCredential.image", plugin.Image))
+			validateRepoSyncStatus(t, wantRs, fakeClient)
+		})
+	}
+}
+
+func TestRepoSyncWithConverter(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	plugin := &v1beta1.Converter{Image: "plugin-image", Command: "dsl2krm", Extensions: []string{".dsl"}}
+	testCases := []struct {
+		name         string
+		pluginImages []string
+		wantErr      bool
+	}{
+		{
+			name:    "no plugin image is allowed",
+			wantErr: true,
+		},
+		{
+			name:         "plugin image is allowed",
+			pluginImages: []string{plugin.Image},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := repoSyncWithOCI(reposyncNs, reposyncName, reposyncOCIAuthType(configsync.AuthNone), func(rs *v1beta1.RepoSync) {
+				rs.Spec.Converter = plugin
+			})
+			fakeClient, fakeDynamicClient, testReconciler := setupNSReconciler(t, rs)
+			testReconciler.SetPluginImages(tc.pluginImages)
+			ctx := context.Background()
+			if _, err := testReconciler.Reconcile(ctx, namespacedName(rs.Name, rs.Namespace)); err != nil {
+				t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+			}
+
+			uObj, err := fakeDynamicClient.Resource(kinds.DeploymentResource()).
+				Namespace(v1.NSConfigManagementSystem).
+				Get(ctx, nsReconcilerName, metav1.GetOptions{})
+			if tc.wantErr {
+				require.True(t, apierrors.IsNotFound(err), "got error %v, want NotFound", err)
+				wantRs := fake.RepoSyncObjectV1Beta1(reposyncNs, reposyncName)
+				reposync.SetStalled(wantRs, "Validation", validate.DisallowedPluginImage(rs, "spec.converter.image", plugin.Image))
+				validateRepoSyncStatus(t, wantRs, fakeClient)
+				return
+			}
+			require.NoError(t, err)
+			obj, err := kinds.ToTypedObject(uObj, core.Scheme)
+			require.NoError(t, err)
+			podSpec := obj.(*appsv1.Deployment).Spec.Template.Spec
+
+			if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Image != plugin.Image {
+				t.Errorf("got init containers %v, want the %s init container", podSpec.InitContainers, reconcilermanager.ConverterPlugin)
+			}
+			hasVolume := false
+			for _, v := range podSpec.Volumes {
+				hasVolume = hasVolume || v.Name == ConverterPluginVolume
+			}
+			if !hasVolume {
+				t.Errorf("missing the %s volume", ConverterPluginVolume)
+			}
+			for _, c := range podSpec.Containers {
+				hasMount := false
+				for _, vm := range c.VolumeMounts {
+					hasMount = hasMount || vm.Name == ConverterPluginVolume
+				}
+				hasEnv := false
+				for _, env := range c.Env {
+					hasEnv = hasEnv || env.Name == reconcilermanager.Converter
+				}
+				if want := c.Name == reconcilermanager.Reconciler; hasMount != want || hasEnv != want {
+					t.Errorf("container %s has the plugin volume mount %t and env %t, want %t", c.Name, hasMount, hasEnv, want)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -245,16 +247,59 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "ClusterRoleBinding reconcile failed")
 	}
 
-	containerEnvs := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
-	mut := r.mutationsFor(ctx, rs, containerEnvs)
+	// Overwrite or remove the registry webhook receiver Service.
+	ociWebhook := v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.OciSource && rs.Spec.Oci != nil && rs.Spec.Oci.Webhook
+	if svcRef, err := r.reconcileOciWebhookService(ctx, reconcilerRef, ociWebhook, labelMap, owRefs); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, svcRef.String(),
+			logFieldKind, "Service")
+		rootsync.SetStalled(rs, "Service", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "Service reconcile failed")
+	}
 
-	// Upsert Root reconciler deployment.
-	deployObj, op, err := r.upsertDeployment(ctx, reconcilerRef, labelMap, mut)
+	override, err := r.overrideWithDefaults(ctx, rs.Spec.Override)
+	if err != nil {
+		log.Error(err, "Failed to read the override defaults",
+			logFieldObject, rsRef.String(),
+			logFieldKind, r.syncKind)
+		rootsync.SetStalled(rs, "ConfigMap", err)
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "override defaults reconcile failed")
+	}
+	// Render the reconciler Deployment from a copy, so the fleet defaults are
+	// never written back into the RootSync spec.
+	rsWithDefaults := rs.DeepCopy()
+	rsWithDefaults.Spec.Override = override
+
+	containerEnvs := r.populateContainerEnvs(ctx, rsWithDefaults, reconcilerRef.Name)
+	mut := r.mutationsFor(ctx, rsWithDefaults, containerEnvs)
+	// Hold back reconciler image upgrades according to the upgrade policy.
+	var upgradeHeld bool
+	mut = r.holdUpgrade(ctx, rsRef, mut, &upgradeHeld)
+
+	// Upsert Root reconciler deployment, or job for one-shot syncs.
+	deployObj, workloadKind, op, err := r.upsertWorkload(ctx, reconcilerRef, labelMap, mut, r.runAsJob(rs.Spec.Mode))
 	if err != nil {
 		log.Error(err, "Managed object upsert failed",
 			logFieldObject, reconcilerRef.String(),
-			logFieldKind, "Deployment")
-		rootsync.SetStalled(rs, "Deployment", err)
+			logFieldKind, workloadKind)
+		rootsync.SetStalled(rs, workloadKind, err)
 		// Upsert errors should always trigger retry (return error),
 		// even if status update is successful.
 		_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -265,19 +310,19 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		}
 		// Use the upsert error for metric tagging.
 		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
-		return controllerruntime.Result{}, errors.Wrap(err, "Deployment reconcile failed")
+		return controllerruntime.Result{}, errors.Wrapf(err, "%s reconcile failed", workloadKind)
 	}
 	rs.Status.Reconciler = reconcilerRef.Name
 
 	// Get the latest deployment to check the status.
-	// For other operations, upsertDeployment will have returned the latest already.
+	// For other operations, upsertWorkload will have returned the latest already.
 	if op == controllerutil.OperationResultNone {
-		deployObj, err = r.deployment(ctx, reconcilerRef)
+		deployObj, err = r.workload(ctx, workloadKind, reconcilerRef)
 		if err != nil {
 			log.Error(err, "Managed object get failed",
 				logFieldObject, reconcilerRef.String(),
-				logFieldKind, "Deployment")
-			rootsync.SetStalled(rs, "Deployment", err)
+				logFieldKind, workloadKind)
+			rootsync.SetStalled(rs, workloadKind, err)
 			// Get errors should always trigger retry (return error),
 			// even if status update is successful.
 			_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -294,8 +339,8 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 	if err != nil {
 		log.Error(err, "Managed object status check failed",
 			logFieldObject, reconcilerRef.String(),
-			logFieldKind, "Deployment")
-		rootsync.SetStalled(rs, "Deployment", err)
+			logFieldKind, workloadKind)
+		rootsync.SetStalled(rs, workloadKind, err)
 		// Get errors should always trigger retry (return error),
 		// even if status update is successful.
 		_, updateErr := r.updateStatus(ctx, currentRS, rs)
@@ -307,8 +352,9 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, err
 	}
 
-	log.V(3).Info("RootSync reconciler Deployment status",
+	log.V(3).Info("RootSync reconciler workload status",
 		logFieldObject, reconcilerRef.String(),
+		logFieldKind, workloadKind,
 		"resourceVersion", deployObj.GetResourceVersion(),
 		"status", result.Status,
 		"message", result.Message)
@@ -318,18 +364,19 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 	case kstatus.InProgressStatus:
 		// inProgressStatus indicates that the deployment is not yet
 		// available. Hence update the Reconciling status condition.
-		rootsync.SetReconciling(rs, "Deployment", result.Message)
+		rootsync.SetReconciling(rs, workloadKind, result.Message)
 		// Clear Stalled condition.
 		rootsync.ClearCondition(rs, v1beta1.RootSyncStalled)
 	case kstatus.FailedStatus:
 		// statusFailed indicates that the deployment failed to reconcile. Update
 		// Reconciling status condition with appropriate message specifying the
 		// reason for failure.
-		rootsync.SetReconciling(rs, "Deployment", result.Message)
+		rootsync.SetReconciling(rs, workloadKind, result.Message)
 		// Set Stalled condition with the deployment statusFailed.
-		rootsync.SetStalled(rs, "Deployment", errors.New(string(result.Status)))
+		rootsync.SetStalled(rs, workloadKind, errors.New(string(result.Status)))
 	case kstatus.CurrentStatus:
-		// currentStatus indicates that the deployment is available, which qualifies
+		// currentStatus indicates that the deployment is available, or that the
+		// job is complete, which qualifies
 		// to clear the Reconciling status condition in RootSync.
 		rootsync.ClearCondition(rs, v1beta1.RootSyncReconciling)
 		// Since there were no errors, we can clear any previous Stalled condition.
@@ -348,6 +395,10 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 			logFieldObject, rsRef.String(),
 			logFieldKind, r.syncKind)
 	}
+	if upgradeHeld {
+		// Check again later whether the upgrade can proceed.
+		return controllerruntime.Result{RequeueAfter: upgradeWaveCheckPeriod}, nil
+	}
 	return controllerruntime.Result{}, nil
 }
 
@@ -372,8 +423,15 @@ func (r *RootSyncReconciler) SetupWithManager(mgr controllerruntime.Manager, wat
 		Owns(&appsv1.Deployment{}).
 		Watches(&source.Kind{Type: &corev1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(r.mapSecretToRootSyncs),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapSyncDefaultsToRootSyncs),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
 
+	if r.oneShotJobs {
+		controllerBuilder.Owns(&batchv1.Job{})
+	}
+
 	if watchFleetMembership {
 		// Custom Watch for membership to trigger reconciliation.
 		controllerBuilder.Watches(&source.Kind{Type: &hubv1.Membership{}},
@@ -424,11 +482,21 @@ func (r *RootSyncReconciler) requeueAllRootSyncs() []reconcile.Request {
 		}
 	}
 	if len(requests) > 0 {
-		klog.Infof("Changes to membership trigger reconciliations for %d RootSync objects.", len(allRootSyncs.Items))
+		klog.Infof("Triggering reconciliations for %d RootSync objects.", len(allRootSyncs.Items))
 	}
 	return requests
 }
 
+// mapSyncDefaultsToRootSyncs triggers a reconciliation of all the RootSync
+// objects when the config-sync-defaults ConfigMap changes.
+func (r *RootSyncReconciler) mapSyncDefaultsToRootSyncs(cm client.Object) []reconcile.Request {
+	if !isSyncDefaultsConfigMap(cm) {
+		return nil
+	}
+	klog.Infof("Changes to ConfigMap %s/%s trigger reconciliations for all RootSync objects", cm.GetNamespace(), cm.GetName())
+	return r.requeueAllRootSyncs()
+}
+
 // mapSecretToRootSyncs define a mapping from the Secret object to its attached
 // RootSync objects via the `spec.git.secretRef.name` field .
 // The update to the Secret object will trigger a reconciliation of the RootSync objects.
@@ -473,8 +541,8 @@ func (r *RootSyncReconciler) mapSecretToRootSyncs(secret client.Object) []reconc
 
 func (r *RootSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RootSync, reconcilerName string) map[string][]corev1.EnvVar {
 	result := map[string][]corev1.EnvVar{
-		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.RootReconciler, reconcilerName, r.hydrationPollingPeriod.String()),
-		reconcilermanager.Reconciler:          append(reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.RootReconciler, rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, rootsync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)), sourceFormatEnv(rs.Spec.SourceFormat)),
+		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.RootReconciler, rs.Name, reconcilerName, r.hydrationPollingPeriod.String()),
+		reconcilermanager.Reconciler:          append(reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.RootReconciler, rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, rootsync.GetHelmBase(rs.Spec.Helm), reconcilerPollingPeriod(rs.Spec.SafeOverride().PollingPeriod, r.reconcilerPollingPeriod), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)), sourceFormatEnv(rs.Spec.SourceFormat)),
 	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
@@ -488,16 +556,70 @@ func (r *RootSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1be
 			depth:           rs.Spec.SafeOverride().GitSyncDepth,
 			noSSLVerify:     rs.Spec.Git.NoSSLVerify,
 			caCertSecretRef: v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef),
+			knownHosts:      useKnownHosts(rs.Spec.Git.Auth, v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef)),
 		})
 	case v1beta1.OciSource:
-		result[reconcilermanager.OciSync] = ociSyncEnvs(rs.Spec.Oci.Image, rs.Spec.Oci.Auth, v1beta1.GetPeriodSecs(rs.Spec.Oci.Period))
+		result[reconcilermanager.OciSync] = append(ociSyncEnvs(rs.Spec.Oci.Image, rs.Spec.Oci.Auth, v1beta1.GetPeriodSecs(rs.Spec.Oci.Period)), ociSyncWebhookEnvs(rs.Spec.Oci.Webhook)...)
+		result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], watchSourceEnvs(rs.Spec.Oci.Webhook)...)
 	case v1beta1.HelmSource:
 		result[reconcilermanager.HelmSync] = helmSyncEnvs(&rs.Spec.Helm.HelmBase, rs.Spec.Helm.Namespace, rs.Spec.Helm.DeployNamespace)
 	}
+	if plugin := sourceExecCredential(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci); plugin != nil {
+		container := execCredentialContainer(rs.Spec.SourceType)
+		result[container] = append(result[container], execCredentialEnvs(plugin)...)
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], upgradeSettlePeriodEnvs(rs.Spec.SafeOverride().UpgradeSettlePeriod)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], supersedeInFlightApplyEnvs(rs.Spec.SafeOverride().SupersedeInFlightApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], partialApplyEnvs(rs.Spec.SafeOverride().PartialApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], commonMetadataEnvs(rs.Spec.SafeOverride().CommonLabels, rs.Spec.SafeOverride().CommonAnnotations)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneDelayEnvs(rs.Spec.SafeOverride().PruneDelay)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], approvalTimeoutEnvs(rs.Spec.SafeOverride().ApprovalTimeout)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], parseConcurrencyEnvs(rs.Spec.SafeOverride().ParseConcurrency)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], retryBudgetEnvs(rs.Spec.SafeOverride().RetryBudget)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], normalizeDeclarationsEnvs(rs.Spec.SafeOverride().NormalizeDeclarations)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], migrateClientSideApplyEnvs(rs.Spec.SafeOverride().MigrateClientSideApply)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneObsoleteMetadataEnvs(rs.Spec.SafeOverride().PruneObsoleteMetadata)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], selfUpdateTimeoutEnvs(rs.Spec.SafeOverride().SelfUpdateTimeout)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], statusUpdatePeriodEnvs(rs.Spec.SafeOverride().StatusUpdatePeriod)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], apiPriorityGroupEnvs(rs.Spec.SafeOverride().APIPriorityGroup)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], metricsLevelEnvs(rs.Spec.SafeOverride().Metrics)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneKindsEnvs(rs.Spec.Prune)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], rolloutEnvs(rs.Spec.Rollout)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], syncWindowsEnvs(rs.Spec.SyncWindows)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], dryRunEnvs(rs.Spec.DryRun)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], renamesEnvs(rs.Spec.Renames)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], converterEnvs(rs.Spec.Converter)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], exportSinkEnvs(r.exportSink)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], hierarchicalDirsEnvs(rs.Spec.HierarchicalDirs)...)
+	if rs.Spec.TargetKubeconfigSecretName() != "" {
+		result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], corev1.EnvVar{
+			Name:  reconcilermanager.TargetKubeconfig,
+			Value: path.Join(TargetKubeconfigPath, TargetKubeconfigSecretKey),
+		})
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], syncTargetsEnv(rs.Spec.Targets)...)
 	return result
 }
 
 func (r *RootSyncReconciler) validateSpec(ctx context.Context, rs *v1beta1.RootSync) error {
+	if err := r.validateTargetKubeconfigSecret(ctx, rs); err != nil {
+		return err
+	}
+	if err := r.validateSyncTargets(ctx, rs); err != nil {
+		return err
+	}
+	if err := validate.HierarchicalDirs(rs.Spec.SourceFormat, rs.Spec.HierarchicalDirs, rs); err != nil {
+		return err
+	}
+	if err := validate.SyncWindows(rs.Spec.SyncWindows, rs); err != nil {
+		return err
+	}
+	if err := validate.Renames(rs.Spec.Renames, rs); err != nil {
+		return err
+	}
+	if err := validate.Converter(rs.Spec.Converter, rs); err != nil {
+		return err
+	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		return r.validateGitSpec(ctx, rs)
@@ -523,9 +645,38 @@ func (r *RootSyncReconciler) validateGitSpec(ctx context.Context, rs *v1beta1.Ro
 	if err := r.validateCACertSecret(ctx, rs.Namespace, v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef)); err != nil {
 		return err
 	}
+	if err := r.ensureKnownHostsSecret(ctx, rs.Namespace, rs.Spec.Git); err != nil {
+		return err
+	}
 	return r.validateRootSecret(ctx, rs)
 }
 
+// validateTargetKubeconfigSecret verifies that the kubeconfig Secret of the
+// target cluster is present, if the RootSync syncs to a remote cluster.
+func (r *RootSyncReconciler) validateTargetKubeconfigSecret(ctx context.Context, rs *v1beta1.RootSync) error {
+	secretName := rs.Spec.TargetKubeconfigSecretName()
+	if secretName == "" {
+		return nil
+	}
+	return r.validateKubeconfigSecret(ctx, rs.Namespace, secretName, "target.kubeconfigSecretRef")
+}
+
+// validateKubeconfigSecret verifies that the kubeconfig Secret referenced by
+// the field is present, and holds a kubeconfig.
+func (r *RootSyncReconciler) validateKubeconfigSecret(ctx context.Context, namespace, secretName, field string) error {
+	secret, err := validateSecretExist(ctx, secretName, namespace, r.client)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return errors.Errorf("Secret %s not found, create one to allow syncing to the target cluster", secretName)
+		}
+		return errors.Wrapf(err, "Secret %s get failed", secretName)
+	}
+	if _, ok := secret.Data[TargetKubeconfigSecretKey]; !ok {
+		return fmt.Errorf("%s was set, but %s key is not present in %s Secret", field, TargetKubeconfigSecretKey, secretName)
+	}
+	return nil
+}
+
 func (r *RootSyncReconciler) validateNamespaceName(namespaceName string) error {
 	if namespaceName != configsync.ControllerNamespace {
 		return fmt.Errorf("RootSync objects are only allowed in the %s namespace, not in %s", configsync.ControllerNamespace, namespaceName)
@@ -625,12 +776,14 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 		var gcpSAEmail string
 		var secretRefName string
 		var caCertSecretRefName string
+		var knownHostsSecretRefName string
 		switch v1beta1.SourceType(rs.Spec.SourceType) {
 		case v1beta1.GitSource:
 			auth = rs.Spec.Auth
 			gcpSAEmail = rs.Spec.GCPServiceAccountEmail
 			secretRefName = v1beta1.GetSecretName(rs.Spec.SecretRef)
 			caCertSecretRefName = v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef)
+			knownHostsSecretRefName = v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef)
 		case v1beta1.OciSource:
 			auth = rs.Spec.Oci.Auth
 			gcpSAEmail = rs.Spec.Oci.GCPServiceAccountEmail
@@ -662,6 +815,23 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 		// authenticate with the git or helm repository using the authorization method specified
 		// in the RootSync CR.
 		templateSpec.Volumes = filterVolumes(templateSpec.Volumes, auth, secretRefName, caCertSecretRefName, rs.Spec.SourceType, r.membership)
+		if useKnownHosts(auth, knownHostsSecretRefName) {
+			templateSpec.Volumes = append(templateSpec.Volumes, knownHostsVolume(knownHostsSecretRefName))
+		}
+		targetKubeconfigSecretName := rs.Spec.TargetKubeconfigSecretName()
+		if targetKubeconfigSecretName != "" {
+			templateSpec.Volumes = append(templateSpec.Volumes, targetKubeconfigVolume(targetKubeconfigSecretName))
+		}
+		templateSpec.Volumes = append(templateSpec.Volumes, syncTargetVolumes(rs.Spec.Targets)...)
+		execCredential := sourceExecCredential(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci)
+		if execCredential != nil {
+			templateSpec.Volumes = append(templateSpec.Volumes, credentialPluginVolume())
+			templateSpec.InitContainers = append(templateSpec.InitContainers, credentialPluginInitContainer(execCredential))
+		}
+		if rs.Spec.Converter != nil {
+			templateSpec.Volumes = append(templateSpec.Volumes, converterPluginVolume())
+			templateSpec.InitContainers = append(templateSpec.InitContainers, converterPluginInitContainer(rs.Spec.Converter))
+		}
 
 		var updatedContainers []corev1.Container
 
@@ -670,6 +840,13 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 			switch container.Name {
 			case reconcilermanager.Reconciler:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				if targetKubeconfigSecretName != "" {
+					container.VolumeMounts = append(container.VolumeMounts, targetKubeconfigVolumeMount())
+				}
+				container.VolumeMounts = append(container.VolumeMounts, syncTargetVolumeMounts(rs.Spec.Targets)...)
+				if rs.Spec.Converter != nil {
+					container.VolumeMounts = append(container.VolumeMounts, converterPluginVolumeMount(true))
+				}
 				mutateContainerResource(&container, rs.Spec.Override)
 			case reconcilermanager.HydrationController:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
@@ -685,6 +862,9 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 					addContainer = false
 				} else {
 					container.Env = append(container.Env, containerEnvs[container.Name]...)
+					if rs.Spec.Oci.Webhook {
+						container.Ports = append(container.Ports, ociSyncWebhookContainerPort())
+					}
 					injectFWICredsToContainer(&container, injectFWICreds)
 					mutateContainerResource(&container, rs.Spec.Override)
 				}
@@ -709,6 +889,9 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 					container.Env = append(container.Env, containerEnvs[container.Name]...)
 					// Don't mount git-creds volume if auth is 'none' or 'gcenode'.
 					container.VolumeMounts = volumeMounts(rs.Spec.Auth, caCertSecretRefName, rs.Spec.SourceType, container.VolumeMounts)
+					if useKnownHosts(auth, knownHostsSecretRefName) {
+						container.VolumeMounts = append(container.VolumeMounts, knownHostsVolumeMount())
+					}
 					// Update Environment variables for `token` Auth, which
 					// passes the credentials as the Username and Password.
 					secretName := v1beta1.GetSecretName(rs.Spec.SecretRef)
@@ -721,11 +904,13 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 					mutateContainerResource(&container, rs.Spec.Override)
 				}
 			case metrics.OtelAgentName:
-				// The no-op case to avoid unknown container error after
-				// first-ever reconcile.
+				setOtelCollectorEndpoint(&container, rs)
 			default:
 				return errors.Errorf("unknown container in reconciler deployment template: %q", container.Name)
 			}
+			if execCredential != nil && container.Name == execCredentialContainer(rs.Spec.SourceType) {
+				container.VolumeMounts = append(container.VolumeMounts, credentialPluginVolumeMount(true))
+			}
 			if addContainer {
 				updatedContainers = append(updatedContainers, container)
 			}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -199,6 +200,18 @@ func rootsyncOverrideAPIServerTimeout(apiServerTimout metav1.Duration) func(*v1b
 	}
 }
 
+func rootsyncOverridePollingPeriod(pollingPeriod metav1.Duration) func(*v1beta1.RootSync) {
+	return func(rs *v1beta1.RootSync) {
+		rs.Spec.SafeOverride().PollingPeriod = &pollingPeriod
+	}
+}
+
+func rootsyncOverrideStatusUpdatePeriod(statusUpdatePeriod metav1.Duration) func(*v1beta1.RootSync) {
+	return func(rs *v1beta1.RootSync) {
+		rs.Spec.SafeOverride().StatusUpdatePeriod = &statusUpdatePeriod
+	}
+}
+
 func rootsyncNoSSLVerify() func(*v1beta1.RootSync) {
 	return func(rs *v1beta1.RootSync) {
 		rs.Spec.Git.NoSSLVerify = true
@@ -211,6 +224,12 @@ func rootsyncCACert(caCertSecretRef string) func(*v1beta1.RootSync) {
 	}
 }
 
+func rootsyncTargetKubeconfig(secretRef string) func(*v1beta1.RootSync) {
+	return func(rs *v1beta1.RootSync) {
+		rs.Spec.Target = &v1beta1.Target{KubeconfigSecretRef: &v1beta1.SecretReference{Name: secretRef}}
+	}
+}
+
 func rootSync(name string, opts ...func(*v1beta1.RootSync)) *v1beta1.RootSync {
 	rs := fake.RootSyncObjectV1Beta1(name)
 	rs.Spec.SourceType = string(v1beta1.GitSource)
@@ -931,6 +950,62 @@ func TestRootSyncWithoutCACertSecret(t *testing.T) {
 	require.Equal(t, fmt.Sprintf("Secret %s not found, create one to allow client connections with CA certificate", caCertSecret), err.Error(), "unexpected function error")
 }
 
+func TestRootSyncCreateWithTargetKubeconfig(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+	kubeconfigSecret := "target-cluster"
+	rs := rootSync(rootsyncName, rootsyncRef(gitRevision), rootsyncBranch(branch), rootsyncSecretType(GitSecretConfigKeySSH),
+		rootsyncSecretRef(rootsyncSSHKey), rootsyncTargetKubeconfig(kubeconfigSecret))
+	reqNamespacedName := namespacedName(rs.Name, rs.Namespace)
+	targetSecret := secretObj(t, kubeconfigSecret, configsync.AuthNone, v1beta1.GitSource, core.Namespace(rs.Namespace))
+	targetSecret.Data = map[string][]byte{TargetKubeconfigSecretKey: []byte("test-data")}
+	_, fakeDynamicClient, testReconciler := setupRootReconciler(t, rs, targetSecret,
+		secretObj(t, rootsyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs.Namespace)))
+
+	// Test creating Deployment resources.
+	ctx := context.Background()
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+
+	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	if !containsEnv(rootContainerEnvs[reconcilermanager.Reconciler], reconcilermanager.TargetKubeconfig, TargetKubeconfigPath+"/"+TargetKubeconfigSecretKey) {
+		t.Errorf("expected the %s env var in the reconciler container, got: %v", reconcilermanager.TargetKubeconfig, rootContainerEnvs[reconcilermanager.Reconciler])
+	}
+
+	rootDeployment := rootSyncDeployment(rootReconcilerName,
+		setServiceAccountName(rootReconcilerName),
+		secretMutator(rootsyncSSHKey),
+		targetKubeconfigMutator(kubeconfigSecret),
+		containerEnvMutator(rootContainerEnvs),
+		setUID("1"), setResourceVersion("1"), setGeneration(1),
+	)
+	wantDeployments := map[core.ID]*appsv1.Deployment{core.IDOf(rootDeployment): rootDeployment}
+
+	if err := validateDeployments(wantDeployments, fakeDynamicClient); err != nil {
+		t.Errorf("Deployment validation failed. err: %v", err)
+	}
+}
+
+func TestRootSyncReconcileWithoutTargetKubeconfigSecret(t *testing.T) {
+	kubeconfigSecret := "target-cluster"
+	rs := rootSync(rootsyncName, rootsyncRef(gitRevision), rootsyncBranch(branch), rootsyncSecretType(GitSecretConfigKeySSH),
+		rootsyncSecretRef(rootsyncSSHKey), rootsyncTargetKubeconfig(kubeconfigSecret))
+	reqNamespacedName := namespacedName(rs.Name, rs.Namespace)
+	fakeClient, _, testReconciler := setupRootReconciler(t, rs,
+		secretObj(t, rootsyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs.Namespace)))
+
+	ctx := context.Background()
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+
+	// rootsync should be in stalled status
+	wantRs := fake.RootSyncObjectV1Beta1(rootsyncName)
+	rootsync.SetStalled(wantRs, "Validation", fmt.Errorf("Secret %s not found, create one to allow syncing to the target cluster", kubeconfigSecret))
+	validateRootSyncStatus(t, wantRs, fakeClient)
+}
+
 func TestRootSyncCreateWithOverrideGitSyncDepth(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -963,6 +1038,59 @@ func TestRootSyncCreateWithOverrideGitSyncDepth(t *testing.T) {
 	t.Log("Deployment successfully created")
 }
 
+func TestRootSyncOverrideEnvs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		override *v1beta1.OverrideSpec
+		wantEnv  corev1.EnvVar
+	}{
+		{
+			name:     "selfUpdateTimeout",
+			override: &v1beta1.OverrideSpec{SelfUpdateTimeout: &metav1.Duration{Duration: 5 * time.Minute}},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.SelfUpdateTimeout, Value: "5m0s"},
+		},
+		{
+			name:     "pruneObsoleteMetadata",
+			override: &v1beta1.OverrideSpec{PruneObsoleteMetadata: pointer.Bool(true)},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.PruneObsoleteMetadata, Value: "true"},
+		},
+		{
+			name:     "migrateClientSideApply",
+			override: &v1beta1.OverrideSpec{MigrateClientSideApply: pointer.Bool(true)},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.MigrateClientSideApply, Value: "true"},
+		},
+		{
+			name:     "normalizeDeclarations",
+			override: &v1beta1.OverrideSpec{NormalizeDeclarations: pointer.Bool(true)},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.NormalizeDeclarations, Value: "true"},
+		},
+		{
+			name:     "retryBudget",
+			override: &v1beta1.OverrideSpec{RetryBudget: pointer.Int64(3)},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.RetryBudget, Value: "3"},
+		},
+		{
+			name:     "parseConcurrency",
+			override: &v1beta1.OverrideSpec{ParseConcurrency: pointer.Int64(4)},
+			wantEnv:  corev1.EnvVar{Name: reconcilermanager.ParseConcurrency, Value: "4"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := rootSync(rootsyncName, rootsyncRef(gitRevision), rootsyncBranch(branch), rootsyncSecretType(GitSecretConfigKeySSH), rootsyncSecretRef(rootsyncSSHKey))
+			_, _, testReconciler := setupRootReconciler(t, rs, secretObj(t, rootsyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs.Namespace)))
+			ctx := context.Background()
+
+			envs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+			require.NotContains(t, envs[reconcilermanager.Reconciler], tc.wantEnv, "the setting should be off by default")
+
+			rs.Spec.Override = tc.override
+			envs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+			require.Contains(t, envs[reconcilermanager.Reconciler], tc.wantEnv)
+		})
+	}
+}
+
 func TestRootSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -2442,6 +2570,136 @@ func TestRootSyncWithHelm(t *testing.T) {
 	t.Log("Deployment successfully updated")
 }
 
+func TestRootSyncWithExecCredential(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	plugin := &v1beta1.ExecCredential{Image: "plugin-image", Command: "vend-token"}
+	testCases := []struct {
+		name           string
+		rs             *v1beta1.RootSync
+		pluginUser     string
+		wantGitAskpass bool
+	}{
+		{
+			name: "git",
+			rs: rootSync(rootsyncName, rootsyncSecretType(configsync.AuthExec), func(rs *v1beta1.RootSync) {
+				rs.Spec.Git.ExecCredential = plugin
+			}),
+			pluginUser:     reconcilermanager.Reconciler,
+			wantGitAskpass: true,
+		},
+		{
+			name: "oci",
+			rs: rootSyncWithOCI(rootsyncName, rootsyncOCIAuthType(configsync.AuthExec), func(rs *v1beta1.RootSync) {
+				rs.Spec.Oci.ExecCredential = plugin
+			}),
+			pluginUser: reconcilermanager.OciSync,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, fakeDynamicClient, testReconciler := setupRootReconciler(t, tc.rs)
+			ctx := context.Background()
+			if _, err := testReconciler.Reconcile(ctx, namespacedName(tc.rs.Name, tc.rs.Namespace)); err != nil {
+				t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+			}
+
+			uObj, err := fakeDynamicClient.Resource(kinds.DeploymentResource()).
+				Namespace(v1.NSConfigManagementSystem).
+				Get(ctx, rootReconcilerName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj, err := kinds.ToTypedObject(uObj, core.Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			podSpec := obj.(*appsv1.Deployment).Spec.Template.Spec
+
+			if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Image != plugin.Image {
+				t.Errorf("got init containers %v, want the %s init container", podSpec.InitContainers, reconcilermanager.CredentialPlugin)
+			}
+			hasVolume := false
+			for _, v := range podSpec.Volumes {
+				hasVolume = hasVolume || v.Name == CredentialPluginVolume
+			}
+			if !hasVolume {
+				t.Errorf("missing the %s volume", CredentialPluginVolume)
+			}
+			gitAskpass := false
+			for _, c := range podSpec.Containers {
+				hasMount := false
+				for _, vm := range c.VolumeMounts {
+					hasMount = hasMount || vm.Name == CredentialPluginVolume
+				}
+				hasEnv := false
+				for _, env := range c.Env {
+					hasEnv = hasEnv || env.Name == reconcilermanager.ExecCredential
+					gitAskpass = gitAskpass || (env.Name == "GIT_ASKPASS_URL" && env.Value == execCredentialAskpassURL)
+				}
+				if want := c.Name == tc.pluginUser; hasMount != want || hasEnv != want {
+					t.Errorf("container %s has the plugin volume mount %t and env %t, want %t", c.Name, hasMount, hasEnv, want)
+				}
+			}
+			if gitAskpass != tc.wantGitAskpass {
+				t.Errorf("got git askpass %t, want %t", gitAskpass, tc.wantGitAskpass)
+			}
+		})
+	}
+}
+
+func TestRootSyncWithConverter(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	plugin := &v1beta1.Converter{Image: "plugin-image", Command: "dsl2krm", Extensions: []string{".dsl"}}
+	rs := rootSyncWithOCI(rootsyncName, rootsyncOCIAuthType(configsync.AuthNone), func(rs *v1beta1.RootSync) {
+		rs.Spec.Converter = plugin
+	})
+	_, fakeDynamicClient, testReconciler := setupRootReconciler(t, rs)
+	ctx := context.Background()
+	if _, err := testReconciler.Reconcile(ctx, namespacedName(rs.Name, rs.Namespace)); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+
+	uObj, err := fakeDynamicClient.Resource(kinds.DeploymentResource()).
+		Namespace(v1.NSConfigManagementSystem).
+		Get(ctx, rootReconcilerName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := kinds.ToTypedObject(uObj, core.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podSpec := obj.(*appsv1.Deployment).Spec.Template.Spec
+
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Image != plugin.Image {
+		t.Errorf("got init containers %v, want the %s init container", podSpec.InitContainers, reconcilermanager.ConverterPlugin)
+	}
+	hasVolume := false
+	for _, v := range podSpec.Volumes {
+		hasVolume = hasVolume || v.Name == ConverterPluginVolume
+	}
+	if !hasVolume {
+		t.Errorf("missing the %s volume", ConverterPluginVolume)
+	}
+	for _, c := range podSpec.Containers {
+		hasMount := false
+		for _, vm := range c.VolumeMounts {
+			hasMount = hasMount || vm.Name == ConverterPluginVolume
+		}
+		hasEnv := false
+		for _, env := range c.Env {
+			hasEnv = hasEnv || env.Name == reconcilermanager.Converter
+		}
+		if want := c.Name == reconcilermanager.Reconciler; hasMount != want || hasEnv != want {
+			t.Errorf("container %s has the plugin volume mount %t and env %t, want %t", c.Name, hasMount, hasEnv, want)
+		}
+	}
+}
+
 func TestRootSyncWithOCI(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -2926,6 +3184,7 @@ func TestPopulateRootContainerEnvs(t *testing.T) {
 			reconcilermanager.ReconcilerNameKey:      rootReconcilerName,
 			reconcilermanager.ScopeKey:               ":root",
 			reconcilermanager.SourceTypeKey:          string(gitSource),
+			reconcilermanager.SyncNameKey:            rootsyncName,
 			reconcilermanager.SyncDirKey:             rootsyncDir,
 		},
 		reconcilermanager.Reconciler: {
@@ -2996,6 +3255,14 @@ func TestPopulateRootContainerEnvs(t *testing.T) {
 			rootSync: rootSync(rootsyncName, rootsyncOverrideAPIServerTimeout(metav1.Duration{Duration: 40 * time.Second})),
 			expected: createEnv(map[string]map[string]string{reconcilermanager.Reconciler: {reconcilermanager.APIServerTimeout: "40s"}}),
 		},
+		{
+			name:     "override polling and status update periods",
+			rootSync: rootSync(rootsyncName, rootsyncOverridePollingPeriod(metav1.Duration{Duration: time.Minute}), rootsyncOverrideStatusUpdatePeriod(metav1.Duration{Duration: 30 * time.Second})),
+			expected: createEnv(map[string]map[string]string{reconcilermanager.Reconciler: {
+				reconcilermanager.ReconcilerPollingPeriod: "1m0s",
+				reconcilermanager.StatusUpdatePeriod:      "30s",
+			}}),
+		},
 	}
 
 	ctx := context.Background()
@@ -3025,9 +3292,14 @@ func validateRootSyncStatus(t *testing.T, want *v1beta1.RootSync, fakeClient *sy
 	require.NoError(t, err, "RootSync[%s] not found", key)
 
 	asserter := testutil.NewAsserter(
-		cmpopts.IgnoreFields(v1beta1.RootSyncCondition{}, "LastUpdateTime", "LastTransitionTime"))
+		cmpopts.IgnoreFields(v1beta1.RootSyncCondition{}, "LastUpdateTime", "LastTransitionTime", "ObservedGeneration"))
 	// cmpopts.SortSlices(func(x, y v1beta1.RootSyncCondition) bool { return x.Message < y.Message })
 	asserter.Equal(t, want.Status.Conditions, got.Status.Conditions, "Unexpected status conditions")
+	// The conditions set by the reconciler-manager are always for the
+	// current generation.
+	for _, condition := range got.Status.Conditions {
+		assert.Equal(t, got.Generation, condition.ObservedGeneration, "Unexpected observedGeneration of the %s condition", condition.Type)
+	}
 }
 
 type depMutator func(*appsv1.Deployment)
@@ -3092,6 +3364,26 @@ func caCertSecretMutator(secretName, caCertSecretName string) depMutator {
 	}
 }
 
+func targetKubeconfigMutator(secretName string) depMutator {
+	return func(dep *appsv1.Deployment) {
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, targetKubeconfigVolume(secretName))
+		for i, con := range dep.Spec.Template.Spec.Containers {
+			if con.Name == reconcilermanager.Reconciler {
+				dep.Spec.Template.Spec.Containers[i].VolumeMounts = append(con.VolumeMounts, targetKubeconfigVolumeMount())
+			}
+		}
+	}
+}
+
+func containsEnv(envs []corev1.EnvVar, name, value string) bool {
+	for _, env := range envs {
+		if env.Name == name && env.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
 func envVarMutator(envName, secretName, key string) depMutator {
 	return func(dep *appsv1.Deployment) {
 		for i, con := range dep.Spec.Template.Spec.Containers {
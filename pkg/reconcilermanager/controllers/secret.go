@@ -39,6 +39,9 @@ func isUpsertedSecret(rs *v1beta1.RepoSync, secretName string) bool {
 	if shouldUpsertCACertSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, v1beta1.GetSecretName(rs.Spec.Git.CACertSecretRef)) {
 		return true
 	}
+	if shouldUpsertKnownHostsSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef)) {
+		return true
+	}
 	if shouldUpsertGitSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, v1beta1.GetSecretName(rs.Spec.Git.SecretRef)) {
 		return true
 	}
@@ -52,6 +55,10 @@ func shouldUpsertCACertSecret(rs *v1beta1.RepoSync) bool {
 	return v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.GitSource && rs.Spec.Git != nil && rs.Spec.CACertSecretRef != nil && useCACert(rs.Spec.CACertSecretRef.Name)
 }
 
+func shouldUpsertKnownHostsSecret(rs *v1beta1.RepoSync) bool {
+	return v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.GitSource && rs.Spec.Git != nil && useKnownHosts(rs.Spec.Auth, v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef))
+}
+
 func shouldUpsertGitSecret(rs *v1beta1.RepoSync) bool {
 	return v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.GitSource && rs.Spec.Git != nil && rs.Spec.Git.SecretRef != nil && !SkipForAuth(rs.Spec.Auth)
 }
@@ -133,6 +140,33 @@ func upsertCACertSecret(ctx context.Context, log logr.Logger, rs *v1beta1.RepoSy
 	return client.ObjectKey{}, nil
 }
 
+// upsertKnownHostsSecret creates or updates the SSH known hosts secret in the
+// config-management-system namespace using an existing secret in the RepoSync
+// namespace.
+func upsertKnownHostsSecret(ctx context.Context, log logr.Logger, rs *v1beta1.RepoSync, c client.Client, reconcilerRef types.NamespacedName) (client.ObjectKey, error) {
+	rsRef := client.ObjectKeyFromObject(rs)
+	if shouldUpsertKnownHostsSecret(rs) {
+		nsSecretRef, cmsSecretRef := getSecretRefs(rsRef, reconcilerRef, v1beta1.GetSecretName(rs.Spec.Git.KnownHostsSecretRef))
+		userSecret, err := getUserSecret(ctx, c, nsSecretRef)
+		if err != nil {
+			return cmsSecretRef, errors.Wrap(err, "user secret required for git host key verification")
+		}
+		op, err := upsertSecret(ctx, c, cmsSecretRef, rsRef, userSecret)
+		if err != nil {
+			return cmsSecretRef, err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Info("Managed object upsert successful",
+				logFieldObject, cmsSecretRef.String(),
+				logFieldKind, "Secret",
+				logFieldOperation, op)
+		}
+		return cmsSecretRef, nil
+	}
+	// No secret required
+	return client.ObjectKey{}, nil
+}
+
 func getSecretRefs(rsRef, reconcilerRef client.ObjectKey, secretName string) (nsSecretRef, cmsSecretRef client.ObjectKey) {
 	// User managed secret
 	nsSecretRef = client.ObjectKey{
@@ -240,10 +274,10 @@ func updateSecret(ctx context.Context, c client.Client, existingsecret *corev1.S
 }
 
 // SkipForAuth returns true if the passed auth is either 'none' or 'gcenode' or
-// 'gcpserviceaccount'.
+// 'gcpserviceaccount' or 'exec'.
 func SkipForAuth(auth configsync.AuthType) bool {
 	switch auth {
-	case configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount:
+	case configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount, configsync.AuthExec:
 		return true
 	default:
 		return false
@@ -0,0 +1,233 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"kpt.dev/configsync/pkg/api/configsync"
+)
+
+const (
+	// badgePathPrefix is the path prefix of the badge endpoint. Badges are
+	// served at `/badges/<kind>/<namespace>/<name>.svg` for the SVG image, and
+	// at `/badges/<kind>/<namespace>/<name>.json` for the shields.io endpoint
+	// JSON, where kind is `rootsync` or `reposync`.
+	badgePathPrefix = "/badges/"
+
+	// badgeLabel is the text on the left side of the badges.
+	badgeLabel = "config sync"
+
+	// badgeShutdownTimeout is how long to wait for the in-flight badge
+	// requests to complete when the reconciler-manager stops.
+	badgeShutdownTimeout = 5 * time.Second
+)
+
+// badgeColors maps the sync states to the colors of the badges.
+var badgeColors = map[SyncState]string{
+	SyncStateSynced:  "#4c1",
+	SyncStatePending: "#dfb317",
+	SyncStateError:   "#e05d44",
+	SyncStateStalled: "#9f9f9f",
+}
+
+// unknownBadgeColor is the color of the badges of unknown RSyncs.
+const unknownBadgeColor = "#9f9f9f"
+
+// BadgeServer is a StatusPublisher which keeps the latest summarized status
+// of the RSyncs in memory, and serves a badge per RSync over HTTP, so that
+// teams can embed the live sync status in their READMEs and portals.
+type BadgeServer struct {
+	addr string
+	log  logr.Logger
+
+	mux       sync.RWMutex
+	summaries map[string]SyncStatusSummary
+}
+
+var _ StatusPublisher = &BadgeServer{}
+
+// NewBadgeServer returns a BadgeServer listening on addr.
+func NewBadgeServer(addr string, log logr.Logger) *BadgeServer {
+	return &BadgeServer{
+		addr:      addr,
+		log:       log,
+		summaries: make(map[string]SyncStatusSummary),
+	}
+}
+
+// Publish implements StatusPublisher.
+func (s *BadgeServer) Publish(_ context.Context, summaries []SyncStatusSummary) error {
+	latest := make(map[string]SyncStatusSummary, len(summaries))
+	for _, summary := range summaries {
+		latest[badgeKey(summary.Kind, summary.Namespace, summary.Name)] = summary
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.summaries = latest
+	return nil
+}
+
+// Start serves the badges until the context is cancelled. It implements
+// manager.Runnable.
+func (s *BadgeServer) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), badgeShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(err, "Failed to shut down the badge server")
+		}
+	}()
+	s.log.Info("Serving sync status badges", "addr", s.addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *BadgeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, badgePathPrefix)
+	if path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	format := "svg"
+	switch {
+	case strings.HasSuffix(path, ".svg"):
+		path = strings.TrimSuffix(path, ".svg")
+	case strings.HasSuffix(path, ".json"):
+		path = strings.TrimSuffix(path, ".json")
+		format = "json"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+	var kind string
+	switch strings.ToLower(parts[0]) {
+	case strings.ToLower(configsync.RootSyncKind):
+		kind = configsync.RootSyncKind
+	case strings.ToLower(configsync.RepoSyncKind):
+		kind = configsync.RepoSyncKind
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mux.RLock()
+	summary, found := s.summaries[badgeKey(kind, parts[1], parts[2])]
+	s.mux.RUnlock()
+
+	message, color := "unknown", unknownBadgeColor
+	code := http.StatusNotFound
+	if found {
+		message, color = badgeMessage(summary), badgeColors[summary.State]
+		code = http.StatusOK
+	}
+
+	// Prevent the image proxies of the code hosting services from caching
+	// the badges, so that they stay live.
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(shieldsEndpoint{
+			SchemaVersion: 1,
+			Label:         badgeLabel,
+			Message:       message,
+			Color:         color,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(badgeSVG(badgeLabel, message, color)))
+}
+
+// shieldsEndpoint is the response schema of the shields.io endpoint badges.
+// See https://shields.io/badges/endpoint-badge.
+type shieldsEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+func badgeKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// badgeMessage returns the sync state, followed by the short hash of the last
+// synced commit, if any.
+func badgeMessage(summary SyncStatusSummary) string {
+	commit := summary.LastSyncedCommit
+	if commit == "" {
+		return string(summary.State)
+	}
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	return fmt.Sprintf("%s %s", summary.State, commit)
+}
+
+// badgeSVG renders a flat badge with the label on a grey background on the
+// left, and the message on a colored background on the right.
+func badgeSVG(label, message, color string) string {
+	labelWidth := badgeTextWidth(label)
+	messageWidth := badgeTextWidth(message)
+	width := labelWidth + messageWidth
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<rect width="%[2]d" height="20" fill="#555"/>`+
+		`<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, messageWidth, label, message, color,
+		labelWidth/2, labelWidth+messageWidth/2)
+}
+
+// badgeTextWidth approximates the width in pixels of the text in a badge,
+// including the padding.
+func badgeTextWidth(text string) int {
+	return 7*len(text) + 10
+}
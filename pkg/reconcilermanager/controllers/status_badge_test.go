@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+)
+
+func TestBadgeServer(t *testing.T) {
+	s := NewBadgeServer(":0", controllerruntime.Log.WithName("badges"))
+	err := s.Publish(context.Background(), []SyncStatusSummary{
+		{
+			Kind:             configsync.RootSyncKind,
+			Namespace:        configsync.ControllerNamespace,
+			Name:             configsync.RootSyncName,
+			State:            SyncStateSynced,
+			LastSyncedCommit: "1234567890abcdef",
+		},
+		{
+			Kind:      configsync.RepoSyncKind,
+			Namespace: "bookstore",
+			Name:      configsync.RepoSyncName,
+			State:     SyncStateError,
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name            string
+		method          string
+		path            string
+		wantCode        int
+		wantContentType string
+		wantEndpoint    *shieldsEndpoint
+		wantSVGText     string
+	}{
+		{
+			name:            "RootSync SVG badge",
+			path:            "/badges/rootsync/config-management-system/root-sync.svg",
+			wantCode:        http.StatusOK,
+			wantContentType: "image/svg+xml",
+			wantSVGText:     "Synced 1234567",
+		},
+		{
+			name:            "RepoSync JSON badge",
+			path:            "/badges/reposync/bookstore/repo-sync.json",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantEndpoint: &shieldsEndpoint{
+				SchemaVersion: 1,
+				Label:         badgeLabel,
+				Message:       "Error",
+				Color:         badgeColors[SyncStateError],
+			},
+		},
+		{
+			name:            "unknown RSync",
+			path:            "/badges/reposync/bookstore/missing.svg",
+			wantCode:        http.StatusNotFound,
+			wantContentType: "image/svg+xml",
+			wantSVGText:     "unknown",
+		},
+		{
+			name:     "unknown kind",
+			path:     "/badges/configmap/bookstore/repo-sync.svg",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "unknown format",
+			path:     "/badges/reposync/bookstore/repo-sync.png",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "unsupported method",
+			method:   http.MethodPost,
+			path:     "/badges/reposync/bookstore/repo-sync.svg",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(method, tc.path, nil))
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantContentType != "" {
+				require.Equal(t, tc.wantContentType, w.Header().Get("Content-Type"))
+			}
+			if tc.wantEndpoint != nil {
+				got := &shieldsEndpoint{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), got))
+				require.Equal(t, tc.wantEndpoint, got)
+			}
+			if tc.wantSVGText != "" {
+				require.Contains(t, w.Body.String(), ">"+tc.wantSVGText+"</text>")
+			}
+		})
+	}
+}
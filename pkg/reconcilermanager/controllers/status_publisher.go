@@ -0,0 +1,305 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/status"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// StatusPublisherLoggerName defines the logger name for StatusPublisherReconciler
+	StatusPublisherLoggerName = "StatusPublisher"
+
+	// SyncStatusSummaryName is the name of the ConfigMap that the
+	// configMapStatusPublisher writes the summarized RSync status into.
+	SyncStatusSummaryName = "config-sync-status-summary"
+
+	// SyncStatusSummaryKey is the ConfigMap data key holding the JSON-encoded
+	// list of SyncStatusSummary objects.
+	SyncStatusSummaryKey = "summary.json"
+)
+
+// SyncState is a coarse-grained health state of a RootSync or RepoSync.
+type SyncState string
+
+const (
+	// SyncStateSynced means the latest source commit was synced without errors.
+	SyncStateSynced SyncState = "Synced"
+	// SyncStatePending means the reconciler is still rendering, parsing or
+	// applying the latest source commit.
+	SyncStatePending SyncState = "Pending"
+	// SyncStateError means at least one rendering, source or sync error was
+	// reported for the latest source commit.
+	SyncStateError SyncState = "Error"
+	// SyncStateStalled means the reconciler-manager was unable to configure
+	// the reconciler for the RSync.
+	SyncStateStalled SyncState = "Stalled"
+)
+
+// SyncStatusSummary is the summarized status of a single RootSync or RepoSync.
+type SyncStatusSummary struct {
+	Kind             string      `json:"kind"`
+	Namespace        string      `json:"namespace"`
+	Name             string      `json:"name"`
+	State            SyncState   `json:"state"`
+	SourceCommit     string      `json:"sourceCommit,omitempty"`
+	LastSyncedCommit string      `json:"lastSyncedCommit,omitempty"`
+	ErrorCount       int         `json:"errorCount"`
+	ErrorCodes       []string    `json:"errorCodes,omitempty"`
+	LastUpdate       metav1.Time `json:"lastUpdate,omitempty"`
+}
+
+// StatusPublisher publishes the summarized status of all the RSyncs on the
+// cluster to a location where org-level tooling can consume it.
+type StatusPublisher interface {
+	Publish(ctx context.Context, summaries []SyncStatusSummary) error
+}
+
+// configMapStatusPublisher publishes the summarized status into a single
+// ConfigMap in the config-management-system namespace.
+type configMapStatusPublisher struct {
+	client client.Client
+}
+
+// NewConfigMapStatusPublisher returns a StatusPublisher that mirrors the
+// summarized status into the `config-sync-status-summary` ConfigMap.
+func NewConfigMapStatusPublisher(c client.Client) StatusPublisher {
+	return &configMapStatusPublisher{client: c}
+}
+
+// Publish implements StatusPublisher.
+func (p *configMapStatusPublisher) Publish(ctx context.Context, summaries []SyncStatusSummary) error {
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{}
+	cm.Name = SyncStatusSummaryName
+	cm.Namespace = configsync.ControllerNamespace
+	_, err = controllerruntime.CreateOrUpdate(ctx, p.client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[metadata.SystemLabel] = "true"
+		cm.Data = map[string]string{
+			SyncStatusSummaryKey: string(data),
+		}
+		return nil
+	})
+	if err != nil {
+		return status.APIServerErrorf(err, "failed to publish sync status summary to ConfigMap %s/%s", cm.Namespace, cm.Name)
+	}
+	return nil
+}
+
+// multiStatusPublisher publishes the summarized status with several
+// StatusPublishers.
+type multiStatusPublisher []StatusPublisher
+
+// NewMultiStatusPublisher returns a StatusPublisher that publishes the
+// summarized status with each of the publishers, in order.
+func NewMultiStatusPublisher(publishers ...StatusPublisher) StatusPublisher {
+	if len(publishers) == 1 {
+		return publishers[0]
+	}
+	return multiStatusPublisher(publishers)
+}
+
+// Publish implements StatusPublisher.
+func (m multiStatusPublisher) Publish(ctx context.Context, summaries []SyncStatusSummary) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, summaries); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+var _ reconcile.Reconciler = &StatusPublisherReconciler{}
+
+// StatusPublisherReconciler watches all the RootSyncs and RepoSyncs and
+// mirrors their summarized status using a StatusPublisher.
+type StatusPublisherReconciler struct {
+	client    client.Client
+	publisher StatusPublisher
+	log       logr.Logger
+	scheme    *runtime.Scheme
+}
+
+// NewStatusPublisherReconciler returns a new StatusPublisherReconciler.
+func NewStatusPublisherReconciler(c client.Client, publisher StatusPublisher, log logr.Logger, scheme *runtime.Scheme) *StatusPublisherReconciler {
+	return &StatusPublisherReconciler{
+		client:    c,
+		publisher: publisher,
+		log:       log,
+		scheme:    scheme,
+	}
+}
+
+// Reconcile lists all the RSyncs and publishes their summarized status.
+// Every request triggers a full re-publish, because the published summary is
+// an aggregate of all the RSyncs on the cluster.
+func (r *StatusPublisherReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	summaries, err := r.summarize(ctx)
+	if err != nil {
+		r.log.Error(err, "Failed to summarize sync status")
+		return controllerruntime.Result{}, err
+	}
+	if err := r.publisher.Publish(ctx, summaries); err != nil {
+		r.log.Error(err, "Failed to publish sync status summary")
+		return controllerruntime.Result{}, err
+	}
+	r.log.V(3).Info("Sync status summary published", "count", len(summaries))
+	return controllerruntime.Result{}, nil
+}
+
+func (r *StatusPublisherReconciler) summarize(ctx context.Context) ([]SyncStatusSummary, error) {
+	var summaries []SyncStatusSummary
+
+	rootSyncList := &v1beta1.RootSyncList{}
+	if err := r.client.List(ctx, rootSyncList, client.InNamespace(configsync.ControllerNamespace)); err != nil {
+		return nil, status.APIServerError(err, "failed to list RootSyncs")
+	}
+	for i := range rootSyncList.Items {
+		summaries = append(summaries, summarizeRootSync(&rootSyncList.Items[i]))
+	}
+
+	repoSyncList := &v1beta1.RepoSyncList{}
+	if err := r.client.List(ctx, repoSyncList); err != nil {
+		return nil, status.APIServerError(err, "failed to list RepoSyncs")
+	}
+	for i := range repoSyncList.Items {
+		summaries = append(summaries, summarizeRepoSync(&repoSyncList.Items[i]))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Kind != summaries[j].Kind {
+			return summaries[i].Kind < summaries[j].Kind
+		}
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries, nil
+}
+
+func summarizeRootSync(rs *v1beta1.RootSync) SyncStatusSummary {
+	summary := summarizeStatus(&rs.Status.Status)
+	summary.Kind = configsync.RootSyncKind
+	summary.Namespace = rs.Namespace
+	summary.Name = rs.Name
+	if rootsync.IsStalled(rs) {
+		summary.State = SyncStateStalled
+	} else if cond := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing); cond == nil || cond.Status == metav1.ConditionTrue {
+		if summary.State != SyncStateError {
+			summary.State = SyncStatePending
+		}
+	}
+	return summary
+}
+
+func summarizeRepoSync(rs *v1beta1.RepoSync) SyncStatusSummary {
+	summary := summarizeStatus(&rs.Status.Status)
+	summary.Kind = configsync.RepoSyncKind
+	summary.Namespace = rs.Namespace
+	summary.Name = rs.Name
+	if reposync.IsStalled(rs) {
+		summary.State = SyncStateStalled
+	} else if cond := reposync.GetCondition(rs.Status.Conditions, v1beta1.RepoSyncSyncing); cond == nil || cond.Status == metav1.ConditionTrue {
+		if summary.State != SyncStateError {
+			summary.State = SyncStatePending
+		}
+	}
+	return summary
+}
+
+// summarizeStatus computes the fields that are common to RootSyncs and
+// RepoSyncs.
+func summarizeStatus(s *v1beta1.Status) SyncStatusSummary {
+	summary := SyncStatusSummary{
+		SourceCommit:     s.Source.Commit,
+		LastSyncedCommit: s.LastSyncedCommit,
+		LastUpdate:       s.Sync.LastUpdate,
+	}
+	for _, errSummary := range []*v1beta1.ErrorSummary{s.Rendering.ErrorSummary, s.Source.ErrorSummary, s.Sync.ErrorSummary} {
+		if errSummary != nil {
+			summary.ErrorCount += errSummary.TotalCount
+		}
+	}
+	codes := make(map[string]bool)
+	for _, errs := range [][]v1beta1.ConfigSyncError{s.Rendering.Errors, s.Source.Errors, s.Sync.Errors} {
+		for _, e := range errs {
+			if !codes[e.Code] {
+				codes[e.Code] = true
+				summary.ErrorCodes = append(summary.ErrorCodes, e.Code)
+			}
+		}
+	}
+	sort.Strings(summary.ErrorCodes)
+	switch {
+	case summary.ErrorCount > 0:
+		summary.State = SyncStateError
+	case s.Source.Commit != "" && s.Sync.Commit == s.Source.Commit && s.LastSyncedCommit == s.Sync.Commit:
+		summary.State = SyncStateSynced
+	default:
+		summary.State = SyncStatePending
+	}
+	return summary
+}
+
+// SetupWithManager registers the status publisher controller with reconciler-manager.
+func (r *StatusPublisherReconciler) SetupWithManager(mgr controllerruntime.Manager) error {
+	// All the RSync events are mapped to the same request, since the summary
+	// is always published as a whole.
+	mapToSummary := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{
+			NamespacedName: types.NamespacedName{
+				Namespace: configsync.ControllerNamespace,
+				Name:      SyncStatusSummaryName,
+			},
+		}}
+	})
+	return controllerruntime.NewControllerManagedBy(mgr).
+		Named(StatusPublisherLoggerName).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		Watches(&source.Kind{Type: &v1beta1.RootSync{}}, mapToSummary).
+		Watches(&source.Kind{Type: &v1beta1.RepoSync{}}, mapToSummary).
+		Complete(r)
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestStatusPublisherReconciler(t *testing.T) {
+	syncedRootSync := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+	syncedRootSync.Status.Source.Commit = "abc"
+	syncedRootSync.Status.Sync.Commit = "abc"
+	syncedRootSync.Status.LastSyncedCommit = "abc"
+	syncedRootSync.Status.Conditions = []v1beta1.RootSyncCondition{
+		{Type: v1beta1.RootSyncSyncing, Status: metav1.ConditionFalse},
+	}
+
+	pendingRootSync := fake.RootSyncObjectV1Beta1("pending")
+	pendingRootSync.Status.Source.Commit = "def"
+	pendingRootSync.Status.Sync.Commit = "abc"
+	pendingRootSync.Status.LastSyncedCommit = "abc"
+
+	errorRepoSync := fake.RepoSyncObjectV1Beta1("bookstore", configsync.RepoSyncName)
+	errorRepoSync.Status.Source.Commit = "abc"
+	errorRepoSync.Status.Source.ErrorSummary = &v1beta1.ErrorSummary{TotalCount: 2}
+	errorRepoSync.Status.Sync.ErrorSummary = &v1beta1.ErrorSummary{TotalCount: 1}
+	errorRepoSync.Status.Conditions = []v1beta1.RepoSyncCondition{
+		{Type: v1beta1.RepoSyncSyncing, Status: metav1.ConditionFalse},
+	}
+
+	stalledRepoSync := fake.RepoSyncObjectV1Beta1("bookstore", "stalled")
+	stalledRepoSync.Status.Conditions = []v1beta1.RepoSyncCondition{
+		{Type: v1beta1.RepoSyncStalled, Status: metav1.ConditionTrue},
+	}
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme, syncedRootSync, pendingRootSync, errorRepoSync, stalledRepoSync)
+	r := NewStatusPublisherReconciler(fakeClient,
+		NewConfigMapStatusPublisher(fakeClient),
+		controllerruntime.Log.WithName("controllers").WithName(StatusPublisherLoggerName),
+		fakeClient.Scheme())
+
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, namespacedName(SyncStatusSummaryName, configsync.ControllerNamespace))
+	require.NoError(t, err)
+
+	cm := &corev1.ConfigMap{}
+	err = fakeClient.Get(ctx, client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: SyncStatusSummaryName}, cm)
+	require.NoError(t, err)
+
+	var got []SyncStatusSummary
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[SyncStatusSummaryKey]), &got))
+
+	want := []SyncStatusSummary{
+		{Kind: configsync.RepoSyncKind, Namespace: "bookstore", Name: configsync.RepoSyncName, State: SyncStateError, SourceCommit: "abc", ErrorCount: 3},
+		{Kind: configsync.RepoSyncKind, Namespace: "bookstore", Name: "stalled", State: SyncStateStalled},
+		{Kind: configsync.RootSyncKind, Namespace: configsync.ControllerNamespace, Name: "pending", State: SyncStatePending, SourceCommit: "def", LastSyncedCommit: "abc"},
+		{Kind: configsync.RootSyncKind, Namespace: configsync.ControllerNamespace, Name: configsync.RootSyncName, State: SyncStateSynced, SourceCommit: "abc", LastSyncedCommit: "abc"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(SyncStatusSummary{}, "LastUpdate")); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SyncDefaultsName is the name of the ConfigMap in the
+	// config-management-system namespace where admins set the fleet defaults
+	// for the `spec.override` fields of all the RootSyncs and RepoSyncs.
+	SyncDefaultsName = "config-sync-defaults"
+
+	// SyncDefaultsOverrideKey is the ConfigMap data key holding the YAML or
+	// JSON encoded OverrideSpec defaults.
+	SyncDefaultsOverrideKey = "override"
+)
+
+// overrideWithDefaults returns the effective OverrideSpec of an RSync object,
+// which is its own `spec.override` layered on top of the fleet defaults from
+// the config-sync-defaults ConfigMap.
+func (r *reconcilerBase) overrideWithDefaults(ctx context.Context, override *v1beta1.OverrideSpec) (*v1beta1.OverrideSpec, error) {
+	defaults, err := getOverrideDefaults(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+	return mergeOverride(defaults, override), nil
+}
+
+// getOverrideDefaults reads the OverrideSpec defaults from the
+// config-sync-defaults ConfigMap. It returns nil if the ConfigMap does not exist.
+func getOverrideDefaults(ctx context.Context, c client.Reader) (*v1beta1.OverrideSpec, error) {
+	cm := &corev1.ConfigMap{}
+	cmRef := types.NamespacedName{Namespace: configsync.ControllerNamespace, Name: SyncDefaultsName}
+	if err := c.Get(ctx, cmRef, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get ConfigMap %s", cmRef)
+	}
+	data, found := cm.Data[SyncDefaultsOverrideKey]
+	if !found {
+		return nil, nil
+	}
+	defaults := &v1beta1.OverrideSpec{}
+	if err := yaml.UnmarshalStrict([]byte(data), defaults); err != nil {
+		return nil, errors.Wrapf(err, "invalid %q in ConfigMap %s", SyncDefaultsOverrideKey, cmRef)
+	}
+	return defaults, nil
+}
+
+// mergeOverride returns a new OverrideSpec with the fields set in override
+// taking precedence over the ones set in defaults.
+//
+// Container resources are merged per field: the defaults are listed first so
+// that mutateContainerResource lets any quantity set on the RSync win.
+func mergeOverride(defaults, override *v1beta1.OverrideSpec) *v1beta1.OverrideSpec {
+	if defaults == nil {
+		return override
+	}
+	merged := defaults.DeepCopy()
+	if override == nil {
+		return merged
+	}
+	for _, res := range override.Resources {
+		merged.Resources = append(merged.Resources, *res.DeepCopy())
+	}
+	if override.GitSyncDepth != nil {
+		merged.GitSyncDepth = override.GitSyncDepth
+	}
+	if override.StatusMode != "" {
+		merged.StatusMode = override.StatusMode
+	}
+	if override.ReconcileTimeout != nil {
+		merged.ReconcileTimeout = override.ReconcileTimeout
+	}
+	if override.APIServerTimeout != nil {
+		merged.APIServerTimeout = override.APIServerTimeout
+	}
+	if override.EnableShellInRendering != nil {
+		merged.EnableShellInRendering = override.EnableShellInRendering
+	}
+	if override.UpgradeSettlePeriod != nil {
+		merged.UpgradeSettlePeriod = override.UpgradeSettlePeriod
+	}
+	if override.PruneDelay != nil {
+		merged.PruneDelay = override.PruneDelay
+	}
+	if override.ApprovalTimeout != nil {
+		merged.ApprovalTimeout = override.ApprovalTimeout
+	}
+	if override.PollingPeriod != nil {
+		merged.PollingPeriod = override.PollingPeriod
+	}
+	if override.StatusUpdatePeriod != nil {
+		merged.StatusUpdatePeriod = override.StatusUpdatePeriod
+	}
+	if override.APIPriorityGroup != "" {
+		merged.APIPriorityGroup = override.APIPriorityGroup
+	}
+	if override.SupersedeInFlightApply != nil {
+		merged.SupersedeInFlightApply = override.SupersedeInFlightApply
+	}
+	if override.PartialApply != nil {
+		merged.PartialApply = override.PartialApply
+	}
+	if override.SelfUpdateTimeout != nil {
+		merged.SelfUpdateTimeout = override.SelfUpdateTimeout
+	}
+	if override.PruneObsoleteMetadata != nil {
+		merged.PruneObsoleteMetadata = override.PruneObsoleteMetadata
+	}
+	if override.MigrateClientSideApply != nil {
+		merged.MigrateClientSideApply = override.MigrateClientSideApply
+	}
+	if override.NormalizeDeclarations != nil {
+		merged.NormalizeDeclarations = override.NormalizeDeclarations
+	}
+	if override.RetryBudget != nil {
+		merged.RetryBudget = override.RetryBudget
+	}
+	if override.ParseConcurrency != nil {
+		merged.ParseConcurrency = override.ParseConcurrency
+	}
+	if override.Metrics != "" {
+		merged.Metrics = override.Metrics
+	}
+	merged.CommonLabels = mergeStringMaps(merged.CommonLabels, override.CommonLabels)
+	merged.CommonAnnotations = mergeStringMaps(merged.CommonAnnotations, override.CommonAnnotations)
+	return merged
+}
+
+// mergeStringMaps returns the union of defaults and override, with the values
+// in override taking precedence.
+func mergeStringMaps(defaults, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return defaults
+	}
+	merged := make(map[string]string, len(defaults)+len(override))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// isSyncDefaultsConfigMap returns true if the object is the
+// config-sync-defaults ConfigMap.
+func isSyncDefaultsConfigMap(obj client.Object) bool {
+	return obj.GetNamespace() == configsync.ControllerNamespace && obj.GetName() == SyncDefaultsName
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMergeOverride(t *testing.T) {
+	depth := int64(1)
+	otherDepth := int64(5)
+	enabled := true
+	testCases := []struct {
+		name     string
+		defaults *v1beta1.OverrideSpec
+		override *v1beta1.OverrideSpec
+		want     *v1beta1.OverrideSpec
+	}{
+		{
+			name:     "no defaults",
+			override: &v1beta1.OverrideSpec{StatusMode: "enabled"},
+			want:     &v1beta1.OverrideSpec{StatusMode: "enabled"},
+		},
+		{
+			name:     "no override",
+			defaults: &v1beta1.OverrideSpec{GitSyncDepth: &depth},
+			want:     &v1beta1.OverrideSpec{GitSyncDepth: &depth},
+		},
+		{
+			name: "override takes precedence",
+			defaults: &v1beta1.OverrideSpec{
+				GitSyncDepth:           &depth,
+				StatusMode:             "disabled",
+				ReconcileTimeout:       &metav1.Duration{Duration: time.Minute},
+				EnableShellInRendering: &enabled,
+			},
+			override: &v1beta1.OverrideSpec{
+				GitSyncDepth:      &otherDepth,
+				ReconcileTimeout:  &metav1.Duration{Duration: 2 * time.Minute},
+				PollingPeriod:     &metav1.Duration{Duration: time.Minute},
+				SelfUpdateTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			want: &v1beta1.OverrideSpec{
+				GitSyncDepth:           &otherDepth,
+				StatusMode:             "disabled",
+				ReconcileTimeout:       &metav1.Duration{Duration: 2 * time.Minute},
+				EnableShellInRendering: &enabled,
+				PollingPeriod:          &metav1.Duration{Duration: time.Minute},
+				SelfUpdateTimeout:      &metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+		{
+			name: "resources from override listed after defaults",
+			defaults: &v1beta1.OverrideSpec{
+				Resources: []v1beta1.ContainerResourcesSpec{
+					{ContainerName: "reconciler", CPURequest: resource.MustParse("100m")},
+				},
+			},
+			override: &v1beta1.OverrideSpec{
+				Resources: []v1beta1.ContainerResourcesSpec{
+					{ContainerName: "reconciler", CPURequest: resource.MustParse("500m")},
+				},
+			},
+			want: &v1beta1.OverrideSpec{
+				Resources: []v1beta1.ContainerResourcesSpec{
+					{ContainerName: "reconciler", CPURequest: resource.MustParse("100m")},
+					{ContainerName: "reconciler", CPURequest: resource.MustParse("500m")},
+				},
+			},
+		},
+		{
+			name: "common labels merged per key",
+			defaults: &v1beta1.OverrideSpec{
+				CommonLabels: map[string]string{"owner": "platform", "cost-center": "1234"},
+			},
+			override: &v1beta1.OverrideSpec{
+				CommonLabels:      map[string]string{"owner": "team-a"},
+				CommonAnnotations: map[string]string{"example.com/contact": "team-a@example.com"},
+			},
+			want: &v1beta1.OverrideSpec{
+				CommonLabels:      map[string]string{"owner": "team-a", "cost-center": "1234"},
+				CommonAnnotations: map[string]string{"example.com/contact": "team-a@example.com"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mergeOverride(tc.defaults, tc.override)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestMergeOverrideResources(t *testing.T) {
+	defaults := &v1beta1.OverrideSpec{
+		Resources: []v1beta1.ContainerResourcesSpec{
+			{ContainerName: "reconciler", CPURequest: resource.MustParse("100m"), MemoryRequest: resource.MustParse("100Mi")},
+		},
+	}
+	override := &v1beta1.OverrideSpec{
+		Resources: []v1beta1.ContainerResourcesSpec{
+			{ContainerName: "reconciler", CPURequest: resource.MustParse("500m")},
+		},
+	}
+	container := corev1.Container{Name: "reconciler"}
+	mutateContainerResource(&container, mergeOverride(defaults, override))
+
+	require.Equal(t, resource.MustParse("500m"), container.Resources.Requests[corev1.ResourceCPU])
+	require.Equal(t, resource.MustParse("100Mi"), container.Resources.Requests[corev1.ResourceMemory])
+}
+
+func TestGetOverrideDefaults(t *testing.T) {
+	depth := int64(1)
+	testCases := []struct {
+		name    string
+		objs    []client.Object
+		want    *v1beta1.OverrideSpec
+		wantErr bool
+	}{
+		{
+			name: "ConfigMap not found",
+		},
+		{
+			name: "valid defaults",
+			objs: []client.Object{syncDefaultsConfigMap("gitSyncDepth: 1\nstatusMode: disabled\n")},
+			want: &v1beta1.OverrideSpec{GitSyncDepth: &depth, StatusMode: "disabled"},
+		},
+		{
+			name:    "unknown field",
+			objs:    []client.Object{syncDefaultsConfigMap("gitSyncDepht: 1\n")},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := syncerFake.NewClient(t, core.Scheme, tc.objs...)
+			got, err := getOverrideDefaults(context.Background(), fakeClient)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func syncDefaultsConfigMap(override string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{}
+	cm.Name = SyncDefaultsName
+	cm.Namespace = configsync.ControllerNamespace
+	cm.Data = map[string]string{SyncDefaultsOverrideKey: override}
+	return cm
+}
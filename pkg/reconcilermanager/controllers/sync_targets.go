@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+)
+
+// SyncTargetsPath is the path under which the kubeconfigs of the RootSync
+// targets are mounted, in a directory per target.
+const SyncTargetsPath = "/etc/targets"
+
+// syncTargetVolumeName returns the name of the volume holding the kubeconfig
+// of the named target.
+func syncTargetVolumeName(targetName string) string {
+	return "target-" + targetName
+}
+
+// syncTargetKubeconfigPath returns the path of the kubeconfig of the named
+// target in the reconciler container.
+func syncTargetKubeconfigPath(targetName string) string {
+	return path.Join(SyncTargetsPath, targetName, TargetKubeconfigSecretKey)
+}
+
+// validateSyncTargets verifies that the spec.targets of the RootSync are well
+// formed, and that the kubeconfig Secret of every target is present.
+func (r *RootSyncReconciler) validateSyncTargets(ctx context.Context, rs *v1beta1.RootSync) error {
+	if len(rs.Spec.Targets) == 0 {
+		return nil
+	}
+	if rs.Spec.Target != nil {
+		return errors.New("spec.target and spec.targets are mutually exclusive")
+	}
+	names := sets.NewString()
+	for _, target := range rs.Spec.Targets {
+		if errs := validation.IsDNS1123Label(syncTargetVolumeName(target.Name)); len(errs) > 0 {
+			return errors.Errorf("invalid target name %q: %v", target.Name, errs)
+		}
+		if names.Has(target.Name) {
+			return errors.Errorf("duplicate target name %q", target.Name)
+		}
+		names.Insert(target.Name)
+		secretName := v1beta1.GetSecretName(target.KubeconfigSecretRef)
+		if secretName == "" {
+			return errors.Errorf("target %q must set kubeconfigSecretRef", target.Name)
+		}
+		if err := r.validateKubeconfigSecret(ctx, rs.Namespace, secretName, fmt.Sprintf("targets[%s].kubeconfigSecretRef", target.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncTargetsEnv returns the environment variable passing the targets to the
+// root reconciler.
+func syncTargetsEnv(targets []v1beta1.SyncTarget) []corev1.EnvVar {
+	if len(targets) == 0 {
+		return nil
+	}
+	configs := make([]reconcilermanager.TargetConfig, len(targets))
+	for i, target := range targets {
+		configs[i] = reconcilermanager.TargetConfig{
+			Name:       target.Name,
+			Kubeconfig: syncTargetKubeconfigPath(target.Name),
+			Variables:  target.Variables,
+		}
+	}
+	// Encoding a list of strings and string maps cannot fail.
+	value, _ := json.Marshal(configs)
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.Targets,
+		Value: string(value),
+	}}
+}
+
+// syncTargetVolumes returns the volumes holding the kubeconfigs of the targets.
+func syncTargetVolumes(targets []v1beta1.SyncTarget) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, target := range targets {
+		volume := targetKubeconfigVolume(v1beta1.GetSecretName(target.KubeconfigSecretRef))
+		volume.Name = syncTargetVolumeName(target.Name)
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
+
+// syncTargetVolumeMounts returns the VolumeMounts of the volumes returned by
+// syncTargetVolumes.
+func syncTargetVolumeMounts(targets []v1beta1.SyncTarget) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, target := range targets {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      syncTargetVolumeName(target.Name),
+			MountPath: path.Join(SyncTargetsPath, target.Name),
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func syncTarget(name, secretName string) v1beta1.SyncTarget {
+	return v1beta1.SyncTarget{
+		Name:   name,
+		Target: v1beta1.Target{KubeconfigSecretRef: &v1beta1.SecretReference{Name: secretName}},
+	}
+}
+
+func TestValidateSyncTargets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		target  *v1beta1.Target
+		targets []v1beta1.SyncTarget
+		wantErr string
+	}{
+		{
+			name: "no targets",
+		},
+		{
+			name:    "valid targets",
+			targets: []v1beta1.SyncTarget{syncTarget("east", "east-kubeconfig"), syncTarget("west", "west-kubeconfig")},
+		},
+		{
+			name:    "target and targets",
+			target:  &v1beta1.Target{KubeconfigSecretRef: &v1beta1.SecretReference{Name: "east-kubeconfig"}},
+			targets: []v1beta1.SyncTarget{syncTarget("west", "west-kubeconfig")},
+			wantErr: "spec.target and spec.targets are mutually exclusive",
+		},
+		{
+			name:    "duplicate name",
+			targets: []v1beta1.SyncTarget{syncTarget("east", "east-kubeconfig"), syncTarget("east", "west-kubeconfig")},
+			wantErr: `duplicate target name "east"`,
+		},
+		{
+			name:    "invalid name",
+			targets: []v1beta1.SyncTarget{syncTarget("East_1", "east-kubeconfig")},
+			wantErr: `invalid target name "East_1"`,
+		},
+		{
+			name:    "missing secret ref",
+			targets: []v1beta1.SyncTarget{{Name: "east"}},
+			wantErr: `target "east" must set kubeconfigSecretRef`,
+		},
+		{
+			name:    "missing secret",
+			targets: []v1beta1.SyncTarget{syncTarget("north", "north-kubeconfig")},
+			wantErr: "Secret north-kubeconfig not found, create one to allow syncing to the target cluster",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := rootSync(rootsyncName)
+			rs.Spec.Target = tc.target
+			rs.Spec.Targets = tc.targets
+			var objs []client.Object
+			for _, name := range []string{"east-kubeconfig", "west-kubeconfig"} {
+				secret := secretObj(t, name, configsync.AuthNone, v1beta1.GitSource, core.Namespace(rs.Namespace))
+				secret.Data = map[string][]byte{TargetKubeconfigSecretKey: []byte("test-data")}
+				objs = append(objs, secret)
+			}
+			_, _, testReconciler := setupRootReconciler(t, objs...)
+
+			err := testReconciler.validateSyncTargets(context.Background(), rs)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncTargetsEnv(t *testing.T) {
+	assert.Empty(t, syncTargetsEnv(nil))
+
+	target := syncTarget("east", "east-kubeconfig")
+	target.Variables = map[string]string{"REGION": "us-east1"}
+	envs := syncTargetsEnv([]v1beta1.SyncTarget{target})
+	require.Len(t, envs, 1)
+	assert.Equal(t, reconcilermanager.Targets, envs[0].Name)
+
+	var configs []reconcilermanager.TargetConfig
+	require.NoError(t, json.Unmarshal([]byte(envs[0].Value), &configs))
+	assert.Equal(t, []reconcilermanager.TargetConfig{{
+		Name:       "east",
+		Kubeconfig: SyncTargetsPath + "/east/" + TargetKubeconfigSecretKey,
+		Variables:  map[string]string{"REGION": "us-east1"},
+	}}, configs)
+}
@@ -0,0 +1,324 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// upgradePolicyName is the name of the ConfigSyncUpgradePolicy used by the
+	// reconciler-manager.
+	upgradePolicyName = "config-sync"
+
+	// defaultUpgradeWaveSize is the number of RootSyncs and RepoSyncs upgraded
+	// in each wave, if the ConfigSyncUpgradePolicy does not set it.
+	defaultUpgradeWaveSize = 10
+
+	// upgradeWaveCheckPeriod is how often the reconciler-manager checks whether
+	// a held reconciler upgrade can proceed.
+	upgradeWaveCheckPeriod = 30 * time.Second
+)
+
+// syncUpgrade is the upgrade state of the reconciler of a RootSync or
+// RepoSync.
+type syncUpgrade struct {
+	kind   string
+	ref    types.NamespacedName
+	labels map[string]string
+	// upgraded is true if the reconciler runs the latest images.
+	upgraded bool
+	// healthy is true if the reconciler is available and syncing without
+	// errors.
+	healthy bool
+}
+
+func (s syncUpgrade) String() string {
+	return fmt.Sprintf("%s/%s/%s", s.kind, s.ref.Namespace, s.ref.Name)
+}
+
+// holdUpgrade wraps mutateObject to keep the current container images of the
+// reconciler Deployment, if the ConfigSyncUpgradePolicy does not allow
+// upgrading the reconciler of the RootSync or RepoSync yet. held is set to
+// true when the images are kept, so that the upgrade can be retried later.
+func (r *reconcilerBase) holdUpgrade(ctx context.Context, syncRef types.NamespacedName, mutateObject mutateFn, held *bool) mutateFn {
+	return func(obj client.Object) error {
+		if err := mutateObject(obj); err != nil {
+			return err
+		}
+		declared, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			return fmt.Errorf("unexpected reconciler object type: %T", obj)
+		}
+		current := &appsv1.Deployment{}
+		if err := r.client.Get(ctx, client.ObjectKeyFromObject(declared), current); err != nil {
+			if apierrors.IsNotFound(err) {
+				// New reconcilers always start with the latest images.
+				return nil
+			}
+			return err
+		}
+		images := containerImages(declared)
+		if imagesMatch(current, images) {
+			return nil
+		}
+		allowed, err := r.upgradeAllowed(ctx, syncRef, images)
+		if err != nil || allowed {
+			return err
+		}
+		r.log.Info("Holding reconciler upgrade until the previous waves are healthy",
+			logFieldObject, syncRef.String(),
+			logFieldKind, r.syncKind)
+		keepCurrentImages(declared, current)
+		*held = true
+		return nil
+	}
+}
+
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=configsyncupgradepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=configsyncupgradepolicies/status,verbs=get;update;patch
+
+// upgradeAllowed returns true if the ConfigSyncUpgradePolicy allows upgrading
+// the reconciler of the RootSync or RepoSync to the images. The upgrade is
+// allowed if there is no ConfigSyncUpgradePolicy.
+func (r *reconcilerBase) upgradeAllowed(ctx context.Context, syncRef types.NamespacedName, images map[string]string) (bool, error) {
+	policy := &v1beta1.ConfigSyncUpgradePolicy{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: upgradePolicyName}, policy); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	syncs, err := r.syncUpgrades(ctx, images)
+	if err != nil {
+		return false, err
+	}
+	allowed, upgradeStatus, err := upgradeWaves(policy.Spec, syncs, r.syncKind, syncRef)
+	if err != nil {
+		return false, err
+	}
+	if !equality.Semantic.DeepEqual(policy.Status, upgradeStatus) {
+		existing := policy.DeepCopy()
+		policy.Status = upgradeStatus
+		if err := r.client.Status().Patch(ctx, policy, client.MergeFrom(existing)); err != nil {
+			r.log.Error(err, "Managed object status update failed",
+				logFieldObject, upgradePolicyName,
+				logFieldKind, configsync.ConfigSyncUpgradePolicyKind)
+		}
+	}
+	return allowed, nil
+}
+
+// syncUpgrades returns the upgrade state of the reconcilers of all the
+// RootSyncs and RepoSyncs.
+func (r *reconcilerBase) syncUpgrades(ctx context.Context, images map[string]string) ([]syncUpgrade, error) {
+	var syncs []syncUpgrade
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := r.client.List(ctx, rootSyncs); err != nil {
+		return nil, err
+	}
+	for _, rs := range rootSyncs.Items {
+		s := syncUpgrade{kind: configsync.RootSyncKind, ref: core.ObjectNamespacedName(&rs), labels: rs.Labels}
+		healthy := !rootsync.IsStalled(&rs) && !hasSyncErrors(rs.Status.Sync)
+		if err := r.reconcilerUpgrade(ctx, &s, core.RootReconcilerName(rs.Name), images, healthy); err != nil {
+			return nil, err
+		}
+		syncs = append(syncs, s)
+	}
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := r.client.List(ctx, repoSyncs); err != nil {
+		return nil, err
+	}
+	for _, rs := range repoSyncs.Items {
+		s := syncUpgrade{kind: configsync.RepoSyncKind, ref: core.ObjectNamespacedName(&rs), labels: rs.Labels}
+		healthy := !reposync.IsStalled(&rs) && !hasSyncErrors(rs.Status.Sync)
+		if err := r.reconcilerUpgrade(ctx, &s, core.NsReconcilerName(rs.Namespace, rs.Name), images, healthy); err != nil {
+			return nil, err
+		}
+		syncs = append(syncs, s)
+	}
+	return syncs, nil
+}
+
+// reconcilerUpgrade sets the upgrade state of the reconciler Deployment.
+func (r *reconcilerBase) reconcilerUpgrade(ctx context.Context, s *syncUpgrade, reconcilerName string, images map[string]string, syncHealthy bool) error {
+	d := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: configmanagement.ControllerNamespace, Name: reconcilerName}
+	if err := r.client.Get(ctx, key, d); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	s.upgraded = imagesMatch(d, images)
+	s.healthy = syncHealthy && deploymentAvailable(d)
+	return nil
+}
+
+// upgradeWaves splits the RootSyncs and RepoSyncs into the upgrade waves of the
+// policy, and returns whether the target may be upgraded, along with the
+// progress of the upgrade.
+//
+// The canaries are upgraded first, then the other RootSyncs and RepoSyncs in
+// a stable order. A wave is upgraded once all the reconcilers of the previous
+// waves are upgraded and healthy.
+func upgradeWaves(spec v1beta1.ConfigSyncUpgradePolicySpec, syncs []syncUpgrade, targetKind string, targetRef types.NamespacedName) (bool, v1beta1.ConfigSyncUpgradePolicyStatus, error) {
+	canarySelector := labels.Nothing()
+	if spec.CanarySelector != nil {
+		var err error
+		canarySelector, err = metav1.LabelSelectorAsSelector(spec.CanarySelector)
+		if err != nil {
+			return false, v1beta1.ConfigSyncUpgradePolicyStatus{}, fmt.Errorf("invalid canarySelector of ConfigSyncUpgradePolicy %s: %w", upgradePolicyName, err)
+		}
+	}
+	waveSize := defaultUpgradeWaveSize
+	if spec.WaveSize != nil && *spec.WaveSize > 0 {
+		waveSize = *spec.WaveSize
+	}
+
+	var canaries, others []syncUpgrade
+	for _, s := range syncs {
+		if canarySelector.Matches(labels.Set(s.labels)) {
+			canaries = append(canaries, s)
+		} else {
+			others = append(others, s)
+		}
+	}
+	sortSyncUpgrades(canaries)
+	sortSyncUpgrades(others)
+
+	// The waves after the canaries start at 1, even if there are no canaries,
+	// so that the wave indexes do not depend on the canaries matched.
+	firstWave := 0
+	if spec.CanarySelector != nil {
+		firstWave = 1
+	}
+	waves := make(map[string]int, len(syncs))
+	for _, s := range canaries {
+		waves[s.String()] = 0
+	}
+	for i, s := range others {
+		waves[s.String()] = firstWave + i/waveSize
+	}
+
+	upgradeStatus := v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: len(syncs)}
+	currentWave := -1
+	for _, s := range append(canaries, others...) {
+		if s.upgraded {
+			upgradeStatus.UpgradedSyncs++
+			if !s.healthy {
+				upgradeStatus.UnhealthySyncs = append(upgradeStatus.UnhealthySyncs, s.String())
+			}
+		}
+		if (!s.upgraded || !s.healthy) && currentWave == -1 {
+			currentWave = waves[s.String()]
+		}
+	}
+	if currentWave == -1 {
+		// Every reconciler is upgraded and healthy.
+		currentWave = waves[lastSyncUpgrade(canaries, others).String()]
+	}
+	upgradeStatus.CurrentWave = currentWave
+
+	target := syncUpgrade{kind: targetKind, ref: targetRef}
+	wave, found := waves[target.String()]
+	if !found {
+		// The RootSync or RepoSync is not listed yet.
+		return true, upgradeStatus, nil
+	}
+	return wave <= currentWave, upgradeStatus, nil
+}
+
+func sortSyncUpgrades(syncs []syncUpgrade) {
+	sort.Slice(syncs, func(i, j int) bool {
+		return syncs[i].String() < syncs[j].String()
+	})
+}
+
+func lastSyncUpgrade(canaries, others []syncUpgrade) syncUpgrade {
+	if len(others) > 0 {
+		return others[len(others)-1]
+	}
+	if len(canaries) > 0 {
+		return canaries[len(canaries)-1]
+	}
+	return syncUpgrade{}
+}
+
+func hasSyncErrors(syncStatus v1beta1.SyncStatus) bool {
+	return syncStatus.ErrorSummary != nil && syncStatus.ErrorSummary.TotalCount > 0
+}
+
+// containerImages returns the images of the Deployment, by container name.
+func containerImages(d *appsv1.Deployment) map[string]string {
+	images := make(map[string]string, len(d.Spec.Template.Spec.Containers))
+	for _, c := range d.Spec.Template.Spec.Containers {
+		images[c.Name] = c.Image
+	}
+	return images
+}
+
+// imagesMatch returns true if the containers of the Deployment that have an
+// image in images use that image.
+func imagesMatch(d *appsv1.Deployment, images map[string]string) bool {
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if image, found := images[c.Name]; found && image != c.Image {
+			return false
+		}
+	}
+	return true
+}
+
+// keepCurrentImages copies the images of the current containers to the
+// declared containers with the same name. New containers keep their declared
+// images.
+func keepCurrentImages(declared, current *appsv1.Deployment) {
+	images := containerImages(current)
+	for i, c := range declared.Spec.Template.Spec.Containers {
+		if image, found := images[c.Name]; found {
+			declared.Spec.Template.Spec.Containers[i].Image = image
+		}
+	}
+}
+
+// deploymentAvailable returns true if all the replicas of the latest
+// generation of the Deployment are available.
+func deploymentAvailable(d *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+func TestUpgradeWaves(t *testing.T) {
+	canaryLabels := map[string]string{"canary": "true"}
+	root := func(name string, upgraded, healthy bool, labels map[string]string) syncUpgrade {
+		return syncUpgrade{kind: configsync.RootSyncKind, ref: types.NamespacedName{Namespace: configsync.ControllerNamespace, Name: name},
+			labels: labels, upgraded: upgraded, healthy: healthy}
+	}
+	repo := func(ns string, upgraded, healthy bool) syncUpgrade {
+		return syncUpgrade{kind: configsync.RepoSyncKind, ref: types.NamespacedName{Namespace: ns, Name: configsync.RepoSyncName},
+			upgraded: upgraded, healthy: healthy}
+	}
+	canaryPolicy := v1beta1.ConfigSyncUpgradePolicySpec{
+		CanarySelector: &metav1.LabelSelector{MatchLabels: canaryLabels},
+		WaveSize:       pointer.Int(2),
+	}
+
+	testCases := []struct {
+		name        string
+		spec        v1beta1.ConfigSyncUpgradePolicySpec
+		syncs       []syncUpgrade
+		target      syncUpgrade
+		wantAllowed bool
+		wantStatus  v1beta1.ConfigSyncUpgradePolicyStatus
+	}{
+		{
+			name:        "canary upgraded first",
+			spec:        canaryPolicy,
+			syncs:       []syncUpgrade{root("canary", false, true, canaryLabels), repo("a", false, true), repo("b", false, true)},
+			target:      root("canary", false, true, canaryLabels),
+			wantAllowed: true,
+			wantStatus:  v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 3},
+		},
+		{
+			name:       "next wave waits for the canary",
+			spec:       canaryPolicy,
+			syncs:      []syncUpgrade{root("canary", false, true, canaryLabels), repo("a", false, true), repo("b", false, true)},
+			target:     repo("a", false, true),
+			wantStatus: v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 3},
+		},
+		{
+			name:       "unhealthy canary halts the upgrade",
+			spec:       canaryPolicy,
+			syncs:      []syncUpgrade{root("canary", true, false, canaryLabels), repo("a", false, true), repo("b", false, true)},
+			target:     repo("a", false, true),
+			wantStatus: v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 3, UpgradedSyncs: 1, UnhealthySyncs: []string{"RootSync/config-management-system/canary"}},
+		},
+		{
+			name:        "healthy canary starts the next wave",
+			spec:        canaryPolicy,
+			syncs:       []syncUpgrade{root("canary", true, true, canaryLabels), repo("a", false, true), repo("b", false, true), repo("c", false, true)},
+			target:      repo("b", false, true),
+			wantAllowed: true,
+			wantStatus:  v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 4, UpgradedSyncs: 1, CurrentWave: 1},
+		},
+		{
+			name:       "later wave waits for the current wave",
+			spec:       canaryPolicy,
+			syncs:      []syncUpgrade{root("canary", true, true, canaryLabels), repo("a", true, true), repo("b", false, true), repo("c", false, true)},
+			target:     repo("c", false, true),
+			wantStatus: v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 4, UpgradedSyncs: 2, CurrentWave: 1},
+		},
+		{
+			name:        "first wave without canaries",
+			spec:        v1beta1.ConfigSyncUpgradePolicySpec{WaveSize: pointer.Int(1)},
+			syncs:       []syncUpgrade{repo("a", false, true), repo("b", false, true)},
+			target:      repo("a", false, true),
+			wantAllowed: true,
+			wantStatus:  v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 2},
+		},
+		{
+			name:        "unknown target is allowed",
+			spec:        canaryPolicy,
+			syncs:       []syncUpgrade{root("canary", false, true, canaryLabels)},
+			target:      repo("new", false, true),
+			wantAllowed: true,
+			wantStatus:  v1beta1.ConfigSyncUpgradePolicyStatus{TotalSyncs: 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, upgradeStatus, err := upgradeWaves(tc.spec, tc.syncs, tc.target.kind, tc.target.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if allowed != tc.wantAllowed {
+				t.Errorf("upgradeWaves() allowed = %v, want %v", allowed, tc.wantAllowed)
+			}
+			if diff := cmp.Diff(tc.wantStatus, upgradeStatus); diff != "" {
+				t.Errorf("upgradeWaves() status diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
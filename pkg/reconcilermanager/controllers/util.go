@@ -15,11 +15,15 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	"kpt.dev/configsync/pkg/api/configsync"
@@ -35,7 +39,7 @@ import (
 )
 
 // hydrationEnvs returns environment variables for the hydration controller.
-func hydrationEnvs(sourceType string, gitConfig *v1beta1.Git, ociConfig *v1beta1.Oci, scope declared.Scope, reconcilerName, pollPeriod string) []corev1.EnvVar {
+func hydrationEnvs(sourceType string, gitConfig *v1beta1.Git, ociConfig *v1beta1.Oci, scope declared.Scope, syncName, reconcilerName, pollPeriod string) []corev1.EnvVar {
 	var result []corev1.EnvVar
 	var syncDir string
 	switch v1beta1.SourceType(sourceType) {
@@ -56,6 +60,10 @@ func hydrationEnvs(sourceType string, gitConfig *v1beta1.Git, ociConfig *v1beta1
 			Name:  reconcilermanager.ScopeKey,
 			Value: string(scope),
 		},
+		corev1.EnvVar{
+			Name:  reconcilermanager.SyncNameKey,
+			Value: syncName,
+		},
 		corev1.EnvVar{
 			Name:  reconcilermanager.ReconcilerNameKey,
 			Value: reconcilerName,
@@ -204,6 +212,30 @@ func ociSyncEnvs(image string, auth configsync.AuthType, period float64) []corev
 	return result
 }
 
+// ociSyncWebhookEnvs returns the environment variables that enable the
+// registry webhook receiver in the oci-sync container.
+func ociSyncWebhookEnvs(enabled bool) []corev1.EnvVar {
+	if !enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.OciSyncWebhookAddr,
+		Value: fmt.Sprintf(":%d", reconcilermanager.OciSyncWebhookPort),
+	}}
+}
+
+// watchSourceEnvs returns the environment variables that make the reconciler
+// reimport the source as soon as it is fetched.
+func watchSourceEnvs(enabled bool) []corev1.EnvVar {
+	if !enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.WatchSource,
+		Value: "true",
+	}}
+}
+
 const (
 	// helm-sync container specific environment variables.
 	helmSyncName     = "HELM_SYNC_USERNAME"
@@ -294,6 +326,379 @@ func ownerReference(kind, name string, uid types.UID) metav1.OwnerReference {
 	}
 }
 
+// upgradeSettlePeriodEnvs returns the environment variables that configure the
+// upgrade settle period of the reconciler container. Nothing is returned if
+// the settle period is unset, so that the reconciler Deployments of the
+// RSyncs without it do not change.
+func upgradeSettlePeriodEnvs(d *metav1.Duration) []corev1.EnvVar {
+	if d == nil || d.Duration <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.UpgradeSettlePeriod,
+		Value: d.Duration.String(),
+	}}
+}
+
+// supersedeInFlightApplyEnvs returns the environment variables that make the
+// reconciler stop applying a commit as soon as a newer commit is ready to
+// apply. Nothing is returned if it is disabled, so that the reconciler
+// Deployments of the RSyncs without it do not change.
+func supersedeInFlightApplyEnvs(enabled *bool) []corev1.EnvVar {
+	if enabled == nil || !*enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.SupersedeInFlightApply,
+		Value: "true",
+	}}
+}
+
+// partialApplyEnvs returns the environment variables that make the reconciler
+// only apply the objects declared in the changed source files. Nothing is
+// returned if it is disabled, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func partialApplyEnvs(enabled *bool) []corev1.EnvVar {
+	if enabled == nil || !*enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.PartialApply,
+		Value: "true",
+	}}
+}
+
+// commonMetadataEnvs returns the environment variables that configure the
+// labels and annotations that the reconciler container adds to every applied
+// object. Nothing is returned for an empty map, so that the reconciler
+// Deployments of the RSyncs without them do not change.
+func commonMetadataEnvs(labels, annotations map[string]string) []corev1.EnvVar {
+	var result []corev1.EnvVar
+	for _, env := range []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: reconcilermanager.CommonLabels, values: labels},
+		{name: reconcilermanager.CommonAnnotations, values: annotations},
+	} {
+		if len(env.values) == 0 {
+			continue
+		}
+		// Marshaling a map of strings does not fail, and sorts the keys so
+		// that the value is stable.
+		value, _ := json.Marshal(env.values)
+		result = append(result, corev1.EnvVar{
+			Name:  env.name,
+			Value: string(value),
+		})
+	}
+	return result
+}
+
+// pruneDelayEnvs returns the environment variables that configure the prune
+// delay of the reconciler container. Nothing is returned if the delay is
+// unset, so that the reconciler Deployments of the RSyncs without it do not
+// change.
+func pruneDelayEnvs(d *metav1.Duration) []corev1.EnvVar {
+	if d == nil || d.Duration <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.PruneDelay,
+		Value: d.Duration.String(),
+	}}
+}
+
+// selfUpdateTimeoutEnvs returns the environment variables that enable the
+// staged self-updates of the root reconciler container. Nothing is returned
+// if the timeout is unset, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func selfUpdateTimeoutEnvs(d *metav1.Duration) []corev1.EnvVar {
+	if d == nil || d.Duration <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.SelfUpdateTimeout,
+		Value: d.Duration.String(),
+	}}
+}
+
+// pruneObsoleteMetadataEnvs returns the environment variables that make the
+// reconciler remove the obsolete Config Sync metadata from the applied objects.
+// Nothing is returned if it is disabled, so that the reconciler Deployments of
+// the RSyncs without it do not change.
+func pruneObsoleteMetadataEnvs(enabled *bool) []corev1.EnvVar {
+	if enabled == nil || !*enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.PruneObsoleteMetadata,
+		Value: "true",
+	}}
+}
+
+// migrateClientSideApplyEnvs returns the environment variables that make the
+// reconciler migrate the objects managed with client-side apply. Nothing is
+// returned if it is disabled, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func migrateClientSideApplyEnvs(enabled *bool) []corev1.EnvVar {
+	if enabled == nil || !*enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.MigrateClientSideApply,
+		Value: "true",
+	}}
+}
+
+// normalizeDeclarationsEnvs returns the environment variables that make the
+// reconciler normalize the declarations before comparing them. Nothing is
+// returned if it is disabled, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func normalizeDeclarationsEnvs(enabled *bool) []corev1.EnvVar {
+	if enabled == nil || !*enabled {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.NormalizeDeclarations,
+		Value: "true",
+	}}
+}
+
+// retryBudgetEnvs returns the environment variables that configure the retry
+// budget of the reconciler container. Nothing is returned if the budget is
+// unset, so that the reconciler Deployments of the RSyncs without it do not
+// change.
+func retryBudgetEnvs(n *int64) []corev1.EnvVar {
+	if n == nil || *n <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.RetryBudget,
+		Value: strconv.FormatInt(*n, 10),
+	}}
+}
+
+// parseConcurrencyEnvs returns the environment variables that configure how
+// many source files the reconciler container parses at once. Nothing is
+// returned if it is unset, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func parseConcurrencyEnvs(n *int64) []corev1.EnvVar {
+	if n == nil || *n <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.ParseConcurrency,
+		Value: strconv.FormatInt(*n, 10),
+	}}
+}
+
+// approvalTimeoutEnvs returns the environment variables that configure the
+// approval timeout of the reconciler container. Nothing is returned if the
+// timeout is unset, so that the reconciler Deployments of the RSyncs without
+// it do not change.
+func approvalTimeoutEnvs(d *metav1.Duration) []corev1.EnvVar {
+	if d == nil || d.Duration <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.ApprovalTimeout,
+		Value: d.Duration.String(),
+	}}
+}
+
+// statusUpdatePeriodEnvs returns the environment variables that configure
+// the status update period of the reconciler container. Nothing is returned
+// if the period is unset, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func statusUpdatePeriodEnvs(d *metav1.Duration) []corev1.EnvVar {
+	if d == nil || d.Duration <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.StatusUpdatePeriod,
+		Value: d.Duration.String(),
+	}}
+}
+
+// reconcilerPollingPeriod returns the polling period of the reconciler
+// container, which is the one set on the RSync, if any, or the polling period
+// of the reconciler-manager.
+func reconcilerPollingPeriod(d *metav1.Duration, defaultPeriod time.Duration) string {
+	if d == nil || d.Duration <= 0 {
+		return defaultPeriod.String()
+	}
+	return d.Duration.String()
+}
+
+// hierarchicalDirsEnvs returns the environment variable of the hierarchical
+// directories of an unstructured repository in the reconciler container.
+// Nothing is returned if there are none, so that the reconciler Deployments
+// of the RootSyncs without them do not change.
+func hierarchicalDirsEnvs(dirs []string) []corev1.EnvVar {
+	if len(dirs) == 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.HierarchicalDirs,
+		Value: strings.Join(dirs, ","),
+	}}
+}
+
+// pruneKindsEnvs returns the environment variables that configure the kinds
+// and the number of the objects that the reconciler container prunes. Nothing
+// is returned if they are unset, so that the reconciler Deployments of the
+// RSyncs without them do not change.
+func pruneKindsEnvs(prune *v1beta1.PruneSpec) []corev1.EnvVar {
+	if prune == nil {
+		return nil
+	}
+	var result []corev1.EnvVar
+	if len(prune.AllowedKinds) > 0 {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.PruneAllowedKinds,
+			Value: groupKindsString(prune.AllowedKinds),
+		})
+	}
+	if len(prune.DeniedKinds) > 0 {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.PruneDeniedKinds,
+			Value: groupKindsString(prune.DeniedKinds),
+		})
+	}
+	if prune.MaxPercentage != nil {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.PruneMaxPercentage,
+			Value: strconv.FormatInt(*prune.MaxPercentage, 10),
+		})
+	}
+	if prune.MaxCount != nil {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.PruneMaxCount,
+			Value: strconv.FormatInt(*prune.MaxCount, 10),
+		})
+	}
+	return result
+}
+
+// rolloutEnvs returns the environment variables that configure the pacing of
+// the workload updates of the reconciler container. Nothing is returned if the
+// rollout spec is unset, so that the reconciler Deployments of the RSyncs
+// without it do not change.
+func rolloutEnvs(rollout *v1beta1.RolloutSpec) []corev1.EnvVar {
+	if rollout == nil {
+		return nil
+	}
+	var result []corev1.EnvVar
+	if rollout.MaxConcurrentUpdates != nil {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.RolloutMaxConcurrentUpdates,
+			Value: strconv.FormatInt(*rollout.MaxConcurrentUpdates, 10),
+		})
+	}
+	if rollout.MaxConcurrentUpdatesPerNamespace != nil {
+		result = append(result, corev1.EnvVar{
+			Name:  reconcilermanager.RolloutMaxConcurrentUpdatesPerNamespace,
+			Value: strconv.FormatInt(*rollout.MaxConcurrentUpdatesPerNamespace, 10),
+		})
+	}
+	return result
+}
+
+// syncWindowsEnvs returns the environment variable of the JSON-encoded sync
+// windows in the reconciler container. Nothing is returned if there are none,
+// so that the reconciler Deployments of the RSyncs without them do not change.
+func syncWindowsEnvs(windows []v1beta1.SyncWindow) []corev1.EnvVar {
+	if len(windows) == 0 {
+		return nil
+	}
+	// Marshalling a slice of structs of strings and durations cannot fail.
+	value, _ := json.Marshal(windows)
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.SyncWindows,
+		Value: string(value),
+	}}
+}
+
+// dryRunEnvs returns the environment variable enabling the dry-run mode in the
+// reconciler container. Nothing is returned if it is disabled, so that the
+// reconciler Deployments of the RSyncs which sync normally do not change.
+func dryRunEnvs(dryRun bool) []corev1.EnvVar {
+	if !dryRun {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.DryRun,
+		Value: "true",
+	}}
+}
+
+// renamesEnvs returns the environment variable of the JSON-encoded rename
+// rules in the reconciler container. Nothing is returned if there are none,
+// so that the reconciler Deployments of the RSyncs without them do not change.
+func renamesEnvs(renames []v1beta1.RenameRule) []corev1.EnvVar {
+	if len(renames) == 0 {
+		return nil
+	}
+	// Marshalling a slice of structs of strings cannot fail.
+	value, _ := json.Marshal(renames)
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.Renames,
+		Value: string(value),
+	}}
+}
+
+// groupKindsString returns the comma-separated list of the GroupKinds in the
+// `Kind.group` format.
+func groupKindsString(gks []metav1.GroupKind) string {
+	var values []string
+	for _, gk := range gks {
+		values = append(values, schema.GroupKind{Group: gk.Group, Kind: gk.Kind}.String())
+	}
+	return strings.Join(values, ",")
+}
+
+// apiPriorityGroupEnvs returns the environment variables that configure the
+// API priority group of the reconciler container. Nothing is returned if the
+// group is unset, so that the reconciler Deployments of the RSyncs without it
+// do not change.
+func apiPriorityGroupEnvs(group string) []corev1.EnvVar {
+	if group == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.APIPriorityGroup,
+		Value: group,
+	}}
+}
+
+// metricsLevelEnvs returns the environment variables that configure the
+// metrics emitted by the reconciler container. Nothing is returned if the
+// level is unset, so that the reconciler Deployments of the RSyncs without it
+// do not change.
+func metricsLevelEnvs(level string) []corev1.EnvVar {
+	if level == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.MetricsLevel,
+		Value: level,
+	}}
+}
+
+// exportSinkEnvs returns the environment variables of the sink which the
+// reconciler container exports its records to. Nothing is returned if
+// exporting is disabled.
+func exportSinkEnvs(sink string) []corev1.EnvVar {
+	if sink == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.ExportSink,
+		Value: sink,
+	}}
+}
+
 // PollingPeriod parses the polling duration from the environment variable.
 // If the variable is not present, it returns the default value.
 func PollingPeriod(envName string, defaultValue time.Duration) time.Duration {
@@ -41,6 +41,24 @@ const CACertSecretKey = "cert"
 // CACertPath is the path where the certificate is mounted.
 const CACertPath = "/etc/ca-cert"
 
+// KnownHostsVolume is the volume name of the SSH known hosts.
+const KnownHostsVolume = "known-hosts"
+
+// KnownHostsSecretKey is the name of the key in the Secret's data map whose value holds the SSH known hosts.
+const KnownHostsSecretKey = "known_hosts"
+
+// KnownHostsPath is the path where the SSH known hosts are mounted.
+const KnownHostsPath = "/etc/known-hosts"
+
+// TargetKubeconfigVolume is the volume name of the kubeconfig of the target cluster.
+const TargetKubeconfigVolume = "target-kubeconfig"
+
+// TargetKubeconfigSecretKey is the name of the key in the Secret's data map whose value holds the kubeconfig of the target cluster.
+const TargetKubeconfigSecretKey = "kubeconfig"
+
+// TargetKubeconfigPath is the path where the kubeconfig of the target cluster is mounted.
+const TargetKubeconfigPath = "/etc/target-kubeconfig"
+
 // defaultMode is the default permission of the `gcp-ksa` volume.
 var defaultMode int32 = 0644
 
@@ -148,3 +166,63 @@ func volumeMounts(auth configsync.AuthType, caCertSecretRef, sourceType string,
 	})
 	return volumeMount
 }
+
+// targetKubeconfigVolume returns the volume that holds the kubeconfig of the
+// target cluster from the Secret secretName.
+func targetKubeconfigVolume(secretName string) corev1.Volume {
+	return corev1.Volume{
+		Name: TargetKubeconfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  TargetKubeconfigSecretKey,
+						Path: TargetKubeconfigSecretKey,
+					},
+				},
+				DefaultMode: &defaultMode,
+			},
+		},
+	}
+}
+
+// targetKubeconfigVolumeMount returns the VolumeMount of the volume returned
+// by targetKubeconfigVolume.
+func targetKubeconfigVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      TargetKubeconfigVolume,
+		MountPath: TargetKubeconfigPath,
+		ReadOnly:  true,
+	}
+}
+
+// knownHostsVolume returns the volume that holds the SSH known hosts from the
+// Secret secretName.
+func knownHostsVolume(secretName string) corev1.Volume {
+	return corev1.Volume{
+		Name: KnownHostsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  KnownHostsSecretKey,
+						Path: KnownHostsSecretKey,
+					},
+				},
+				DefaultMode: &defaultMode,
+			},
+		},
+	}
+}
+
+// knownHostsVolumeMount returns the VolumeMount of the volume returned by
+// knownHostsVolume.
+func knownHostsVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      KnownHostsVolume,
+		MountPath: KnownHostsPath,
+		ReadOnly:  true,
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	"kpt.dev/configsync/pkg/webhook/configuration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// webhookHealthCheckPeriod is how often the reconciler-manager checks the
+	// health of the admission webhook.
+	webhookHealthCheckPeriod = time.Minute
+
+	// webhookDialTimeout is how long the reconciler-manager waits for the TLS
+	// handshake with the admission webhook.
+	webhookDialTimeout = 5 * time.Second
+
+	// webhookCertRotationWindow is how long before its expiration the serving
+	// certificate is rotated by the cert-controller of the admission webhook.
+	webhookCertRotationWindow = 90 * 24 * time.Hour
+
+	// webhookCACertKey and webhookServingCertKey are the keys of the CA and
+	// of the serving certificate in the Secret of the admission webhook.
+	webhookCACertKey      = "ca.crt"
+	webhookServingCertKey = "tls.crt"
+)
+
+// The reasons of the WebhookDegraded condition.
+const (
+	// WebhookCertMissingReason means that the Secret of the admission webhook
+	// does not have a valid CA or serving certificate.
+	WebhookCertMissingReason = "CertificateMissing"
+	// WebhookCertExpiredReason means that the CA or the serving certificate
+	// of the admission webhook expired.
+	WebhookCertExpiredReason = "CertificateExpired"
+	// WebhookCertRotatingReason means that the CA or the serving certificate
+	// of the admission webhook is being rotated, and is not yet in use
+	// everywhere.
+	WebhookCertRotatingReason = "CertificateRotating"
+	// WebhookUnreachableReason means that the TLS handshake with the
+	// admission webhook failed.
+	WebhookUnreachableReason = "Unreachable"
+)
+
+// webhookHealth is the health of the admission webhook. The zero value means
+// that the webhook is healthy, or disabled.
+type webhookHealth struct {
+	reason  string
+	message string
+}
+
+func (h webhookHealth) degraded() bool {
+	return h.reason != ""
+}
+
+// WebhookHealthChecker periodically checks the freshness of the serving
+// certificate and CA of the Config Sync admission webhook, and whether the
+// webhook is reachable, and reports a degraded webhook with the
+// WebhookDegraded condition of all the RootSyncs and RepoSyncs and with the
+// webhook_degraded metric.
+// A failing webhook otherwise only shows as drift prevention quietly not
+// working.
+type WebhookHealthChecker struct {
+	client client.Client
+	log    logr.Logger
+	// dial performs a TLS handshake with the address.
+	dial func(ctx context.Context, addr string, config *tls.Config) error
+	now  func() time.Time
+}
+
+// NewWebhookHealthChecker returns a new WebhookHealthChecker.
+func NewWebhookHealthChecker(c client.Client, log logr.Logger) *WebhookHealthChecker {
+	return &WebhookHealthChecker{
+		client: c,
+		log:    log,
+		dial:   dialTLS,
+		now:    time.Now,
+	}
+}
+
+// Start implements manager.Runnable.
+func (w *WebhookHealthChecker) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := w.check(ctx); err != nil {
+			w.log.Error(err, "Failed to check the health of the admission webhook")
+		}
+	}, webhookHealthCheckPeriod)
+	return nil
+}
+
+func (w *WebhookHealthChecker) check(ctx context.Context) error {
+	health, err := w.health(ctx)
+	if err != nil {
+		return err
+	}
+	if health.degraded() {
+		w.log.Info("The admission webhook is degraded", "reason", health.reason, "message", health.message)
+	}
+	metrics.RecordWebhookDegraded(ctx, health.degraded())
+	return w.setConditions(ctx, health)
+}
+
+// health returns the health of the admission webhook. A webhook without a
+// ValidatingWebhookConfiguration is disabled, and reported as healthy.
+func (w *WebhookHealthChecker) health(ctx context.Context) (webhookHealth, error) {
+	webhookCfg := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := w.client.Get(ctx, client.ObjectKey{Name: configuration.Name}, webhookCfg); err != nil {
+		if apierrors.IsNotFound(err) {
+			return webhookHealth{}, nil
+		}
+		return webhookHealth{}, errors.Wrapf(err, "failed to get the ValidatingWebhookConfiguration %s", configuration.Name)
+	}
+
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: configuration.CertSecretName}
+	if err := w.client.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return webhookHealth{
+				reason:  WebhookCertMissingReason,
+				message: fmt.Sprintf("The Secret %s of the admission webhook is missing", secretKey),
+			}, nil
+		}
+		return webhookHealth{}, errors.Wrapf(err, "failed to get the Secret %s", secretKey)
+	}
+	caCert, err := parseCertificate(secret.Data[webhookCACertKey])
+	if err != nil {
+		return webhookHealth{
+			reason:  WebhookCertMissingReason,
+			message: fmt.Sprintf("The CA of the admission webhook in the Secret %s is invalid: %v", secretKey, err),
+		}, nil
+	}
+	servingCert, err := parseCertificate(secret.Data[webhookServingCertKey])
+	if err != nil {
+		return webhookHealth{
+			reason:  WebhookCertMissingReason,
+			message: fmt.Sprintf("The serving certificate of the admission webhook in the Secret %s is invalid: %v", secretKey, err),
+		}, nil
+	}
+
+	now := w.now()
+	for _, cert := range []struct {
+		name string
+		cert *x509.Certificate
+	}{
+		{name: "CA", cert: caCert},
+		{name: "serving certificate", cert: servingCert},
+	} {
+		if now.After(cert.cert.NotAfter) {
+			return webhookHealth{
+				reason:  WebhookCertExpiredReason,
+				message: fmt.Sprintf("The %s of the admission webhook expired at %s", cert.name, cert.cert.NotAfter.UTC().Format(time.RFC3339)),
+			}, nil
+		}
+	}
+	if err := servingCert.CheckSignatureFrom(caCert); err != nil {
+		return webhookHealth{
+			reason:  WebhookCertRotatingReason,
+			message: "The serving certificate of the admission webhook is not yet signed by the new CA",
+		}, nil
+	}
+	for _, webhook := range webhookCfg.Webhooks {
+		if !containsCertificate(webhook.ClientConfig.CABundle, caCert) {
+			return webhookHealth{
+				reason:  WebhookCertRotatingReason,
+				message: fmt.Sprintf("The CA bundle of the webhook %s does not yet include the new CA", webhook.Name),
+			}, nil
+		}
+	}
+	if now.Add(webhookCertRotationWindow).After(servingCert.NotAfter) {
+		return webhookHealth{
+			reason:  WebhookCertRotatingReason,
+			message: fmt.Sprintf("The serving certificate of the admission webhook expires at %s and is not yet rotated", servingCert.NotAfter.UTC().Format(time.RFC3339)),
+		}, nil
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	addr := net.JoinHostPort(configuration.ServiceHost, fmt.Sprint(configuration.ServicePort))
+	tlsConfig := &tls.Config{RootCAs: roots, ServerName: configuration.ServiceHost, MinVersion: tls.VersionTLS12}
+	if err := w.dial(ctx, addr, tlsConfig); err != nil {
+		return webhookHealth{
+			reason:  WebhookUnreachableReason,
+			message: fmt.Sprintf("Failed to connect to the admission webhook at %s: %v", addr, err),
+		}, nil
+	}
+	return webhookHealth{}, nil
+}
+
+// setConditions sets or removes the WebhookDegraded condition of all the
+// RootSyncs and RepoSyncs.
+func (w *WebhookHealthChecker) setConditions(ctx context.Context, health webhookHealth) error {
+	var errs []error
+	rootSyncs := &v1beta1.RootSyncList{}
+	if err := w.client.List(ctx, rootSyncs); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list the RootSyncs"))
+	}
+	for i := range rootSyncs.Items {
+		rs := &rootSyncs.Items[i]
+		var updated bool
+		if health.degraded() {
+			updated = rootsync.SetWebhookDegraded(rs, health.reason, health.message)
+		} else {
+			updated = rootsync.RemoveCondition(rs, v1beta1.RootSyncWebhookDegraded)
+		}
+		if updated {
+			if err := w.client.Status().Update(ctx, rs); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to update the status of the RootSync %s", client.ObjectKeyFromObject(rs)))
+			}
+		}
+	}
+	repoSyncs := &v1beta1.RepoSyncList{}
+	if err := w.client.List(ctx, repoSyncs); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list the RepoSyncs"))
+	}
+	for i := range repoSyncs.Items {
+		rs := &repoSyncs.Items[i]
+		var updated bool
+		if health.degraded() {
+			updated = reposync.SetWebhookDegraded(rs, health.reason, health.message)
+		} else {
+			updated = reposync.RemoveCondition(rs, v1beta1.RepoSyncWebhookDegraded)
+		}
+		if updated {
+			if err := w.client.Status().Update(ctx, rs); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to update the status of the RepoSync %s", client.ObjectKeyFromObject(rs)))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// parseCertificate parses the first PEM-encoded certificate.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// containsCertificate returns true if the PEM-encoded bundle includes the
+// certificate.
+func containsCertificate(bundle []byte, cert *x509.Certificate) bool {
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return false
+		}
+		if block.Type == "CERTIFICATE" {
+			if bundleCert, err := x509.ParseCertificate(block.Bytes); err == nil && bundleCert.Equal(cert) {
+				return true
+			}
+		}
+	}
+}
+
+// dialTLS performs a TLS handshake with the address.
+func dialTLS(ctx context.Context, addr string, config *tls.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookDialTimeout)
+	defer cancel()
+	dialer := &tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
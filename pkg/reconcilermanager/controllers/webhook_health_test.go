@@ -0,0 +1,183 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/rootsync"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/webhook/configuration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTestCertificate returns a PEM-encoded certificate valid until notAfter.
+// The certificate is self-signed if parent is nil.
+func newTestCertificate(t *testing.T, name string, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestWebhookHealthChecker(t *testing.T) {
+	now := time.Now()
+	year := now.Add(365 * 24 * time.Hour)
+	ca, caKey, caPEM := newTestCertificate(t, "config-sync-ca", year, nil, nil)
+	_, _, servingPEM := newTestCertificate(t, configuration.ServiceHost, year, ca, caKey)
+	_, _, expiringPEM := newTestCertificate(t, configuration.ServiceHost, now.Add(24*time.Hour), ca, caKey)
+	_, _, expiredPEM := newTestCertificate(t, configuration.ServiceHost, now.Add(-time.Minute), ca, caKey)
+	_, _, otherCAPEM := newTestCertificate(t, "other-ca", year, nil, nil)
+
+	newWebhookCfg := func(caBundle []byte) *admissionv1.ValidatingWebhookConfiguration {
+		cfg := &admissionv1.ValidatingWebhookConfiguration{
+			Webhooks: []admissionv1.ValidatingWebhook{{
+				Name:         configuration.Name,
+				ClientConfig: admissionv1.WebhookClientConfig{CABundle: caBundle},
+			}},
+		}
+		cfg.Name = configuration.Name
+		return cfg
+	}
+	newSecret := func(servingCert []byte) *corev1.Secret {
+		secret := &corev1.Secret{
+			Data: map[string][]byte{
+				webhookCACertKey:      caPEM,
+				webhookServingCertKey: servingCert,
+			},
+		}
+		secret.Name = configuration.CertSecretName
+		secret.Namespace = configsync.ControllerNamespace
+		return secret
+	}
+
+	testCases := []struct {
+		name       string
+		objs       []client.Object
+		dialErr    error
+		wantReason string
+	}{
+		{
+			name: "webhook disabled",
+		},
+		{
+			name: "healthy",
+			objs: []client.Object{newWebhookCfg(caPEM), newSecret(servingPEM)},
+		},
+		{
+			name:       "secret missing",
+			objs:       []client.Object{newWebhookCfg(caPEM)},
+			wantReason: WebhookCertMissingReason,
+		},
+		{
+			name:       "serving certificate expired",
+			objs:       []client.Object{newWebhookCfg(caPEM), newSecret(expiredPEM)},
+			wantReason: WebhookCertExpiredReason,
+		},
+		{
+			name:       "serving certificate due for rotation",
+			objs:       []client.Object{newWebhookCfg(caPEM), newSecret(expiringPEM)},
+			wantReason: WebhookCertRotatingReason,
+		},
+		{
+			name:       "CA bundle not yet injected",
+			objs:       []client.Object{newWebhookCfg(otherCAPEM), newSecret(servingPEM)},
+			wantReason: WebhookCertRotatingReason,
+		},
+		{
+			name:       "unreachable",
+			objs:       []client.Object{newWebhookCfg(caPEM), newSecret(servingPEM)},
+			dialErr:    errors.New("connection refused"),
+			wantReason: WebhookUnreachableReason,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			rootSync := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+			// A stale condition is removed once the webhook is healthy.
+			rootsync.SetWebhookDegraded(rootSync, WebhookUnreachableReason, "stale")
+			repoSync := fake.RepoSyncObjectV1Beta1("bookstore", configsync.RepoSyncName)
+			objs := append([]client.Object{rootSync, repoSync}, tc.objs...)
+			fakeClient := syncerFake.NewClient(t, core.Scheme, objs...)
+
+			checker := NewWebhookHealthChecker(fakeClient, logr.Discard())
+			checker.now = func() time.Time { return now }
+			var dialed bool
+			checker.dial = func(_ context.Context, addr string, config *tls.Config) error {
+				dialed = true
+				require.Equal(t, configuration.ServiceHost+":443", addr)
+				require.Equal(t, configuration.ServiceHost, config.ServerName)
+				return tc.dialErr
+			}
+			require.NoError(t, checker.check(ctx))
+
+			gotRootSync := &v1beta1.RootSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(rootSync), gotRootSync))
+			gotRepoSync := &v1beta1.RepoSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(repoSync), gotRepoSync))
+			rootCond := rootsync.GetCondition(gotRootSync.Status.Conditions, v1beta1.RootSyncWebhookDegraded)
+			repoCond := reposync.GetCondition(gotRepoSync.Status.Conditions, v1beta1.RepoSyncWebhookDegraded)
+			if tc.wantReason == "" {
+				require.Nil(t, rootCond)
+				require.Nil(t, repoCond)
+				return
+			}
+			require.NotNil(t, rootCond)
+			require.NotNil(t, repoCond)
+			require.Equal(t, metav1.ConditionTrue, rootCond.Status)
+			require.Equal(t, tc.wantReason, rootCond.Reason)
+			require.Equal(t, tc.wantReason, repoCond.Reason)
+			require.Equal(t, tc.wantReason == WebhookUnreachableReason, dialed)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcilermanager
+
+// TargetConfig is the configuration of one of the clusters that a root
+// reconciler fans the resources out to.
+type TargetConfig struct {
+	// Name identifies the target in the RootSync status.
+	Name string `json:"name"`
+	// Kubeconfig is the path to the kubeconfig of the target cluster.
+	Kubeconfig string `json:"kubeconfig"`
+	// Variables are substituted for the `${NAME}` references in the string
+	// fields of the resources synced to the target.
+	Variables map[string]string `json:"variables,omitempty"`
+}
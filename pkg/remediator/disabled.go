@@ -0,0 +1,68 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remediator
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/remediator/watch"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// disabled is a Remediator which does not watch or correct drift.
+type disabled struct{}
+
+var _ Runner = disabled{}
+
+// NewDisabled returns a Remediator which does not watch or correct drift.
+// It is used when the declared resources are transformed before they are
+// applied, so drift can only be corrected by re-applying them.
+func NewDisabled() Runner {
+	return disabled{}
+}
+
+// Start returns a closed channel, as there are no workers to wait for.
+func (disabled) Start(context.Context) <-chan struct{} {
+	doneCh := make(chan struct{})
+	close(doneCh)
+	return doneCh
+}
+
+// Pause implements Interface.
+func (disabled) Pause() {}
+
+// Resume implements Interface.
+func (disabled) Resume() {}
+
+// NeedsUpdate implements Interface.
+func (disabled) NeedsUpdate() bool { return false }
+
+// UpdateWatches implements Interface.
+func (disabled) UpdateWatches(context.Context, map[schema.GroupVersionKind]struct{}) status.MultiError {
+	return nil
+}
+
+// ManagementConflict implements Interface.
+func (disabled) ManagementConflict() bool { return false }
+
+// ConflictErrors implements Interface.
+func (disabled) ConflictErrors() []status.ManagementConflictError { return nil }
+
+// FightErrors implements Interface.
+func (disabled) FightErrors() []status.Error { return nil }
+
+// WatchFailures implements Interface.
+func (disabled) WatchFailures() []watch.Failure { return nil }
@@ -0,0 +1,66 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EmergencyOverrideExpiredReason is the reason of the events emitted when the
+// remediator reverts an object whose emergency override expired.
+const EmergencyOverrideExpiredReason = "EmergencyOverrideExpired"
+
+// clearExpiredEmergencyOverride removes the expired emergency override
+// annotation from the remediated object, and emits an event on the object to
+// report that its manual changes were reverted. An override whose expiry is
+// later than the maximum is cleared the same way.
+// Failures are logged, but otherwise ignored, because the object was already
+// remediated, and an expired override has no effect.
+func clearExpiredEmergencyOverride(ctx context.Context, c client.Client, obj client.Object, expiry, now time.Time) {
+	id := core.IDOf(obj)
+	reason := fmt.Sprintf("the emergency override expired at %s", expiry.Format(time.RFC3339))
+	if metadata.EmergencyOverrideTooLong(expiry, now) {
+		reason = fmt.Sprintf("the emergency override until %s exceeds the maximum of %s",
+			expiry.Format(time.RFC3339), metadata.EmergencyOverrideMaxDuration)
+	}
+	klog.Warningf("Remediator reverted %q, because %s", id, reason)
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, metadata.EmergencyOverrideUntilKey))
+	if err := c.Patch(ctx, u, client.RawPatch("application/merge-patch+json", patch)); err != nil {
+		klog.Warningf("Failed to remove the expired %s annotation from %q: %v", metadata.EmergencyOverrideUntilKey, id, err)
+	}
+
+	message := "Reverted the manual changes, because " + reason
+	e := event.New(event.Reference(obj, obj.GetObjectKind().GroupVersionKind()), corev1.EventTypeWarning, EmergencyOverrideExpiredReason, message,
+		"remediator", metav1.NewTime(now))
+	if err := c.Create(ctx, e); err != nil {
+		klog.Warningf("Failed to record the %s event for %q: %v", EmergencyOverrideExpiredReason, id, err)
+	}
+}
@@ -22,9 +22,11 @@ import (
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/export"
 	"kpt.dev/configsync/pkg/importer/analyzer/validation/nonhierarchical"
 	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/status"
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
 	syncerreconcile "kpt.dev/configsync/pkg/syncer/reconcile"
@@ -69,9 +71,16 @@ func newReconciler(
 
 // Remediate takes a client.Object representing the object to update, and then
 // ensures that the version on the server matches it.
+// A nil or queue.Deleted object signals that the object was deleted.
 func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Object) status.Error {
 	start := time.Now()
 
+	var deleted client.Object
+	if d, wasDeleted := obj.(*queue.Deleted); wasDeleted {
+		deleted = d.Object
+		obj = nil
+	}
+
 	declU, commit, found := r.declared.Get(id)
 	// Yes, this if block is necessary because Go is pedantic about nil interfaces.
 	// 1) var decl client.Object = declU results in a panic.
@@ -89,6 +98,7 @@ func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Objec
 
 	// Record duration, even if there's an error
 	metrics.RecordRemediateDuration(ctx, metrics.StatusTagKey(err), id.Kind, start)
+	exportDrift(id, objDiff.Operation(r.scope, r.syncName), commit, err)
 
 	if err != nil {
 		switch err.Code() {
@@ -103,10 +113,34 @@ func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Objec
 		return err
 	}
 
+	if deleted != nil && objDiff.Operation(r.scope, r.syncName) == diff.Create {
+		r.reportRecreation(ctx, id, deleted)
+	}
 	r.fightHandler.RemoveFightError(id)
 	return nil
 }
 
+// exportDrift exports the record of the drift corrected by the operation,
+// unless there was no drift.
+func exportDrift(id core.ID, operation diff.Operation, commit string, err status.Error) {
+	if operation == diff.NoOp || !export.Enabled() {
+		return
+	}
+	record := export.DriftEvent{
+		Time:      time.Now(),
+		Commit:    commit,
+		Group:     id.Group,
+		Kind:      id.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+		Operation: string(operation),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	export.RecordDrift(record)
+}
+
 // Remediate takes diff (declared & actual) and ensures the server matches the
 // declared state.
 func (r *reconciler) remediate(ctx context.Context, id core.ID, objDiff diff.Diff) status.Error {
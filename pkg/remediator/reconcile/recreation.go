@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/util/event"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectRecreatedReason is the reason of the events recorded when the
+// remediator recreates a managed object deleted out-of-band.
+const ObjectRecreatedReason = "ObjectRecreated"
+
+// reportRecreation logs, records an event on the RootSync or RepoSync, and
+// increments the ResourcesRecreated metric for a managed object that was
+// deleted out-of-band and has just been recreated by the remediator.
+// Failures to record the event are logged, but otherwise ignored, because
+// events are best effort.
+func (r *reconciler) reportRecreation(ctx context.Context, id core.ID, deleted client.Object) {
+	deletedBy := lastModifier(deleted)
+	message := fmt.Sprintf("Recreated managed object %s, which was deleted out-of-band", id)
+	if deletedBy != "" {
+		message += fmt.Sprintf(" (last modified by %q)", deletedBy)
+	}
+	klog.Warningf("Remediator: %s", message)
+	metrics.RecordResourceRecreated(ctx, id.Kind)
+
+	gvk := kinds.RepoSyncV1Beta1()
+	namespace := string(r.scope)
+	if r.scope == declared.RootReconciler {
+		gvk = kinds.RootSyncV1Beta1()
+		namespace = configsync.ControllerNamespace
+	}
+	involved := corev1.ObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       r.syncName,
+		Namespace:  namespace,
+	}
+	e := event.New(involved, corev1.EventTypeWarning, ObjectRecreatedReason, message,
+		metrics.RemediatorController, metav1.Now())
+	if err := r.applier.GetClient().Create(ctx, e); err != nil {
+		klog.Warningf("Failed to record the %s event for %s: %v", ObjectRecreatedReason, id, err)
+	}
+}
+
+// lastModifier returns the field manager, other than Config Sync, which most
+// recently modified the last observed state of a deleted object, or an empty
+// string if unknown. The API server does not record who deleted an object, so
+// this is the best available hint, e.g. the controller which removed a
+// finalizer or the client which set the deletion timestamp.
+func lastModifier(obj client.Object) string {
+	if obj == nil {
+		return ""
+	}
+	var manager string
+	var latest *metav1.Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == configsync.FieldManager || entry.Time == nil {
+			continue
+		}
+		if latest == nil || !entry.Time.Before(latest) {
+			manager = entry.Manager
+			latest = entry.Time
+		}
+	}
+	return manager
+}
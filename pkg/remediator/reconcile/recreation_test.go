@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/remediator/queue"
+	"kpt.dev/configsync/pkg/syncer/syncertest"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/testing/testmetrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestLastModifier(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	testCases := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		want          string
+	}{
+		{
+			name: "no managed fields",
+			want: "",
+		},
+		{
+			name: "only managed by Config Sync",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: configsync.FieldManager, Time: &later},
+			},
+			want: "",
+		},
+		{
+			name: "most recent manager other than Config Sync",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl-edit", Time: &earlier},
+				{Manager: "some-controller", Time: &later},
+				{Manager: configsync.FieldManager, Time: &later},
+			},
+			want: "some-controller",
+		},
+		{
+			name: "entries without time are ignored",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl-edit", Time: &earlier},
+				{Manager: "unknown"},
+			},
+			want: "kubectl-edit",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := fake.RoleObject(core.Namespace("example"), core.Name("example"))
+			obj.SetManagedFields(tc.managedFields)
+			if got := lastModifier(obj); got != tc.want {
+				t.Errorf("lastModifier() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRemediator_ReportRecreation(t *testing.T) {
+	now := metav1.Now()
+	declaredObj := fake.RoleObject(core.Namespace("example"), core.Name("example"),
+		syncertest.ManagementEnabled)
+	deletedObj := fake.RoleObject(core.Namespace("example"), core.Name("example"),
+		syncertest.ManagementEnabled)
+	deletedObj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl-edit", Time: &now},
+	})
+
+	fakeClient := testingfake.NewClient(t, core.Scheme)
+	d := makeDeclared(t, "abc123", declaredObj)
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, fakeClient.Applier(), d, testingfake.NewFightHandler())
+	m := testmetrics.RegisterMetrics(metrics.ResourcesRecreatedView)
+
+	ctx := context.Background()
+	if err := r.Remediate(ctx, core.IDOf(declaredObj), queue.MarkDeleted(ctx, deletedObj)); err != nil {
+		t.Fatalf("Remediate() = %v", err)
+	}
+
+	wantRows := []*view.Row{
+		{Data: &view.CountData{Value: 1}, Tags: []tag.Tag{
+			{Key: metrics.KeyType, Value: kinds.Role().Kind},
+		}},
+	}
+	if diff := m.ValidateMetrics(metrics.ResourcesRecreatedView, wantRows); diff != "" {
+		t.Errorf("Unexpected metrics recorded: %v", diff)
+	}
+
+	events := &corev1.EventList{}
+	if err := fakeClient.List(ctx, events, client.InNamespace(configsync.ControllerNamespace)); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("got %d events, want 1", len(events.Items))
+	}
+	event := events.Items[0]
+	if event.Reason != ObjectRecreatedReason || event.InvolvedObject.Kind != configsync.RootSyncKind ||
+		event.InvolvedObject.Name != configsync.RootSyncName {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if !strings.Contains(event.Message, `"kubectl-edit"`) {
+		t.Errorf("event message %q does not name the last modifier", event.Message)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/status"
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
@@ -38,6 +39,9 @@ import (
 type Worker struct {
 	objectQueue queue.Interface
 	reconciler  reconcilerInterface
+	// resources tracks the objects under an active emergency override, so
+	// that the applier does not revert them either.
+	resources *declared.Resources
 }
 
 // NewWorker returns a new Worker for the given queue and declared resources.
@@ -46,6 +50,7 @@ func NewWorker(scope declared.Scope, syncName string, a syncerreconcile.Applier,
 	return &Worker{
 		objectQueue: q,
 		reconciler:  newReconciler(scope, syncName, a, d, fh),
+		resources:   d,
 	}
 }
 
@@ -98,13 +103,31 @@ func (w *Worker) processNextObject(ctx context.Context) error {
 func (w *Worker) process(ctx context.Context, obj client.Object) error {
 	id := core.IDOf(obj)
 	var toRemediate client.Object
+	var overrideExpiry, now time.Time
+	var overrideExpired bool
 	if queue.WasDeleted(ctx, obj) {
-		// Passing a nil Object to the reconciler signals that the accompanying ID
-		// is for an Object that was deleted.
-		toRemediate = nil
+		// Passing a Deleted Object to the reconciler signals that the accompanying
+		// ID is for an Object that was deleted. The last observed state of the
+		// Object is used to report its recreation.
+		toRemediate = obj
 	} else {
 		toRemediate = obj
+		if expiry, found := metadata.EmergencyOverrideExpiry(obj); found {
+			now = time.Now()
+			if metadata.EmergencyOverrideActive(obj, now) {
+				// Tolerate the manual changes until the override expires, and then
+				// process the object again to revert them.
+				klog.Infof("Worker skipping %q under an emergency override until %s", id, expiry.Format(time.RFC3339))
+				w.resources.SetEmergencyOverride(id, expiry)
+				w.objectQueue.Forget(obj)
+				w.objectQueue.AddAfter(obj, expiry.Sub(now))
+				return nil
+			}
+			overrideExpiry = expiry
+			overrideExpired = true
+		}
 	}
+	w.resources.RemoveEmergencyOverride(id)
 
 	err := w.reconciler.Remediate(ctx, id, toRemediate)
 	if err != nil {
@@ -122,6 +145,9 @@ func (w *Worker) process(ctx context.Context, obj client.Object) error {
 		return fmt.Errorf("failed to remediate %q: %w", id, err)
 	}
 
+	if overrideExpired {
+		clearExpiredEmergencyOverride(ctx, w.reconciler.GetClient(), obj, overrideExpiry, now)
+	}
 	klog.V(3).Infof("Worker reconciled %q", id)
 	w.objectQueue.Forget(obj)
 	return nil
@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -125,6 +126,7 @@ func TestWorker_Run_Remediates(t *testing.T) {
 		t.Error("Run() failed to return when context was cancelled")
 	case <-doneCh:
 		// pass
+		checkRecreatedEvents(t, c, 1)
 		c.Check(t, expectedObjs...)
 	}
 }
@@ -214,6 +216,7 @@ func TestWorker_Run_RemediatesExisting(t *testing.T) {
 		t.Error("Run() failed to return when context was cancelled")
 	case <-doneCh:
 		// pass
+		checkRecreatedEvents(t, c, 1)
 		c.Check(t, expectedObjs...)
 	}
 }
@@ -224,6 +227,8 @@ func TestWorker_ProcessNextObject(t *testing.T) {
 		declared  []client.Object
 		toProcess []client.Object
 		want      []client.Object
+		// wantRecreated is the number of objects reported as recreated
+		wantRecreated int
 	}{
 		{
 			name: "update actual objects",
@@ -276,6 +281,7 @@ func TestWorker_ProcessNextObject(t *testing.T) {
 					core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 				),
 			},
+			wantRecreated: 2,
 		},
 	}
 
@@ -304,6 +310,7 @@ func TestWorker_ProcessNextObject(t *testing.T) {
 				}
 			}
 
+			checkRecreatedEvents(t, c, tc.wantRecreated)
 			c.Check(t, tc.want...)
 		})
 	}
@@ -437,6 +444,7 @@ func TestWorker_Run_CancelledWhenNotEmpty(t *testing.T) {
 		t.Error("Run() with empty queue did not return when context was cancelled")
 	case <-doneCh:
 		// pass
+		checkRecreatedEvents(t, c, 1)
 		c.Check(t, expectedObjs...)
 	}
 }
@@ -531,6 +539,23 @@ func TestWorker_Refresh(t *testing.T) {
 	}
 }
 
+// checkRecreatedEvents verifies that the expected number of ObjectRecreated
+// events were recorded, and removes them from the fake client, so that only
+// the remediated objects remain.
+func checkRecreatedEvents(t *testing.T, c *testingfake.Client, want int) {
+	t.Helper()
+	got := 0
+	for id, obj := range c.Objects {
+		if event, ok := obj.(*corev1.Event); ok && event.Reason == ObjectRecreatedReason {
+			got++
+			delete(c.Objects, id)
+		}
+	}
+	if got != want {
+		t.Errorf("got %d %s events, want %d", got, ObjectRecreatedReason, want)
+	}
+}
+
 func randomCommitHash() string {
 	return uuid.NewString()
 }
@@ -566,3 +591,67 @@ func (q *fakeQueue) Retry(o client.Object) {
 func (q *fakeQueue) Forget(_ client.Object) {
 	q.element = nil
 }
+
+func TestWorker_EmergencyOverride(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expiry     time.Time
+		wantLabel  string
+		wantExpiry bool
+	}{
+		{
+			name:       "tolerate manual changes until the override expires",
+			expiry:     time.Now().Add(time.Hour),
+			wantExpiry: true,
+		},
+		{
+			name:      "revert manual changes after the override expired",
+			expiry:    time.Now().Add(-time.Hour),
+			wantLabel: "declared",
+		},
+		{
+			name:      "revert manual changes under an override longer than the maximum",
+			expiry:    time.Now().Add(metadata.EmergencyOverrideMaxDuration + time.Hour),
+			wantLabel: "declared",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			override := core.Annotation(metadata.EmergencyOverrideUntilKey, tc.expiry.Format(time.RFC3339))
+			actual := fake.ClusterRoleObject(syncertest.ManagementEnabled, override)
+
+			q := queue.New("test")
+			defer q.ShutDown()
+			q.Add(actual)
+
+			c := testingfake.NewClient(t, core.Scheme)
+			if err := c.Create(ctx, actual.DeepCopy()); err != nil {
+				t.Fatalf("Failed to create object in fake client: %v", err)
+			}
+
+			d := makeDeclared(t, randomCommitHash(),
+				fake.ClusterRoleObject(syncertest.ManagementEnabled, core.Label("state", "declared")))
+			w := NewWorker(declared.RootReconciler, configsync.RootSyncName, c.Applier(), q, d, syncertestfake.NewFightHandler())
+
+			if err := w.processNextObject(ctx); err != nil {
+				t.Fatalf("unexpected error from processNextObject(): %v", err)
+			}
+
+			got := &v1.ClusterRole{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(actual), got); err != nil {
+				t.Fatalf("Failed to get object from fake client: %v", err)
+			}
+			if label := core.GetLabel(got, "state"); label != tc.wantLabel {
+				t.Errorf("got state label %q, want %q", label, tc.wantLabel)
+			}
+			if _, found := got.GetAnnotations()[metadata.EmergencyOverrideUntilKey]; found != tc.wantExpiry {
+				t.Errorf("got emergency override annotation %v, want %v", found, tc.wantExpiry)
+			}
+			if tracked := len(d.EmergencyOverrides(time.Now())) > 0; tracked != tc.wantExpiry {
+				t.Errorf("got emergency override tracked %v, want %v", tracked, tc.wantExpiry)
+			}
+		})
+	}
+}
@@ -83,10 +83,23 @@ type Interface interface {
 	ConflictErrors() []status.ManagementConflictError
 	// FightErrors returns the fight errors (KNV2005) the remediator encounters.
 	FightErrors() []status.Error
+	// WatchFailures returns the watches which are failing to start, or which
+	// stalled.
+	WatchFailures() []watch.Failure
 }
 
 var _ Interface = &Remediator{}
 
+// Runner is a Remediator which can be started.
+type Runner interface {
+	Interface
+	// Start the Remediator's workers. The returned channel is closed once the
+	// workers have exited after the context is done.
+	Start(context.Context) <-chan struct{}
+}
+
+var _ Runner = &Remediator{}
+
 // New instantiates launches goroutines to make the state of the connected
 // cluster match the declared resources.
 //
@@ -214,3 +227,8 @@ func (r *Remediator) ConflictErrors() []status.ManagementConflictError {
 func (r *Remediator) FightErrors() []status.Error {
 	return r.fightHandler.FightErrors()
 }
+
+// WatchFailures implements Interface.
+func (r *Remediator) WatchFailures() []watch.Failure {
+	return r.watchMgr.WatchFailures()
+}
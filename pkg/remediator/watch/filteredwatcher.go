@@ -85,6 +85,8 @@ const errorLoggingInterval = time.Second
 // - or managed by the same reconciler.
 type filteredWatcher struct {
 	gvk        string
+	gvkKey     schema.GroupVersionKind
+	health     *healthTracker
 	startWatch WatchFunc
 	resources  *declared.Resources
 	queue      *queue.ObjectQueue
@@ -108,6 +110,8 @@ var _ Runnable = &filteredWatcher{}
 func NewFiltered(cfg watcherConfig) Runnable {
 	return &filteredWatcher{
 		gvk:             cfg.gvk.String(),
+		gvkKey:          cfg.gvk,
+		health:          cfg.health,
 		startWatch:      cfg.startWatch,
 		resources:       cfg.resources,
 		queue:           cfg.queue,
@@ -254,6 +258,9 @@ func (w *filteredWatcher) Run(ctx context.Context) status.Error {
 		if !started {
 			break
 		}
+		// The watch is established, so it is healthy even before the first
+		// event arrives, which may take a long time for a quiet resource.
+		w.health.healthy(w.gvkKey)
 
 		eventCount := 0
 		ignoredEventCount := 0
@@ -273,8 +280,11 @@ func (w *filteredWatcher) Run(ctx context.Context) status.Error {
 					// Reset `resourceVersion` to an empty string here so that we can start a new
 					// watch at the most recent resource version.
 					resourceVersion = ""
-				} else if w.addError(watchEventErrorType + errorID(err)) {
-					klog.Errorf("Watch for %s at resource version %q ended with: %v", w.gvk, resourceVersion, err)
+				} else {
+					w.health.failed(w.gvkKey, err)
+					if w.addError(watchEventErrorType + errorID(err)) {
+						klog.Errorf("Watch for %s at resource version %q ended with: %v", w.gvk, resourceVersion, err)
+					}
 				}
 				retriesForWatchError++
 				waitUntilNextRetry(retriesForWatchError)
@@ -282,6 +292,7 @@ func (w *filteredWatcher) Run(ctx context.Context) status.Error {
 				break
 			}
 			retriesForWatchError = 0
+			w.health.healthy(w.gvkKey)
 			if newVersion != "" {
 				resourceVersion = newVersion
 			}
@@ -16,7 +16,9 @@ package watch
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,7 +26,9 @@ import (
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/diff/difftest"
+	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/remediator/queue"
+	"kpt.dev/configsync/pkg/status"
 	"kpt.dev/configsync/pkg/syncer/syncertest"
 	testfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
 	"kpt.dev/configsync/pkg/testing/fake"
@@ -230,3 +234,40 @@ func TestFilteredWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestFilteredWatcher_HealthyOnStart(t *testing.T) {
+	h := newHealthTracker()
+	gvk := kinds.Deployment()
+	h.failed(gvk, errors.New("deployments.apps is forbidden"))
+
+	base := watch.NewFake()
+	cfg := watcherConfig{
+		gvk:       gvk,
+		health:    h,
+		scope:     declared.Scope("test"),
+		syncName:  "rs",
+		resources: &declared.Resources{},
+		queue:     queue.New("test"),
+		startWatch: func(_ context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return base, nil
+		},
+		conflictHandler: testfake.NewConflictHandler(),
+	}
+	w := NewFiltered(cfg)
+
+	errCh := make(chan status.Error, 1)
+	go func() { errCh <- w.Run(context.Background()) }()
+
+	// No event is ever sent: the watch must be healthy as soon as it starts.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(h.Failures()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got failures %v after the watch started, want none", h.Failures())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w.Stop()
+	if err := <-errCh; err != nil {
+		t.Fatalf("got Run() = %v, want Run() = <nil>", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Failure describes a watch which fails to start, or which stalled, because
+// its events are errors, e.g. when the reconciler lacks the permission to
+// watch the type, or when the CRD of the type was deleted.
+type Failure struct {
+	// GVK is the type being watched.
+	GVK schema.GroupVersionKind
+	// Message is the last error of the watch.
+	Message string
+	// Since is the time when the watch first failed, after it was last
+	// healthy.
+	Since time.Time
+}
+
+// healthTracker tracks the failing watches.
+// A nil healthTracker ignores all the reports.
+type healthTracker struct {
+	// now returns the current time. Overridden in tests.
+	now func() time.Time
+
+	mux      sync.Mutex
+	failures map[schema.GroupVersionKind]Failure
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		now:      time.Now,
+		failures: make(map[schema.GroupVersionKind]Failure),
+	}
+}
+
+// failed records the error of the watch for the GVK. The time of the first
+// failure is preserved, until the watch is healthy again.
+func (h *healthTracker) failed(gvk schema.GroupVersionKind, err error) {
+	if h == nil || err == nil {
+		return
+	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	failure, found := h.failures[gvk]
+	if !found {
+		failure = Failure{GVK: gvk, Since: h.now()}
+	}
+	failure.Message = err.Error()
+	h.failures[gvk] = failure
+}
+
+// healthy clears the failure of the watch for the GVK, if any.
+func (h *healthTracker) healthy(gvk schema.GroupVersionKind) {
+	if h == nil {
+		return
+	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	delete(h.failures, gvk)
+}
+
+// Failures returns the failing watches, sorted by GVK.
+func (h *healthTracker) Failures() []Failure {
+	if h == nil {
+		return nil
+	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	var failures []Failure
+	for _, failure := range h.failures {
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].GVK.String() < failures[j].GVK.String()
+	})
+	return failures
+}
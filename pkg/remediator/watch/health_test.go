@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/kinds"
+)
+
+func TestHealthTracker(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	h := newHealthTracker()
+	h.now = func() time.Time { return now }
+
+	h.failed(kinds.Role(), errors.New("roles.rbac.authorization.k8s.io is forbidden"))
+	now = start.Add(time.Minute)
+	h.failed(kinds.Role(), errors.New("the server could not find the requested resource"))
+	h.failed(kinds.ConfigMap(), errors.New("configmaps is forbidden"))
+
+	want := []Failure{
+		{GVK: kinds.ConfigMap(), Message: "configmaps is forbidden", Since: start.Add(time.Minute)},
+		{GVK: kinds.Role(), Message: "the server could not find the requested resource", Since: start},
+	}
+	if diff := cmp.Diff(want, h.Failures()); diff != "" {
+		t.Errorf("unexpected failures (-want, +got):\n%s", diff)
+	}
+
+	h.healthy(kinds.Role())
+	h.healthy(kinds.ConfigMap())
+	if got := h.Failures(); len(got) != 0 {
+		t.Errorf("got failures %v after the watches recovered, want none", got)
+	}
+
+	var nilTracker *healthTracker
+	nilTracker.failed(kinds.Role(), errors.New("ignored"))
+	if got := nilTracker.Failures(); got != nil {
+		t.Errorf("got failures %v from a nil tracker, want nil", got)
+	}
+}
@@ -55,6 +55,9 @@ type Manager struct {
 	// needsUpdate indicates if the Manager's watches need to be updated.
 	needsUpdate     bool
 	conflictHandler conflict.Handler
+
+	// health tracks the failing watches.
+	health *healthTracker
 }
 
 // Options contains options for creating a watch manager.
@@ -95,6 +98,7 @@ func NewManager(scope declared.Scope, syncName string, cfg *rest.Config,
 		watcherFactory:  options.watcherFactory,
 		queue:           q,
 		conflictHandler: ch,
+		health:          newHealthTracker(),
 	}, nil
 }
 
@@ -195,9 +199,11 @@ func (m *Manager) startWatcher(ctx context.Context, gvk schema.GroupVersionKind)
 		scope:           m.scope,
 		syncName:        m.syncName,
 		conflictHandler: m.conflictHandler,
+		health:          m.health,
 	}
 	w, err := m.watcherFactory(cfg)
 	if err != nil {
+		m.health.failed(gvk, err)
 		return err
 	}
 
@@ -211,6 +217,7 @@ func (m *Manager) startWatcher(ctx context.Context, gvk schema.GroupVersionKind)
 func (m *Manager) runWatcher(ctx context.Context, r Runnable, gvk schema.GroupVersionKind) {
 	if err := r.Run(ctx); err != nil {
 		klog.Warningf("Error running watcher for %s: %v", gvk.String(), status.FormatSingleLine(err))
+		m.health.failed(gvk, err)
 		m.mux.Lock()
 		delete(m.watcherMap, gvk)
 		m.needsUpdate = true
@@ -233,4 +240,12 @@ func (m *Manager) stopWatcher(gvk schema.GroupVersionKind) {
 	// objects are no longer managed by the reconciler.
 	w.removeAllManagementConflictErrorsWithGVK(gvk)
 	delete(m.watcherMap, gvk)
+	// The type is no longer declared, so its watch health is irrelevant.
+	m.health.healthy(gvk)
+}
+
+// WatchFailures returns the watches which are failing to start, or which
+// stalled. This function is threadsafe.
+func (m *Manager) WatchFailures() []Failure {
+	return m.health.Failures()
 }
@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rename renames the declared objects of a RootSync or RepoSync with
+// its rename rules, so that the same package can be synced multiple times in
+// a cluster.
+package rename
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// Rule is a parsed rename rule.
+type Rule struct {
+	kinds           map[schema.GroupKind]bool
+	namespacePrefix string
+	namespaceSuffix string
+	nameRegexp      *regexp.Regexp
+	nameReplacement string
+}
+
+// Parse parses and validates the rename rules.
+func Parse(rules []v1beta1.RenameRule) ([]Rule, error) {
+	var result []Rule
+	for i, r := range rules {
+		if r.NamespacePrefix == "" && r.NamespaceSuffix == "" && r.NameRegex == "" {
+			return nil, errors.Errorf("renames[%d]: must set at least one of namespacePrefix, namespaceSuffix or nameRegex", i)
+		}
+		if r.NameReplacement != "" && r.NameRegex == "" {
+			return nil, errors.Errorf("renames[%d]: nameReplacement requires nameRegex", i)
+		}
+		rule := Rule{
+			namespacePrefix: r.NamespacePrefix,
+			namespaceSuffix: r.NamespaceSuffix,
+			nameReplacement: r.NameReplacement,
+		}
+		if r.NameRegex != "" {
+			re, err := regexp.Compile(r.NameRegex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "renames[%d]: invalid nameRegex %q", i, r.NameRegex)
+			}
+			rule.nameRegexp = re
+		}
+		if len(r.Kinds) > 0 {
+			rule.kinds = make(map[schema.GroupKind]bool, len(r.Kinds))
+			for _, gk := range r.Kinds {
+				rule.kinds[schema.GroupKind{Group: gk.Group, Kind: gk.Kind}] = true
+			}
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// RenamesNamespaces returns true if any of the rules renames the namespaces.
+func RenamesNamespaces(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.namespacePrefix != "" || rule.namespaceSuffix != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply renames the objects in place with the rules, in order. An error is
+// returned for each object renamed to an invalid namespace or name, and for
+// each object renamed to the same object as another one.
+func Apply(rules []Rule, objs []ast.FileObject) status.MultiError {
+	if len(rules) == 0 {
+		return nil
+	}
+	var errs status.MultiError
+	renamed := make(map[core.ID]ast.FileObject, len(objs))
+	for _, obj := range objs {
+		oldID := core.IDOf(obj)
+		for _, rule := range rules {
+			rule.apply(obj)
+		}
+		id := core.IDOf(obj)
+		if id != oldID {
+			if err := validateID(id); err != nil {
+				errs = status.Append(errs, status.RenameError(fmt.Sprintf("%s was renamed to %s: %v", oldID, id, err), obj))
+				continue
+			}
+		}
+		if other, found := renamed[id]; found {
+			errs = status.Append(errs, status.RenameError(fmt.Sprintf("%s is declared more than once after renaming", id), obj, other))
+			continue
+		}
+		renamed[id] = obj
+	}
+	return errs
+}
+
+// apply renames the object if the rule applies to its kind.
+func (r Rule) apply(obj ast.FileObject) {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if len(r.kinds) > 0 && !r.kinds[gk] {
+		return
+	}
+	if r.nameRegexp != nil {
+		obj.SetName(r.nameRegexp.ReplaceAllString(obj.GetName(), r.nameReplacement))
+	}
+	if r.namespacePrefix == "" && r.namespaceSuffix == "" {
+		return
+	}
+	if gk == kinds.Namespace().GroupKind() {
+		obj.SetName(r.namespacePrefix + obj.GetName() + r.namespaceSuffix)
+	} else if ns := obj.GetNamespace(); ns != "" {
+		obj.SetNamespace(r.namespacePrefix + ns + r.namespaceSuffix)
+	}
+}
+
+// validateID returns an error if the name or namespace of the renamed object
+// is invalid.
+func validateID(id core.ID) error {
+	if id.Name == "" {
+		return errors.New("the name is empty")
+	}
+	namespace := id.Namespace
+	if id.GroupKind == kinds.Namespace().GroupKind() {
+		namespace = id.Name
+	}
+	if namespace == "" {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return errors.Errorf("invalid namespace %q: %s", namespace, msgs[0])
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rename
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func ids(objs []ast.FileObject) []core.ID {
+	var result []core.ID
+	for _, obj := range objs {
+		result = append(result, core.IDOf(obj))
+	}
+	return result
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []v1beta1.RenameRule
+		objs  []ast.FileObject
+		want  []ast.FileObject
+	}{
+		{
+			name: "no rules",
+			objs: []ast.FileObject{fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore"))},
+			want: []ast.FileObject{fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore"))},
+		},
+		{
+			name:  "namespace prefix and suffix",
+			rules: []v1beta1.RenameRule{{NamespacePrefix: "team-a-", NamespaceSuffix: "-prod"}},
+			objs: []ast.FileObject{
+				fake.Namespace("namespaces/bookstore"),
+				fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore")),
+				fake.ClusterRole(core.Name("reader")),
+			},
+			want: []ast.FileObject{
+				fake.Namespace("namespaces/team-a-bookstore-prod"),
+				fake.ConfigMap(core.Name("cm"), core.Namespace("team-a-bookstore-prod")),
+				fake.ClusterRole(core.Name("reader")),
+			},
+		},
+		{
+			name:  "name regex",
+			rules: []v1beta1.RenameRule{{NameRegex: "^(.*)-base$", NameReplacement: "${1}-team-a"}},
+			objs: []ast.FileObject{
+				fake.ConfigMap(core.Name("settings-base"), core.Namespace("bookstore")),
+				fake.ConfigMap(core.Name("other"), core.Namespace("bookstore")),
+			},
+			want: []ast.FileObject{
+				fake.ConfigMap(core.Name("settings-team-a"), core.Namespace("bookstore")),
+				fake.ConfigMap(core.Name("other"), core.Namespace("bookstore")),
+			},
+		},
+		{
+			name: "rules restricted to kinds, in order",
+			rules: []v1beta1.RenameRule{
+				{Kinds: []metav1.GroupKind{{Kind: "ConfigMap"}}, NameRegex: "^", NameReplacement: "a-"},
+				{Kinds: []metav1.GroupKind{{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}}, NameRegex: "$", NameReplacement: "-b"},
+				{NameRegex: "^a-", NameReplacement: "c-"},
+			},
+			objs: []ast.FileObject{
+				fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore")),
+				fake.ClusterRole(core.Name("reader")),
+			},
+			want: []ast.FileObject{
+				fake.ConfigMap(core.Name("c-cm"), core.Namespace("bookstore")),
+				fake.ClusterRole(core.Name("reader-b")),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := Parse(tc.rules)
+			require.NoError(t, err)
+			require.Nil(t, Apply(rules, tc.objs))
+			assert.Equal(t, ids(tc.want), ids(tc.objs))
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []v1beta1.RenameRule
+		objs  []ast.FileObject
+	}{
+		{
+			name:  "invalid namespace",
+			rules: []v1beta1.RenameRule{{NamespacePrefix: "Team_"}},
+			objs:  []ast.FileObject{fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore"))},
+		},
+		{
+			name:  "empty name",
+			rules: []v1beta1.RenameRule{{NameRegex: ".*"}},
+			objs:  []ast.FileObject{fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore"))},
+		},
+		{
+			name:  "renamed to another object",
+			rules: []v1beta1.RenameRule{{NameRegex: "-v1$"}},
+			objs: []ast.FileObject{
+				fake.ConfigMap(core.Name("cm-v1"), core.Namespace("bookstore")),
+				fake.ConfigMap(core.Name("cm"), core.Namespace("bookstore")),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := Parse(tc.rules)
+			require.NoError(t, err)
+			errs := Apply(rules, tc.objs)
+			require.NotNil(t, errs)
+			assert.Equal(t, status.RenameErrorCode, errs.Errors()[0].Code())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, rule := range []v1beta1.RenameRule{
+		{},
+		{NameReplacement: "foo"},
+		{NameRegex: "(unclosed"},
+	} {
+		_, err := Parse([]v1beta1.RenameRule{rule})
+		assert.Error(t, err, "%+v", rule)
+	}
+}
+
+func TestRenamesNamespaces(t *testing.T) {
+	rules, err := Parse([]v1beta1.RenameRule{{NameRegex: "a"}})
+	require.NoError(t, err)
+	assert.False(t, RenamesNamespaces(rules))
+	rules, err = Parse([]v1beta1.RenameRule{{NameRegex: "a"}, {NamespaceSuffix: "-b"}})
+	require.NoError(t, err)
+	assert.True(t, RenamesNamespaces(rules))
+}
@@ -15,9 +15,12 @@
 package reposync
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/status"
 )
 
@@ -65,11 +68,16 @@ func ClearCondition(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType
 	}
 
 	if condition.Status == metav1.ConditionFalse {
+		if condition.ObservedGeneration != rs.Generation {
+			condition.ObservedGeneration = rs.Generation
+			condition.LastUpdateTime = now()
+		}
 		return
 	}
 
 	time := now()
 	condition.Status = metav1.ConditionFalse
+	condition.ObservedGeneration = rs.Generation
 	condition.Reason = ""
 	condition.Message = ""
 	condition.LastTransitionTime = time
@@ -90,7 +98,7 @@ var singleErrorSummary = &v1beta1.ErrorSummary{
 // (status change).
 // Removes the Syncing condition if the Reconciling condition transitioned.
 func SetReconciling(rs *v1beta1.RepoSync, reason, message string) (updated, transitioned bool) {
-	updated, transitioned = setCondition(rs, v1beta1.RepoSyncReconciling, metav1.ConditionTrue, reason, message, "", nil, nil, &v1beta1.ErrorSummary{}, now())
+	updated, transitioned = setCondition(rs, v1beta1.RepoSyncReconciling, metav1.ConditionTrue, reason, message, "", nil, nil, &v1beta1.ErrorSummary{}, now(), rs.Generation)
 	if transitioned {
 		RemoveCondition(rs, v1beta1.RepoSyncSyncing)
 	}
@@ -102,7 +110,7 @@ func SetReconciling(rs *v1beta1.RepoSync, reason, message string) (updated, tran
 // (status change).
 // Removes the Syncing condition if the Stalled condition transitioned.
 func SetStalled(rs *v1beta1.RepoSync, reason string, err error) (updated, transitioned bool) {
-	updated, transitioned = setCondition(rs, v1beta1.RepoSyncStalled, metav1.ConditionTrue, reason, err.Error(), "", nil, nil, singleErrorSummary, now())
+	updated, transitioned = setCondition(rs, v1beta1.RepoSyncStalled, metav1.ConditionTrue, reason, err.Error(), "", nil, nil, singleErrorSummary, now(), rs.Generation)
 	if transitioned {
 		RemoveCondition(rs, v1beta1.RepoSyncSyncing)
 	}
@@ -119,13 +127,13 @@ func SetSyncing(rs *v1beta1.RepoSync, status bool, reason, message, commit strin
 	} else {
 		conditionStatus = metav1.ConditionFalse
 	}
-	return setCondition(rs, v1beta1.RepoSyncSyncing, conditionStatus, reason, message, commit, nil, errorSources, errorSummary, timestamp)
+	return setCondition(rs, v1beta1.RepoSyncSyncing, conditionStatus, reason, message, commit, nil, errorSources, errorSummary, timestamp, reconcilerGeneration(rs, v1beta1.RepoSyncSyncing))
 }
 
 // SetReconcilerFinalizing sets the ReconcilerFinalizing condition to True.
 // Use RemoveCondition to remove this condition. It should never be set to False.
 func SetReconcilerFinalizing(rs *v1beta1.RepoSync, reason, message string) (updated bool) {
-	updated, _ = setCondition(rs, v1beta1.RepoSyncReconcilerFinalizing, metav1.ConditionTrue, reason, message, "", nil, nil, nil, now())
+	updated, _ = setCondition(rs, v1beta1.RepoSyncReconcilerFinalizing, metav1.ConditionTrue, reason, message, "", nil, nil, nil, now(), rs.Generation)
 	return updated
 }
 
@@ -148,17 +156,67 @@ func SetReconcilerFinalizerFailure(rs *v1beta1.RepoSync, errs status.MultiError)
 		csErrs = nil
 	}
 	updated, _ = setCondition(rs, v1beta1.RepoSyncReconcilerFinalizerFailure,
-		conditionStatus, reason, message, "", csErrs, nil, nil, now())
+		conditionStatus, reason, message, "", csErrs, nil, nil, now(), rs.Generation)
+	return updated
+}
+
+// SetRetriesExhausted sets the RetriesExhausted condition to True.
+// Use RemoveCondition to remove this condition when the reconciler retries
+// again.
+func SetRetriesExhausted(rs *v1beta1.RepoSync, commit string, failures int) (updated bool) {
+	message := fmt.Sprintf("Stopped retrying after %d failed attempts to sync commit %s. "+
+		"Push a new commit or set the %s annotation to retry.", failures, commit, metadata.SyncRequestedAtAnnotationKey)
+	updated, _ = setCondition(rs, v1beta1.RepoSyncRetriesExhausted, metav1.ConditionTrue, "RetryBudgetExhausted", message, commit, nil, nil, nil, now(), reconcilerGeneration(rs, v1beta1.RepoSyncRetriesExhausted))
+	return updated
+}
+
+// SetFlapping sets the Flapping condition to True.
+// Use RemoveCondition to remove this condition when the sync is stable again.
+func SetFlapping(rs *v1beta1.RepoSync, commit string, transitions int) (updated bool) {
+	message := fmt.Sprintf("The sync alternated between failure and success about %d times recently. "+
+		"The sync is reported as flapping until it stabilizes.", transitions)
+	updated, _ = setCondition(rs, v1beta1.RepoSyncFlapping, metav1.ConditionTrue, "SyncFlapping", message, commit, nil, nil, nil, now(), reconcilerGeneration(rs, v1beta1.RepoSyncFlapping))
+	return updated
+}
+
+// SetWebhookDegraded sets the WebhookDegraded condition to True.
+// Use RemoveCondition to remove this condition when the webhook is healthy.
+func SetWebhookDegraded(rs *v1beta1.RepoSync, reason, message string) (updated bool) {
+	updated, _ = setCondition(rs, v1beta1.RepoSyncWebhookDegraded, metav1.ConditionTrue, reason, message, "", nil, nil, nil, now(), rs.Generation)
+	return updated
+}
+
+// SetOverlapping sets the Overlapping condition to True.
+// Use RemoveCondition to remove this condition when the inventory of the
+// RepoSync no longer overlaps with the other inventories.
+func SetOverlapping(rs *v1beta1.RepoSync, message string) (updated bool) {
+	updated, _ = setCondition(rs, v1beta1.RepoSyncOverlapping, metav1.ConditionTrue, "DeclaredByMultipleRSyncs", message, "", nil, nil, nil, now(), rs.Generation)
 	return updated
 }
 
+// reconcilerGeneration returns the generation to record as the
+// observedGeneration of a condition set by the reconciler: the generation last
+// acted upon by the reconciler-manager. While the reconciler-manager is still
+// rolling the reconciler out for it, the running reconciler may not be
+// configured for it yet, so the generation already recorded is kept.
+func reconcilerGeneration(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType) int64 {
+	if !IsReconciling(rs) {
+		return rs.Status.ObservedGeneration
+	}
+	if condition := GetCondition(rs.Status.Conditions, condType); condition != nil {
+		return condition.ObservedGeneration
+	}
+	return 0
+}
+
 // setCondition adds or updates the specified condition with a True status.
 // Returns whether the condition was updated (any change) or transitioned
 // (status change).
+// The generation is recorded as the observedGeneration of the condition.
 //
 // Use Errors OR (ErrorSource & ErrorSummary).
 // Errors should only be used if there isn't another status field to reference.
-func setCondition(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType, status metav1.ConditionStatus, reason, message, commit string, errs []v1beta1.ConfigSyncError, errorSources []v1beta1.ErrorSource, errorSummary *v1beta1.ErrorSummary, timestamp metav1.Time) (updated, transitioned bool) {
+func setCondition(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType, status metav1.ConditionStatus, reason, message, commit string, errs []v1beta1.ConfigSyncError, errorSources []v1beta1.ErrorSource, errorSummary *v1beta1.ErrorSummary, timestamp metav1.Time, generation int64) (updated, transitioned bool) {
 	condition := GetCondition(rs.Status.Conditions, condType)
 	if condition == nil {
 		i := len(rs.Status.Conditions)
@@ -174,6 +232,7 @@ func setCondition(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType,
 	} else if condition.Reason != reason ||
 		condition.Message != message ||
 		condition.Commit != commit ||
+		condition.ObservedGeneration != generation ||
 		!equality.Semantic.DeepEqual(condition.Errors, errs) ||
 		!equality.Semantic.DeepEqual(condition.ErrorSourceRefs, errorSources) ||
 		!equality.Semantic.DeepEqual(condition.ErrorSummary, errorSummary) {
@@ -184,6 +243,7 @@ func setCondition(rs *v1beta1.RepoSync, condType v1beta1.RepoSyncConditionType,
 	condition.Reason = reason
 	condition.Message = message
 	condition.Commit = commit
+	condition.ObservedGeneration = generation
 	condition.Errors = errs
 	condition.ErrorSourceRefs = errorSources
 	condition.ErrorSummary = errorSummary
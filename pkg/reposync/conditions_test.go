@@ -44,6 +44,13 @@ func withConditions(conds ...v1beta1.RepoSyncCondition) core.MetaMutator {
 	}
 }
 
+func withObservedGeneration(generation int64) core.MetaMutator {
+	return func(o client.Object) {
+		rs := o.(*v1beta1.RepoSync)
+		rs.Status.ObservedGeneration = generation
+	}
+}
+
 func fakeCondition(condType v1beta1.RepoSyncConditionType, status metav1.ConditionStatus, lastTransitionTime, lastUpdateTime metav1.Time, strs ...string) v1beta1.RepoSyncCondition {
 	rsc := v1beta1.RepoSyncCondition{
 		Type:               condType,
@@ -407,6 +414,88 @@ func TestSetSyncing(t *testing.T) {
 		wantUpdated      bool
 		wantTransitioned bool
 	}{
+		{
+			name: "Update observedGeneration",
+			rs: fake.RepoSyncObjectV1Beta1(testNs, configsync.RepoSyncName,
+				withConditions(
+					v1beta1.RepoSyncCondition{
+						Type:               v1beta1.RepoSyncSyncing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "Syncing",
+						Commit:             "commit-1",
+						ObservedGeneration: 1,
+						ErrorSummary:       &v1beta1.ErrorSummary{},
+						LastUpdateTime:     initialNow,
+						LastTransitionTime: initialNow,
+					}),
+				withObservedGeneration(2)),
+			status:       true,
+			reason:       "Syncing",
+			commit:       "commit-1",
+			errorSummary: &v1beta1.ErrorSummary{},
+			timestamp:    updatedNow,
+			want: []v1beta1.RepoSyncCondition{
+				// Update but no transition
+				{
+					Type:               v1beta1.RepoSyncSyncing,
+					Status:             metav1.ConditionTrue,
+					Reason:             "Syncing",
+					Commit:             "commit-1",
+					ObservedGeneration: 2,
+					ErrorSummary:       &v1beta1.ErrorSummary{},
+					LastUpdateTime:     updatedNow,
+					LastTransitionTime: initialNow,
+				},
+			},
+			wantUpdated:      true,
+			wantTransitioned: false,
+		},
+		{
+			name: "Keep observedGeneration while reconciling",
+			rs: fake.RepoSyncObjectV1Beta1(testNs, configsync.RepoSyncName,
+				withConditions(
+					v1beta1.RepoSyncCondition{
+						Type:   v1beta1.RepoSyncReconciling,
+						Status: metav1.ConditionTrue,
+					},
+					v1beta1.RepoSyncCondition{
+						Type:               v1beta1.RepoSyncSyncing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "Syncing",
+						Commit:             "commit-1",
+						ObservedGeneration: 1,
+						ErrorSummary:       &v1beta1.ErrorSummary{},
+						LastUpdateTime:     initialNow,
+						LastTransitionTime: initialNow,
+					}),
+				withObservedGeneration(2)),
+			status:       false,
+			reason:       "Sync",
+			message:      "Sync Completed",
+			commit:       "commit-1",
+			errorSummary: &v1beta1.ErrorSummary{},
+			timestamp:    updatedNow,
+			want: []v1beta1.RepoSyncCondition{
+				{
+					Type:   v1beta1.RepoSyncReconciling,
+					Status: metav1.ConditionTrue,
+				},
+				// Update and transition
+				{
+					Type:               v1beta1.RepoSyncSyncing,
+					Status:             metav1.ConditionFalse,
+					Reason:             "Sync",
+					Message:            "Sync Completed",
+					Commit:             "commit-1",
+					ObservedGeneration: 1,
+					ErrorSummary:       &v1beta1.ErrorSummary{},
+					LastUpdateTime:     updatedNow,
+					LastTransitionTime: updatedNow,
+				},
+			},
+			wantUpdated:      true,
+			wantTransitioned: true,
+		},
 		{
 			name:         "Set new syncing condition without error",
 			rs:           fake.RepoSyncObjectV1Beta1(testNs, configsync.RepoSyncName),
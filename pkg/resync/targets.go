@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resync parses the targets of a selective resync, which re-applies
+// some of the declared objects of a RootSync or RepoSync from the current
+// commit, without waiting for the periodic full resync.
+package resync
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dirPrefix is the prefix of the targets which select all the objects
+// declared in a directory.
+const dirPrefix = "dir:"
+
+// Target selects the declared objects to resync: either a single object, or
+// all the objects declared in a directory of the source of truth.
+type Target struct {
+	// Group of the object. Empty matches any group.
+	Group string
+	// Kind of the object, matched case-insensitively.
+	Kind string
+	// Namespace of the object. Empty for cluster-scoped objects.
+	Namespace string
+	// Name of the object.
+	Name string
+
+	// Dir is the slash-separated path of a directory, relative to the sync
+	// directory. "." selects all the declared objects.
+	Dir string
+}
+
+// ParseTargets parses a list of targets, separated by commas or whitespace.
+// Each target is either:
+//   - `<kind>[.<group>]/<namespace>/<name>` for a namespaced object,
+//   - `<kind>[.<group>]/<name>` for a cluster-scoped object,
+//   - `dir:<path>` for the objects declared in a directory.
+func ParseTargets(value string) ([]Target, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no objects to resync")
+	}
+	targets := make([]Target, 0, len(fields))
+	for _, field := range fields {
+		target, err := parseTarget(field)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+func parseTarget(value string) (Target, error) {
+	if strings.HasPrefix(value, dirPrefix) {
+		dir := strings.TrimPrefix(value, dirPrefix)
+		if dir == "" {
+			return Target{}, fmt.Errorf("invalid resync target %q: the directory is empty", value)
+		}
+		dir = strings.Trim(path.Clean(dir), "/")
+		if dir == "" || strings.HasPrefix(dir, "..") {
+			return Target{}, fmt.Errorf("invalid resync target %q: the directory must be in the sync directory", value)
+		}
+		return Target{Dir: dir}, nil
+	}
+
+	parts := strings.Split(value, "/")
+	var target Target
+	switch len(parts) {
+	case 2:
+		target.Name = parts[1]
+	case 3:
+		target.Namespace = parts[1]
+		target.Name = parts[2]
+	default:
+		return Target{}, fmt.Errorf("invalid resync target %q: expected <kind>/<name>, <kind>/<namespace>/<name> or dir:<path>", value)
+	}
+	target.Kind = parts[0]
+	if i := strings.Index(parts[0], "."); i >= 0 {
+		target.Kind = parts[0][:i]
+		target.Group = parts[0][i+1:]
+	}
+	for _, part := range parts {
+		if part == "" {
+			return Target{}, fmt.Errorf("invalid resync target %q: empty kind, namespace or name", value)
+		}
+	}
+	if target.Kind == "" {
+		return Target{}, fmt.Errorf("invalid resync target %q: empty kind", value)
+	}
+	return target, nil
+}
+
+// Matches returns true if the target selects the declared object.
+func (t Target) Matches(obj client.Object) bool {
+	if t.Dir != "" {
+		if t.Dir == "." {
+			return true
+		}
+		sourcePath := core.GetAnnotation(obj, metadata.SourcePathAnnotationKey)
+		return strings.HasPrefix(sourcePath, t.Dir+"/")
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if !strings.EqualFold(gvk.Kind, t.Kind) {
+		return false
+	}
+	if t.Group != "" && !strings.EqualFold(gvk.Group, t.Group) {
+		return false
+	}
+	return obj.GetNamespace() == t.Namespace && obj.GetName() == t.Name
+}
+
+// String returns the target in the format accepted by ParseTargets.
+func (t Target) String() string {
+	if t.Dir != "" {
+		return dirPrefix + t.Dir
+	}
+	kind := t.Kind
+	if t.Group != "" {
+		kind += "." + t.Group
+	}
+	if t.Namespace == "" {
+		return kind + "/" + t.Name
+	}
+	return kind + "/" + t.Namespace + "/" + t.Name
+}
+
+// MatchesAny returns true if any of the targets selects the declared object.
+func MatchesAny(targets []Target, obj client.Object) bool {
+	for _, t := range targets {
+		if t.Matches(obj) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		want    []Target
+		wantErr bool
+	}{
+		{
+			name:  "namespaced and cluster-scoped objects",
+			value: "deployment.apps/bookstore/app, ClusterRole/reader",
+			want: []Target{
+				{Group: "apps", Kind: "deployment", Namespace: "bookstore", Name: "app"},
+				{Kind: "ClusterRole", Name: "reader"},
+			},
+		},
+		{
+			name:  "directory",
+			value: "dir:./namespaces/bookstore/",
+			want:  []Target{{Dir: "namespaces/bookstore"}},
+		},
+		{
+			name:    "directory outside of the sync directory",
+			value:   "dir:../other",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			value:   "deployment",
+			wantErr: true,
+		},
+		{
+			name:    "empty namespace",
+			value:   "deployment//app",
+			wantErr: true,
+		},
+		{
+			name:    "no target",
+			value:   " , ",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseTargets(tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
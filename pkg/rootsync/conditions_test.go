@@ -43,6 +43,13 @@ func withConditions(conds ...v1beta1.RootSyncCondition) core.MetaMutator {
 	}
 }
 
+func withObservedGeneration(generation int64) core.MetaMutator {
+	return func(o client.Object) {
+		rs := o.(*v1beta1.RootSync)
+		rs.Status.ObservedGeneration = generation
+	}
+}
+
 func fakeCondition(condType v1beta1.RootSyncConditionType, status metav1.ConditionStatus, lastTransitionTime, lastUpdateTime metav1.Time, strs ...string) v1beta1.RootSyncCondition {
 	rsc := v1beta1.RootSyncCondition{
 		Type:               condType,
@@ -406,6 +413,88 @@ func TestSetSyncing(t *testing.T) {
 		wantUpdated      bool
 		wantTransitioned bool
 	}{
+		{
+			name: "Update observedGeneration",
+			rs: fake.RootSyncObjectV1Beta1(configsync.RootSyncName,
+				withConditions(
+					v1beta1.RootSyncCondition{
+						Type:               v1beta1.RootSyncSyncing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "Syncing",
+						Commit:             "commit-1",
+						ObservedGeneration: 1,
+						ErrorSummary:       &v1beta1.ErrorSummary{},
+						LastUpdateTime:     initialNow,
+						LastTransitionTime: initialNow,
+					}),
+				withObservedGeneration(2)),
+			status:       true,
+			reason:       "Syncing",
+			commit:       "commit-1",
+			errorSummary: &v1beta1.ErrorSummary{},
+			timestamp:    updatedNow,
+			want: []v1beta1.RootSyncCondition{
+				// Update but no transition
+				{
+					Type:               v1beta1.RootSyncSyncing,
+					Status:             metav1.ConditionTrue,
+					Reason:             "Syncing",
+					Commit:             "commit-1",
+					ObservedGeneration: 2,
+					ErrorSummary:       &v1beta1.ErrorSummary{},
+					LastUpdateTime:     updatedNow,
+					LastTransitionTime: initialNow,
+				},
+			},
+			wantUpdated:      true,
+			wantTransitioned: false,
+		},
+		{
+			name: "Keep observedGeneration while reconciling",
+			rs: fake.RootSyncObjectV1Beta1(configsync.RootSyncName,
+				withConditions(
+					v1beta1.RootSyncCondition{
+						Type:   v1beta1.RootSyncReconciling,
+						Status: metav1.ConditionTrue,
+					},
+					v1beta1.RootSyncCondition{
+						Type:               v1beta1.RootSyncSyncing,
+						Status:             metav1.ConditionTrue,
+						Reason:             "Syncing",
+						Commit:             "commit-1",
+						ObservedGeneration: 1,
+						ErrorSummary:       &v1beta1.ErrorSummary{},
+						LastUpdateTime:     initialNow,
+						LastTransitionTime: initialNow,
+					}),
+				withObservedGeneration(2)),
+			status:       false,
+			reason:       "Sync",
+			message:      "Sync Completed",
+			commit:       "commit-1",
+			errorSummary: &v1beta1.ErrorSummary{},
+			timestamp:    updatedNow,
+			want: []v1beta1.RootSyncCondition{
+				{
+					Type:   v1beta1.RootSyncReconciling,
+					Status: metav1.ConditionTrue,
+				},
+				// Update and transition
+				{
+					Type:               v1beta1.RootSyncSyncing,
+					Status:             metav1.ConditionFalse,
+					Reason:             "Sync",
+					Message:            "Sync Completed",
+					Commit:             "commit-1",
+					ObservedGeneration: 1,
+					ErrorSummary:       &v1beta1.ErrorSummary{},
+					LastUpdateTime:     updatedNow,
+					LastTransitionTime: updatedNow,
+				},
+			},
+			wantUpdated:      true,
+			wantTransitioned: true,
+		},
 		{
 			name:         "Set new syncing condition without error",
 			rs:           fake.RootSyncObjectV1Beta1(configsync.RootSyncName),
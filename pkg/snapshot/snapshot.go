@@ -0,0 +1,234 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot persists the objects applied by a reconciler for a commit,
+// so that they can be re-applied later for disaster recovery, even if the
+// source of truth is broken or unreachable.
+//
+// The snapshots are stored in Secrets, rather than ConfigMaps, because the
+// applied objects may include Secrets, whose data must not be readable by
+// everyone who can read ConfigMaps.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ObjectsKey is the key of the gzipped JSON list of objects in the data
+	// of a snapshot Secret.
+	ObjectsKey = "objects.json.gz"
+
+	// Retention is the number of snapshots kept for each reconciler. The
+	// oldest snapshots are deleted when new ones are saved.
+	Retention = 5
+
+	// maxSize is the maximum size of the compressed objects of a snapshot,
+	// leaving room for the rest of the Secret below the 1MiB object size
+	// limit of the API server.
+	maxSize = 1000 * 1024
+)
+
+// Snapshot is the set of objects applied by a reconciler for a commit.
+type Snapshot struct {
+	// Commit is the commit the objects were parsed from.
+	Commit string
+	// SavedAt is when the snapshot was last saved.
+	SavedAt time.Time
+	// Objects are the applied objects.
+	Objects []*unstructured.Unstructured
+}
+
+// Name returns the name of the Secret holding the snapshot of the commit
+// for the reconciler. The commit is hashed, as it may be an OCI image digest,
+// which is not a valid object name.
+func Name(reconcilerName, commit string) string {
+	hash := sha256.Sum256([]byte(commit))
+	return fmt.Sprintf("%s-snapshot-%x", reconcilerName, hash[:6])
+}
+
+// Save stores the snapshot of the objects applied for the commit in a
+// Secret in the namespace, and deletes the oldest snapshots beyond the
+// Retention. Saving a snapshot for a commit which already has one only
+// refreshes its content.
+func Save(ctx context.Context, c client.Client, namespace, reconcilerName, commit string, objs []*unstructured.Unstructured, now time.Time) error {
+	data, err := encode(objs)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxSize {
+		return errors.Errorf("the snapshot of commit %s is too large: %d bytes compressed, exceeding %d bytes", commit, len(data), maxSize)
+	}
+
+	secret := &corev1.Secret{}
+	secret.Name = Name(reconcilerName, commit)
+	secret.Namespace = namespace
+	op, err := createOrUpdate(ctx, c, secret, func() {
+		core.SetLabel(secret, metadata.SnapshotLabel, "true")
+		core.SetLabel(secret, metadata.ReconcilerLabel, reconcilerName)
+		core.SetLabel(secret, metadata.ManagedByKey, metadata.ManagedByValue)
+		core.SetAnnotation(secret, metadata.SnapshotCommitAnnotationKey, commit)
+		core.SetAnnotation(secret, metadata.SnapshotSavedAtAnnotationKey, now.UTC().Format(time.RFC3339))
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{ObjectsKey: data}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s the snapshot of commit %s", op, commit)
+	}
+	return prune(ctx, c, namespace, reconcilerName)
+}
+
+// Load returns the snapshot of the commit for the reconciler.
+// Returns a NotFound error if there is no snapshot of the commit.
+func Load(ctx context.Context, c client.Client, namespace, reconcilerName, commit string) (*Snapshot, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: namespace, Name: Name(reconcilerName, commit)}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+	if got := secret.Annotations[metadata.SnapshotCommitAnnotationKey]; got != commit {
+		return nil, apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	objs, err := decode(secret.Data[ObjectsKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid snapshot %s/%s", namespace, secret.Name)
+	}
+	return &Snapshot{
+		Commit:  commit,
+		SavedAt: savedAt(secret),
+		Objects: objs,
+	}, nil
+}
+
+// List returns the commits with a snapshot for the reconciler, from the
+// newest to the oldest snapshot.
+func List(ctx context.Context, c client.Client, namespace, reconcilerName string) ([]string, error) {
+	secrets, err := list(ctx, c, namespace, reconcilerName)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]string, len(secrets))
+	for i, secret := range secrets {
+		commits[i] = secret.Annotations[metadata.SnapshotCommitAnnotationKey]
+	}
+	return commits, nil
+}
+
+// list returns the snapshot Secrets of the reconciler, from the most to the
+// least recently saved. A snapshot is saved again when its commit is synced
+// again, such as after a revert, so that it is not pruned while in use.
+func list(ctx context.Context, c client.Client, namespace, reconcilerName string) ([]corev1.Secret, error) {
+	secretList := &corev1.SecretList{}
+	if err := c.List(ctx, secretList, client.InNamespace(namespace), client.MatchingLabels{
+		metadata.SnapshotLabel:   "true",
+		metadata.ReconcilerLabel: reconcilerName,
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to list the snapshots")
+	}
+	secrets := secretList.Items
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return savedAt(&secrets[j]).Before(savedAt(&secrets[i]))
+	})
+	return secrets, nil
+}
+
+// savedAt returns when the snapshot was last saved, or the zero time if the
+// annotation is missing or invalid.
+func savedAt(secret *corev1.Secret) time.Time {
+	t, err := time.Parse(time.RFC3339, secret.Annotations[metadata.SnapshotSavedAtAnnotationKey])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// prune deletes the oldest snapshots of the reconciler beyond the Retention.
+func prune(ctx context.Context, c client.Client, namespace, reconcilerName string) error {
+	secrets, err := list(ctx, c, namespace, reconcilerName)
+	if err != nil {
+		return err
+	}
+	for i := Retention; i < len(secrets); i++ {
+		if err := c.Delete(ctx, &secrets[i]); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the snapshot %s/%s", namespace, secrets[i].Name)
+		}
+	}
+	return nil
+}
+
+// createOrUpdate creates the Secret, or updates it if it already exists,
+// after calling mutate. Returns the operation attempted.
+func createOrUpdate(ctx context.Context, c client.Client, secret *corev1.Secret, mutate func()) (string, error) {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "get", err
+		}
+		mutate()
+		return "create", c.Create(ctx, secret)
+	}
+	mutate()
+	return "update", c.Update(ctx, secret)
+}
+
+func encode(objs []*unstructured.Unstructured) ([]byte, error) {
+	items := make([]map[string]interface{}, len(objs))
+	for i, obj := range objs {
+		items[i] = obj.Object
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(items); err != nil {
+		return nil, errors.Wrap(err, "failed to encode the snapshot")
+	}
+	if err := gz.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to compress the snapshot")
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) ([]*unstructured.Unstructured, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	objs := make([]*unstructured.Unstructured, len(items))
+	for i, item := range items {
+		objs[i] = &unstructured.Unstructured{Object: item}
+	}
+	return objs, nil
+}
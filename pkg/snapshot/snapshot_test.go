@@ -0,0 +1,102 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	namespace      = "config-management-system"
+	reconcilerName = "root-reconciler"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	c := syncerFake.NewClient(t, core.Scheme)
+
+	cm := fake.UnstructuredObject(kinds.ConfigMap(), core.Name("cm"), core.Namespace("bookstore"))
+	cm.Object["data"] = map[string]interface{}{"key": "value"}
+	ns := fake.UnstructuredObject(kinds.Namespace(), core.Name("bookstore"))
+	require.NoError(t, Save(ctx, c, namespace, reconcilerName, "abc123", []*unstructured.Unstructured{ns, cm}, time.Now()))
+
+	// The snapshot is stored in a Secret, as it may contain Secret data.
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: Name(reconcilerName, "abc123")}, secret))
+	assert.Contains(t, secret.Data, ObjectsKey)
+	cms := &corev1.ConfigMapList{}
+	require.NoError(t, c.List(ctx, cms))
+	assert.Empty(t, cms.Items)
+
+	snap, err := Load(ctx, c, namespace, reconcilerName, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", snap.Commit)
+	assert.Equal(t, []*unstructured.Unstructured{ns, cm}, snap.Objects)
+
+	_, err = Load(ctx, c, namespace, reconcilerName, "def456")
+	assert.True(t, apierrors.IsNotFound(err), "unexpected error: %v", err)
+	_, err = Load(ctx, c, namespace, "ns-reconciler-bookstore", "abc123")
+	assert.True(t, apierrors.IsNotFound(err), "unexpected error: %v", err)
+
+	// Saving again refreshes the snapshot.
+	require.NoError(t, Save(ctx, c, namespace, reconcilerName, "abc123", []*unstructured.Unstructured{ns}, time.Now()))
+	snap, err = Load(ctx, c, namespace, reconcilerName, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{ns}, snap.Objects)
+}
+
+func TestSaveKeepsRetention(t *testing.T) {
+	ctx := context.Background()
+	var objs []client.Object
+	created := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < Retention; i++ {
+		commit := fmt.Sprintf("commit-%d", i)
+		secret := &corev1.Secret{}
+		secret.Name = Name(reconcilerName, commit)
+		secret.Namespace = namespace
+		secret.Labels = map[string]string{
+			"configsync.gke.io/snapshot":   "true",
+			"configsync.gke.io/reconciler": reconcilerName,
+		}
+		secret.Annotations = map[string]string{
+			"configsync.gke.io/snapshot-commit":   commit,
+			"configsync.gke.io/snapshot-saved-at": created.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+		}
+		objs = append(objs, secret)
+	}
+	c := syncerFake.NewClient(t, core.Scheme, objs...)
+
+	require.NoError(t, Save(ctx, c, namespace, reconcilerName, "commit-new", nil, created.Add(time.Hour)))
+
+	commits, err := List(ctx, c, namespace, reconcilerName)
+	require.NoError(t, err)
+	require.Len(t, commits, Retention)
+	assert.NotContains(t, commits, "commit-0")
+	assert.Contains(t, commits, "commit-new")
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "time"
+
+// ApprovalErrorCode is the error code for an ApprovalError.
+const ApprovalErrorCode = "1079"
+
+var approvalError = NewErrorBuilder(ApprovalErrorCode)
+
+// ApprovalDeniedError reports that the external approval system denied
+// applying the commit.
+func ApprovalDeniedError(commit, decidedBy, message string) Error {
+	return approvalError.
+		Sprintf("commit %q was denied by %q: %s. "+
+			"Config Sync keeps the last synced commit until a new commit is approved.", commit, decidedBy, message).
+		Build()
+}
+
+// ApprovalTimedOutError reports that the commit was not approved before the
+// approval request expired.
+func ApprovalTimedOutError(commit string, expiredAt time.Time) Error {
+	return approvalError.
+		Sprintf("commit %q was not approved before the approval request expired at %s. "+
+			"Config Sync keeps the last synced commit until the commit is approved.", commit, expiredAt.Format(time.RFC3339)).
+		Build()
+}
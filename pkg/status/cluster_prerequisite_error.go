@@ -0,0 +1,34 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// ClusterPrerequisiteErrorCode is the error code for a ClusterPrerequisiteError.
+const ClusterPrerequisiteErrorCode = "1077"
+
+var clusterPrerequisiteError = NewErrorBuilder(ClusterPrerequisiteErrorCode)
+
+// ClusterPrerequisiteError reports that an object declared for a RepoSync is
+// waiting for the CustomResourceDefinition of its type, which the namespace
+// reconciler cannot create.
+func ClusterPrerequisiteError(resource client.Object) Error {
+	gk := resource.GetObjectKind().GroupVersionKind().GroupKind()
+	return clusterPrerequisiteError.
+		Sprintf("waiting for the cluster-scoped CustomResourceDefinition of type %q, "+
+			"which must be created by a cluster admin or a RootSync. "+
+			"Config Sync will sync the object as soon as the type is available.", gk).
+		BuildWithResources(resource)
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// DeclaredObjectMutationErrorCode is the error code for a DeclaredObjectMutationError.
+const DeclaredObjectMutationErrorCode = "1071"
+
+var declaredObjectMutationError = NewErrorBuilder(DeclaredObjectMutationErrorCode)
+
+// DeclaredObjectMutationError reports that a mutation function of a
+// DeclaredObjectMutator failed. The DeclaredObjectMutator is reported as the
+// first resource, followed by the declared object being mutated, if any.
+func DeclaredObjectMutationError(mutator client.Object, err error, resources ...client.Object) Error {
+	return declaredObjectMutationError.
+		Wrap(err).
+		Sprintf("failed to evaluate the DeclaredObjectMutator %q", mutator.GetName()).
+		BuildWithResources(append([]client.Object{mutator}, resources...)...)
+}
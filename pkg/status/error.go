@@ -209,7 +209,8 @@ func fromResourceError(err ResourceError) v1.ConfigManagementError {
 	return cme
 }
 
-func toResourceRef(r client.Object) v1beta1.ResourceRef {
+// ToResourceRef converts an object to a ResourceRef.
+func ToResourceRef(r client.Object) v1beta1.ResourceRef {
 	gvk := r.GetObjectKind().GroupVersionKind()
 	return v1beta1.ResourceRef{
 		SourcePath: GetSourceAnnotation(r),
@@ -243,7 +244,7 @@ func cseFromPathError(err PathError) v1beta1.ConfigSyncError {
 func cseFromResourceError(err ResourceError) v1beta1.ConfigSyncError {
 	cse := cseFromError(err)
 	for _, r := range err.Resources() {
-		cse.Resources = append(cse.Resources, toResourceRef(r))
+		cse.Resources = append(cse.Resources, ToResourceRef(r))
 	}
 	return cse
 }
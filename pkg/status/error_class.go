@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+// ErrorClass groups errors by how they are expected to be resolved, which
+// determines how aggressively the reconciler retries them.
+type ErrorClass string
+
+const (
+	// TransientErrorClass is the class of errors that are likely to be
+	// auto-resolved by retrying, e.g. a flaky or overloaded API server.
+	TransientErrorClass ErrorClass = "transient"
+	// UserErrorClass is the class of errors that require a change from the
+	// user, either in the source of truth or on the cluster, e.g. invalid YAML.
+	UserErrorClass ErrorClass = "user"
+	// TerminalErrorClass is the class of internal errors that neither a retry
+	// nor the user is expected to resolve.
+	TerminalErrorClass ErrorClass = "terminal"
+)
+
+// resourceConflictErrorCode mirrors client.ResourceConflictCode, which cannot
+// be imported here without an import cycle.
+const resourceConflictErrorCode = "2008"
+
+// namespaceTerminatingErrorCode mirrors applier.NamespaceTerminatingErrorCode,
+// which cannot be imported here without an import cycle.
+const namespaceTerminatingErrorCode = "2025"
+
+// workloadUpdateHeldErrorCode mirrors applier.WorkloadUpdateHeldErrorCode,
+// which cannot be imported here without an import cycle.
+const workloadUpdateHeldErrorCode = "2030"
+
+var transientErrorCodes = map[string]struct{}{
+	TransientErrorCode:            {},
+	APIServerErrorCode:            {},
+	OSErrorCode:                   {},
+	resourceConflictErrorCode:     {},
+	namespaceTerminatingErrorCode: {},
+	workloadUpdateHeldErrorCode:   {},
+}
+
+var terminalErrorCodes = map[string]struct{}{
+	InternalErrorCode:     {},
+	UndocumentedErrorCode: {},
+}
+
+// ClassOf returns the ErrorClass of the error, based on its error code.
+func ClassOf(err Error) ErrorClass {
+	if _, ok := transientErrorCodes[err.Code()]; ok {
+		return TransientErrorClass
+	}
+	if _, ok := terminalErrorCodes[err.Code()]; ok {
+		return TerminalErrorClass
+	}
+	return UserErrorClass
+}
+
+// Classify returns the ErrorClass that should drive the retry behavior for
+// `errs`. Errors that need a user fix take precedence over terminal errors,
+// which take precedence over transient errors, because retrying quickly only
+// helps if every error is transient.
+//
+// Classify returns TransientErrorClass if `errs` is empty.
+func Classify(errs MultiError) ErrorClass {
+	result := TransientErrorClass
+	if errs == nil {
+		return result
+	}
+	for _, err := range errs.Errors() {
+		switch ClassOf(err) {
+		case UserErrorClass:
+			return UserErrorClass
+		case TerminalErrorClass:
+			result = TerminalErrorClass
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	transientErr := TransientError(errors.New("etcdserver: leader changed"))
+	apiServerErr := APIServerError(errors.New("connection refused"), "failed to list")
+	userErr := SourceError.Sprint("invalid YAML").Build()
+	terminalErr := InternalError("unexpected state")
+
+	testCases := []struct {
+		name string
+		errs MultiError
+		want ErrorClass
+	}{
+		{
+			name: "no errors",
+			errs: nil,
+			want: TransientErrorClass,
+		},
+		{
+			name: "only transient errors",
+			errs: Append(transientErr, apiServerErr),
+			want: TransientErrorClass,
+		},
+		{
+			name: "terminal error wins over transient errors",
+			errs: Append(transientErr, terminalErr),
+			want: TerminalErrorClass,
+		},
+		{
+			name: "user error wins over all other errors",
+			errs: Append(Append(transientErr, terminalErr), userErr),
+			want: UserErrorClass,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.errs); got != tc.want {
+				t.Errorf("Classify() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
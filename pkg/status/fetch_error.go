@@ -0,0 +1,42 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+const (
+	// SourceAuthErrorCode is the error code for a SourceAuthError.
+	SourceAuthErrorCode = "2019"
+	// SourceNotFoundErrorCode is the error code for a SourceNotFoundError.
+	SourceNotFoundErrorCode = "2020"
+	// SourceRateLimitedErrorCode is the error code for a SourceRateLimitedError.
+	SourceRateLimitedErrorCode = "2021"
+	// SourceHostKeyErrorCode is the error code for a SourceHostKeyError.
+	SourceHostKeyErrorCode = "2022"
+)
+
+// SourceAuthError is an ErrorBuilder for source fetch failures caused by the
+// source host rejecting the configured credentials.
+var SourceAuthError = NewErrorBuilder(SourceAuthErrorCode)
+
+// SourceNotFoundError is an ErrorBuilder for source fetch failures caused by
+// the repository, branch or revision not being found.
+var SourceNotFoundError = NewErrorBuilder(SourceNotFoundErrorCode)
+
+// SourceRateLimitedError is an ErrorBuilder for source fetch failures caused by
+// the source host throttling requests.
+var SourceRateLimitedError = NewErrorBuilder(SourceRateLimitedErrorCode)
+
+// SourceHostKeyError is an ErrorBuilder for source fetch failures caused by
+// the SSH host key of the source host not matching the known hosts.
+var SourceHostKeyError = NewErrorBuilder(SourceHostKeyErrorCode)
@@ -20,12 +20,19 @@ const InternalHydrationErrorCode = "2015"
 // ActionableHydrationErrorCode is the error code for a user actionable Error related to the hydration process.
 const ActionableHydrationErrorCode = "1068"
 
+// HydrationVerificationErrorCode is the error code for hydrated configs that do
+// not match the manifest written by the hydration controller.
+const HydrationVerificationErrorCode = "2024"
+
 // internalHydrationErrorBuilder is an ErrorBuilder for internal errors related to the hydration process.
 var internalHydrationErrorBuilder = NewErrorBuilder(InternalHydrationErrorCode)
 
 // actionableHydrationErrorBuilder is an ErrorBuilder for user actionable errors related to the hydration process.
 var actionableHydrationErrorBuilder = NewErrorBuilder(ActionableHydrationErrorCode)
 
+// hydrationVerificationErrorBuilder is an ErrorBuilder for hydrated configs that fail the verification.
+var hydrationVerificationErrorBuilder = NewErrorBuilder(HydrationVerificationErrorCode)
+
 // InternalHydrationError returns an internal error related to the hydration process.
 func InternalHydrationError(err error, format string, a ...interface{}) Error {
 	return internalHydrationErrorBuilder.Wrap(err).Sprintf(format, a...).Build()
@@ -38,6 +45,8 @@ func HydrationError(code string, err error) Error {
 		return TransientError(err)
 	case ActionableHydrationErrorCode:
 		return actionableHydrationErrorBuilder.Wrap(err).Build()
+	case HydrationVerificationErrorCode:
+		return hydrationVerificationErrorBuilder.Wrap(err).Build()
 	default:
 		return internalHydrationErrorBuilder.Wrap(err).Build()
 	}
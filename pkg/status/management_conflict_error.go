@@ -104,7 +104,7 @@ func (m managementConflictErrorImpl) ToCME() v1.ConfigManagementError {
 
 func (m managementConflictErrorImpl) ToCSE() v1beta1.ConfigSyncError {
 	cse := cseFromError(m)
-	cse.Resources = append(cse.Resources, toResourceRef(m.resource))
+	cse.Resources = append(cse.Resources, ToResourceRef(m.resource))
 	return cse
 }
 
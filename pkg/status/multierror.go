@@ -33,7 +33,9 @@ type MultiError interface {
 
 var nonBlockingErrorCodes = map[string]struct{}{
 	UnknownKindErrorCode:         {},
+	ClusterPrerequisiteErrorCode: {},
 	EncodeDeclaredFieldErrorCode: {},
+	PlaintextSecretWarningCode:   {},
 }
 
 // HasTransientErrors return whether `errs` include any transient errors.
@@ -0,0 +1,31 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// NamespaceFrozenErrorCode is the error code for a NamespaceFrozenError.
+const NamespaceFrozenErrorCode = "2017"
+
+var namespaceFrozenError = NewErrorBuilder(NamespaceFrozenErrorCode)
+
+// NamespaceFrozenError reports that changes to a declared resource were not
+// applied, because the Namespace it belongs to is frozen.
+func NamespaceFrozenError(namespace string, resource client.Object) Error {
+	return namespaceFrozenError.
+		Sprintf("skipped syncing changes to the resource, because Namespace %q is frozen. "+
+			"Remove the freeze annotation from the Namespace to resume syncing", namespace).
+		BuildWithResources(resource)
+}
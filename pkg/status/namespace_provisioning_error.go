@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// NamespaceProvisioningErrorCode is the error code for a NamespaceProvisioningError.
+const NamespaceProvisioningErrorCode = "1076"
+
+var namespaceProvisioningError = NewErrorBuilder(NamespaceProvisioningErrorCode)
+
+// NamespaceProvisioningError reports that the namespace provisioning template
+// could not be rendered for a requested Namespace. The ConfigMap holding the
+// template is reported as the resource.
+func NamespaceProvisioningError(template client.Object, namespace string, err error) Error {
+	return namespaceProvisioningError.
+		Wrap(err).
+		Sprintf("failed to render the namespace provisioning template for Namespace %q", namespace).
+		BuildWithResources(template)
+}
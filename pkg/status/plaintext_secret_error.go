@@ -0,0 +1,51 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PlaintextSecretWarningCode is the error code for a PlaintextSecretWarning.
+	PlaintextSecretWarningCode = "1073"
+	// PlaintextSecretErrorCode is the error code for a PlaintextSecretError.
+	PlaintextSecretErrorCode = "1074"
+)
+
+var (
+	plaintextSecretWarning = NewErrorBuilder(PlaintextSecretWarningCode)
+	plaintextSecretError   = NewErrorBuilder(PlaintextSecretErrorCode)
+)
+
+// PlaintextSecretWarning reports that a declared Secret seems to contain
+// plaintext credentials. It does not block the apply.
+func PlaintextSecretWarning(secret client.Object, keys []string) Error {
+	return plaintextSecretWarning.
+		Sprintf("the Secret seems to contain plaintext credentials in the keys [%s]. "+
+			"Commit encrypted Secrets or references to an external secret store instead", strings.Join(keys, ", ")).
+		BuildWithResources(secret)
+}
+
+// PlaintextSecretError reports that a declared Secret seems to contain
+// plaintext credentials, and blocks the apply.
+func PlaintextSecretError(secret client.Object, keys []string) Error {
+	return plaintextSecretError.
+		Sprintf("the Secret seems to contain plaintext credentials in the keys [%s]. "+
+			"Commit encrypted Secrets or references to an external secret store instead", strings.Join(keys, ", ")).
+		BuildWithResources(secret)
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// ReferenceMismatchErrorCode is the error code for a ReferenceMismatchError.
+const ReferenceMismatchErrorCode = "2029"
+
+var referenceMismatchError = NewErrorBuilder(ReferenceMismatchErrorCode)
+
+// ReferenceMismatchError reports that a reference-only resource declared in
+// the source of truth is missing from the cluster, or does not match its
+// declaration. Reference-only resources are owned by another system, so Config
+// Sync never creates or updates them.
+func ReferenceMismatchError(reason string, resource client.Object) Error {
+	return referenceMismatchError.
+		Sprintf("the reference-only resource %s. "+
+			"Config Sync does not manage reference-only resources, so the system owning it must fix it, "+
+			"or the declaration must be updated in a new commit", reason).
+		BuildWithResources(resource)
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// RenameErrorCode is the error code for a RenameError.
+const RenameErrorCode = "1080"
+
+var renameError = NewErrorBuilder(RenameErrorCode)
+
+// RenameError reports that the rename rules of the RootSync or RepoSync
+// renamed a declared object to an invalid or conflicting name.
+func RenameError(message string, resources ...client.Object) Error {
+	return renameError.
+		Sprintf("failed to rename the declared objects with the spec.renames rules: %s", message).
+		BuildWithResources(resources...)
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// RepoSyncQuotaExceededErrorCode is the error code for a RepoSyncQuotaExceededError.
+const RepoSyncQuotaExceededErrorCode = "1070"
+
+var repoSyncQuotaExceededError = NewErrorBuilder(RepoSyncQuotaExceededErrorCode)
+
+// RepoSyncQuotaExceededError reports that the resources declared in a
+// RepoSync's source of truth exceed a limit of a RepoSyncQuota. The quota is
+// reported as the first resource, followed by the offending resources, if any.
+func RepoSyncQuotaExceededError(quota client.Object, message string, resources ...client.Object) Error {
+	return repoSyncQuotaExceededError.
+		Sprintf("the declared resources exceed the RepoSyncQuota %q: %s", quota.GetName(), message).
+		BuildWithResources(append([]client.Object{quota}, resources...)...)
+}
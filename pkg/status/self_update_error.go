@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// SelfUpdateRevertedErrorCode is the error code for a SelfUpdateRevertedError.
+const SelfUpdateRevertedErrorCode = "2023"
+
+var selfUpdateRevertedError = NewErrorBuilder(SelfUpdateRevertedErrorCode)
+
+// SelfUpdateRevertedError reports that changes to a declared Config Sync
+// component were reverted, because the components did not become healthy
+// after the changes were applied.
+func SelfUpdateRevertedError(commit string, cause error, resource client.Object) Error {
+	return selfUpdateRevertedError.
+		Sprintf("reverted the changes to the Config Sync component from commit %q, because the components did not become healthy: %v. "+
+			"Fix the component manifests in a new commit to resume syncing them", commit, cause).
+		BuildWithResources(resource)
+}
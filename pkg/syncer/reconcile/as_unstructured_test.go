@@ -17,6 +17,7 @@ package reconcile
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -180,3 +181,24 @@ func newUnstructured(gvk schema.GroupVersionKind) *unstructured.Unstructured {
 	u.SetGroupVersionKind(gvk)
 	return u
 }
+
+func TestAsUnstructuredSanitized_PreservesUnknownFields(t *testing.T) {
+	u := newUnstructured(schema.GroupVersionKind{Group: "operator.example.com", Version: "v1", Kind: "Database"})
+	u.SetName("foo")
+	spec := map[string]interface{}{
+		"replicas": int64(1),
+		"x-extra":  map[string]interface{}{"nested": []interface{}{"a", "b"}},
+	}
+	if err := unstructured.SetNestedMap(u.Object, spec, "spec"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AsUnstructuredSanitized(u)
+	if err != nil {
+		t.Fatalf("unable to convert %T to Unstructured: %v", u, err)
+	}
+	gotSpec, _, _ := unstructured.NestedMap(got.Object, "spec")
+	if diff := cmp.Diff(spec, gotSpec); diff != "" {
+		t.Errorf("unexpected spec diff (-want +got):\n%s", diff)
+	}
+}
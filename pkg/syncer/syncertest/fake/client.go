@@ -38,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/klog/v2"
@@ -398,6 +399,12 @@ func (c *Client) Create(_ context.Context, obj client.Object, opts ...client.Cre
 	// Set defaults
 	c.scheme.Default(tObj)
 
+	// Generate a name, like the API server does
+	if tObj.GetName() == "" && tObj.GetGenerateName() != "" {
+		tObj.SetName(tObj.GetGenerateName() + utilrand.String(5))
+		obj.SetName(tObj.GetName())
+	}
+
 	id := c.idFromObject(tObj)
 	_, found := c.Objects[id]
 	if found {
@@ -0,0 +1,227 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syncwindow evaluates the sync windows of a RootSync or RepoSync,
+// which restrict when the reconciler applies the changes of the source of
+// truth.
+package syncwindow
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+// MaxDuration is the longest duration of a sync window.
+const MaxDuration = 31 * 24 * time.Hour
+
+// Window is a parsed sync window.
+type Window struct {
+	kind     string
+	schedule *schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// Parse parses and validates the sync windows.
+func Parse(windows []v1beta1.SyncWindow) ([]Window, error) {
+	var result []Window
+	for i, w := range windows {
+		if w.Kind != v1beta1.SyncWindowAllow && w.Kind != v1beta1.SyncWindowDeny {
+			return nil, errors.Errorf("syncWindows[%d]: kind must be one of %s, %s, got %q",
+				i, v1beta1.SyncWindowAllow, v1beta1.SyncWindowDeny, w.Kind)
+		}
+		s, err := parseSchedule(w.Schedule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "syncWindows[%d]: invalid schedule %q", i, w.Schedule)
+		}
+		if w.Duration.Duration <= 0 || w.Duration.Duration > MaxDuration {
+			return nil, errors.Errorf("syncWindows[%d]: duration must be positive and at most %s, got %s",
+				i, MaxDuration, w.Duration.Duration)
+		}
+		location := time.UTC
+		if w.TimeZone != "" {
+			location, err = time.LoadLocation(w.TimeZone)
+			if err != nil {
+				return nil, errors.Wrapf(err, "syncWindows[%d]: invalid time zone %q", i, w.TimeZone)
+			}
+		}
+		result = append(result, Window{
+			kind:     w.Kind,
+			schedule: s,
+			duration: w.Duration.Duration,
+			location: location,
+		})
+	}
+	return result, nil
+}
+
+// Allowed returns true if the windows allow applying changes at the time:
+// none of the deny windows is open, and either there are no allow windows, or
+// one of them is open.
+func Allowed(windows []Window, now time.Time) bool {
+	hasAllow := false
+	allowed := false
+	for _, w := range windows {
+		switch w.kind {
+		case v1beta1.SyncWindowDeny:
+			if w.open(now) {
+				return false
+			}
+		case v1beta1.SyncWindowAllow:
+			hasAllow = true
+			if !allowed && w.open(now) {
+				allowed = true
+			}
+		}
+	}
+	return allowed || !hasAllow
+}
+
+// open returns true if the window opened less than its duration before the
+// time.
+func (w Window) open(now time.Time) bool {
+	for start := now.Truncate(time.Minute); start.Add(w.duration).After(now); start = start.Add(-time.Minute) {
+		if w.schedule.matches(start.In(w.location)) {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule is a parsed cron expression. Each field holds the set of the
+// matching values.
+type schedule struct {
+	minutes    map[int]bool
+	hours      map[int]bool
+	daysOfMon  map[int]bool
+	months     map[int]bool
+	daysOfWeek map[int]bool
+	// anyDayOfMon and anyDayOfWeek are true if the day of month and the day
+	// of week fields are `*`. Like cron, if both fields are restricted, a day
+	// matches if either field matches.
+	anyDayOfMon  bool
+	anyDayOfWeek bool
+}
+
+func parseSchedule(expr string) (*schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("must have 5 fields, got %d", len(fields))
+	}
+	s := &schedule{
+		anyDayOfMon:  fields[2] == "*",
+		anyDayOfWeek: fields[4] == "*",
+	}
+	var err error
+	if s.minutes, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrap(err, "minute")
+	}
+	if s.hours, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrap(err, "hour")
+	}
+	if s.daysOfMon, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrap(err, "day of month")
+	}
+	if s.months, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrap(err, "month")
+	}
+	// Both 0 and 7 are Sunday.
+	if s.daysOfWeek, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrap(err, "day of week")
+	}
+	if s.daysOfWeek[7] {
+		s.daysOfWeek[0] = true
+	}
+	return s, nil
+}
+
+// parseField parses a field of a cron expression, which is a comma-separated
+// list of `*`, values and ranges, each with an optional `/step`.
+func parseField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, errors.Errorf("invalid step in %q", part)
+			}
+		}
+		low, high := min, max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], min, max); err != nil {
+				return nil, err
+			}
+			if high, err = parseValue(bounds[1], min, max); err != nil {
+				return nil, err
+			}
+			if low > high {
+				return nil, errors.Errorf("invalid range %q", rangeExpr)
+			}
+		default:
+			v, err := parseValue(rangeExpr, min, max)
+			if err != nil {
+				return nil, err
+			}
+			low = v
+			if step == 1 {
+				high = v
+			}
+		}
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, errors.Errorf("value %d out of range [%d, %d]", v, min, max)
+	}
+	return v, nil
+}
+
+// matches returns true if the schedule matches the minute of the time.
+func (s *schedule) matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	dayOfMon := s.daysOfMon[t.Day()]
+	dayOfWeek := s.daysOfWeek[int(t.Weekday())]
+	switch {
+	case s.anyDayOfMon && s.anyDayOfWeek:
+		return true
+	case s.anyDayOfMon:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMon
+	default:
+		return dayOfMon || dayOfWeek
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncwindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+func window(kind, schedule string, duration time.Duration) v1beta1.SyncWindow {
+	return v1beta1.SyncWindow{Kind: kind, Schedule: schedule, Duration: metav1.Duration{Duration: duration}}
+}
+
+func TestAllowed(t *testing.T) {
+	// Monday.
+	now := time.Date(2022, 11, 7, 22, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		windows []v1beta1.SyncWindow
+		want    bool
+	}{
+		{
+			name: "no windows",
+			want: true,
+		},
+		{
+			name:    "open allow window",
+			windows: []v1beta1.SyncWindow{window(v1beta1.SyncWindowAllow, "0 22 * * 1-5", 2*time.Hour)},
+			want:    true,
+		},
+		{
+			name:    "closed allow window",
+			windows: []v1beta1.SyncWindow{window(v1beta1.SyncWindowAllow, "0 22 * * 1-5", 30*time.Minute)},
+			want:    false,
+		},
+		{
+			name:    "allow window on other days",
+			windows: []v1beta1.SyncWindow{window(v1beta1.SyncWindowAllow, "0 22 * * 6,0", 2*time.Hour)},
+			want:    false,
+		},
+		{
+			name: "open deny window takes precedence",
+			windows: []v1beta1.SyncWindow{
+				window(v1beta1.SyncWindowAllow, "* * * * *", time.Minute),
+				window(v1beta1.SyncWindowDeny, "0 20 7 11 *", 3*time.Hour),
+			},
+			want: false,
+		},
+		{
+			name:    "closed deny window",
+			windows: []v1beta1.SyncWindow{window(v1beta1.SyncWindowDeny, "0 9 * * *", 8*time.Hour)},
+			want:    true,
+		},
+		{
+			name:    "window opened the day before",
+			windows: []v1beta1.SyncWindow{window(v1beta1.SyncWindowAllow, "0 23 6 11 *", 24*time.Hour)},
+			want:    true,
+		},
+		{
+			name: "window in another time zone",
+			windows: []v1beta1.SyncWindow{{
+				Kind:     v1beta1.SyncWindowAllow,
+				Schedule: "*/15 17 * * *",
+				Duration: metav1.Duration{Duration: time.Minute},
+				TimeZone: "America/New_York",
+			}},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			windows, err := Parse(tc.windows)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, Allowed(windows, now))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		window v1beta1.SyncWindow
+	}{
+		{
+			name:   "invalid kind",
+			window: window("maybe", "* * * * *", time.Hour),
+		},
+		{
+			name:   "missing field",
+			window: window(v1beta1.SyncWindowAllow, "* * * *", time.Hour),
+		},
+		{
+			name:   "value out of range",
+			window: window(v1beta1.SyncWindowAllow, "0 24 * * *", time.Hour),
+		},
+		{
+			name:   "invalid range",
+			window: window(v1beta1.SyncWindowAllow, "0 5-1 * * *", time.Hour),
+		},
+		{
+			name:   "invalid step",
+			window: window(v1beta1.SyncWindowAllow, "*/0 * * * *", time.Hour),
+		},
+		{
+			name:   "zero duration",
+			window: window(v1beta1.SyncWindowAllow, "* * * * *", 0),
+		},
+		{
+			name: "invalid time zone",
+			window: v1beta1.SyncWindow{
+				Kind:     v1beta1.SyncWindowAllow,
+				Schedule: "* * * * *",
+				Duration: metav1.Duration{Duration: time.Hour},
+				TimeZone: "Mars/Olympus_Mons",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]v1beta1.SyncWindow{tc.window})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tunables reloads the tunables of a running reconciler, such as the
+// log verbosity, the client-side QPS or the polling period, from a ConfigMap.
+// Unlike the reconciler flags, changing them does not restart the reconciler,
+// which keeps its state for troubleshooting.
+package tunables
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LogLevelKey is the ConfigMap key of the log verbosity, e.g. "5".
+	LogLevelKey = "logLevel"
+	// PollingPeriodKey is the ConfigMap key of the period between checks of
+	// the source for new commits, e.g. "30s".
+	PollingPeriodKey = "pollingPeriod"
+	// QPSKey is the ConfigMap key of the client-side QPS limit of the requests
+	// to the API server. A negative value disables the client-side throttling.
+	QPSKey = "qps"
+	// BurstKey is the ConfigMap key of the client-side burst limit of the
+	// requests to the API server.
+	BurstKey = "burst"
+
+	// reloadPeriod is how often the tunables ConfigMap is read.
+	reloadPeriod = 10 * time.Second
+)
+
+// ConfigMapName returns the name of the tunables ConfigMap of a reconciler.
+// The ConfigMap is in the namespace of the RootSync or RepoSync.
+func ConfigMapName(reconcilerName string) string {
+	return reconcilerName + "-tunables"
+}
+
+// Duration is a time.Duration that is safe to change while it is read by
+// other goroutines.
+type Duration struct {
+	mux   sync.RWMutex
+	value time.Duration
+}
+
+// NewDuration returns a Duration with the initial value d.
+func NewDuration(d time.Duration) *Duration {
+	return &Duration{value: d}
+}
+
+// Get returns the current value.
+func (d *Duration) Get() time.Duration {
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+	return d.value
+}
+
+// Set changes the value.
+func (d *Duration) Set(value time.Duration) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.value = value
+}
+
+// RateLimiter is a client-side rate limiter of the requests to the API server,
+// whose QPS and burst can be changed while it is used.
+type RateLimiter struct {
+	mux     sync.RWMutex
+	qps     float32
+	burst   int
+	limiter flowcontrol.RateLimiter
+}
+
+var _ flowcontrol.RateLimiter = &RateLimiter{}
+
+// NewRateLimiter returns a RateLimiter with the initial QPS and burst.
+// A negative QPS disables the client-side throttling, as in rest.Config.
+func NewRateLimiter(qps float32, burst int) *RateLimiter {
+	r := &RateLimiter{}
+	r.Set(qps, burst)
+	return r
+}
+
+// Set changes the QPS and burst.
+func (r *RateLimiter) Set(qps float32, burst int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.qps = qps
+	r.burst = burst
+	if qps > 0 {
+		r.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	} else {
+		r.limiter = flowcontrol.NewFakeAlwaysRateLimiter()
+	}
+}
+
+// Limits returns the current QPS and burst.
+func (r *RateLimiter) Limits() (float32, int) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.qps, r.burst
+}
+
+func (r *RateLimiter) current() flowcontrol.RateLimiter {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.limiter
+}
+
+// TryAccept implements flowcontrol.RateLimiter.
+func (r *RateLimiter) TryAccept() bool {
+	return r.current().TryAccept()
+}
+
+// Accept implements flowcontrol.RateLimiter.
+func (r *RateLimiter) Accept() {
+	r.current().Accept()
+}
+
+// Wait implements flowcontrol.RateLimiter.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	return r.current().Wait(ctx)
+}
+
+// Stop implements flowcontrol.RateLimiter.
+func (r *RateLimiter) Stop() {
+	r.current().Stop()
+}
+
+// QPS implements flowcontrol.RateLimiter.
+func (r *RateLimiter) QPS() float32 {
+	return r.current().QPS()
+}
+
+// Reloader periodically reads the tunables ConfigMap of a reconciler and
+// applies its changes. The tunables which are not set in the ConfigMap, or
+// whose value is invalid, are reset to the values the reconciler started with.
+type Reloader struct {
+	client        client.Client
+	key           client.ObjectKey
+	pollingPeriod *Duration
+	rateLimiter   *RateLimiter
+
+	defaults map[string]string
+	applied  map[string]string
+}
+
+// NewReloader returns a Reloader for the tunables ConfigMap with the given key.
+// The current values of pollingPeriod, rateLimiter and of the `v` flag are
+// the defaults of the tunables.
+func NewReloader(c client.Client, key client.ObjectKey, pollingPeriod *Duration, rateLimiter *RateLimiter) *Reloader {
+	qps, burst := rateLimiter.Limits()
+	defaults := map[string]string{
+		PollingPeriodKey: pollingPeriod.Get().String(),
+		QPSKey:           strconv.FormatFloat(float64(qps), 'f', -1, 32),
+		BurstKey:         strconv.Itoa(burst),
+	}
+	if v := flag.Lookup("v"); v != nil {
+		defaults[LogLevelKey] = v.Value.String()
+	}
+	return &Reloader{
+		client:        c,
+		key:           key,
+		pollingPeriod: pollingPeriod,
+		rateLimiter:   rateLimiter,
+		defaults:      defaults,
+		applied:       defaults,
+	}
+}
+
+// Run reloads the tunables until the context is cancelled.
+func (r *Reloader) Run(ctx context.Context) {
+	// Use timers, not tickers, like the parser.
+	reloadTimer := time.NewTimer(reloadPeriod)
+	defer reloadTimer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reloadTimer.C:
+			if err := r.Reload(ctx); err != nil {
+				klog.Warningf("Failed to reload the tunables from ConfigMap %s: %v", r.key, err)
+			}
+			reloadTimer.Reset(reloadPeriod)
+		}
+	}
+}
+
+// Reload reads the tunables ConfigMap once and applies the changed tunables.
+func (r *Reloader) Reload(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, r.key, cm); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	values := make(map[string]string, len(r.defaults))
+	for key, value := range r.defaults {
+		values[key] = value
+	}
+	for key, value := range cm.Data {
+		if _, found := r.defaults[key]; found {
+			values[key] = value
+		}
+	}
+
+	if values[LogLevelKey] != r.applied[LogLevelKey] {
+		if err := r.setLogLevel(values[LogLevelKey]); err != nil {
+			klog.Warningf("Ignoring the invalid %s tunable %q: %v", LogLevelKey, values[LogLevelKey], err)
+			values[LogLevelKey] = r.applied[LogLevelKey]
+		}
+	}
+	if values[PollingPeriodKey] != r.applied[PollingPeriodKey] {
+		if err := r.setPollingPeriod(values[PollingPeriodKey]); err != nil {
+			klog.Warningf("Ignoring the invalid %s tunable %q: %v", PollingPeriodKey, values[PollingPeriodKey], err)
+			values[PollingPeriodKey] = r.applied[PollingPeriodKey]
+		}
+	}
+	if values[QPSKey] != r.applied[QPSKey] || values[BurstKey] != r.applied[BurstKey] {
+		if err := r.setRateLimits(values[QPSKey], values[BurstKey]); err != nil {
+			klog.Warningf("Ignoring the invalid %s and %s tunables %q and %q: %v", QPSKey, BurstKey, values[QPSKey], values[BurstKey], err)
+			values[QPSKey] = r.applied[QPSKey]
+			values[BurstKey] = r.applied[BurstKey]
+		}
+	}
+	r.applied = values
+	return nil
+}
+
+func (r *Reloader) setLogLevel(value string) error {
+	if err := flag.Set("v", value); err != nil {
+		return err
+	}
+	klog.Infof("Log verbosity changed to %s", value)
+	return nil
+}
+
+func (r *Reloader) setPollingPeriod(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("the polling period must be positive")
+	}
+	r.pollingPeriod.Set(d)
+	klog.Infof("Polling period changed to %s", d)
+	return nil
+}
+
+func (r *Reloader) setRateLimits(qpsValue, burstValue string) error {
+	qps, err := strconv.ParseFloat(qpsValue, 32)
+	if err != nil {
+		return err
+	}
+	burst, err := strconv.Atoi(burstValue)
+	if err != nil {
+		return err
+	}
+	if qps > 0 && burst <= 0 {
+		return fmt.Errorf("the burst must be positive when the QPS is positive")
+	}
+	r.rateLimiter.Set(float32(qps), burst)
+	klog.Infof("Client-side throttling changed to QPS %s (burst: %d)", qpsValue, burst)
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tunables
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/core"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	key := client.ObjectKey{Namespace: "config-management-system", Name: ConfigMapName("root-reconciler")}
+	fakeClient := syncerFake.NewClient(t, core.Scheme)
+	pollingPeriod := NewDuration(15 * time.Second)
+	rateLimiter := NewRateLimiter(-1, -1)
+	r := NewReloader(fakeClient, key, pollingPeriod, rateLimiter)
+
+	// Without the ConfigMap, the defaults are kept.
+	require.NoError(t, r.Reload(ctx))
+	require.Equal(t, 15*time.Second, pollingPeriod.Get())
+
+	cm := &corev1.ConfigMap{Data: map[string]string{
+		PollingPeriodKey: "1m",
+		QPSKey:           "5",
+		BurstKey:         "10",
+	}}
+	cm.Name = key.Name
+	cm.Namespace = key.Namespace
+	require.NoError(t, fakeClient.Create(ctx, cm))
+	require.NoError(t, r.Reload(ctx))
+	require.Equal(t, time.Minute, pollingPeriod.Get())
+	qps, burst := rateLimiter.Limits()
+	require.Equal(t, float32(5), qps)
+	require.Equal(t, 10, burst)
+	require.Equal(t, float32(5), rateLimiter.QPS())
+
+	// Invalid values are ignored.
+	cm.Data[PollingPeriodKey] = "-1s"
+	cm.Data[QPSKey] = "fast"
+	require.NoError(t, fakeClient.Update(ctx, cm))
+	require.NoError(t, r.Reload(ctx))
+	require.Equal(t, time.Minute, pollingPeriod.Get())
+	qps, _ = rateLimiter.Limits()
+	require.Equal(t, float32(5), qps)
+
+	// Deleting the ConfigMap restores the defaults.
+	require.NoError(t, fakeClient.Delete(ctx, cm))
+	require.NoError(t, r.Reload(ctx))
+	require.Equal(t, 15*time.Second, pollingPeriod.Get())
+	qps, burst = rateLimiter.Limits()
+	require.Equal(t, float32(-1), qps)
+	require.Equal(t, -1, burst)
+	require.True(t, rateLimiter.TryAccept())
+}
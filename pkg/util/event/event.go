@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package event builds the Kubernetes Events which Config Sync records on the
+// objects it manages, and on the RootSyncs and RepoSyncs.
+package event
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reference returns the reference to the object with the given GVK, for the
+// InvolvedObject of its Events. The GVK is passed separately, since typed
+// objects usually have an empty TypeMeta.
+func Reference(obj client.Object, gvk schema.GroupVersionKind) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion:      gvk.GroupVersion().String(),
+		Kind:            gvk.Kind,
+		Name:            obj.GetName(),
+		Namespace:       obj.GetNamespace(),
+		UID:             obj.GetUID(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+}
+
+// New returns an Event of the involved object, reported by the component,
+// which occurred once at the timestamp. The Event is created in the Namespace
+// of the involved object, or in the default Namespace if it is cluster-scoped.
+func New(involved corev1.ObjectReference, eventType, reason, message, component string, timestamp metav1.Time) *corev1.Event {
+	namespace := involved.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: involved.Name + "-",
+			Namespace:    namespace,
+		},
+		InvolvedObject: involved,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: component},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestNew(t *testing.T) {
+	now := metav1.NewTime(time.Unix(1700000000, 0))
+
+	cm := fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), core.UID("1"))
+	e := New(Reference(cm, kinds.ConfigMap()), corev1.EventTypeWarning, "Reason", "message", "remediator", now)
+	assert.Equal(t, "cm-", e.GenerateName)
+	assert.Equal(t, "bookstore", e.Namespace)
+	assert.Equal(t, corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "bookstore", UID: "1"}, e.InvolvedObject)
+	assert.Equal(t, "remediator", e.Source.Component)
+	assert.Equal(t, now, e.FirstTimestamp)
+	assert.Equal(t, now, e.LastTimestamp)
+	assert.Equal(t, int32(1), e.Count)
+
+	ns := fake.NamespaceObject("bookstore")
+	e = New(Reference(ns, kinds.Namespace()), corev1.EventTypeNormal, "Reason", "message", "remediator", now)
+	assert.Equal(t, metav1.NamespaceDefault, e.Namespace, "events of cluster-scoped objects are recorded in the default Namespace")
+	assert.Equal(t, "", e.InvolvedObject.Namespace)
+}
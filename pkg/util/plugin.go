@@ -0,0 +1,43 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// pluginBaseEnv is the environment every plugin executed by the reconciler
+// containers is given, so that the plugin can find the usual executables.
+var pluginBaseEnv = []string{
+	"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+	"HOME=/tmp",
+}
+
+// PluginEnv returns the environment of a plugin executed by a reconciler
+// container: the base environment, followed by the variables. The
+// environment of the container is not passed to the plugin, because it may
+// hold credentials which the plugin must not see.
+func PluginEnv(vars ...string) []string {
+	return append(append([]string{}, pluginBaseEnv...), vars...)
+}
+
+// ValidPluginCommand returns true if the command of a plugin is a path
+// relative to the plugin directory, which cannot escape it. Absolute paths
+// and paths containing `..` would execute binaries of the reconciler
+// container, rather than of the plugin image.
+func ValidPluginCommand(command string) bool {
+	return command != "" && !filepath.IsAbs(command) && !strings.Contains(command, "..")
+}
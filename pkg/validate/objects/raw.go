@@ -42,6 +42,8 @@ type Raw struct {
 	BuildScoper       utildiscovery.BuildScoperFunc
 	Converter         *declared.ValueConverter
 	AllowUnknownKinds bool
+	// SelectorSkipped are the objects filtered out by their cluster selector.
+	SelectorSkipped []ast.FileObject
 }
 
 // Scoped builds a Scoped collection of objects from the Raw objects.
@@ -55,7 +57,7 @@ func (r *Raw) Scoped() (*Scoped, status.MultiError) {
 		return nil, errs
 	}
 
-	scoped := &Scoped{}
+	scoped := &Scoped{SelectorSkipped: r.SelectorSkipped}
 	for _, obj := range r.Objects {
 		s, err := scoper.GetObjectScope(obj)
 		if err != nil {
@@ -30,6 +30,9 @@ type Scoped struct {
 	Unknown               []ast.FileObject
 	DefaultNamespace      string
 	IsNamespaceReconciler bool
+	// SelectorSkipped are the objects filtered out by their cluster selector
+	// or namespace selector.
+	SelectorSkipped []ast.FileObject
 }
 
 // Objects returns all FileObjects in the Scoped collection.
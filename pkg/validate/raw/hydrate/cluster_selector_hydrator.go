@@ -42,7 +42,7 @@ func ClusterSelectors(objs *objects.Raw) status.MultiError {
 		return errs
 	}
 
-	var filtered []ast.FileObject
+	var filtered, skipped []ast.FileObject
 	// We process namespaces first so that we can use their stateActive/stateInactive state
 	// to do additional filtering on other resources  below.
 	activeNamespaces := make(map[string]bool)
@@ -65,12 +65,18 @@ func ClusterSelectors(objs *objects.Raw) status.MultiError {
 			activeNamespaces[ns.GetName()] = false
 		}
 	}
+	for _, ns := range set.namespaces {
+		if active, ok := activeNamespaces[ns.GetName()]; ok && !active {
+			skipped = append(skipped, ns)
+		}
+	}
 
 	// Now process the rest of the resources.
 	for _, res := range set.resources {
 		// First filter out namespace-scoped resources that are in an stateInactive
 		// namespace.
 		if active, ok := activeNamespaces[res.GetNamespace()]; ok && !active {
+			skipped = append(skipped, res)
 			continue
 		}
 		// Now perform the same cluster selection filtering as before.
@@ -81,6 +87,8 @@ func ClusterSelectors(objs *objects.Raw) status.MultiError {
 		}
 		if objState == stateActive {
 			filtered = append(filtered, res)
+		} else {
+			skipped = append(skipped, res)
 		}
 	}
 
@@ -91,6 +99,7 @@ func ClusterSelectors(objs *objects.Raw) status.MultiError {
 	// We are done with Clusters and ClusterSelectors so we can filter them out
 	// now as well.
 	objs.Objects = filtered
+	objs.SelectorSkipped = append(objs.SelectorSkipped, skipped...)
 	return nil
 }
 
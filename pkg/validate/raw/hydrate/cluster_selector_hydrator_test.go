@@ -164,6 +164,10 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: prodClusterName,
+				SelectorSkipped: []ast.FileObject{
+					fake.Namespace("namespaces/foo", withDevLegacyClusterSelector),
+					fake.Role(core.Namespace("foo")),
+				},
 			},
 		},
 		{
@@ -198,6 +202,10 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: prodClusterName,
+				SelectorSkipped: []ast.FileObject{
+					fake.Namespace("namespaces/foo", withDevInlineMatchLabels),
+					fake.Role(core.Namespace("foo")),
+				},
 			},
 		},
 		{
@@ -212,6 +220,9 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: unknownClusterName,
+				SelectorSkipped: []ast.FileObject{
+					fake.Role(core.Namespace("foo"), withDevLegacyClusterSelector),
+				},
 			},
 		},
 		{
@@ -224,6 +235,9 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: unknownClusterName,
+				SelectorSkipped: []ast.FileObject{
+					fake.Role(core.Namespace("foo"), withDevInlineMatchLabels),
+				},
 			},
 		},
 		{
@@ -255,6 +269,9 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: prodClusterName,
+				SelectorSkipped: []ast.FileObject{
+					fake.Role(withInlineClusterNameSelector("")),
+				},
 			},
 		},
 		{
@@ -269,6 +286,9 @@ func TestClusterSelectors(t *testing.T) {
 			},
 			want: &objects.Raw{
 				ClusterName: "",
+				SelectorSkipped: []ast.FileObject{
+					fake.Role(withInlineClusterNameSelector("a,,b")),
+				},
 			},
 		},
 		{
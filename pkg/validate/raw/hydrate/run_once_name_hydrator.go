@@ -0,0 +1,68 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+// runOnceHashLength is the number of hex characters of the content hash
+// appended to the name of append-hash run-once objects.
+const runOnceHashLength = 8
+
+// RunOnceNames appends a hash of the declared content to the name of every
+// object annotated with the append-hash run-once strategy. Changing the
+// declaration of such an object then creates a new object rather than
+// attempting to update immutable fields of the existing one, and the old
+// object is pruned.
+func RunOnceNames(objs *objects.Raw) status.MultiError {
+	var errs status.MultiError
+	for _, obj := range objs.Objects {
+		if core.GetAnnotation(obj, metadata.RunOnceStrategyKey) != metadata.RunOnceStrategyAppendHash {
+			continue
+		}
+		content := make(map[string]interface{}, len(obj.Object))
+		for k, v := range obj.Object {
+			if k == "metadata" || k == "status" {
+				continue
+			}
+			content[k] = v
+		}
+		bytes, err := json.Marshal(content)
+		if err != nil {
+			errs = status.Append(errs, status.ResourceWrap(err, "failed to hash run-once object", obj))
+			continue
+		}
+		sum := sha256.Sum256(bytes)
+		hash := hex.EncodeToString(sum[:])[:runOnceHashLength]
+
+		name := obj.GetName()
+		// Leave room for the separator and hash so the result remains a valid
+		// label value, which is required for the names of Jobs.
+		if maxLen := validation.DNS1123LabelMaxLength - runOnceHashLength - 1; len(name) > maxLen {
+			name = name[:maxLen]
+		}
+		obj.SetName(name + "-" + hash)
+	}
+	return errs
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"strings"
+	"testing"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+func TestRunOnceNames(t *testing.T) {
+	appendHash := core.Annotation(metadata.RunOnceStrategyKey, metadata.RunOnceStrategyAppendHash)
+	longName := strings.Repeat("a", 63)
+	objs := &objects.Raw{
+		Objects: []ast.FileObject{
+			fake.Unstructured(kinds.Job(), core.Name("plain")),
+			fake.Unstructured(kinds.Job(), core.Name("migrate"), appendHash),
+			fake.Unstructured(kinds.Job(), core.Name("migrate"), appendHash, core.Label("team", "a")),
+			fake.Unstructured(kinds.Job(), core.Name(longName), appendHash),
+		},
+	}
+	if err := RunOnceNames(objs); err != nil {
+		t.Fatalf("Got RunOnceNames() error %v, want nil", err)
+	}
+
+	if got := objs.Objects[0].GetName(); got != "plain" {
+		t.Errorf("got name %q for object without the annotation, want %q", got, "plain")
+	}
+	hashed := objs.Objects[1].GetName()
+	if !strings.HasPrefix(hashed, "migrate-") || len(hashed) != len("migrate-")+runOnceHashLength {
+		t.Errorf("got name %q, want migrate-<hash>", hashed)
+	}
+	// Metadata is excluded from the hash, so only content changes rename.
+	if got := objs.Objects[2].GetName(); got != hashed {
+		t.Errorf("got name %q for object with different labels, want %q", got, hashed)
+	}
+	if got := objs.Objects[3].GetName(); len(got) != 63 {
+		t.Errorf("got name %q of length %d, want length 63", got, len(got))
+	}
+}
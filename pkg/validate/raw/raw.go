@@ -44,6 +44,8 @@ func Hierarchical(objs *objects.Raw) status.MultiError {
 		objects.VisitAllRaw(validate.Directory),
 		objects.VisitAllRaw(validate.HNCLabels),
 		objects.VisitAllRaw(validate.ManagementAnnotation),
+		objects.VisitAllRaw(validate.RunOnceStrategyAnnotation),
+		objects.VisitAllRaw(validate.SyncWeightAnnotation),
 		objects.VisitAllRaw(validate.IllegalCRD),
 		objects.VisitAllRaw(validate.CRDName),
 		objects.VisitAllRaw(validate.RootSync),
@@ -77,6 +79,7 @@ func Hierarchical(objs *objects.Raw) status.MultiError {
 		hydrate.Filepath,
 		hydrate.HNCDepth,
 		hydrate.PreventDeletion,
+		hydrate.RunOnceNames,
 	}
 	for _, hydrator := range hydrators {
 		errs = status.Append(errs, hydrator(objs))
@@ -98,6 +101,8 @@ func Unstructured(objs *objects.Raw) status.MultiError {
 		objects.VisitAllRaw(validate.Name),
 		objects.VisitAllRaw(validate.Namespace),
 		objects.VisitAllRaw(validate.ManagementAnnotation),
+		objects.VisitAllRaw(validate.RunOnceStrategyAnnotation),
+		objects.VisitAllRaw(validate.SyncWeightAnnotation),
 		objects.VisitAllRaw(validate.IllegalCRD),
 		objects.VisitAllRaw(validate.CRDName),
 		objects.VisitAllRaw(validate.RootSync),
@@ -125,6 +130,7 @@ func Unstructured(objs *objects.Raw) status.MultiError {
 		hydrate.ClusterName,
 		hydrate.Filepath,
 		hydrate.PreventDeletion,
+		hydrate.RunOnceNames,
 	}
 	for _, hydrator := range hydrators {
 		errs = status.Append(errs, hydrator(objs))
@@ -73,6 +73,11 @@ func TestAnnotations(t *testing.T) {
 			obj:     fake.RoleBinding(core.Annotation(csAnnotation, "a")),
 			wantErr: metadata.IllegalAnnotationDefinitionError(fake.RoleBinding(), []string{csAnnotation}),
 		},
+		{
+			name:    "illegal emergency override annotation",
+			obj:     fake.Role(core.Annotation(csmetadata.EmergencyOverrideUntilKey, "2022-01-01T00:00:00Z")),
+			wantErr: metadata.IllegalAnnotationDefinitionError(fake.Role(), []string{csmetadata.EmergencyOverrideUntilKey}),
+		},
 	}
 
 	for _, tc := range testCases {
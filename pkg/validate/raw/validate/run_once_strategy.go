@@ -0,0 +1,53 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var runOnceStrategies = map[string]bool{
+	metadata.RunOnceStrategyReplace:           true,
+	metadata.RunOnceStrategyIgnoreIfSucceeded: true,
+	metadata.RunOnceStrategyAppendHash:        true,
+}
+
+// RunOnceStrategyAnnotation returns an Error if the user-specified run-once
+// strategy annotation is invalid.
+func RunOnceStrategyAnnotation(obj ast.FileObject) status.Error {
+	value, found := obj.GetAnnotations()[metadata.RunOnceStrategyKey]
+	if found && !runOnceStrategies[value] {
+		return IllegalRunOnceStrategyError(obj, value)
+	}
+	return nil
+}
+
+// IllegalRunOnceStrategyErrorCode is the error code for IllegalRunOnceStrategyError.
+const IllegalRunOnceStrategyErrorCode = "1072"
+
+var illegalRunOnceStrategyErrorBuilder = status.NewErrorBuilder(IllegalRunOnceStrategyErrorCode)
+
+// IllegalRunOnceStrategyError represents an illegal run-once strategy
+// annotation value.
+func IllegalRunOnceStrategyError(resource client.Object, value string) status.Error {
+	return illegalRunOnceStrategyErrorBuilder.
+		Sprintf("Config has invalid run-once strategy annotation %s=%s. If set, the value must be one of %q, %q or %q.",
+			metadata.RunOnceStrategyKey, value, metadata.RunOnceStrategyReplace,
+			metadata.RunOnceStrategyIgnoreIfSucceeded, metadata.RunOnceStrategyAppendHash).
+		BuildWithResources(resource)
+}
@@ -15,13 +15,19 @@
 package validate
 
 import (
+	"fmt"
+	"path"
 	"strings"
 
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/rename"
 	"kpt.dev/configsync/pkg/reposync"
 	"kpt.dev/configsync/pkg/rootsync"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncwindow"
+	"kpt.dev/configsync/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -63,6 +69,66 @@ func RootSyncSpec(sourceType string, git *v1beta1.Git, oci *v1beta1.Oci, helm *v
 	}
 }
 
+// HierarchicalDirs validates the hierarchical directories of a RootSync with
+// the source format.
+func HierarchicalDirs(sourceFormat string, dirs []string, rs client.Object) status.Error {
+	if len(dirs) == 0 {
+		return nil
+	}
+	if filesystem.SourceFormat(sourceFormat) != filesystem.SourceFormatUnstructured {
+		return HierarchicalDirsWithoutUnstructured(rs)
+	}
+	for i, dir := range dirs {
+		clean := path.Clean(dir)
+		if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return InvalidHierarchicalDir(rs, dir, "it must be a subdirectory of the sync directory")
+		}
+		for _, other := range dirs[:i] {
+			other = path.Clean(other)
+			if clean == other || strings.HasPrefix(clean, other+"/") || strings.HasPrefix(other, clean+"/") {
+				return InvalidHierarchicalDir(rs, dir, fmt.Sprintf("it overlaps with %q", other))
+			}
+		}
+	}
+	return nil
+}
+
+// SyncWindows validates the sync windows of a RootSync or RepoSync.
+func SyncWindows(windows []v1beta1.SyncWindow, rs client.Object) status.Error {
+	if _, err := syncwindow.Parse(windows); err != nil {
+		return InvalidSyncWindows(rs, err)
+	}
+	return nil
+}
+
+// Renames validates the rename rules of a RootSync or RepoSync. RepoSyncs
+// cannot rename the namespaces, since their objects must stay in the
+// namespace of the RepoSync.
+func Renames(renames []v1beta1.RenameRule, rs client.Object) status.Error {
+	rules, err := rename.Parse(renames)
+	if err != nil {
+		return InvalidRenames(rs, err)
+	}
+	if _, isRepoSync := rs.(*v1beta1.RepoSync); isRepoSync && rename.RenamesNamespaces(rules) {
+		return InvalidRenames(rs, fmt.Errorf("namespacePrefix and namespaceSuffix are only supported by RootSyncs"))
+	}
+	return nil
+}
+
+// Converter validates the converter plugin of a RootSync or RepoSync.
+func Converter(plugin *v1beta1.Converter, rs client.Object) status.Error {
+	if plugin == nil {
+		return nil
+	}
+	if plugin.Image == "" || plugin.Command == "" || len(plugin.Extensions) == 0 {
+		return MissingConverter(rs)
+	}
+	if !util.ValidPluginCommand(plugin.Command) {
+		return InvalidPluginCommand(rs, "spec.converter.command", plugin.Command)
+	}
+	return nil
+}
+
 // GitSpec validates the git specification for any obvious problems.
 func GitSpec(git *v1beta1.Git, rs client.Object) status.Error {
 	if git == nil {
@@ -86,6 +152,10 @@ func GitSpec(git *v1beta1.Git, rs client.Object) status.Error {
 		if !validGCPServiceAccountEmail(git.GCPServiceAccountEmail) {
 			return InvalidGCPSAEmail(rs)
 		}
+	case configsync.AuthExec:
+		if err := ExecCredentialSpec(git.ExecCredential, "git", rs); err != nil {
+			return err
+		}
 	default:
 		return InvalidAuthType(rs)
 	}
@@ -97,7 +167,7 @@ func GitSpec(git *v1beta1.Git, rs client.Object) status.Error {
 
 	// Check the secret ref is specified if and only if it is required.
 	switch git.Auth {
-	case configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount:
+	case configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount, configsync.AuthExec:
 		if git.SecretRef != nil && git.SecretRef.Name != "" {
 			return IllegalSecretRef(rs)
 		}
@@ -133,12 +203,28 @@ func OciSpec(oci *v1beta1.Oci, rs client.Object) status.Error {
 		if !validGCPServiceAccountEmail(oci.GCPServiceAccountEmail) {
 			return InvalidGCPSAEmail(rs)
 		}
+	case configsync.AuthExec:
+		if err := ExecCredentialSpec(oci.ExecCredential, "oci", rs); err != nil {
+			return err
+		}
 	default:
 		return InvalidOciAuthType(rs)
 	}
 	return nil
 }
 
+// ExecCredentialSpec validates the exec credential plugin of the git or oci
+// source, whose auth is exec.
+func ExecCredentialSpec(execCredential *v1beta1.ExecCredential, source string, rs client.Object) status.Error {
+	if execCredential == nil || execCredential.Image == "" || execCredential.Command == "" {
+		return MissingExecCredential(rs, source)
+	}
+	if !util.ValidPluginCommand(execCredential.Command) {
+		return InvalidPluginCommand(rs, fmt.Sprintf("spec.%s.execCredential.command", source), execCredential.Command)
+	}
+	return nil
+}
+
 // HelmSpec validates the Helm specification for any obvious problems.
 func HelmSpec(helm *v1beta1.HelmBase, rs client.Object) status.Error {
 	if helm == nil {
@@ -210,7 +296,7 @@ func MissingGitRepo(o client.Object) status.Error {
 // InvalidAuthType reports that a RootSync/RepoSync doesn't use one of the known auth
 // methods.
 func InvalidAuthType(o client.Object) status.Error {
-	types := []string{string(configsync.AuthSSH), string(configsync.AuthCookieFile), string(configsync.AuthGCENode), string(configsync.AuthToken), string(configsync.AuthNone), string(configsync.AuthGCPServiceAccount)}
+	types := []string{string(configsync.AuthSSH), string(configsync.AuthCookieFile), string(configsync.AuthGCENode), string(configsync.AuthToken), string(configsync.AuthNone), string(configsync.AuthGCPServiceAccount), string(configsync.AuthExec)}
 	kind := o.GetObjectKind().GroupVersionKind().Kind
 	return invalidSyncBuilder.
 		Sprintf("%ss must specify spec.git.auth to be one of %s", kind,
@@ -233,8 +319,8 @@ func NoOpProxy(o client.Object) status.Error {
 func IllegalSecretRef(o client.Object) status.Error {
 	kind := o.GetObjectKind().GroupVersionKind().Kind
 	return invalidSyncBuilder.
-		Sprintf("%ss which specify spec.git.auth as one of %q, %q, or %q must not specify spec.git.secretRef",
-			kind, configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount).
+		Sprintf("%ss which specify spec.git.auth as one of %q, %q, %q, or %q must not specify spec.git.secretRef",
+			kind, configsync.AuthNone, configsync.AuthGCENode, configsync.AuthGCPServiceAccount, configsync.AuthExec).
 		BuildWithResources(o)
 }
 
@@ -269,6 +355,37 @@ func MissingGCPSAEmail(o client.Object) status.Error {
 		BuildWithResources(o)
 }
 
+// MissingExecCredential reports that a RepoSync/RootSync resource declares the
+// exec auth mode, but does not specify the image and command of the exec
+// credential plugin.
+func MissingExecCredential(o client.Object, source string) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss which specify spec.%s.auth as %q must also specify spec.%s.execCredential.image and spec.%s.execCredential.command",
+			kind, source, configsync.AuthExec, source, source).
+		BuildWithResources(o)
+}
+
+// InvalidPluginCommand reports that a RepoSync/RootSync resource declares a
+// plugin whose command is not a path relative to the plugin directory.
+func InvalidPluginCommand(o client.Object, field, command string) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss must specify %s as a path relative to the plugin directory, without `..`: %q",
+			kind, field, command).
+		BuildWithResources(o)
+}
+
+// DisallowedPluginImage reports that a RepoSync resource declares a plugin
+// whose image is not one of the images allowed by the cluster admin.
+func DisallowedPluginImage(o client.Object, field, image string) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss may only specify %s as one of the plugin images allowed by the cluster admin, not %q",
+			kind, field, image).
+		BuildWithResources(o)
+}
+
 // validGCPServiceAccountEmail verifies whether GCP SA email has correct
 // prefix and suffix format.
 func validGCPServiceAccountEmail(email string) bool {
@@ -295,6 +412,52 @@ func InvalidSourceType(o client.Object) status.Error {
 		BuildWithResources(o)
 }
 
+// HierarchicalDirsWithoutUnstructured reports that a RootSync declares
+// hierarchical directories without the unstructured source format.
+func HierarchicalDirsWithoutUnstructured(o client.Object) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss must set spec.sourceFormat to %q to declare spec.hierarchicalDirs", kind, filesystem.SourceFormatUnstructured).
+		BuildWithResources(o)
+}
+
+// InvalidHierarchicalDir reports that a hierarchical directory of a RootSync
+// is invalid.
+func InvalidHierarchicalDir(o client.Object, dir, reason string) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss must declare valid spec.hierarchicalDirs: %q is invalid, because %s", kind, dir, reason).
+		BuildWithResources(o)
+}
+
+// InvalidSyncWindows reports that the sync windows of a RootSync or RepoSync
+// are invalid.
+func InvalidSyncWindows(o client.Object, err error) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss must declare valid spec.syncWindows: %v", kind, err).
+		BuildWithResources(o)
+}
+
+// InvalidRenames reports that the rename rules of a RootSync or RepoSync are
+// invalid.
+func InvalidRenames(o client.Object, err error) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss must declare valid spec.renames: %v", kind, err).
+		BuildWithResources(o)
+}
+
+// MissingConverter reports that a RootSync or RepoSync declares a converter
+// plugin without its image, command or extensions.
+func MissingConverter(o client.Object) status.Error {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	return invalidSyncBuilder.
+		Sprintf("%ss which specify spec.converter must also specify spec.converter.image, spec.converter.command and spec.converter.extensions",
+			kind).
+		BuildWithResources(o)
+}
+
 // MissingOciSpec reports that a RootSync/RepoSync doesn't declare the OCI spec
 // when spec.sourceType is set to `oci`.
 func MissingOciSpec(o client.Object) status.Error {
@@ -316,7 +479,7 @@ func MissingOciImage(o client.Object) status.Error {
 // InvalidOciAuthType reports that a RootSync/RepoSync doesn't use one of the known auth
 // methods for OCI image.
 func InvalidOciAuthType(o client.Object) status.Error {
-	types := []string{string(configsync.AuthGCENode), string(configsync.AuthGCPServiceAccount), string(configsync.AuthNone)}
+	types := []string{string(configsync.AuthGCENode), string(configsync.AuthGCPServiceAccount), string(configsync.AuthNone), string(configsync.AuthExec)}
 	kind := o.GetObjectKind().GroupVersionKind().Kind
 	return invalidSyncBuilder.
 		Sprintf("%ss must specify spec.oci.auth to be one of %s", kind,
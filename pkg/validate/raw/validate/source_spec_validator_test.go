@@ -42,6 +42,18 @@ func helmAuth(authType configsync.AuthType) func(*v1beta1.RepoSync) {
 	}
 }
 
+func execCredential(command string) func(*v1beta1.RepoSync) {
+	return func(sync *v1beta1.RepoSync) {
+		plugin := &v1beta1.ExecCredential{Image: "plugin-image", Command: command}
+		if sync.Spec.Git != nil {
+			sync.Spec.Git.ExecCredential = plugin
+		}
+		if sync.Spec.Oci != nil {
+			sync.Spec.Oci.ExecCredential = plugin
+		}
+	}
+}
+
 func named(name string) func(*v1beta1.RepoSync) {
 	return func(sync *v1beta1.RepoSync) {
 		sync.Name = name
@@ -206,6 +218,35 @@ func TestValidateRepoSyncSpec(t *testing.T) {
 			obj:     repoSyncWithGit(auth(configsync.AuthNone), secret("illegal secret")),
 			wantErr: fake.Error(InvalidSyncCode),
 		},
+		{
+			name: "valid git exec credential",
+			obj:  repoSyncWithGit(auth(configsync.AuthExec), execCredential("vend-token")),
+		},
+		{
+			name:    "missing git exec credential",
+			obj:     repoSyncWithGit(auth(configsync.AuthExec)),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
+		{
+			name:    "missing git exec credential command",
+			obj:     repoSyncWithGit(auth(configsync.AuthExec), execCredential("")),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
+		{
+			name:    "absolute git exec credential command",
+			obj:     repoSyncWithGit(auth(configsync.AuthExec), execCredential("/bin/sh")),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
+		{
+			name:    "git exec credential command escaping the plugin directory",
+			obj:     repoSyncWithGit(auth(configsync.AuthExec), execCredential("../../bin/sh")),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
+		{
+			name:    "illegal secret with exec credential",
+			obj:     repoSyncWithGit(auth(configsync.AuthExec), execCredential("vend-token"), secret("illegal secret")),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
 		{
 			name:    "missing secret",
 			obj:     repoSyncWithGit(auth(configsync.AuthSSH)),
@@ -251,6 +292,20 @@ func TestValidateRepoSyncSpec(t *testing.T) {
 			obj:     repoSyncWithOci(ociAuth(configsync.AuthGCPServiceAccount)),
 			wantErr: fake.Error(InvalidSyncCode),
 		},
+		{
+			name: "valid oci exec credential",
+			obj:  repoSyncWithOci(ociAuth(configsync.AuthExec), execCredential("vend-token")),
+		},
+		{
+			name:    "absolute oci exec credential command",
+			obj:     repoSyncWithOci(ociAuth(configsync.AuthExec), execCredential("/bin/sh")),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
+		{
+			name:    "missing oci exec credential",
+			obj:     repoSyncWithOci(ociAuth(configsync.AuthExec)),
+			wantErr: fake.Error(InvalidSyncCode),
+		},
 		{
 			name:    "invalid source type",
 			obj:     fake.RepoSyncObjectV1Beta1("test-ns", configsync.RepoSyncName, fake.WithRepoSyncSourceType("invalid")),
@@ -331,3 +386,56 @@ func TestValidateRootSyncSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateHierarchicalDirs(t *testing.T) {
+	rs := fake.RootSyncObjectV1Beta1(configsync.RootSyncName)
+	testCases := []struct {
+		name         string
+		sourceFormat string
+		dirs         []string
+		wantErr      status.Error
+	}{
+		{
+			name:         "no hierarchical dirs",
+			sourceFormat: "hierarchy",
+		},
+		{
+			name:         "valid hierarchical dirs",
+			sourceFormat: "unstructured",
+			dirs:         []string{"teams/a", "teams/b/"},
+		},
+		{
+			name:         "hierarchy format",
+			sourceFormat: "hierarchy",
+			dirs:         []string{"teams/a"},
+			wantErr:      fake.Error(InvalidSyncCode),
+		},
+		{
+			name:         "sync directory",
+			sourceFormat: "unstructured",
+			dirs:         []string{"."},
+			wantErr:      fake.Error(InvalidSyncCode),
+		},
+		{
+			name:         "outside of the sync directory",
+			sourceFormat: "unstructured",
+			dirs:         []string{"../teams"},
+			wantErr:      fake.Error(InvalidSyncCode),
+		},
+		{
+			name:         "nested dirs",
+			sourceFormat: "unstructured",
+			dirs:         []string{"teams", "teams/a"},
+			wantErr:      fake.Error(InvalidSyncCode),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := HierarchicalDirs(tc.sourceFormat, tc.dirs, rs)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Got HierarchicalDirs() error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
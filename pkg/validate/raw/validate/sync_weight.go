@@ -0,0 +1,50 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"strconv"
+
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SyncWeightAnnotation returns an Error if the user-specified sync weight
+// annotation is not an integer.
+func SyncWeightAnnotation(obj ast.FileObject) status.Error {
+	value, found := obj.GetAnnotations()[metadata.SyncWeightKey]
+	if !found {
+		return nil
+	}
+	if _, err := strconv.Atoi(value); err != nil {
+		return IllegalSyncWeightError(obj, value)
+	}
+	return nil
+}
+
+// IllegalSyncWeightErrorCode is the error code for IllegalSyncWeightError.
+const IllegalSyncWeightErrorCode = "1075"
+
+var illegalSyncWeightErrorBuilder = status.NewErrorBuilder(IllegalSyncWeightErrorCode)
+
+// IllegalSyncWeightError represents an illegal sync weight annotation value.
+func IllegalSyncWeightError(resource client.Object, value string) status.Error {
+	return illegalSyncWeightErrorBuilder.
+		Sprintf("Config has invalid sync weight annotation %s=%s. If set, the value must be an integer, like \"-1\" or \"5\".",
+			metadata.SyncWeightKey, value).
+		BuildWithResources(resource)
+}
@@ -29,7 +29,8 @@ import (
 // NamespaceSelectors hydrates the given Scoped objects by performing namespace
 // selection to copy objects into namespaces which match their selector. It also
 // sets a default namespace on any namespace-scoped object that does not already
-// have a namespace or namespace selector set.
+// have a namespace or namespace selector set. The default namespace from the
+// object's directory defaults file takes precedence over the repo-wide one.
 func NamespaceSelectors(objs *objects.Scoped) status.MultiError {
 	nsSelectors, errs := buildSelectorMap(objs)
 	if errs != nil {
@@ -42,12 +43,18 @@ func NamespaceSelectors(objs *objects.Scoped) status.MultiError {
 			copies, err := makeNamespaceCopies(obj, nsSelectors)
 			if err != nil {
 				errs = status.Append(errs, err)
+			} else if len(copies) == 0 {
+				objs.SelectorSkipped = append(objs.SelectorSkipped, obj)
 			} else {
 				result = append(result, copies...)
 			}
 		} else {
 			if obj.GetNamespace() == "" {
-				obj.SetNamespace(objs.DefaultNamespace)
+				if obj.DefaultNamespace != "" {
+					obj.SetNamespace(obj.DefaultNamespace)
+				} else {
+					obj.SetNamespace(objs.DefaultNamespace)
+				}
 			}
 			result = append(result, obj)
 		}
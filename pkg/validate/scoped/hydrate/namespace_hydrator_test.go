@@ -124,6 +124,9 @@ func TestNamespaceSelectors(t *testing.T) {
 				Cluster: []ast.FileObject{
 					fake.Namespace("namespaces/prod", core.Label("environment", "prod")),
 				},
+				SelectorSkipped: []ast.FileObject{
+					fake.Role(core.Annotation(metadata.NamespaceSelectorAnnotationKey, "dev-only")),
+				},
 			},
 		},
 		{
@@ -149,6 +152,23 @@ func TestNamespaceSelectors(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Set directory default namespace on namespaced object without namespace",
+			objs: &objects.Scoped{
+				DefaultNamespace: "hello",
+				Namespace: []ast.FileObject{
+					withDefaultNamespace(fake.Role(), "bookstore"),
+					withDefaultNamespace(fake.Role(core.Namespace("world")), "bookstore"),
+				},
+			},
+			want: &objects.Scoped{
+				DefaultNamespace: "hello",
+				Namespace: []ast.FileObject{
+					withDefaultNamespace(fake.Role(core.Namespace("bookstore")), "bookstore"),
+					withDefaultNamespace(fake.Role(core.Namespace("world")), "bookstore"),
+				},
+			},
+		},
 		{
 			name: "Error for missing namespace selector",
 			objs: &objects.Scoped{
@@ -227,3 +247,8 @@ func TestNamespaceSelectors(t *testing.T) {
 		})
 	}
 }
+
+func withDefaultNamespace(obj ast.FileObject, namespace string) ast.FileObject {
+	obj.DefaultNamespace = namespace
+	return obj
+}
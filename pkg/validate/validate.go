@@ -71,6 +71,11 @@ type Options struct {
 	// Visitors is a list of optional visitor functions which can be used to
 	// inject additional validation or hydration steps on the final objects.
 	Visitors []VisitorFunc
+	// SelectorSkipped, if set, is set to the declared objects which are
+	// filtered out because their cluster selector does not select the cluster,
+	// or their namespace selector in an unstructured repo does not select any
+	// namespace.
+	SelectorSkipped *[]ast.FileObject
 }
 
 // Hierarchical validates and hydrates the given FileObjects from a structured,
@@ -150,6 +155,9 @@ func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 		}
 	}
 
+	if opts.SelectorSkipped != nil {
+		*opts.SelectorSkipped = scopedObjects.SelectorSkipped
+	}
 	return finalObjects, nonBlockingErrs
 }
 
@@ -220,5 +228,8 @@ func Unstructured(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 		}
 	}
 
+	if opts.SelectorSkipped != nil {
+		*opts.SelectorSkipped = scopedObjects.SelectorSkipped
+	}
 	return finalObjects, nonBlockingErrs
 }
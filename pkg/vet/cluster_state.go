@@ -0,0 +1,226 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"kpt.dev/configsync/pkg/util/clusterconfig"
+	"kpt.dev/configsync/pkg/util/discovery"
+)
+
+// ClusterState is a fake cluster state, read from the manifests of the
+// objects on a cluster, like the output of `kubectl get -o yaml`. It lets
+// nomos vet and nomos hydrate resolve the scopes of the custom resources and
+// the removed CRDs, and report the management conflicts and the objects which
+// would be pruned, exactly as on that cluster, without contacting it.
+type ClusterState struct {
+	// Objects are the objects on the cluster.
+	Objects []*unstructured.Unstructured
+}
+
+// ReadClusterState reads the cluster state from the YAML and JSON files at
+// the path, which is either a file or a directory read recursively. Lists,
+// like the output of `kubectl get -o yaml`, are flattened.
+func ReadClusterState(path cmpath.Absolute) (*ClusterState, status.MultiError) {
+	var files []cmpath.Absolute
+	err := filepath.Walk(path.OSPath(), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, cmpath.Absolute(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, UnableToReadClusterState(path, err)
+	}
+
+	state := &ClusterState{}
+	var errs status.MultiError
+	for _, file := range files {
+		objs, err := readClusterStateFile(file)
+		if err != nil {
+			errs = status.Append(errs, UnableToReadClusterState(file, err))
+			continue
+		}
+		state.Objects = append(state.Objects, objs...)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return state, nil
+}
+
+func readClusterStateFile(file cmpath.Absolute) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file.OSPath())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		content := map[string]interface{}{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if len(content) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: content}
+		if !u.IsList() {
+			objs = append(objs, u)
+			continue
+		}
+		err := u.EachListItem(func(item runtime.Object) error {
+			objs = append(objs, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// CRDs returns the CustomResourceDefinitions on the cluster.
+func (s *ClusterState) CRDs() ([]*v1beta1.CustomResourceDefinition, status.MultiError) {
+	var crds []*v1beta1.CustomResourceDefinition
+	var errs status.MultiError
+	for _, obj := range s.Objects {
+		if obj.GroupVersionKind().GroupKind() != kinds.CustomResourceDefinition() {
+			continue
+		}
+		crd, err := clusterconfig.AsCRD(obj)
+		if err != nil {
+			errs = status.Append(errs, err)
+			continue
+		}
+		crds = append(crds, crd)
+	}
+	return crds, errs
+}
+
+// SyncedCRDs returns the CustomResourceDefinitions on the cluster which are
+// managed by Config Sync, so that removing one of them from the source of
+// truth is validated like on the cluster.
+func (s *ClusterState) SyncedCRDs() ([]*v1beta1.CustomResourceDefinition, status.MultiError) {
+	crds, errs := s.CRDs()
+	var synced []*v1beta1.CustomResourceDefinition
+	for _, crd := range crds {
+		if differ.ManagementEnabled(crd) {
+			synced = append(synced, crd)
+		}
+	}
+	return synced, errs
+}
+
+// AddResources returns an AddResourcesFunc which adds the scopes of the
+// CustomResourceDefinitions on the cluster.
+func (s *ClusterState) AddResources() discovery.AddResourcesFunc {
+	return func(scoper *discovery.Scoper) status.MultiError {
+		crds, errs := s.CRDs()
+		scoper.AddCustomResources(crds)
+		return errs
+	}
+}
+
+// ManagementConflicts returns the management conflict errors for the declared
+// objects which are managed on the cluster by a reconciler which the
+// reconciler of the RootSync or RepoSync cannot take over.
+func (s *ClusterState) ManagementConflicts(objs []ast.FileObject, scope declared.Scope, syncName string) status.MultiError {
+	live := s.byID()
+	var errs status.MultiError
+	for _, obj := range objs {
+		liveObj, found := live[core.IDOf(obj)]
+		if !found {
+			continue
+		}
+		if !diff.CanManage(scope, syncName, liveObj, admissionv1.Update) {
+			errs = status.Append(errs, status.ManagementConflictErrorWrap(liveObj, declared.ResourceManager(scope, syncName)))
+		}
+	}
+	return errs
+}
+
+// PrunePreview returns the objects on the cluster managed by the reconciler
+// of the RootSync or RepoSync, which are not declared, so would be pruned.
+func (s *ClusterState) PrunePreview(objs []ast.FileObject, scope declared.Scope, syncName string) []core.ID {
+	declaredIDs := make(map[core.ID]bool, len(objs))
+	for _, obj := range objs {
+		declaredIDs[core.IDOf(obj)] = true
+	}
+	manager := declared.ResourceManager(scope, syncName)
+	var pruned []core.ID
+	for id, liveObj := range s.byID() {
+		if declaredIDs[id] || !differ.ManagementEnabled(liveObj) ||
+			core.GetAnnotation(liveObj, metadata.ResourceManagerKey) != manager {
+			continue
+		}
+		pruned = append(pruned, id)
+	}
+	sort.Slice(pruned, func(i, j int) bool {
+		return pruned[i].String() < pruned[j].String()
+	})
+	return pruned
+}
+
+func (s *ClusterState) byID() map[core.ID]*unstructured.Unstructured {
+	result := make(map[core.ID]*unstructured.Unstructured, len(s.Objects))
+	for _, obj := range s.Objects {
+		result[core.IDOf(obj)] = obj
+	}
+	return result
+}
+
+// InvalidClusterStateCode is the error code for a cluster state which cannot
+// be read.
+const InvalidClusterStateCode = "1078"
+
+var invalidClusterStateBuilder = status.NewErrorBuilder(InvalidClusterStateCode)
+
+// UnableToReadClusterState represents that the cluster state passed to nomos
+// could not be read or parsed.
+func UnableToReadClusterState(path cmpath.Absolute, err error) status.Error {
+	return invalidClusterStateBuilder.Wrap(err).Sprint("unable to read the cluster state").BuildWithPaths(path)
+}
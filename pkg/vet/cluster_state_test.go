@@ -0,0 +1,165 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/util/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const clusterStateList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: apiextensions.k8s.io/v1
+  kind: CustomResourceDefinition
+  metadata:
+    name: anvils.acme.com
+    annotations:
+      configmanagement.gke.io/managed: enabled
+      configsync.gke.io/resource-id: apiextensions.k8s.io_customresourcedefinition_anvils.acme.com
+  spec:
+    group: acme.com
+    names:
+      kind: Anvil
+      plural: anvils
+    scope: Namespaced
+    versions:
+    - name: v1
+      served: true
+      storage: true
+  status:
+    acceptedNames:
+      kind: Anvil
+      plural: anvils
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: stale
+    namespace: shipping
+    annotations:
+      configmanagement.gke.io/managed: enabled
+      configsync.gke.io/manager: :root
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: taken
+    namespace: shipping
+    annotations:
+      configmanagement.gke.io/managed: enabled
+      configsync.gke.io/manager: :root_other-sync
+`
+
+const clusterStateJSON = `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shipping"}}`
+
+func writeClusterState(t *testing.T) cmpath.Absolute {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"list.yaml":            clusterStateList,
+		"nested/shipping.json": clusterStateJSON,
+		"README.md":            "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cmpath.Absolute(dir)
+}
+
+func TestClusterState(t *testing.T) {
+	state, errs := ReadClusterState(writeClusterState(t))
+	if errs != nil {
+		t.Fatalf("ReadClusterState() got error %v", errs)
+	}
+	if len(state.Objects) != 4 {
+		t.Fatalf("ReadClusterState() got %d objects, want 4", len(state.Objects))
+	}
+
+	crds, errs := state.SyncedCRDs()
+	if errs != nil {
+		t.Fatalf("SyncedCRDs() got error %v", errs)
+	}
+	if len(crds) != 1 || crds[0].Name != "anvils.acme.com" {
+		t.Errorf("SyncedCRDs() got %v, want anvils.acme.com", crds)
+	}
+
+	scoper := discovery.Scoper{}
+	if errs := state.AddResources()(&scoper); errs != nil {
+		t.Fatalf("AddResources() got error %v", errs)
+	}
+	scope, err := scoper.GetGroupKindScope(schema.GroupKind{Group: "acme.com", Kind: "Anvil"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope != discovery.NamespaceScope {
+		t.Errorf("got Anvil scope %v, want %v", scope, discovery.NamespaceScope)
+	}
+
+	objs := []ast.FileObject{
+		fake.Namespace("namespaces/shipping"),
+		fake.Unstructured(kinds.ConfigMap(), core.Name("taken"), core.Namespace("shipping")),
+		fake.Unstructured(kinds.ConfigMap(), core.Name("new"), core.Namespace("shipping")),
+	}
+
+	wantConflicts := status.ManagementConflictErrorWrap(state.Objects[2], declared.ResourceManager(declared.RootReconciler, configsync.RootSyncName))
+	gotConflicts := state.ManagementConflicts(objs, declared.RootReconciler, configsync.RootSyncName)
+	if gotConflicts == nil || len(gotConflicts.Errors()) != 1 || gotConflicts.Errors()[0].Error() != wantConflicts.Error() {
+		t.Errorf("ManagementConflicts() got %v, want %v", gotConflicts, wantConflicts)
+	}
+	if errs := state.ManagementConflicts(objs, declared.RootReconciler, "other-sync"); errs != nil {
+		t.Errorf("ManagementConflicts() for the manager got error %v, want nil", errs)
+	}
+
+	wantPruned := []core.ID{{
+		GroupKind: kinds.ConfigMap().GroupKind(),
+		ObjectKey: client.ObjectKey{Namespace: "shipping", Name: "stale"},
+	}}
+	if diff := cmp.Diff(wantPruned, state.PrunePreview(objs, declared.RootReconciler, configsync.RootSyncName)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestReadClusterStateError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("kind: [unterminated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, errs := ReadClusterState(cmpath.Absolute(dir))
+	if errs == nil {
+		t.Fatal("ReadClusterState() got nil error, want error")
+	}
+	if got := errs.Errors()[0].Code(); got != InvalidClusterStateCode {
+		t.Errorf("got error code %q, want %q", got, InvalidClusterStateCode)
+	}
+}
@@ -55,3 +55,13 @@ func isConfigSyncSA(userInfo authenticationv1.UserInfo) bool {
 func configSyncSAName(userInfo authenticationv1.UserInfo) string {
 	return strings.TrimPrefix(userInfo.Username, saNamespaceGroupPrefix)
 }
+
+// inGroup returns true if the given UserInfo is a member of the group.
+func inGroup(userInfo authenticationv1.UserInfo, group string) bool {
+	for _, g := range userInfo.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
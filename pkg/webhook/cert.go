@@ -23,12 +23,8 @@ import (
 )
 
 const (
-	secret         = configuration.ShortName + "-cert"
 	caName         = "config-sync-ca"
 	caOrganization = "config-sync"
-
-	// dnsName is <service name>.<namespace>.svc
-	dnsName = configuration.ShortName + "." + configsync.ControllerNamespace + ".svc"
 )
 
 // CreateCertsIfNeeded creates all certs for webhooks.
@@ -38,12 +34,12 @@ func CreateCertsIfNeeded(mgr manager.Manager, restartOnSecretRefresh bool) (chan
 	err := cert.AddRotator(mgr, &cert.CertRotator{
 		SecretKey: types.NamespacedName{
 			Namespace: configsync.ControllerNamespace,
-			Name:      secret,
+			Name:      configuration.CertSecretName,
 		},
 		CertDir:        configuration.CertDir,
 		CAName:         caName,
 		CAOrganization: caOrganization,
-		DNSName:        dnsName,
+		DNSName:        configuration.ServiceHost,
 		IsReady:        setupFinished,
 		Webhooks: []cert.WebhookInfo{{
 			Type: cert.Validating,
@@ -58,6 +58,14 @@ const ContainerPort = 10250
 // allowlisted in any firewall.
 const HealthProbePort = 10258
 
+// CertSecretName is the name of the Secret with the serving certificate and
+// the CA of the admission webhook.
+const CertSecretName = ShortName + "-cert"
+
+// ServiceHost is the DNS name of the Service of the admission webhook, in the
+// <service name>.<namespace>.svc format.
+const ServiceHost = ShortName + "." + configsync.ControllerNamespace + ".svc"
+
 // CertDir matches the mountPath specified in admission-webhook.yaml.
 const CertDir = "/certs"
 
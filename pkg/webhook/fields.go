@@ -78,7 +78,9 @@ func ConfigSyncMetadata(set *fieldpath.Set) *fieldpath.Set {
 		s := path.String()
 		if strings.HasPrefix(s, annotations) {
 			s = s[len(annotations):]
-			if csmetadata.IsConfigSyncAnnotationKey(s) {
+			// The emergency override annotation is set by users on live
+			// objects, so changing it is not a change of Config Sync metadata.
+			if csmetadata.IsConfigSyncAnnotationKey(s) && s != csmetadata.EmergencyOverrideUntilKey {
 				csSet.Insert(path)
 			}
 		} else if strings.HasPrefix(s, labels) {
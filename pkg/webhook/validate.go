@@ -17,9 +17,11 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/discovery"
@@ -38,8 +40,10 @@ import (
 )
 
 // AddValidator adds the admission webhook validator to the passed manager.
-func AddValidator(mgr manager.Manager) error {
-	handler, err := handler(mgr.GetConfig())
+// The members of the emergencyOverrideGroup may set emergency overrides on
+// managed objects. Emergency overrides are disabled if it is empty.
+func AddValidator(mgr manager.Manager, emergencyOverrideGroup string) error {
+	handler, err := handler(mgr.GetConfig(), emergencyOverrideGroup)
 	if err != nil {
 		return err
 	}
@@ -53,12 +57,16 @@ func AddValidator(mgr manager.Manager) error {
 // requests and admits or denies them.
 type Validator struct {
 	differ *ObjectDiffer
+	// emergencyOverrideGroup is the group whose members may set emergency
+	// overrides on managed objects. Emergency overrides are disabled if it is
+	// empty.
+	emergencyOverrideGroup string
 }
 
 var _ admission.Handler = &Validator{}
 
 // Handler returns a Validator which satisfies the admission.Handler interface.
-func handler(cfg *rest.Config) (*Validator, error) {
+func handler(cfg *rest.Config, emergencyOverrideGroup string) (*Validator, error) {
 	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -67,7 +75,7 @@ func handler(cfg *rest.Config) (*Validator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Validator{&ObjectDiffer{vc}}, nil
+	return &Validator{differ: &ObjectDiffer{vc}, emergencyOverrideGroup: emergencyOverrideGroup}, nil
 }
 
 // Handle implements admission.Handler
@@ -115,7 +123,7 @@ func (v *Validator) Handle(_ context.Context, req admission.Request) admission.R
 	case admissionv1.Delete:
 		return v.handleDelete(oldObj, username)
 	case admissionv1.Update:
-		return v.handleUpdate(oldObj, newObj, username)
+		return v.handleUpdate(oldObj, newObj, req.UserInfo)
 	default:
 		klog.Errorf("Unsupported operation: %v from %s", req.Operation, username)
 		return allow()
@@ -143,7 +151,8 @@ func (v *Validator) handleDelete(oldObj client.Object, username string) admissio
 	return allow()
 }
 
-func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string) admission.Response {
+func (v *Validator) handleUpdate(oldObj, newObj client.Object, userInfo authenticationv1.UserInfo) admission.Response {
+	username := userInfo.Username
 	if !differ.ManagedByConfigSync(oldObj) && !differ.ManagedByConfigSync(newObj) {
 		// Both oldObj and newObj are not managed by Config Sync.
 		// The webhook should be configured to only intercept resources which are
@@ -166,6 +175,10 @@ func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string)
 		return deny(metav1.StatusReasonForbidden, fmt.Sprintf("%s cannot modify Config Sync metadata of object %q: %s", username, core.GKNN(oldObj), csSet.String()))
 	}
 
+	if resp, handled := v.handleEmergencyOverride(oldObj, newObj, userInfo); handled {
+		return resp
+	}
+
 	if oldObj.GetAnnotations()[csmetadata.LifecycleMutationAnnotation] == csmetadata.IgnoreMutation {
 		// We ignore user modifications to this resource. Per the above check, we
 		// know that this annotation has not been altered.
@@ -190,6 +203,50 @@ func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string)
 	return allow()
 }
 
+// handleEmergencyOverride denies the changes to the emergency override
+// annotation of an object by users outside of the emergency override group,
+// and the expiries later than the maximum. It allows the changes of the
+// members of the group to an object under an active emergency override.
+// It returns false if the request is not decided by the emergency override.
+func (v *Validator) handleEmergencyOverride(oldObj, newObj client.Object, userInfo authenticationv1.UserInfo) (admission.Response, bool) {
+	username := userInfo.Username
+	oldValue, oldFound := oldObj.GetAnnotations()[csmetadata.EmergencyOverrideUntilKey]
+	newValue, newFound := newObj.GetAnnotations()[csmetadata.EmergencyOverrideUntilKey]
+	changed := oldFound != newFound || oldValue != newValue
+	if !changed && !newFound {
+		return admission.Response{}, false
+	}
+
+	member := v.emergencyOverrideGroup != "" && inGroup(userInfo, v.emergencyOverrideGroup)
+	if !member {
+		if !changed {
+			// The object is validated as usual, whether or not the override
+			// is active.
+			return admission.Response{}, false
+		}
+		msg := fmt.Sprintf("%s is not authorized to change the %s annotation of object %q", username, csmetadata.EmergencyOverrideUntilKey, core.GKNN(oldObj))
+		klog.Error(msg)
+		return deny(metav1.StatusReasonForbidden, msg), true
+	}
+
+	if !newFound {
+		return admission.Response{}, false
+	}
+	now := time.Now()
+	expiry, valid := csmetadata.EmergencyOverrideExpiry(newObj)
+	if !valid || csmetadata.EmergencyOverrideTooLong(expiry, now) {
+		msg := fmt.Sprintf("the %s annotation of object %q must be an RFC 3339 timestamp at most %s in the future: %q",
+			csmetadata.EmergencyOverrideUntilKey, core.GKNN(oldObj), csmetadata.EmergencyOverrideMaxDuration, newValue)
+		klog.Error(msg)
+		return deny(metav1.StatusReasonInvalid, msg), true
+	}
+	if csmetadata.EmergencyOverrideActive(newObj, now) {
+		klog.Infof("%s modified object %q under an emergency override until %s", username, core.GKNN(newObj), newValue)
+		return allow(), true
+	}
+	return admission.Response{}, false
+}
+
 func convertObjects(req admission.Request) (client.Object, client.Object, error) {
 	var oldObj client.Object
 	switch {
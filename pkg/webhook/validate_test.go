@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
@@ -614,6 +615,149 @@ func TestValidator_Handle(t *testing.T) {
 				core.Annotation(csmetadata.LifecycleMutationAnnotation, csmetadata.IgnoreMutation)),
 			deny: metav1.StatusReasonForbidden,
 		},
+		{
+			name: "Responder updates declared fields of a managed object under an emergency override",
+			oldObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			newObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				core.Annotation(csmetadata.EmergencyOverrideUntilKey, time.Now().Add(time.Hour).Format(time.RFC3339)),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"*"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			user: responder(),
+		},
+		{
+			name: "Responder updates declared fields of a managed object under an expired emergency override",
+			oldObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			newObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				core.Annotation(csmetadata.EmergencyOverrideUntilKey, time.Now().Add(-time.Hour).Format(time.RFC3339)),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"*"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			user: responder(),
+			deny: metav1.StatusReasonForbidden,
+		},
+		{
+			name: "Bob sets an emergency override outside of the emergency override group",
+			oldObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			newObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				core.Annotation(csmetadata.EmergencyOverrideUntilKey, time.Now().Add(time.Hour).Format(time.RFC3339)),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"*"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			user: bob(),
+			deny: metav1.StatusReasonForbidden,
+		},
+		{
+			name: "Responder sets an emergency override longer than the maximum",
+			oldObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			newObj: fake.RoleObject(
+				core.Name("hello"),
+				core.Namespace("world"),
+				core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+				core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+				core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+				core.Annotation(csmetadata.EmergencyOverrideUntilKey, time.Now().Add(csmetadata.EmergencyOverrideMaxDuration+time.Hour).Format(time.RFC3339)),
+				setRules([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"*"},
+					},
+				}),
+				core.Annotation(csmetadata.DeclaredFieldsKey, `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`),
+			),
+			user: responder(),
+			deny: metav1.StatusReasonInvalid,
+		},
 	}
 
 	v := validatorForTest(t)
@@ -643,7 +787,7 @@ func validatorForTest(t *testing.T) *Validator {
 		t.Fatalf("Failed to create ValueConverter: %v", err)
 	}
 	od := &ObjectDiffer{converter: vc}
-	return &Validator{differ: od}
+	return &Validator{differ: od, emergencyOverrideGroup: "oncall@acme.com"}
 }
 
 func configSyncImporter() authenticationv1.UserInfo {
@@ -674,6 +818,13 @@ func bob() authenticationv1.UserInfo {
 	}
 }
 
+func responder() authenticationv1.UserInfo {
+	return authenticationv1.UserInfo{
+		Groups:   []string{"devs@acme.com", "oncall@acme.com"},
+		Username: "alice@acme.com",
+	}
+}
+
 func request(oldObj, newObj client.Object) admission.Request {
 	var gvk schema.GroupVersionKind
 	var name, namespace string